@@ -0,0 +1,244 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ops for alert errors.
+var (
+	OpFindAlertStates              = "FindAlertStates"
+	OpFindAlertHistory             = "FindAlertHistory"
+	OpFindAlertAcknowledgementByID = "FindAlertAcknowledgementByID"
+	OpFindAlertAcknowledgements    = "FindAlertAcknowledgements"
+	OpCreateAlertAcknowledgement   = "CreateAlertAcknowledgement"
+	OpDeleteAlertAcknowledgement   = "DeleteAlertAcknowledgement"
+)
+
+// DefaultAlertLookback is how far back alerts are looked up when a filter
+// has no start.
+const DefaultAlertLookback = 24 * time.Hour
+
+// ErrAlertAcknowledgementNotFound is returned when an alert acknowledgement does not exist.
+var ErrAlertAcknowledgementNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "alert acknowledgement not found",
+}
+
+// AlertService reports the alerts of an organization from the statuses that
+// its checks write to the monitoring bucket.
+type AlertService interface {
+	// FindAlertStates returns the current state of each series of the checks
+	// that match filter, and the total count of states.
+	FindAlertStates(ctx context.Context, filter AlertFilter) ([]*AlertState, int, error)
+
+	// FindAlertHistory returns the statuses that match filter, most recent
+	// first, and the total count of returned statuses.
+	FindAlertHistory(ctx context.Context, filter AlertFilter, opt ...FindOptions) ([]*AlertStatus, int, error)
+}
+
+// AlertAcknowledgementService manages the acknowledgements of alerts.
+type AlertAcknowledgementService interface {
+	// FindAlertAcknowledgementByID returns a single alert acknowledgement by ID.
+	FindAlertAcknowledgementByID(ctx context.Context, id ID) (*AlertAcknowledgement, error)
+
+	// FindAlertAcknowledgements returns the alert acknowledgements that match filter and the total count of matching acknowledgements.
+	FindAlertAcknowledgements(ctx context.Context, filter AlertAcknowledgementFilter, opt ...FindOptions) ([]*AlertAcknowledgement, int, error)
+
+	// CreateAlertAcknowledgement creates a new alert acknowledgement and sets a.ID with the new identifier.
+	CreateAlertAcknowledgement(ctx context.Context, a *AlertAcknowledgement) error
+
+	// DeleteAlertAcknowledgement removes an alert acknowledgement by ID.
+	DeleteAlertAcknowledgement(ctx context.Context, id ID) error
+}
+
+// AlertStatus is a status written by a check for one of its series.
+type AlertStatus struct {
+	Time      time.Time `json:"time"`
+	CheckID   ID        `json:"checkID"`
+	CheckName string    `json:"checkName"`
+	CheckType string    `json:"checkType"`
+	// Level is one of UNKNOWN, OK, INFO, WARN or CRIT.
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	// Tags identify the series of the check the status is about.
+	Tags []Tag `json:"tags"`
+}
+
+// AlertState is the current state of a series of a check.
+type AlertState struct {
+	CheckID   ID     `json:"checkID"`
+	CheckName string `json:"checkName"`
+	CheckType string `json:"checkType"`
+	Tags      []Tag  `json:"tags"`
+	Level     string `json:"level"`
+	// Since is the time of the first status of the series at its current
+	// level. It is no earlier than the start of the filter it was found with.
+	Since       time.Time `json:"since"`
+	LastMessage string    `json:"lastMessage"`
+	LastTime    time.Time `json:"lastTime"`
+	// Acknowledgement is the acknowledgement of the state, if any.
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
+}
+
+// AlertFilter restricts the alerts returned by an AlertService.
+type AlertFilter struct {
+	OrgID   ID
+	CheckID *ID
+	// Levels matches the level of a status. It matches all levels when empty.
+	Levels []string
+	// Tags matches the statuses of the series that have all of the tags.
+	Tags []Tag
+	// Start defaults to DefaultAlertLookback before now, and Stop to now.
+	Start *time.Time
+	Stop  *time.Time
+}
+
+// QueryParams converts AlertFilter fields to url query params.
+func (f AlertFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{
+		"orgID": {f.OrgID.String()},
+	}
+	if f.CheckID != nil {
+		qp["checkID"] = []string{f.CheckID.String()}
+	}
+	for _, l := range f.Levels {
+		qp["level"] = append(qp["level"], l)
+	}
+	for _, t := range f.Tags {
+		qp["tag"] = append(qp["tag"], t.QueryParam())
+	}
+	if f.Start != nil {
+		qp["start"] = []string{f.Start.Format(time.RFC3339Nano)}
+	}
+	if f.Stop != nil {
+		qp["stop"] = []string{f.Stop.Format(time.RFC3339Nano)}
+	}
+	return qp
+}
+
+var alertLevels = map[string]bool{
+	"UNKNOWN": true,
+	"OK":      true,
+	"INFO":    true,
+	"WARN":    true,
+	"CRIT":    true,
+}
+
+// Valid returns an error if the filter is malformed.
+func (f AlertFilter) Valid() error {
+	if !f.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert filter orgID is invalid",
+		}
+	}
+	for _, l := range f.Levels {
+		if !alertLevels[strings.ToUpper(l)] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid alert level %q", l),
+			}
+		}
+	}
+	for _, t := range f.Tags {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+	}
+	if f.Start != nil && f.Stop != nil && !f.Stop.After(*f.Start) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert filter stop must be after its start",
+		}
+	}
+	return nil
+}
+
+// AlertAcknowledgement is the acknowledgement of an alert by a user. It
+// acknowledges the state of the series of the check while the series stays
+// at the acknowledged level.
+type AlertAcknowledgement struct {
+	ID      ID     `json:"id,omitempty"`
+	OrgID   ID     `json:"orgID"`
+	CheckID ID     `json:"checkID"`
+	Tags    []Tag  `json:"tags"`
+	Level   string `json:"level"`
+	Message string `json:"message,omitempty"`
+	// UserID is the user that acknowledged the alert.
+	UserID    ID        `json:"userID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Valid returns an error if the acknowledgement is malformed.
+func (a *AlertAcknowledgement) Valid() error {
+	if !a.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert acknowledgement orgID is invalid",
+		}
+	}
+	if !a.CheckID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert acknowledgement checkID is invalid",
+		}
+	}
+	for _, t := range a.Tags {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+	}
+	if !alertLevels[strings.ToUpper(a.Level)] {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid alert level %q", a.Level),
+		}
+	}
+	return nil
+}
+
+// Acknowledges returns whether the acknowledgement applies to the state. It
+// does once the series of the state has been at the acknowledged level since
+// before the acknowledgement was created.
+func (a *AlertAcknowledgement) Acknowledges(s *AlertState) bool {
+	if a.CheckID != s.CheckID || !strings.EqualFold(a.Level, s.Level) {
+		return false
+	}
+	if a.CreatedAt.Before(s.Since) {
+		return false
+	}
+	if len(a.Tags) != len(s.Tags) {
+		return false
+	}
+	for _, at := range a.Tags {
+		var ok bool
+		for _, st := range s.Tags {
+			ok = ok || at == st
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// AlertAcknowledgementFilter restricts the acknowledgements returned by FindAlertAcknowledgements.
+type AlertAcknowledgementFilter struct {
+	OrgID   *ID
+	CheckID *ID
+}
+
+// QueryParams converts AlertAcknowledgementFilter fields to url query params.
+func (f AlertAcknowledgementFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.CheckID != nil {
+		qp["checkID"] = []string{f.CheckID.String()}
+	}
+	return qp
+}
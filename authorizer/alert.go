@@ -0,0 +1,133 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.AlertService = (*AlertService)(nil)
+var _ influxdb.AlertAcknowledgementService = (*AlertAcknowledgementService)(nil)
+
+// AlertService wraps a influxdb.AlertService and authorizes actions against
+// it appropriately. Alerts are authorized with the permissions of the
+// organization of the checks they come from.
+type AlertService struct {
+	s influxdb.AlertService
+}
+
+// NewAlertService constructs an instance of an authorizing alert service.
+func NewAlertService(s influxdb.AlertService) *AlertService {
+	return &AlertService{
+		s: s,
+	}
+}
+
+// FindAlertStates checks to see if the authorizer on context has read access to the organization of the filter.
+func (s *AlertService) FindAlertStates(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeReadOrg(ctx, filter.OrgID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.s.FindAlertStates(ctx, filter)
+}
+
+// FindAlertHistory checks to see if the authorizer on context has read access to the organization of the filter.
+func (s *AlertService) FindAlertHistory(ctx context.Context, filter influxdb.AlertFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeReadOrg(ctx, filter.OrgID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.s.FindAlertHistory(ctx, filter, opt...)
+}
+
+// AlertAcknowledgementService wraps a influxdb.AlertAcknowledgementService and
+// authorizes actions against it appropriately.
+type AlertAcknowledgementService struct {
+	s influxdb.AlertAcknowledgementService
+}
+
+// NewAlertAcknowledgementService constructs an instance of an authorizing alert acknowledgement service.
+func NewAlertAcknowledgementService(s influxdb.AlertAcknowledgementService) *AlertAcknowledgementService {
+	return &AlertAcknowledgementService{
+		s: s,
+	}
+}
+
+// FindAlertAcknowledgementByID checks to see if the authorizer on context has read access to the organization of the acknowledgement.
+func (s *AlertAcknowledgementService) FindAlertAcknowledgementByID(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	a, err := s.s.FindAlertAcknowledgementByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, a.OrgID); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FindAlertAcknowledgements retrieves all acknowledgements that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *AlertAcknowledgementService) FindAlertAcknowledgements(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	acks, _, err := s.s.FindAlertAcknowledgements(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filtered := acks[:0]
+	for _, a := range acks {
+		err := authorizeReadOrg(ctx, a.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+
+	return filtered, len(filtered), nil
+}
+
+// CreateAlertAcknowledgement checks to see if the authorizer on context has write access to the organization of the acknowledgement.
+func (s *AlertAcknowledgementService) CreateAlertAcknowledgement(ctx context.Context, a *influxdb.AlertAcknowledgement) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteOrg(ctx, a.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateAlertAcknowledgement(ctx, a)
+}
+
+// DeleteAlertAcknowledgement checks to see if the authorizer on context has write access to the organization of the acknowledgement.
+func (s *AlertAcknowledgementService) DeleteAlertAcknowledgement(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	a, err := s.s.FindAlertAcknowledgementByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, a.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteAlertAcknowledgement(ctx, id)
+}
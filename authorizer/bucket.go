@@ -157,6 +157,10 @@ func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) er
 		return err
 	}
 
+	if err := authorizeDownsamplingPolicies(ctx, b.OrgID, b.DownsamplingPolicies); err != nil {
+		return err
+	}
+
 	return s.s.CreateBucket(ctx, b)
 }
 
@@ -171,9 +175,38 @@ func (s *BucketService) UpdateBucket(ctx context.Context, id influxdb.ID, upd in
 		return nil, err
 	}
 
+	if upd.DownsamplingPolicies != nil {
+		if err := authorizeDownsamplingPolicies(ctx, b.OrgID, *upd.DownsamplingPolicies); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.s.UpdateBucket(ctx, id, upd)
 }
 
+// authorizeDownsamplingPolicies checks that the authorizer on context can write
+// to the destination buckets of the policies and create the tasks that run them.
+func authorizeDownsamplingPolicies(ctx context.Context, orgID influxdb.ID, policies []influxdb.DownsamplingPolicy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.TasksResourceType, orgID)
+	if err != nil {
+		return err
+	}
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if err := authorizeWriteBucket(ctx, orgID, policy.DestinationBucketID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DeleteBucket checks to see if the authorizer on context has write access to the bucket provided.
 func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 	b, err := s.s.FindBucketByID(ctx, id)
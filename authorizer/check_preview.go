@@ -0,0 +1,66 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.CheckPreviewService = (*CheckPreviewService)(nil)
+
+// CheckPreviewService wraps a influxdb.CheckPreviewService and authorizes
+// actions against it appropriately. A check may be previewed by those who
+// could read the checks of its organization; its queries are authorized like
+// any other query. Previews are found by those who could read the checks of
+// the organization of the preview.
+type CheckPreviewService struct {
+	s influxdb.CheckPreviewService
+}
+
+// NewCheckPreviewService constructs an instance of an authorizing check preview service.
+func NewCheckPreviewService(s influxdb.CheckPreviewService) *CheckPreviewService {
+	return &CheckPreviewService{
+		s: s,
+	}
+}
+
+// PreviewCheck checks to see if the authorizer on context has read access to the checks of the organization.
+func (s *CheckPreviewService) PreviewCheck(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	pm, err := influxdb.NewPermission(influxdb.ReadAction, influxdb.ChecksResourceType, p.Check.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	if err := IsAllowed(ctx, *pm); err != nil {
+		return nil, err
+	}
+
+	return s.s.PreviewCheck(ctx, p)
+}
+
+// FindCheckPreviewByID checks to see if the authorizer on context has read access to the checks of the organization of the preview.
+func (s *CheckPreviewService) FindCheckPreviewByID(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	res, err := s.s.FindCheckPreviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pm, err := influxdb.NewPermission(influxdb.ReadAction, influxdb.ChecksResourceType, res.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := IsAllowed(ctx, *pm); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.EscalationPolicyService = (*EscalationPolicyService)(nil)
+
+// EscalationPolicyService wraps a influxdb.EscalationPolicyService and
+// authorizes actions against it appropriately. Escalation policies are
+// authorized with the permissions of the organization they belong to, like
+// the notification rules that escalate with them.
+type EscalationPolicyService struct {
+	s influxdb.EscalationPolicyService
+}
+
+// NewEscalationPolicyService constructs an instance of an authorizing escalation policy service.
+func NewEscalationPolicyService(s influxdb.EscalationPolicyService) *EscalationPolicyService {
+	return &EscalationPolicyService{
+		s: s,
+	}
+}
+
+// FindEscalationPolicyByID checks to see if the authorizer on context has read access to the organization of the escalation policy.
+func (s *EscalationPolicyService) FindEscalationPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindEscalationPolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, p.OrgID); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// FindEscalationPolicies retrieves all escalation policies that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *EscalationPolicyService) FindEscalationPolicies(ctx context.Context, filter influxdb.EscalationPolicyFilter, opt ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	policies, _, err := s.s.FindEscalationPolicies(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filtered := policies[:0]
+	for _, p := range policies {
+		err := authorizeReadOrg(ctx, p.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+
+	return filtered, len(filtered), nil
+}
+
+// CreateEscalationPolicy checks to see if the authorizer on context has write access to the organization of the escalation policy.
+func (s *EscalationPolicyService) CreateEscalationPolicy(ctx context.Context, p *influxdb.EscalationPolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteOrg(ctx, p.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateEscalationPolicy(ctx, p)
+}
+
+// UpdateEscalationPolicy checks to see if the authorizer on context has write access to the organization of the escalation policy.
+func (s *EscalationPolicyService) UpdateEscalationPolicy(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindEscalationPolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteOrg(ctx, p.OrgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateEscalationPolicy(ctx, id, upd)
+}
+
+// DeleteEscalationPolicy checks to see if the authorizer on context has write access to the organization of the escalation policy.
+func (s *EscalationPolicyService) DeleteEscalationPolicy(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindEscalationPolicyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, p.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteEscalationPolicy(ctx, id)
+}
@@ -0,0 +1,115 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MeasurementSchemaService = (*MeasurementSchemaService)(nil)
+
+// MeasurementSchemaService wraps a influxdb.MeasurementSchemaService and authorizes actions
+// against it appropriately. Measurement schemas are authorized with the permissions of
+// the bucket they belong to.
+type MeasurementSchemaService struct {
+	s influxdb.MeasurementSchemaService
+}
+
+// NewMeasurementSchemaService constructs an instance of an authorizing measurement schema service.
+func NewMeasurementSchemaService(s influxdb.MeasurementSchemaService) *MeasurementSchemaService {
+	return &MeasurementSchemaService{
+		s: s,
+	}
+}
+
+// FindMeasurementSchemaByID checks to see if the authorizer on context has read access to the bucket of the schema.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// FindMeasurementSchemas retrieves all measurement schemas that match the provided filter
+// and then filters the list down to only the schemas of buckets that are authorized.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	schemas, err := s.s.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := schemas[:0]
+	for _, ms := range schemas {
+		err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		filtered = append(filtered, ms)
+	}
+
+	return filtered, nil
+}
+
+// CreateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.CreateMeasurementSchema(ctx, ms)
+}
+
+// UpdateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateMeasurementSchema(ctx, id, upd)
+}
+
+// DeleteMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteMeasurementSchema(ctx, id)
+}
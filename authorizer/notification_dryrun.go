@@ -0,0 +1,81 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.NotificationEndpointTestService = (*NotificationEndpointTestService)(nil)
+var _ influxdb.NotificationRuleDryRunService = (*NotificationRuleDryRunService)(nil)
+
+// NotificationEndpointTestService wraps a influxdb.NotificationEndpointTestService
+// and authorizes actions against it appropriately. An endpoint may be tested by
+// those who could update it.
+type NotificationEndpointTestService struct {
+	s         influxdb.NotificationEndpointTestService
+	endpoints influxdb.NotificationEndpointService
+}
+
+// NewNotificationEndpointTestService constructs an instance of an authorizing
+// notification endpoint test service, that finds the organization of the
+// endpoints it tests with endpoints.
+func NewNotificationEndpointTestService(s influxdb.NotificationEndpointTestService, endpoints influxdb.NotificationEndpointService) *NotificationEndpointTestService {
+	return &NotificationEndpointTestService{
+		s:         s,
+		endpoints: endpoints,
+	}
+}
+
+// TestNotificationEndpoint checks to see if the authorizer on context has write access to the organization of the endpoint.
+func (s *NotificationEndpointTestService) TestNotificationEndpoint(ctx context.Context, id influxdb.ID, t influxdb.NotificationEndpointTest) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	edp, err := s.endpoints.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, edp.GetOrgID()); err != nil {
+		return err
+	}
+
+	return s.s.TestNotificationEndpoint(ctx, id, t)
+}
+
+// NotificationRuleDryRunService wraps a influxdb.NotificationRuleDryRunService
+// and authorizes actions against it appropriately. A rule may be dry run by
+// those who could read it, as its dry run only reads statuses.
+type NotificationRuleDryRunService struct {
+	s     influxdb.NotificationRuleDryRunService
+	rules influxdb.NotificationRuleStore
+}
+
+// NewNotificationRuleDryRunService constructs an instance of an authorizing
+// notification rule dry run service, that finds the organization of the rules
+// it dry runs with rules.
+func NewNotificationRuleDryRunService(s influxdb.NotificationRuleDryRunService, rules influxdb.NotificationRuleStore) *NotificationRuleDryRunService {
+	return &NotificationRuleDryRunService{
+		s:     s,
+		rules: rules,
+	}
+}
+
+// DryRunNotificationRule checks to see if the authorizer on context has read access to the organization of the rule.
+func (s *NotificationRuleDryRunService) DryRunNotificationRule(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	nr, err := s.rules.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, nr.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	return s.s.DryRunNotificationRule(ctx, id)
+}
@@ -0,0 +1,107 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.TaskBackfillService = (*TaskBackfillService)(nil)
+
+// TaskBackfillService wraps a influxdb.TaskBackfillService and authorizes actions
+// against it appropriately. Backfills are authorized with the permissions of
+// the task they run.
+type TaskBackfillService struct {
+	s influxdb.TaskBackfillService
+}
+
+// NewTaskBackfillService constructs an instance of an authorizing task backfill service.
+func NewTaskBackfillService(s influxdb.TaskBackfillService) *TaskBackfillService {
+	return &TaskBackfillService{
+		s: s,
+	}
+}
+
+func authorizeTask(ctx context.Context, a influxdb.Action, orgID, id influxdb.ID) error {
+	p, err := influxdb.NewPermissionAtID(id, a, influxdb.TasksResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	return IsAllowed(ctx, *p)
+}
+
+// FindBackfillByID checks to see if the authorizer on context has read access to the task of the backfill.
+func (s *TaskBackfillService) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.s.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.ReadAction, b.OrgID, b.TaskID); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// FindBackfills retrieves all backfills that match the provided filter
+// and then filters the list down to only the backfills of tasks that are authorized.
+func (s *TaskBackfillService) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	backfills, err := s.s.FindBackfills(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := backfills[:0]
+	for _, b := range backfills {
+		err := authorizeTask(ctx, influxdb.ReadAction, b.OrgID, b.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		filtered = append(filtered, b)
+	}
+
+	return filtered, nil
+}
+
+// CreateBackfill checks to see if the authorizer on context has write access to the task of the backfill.
+func (s *TaskBackfillService) CreateBackfill(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, bc.OrgID, bc.TaskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.CreateBackfill(ctx, bc)
+}
+
+// CancelBackfill checks to see if the authorizer on context has write access to the task of the backfill.
+func (s *TaskBackfillService) CancelBackfill(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.s.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, b.OrgID, b.TaskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.CancelBackfill(ctx, id)
+}
@@ -0,0 +1,69 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.TaskVersionService = (*TaskVersionService)(nil)
+
+// TaskVersionService wraps a influxdb.TaskVersionService and authorizes actions
+// against it appropriately. Versions are authorized with the permissions of
+// the task they belong to.
+type TaskVersionService struct {
+	s influxdb.TaskVersionService
+}
+
+// NewTaskVersionService constructs an instance of an authorizing task version service.
+func NewTaskVersionService(s influxdb.TaskVersionService) *TaskVersionService {
+	return &TaskVersionService{
+		s: s,
+	}
+}
+
+// FindTaskVersion checks to see if the authorizer on context has read access to the task of the version.
+func (s *TaskVersionService) FindTaskVersion(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindTaskVersion(ctx, taskID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.ReadAction, v.OrganizationID, v.TaskID); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// FindTaskVersions retrieves the versions of a task and then filters the list
+// down to only the versions of tasks that are authorized.
+func (s *TaskVersionService) FindTaskVersions(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	versions, err := s.s.FindTaskVersions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := versions[:0]
+	for _, v := range versions {
+		err := authorizeTask(ctx, influxdb.ReadAction, v.OrganizationID, v.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		filtered = append(filtered, v)
+	}
+
+	return filtered, nil
+}
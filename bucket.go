@@ -41,6 +41,10 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	MaxSeries           int64         `json:"maxSeries,omitempty"` // Zero means unlimited.
+	SchemaType          SchemaType    `json:"schemaType,omitempty"`
+
+	DownsamplingPolicies []DownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
 	CRUDLog
 }
 
@@ -104,6 +108,10 @@ type BucketUpdate struct {
 	Name            *string        `json:"name,omitempty"`
 	Description     *string        `json:"description,omitempty"`
 	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	MaxSeries       *int64         `json:"maxSeries,omitempty"`
+
+	// DownsamplingPolicies replaces the downsampling policies of the bucket.
+	DownsamplingPolicies *[]DownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
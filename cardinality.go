@@ -0,0 +1,87 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCardinalityTopN is the default number of measurements and tag keys
+// returned in a cardinality report.
+const DefaultCardinalityTopN = 10
+
+// ops for cardinality error.
+var (
+	OpBucketCardinality = "BucketCardinality"
+)
+
+// CardinalityService provides series cardinality information about the data
+// stored in buckets.
+type CardinalityService interface {
+	// BucketCardinality returns the current series cardinality of a bucket.
+	BucketCardinality(ctx context.Context, orgID, bucketID ID, opt CardinalityOptions) (*BucketCardinality, error)
+}
+
+// CardinalityOptions restrict the size of a cardinality report.
+type CardinalityOptions struct {
+	// TopN is the maximum number of measurements and tag keys to return.
+	TopN int
+}
+
+// BucketCardinality is a report of the series cardinality of a bucket.
+// Estimated reports whether the counts of the measurements and tag keys are
+// estimates rather than exact counts.
+type BucketCardinality struct {
+	BucketID     ID                       `json:"bucketID"`
+	SeriesN      int64                    `json:"seriesN"`
+	Measurements []MeasurementCardinality `json:"measurements"`
+	TagKeys      []TagKeyCardinality      `json:"tagKeys"`
+	Estimated    bool                     `json:"estimated"`
+	Growth       *CardinalityGrowth       `json:"growth,omitempty"`
+	SampledAt    time.Time                `json:"sampledAt"`
+}
+
+// MeasurementCardinality is the number of series in a measurement.
+type MeasurementCardinality struct {
+	Name    string `json:"name"`
+	SeriesN int64  `json:"seriesN"`
+}
+
+// TagKeyCardinality is the number of distinct values of a tag key across all
+// measurements of a bucket.
+type TagKeyCardinality struct {
+	Key     string `json:"key"`
+	ValuesN int64  `json:"valuesN"`
+}
+
+// CardinalityGrowth is the change in series cardinality since a previous sample.
+type CardinalityGrowth struct {
+	Since   time.Time `json:"since"`
+	SeriesN int64     `json:"seriesN"`
+}
+
+// CardinalityGrowthWindow is how far back the growth of a bucket is reported
+// from. Samples older than the window are discarded.
+const CardinalityGrowthWindow = 24 * time.Hour
+
+// CardinalitySampleInterval is the least time between two recorded samples of
+// the cardinality of a bucket.
+const CardinalitySampleInterval = 10 * time.Minute
+
+// CardinalitySample is the series cardinality of a bucket at a point in time.
+type CardinalitySample struct {
+	BucketID ID        `json:"bucketID"`
+	SeriesN  int64     `json:"seriesN"`
+	Time     time.Time `json:"time"`
+}
+
+// CardinalitySampleService stores the history of the cardinality of buckets,
+// so that growth can be reported across restarts.
+type CardinalitySampleService interface {
+	// AddCardinalitySample records a sample, and discards the samples of its
+	// bucket older than the growth window.
+	AddCardinalitySample(ctx context.Context, s *CardinalitySample) error
+
+	// FindCardinalitySamples returns the samples of a bucket taken at or after
+	// since, oldest first.
+	FindCardinalitySamples(ctx context.Context, bucketID ID, since time.Time) ([]*CardinalitySample, error)
+}
@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ops for check preview errors.
+var (
+	OpPreviewCheck         = "PreviewCheck"
+	OpFindCheckPreviewByID = "FindCheckPreviewByID"
+)
+
+// MaxCheckPreviewEvaluations is the most evaluations of a check a preview may
+// run, a day of a check that runs every 5 minutes.
+const MaxCheckPreviewEvaluations = 288
+
+// statuses of the result of a check preview.
+const (
+	CheckPreviewRunning = "running"
+	CheckPreviewSuccess = "success"
+	CheckPreviewFailed  = "failed"
+)
+
+// ErrCheckPreviewNotFound is the error of a preview that doesn't exist, or
+// that finished long enough ago to be forgotten.
+var ErrCheckPreviewNotFound = &Error{
+	Code: ENotFound,
+	Op:   OpFindCheckPreviewByID,
+	Msg:  "check preview not found",
+}
+
+// CheckPreview is a check to evaluate against the data of a time range, as its
+// task would have evaluated it every interval of the range. The check does not
+// need to be saved.
+type CheckPreview struct {
+	Check Check     `json:"check"`
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+// Valid returns an error if the preview is malformed.
+func (p CheckPreview) Valid() error {
+	switch {
+	case p.Check == nil:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "preview requires a check",
+		}
+	case !p.Check.GetOrgID().Valid():
+		return &Error{
+			Code: EInvalid,
+			Msg:  "preview requires the organization of the check",
+		}
+	case p.Start.IsZero() || p.Stop.IsZero():
+		return &Error{
+			Code: EInvalid,
+			Msg:  "preview requires a start and a stop time",
+		}
+	case !p.Stop.After(p.Start):
+		return &Error{
+			Code: EInvalid,
+			Msg:  "preview stop time must be after its start time",
+		}
+	}
+	return nil
+}
+
+// CheckPreviewResult is the outcome of the preview of a check.
+type CheckPreviewResult struct {
+	ID    ID `json:"id"`
+	OrgID ID `json:"orgID"`
+	// Status is CheckPreviewRunning until every evaluation of the check ran.
+	// Transitions and Levels are only set once it is CheckPreviewSuccess.
+	Status string `json:"status"`
+	// Error is why the preview failed.
+	Error string    `json:"error,omitempty"`
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+	// Evaluations is the number of times the check was evaluated.
+	Evaluations int `json:"evaluations"`
+	// Transitions are the changes of level of each series, in time order.
+	Transitions []*CheckPreviewTransition `json:"transitions"`
+	// Levels is the number of transitions to each level.
+	Levels map[string]int `json:"levels"`
+}
+
+// CheckPreviewTransition is a change of the level of a series of a check.
+type CheckPreviewTransition struct {
+	// Time is the time of the evaluation the level changed at.
+	Time time.Time `json:"time"`
+	Tags []Tag     `json:"tags"`
+	// PreviousLevel is empty for the first status of a series.
+	PreviousLevel string `json:"previousLevel,omitempty"`
+	Level         string `json:"level"`
+	Message       string `json:"message"`
+}
+
+// ErrCheckPreviewTooLong is the error of a preview that would evaluate a check
+// more than MaxCheckPreviewEvaluations times.
+func ErrCheckPreviewTooLong(evaluations int) *Error {
+	return &Error{
+		Code: EInvalid,
+		Op:   OpPreviewCheck,
+		Msg:  fmt.Sprintf("preview would evaluate the check %d times, at most %d are allowed", evaluations, MaxCheckPreviewEvaluations),
+	}
+}
+
+// CheckPreviewService evaluates checks against historical data.
+type CheckPreviewService interface {
+	// PreviewCheck starts to evaluate the check of p at each time its task
+	// would have run between the start and stop of p, and returns the running
+	// preview. Nothing is written to the monitoring bucket.
+	PreviewCheck(ctx context.Context, p CheckPreview) (*CheckPreviewResult, error)
+
+	// FindCheckPreviewByID returns a preview, with the status transitions that
+	// would have occurred once it succeeded.
+	FindCheckPreviewByID(ctx context.Context, id ID) (*CheckPreviewResult, error)
+}
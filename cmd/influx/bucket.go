@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -30,6 +32,15 @@ type cmdBucketBuilder struct {
 	description string
 	org         organization
 	retention   time.Duration
+	maxSeries   int64
+	schemaType  string
+
+	downsampleTo         string
+	downsampleEvery      time.Duration
+	downsampleOffset     time.Duration
+	downsampleAggregates []string
+	downsampleTags       []string
+	noDownsampling       bool
 }
 
 func newCmdBucketBuilder(svcsFn bucketSVCsFn, opts genericCLIOpts) *cmdBucketBuilder {
@@ -72,6 +83,9 @@ func (b *cmdBucketBuilder) cmdCreate() *cobra.Command {
 
 	cmd.Flags().StringVarP(&b.description, "description", "d", "", "Description of bucket that will be created")
 	cmd.Flags().DurationVarP(&b.retention, "retention", "r", 0, "Duration in nanoseconds data will live in bucket")
+	cmd.Flags().Int64Var(&b.maxSeries, "max-series", 0, "Maximum number of series the bucket may contain; 0 means unlimited")
+	cmd.Flags().StringVar(&b.schemaType, "schema-type", "", "Schema type of the bucket, implicit (default) or explicit")
+	b.registerDownsamplingFlags(cmd)
 	b.org.register(cmd, false)
 
 	return cmd
@@ -91,6 +105,18 @@ func (b *cmdBucketBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 		Name:            b.name,
 		Description:     b.description,
 		RetentionPeriod: b.retention,
+		MaxSeries:       b.maxSeries,
+		SchemaType:      influxdb.SchemaType(b.schemaType),
+	}
+	if err := bkt.SchemaType.Valid(); err != nil {
+		return err
+	}
+	policy, err := b.downsamplingPolicy()
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		bkt.DownsamplingPolicies = []influxdb.DownsamplingPolicy{*policy}
 	}
 	bkt.OrgID, err = b.org.getID(orgSVC)
 	if err != nil {
@@ -253,6 +279,9 @@ func (b *cmdBucketBuilder) cmdUpdate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.description, "description", "d", "", "Description of bucket that will be created")
 	cmd.MarkFlagRequired("id")
 	cmd.Flags().DurationVarP(&b.retention, "retention", "r", 0, "New duration data will live in bucket")
+	cmd.Flags().Int64Var(&b.maxSeries, "max-series", 0, "New maximum number of series the bucket may contain; 0 means unlimited")
+	b.registerDownsamplingFlags(cmd)
+	cmd.Flags().BoolVar(&b.noDownsampling, "no-downsampling", false, "Remove the downsampling policies of the bucket")
 
 	return cmd
 }
@@ -278,6 +307,22 @@ func (b *cmdBucketBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) er
 	if b.retention != 0 {
 		update.RetentionPeriod = &b.retention
 	}
+	if cmd.Flags().Changed("max-series") {
+		update.MaxSeries = &b.maxSeries
+	}
+
+	policy, err := b.downsamplingPolicy()
+	if err != nil {
+		return err
+	}
+	if policy != nil && b.noDownsampling {
+		return errors.New("--downsample-to and --no-downsampling cannot be used together")
+	}
+	if policy != nil {
+		update.DownsamplingPolicies = &[]influxdb.DownsamplingPolicy{*policy}
+	} else if b.noDownsampling {
+		update.DownsamplingPolicies = &[]influxdb.DownsamplingPolicy{}
+	}
 
 	bkt, err := bktSVC.UpdateBucket(context.Background(), id, update)
 	if err != nil {
@@ -297,6 +342,63 @@ func (b *cmdBucketBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) er
 	return nil
 }
 
+func (b *cmdBucketBuilder) registerDownsamplingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&b.downsampleTo, "downsample-to", "", "ID of the bucket to downsample data into; replaces the downsampling policies of the bucket")
+	cmd.Flags().DurationVar(&b.downsampleEvery, "downsample-every", time.Hour, "Window to aggregate data over when downsampling")
+	cmd.Flags().DurationVar(&b.downsampleOffset, "downsample-offset", 0, "Time to wait for late data before downsampling a window")
+	cmd.Flags().StringSliceVar(&b.downsampleAggregates, "downsample-aggregate", nil, "Aggregate function per field type, e.g. float=mean,string=last")
+	cmd.Flags().StringSliceVar(&b.downsampleTags, "downsample-tag", nil, "Only downsample series with the tag value, e.g. host=server01")
+}
+
+// downsamplingPolicy returns the downsampling policy given by the flags, or nil
+// if there is none.
+func (b *cmdBucketBuilder) downsamplingPolicy() (*influxdb.DownsamplingPolicy, error) {
+	if b.downsampleTo == "" {
+		return nil, nil
+	}
+
+	destID, err := influxdb.IDFromString(b.downsampleTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode downsampling bucket id %q: %v", b.downsampleTo, err)
+	}
+	if len(b.downsampleAggregates) == 0 {
+		return nil, errors.New("--downsample-aggregate is required with --downsample-to")
+	}
+
+	policy := &influxdb.DownsamplingPolicy{
+		DestinationBucketID: *destID,
+		Every:               influxdb.Duration{Duration: b.downsampleEvery},
+		Offset:              influxdb.Duration{Duration: b.downsampleOffset},
+		Aggregates:          make(map[influxdb.SchemaColumnDataType]string, len(b.downsampleAggregates)),
+	}
+	for _, agg := range b.downsampleAggregates {
+		typ, fn, err := splitKeyValue(agg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aggregate %q: %v", agg, err)
+		}
+		policy.Aggregates[influxdb.SchemaColumnDataType(typ)] = fn
+	}
+	for _, tag := range b.downsampleTags {
+		k, v, err := splitKeyValue(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %v", tag, err)
+		}
+		if policy.TagFilter == nil {
+			policy.TagFilter = make(map[string]string)
+		}
+		policy.TagFilter[k] = v
+	}
+	return policy, nil
+}
+
+func splitKeyValue(s string) (string, string, error) {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+		return "", "", errors.New("must be of the form key=value")
+	}
+	return kv[0], kv[1], nil
+}
+
 func newBucketSVCs() (influxdb.BucketService, influxdb.OrganizationService, error) {
 	httpClient, err := newHTTPClient()
 	if err != nil {
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http"
+	"github.com/spf13/cobra"
+)
+
+type bucketSchemaSVCsFn func(bucketID influxdb.ID) (influxdb.MeasurementSchemaService, influxdb.BucketService, influxdb.OrganizationService, error)
+
+func cmdBucketSchema(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdBucketSchemaBuilder(newBucketSchemaSVCs, opt)
+	builder.globalFlags = f
+	return builder.cmd()
+}
+
+type cmdBucketSchemaBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn bucketSchemaSVCsFn
+
+	bucketID    string
+	bucket      string
+	org         organization
+	name        string
+	columnsFile string
+}
+
+func newCmdBucketSchemaBuilder(svcsFn bucketSchemaSVCsFn, opts genericCLIOpts) *cmdBucketSchemaBuilder {
+	return &cmdBucketSchemaBuilder{
+		genericCLIOpts: opts,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdBucketSchemaBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("bucket-schema", nil)
+	cmd.Short = "Bucket measurement schema management commands"
+	cmd.TraverseChildren = true
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdList(),
+		b.cmdUpdate(),
+	)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) registerBucketFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&b.bucketID, "bucket-id", "i", "", "The ID of the bucket")
+	cmd.Flags().StringVarP(&b.bucket, "bucket", "b", "", "The name of the bucket")
+	b.org.register(cmd, false)
+}
+
+func (b *cmdBucketSchemaBuilder) registerSchemaFlags(cmd *cobra.Command, nameRequired bool) {
+	desc := "The name of the measurement"
+	if nameRequired {
+		desc += " (required)"
+	}
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", desc)
+	if nameRequired {
+		cmd.MarkFlagRequired("name")
+	}
+}
+
+func (b *cmdBucketSchemaBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a measurement schema for a bucket"
+
+	b.registerBucketFlags(cmd)
+	b.registerSchemaFlags(cmd, true)
+	cmd.Flags().StringVarP(&b.columnsFile, "columns-file", "c", "", "Path to a JSON file with the columns of the measurement (required)")
+	cmd.MarkFlagRequired("columns-file")
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
+	columns, err := b.readColumns()
+	if err != nil {
+		return err
+	}
+
+	schemaSVC, bucketID, err := b.schemaService()
+	if err != nil {
+		return err
+	}
+
+	ms := &influxdb.MeasurementSchema{
+		BucketID: bucketID,
+		Name:     b.name,
+		Columns:  columns,
+	}
+	if err := schemaSVC.CreateMeasurementSchema(context.Background(), ms); err != nil {
+		return fmt.Errorf("failed to create measurement schema: %v", err)
+	}
+
+	b.printSchemas(ms)
+	return nil
+}
+
+func (b *cmdBucketSchemaBuilder) cmdList() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdListRunEFn)
+	cmd.Short = "List the measurement schemas of a bucket"
+	cmd.Aliases = []string{"find", "ls"}
+
+	b.registerBucketFlags(cmd)
+	b.registerSchemaFlags(cmd, false)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdListRunEFn(*cobra.Command, []string) error {
+	schemaSVC, bucketID, err := b.schemaService()
+	if err != nil {
+		return err
+	}
+
+	filter := influxdb.MeasurementSchemaFilter{BucketID: bucketID}
+	if b.name != "" {
+		filter.Name = &b.name
+	}
+
+	schemas, err := schemaSVC.FindMeasurementSchemas(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve measurement schemas: %v", err)
+	}
+
+	b.printSchemas(schemas...)
+	return nil
+}
+
+func (b *cmdBucketSchemaBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Add columns to a measurement schema"
+
+	b.registerBucketFlags(cmd)
+	b.registerSchemaFlags(cmd, true)
+	cmd.Flags().StringVarP(&b.columnsFile, "columns-file", "c", "", "Path to a JSON file with all columns of the measurement, including existing ones (required)")
+	cmd.MarkFlagRequired("columns-file")
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdUpdateRunEFn(*cobra.Command, []string) error {
+	columns, err := b.readColumns()
+	if err != nil {
+		return err
+	}
+
+	schemaSVC, bucketID, err := b.schemaService()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	schemas, err := schemaSVC.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{
+		BucketID: bucketID,
+		Name:     &b.name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve measurement schema: %v", err)
+	}
+	if len(schemas) == 0 {
+		return fmt.Errorf("measurement schema %q not found", b.name)
+	}
+
+	ms, err := schemaSVC.UpdateMeasurementSchema(ctx, schemas[0].ID, influxdb.MeasurementSchemaUpdate{Columns: columns})
+	if err != nil {
+		return fmt.Errorf("failed to update measurement schema: %v", err)
+	}
+
+	b.printSchemas(ms)
+	return nil
+}
+
+// schemaService returns the measurement schema service of the bucket given by
+// the bucket flags.
+func (b *cmdBucketSchemaBuilder) schemaService() (influxdb.MeasurementSchemaService, influxdb.ID, error) {
+	if b.bucketID == "" && b.bucket == "" {
+		return nil, 0, errors.New("must specify bucket-id, or bucket name and org")
+	} else if b.bucketID != "" && b.bucket != "" {
+		return nil, 0, errors.New("must specify either bucket-id or bucket name, not both")
+	}
+
+	var bucketID influxdb.ID
+	if b.bucketID != "" {
+		if err := bucketID.DecodeFromString(b.bucketID); err != nil {
+			return nil, 0, fmt.Errorf("invalid bucket ID provided: %v", err)
+		}
+	}
+
+	schemaSVC, bktSVC, orgSVC, err := b.svcFn(bucketID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if bucketID.Valid() {
+		return schemaSVC, bucketID, nil
+	}
+
+	if err := b.org.validOrgFlags(); err != nil {
+		return nil, 0, err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return nil, 0, err
+	}
+	bkt, err := bktSVC.FindBucketByName(context.Background(), orgID, b.bucket)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find bucket %q: %v", b.bucket, err)
+	}
+
+	schemaSVC, _, _, err = b.svcFn(bkt.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return schemaSVC, bkt.ID, nil
+}
+
+func (b *cmdBucketSchemaBuilder) readColumns() ([]influxdb.MeasurementSchemaColumn, error) {
+	buf, err := ioutil.ReadFile(b.columnsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns file: %v", err)
+	}
+
+	var columns []influxdb.MeasurementSchemaColumn
+	if err := json.Unmarshal(buf, &columns); err != nil {
+		return nil, fmt.Errorf("failed to decode columns file: %v", err)
+	}
+	return columns, nil
+}
+
+func (b *cmdBucketSchemaBuilder) printSchemas(schemas ...*influxdb.MeasurementSchema) {
+	w := b.newTabWriter()
+	w.WriteHeaders("ID", "Measurement", "Columns", "BucketID")
+	for _, ms := range schemas {
+		columns := make([]string, 0, len(ms.Columns))
+		for _, c := range ms.Columns {
+			col := c.Name + ":" + string(c.Type)
+			if c.DataType != "" {
+				col += ":" + string(c.DataType)
+			}
+			columns = append(columns, col)
+		}
+
+		w.Write(map[string]interface{}{
+			"ID":          ms.ID.String(),
+			"Measurement": ms.Name,
+			"Columns":     strings.Join(columns, ","),
+			"BucketID":    ms.BucketID.String(),
+		})
+	}
+	w.Flush()
+}
+
+func newBucketSchemaSVCs(bucketID influxdb.ID) (influxdb.MeasurementSchemaService, influxdb.BucketService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	schemaSvc := &http.MeasurementSchemaService{Client: httpClient, BucketID: bucketID}
+	return schemaSvc, &http.BucketService{Client: httpClient}, &http.OrganizationService{Client: httpClient}, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdBucketSchema(t *testing.T) {
+	orgID, bucketID := influxdb.ID(9000), influxdb.ID(9001)
+
+	columnsFile, err := ioutil.TempFile("", "columns-*.json")
+	require.NoError(t, err)
+	defer os.Remove(columnsFile.Name())
+	_, err = columnsFile.WriteString(`[
+		{"name": "time", "type": "timestamp"},
+		{"name": "host", "type": "tag"},
+		{"name": "usage", "type": "field", "dataType": "float"}
+	]`)
+	require.NoError(t, err)
+	require.NoError(t, columnsFile.Close())
+
+	fakeSVCFn := func(svc influxdb.MeasurementSchemaService) bucketSchemaSVCsFn {
+		return func(influxdb.ID) (influxdb.MeasurementSchemaService, influxdb.BucketService, influxdb.OrganizationService, error) {
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: bucketID, OrgID: id, Name: name}, nil
+			}
+			return svc, bktSVC, &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+				},
+			}, nil
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			flags []string
+		}{
+			{
+				name:  "by bucket id",
+				flags: []string{"--bucket-id=" + bucketID.String(), "--name=cpu", "--columns-file=" + columnsFile.Name()},
+			},
+			{
+				name:  "by bucket name",
+				flags: []string{"--bucket=b", "--org=influxdata", "-n=cpu", "-c=" + columnsFile.Name()},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				var got *influxdb.MeasurementSchema
+				svc := mock.NewMeasurementSchemaService()
+				svc.CreateMeasurementSchemaF = func(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+					got = ms
+					return nil
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+					return newCmdBucketSchemaBuilder(fakeSVCFn(svc), opt).cmd()
+				})
+				cmd.SetArgs(append([]string{"bucket-schema", "create"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				require.NotNil(t, got)
+				assert.Equal(t, bucketID, got.BucketID)
+				assert.Equal(t, "cpu", got.Name)
+				assert.Equal(t, []influxdb.MeasurementSchemaColumn{
+					{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+					{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+					{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+				}, got.Columns)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+}
@@ -60,6 +60,58 @@ func TestCmdBucket(t *testing.T) {
 					OrgID:           orgID,
 				},
 			},
+			{
+				name: "with max series",
+				flags: []string{
+					"--name=new name",
+					"--max-series=1000",
+					"--org=org name",
+				},
+				expectedBucket: influxdb.Bucket{
+					Name:      "new name",
+					MaxSeries: 1000,
+					OrgID:     orgID,
+				},
+			},
+			{
+				name: "with explicit schema",
+				flags: []string{
+					"--name=new name",
+					"--schema-type=explicit",
+					"--org=org name",
+				},
+				expectedBucket: influxdb.Bucket{
+					Name:       "new name",
+					SchemaType: influxdb.SchemaTypeExplicit,
+					OrgID:      orgID,
+				},
+			},
+			{
+				name: "with downsampling policy",
+				flags: []string{
+					"--name=new name",
+					"--org=org name",
+					"--downsample-to=" + influxdb.ID(2).String(),
+					"--downsample-every=1h",
+					"--downsample-offset=5m",
+					"--downsample-aggregate=float=mean,string=last",
+					"--downsample-tag=host=a",
+				},
+				expectedBucket: influxdb.Bucket{
+					Name:  "new name",
+					OrgID: orgID,
+					DownsamplingPolicies: []influxdb.DownsamplingPolicy{{
+						DestinationBucketID: 2,
+						Every:               influxdb.Duration{Duration: time.Hour},
+						Offset:              influxdb.Duration{Duration: 5 * time.Minute},
+						Aggregates: map[influxdb.SchemaColumnDataType]string{
+							influxdb.SchemaColumnDataTypeFloat:  "mean",
+							influxdb.SchemaColumnDataTypeString: "last",
+						},
+						TagFilter: map[string]string{"host": "a"},
+					}},
+				},
+			},
 			{
 				name: "shorts",
 				flags: []string{
@@ -95,7 +147,7 @@ func TestCmdBucket(t *testing.T) {
 		cmdFn := func(expectedBkt influxdb.Bucket) func(*globalFlags, genericCLIOpts) *cobra.Command {
 			svc := mock.NewBucketService()
 			svc.CreateBucketFn = func(ctx context.Context, bucket *influxdb.Bucket) error {
-				if expectedBkt != *bucket {
+				if !reflect.DeepEqual(expectedBkt, *bucket) {
 					return fmt.Errorf("unexpected bucket;\n\twant= %+v\n\tgot=  %+v", expectedBkt, *bucket)
 				}
 				return nil
@@ -323,11 +375,23 @@ func TestCmdBucket(t *testing.T) {
 					"--name=new name",
 					"--description=desc",
 					"--retention=1m",
+					"--max-series=0",
 				},
 				expected: influxdb.BucketUpdate{
 					Name:            strPtr("new name"),
 					Description:     strPtr("desc"),
 					RetentionPeriod: durPtr(time.Minute),
+					MaxSeries:       int64Ptr(0),
+				},
+			},
+			{
+				name: "without downsampling",
+				flags: []string{
+					"--id=" + influxdb.ID(3).String(),
+					"--no-downsampling",
+				},
+				expected: influxdb.BucketUpdate{
+					DownsamplingPolicies: &[]influxdb.DownsamplingPolicy{},
 				},
 			},
 			{
@@ -405,6 +469,10 @@ func durPtr(d time.Duration) *time.Duration {
 	return &d
 }
 
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
 func addEnvVars(t *testing.T, envVars map[string]string) func() {
 	t.Helper()
 
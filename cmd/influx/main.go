@@ -181,6 +181,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdAuth,
 		cmdBackup,
 		cmdBucket,
+		cmdBucketSchema,
 		cmdDelete,
 		cmdOrganization,
 		cmdPing,
@@ -29,10 +29,13 @@ func cmdTask(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	cmd.AddCommand(
 		taskLogCmd(opt),
 		taskRunCmd(opt),
+		taskBackfillCmd(opt),
+		taskVersionCmd(opt),
 		taskCreateCmd(opt),
 		taskDeleteCmd(opt),
 		taskFindCmd(opt),
 		taskUpdateCmd(opt),
+		taskTestCmd(opt),
 	)
 
 	return cmd
@@ -118,12 +121,101 @@ func taskCreateF(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var taskTestFlags struct {
+	org          organization
+	scheduledFor string
+}
+
+func taskTestCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("test [query literal or @/path/to/query.flux]", taskTestF)
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Short = "Dry run a task script without persisting what it writes"
+
+	taskTestFlags.org.register(cmd, false)
+	cmd.Flags().StringVarP(&taskTestFlags.scheduledFor, "scheduled-for", "", "", "time the run is scheduled for, in RFC3339 format (defaults to now)")
+
+	return cmd
+}
+
+func taskTestF(cmd *cobra.Command, args []string) error {
+	if err := taskTestFlags.org.validOrgFlags(); err != nil {
+		return err
+	}
+
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	flux, err := repl.LoadQuery(args[0])
+	if err != nil {
+		return fmt.Errorf("error parsing flux script: %s", err)
+	}
+
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := taskTestFlags.org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	d := influxdb.TaskDryRun{
+		OrganizationID: orgID,
+		Flux:           flux,
+	}
+	if taskTestFlags.scheduledFor != "" {
+		d.ScheduledFor, err = time.Parse(time.RFC3339, taskTestFlags.scheduledFor)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := &http.TaskDryRunService{Client: client}
+	res, err := s.DryRunTask(context.Background(), d)
+	if err != nil {
+		return err
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"BucketID",
+		"Measurement",
+		"Tags",
+		"Time",
+		"Fields",
+	)
+	for _, t := range res.Tables {
+		for _, r := range t.Rows {
+			w.Write(map[string]interface{}{
+				"BucketID":    t.BucketID.String(),
+				"Measurement": t.Measurement,
+				"Tags":        t.Tags,
+				"Time":        r.Time.Format(time.RFC3339Nano),
+				"Fields":      r.Fields,
+			})
+		}
+	}
+	w.Flush()
+
+	for _, l := range res.Log {
+		fmt.Printf("%s\t%s\n", l.Time, l.Message)
+	}
+
+	if res.Error != "" {
+		return fmt.Errorf("task dry run failed: %s", res.Error)
+	}
+	return nil
+}
+
 var taskFindFlags struct {
-	user    string
-	id      string
-	limit   int
-	headers bool
-	org     organization
+	user     string
+	id       string
+	limit    int
+	headers  bool
+	org      organization
+	taskType string
 }
 
 func taskFindCmd(opt genericCLIOpts) *cobra.Command {
@@ -135,6 +227,7 @@ func taskFindCmd(opt genericCLIOpts) *cobra.Command {
 	cmd.Flags().StringVarP(&taskFindFlags.user, "user-id", "n", "", "task owner ID")
 	cmd.Flags().IntVarP(&taskFindFlags.limit, "limit", "", influxdb.TaskDefaultPageSize, "the number of tasks to find")
 	cmd.Flags().BoolVar(&taskFindFlags.headers, "headers", true, "To print the table headers; defaults true")
+	cmd.Flags().StringVar(&taskFindFlags.taskType, "type", "", "task type; downsampling finds the tasks of bucket downsampling policies")
 
 	return cmd
 }
@@ -179,6 +272,10 @@ func taskFindF(cmd *cobra.Command, args []string) error {
 	}
 	filter.Limit = taskFindFlags.limit
 
+	if taskFindFlags.taskType != "" {
+		filter.Type = &taskFindFlags.taskType
+	}
+
 	var tasks []http.Task
 
 	if taskFindFlags.id != "" {
@@ -465,6 +562,9 @@ var taskRunFindFlags struct {
 	afterTime  string
 	beforeTime string
 	limit      int
+
+	minRowsWritten int64
+	maxRowsWritten int64
 }
 
 func taskRunFindCmd(opt genericCLIOpts) *cobra.Command {
@@ -476,6 +576,8 @@ func taskRunFindCmd(opt genericCLIOpts) *cobra.Command {
 	cmd.Flags().StringVarP(&taskRunFindFlags.afterTime, "after", "", "", "after time for filtering")
 	cmd.Flags().StringVarP(&taskRunFindFlags.beforeTime, "before", "", "", "before time for filtering")
 	cmd.Flags().IntVarP(&taskRunFindFlags.limit, "limit", "", 0, "limit the results")
+	cmd.Flags().Int64VarP(&taskRunFindFlags.minRowsWritten, "min-rows-written", "", -1, "only find runs that wrote at least this many rows")
+	cmd.Flags().Int64VarP(&taskRunFindFlags.maxRowsWritten, "max-rows-written", "", -1, "only find runs that wrote at most this many rows")
 
 	cmd.MarkFlagRequired("task-id")
 
@@ -503,6 +605,12 @@ func taskRunFindF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	filter.Task = *taskID
+	if taskRunFindFlags.minRowsWritten >= 0 {
+		filter.MinRowsWritten = &taskRunFindFlags.minRowsWritten
+	}
+	if taskRunFindFlags.maxRowsWritten >= 0 {
+		filter.MaxRowsWritten = &taskRunFindFlags.maxRowsWritten
+	}
 
 	var runs []*influxdb.Run
 	if taskRunFindFlags.runID != "" {
@@ -531,6 +639,8 @@ func taskRunFindF(cmd *cobra.Command, args []string) error {
 		"StartedAt",
 		"FinishedAt",
 		"RequestedAt",
+		"TaskVersion",
+		"RowsWritten",
 	)
 
 	for _, r := range runs {
@@ -538,6 +648,10 @@ func taskRunFindF(cmd *cobra.Command, args []string) error {
 		startedAt := r.StartedAt.Format(time.RFC3339Nano)
 		finishedAt := r.FinishedAt.Format(time.RFC3339Nano)
 		requestedAt := r.RequestedAt.Format(time.RFC3339Nano)
+		var rowsWritten string
+		if r.Statistics != nil {
+			rowsWritten = fmt.Sprintf("%d", r.Statistics.RowsWritten)
+		}
 
 		w.Write(map[string]interface{}{
 			"ID":           r.ID,
@@ -547,6 +661,8 @@ func taskRunFindF(cmd *cobra.Command, args []string) error {
 			"StartedAt":    startedAt,
 			"FinishedAt":   finishedAt,
 			"RequestedAt":  requestedAt,
+			"TaskVersion":  r.TaskVersion,
+			"RowsWritten":  rowsWritten,
 		})
 	}
 	w.Flush()
@@ -599,3 +715,401 @@ func runRetryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func taskBackfillCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("backfill", nil)
+	cmd.Run = seeHelp
+	cmd.Short = "backfill a task over a time range"
+	cmd.AddCommand(
+		taskBackfillCreateCmd(opt),
+		taskBackfillFindCmd(opt),
+		taskBackfillCancelCmd(opt),
+	)
+
+	return cmd
+}
+
+var taskBackfillCreateFlags struct {
+	taskID      string
+	start       string
+	stop        string
+	concurrency int
+}
+
+func taskBackfillCreateCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("create", taskBackfillCreateF)
+	cmd.Short = "run a task for every time it is scheduled for in a time range"
+
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.start, "start", "", "", "start of the time range, in RFC3339 format (required)")
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.stop, "stop", "", "", "stop of the time range, in RFC3339 format (required)")
+	cmd.Flags().IntVarP(&taskBackfillCreateFlags.concurrency, "concurrency", "", 0, "number of runs to execute at once")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("stop")
+
+	return cmd
+}
+
+func taskBackfillCreateF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskBackfillService(taskBackfillCreateFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.RFC3339, taskBackfillCreateFlags.start)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %v", err)
+	}
+	stop, err := time.Parse(time.RFC3339, taskBackfillCreateFlags.stop)
+	if err != nil {
+		return fmt.Errorf("invalid stop time: %v", err)
+	}
+
+	b, err := s.CreateBackfill(context.Background(), influxdb.BackfillCreate{
+		TaskID:      s.TaskID,
+		Start:       start,
+		Stop:        stop,
+		Concurrency: taskBackfillCreateFlags.concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	printBackfills(b)
+	return nil
+}
+
+var taskBackfillFindFlags struct {
+	taskID string
+	id     string
+	status string
+}
+
+func taskBackfillFindCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("find", taskBackfillFindF)
+	cmd.Short = "find backfills of a task"
+
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.id, "id", "", "", "backfill id")
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.status, "status", "", "", "backfill status for filtering")
+	cmd.MarkFlagRequired("task-id")
+
+	return cmd
+}
+
+func taskBackfillFindF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskBackfillService(taskBackfillFindFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	var backfills []*influxdb.Backfill
+	if taskBackfillFindFlags.id != "" {
+		id, err := influxdb.IDFromString(taskBackfillFindFlags.id)
+		if err != nil {
+			return err
+		}
+		b, err := s.FindBackfillByID(context.Background(), *id)
+		if err != nil {
+			return err
+		}
+		backfills = append(backfills, b)
+	} else {
+		filter := influxdb.BackfillFilter{TaskID: &s.TaskID}
+		if taskBackfillFindFlags.status != "" {
+			filter.Status = &taskBackfillFindFlags.status
+		}
+		backfills, err = s.FindBackfills(context.Background(), filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	printBackfills(backfills...)
+	return nil
+}
+
+var taskBackfillCancelFlags struct {
+	taskID string
+	id     string
+}
+
+func taskBackfillCancelCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("cancel", taskBackfillCancelF)
+	cmd.Short = "cancel a running backfill"
+
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.id, "id", "", "", "backfill id (required)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func taskBackfillCancelF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskBackfillService(taskBackfillCancelFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	id, err := influxdb.IDFromString(taskBackfillCancelFlags.id)
+	if err != nil {
+		return err
+	}
+
+	b, err := s.CancelBackfill(context.Background(), *id)
+	if err != nil {
+		return err
+	}
+
+	printBackfills(b)
+	return nil
+}
+
+func newTaskBackfillService(taskID string) (*http.TaskBackfillService, error) {
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := influxdb.IDFromString(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.TaskBackfillService{
+		Client: client,
+		TaskID: *id,
+	}, nil
+}
+
+func printBackfills(backfills ...*influxdb.Backfill) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"TaskID",
+		"Status",
+		"Start",
+		"Stop",
+		"Concurrency",
+		"Total",
+		"Completed",
+		"Failed",
+		"Cursor",
+	)
+
+	for _, b := range backfills {
+		w.Write(map[string]interface{}{
+			"ID":          b.ID,
+			"TaskID":      b.TaskID,
+			"Status":      b.Status,
+			"Start":       b.Start.Format(time.RFC3339),
+			"Stop":        b.Stop.Format(time.RFC3339),
+			"Concurrency": b.Concurrency,
+			"Total":       b.Total,
+			"Completed":   b.Completed,
+			"Failed":      b.Failed,
+			"Cursor":      b.Cursor.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+}
+
+func taskVersionCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("version", nil)
+	cmd.Run = seeHelp
+	cmd.Short = "version history of a task's script"
+	cmd.AddCommand(
+		taskVersionFindCmd(opt),
+		taskVersionDiffCmd(opt),
+		taskVersionRollbackCmd(opt),
+	)
+
+	return cmd
+}
+
+var taskVersionFindFlags struct {
+	taskID  string
+	version int
+	flux    bool
+}
+
+func taskVersionFindCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("find", taskVersionFindF)
+	cmd.Short = "find versions of a task"
+
+	cmd.Flags().StringVarP(&taskVersionFindFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().IntVarP(&taskVersionFindFlags.version, "version", "", 0, "version number")
+	cmd.Flags().BoolVarP(&taskVersionFindFlags.flux, "flux", "", false, "print the script of the version")
+	cmd.MarkFlagRequired("task-id")
+
+	return cmd
+}
+
+func taskVersionFindF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskVersionService()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := influxdb.IDFromString(taskVersionFindFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	var versions []*influxdb.TaskVersion
+	if taskVersionFindFlags.version != 0 {
+		v, err := s.FindTaskVersion(context.Background(), *taskID, taskVersionFindFlags.version)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, v)
+	} else {
+		versions, err = s.FindTaskVersions(context.Background(), influxdb.TaskVersionFilter{TaskID: *taskID})
+		if err != nil {
+			return err
+		}
+	}
+
+	if taskVersionFindFlags.flux && len(versions) == 1 {
+		fmt.Println(versions[0].Flux)
+		return nil
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"TaskID",
+		"Version",
+		"Name",
+		"Every",
+		"Cron",
+		"AuthorID",
+		"CreatedAt",
+	)
+
+	for _, v := range versions {
+		authorID := ""
+		if v.AuthorID.Valid() {
+			authorID = v.AuthorID.String()
+		}
+
+		w.Write(map[string]interface{}{
+			"TaskID":    v.TaskID,
+			"Version":   v.Version,
+			"Name":      v.Name,
+			"Every":     v.Every,
+			"Cron":      v.Cron,
+			"AuthorID":  authorID,
+			"CreatedAt": v.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+
+	return nil
+}
+
+var taskVersionDiffFlags struct {
+	taskID string
+	from   int
+	to     int
+}
+
+func taskVersionDiffCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("diff", taskVersionDiffF)
+	cmd.Short = "compare the scripts of two versions of a task"
+
+	cmd.Flags().StringVarP(&taskVersionDiffFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().IntVarP(&taskVersionDiffFlags.from, "from", "", 0, "version to compare from, defaults to the version before --to")
+	cmd.Flags().IntVarP(&taskVersionDiffFlags.to, "to", "", 0, "version to compare (required)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func taskVersionDiffF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskVersionService()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := influxdb.IDFromString(taskVersionDiffFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	d, err := s.DiffTaskVersions(context.Background(), *taskID, taskVersionDiffFlags.from, taskVersionDiffFlags.to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(d.Diff)
+	return nil
+}
+
+var taskVersionRollbackFlags struct {
+	taskID  string
+	version int
+}
+
+func taskVersionRollbackCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("rollback", taskVersionRollbackF)
+	cmd.Short = "update a task with the script of one of its versions"
+
+	cmd.Flags().StringVarP(&taskVersionRollbackFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().IntVarP(&taskVersionRollbackFlags.version, "version", "", 0, "version to roll back to (required)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+func taskVersionRollbackF(cmd *cobra.Command, args []string) error {
+	s, err := newTaskVersionService()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := influxdb.IDFromString(taskVersionRollbackFlags.taskID)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.RollbackTask(context.Background(), *taskID, taskVersionRollbackFlags.version)
+	if err != nil {
+		return err
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Version",
+		"Status",
+		"Every",
+		"Cron",
+	)
+	w.Write(map[string]interface{}{
+		"ID":      t.ID.String(),
+		"Name":    t.Name,
+		"Version": t.Version,
+		"Status":  t.Status,
+		"Every":   t.Every,
+		"Cron":    t.Cron,
+	})
+	w.Flush()
+
+	return nil
+}
+
+func newTaskVersionService() (*http.TaskVersionService, error) {
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.TaskVersionService{
+		Client: client,
+	}, nil
+}
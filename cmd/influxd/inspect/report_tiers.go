@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// reportTiersFlags defines the `report-tiers` Command.
+var reportTiersFlags = struct {
+	dataDir string
+	coldDir string
+	coldAge time.Duration
+}{}
+
+func NewReportTiersCommand() *cobra.Command {
+	reportTiersCommand := &cobra.Command{
+		Use:   "report-tiers",
+		Short: "Report the TSM files of the hot and cold storage tiers",
+		Long: `
+This command reports the TSM files in the hot tier (the engine data directory)
+and in the cold tier, the directory fully compacted files are moved to once all
+of their data is older than the cold age.
+
+For each file, the following is output:
+
+	* The filename;
+	* The tier and compaction level of the file;
+	* The size of the file and its tombstones in bytes;
+	* The min and max timestamp associated with TSM data in the file; and
+	* Whether the file is due to move to the cold tier.
+
+The summary section then outputs the number of files, size and time range of
+each tier.`,
+		RunE: inspectReportTiersF,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	dir = filepath.Join(dir, "engine/data")
+	reportTiersCommand.Flags().StringVarP(&reportTiersFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
+	reportTiersCommand.Flags().StringVarP(&reportTiersFlags.coldDir, "cold-dir", "", "", "cold tier directory, as set by --engine-cold-path (required).")
+	reportTiersCommand.Flags().DurationVarP(&reportTiersFlags.coldAge, "cold-age", "", time.Duration(tsm1.DefaultTieringColdAge), "age of the newest data in a file before it is due to move to the cold tier.")
+
+	return reportTiersCommand
+}
+
+// inspectReportTiersF runs the report-tiers tool.
+func inspectReportTiersF(cmd *cobra.Command, args []string) error {
+	if reportTiersFlags.coldDir == "" {
+		return errors.New("cold-dir must be set")
+	}
+
+	report := &tsm1.TierReport{
+		Stderr:  os.Stderr,
+		Stdout:  os.Stdout,
+		Dir:     reportTiersFlags.dataDir,
+		ColdDir: reportTiersFlags.coldDir,
+		ColdAge: reportTiersFlags.coldAge,
+	}
+
+	_, _, err := report.Run(true)
+	return err
+}
@@ -0,0 +1,316 @@
+package launcher_test
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/alert"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/query"
+)
+
+// previousStatus is a status that an earlier evaluation of a check wrote.
+type previousStatus struct {
+	level string
+	value float64
+}
+
+// TestLauncher_StatefulThresholdCheck runs the scripts of threshold checks
+// over a value between the value and the exit value of their threshold,
+// with the status that their previous evaluation wrote.
+func TestLauncher_StatefulThresholdCheck(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	// onboarding doesn't create the monitoring bucket that the checks write to.
+	monitoring := &influxdb.Bucket{
+		OrgID:           l.Org.ID,
+		Type:            influxdb.BucketTypeSystem,
+		Name:            influxdb.MonitoringSystemBucketName,
+		RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
+	}
+	if err := l.Launcher.BucketService().CreateBucket(ctx, monitoring); err != nil {
+		t.Fatal(err)
+	}
+
+	exit := 80.0
+	withExit := func(t *check.Threshold) {
+		t.Thresholds[0].(*check.Greater).ExitValue = &exit
+	}
+	tests := []struct {
+		name      string
+		threshold func(t *check.Threshold)
+		previous  *previousStatus
+		value     float64
+		level     string
+	}{
+		{
+			name:      "without state",
+			threshold: func(t *check.Threshold) {},
+			previous:  &previousStatus{level: "crit", value: 95},
+			value:     85,
+			level:     "ok",
+		},
+		{
+			name:      "exit value at the level",
+			threshold: withExit,
+			previous:  &previousStatus{level: "crit", value: 95},
+			value:     85,
+			level:     "crit",
+		},
+		{
+			name:      "exit value at another level",
+			threshold: withExit,
+			previous:  &previousStatus{level: "ok", value: 75},
+			value:     85,
+			level:     "ok",
+		},
+		{
+			name:      "exit value without previous status",
+			threshold: withExit,
+			value:     85,
+			level:     "ok",
+		},
+		{
+			name: "for evaluations held",
+			threshold: func(t *check.Threshold) {
+				t.ForEvaluations = 2
+			},
+			previous: &previousStatus{level: "ok", value: 95},
+			value:    95,
+			level:    "crit",
+		},
+		{
+			name: "for evaluations not held",
+			threshold: func(t *check.Threshold) {
+				t.ForEvaluations = 2
+			},
+			previous: &previousStatus{level: "ok", value: 85},
+			value:    95,
+			level:    "ok",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := influxdb.ID(i + 1)
+			host := fmt.Sprintf("host%d", i)
+			now := time.Now()
+
+			l.WritePointsOrFail(t, fmt.Sprintf("cpu,host=%s usage_user=%v %d", host, tt.value, now.Add(-10*time.Second).UnixNano()))
+			if p := tt.previous; p != nil {
+				writeOrFail(t, l, monitoring.ID, fmt.Sprintf(
+					`statuses,_check_id=%s,_check_name=cpu,_level=%s,_source_measurement=cpu,_type=threshold,host=%s _message="cpu",_source_timestamp=%di,usage_user=%v %d`,
+					id, p.level, host, now.Add(-70*time.Second).UnixNano(), p.value, now.Add(-time.Minute).UnixNano(),
+				))
+			}
+
+			chk := newThresholdCheck(t, id, l, host)
+			tt.threshold(chk)
+			if err := chk.Valid(); err != nil {
+				t.Fatal(err)
+			}
+			script, err := chk.GenerateFlux()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			statuses := runCheck(t, l, script)
+			if len(statuses) != 1 {
+				t.Fatalf("expected a single status, got %v", statuses)
+			}
+			status := statuses[0]
+			if got := status["_level"]; got != tt.level {
+				t.Errorf("expected level %s, got %v", tt.level, got)
+			}
+			if got := status["usage_user"]; got != tt.value {
+				t.Errorf("expected the status of the value %v, got %v", tt.value, got)
+			}
+			if got := status["_source_measurement"]; got != "cpu" {
+				t.Errorf("expected the status of the measurement cpu, got %v", got)
+			}
+			for _, col := range []string{"_previous_level", "_current", "_crit_count"} {
+				if _, ok := status[col]; ok {
+					t.Errorf("expected the state column %s not to be written with the status, got %v", col, status)
+				}
+			}
+		})
+	}
+}
+
+func newThresholdCheck(t *testing.T, id influxdb.ID, l *launcher.TestLauncher, host string) *check.Threshold {
+	t.Helper()
+	every, err := parser.ParseDuration("1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &check.Threshold{
+		Base: check.Base{
+			ID:                    id,
+			Name:                  "cpu",
+			OrgID:                 l.Org.ID,
+			OwnerID:               l.User.ID,
+			Every:                 (*notification.Duration)(every),
+			StatusMessageTemplate: "cpu is {r.usage_user}",
+			Query: influxdb.DashboardQuery{
+				Text: fmt.Sprintf(`from(bucket: "%s") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user" and r.host == "%s")`, l.Bucket.Name, host),
+			},
+		},
+		Thresholds: []check.ThresholdConfig{
+			&check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical},
+				Value:               90,
+			},
+		},
+	}
+}
+
+// writeOrFail writes line protocol to a bucket of the organization of the launcher.
+func writeOrFail(t *testing.T, l *launcher.TestLauncher, bucketID influxdb.ID, data string) {
+	t.Helper()
+	req := l.NewHTTPRequestOrFail(t, "POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", l.Org.ID, bucketID), l.Auth.Token, data)
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+}
+
+// runCheck runs the script of a check, and returns the statuses it wrote.
+func runCheck(t *testing.T, l *launcher.TestLauncher, script string) []map[string]interface{} {
+	t.Helper()
+	ctx := icontext.SetAuthorizer(context.Background(), &mock.Authorization{})
+	q, err := l.QueryController().Query(ctx, &query.Request{
+		Authorization:  l.Auth,
+		OrganizationID: l.Org.ID,
+		Compiler:       lang.FluxCompiler{Query: script},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Done()
+
+	var statuses []map[string]interface{}
+	for res := range q.Results() {
+		err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					status := map[string]interface{}{}
+					for j, col := range cr.Cols() {
+						switch col.Type {
+						case flux.TString:
+							status[col.Label] = cr.Strings(j).ValueString(i)
+						case flux.TFloat:
+							status[col.Label] = cr.Floats(j).Value(i)
+						default:
+							status[col.Label] = nil
+						}
+					}
+					statuses = append(statuses, status)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return statuses
+}
+
+// TestLauncher_PreviewStatefulThresholdCheck previews a threshold check with
+// an exit value over values between its value and exit value, which keep the
+// level of the previous evaluation. The statuses in the monitoring bucket are
+// not read by the preview.
+func TestLauncher_PreviewStatefulThresholdCheck(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	monitoring := &influxdb.Bucket{
+		OrgID:           l.Org.ID,
+		Type:            influxdb.BucketTypeSystem,
+		Name:            influxdb.MonitoringSystemBucketName,
+		RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
+	}
+	if err := l.Launcher.BucketService().CreateBucket(ctx, monitoring); err != nil {
+		t.Fatal(err)
+	}
+
+	id := influxdb.ID(1)
+	start := time.Now().Truncate(time.Minute).Add(-10 * time.Minute)
+	for i, v := range []float64{95, 85, 85, 75} {
+		at := start.Add(time.Duration(i)*time.Minute + 30*time.Second)
+		l.WritePointsOrFail(t, fmt.Sprintf("cpu,host=a usage_user=%v %d", v, at.UnixNano()))
+		writeOrFail(t, l, monitoring.ID, fmt.Sprintf(
+			`statuses,_check_id=%s,_check_name=cpu,_level=ok,_source_measurement=cpu,_type=threshold,host=a _message="cpu",_source_timestamp=%di,usage_user=0 %d`,
+			id, at.UnixNano(), at.UnixNano(),
+		))
+	}
+
+	exit := 80.0
+	chk := newThresholdCheck(t, id, l, "a")
+	chk.Thresholds[0].(*check.Greater).ExitValue = &exit
+	if err := chk.Valid(); err != nil {
+		t.Fatal(err)
+	}
+
+	previewer := alert.NewPreviewer(query.QueryServiceBridge{AsyncQueryService: l.QueryController()})
+	started, err := previewer.PreviewCheck(icontext.SetAuthorizer(ctx, l.Auth), influxdb.CheckPreview{
+		Check: chk,
+		Start: start.Add(time.Minute),
+		Stop:  start.Add(4 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res *influxdb.CheckPreviewResult
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if res, err = previewer.FindCheckPreviewByID(ctx, started.ID); err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != influxdb.CheckPreviewRunning {
+			break
+		}
+	}
+	if res.Status != influxdb.CheckPreviewSuccess {
+		t.Fatalf("expected the preview to succeed, got %+v", res)
+	}
+
+	type transition struct {
+		at            time.Time
+		previous, now string
+	}
+	var got []transition
+	for _, tr := range res.Transitions {
+		got = append(got, transition{at: tr.Time, previous: tr.PreviousLevel, now: tr.Level})
+	}
+	want := []transition{
+		{at: start.Add(time.Minute), previous: "", now: "CRIT"},
+		{at: start.Add(4 * time.Minute), previous: "CRIT", now: "OK"},
+	}
+	if !cmp.Equal(want, got, cmp.AllowUnexported(transition{})) {
+		t.Fatalf("unexpected transitions -want/+got:\n%s", cmp.Diff(want, got, cmp.AllowUnexported(transition{})))
+	}
+}
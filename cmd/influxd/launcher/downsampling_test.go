@@ -0,0 +1,47 @@
+package launcher_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+)
+
+// TestLauncher_DownsamplingPolicy sets a downsampling policy on a bucket, and
+// waits for its task to write the downsampled data to the destination bucket.
+func TestLauncher_DownsamplingPolicy(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	rollup := &influxdb.Bucket{OrgID: l.Org.ID, Name: "rollup"}
+	if err := l.BucketService(t).CreateBucket(ctx, rollup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.BucketService(t).UpdateBucket(ctx, l.Bucket.ID, influxdb.BucketUpdate{
+		DownsamplingPolicies: &[]influxdb.DownsamplingPolicy{{
+			DestinationBucketID: rollup.ID,
+			Every:               influxdb.Duration{Duration: time.Second},
+			Aggregates:          map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "max"},
+		}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := fmt.Sprintf(`from(bucket: "%s") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "cpu")`, rollup.Name)
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		// Each run only downsamples the data of the last second.
+		l.WritePointsOrFail(t, fmt.Sprintf("cpu usage=42 %d", time.Now().UnixNano()))
+		if res := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, query); strings.Contains(res, "usage") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the task of the downsampling policy didn't write to the destination bucket within the deadline")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
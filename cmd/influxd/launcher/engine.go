@@ -31,6 +31,7 @@ type Engine interface {
 	storage.BucketDeleter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.CardinalityService
 
 	SeriesCardinality() int64
 
@@ -115,6 +116,11 @@ func (t *TemporaryEngine) SeriesCardinality() int64 {
 	return t.engine.SeriesCardinality()
 }
 
+// BucketCardinality returns the series cardinality of a bucket.
+func (t *TemporaryEngine) BucketCardinality(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error) {
+	return t.engine.BucketCardinality(ctx, orgID, bucketID, opt)
+}
+
 // DeleteBucketRangePredicate will delete a bucket from the range and predicate.
 func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
@@ -34,6 +34,10 @@ import (
 	"github.com/influxdata/influxdb/kv"
 	influxlogger "github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/nats"
+	"github.com/influxdata/influxdb/notification/alert"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/escalation"
+	"github.com/influxdata/influxdb/notification/grouping"
 	"github.com/influxdata/influxdb/pkger"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
@@ -45,13 +49,15 @@ import (
 	"github.com/influxdata/influxdb/storage/reads"
 	"github.com/influxdata/influxdb/storage/readservice"
 	taskbackend "github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/backfill"
 	"github.com/influxdata/influxdb/task/backend/coordinator"
 	"github.com/influxdata/influxdb/task/backend/executor"
 	"github.com/influxdata/influxdb/task/backend/middleware"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
 	"github.com/influxdata/influxdb/telemetry"
+	"github.com/influxdata/influxdb/toml"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/influxdata/influxdb/vault"
 	pzap "github.com/influxdata/influxdb/zap"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -174,6 +180,41 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP: &l.engineColdPath,
+			Flag:  "engine-cold-path",
+			Desc:  "path to move TSM files to once all of their data is older than engine-cold-age; disabled if empty",
+		},
+		{
+			DestP:   &l.engineColdAge,
+			Flag:    "engine-cold-age",
+			Default: time.Duration(tsm1.DefaultTieringColdAge),
+			Desc:    "age of the newest data in a TSM file before it is moved to engine-cold-path",
+		},
+		{
+			DestP:   &l.taskRetryBackoff,
+			Flag:    "task-retry-backoff",
+			Default: executor.DefaultRetryBackoff,
+			Desc:    "time to wait before retrying a failed task run, doubled with each further retry",
+		},
+		{
+			DestP:   &l.taskMaxRetryBackoff,
+			Flag:    "task-max-retry-backoff",
+			Default: executor.DefaultMaxRetryBackoff,
+			Desc:    "longest time to wait between retries of a failed task run",
+		},
+		{
+			DestP:   &l.taskSchedulerNodeID,
+			Flag:    "task-scheduler-node-id",
+			Default: "",
+			Desc:    "ID of this node among the nodes that divide the scheduling of tasks between them through the leases in the metadata store; the bolt and in-memory stores are opened by a single influxd, so this node holds every lease with them; tasks are scheduled without leases when empty",
+		},
+		{
+			DestP:   &l.taskSchedulerPartitions,
+			Flag:    "task-scheduler-partitions",
+			Default: coordinator.DefaultPartitions,
+			Desc:    "number of partitions that tasks are divided into between the nodes that schedule them; must be the same on every node",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
@@ -286,8 +327,15 @@ type Launcher struct {
 	httpBindAddress string
 	boltPath        string
 	enginePath      string
+	engineColdPath  string
+	engineColdAge   time.Duration
 	secretStore     string
 
+	taskRetryBackoff        time.Duration
+	taskMaxRetryBackoff     time.Duration
+	taskSchedulerNodeID     string
+	taskSchedulerPartitions int
+
 	boltClient    *bolt.Client
 	kvService     *kv.Service
 	engine        Engine
@@ -304,8 +352,11 @@ type Launcher struct {
 	natsPort   int
 
 	scheduler          *scheduler.TreeScheduler
+	taskScheduler      scheduler.Scheduler
+	leasedScheduler    *coordinator.LeasedScheduler
 	executor           *executor.Executor
 	taskControlService taskbackend.TaskControlService
+	backfillService    *backfill.Service
 
 	jaegerTracerCloser io.Closer
 	log                *zap.Logger
@@ -324,6 +375,10 @@ func NewLauncher() *Launcher {
 		Stdout:        os.Stdout,
 		Stderr:        os.Stderr,
 		StorageConfig: storage.NewConfig(),
+
+		taskRetryBackoff:        executor.DefaultRetryBackoff,
+		taskMaxRetryBackoff:     executor.DefaultMaxRetryBackoff,
+		taskSchedulerPartitions: coordinator.DefaultPartitions,
 	}
 }
 
@@ -369,7 +424,13 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 
 	m.log.Info("Stopping", zap.String("service", "task"))
 
+	if m.leasedScheduler != nil {
+		if err := m.leasedScheduler.Close(ctx); err != nil {
+			m.log.Info("Failed releasing task partitions", zap.Error(err))
+		}
+	}
 	m.scheduler.Stop()
+	m.backfillService.Close()
 
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
@@ -537,6 +598,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		secretSvc                 platform.SecretService                   = m.kvService
 		lookupSvc                 platform.LookupService                   = m.kvService
 		notificationEndpointStore platform.NotificationEndpointService     = m.kvService
+		measurementSchemaSvc      platform.MeasurementSchemaService        = m.kvService
 	)
 
 	switch m.secretStore {
@@ -563,13 +625,18 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	if m.engineColdPath != "" {
+		m.StorageConfig.Engine.Tiering.ColdPath = m.engineColdPath
+		m.StorageConfig.Engine.Tiering.ColdAge = toml.Duration(m.engineColdAge)
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, storage.WithSeriesLimits(bucketSvc), storage.WithRetentionEnforcer(bucketSvc))
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithSeriesLimits(bucketSvc), storage.WithRetentionEnforcer(bucketSvc))
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -585,6 +652,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		backupService platform.BackupService = m.engine
 	)
 
+	// Every writer of points, from HTTP writes to the to() calls of tasks, is
+	// held to the schemas of buckets with an explicit schema.
+	// Schemas are changed through the writer, so that it drops the schemas it
+	// cached.
+	schemaPointsWriter := storage.NewSchemaPointsWriter(pointsWriter, bucketSvc, measurementSchemaSvc)
+	pointsWriter, measurementSchemaSvc = schemaPointsWriter, schemaPointsWriter.SchemaService()
+
 	// TODO(cwolff): Figure out a good default per-query memory limit:
 	//   https://github.com/influxdata/influxdb/issues/13642
 	const (
@@ -595,7 +669,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	deps, err := influxdb.NewDependencies(
 		reads.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		pointsWriter,
 		authorizer.NewBucketService(bucketSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -621,11 +695,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	alertSvc := alert.NewService(m.kvService, m.kvService, query.QueryServiceBridge{AsyncQueryService: m.queryController})
+
 	var taskSvc platform.TaskService
 	{
 		// create the task stack
 		combinedTaskService := taskbackend.NewAnalyticalStorage(m.log.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
 
+		// tasks notify the failures of their runs to their notification endpoints.
+		notifier := executor.NewNotifier(notificationEndpointStore, endpoint.NewSender(secretSvc))
+
 		executor, executorMetrics := executor.NewExecutor(
 			m.log.With(zap.String("service", "task-executor")),
 			query.QueryServiceBridge{AsyncQueryService: m.queryController},
@@ -633,35 +712,93 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			combinedTaskService,
 			combinedTaskService,
 		)
+		executor.SetRetryBackoff(m.taskRetryBackoff, m.taskMaxRetryBackoff)
+		executor.SetNotifier(notifier)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
 		schLogger := m.log.With(zap.String("service", "task-scheduler"))
+		onSchedulerErr := func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
+			schLogger.Info(
+				"error in scheduler run",
+				zap.String("taskID", platform.ID(taskID).String()),
+				zap.Time("scheduledAt", scheduledAt),
+				zap.Error(err))
+		}
+
+		// the runs of tasks that depend on other tasks wait until the runs
+		// of those tasks for the same time succeed.
+		deps := scheduler.NewDependencyExecutor(executor, executor, taskbackend.NewSchedulableTaskService(m.kvService), onSchedulerErr)
+		// the alerts of notification rules are escalated by their policies
+		// after each run of their tasks.
+		escalator := escalation.NewEscalator(m.kvService, m.kvService, m.kvService, alertSvc, m.kvService, notificationEndpointStore, endpoint.NewSender(secretSvc))
+		escalationLogger := m.log.With(zap.String("service", "escalation"))
+		// the statuses of grouped notification rules are sent in groups
+		// after each run of their tasks.
+		grouper := grouping.NewGrouper(m.kvService, m.kvService, bucketSvc, query.QueryServiceBridge{AsyncQueryService: m.queryController}, notificationEndpointStore, endpoint.NewSender(secretSvc))
+		groupingLogger := m.log.With(zap.String("service", "notification-grouping"))
+		executor.SetFinishFunc(func(task *platform.Task, run *platform.Run, rs taskbackend.RunStatus, err error) {
+			if rs == taskbackend.RunSuccess {
+				deps.Succeeded(scheduler.ID(task.ID), run.ScheduledFor)
+				go func() {
+					ctx, cancel := context.WithTimeout(ctx, escalation.Timeout)
+					defer cancel()
+					if err := escalator.Escalate(ctx, task); err != nil {
+						escalationLogger.Error("Failed to escalate alerts", zap.String("taskID", task.ID.String()), zap.Error(err))
+					}
+				}()
+				go func() {
+					ctx, cancel := context.WithTimeout(ctx, grouping.Timeout)
+					defer cancel()
+					if err := grouper.Group(ctx, task, run); err != nil {
+						groupingLogger.Error("Failed to send grouped notifications", zap.String("taskID", task.ID.String()), zap.Error(err))
+					}
+				}()
+			}
+		})
+
+		// nodes that share the metadata store only schedule the tasks of
+		// the partitions they hold the leases of.
+		var leases *coordinator.Leases
+		var schExecutor scheduler.Executor = deps
+		if m.taskSchedulerNodeID != "" {
+			leases = coordinator.NewLeases(
+				m.log.With(zap.String("service", "task-leases")),
+				m.taskSchedulerNodeID,
+				m.kvService,
+				m.taskSchedulerPartitions,
+				coordinator.DefaultLeaseTTL)
+			schExecutor = coordinator.NewLeasedExecutor(leases, deps)
+		}
 
 		sch, sm, err := scheduler.NewScheduler(
-			executor,
+			schExecutor,
 			taskbackend.NewSchedulableTaskService(m.kvService),
-			scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
-				schLogger.Info(
-					"error in scheduler run",
-					zap.String("taskID", platform.ID(taskID).String()),
-					zap.Time("scheduledAt", scheduledAt),
-					zap.Error(err))
-			}),
+			scheduler.WithOnErrorFn(onSchedulerErr),
 		)
 		if err != nil {
 			m.log.Fatal("could not start task scheduler", zap.Error(err))
 		}
 		m.scheduler = sch
+		m.taskScheduler = sch
+		if leases != nil {
+			m.leasedScheduler = coordinator.NewLeasedScheduler(schLogger, leases, sch, m.kvService, deps)
+			m.taskScheduler = m.leasedScheduler
+		}
 		m.reg.MustRegister(sm.PrometheusCollectors()...)
 		coordLogger := m.log.With(zap.String("service", "task-coordinator"))
 		taskCoord := coordinator.NewCoordinator(
 			coordLogger,
-			sch,
-			executor)
+			m.taskScheduler,
+			executor,
+			coordinator.WithDependencies(deps))
 
 		taskSvc = middleware.New(combinedTaskService, taskCoord)
 		m.taskControlService = combinedTaskService
-		if err := taskbackend.TaskNotifyCoordinatorOfExisting(
+		if m.leasedScheduler != nil {
+			// the tasks of the partitions of this node are scheduled from
+			// their checkpoints as their leases are acquired.
+			m.leasedScheduler.Open(ctx)
+		} else if err := taskbackend.TaskNotifyCoordinatorOfExisting(
 			ctx,
 			taskSvc,
 			combinedTaskService,
@@ -673,20 +810,40 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			coordLogger); err != nil {
 			m.log.Error("Failed to resume existing tasks", zap.Error(err))
 		}
+		// the runs held back for runs that succeeded before a restart are
+		// released once the dependencies of the scheduled tasks are set.
+		if err := deps.Open(ctx); err != nil {
+			m.log.Error("Failed to restore the succeeded runs of upstream tasks", zap.Error(err))
+		}
+
+		// backfills force runs without the coordinator, which would execute them on the live schedule.
+		m.backfillService = backfill.New(m.log.With(zap.String("service", "task-backfill")), m.kvService, combinedTaskService, executor)
+		if err := m.backfillService.Resume(ctx); err != nil {
+			m.log.Error("Failed to resume task backfills", zap.Error(err))
+		}
 	}
 
 	var checkSvc platform.CheckService
 	{
-		coordinator := coordinator.NewCoordinator(m.log, m.scheduler, m.executor)
+		coordinator := coordinator.NewCoordinator(m.log, m.taskScheduler, m.executor)
 		checkSvc = middleware.NewCheckService(m.kvService, m.kvService, coordinator)
 	}
 
 	var notificationRuleSvc platform.NotificationRuleStore
 	{
-		coordinator := coordinator.NewCoordinator(m.log, m.scheduler, m.executor)
+		coordinator := coordinator.NewCoordinator(m.log, m.taskScheduler, m.executor)
 		notificationRuleSvc = middleware.NewNotificationRuleStore(m.kvService, m.kvService, coordinator)
 	}
 
+	// the tasks of downsampling policies change with their buckets, and with
+	// the schemas of their buckets.
+	var downsamplingBucketSvc platform.BucketService
+	{
+		coordinator := coordinator.NewCoordinator(m.log, m.taskScheduler, m.executor)
+		downsamplingBucketSvc = middleware.NewBucketService(bucketSvc, m.kvService, coordinator)
+		measurementSchemaSvc = middleware.NewMeasurementSchemaService(measurementSchemaSvc, bucketSvc, m.kvService, coordinator)
+	}
+
 	// NATS streaming server
 	natsOpts := nats.NewDefaultServerOptions()
 
@@ -775,10 +932,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		PointsWriter:         pointsWriter,
 		DeleteService:        deleteService,
 		BackupService:        backupService,
+		CardinalityService:   storage.NewCardinalityService(m.log.With(zap.String("service", "cardinality")), m.engine, m.kvService),
 		KVBackupService:      m.kvService,
 		AuthorizationService: authSvc,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketService:                   storage.NewBucketService(downsamplingBucketSvc, m.engine),
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
@@ -798,14 +956,25 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		TaskService:                     taskSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
+		NotificationRuleDryRunService:   alert.NewDryRunner(alertSvc, m.kvService, m.kvService),
 		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
+		NotificationEndpointTestService: endpoint.NewTester(notificationEndpointStore, endpoint.NewSender(secretSvc)),
 		CheckService:                    checkSvc,
+		CheckPreviewService:             alert.NewPreviewer(query.QueryServiceBridge{AsyncQueryService: m.queryController}),
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
 		SecretService:                   secretSvc,
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
+		MeasurementSchemaService:        measurementSchemaSvc,
+		TaskBackfillService:             m.backfillService,
+		TaskDryRunService:               m.executor,
+		TaskVersionService:              m.kvService,
+		SilenceService:                  m.kvService,
+		AlertService:                    alertSvc,
+		AlertAcknowledgementService:     m.kvService,
+		EscalationPolicyService:         m.kvService,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
 	}
@@ -830,6 +999,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			pkger.WithTaskSVC(authorizer.NewTaskService(pkgerLogger, b.TaskService)),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
 			pkger.WithVariableSVC(authorizer.NewVariableService(b.VariableService)),
+			pkger.WithMeasurementSchemaSVC(authorizer.NewMeasurementSchemaService(b.MeasurementSchemaService)),
 		)
 		pkgSVC = pkger.MWTracing()(pkgSVC)
 		pkgSVC = pkger.MWMetrics(m.reg)(pkgSVC)
@@ -0,0 +1,109 @@
+package influxdb
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// downsamplingAggregates are the aggregate functions a downsampling policy may
+// apply to fields of each data type.
+var downsamplingAggregates = map[SchemaColumnDataType][]string{
+	SchemaColumnDataTypeFloat:    {"mean", "median", "sum", "min", "max", "first", "last", "count"},
+	SchemaColumnDataTypeInteger:  {"mean", "median", "sum", "min", "max", "first", "last", "count"},
+	SchemaColumnDataTypeUnsigned: {"mean", "median", "sum", "min", "max", "first", "last", "count"},
+	SchemaColumnDataTypeString:   {"first", "last", "count"},
+	SchemaColumnDataTypeBoolean:  {"first", "last", "count"},
+}
+
+// DownsamplingPolicy aggregates the data of the bucket it is attached to into a
+// destination bucket. Each policy is run by a managed task of type
+// TaskDownsamplingType, which aggregates the previous Every of data once Offset
+// has passed, so that points arriving up to Offset late are included.
+//
+// Aggregates maps field data types to the aggregate function applied to fields
+// of that type. Field types are only known for buckets with an explicit schema;
+// fields of a bucket with an implicit schema are all aggregated with the same
+// function, so its aggregates must all name the same function. When that
+// function only applies to numbers, string and boolean fields of the bucket are
+// downsampled with last instead.
+type DownsamplingPolicy struct {
+	DestinationBucketID ID                              `json:"destinationBucketID"`
+	Every               Duration                        `json:"every"`
+	Offset              Duration                        `json:"offset"`
+	Aggregates          map[SchemaColumnDataType]string `json:"aggregates"`
+	TagFilter           map[string]string               `json:"tagFilter,omitempty"`
+	TaskID              ID                              `json:"taskID,omitempty"`
+}
+
+// Valid returns an error if the policy is malformed for a bucket with the
+// given schema type.
+func (p DownsamplingPolicy) Valid(schemaType SchemaType) error {
+	invalidPolicyErr := func(format string, args ...interface{}) error {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	if !p.DestinationBucketID.Valid() {
+		return invalidPolicyErr("downsampling policy requires a destination bucket")
+	}
+	if p.Every.Duration < time.Second {
+		return invalidPolicyErr("downsampling policy every must be at least 1s")
+	}
+	if p.Offset.Duration < 0 {
+		return invalidPolicyErr("downsampling policy offset cannot be negative")
+	}
+	if p.Every.Duration%time.Second != 0 || p.Offset.Duration%time.Second != 0 {
+		return invalidPolicyErr("downsampling policy every and offset must be whole seconds")
+	}
+	if len(p.Aggregates) == 0 {
+		return invalidPolicyErr("downsampling policy requires at least one aggregate")
+	}
+
+	for typ, fn := range p.Aggregates {
+		if err := typ.Valid(); err != nil {
+			return err
+		}
+		if !DownsamplingAggregateAllowed(typ, fn) {
+			return invalidPolicyErr("aggregate %q cannot be applied to %s fields", fn, typ)
+		}
+	}
+	if schemaType != SchemaTypeExplicit && len(p.AggregateFuncs()) > 1 {
+		return invalidPolicyErr("aggregates per field type require a bucket with an explicit schema")
+	}
+
+	for k := range p.TagFilter {
+		if k == "" {
+			return invalidPolicyErr("downsampling policy tag filter key is required")
+		}
+	}
+	return nil
+}
+
+// AggregateFuncs returns the distinct aggregate functions of the policy in
+// sorted order.
+func (p DownsamplingPolicy) AggregateFuncs() []string {
+	seen := make(map[string]bool, len(p.Aggregates))
+	fns := make([]string, 0, len(p.Aggregates))
+	for _, fn := range p.Aggregates {
+		if !seen[fn] {
+			seen[fn] = true
+			fns = append(fns, fn)
+		}
+	}
+	sort.Strings(fns)
+	return fns
+}
+
+// DownsamplingAggregateAllowed reports whether a downsampling policy may apply
+// the aggregate function fn to fields of data type typ.
+func DownsamplingAggregateAllowed(typ SchemaColumnDataType, fn string) bool {
+	for _, allowed := range downsamplingAggregates[typ] {
+		if fn == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestDownsamplingPolicy_Valid(t *testing.T) {
+	policy := func(aggregates map[influxdb.SchemaColumnDataType]string) influxdb.DownsamplingPolicy {
+		return influxdb.DownsamplingPolicy{
+			DestinationBucketID: 1,
+			Every:               influxdb.Duration{Duration: time.Hour},
+			Aggregates:          aggregates,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policy     influxdb.DownsamplingPolicy
+		schemaType influxdb.SchemaType
+		wantErr    bool
+	}{
+		{
+			name:   "valid",
+			policy: policy(map[influxdb.SchemaColumnDataType]string{"float": "mean"}),
+		},
+		{
+			name:       "aggregates per type",
+			policy:     policy(map[influxdb.SchemaColumnDataType]string{"float": "mean", "string": "last"}),
+			schemaType: influxdb.SchemaTypeExplicit,
+		},
+		{
+			name:    "aggregates per type without explicit schema",
+			policy:  policy(map[influxdb.SchemaColumnDataType]string{"float": "mean", "string": "last"}),
+			wantErr: true,
+		},
+		{
+			name:       "aggregate not allowed for type",
+			policy:     policy(map[influxdb.SchemaColumnDataType]string{"string": "mean"}),
+			schemaType: influxdb.SchemaTypeExplicit,
+			wantErr:    true,
+		},
+		{
+			name:    "unknown type",
+			policy:  policy(map[influxdb.SchemaColumnDataType]string{"decimal": "mean"}),
+			wantErr: true,
+		},
+		{
+			name:    "no aggregates",
+			policy:  policy(nil),
+			wantErr: true,
+		},
+		{
+			name: "every less than a second",
+			policy: influxdb.DownsamplingPolicy{
+				DestinationBucketID: 1,
+				Every:               influxdb.Duration{Duration: time.Millisecond},
+				Aggregates:          map[influxdb.SchemaColumnDataType]string{"float": "mean"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing destination",
+			policy: influxdb.DownsamplingPolicy{
+				Every:      influxdb.Duration{Duration: time.Hour},
+				Aggregates: map[influxdb.SchemaColumnDataType]string{"float": "mean"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Valid(tt.schemaType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: got %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
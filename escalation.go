@@ -0,0 +1,222 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ops for escalation policy errors.
+var (
+	OpFindEscalationPolicyByID = "FindEscalationPolicyByID"
+	OpFindEscalationPolicies   = "FindEscalationPolicies"
+	OpCreateEscalationPolicy   = "CreateEscalationPolicy"
+	OpUpdateEscalationPolicy   = "UpdateEscalationPolicy"
+	OpDeleteEscalationPolicy   = "DeleteEscalationPolicy"
+)
+
+// ErrEscalationPolicyNotFound is returned when an escalation policy does not exist.
+var ErrEscalationPolicyNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "escalation policy not found",
+}
+
+// ErrEscalationStepSent is returned when the step of an escalation was
+// already recorded, and so sent, for the alert.
+var ErrEscalationStepSent = &Error{
+	Code: EConflict,
+	Msg:  "escalation step was already sent",
+}
+
+// EscalationPolicyService manages the escalation policies of organizations.
+type EscalationPolicyService interface {
+	// FindEscalationPolicyByID returns a single escalation policy by ID.
+	FindEscalationPolicyByID(ctx context.Context, id ID) (*EscalationPolicy, error)
+
+	// FindEscalationPolicies returns the escalation policies that match filter and the total count of matching escalation policies.
+	FindEscalationPolicies(ctx context.Context, filter EscalationPolicyFilter, opt ...FindOptions) ([]*EscalationPolicy, int, error)
+
+	// CreateEscalationPolicy creates a new escalation policy and sets p.ID with the new identifier.
+	CreateEscalationPolicy(ctx context.Context, p *EscalationPolicy) error
+
+	// UpdateEscalationPolicy updates a single escalation policy with changeset.
+	// Returns the new escalation policy after update.
+	UpdateEscalationPolicy(ctx context.Context, id ID, upd EscalationPolicyUpdate) (*EscalationPolicy, error)
+
+	// DeleteEscalationPolicy removes an escalation policy by ID. Policies
+	// that notification rules escalate with cannot be deleted.
+	DeleteEscalationPolicy(ctx context.Context, id ID) error
+}
+
+// EscalationPolicy escalates the alerts of the notification rules that use
+// it to more and more endpoints, for as long as the alerts are neither
+// recovered nor acknowledged.
+//
+// The notification rule notifies its own endpoint first, as it does without
+// a policy. The steps of the policy then notify their endpoints in order,
+// each one Delay after the previous step, the first one Delay after the
+// alert started.
+type EscalationPolicy struct {
+	ID          ID               `json:"id,omitempty"`
+	OrgID       ID               `json:"orgID"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Steps       []EscalationStep `json:"steps"`
+	CRUDLog
+}
+
+// EscalationStep notifies the endpoints of a step of an escalation policy.
+type EscalationStep struct {
+	// Delay is the time after the previous step, or after the alert started
+	// for the first step, before the step notifies its endpoints.
+	Delay       Duration `json:"delay"`
+	EndpointIDs []ID     `json:"endpointIDs"`
+}
+
+// Valid returns an error if the escalation policy is malformed.
+func (p *EscalationPolicy) Valid() error {
+	if p.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "escalation policy name is required",
+		}
+	}
+	if !p.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "escalation policy orgID is invalid",
+		}
+	}
+	if len(p.Steps) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "escalation policy must have at least one step",
+		}
+	}
+	for i, s := range p.Steps {
+		if s.Delay.Duration < 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("escalation policy step %d has a negative delay", i+1),
+			}
+		}
+		if len(s.EndpointIDs) == 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("escalation policy step %d must notify at least one endpoint", i+1),
+			}
+		}
+		for _, id := range s.EndpointIDs {
+			if !id.Valid() {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("escalation policy step %d has an invalid endpoint ID", i+1),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// StepAt returns when the step i of the policy notifies an alert that
+// started at since.
+func (p *EscalationPolicy) StepAt(i int, since time.Time) time.Time {
+	at := since
+	for _, s := range p.Steps[:i+1] {
+		at = at.Add(s.Delay.Duration)
+	}
+	return at
+}
+
+// EscalationPolicyFilter restricts the escalation policies returned by FindEscalationPolicies.
+type EscalationPolicyFilter struct {
+	OrgID        *ID
+	Organization *string
+}
+
+// QueryParams converts EscalationPolicyFilter fields to url query params.
+func (f EscalationPolicyFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.Organization != nil {
+		qp["org"] = []string{*f.Organization}
+	}
+	return qp
+}
+
+// EscalationPolicyUpdate is the changeset of an escalation policy. Fields
+// that are nil are left unchanged.
+type EscalationPolicyUpdate struct {
+	Name        *string           `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Steps       *[]EscalationStep `json:"steps,omitempty"`
+}
+
+// Apply applies an update to an escalation policy.
+func (p *EscalationPolicy) Apply(upd EscalationPolicyUpdate) error {
+	if upd.Name != nil {
+		p.Name = *upd.Name
+	}
+	if upd.Description != nil {
+		p.Description = *upd.Description
+	}
+	if upd.Steps != nil {
+		p.Steps = *upd.Steps
+	}
+	return p.Valid()
+}
+
+// EscalationService keeps the state of the escalations of the alerts of
+// notification rules between the runs of their tasks.
+type EscalationService interface {
+	// FindEscalations returns the escalations of the alerts of a notification rule.
+	FindEscalations(ctx context.Context, ruleID ID) ([]*Escalation, error)
+
+	// PutEscalation creates or replaces the escalation of the alert of e, and
+	// sets e.ID with the identifier of the escalation. It fails with
+	// ErrEscalationStepSent unless e escalates the alert with another policy,
+	// or to more steps than the stored escalation, so that each step is sent
+	// once when the alerts of a rule are escalated at once.
+	PutEscalation(ctx context.Context, e *Escalation) error
+
+	// DeleteEscalation removes an escalation by ID.
+	DeleteEscalation(ctx context.Context, id ID) error
+}
+
+// Escalation is the state of the escalation of the alert of a series of a
+// check by a notification rule.
+type Escalation struct {
+	ID       ID     `json:"id,omitempty"`
+	RuleID   ID     `json:"ruleID"`
+	PolicyID ID     `json:"policyID"`
+	CheckID  ID     `json:"checkID"`
+	Tags     []Tag  `json:"tags"`
+	Level    string `json:"level"`
+	// Since is when the alert started.
+	Since time.Time `json:"since"`
+	// Steps is the number of steps of the policy that the alert was
+	// escalated to. A step is recorded before it is sent.
+	Steps int `json:"steps"`
+	// NotifiedAt is when the last step notified the alert.
+	NotifiedAt time.Time `json:"notifiedAt,omitempty"`
+}
+
+// SameAlert returns whether the escalations escalate the alert of the same
+// series of a check by the same notification rule.
+func (e *Escalation) SameAlert(o *Escalation) bool {
+	if e.RuleID != o.RuleID || e.CheckID != o.CheckID || len(e.Tags) != len(o.Tags) {
+		return false
+	}
+	for _, et := range e.Tags {
+		var ok bool
+		for _, ot := range o.Tags {
+			ok = ok || et == ot
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestEscalationPolicy_Valid(t *testing.T) {
+	policy := func(fn func(p *influxdb.EscalationPolicy)) *influxdb.EscalationPolicy {
+		p := &influxdb.EscalationPolicy{
+			OrgID: 1,
+			Name:  "on-call",
+			Steps: []influxdb.EscalationStep{
+				{Delay: influxdb.Duration{Duration: 15 * time.Minute}, EndpointIDs: []influxdb.ID{10}},
+			},
+		}
+		fn(p)
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		policy  *influxdb.EscalationPolicy
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			policy: policy(func(p *influxdb.EscalationPolicy) {}),
+		},
+		{
+			name:    "missing name",
+			policy:  policy(func(p *influxdb.EscalationPolicy) { p.Name = "" }),
+			wantErr: true,
+		},
+		{
+			name:    "no steps",
+			policy:  policy(func(p *influxdb.EscalationPolicy) { p.Steps = nil }),
+			wantErr: true,
+		},
+		{
+			name:    "step without endpoints",
+			policy:  policy(func(p *influxdb.EscalationPolicy) { p.Steps[0].EndpointIDs = nil }),
+			wantErr: true,
+		},
+		{
+			name:    "negative delay",
+			policy:  policy(func(p *influxdb.EscalationPolicy) { p.Steps[0].Delay.Duration = -time.Minute }),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Valid(); (err != nil) != tt.wantErr {
+				t.Fatalf("Valid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEscalationPolicy_StepAt(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &influxdb.EscalationPolicy{
+		Steps: []influxdb.EscalationStep{
+			{Delay: influxdb.Duration{Duration: 5 * time.Minute}},
+			{Delay: influxdb.Duration{Duration: 10 * time.Minute}},
+		},
+	}
+	if at := p.StepAt(0, since); !at.Equal(since.Add(5 * time.Minute)) {
+		t.Fatalf("expected the first step 5m after the alert started, got %s", at)
+	}
+	if at := p.StepAt(1, since); !at.Equal(since.Add(15 * time.Minute)) {
+		t.Fatalf("expected the second step 10m after the first, got %s", at)
+	}
+}
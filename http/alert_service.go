@@ -0,0 +1,440 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixAlerts                = "/api/v2/alerts"
+	prefixAlertHistory          = "/api/v2/alerts/history"
+	prefixAlertAcknowledgements = "/api/v2/alerts/acknowledgements"
+)
+
+// AlertBackend is all services and associated parameters required to construct
+// the AlertHandler.
+type AlertBackend struct {
+	influxdb.HTTPErrorHandler
+	log                         *zap.Logger
+	AlertService                influxdb.AlertService
+	AlertAcknowledgementService influxdb.AlertAcknowledgementService
+	OrganizationService         influxdb.OrganizationService
+}
+
+// NewAlertBackend creates a backend used by the alert handler.
+func NewAlertBackend(log *zap.Logger, b *APIBackend) *AlertBackend {
+	return &AlertBackend{
+		HTTPErrorHandler:            b.HTTPErrorHandler,
+		log:                         log,
+		AlertService:                b.AlertService,
+		AlertAcknowledgementService: b.AlertAcknowledgementService,
+		OrganizationService:         b.OrganizationService,
+	}
+}
+
+// AlertHandler is the handler for the alert service
+type AlertHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AlertService                influxdb.AlertService
+	AlertAcknowledgementService influxdb.AlertAcknowledgementService
+	OrganizationService         influxdb.OrganizationService
+}
+
+// NewAlertHandler creates a new AlertHandler
+func NewAlertHandler(log *zap.Logger, b *AlertBackend) *AlertHandler {
+	h := &AlertHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		AlertService:                b.AlertService,
+		AlertAcknowledgementService: b.AlertAcknowledgementService,
+		OrganizationService:         b.OrganizationService,
+	}
+
+	h.HandlerFunc("GET", prefixAlerts, h.handleGetAlertStates)
+	h.HandlerFunc("GET", prefixAlertHistory, h.handleGetAlertHistory)
+	h.HandlerFunc("GET", prefixAlertAcknowledgements, h.handleGetAlertAcknowledgements)
+	h.HandlerFunc("POST", prefixAlertAcknowledgements, h.handlePostAlertAcknowledgement)
+	h.HandlerFunc("GET", prefixAlertAcknowledgements+"/:id", h.handleGetAlertAcknowledgement)
+	h.HandlerFunc("DELETE", prefixAlertAcknowledgements+"/:id", h.handleDeleteAlertAcknowledgement)
+
+	return h
+}
+
+type getAlertStatesResponse struct {
+	States []*influxdb.AlertState `json:"states"`
+}
+
+type getAlertHistoryResponse struct {
+	Statuses []*influxdb.AlertStatus `json:"statuses"`
+	Links    *influxdb.PagingLinks   `json:"links"`
+}
+
+type alertAcknowledgementLinks struct {
+	Self  string `json:"self"`
+	Check string `json:"check"`
+}
+
+type alertAcknowledgementResponse struct {
+	*influxdb.AlertAcknowledgement
+	Links alertAcknowledgementLinks `json:"links"`
+}
+
+func newAlertAcknowledgementResponse(a *influxdb.AlertAcknowledgement) alertAcknowledgementResponse {
+	return alertAcknowledgementResponse{
+		AlertAcknowledgement: a,
+		Links: alertAcknowledgementLinks{
+			Self:  fmt.Sprintf("%s/%s", prefixAlertAcknowledgements, a.ID),
+			Check: fmt.Sprintf("%s/%s", prefixChecks, a.CheckID),
+		},
+	}
+}
+
+type getAlertAcknowledgementsResponse struct {
+	Acknowledgements []alertAcknowledgementResponse `json:"acknowledgements"`
+	Links            *influxdb.PagingLinks          `json:"links"`
+}
+
+func (r getAlertAcknowledgementsResponse) toInfluxDB() []*influxdb.AlertAcknowledgement {
+	acks := make([]*influxdb.AlertAcknowledgement, len(r.Acknowledgements))
+	for i := range r.Acknowledgements {
+		acks[i] = r.Acknowledgements[i].AlertAcknowledgement
+	}
+	return acks
+}
+
+// decodeAlertFilter decodes the organization, check, levels, tags and time
+// range of the alerts requested. The organization is looked up by name when
+// the request has no orgID.
+func (h *AlertHandler) decodeAlertFilter(ctx context.Context, r *http.Request) (*influxdb.AlertFilter, error) {
+	o, err := queryOrganization(ctx, r, h.OrganizationService)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &influxdb.AlertFilter{OrgID: o.ID}
+	qp := r.URL.Query()
+	if checkID := qp.Get("checkID"); checkID != "" {
+		id, err := influxdb.IDFromString(checkID)
+		if err != nil {
+			return nil, err
+		}
+		f.CheckID = id
+	}
+	f.Levels = qp["level"]
+	for _, tag := range qp["tag"] {
+		t, err := influxdb.NewTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		f.Tags = append(f.Tags, t)
+	}
+	if f.Start, err = decodeAlertTime(qp.Get("start"), "start"); err != nil {
+		return nil, err
+	}
+	if f.Stop, err = decodeAlertTime(qp.Get("stop"), "stop"); err != nil {
+		return nil, err
+	}
+	return f, f.Valid()
+}
+
+func decodeAlertTime(s, name string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("%s must be an RFC3339 time", name),
+			Err:  err,
+		}
+	}
+	return &t, nil
+}
+
+func (h *AlertHandler) handleGetAlertStates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := h.decodeAlertFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	states, _, err := h.AlertService.FindAlertStates(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert states retrieved", zap.Int("states", len(states)))
+	if err := encodeResponse(ctx, w, http.StatusOK, getAlertStatesResponse{States: states}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *AlertHandler) handleGetAlertHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := h.decodeAlertFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	statuses, _, err := h.AlertService.FindAlertHistory(ctx, *filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert history retrieved", zap.Int("statuses", len(statuses)))
+	resp := getAlertHistoryResponse{
+		Statuses: statuses,
+		Links:    newPagingLinks(prefixAlertHistory, *opts, filter, len(statuses)),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *AlertHandler) handleGetAlertAcknowledgements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var filter influxdb.AlertAcknowledgementFilter
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		if filter.OrgID, err = influxdb.IDFromString(orgID); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if checkID := qp.Get("checkID"); checkID != "" {
+		if filter.CheckID, err = influxdb.IDFromString(checkID); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if filter.OrgID == nil && filter.CheckID == nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID or checkID is required",
+		}, w)
+		return
+	}
+
+	acks, _, err := h.AlertAcknowledgementService.FindAlertAcknowledgements(ctx, filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert acknowledgements retrieved", zap.Int("acknowledgements", len(acks)))
+	resp := getAlertAcknowledgementsResponse{
+		Acknowledgements: make([]alertAcknowledgementResponse, 0, len(acks)),
+		Links:            newPagingLinks(prefixAlertAcknowledgements, *opts, filter, len(acks)),
+	}
+	for _, a := range acks {
+		resp.Acknowledgements = append(resp.Acknowledgements, newAlertAcknowledgementResponse(a))
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, resp); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostAlertAcknowledgement acknowledges an alert on behalf of the user
+// of the request.
+func (h *AlertHandler) handlePostAlertAcknowledgement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a := &influxdb.AlertAcknowledgement{}
+	if err := json.NewDecoder(r.Body).Decode(a); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode alert acknowledgement",
+			Err:  err,
+		}, w)
+		return
+	}
+	a.UserID = auth.GetUserID()
+
+	if err := h.AlertAcknowledgementService.CreateAlertAcknowledgement(ctx, a); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert acknowledged", zap.String("acknowledgement", fmt.Sprint(a)))
+	if err := encodeResponse(ctx, w, http.StatusCreated, newAlertAcknowledgementResponse(a)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *AlertHandler) handleGetAlertAcknowledgement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a, err := h.AlertAcknowledgementService.FindAlertAcknowledgementByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert acknowledgement retrieved", zap.String("acknowledgement", fmt.Sprint(a)))
+	if err := encodeResponse(ctx, w, http.StatusOK, newAlertAcknowledgementResponse(a)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *AlertHandler) handleDeleteAlertAcknowledgement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.AlertAcknowledgementService.DeleteAlertAcknowledgement(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert acknowledgement deleted", zap.String("acknowledgementID", fmt.Sprint(id)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AlertService is an alert service over HTTP to the influxdb server
+type AlertService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.AlertService = (*AlertService)(nil)
+var _ influxdb.AlertAcknowledgementService = (*AlertService)(nil)
+
+func alertFilterParams(filter influxdb.AlertFilter) [][2]string {
+	var params [][2]string
+	for k, vals := range filter.QueryParams() {
+		for _, v := range vals {
+			params = append(params, [2]string{k, v})
+		}
+	}
+	return params
+}
+
+// FindAlertStates returns the current state of the series of the checks that match filter.
+func (s *AlertService) FindAlertStates(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+	var resp getAlertStatesResponse
+	err := s.Client.
+		Get(prefixAlerts).
+		QueryParams(alertFilterParams(filter)...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.States, len(resp.States), nil
+}
+
+// FindAlertHistory returns the statuses that match filter, most recent first.
+func (s *AlertService) FindAlertHistory(ctx context.Context, filter influxdb.AlertFilter, opts ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error) {
+	params := append(findOptionParams(opts...), alertFilterParams(filter)...)
+
+	var resp getAlertHistoryResponse
+	err := s.Client.
+		Get(prefixAlertHistory).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Statuses, len(resp.Statuses), nil
+}
+
+// FindAlertAcknowledgementByID finds a single alert acknowledgement by its ID.
+func (s *AlertService) FindAlertAcknowledgementByID(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+	var resp alertAcknowledgementResponse
+	err := s.Client.
+		Get(prefixAlertAcknowledgements, id.String()).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AlertAcknowledgement, nil
+}
+
+// FindAlertAcknowledgements returns the alert acknowledgements that match filter.
+func (s *AlertService) FindAlertAcknowledgements(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opts ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+	params := findOptionParams(opts...)
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.CheckID != nil {
+		params = append(params, [2]string{"checkID", filter.CheckID.String()})
+	}
+
+	var resp getAlertAcknowledgementsResponse
+	err := s.Client.
+		Get(prefixAlertAcknowledgements).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	acks := resp.toInfluxDB()
+	return acks, len(acks), nil
+}
+
+// CreateAlertAcknowledgement acknowledges an alert as the user of the client, and sets the ID of a.
+func (s *AlertService) CreateAlertAcknowledgement(ctx context.Context, a *influxdb.AlertAcknowledgement) error {
+	var resp alertAcknowledgementResponse
+	err := s.Client.
+		PostJSON(a, prefixAlertAcknowledgements).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*a = *resp.AlertAcknowledgement
+	return nil
+}
+
+// DeleteAlertAcknowledgement removes an alert acknowledgement by its ID.
+func (s *AlertService) DeleteAlertAcknowledgement(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(prefixAlertAcknowledgements, id.String()).
+		Do(ctx)
+}
@@ -74,6 +74,7 @@ type APIBackend struct {
 	FluxService                     query.ProxyQueryService
 	TaskService                     influxdb.TaskService
 	CheckService                    influxdb.CheckService
+	CheckPreviewService             influxdb.CheckPreviewService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
 	SecretService                   influxdb.SecretService
@@ -82,7 +83,18 @@ type APIBackend struct {
 	OrgLookupService                authorizer.OrganizationService
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
+	NotificationRuleDryRunService   influxdb.NotificationRuleDryRunService
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	NotificationEndpointTestService influxdb.NotificationEndpointTestService
+	CardinalityService              influxdb.CardinalityService
+	MeasurementSchemaService        influxdb.MeasurementSchemaService
+	TaskBackfillService             influxdb.TaskBackfillService
+	TaskDryRunService               influxdb.TaskDryRunService
+	TaskVersionService              influxdb.TaskVersionService
+	SilenceService                  influxdb.SilenceService
+	AlertService                    influxdb.AlertService
+	AlertAcknowledgementService     influxdb.AlertAcknowledgementService
+	EscalationPolicyService         influxdb.EscalationPolicyService
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -121,18 +133,27 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	h.Mount("/api/v2", serveLinksHandler(b.HTTPErrorHandler))
 
+	alertBackend := NewAlertBackend(b.Logger.With(zap.String("handler", "alert")), b)
+	alertBackend.AlertService = authorizer.NewAlertService(b.AlertService)
+	alertBackend.AlertAcknowledgementService = authorizer.NewAlertAcknowledgementService(b.AlertAcknowledgementService)
+	h.Mount(prefixAlerts, NewAlertHandler(b.Logger, alertBackend))
+
 	authorizationBackend := NewAuthorizationBackend(b.Logger.With(zap.String("handler", "authorization")), b)
 	authorizationBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
 	h.Mount(prefixAuthorization, NewAuthorizationHandler(b.Logger, authorizationBackend))
 
 	bucketBackend := NewBucketBackend(b.Logger.With(zap.String("handler", "bucket")), b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	bucketBackend.CardinalityService = authorizer.NewCardinalityService(b.CardinalityService)
+	bucketBackend.MeasurementSchemaService = authorizer.NewMeasurementSchemaService(b.MeasurementSchemaService)
 	h.Mount(prefixBuckets, NewBucketHandler(b.Logger, bucketBackend))
 
 	checkBackend := NewCheckBackend(b.Logger.With(zap.String("handler", "check")), b)
 	checkBackend.CheckService = authorizer.NewCheckService(b.CheckService,
 		b.UserResourceMappingService, b.OrganizationService)
+	checkBackend.CheckPreviewService = authorizer.NewCheckPreviewService(b.CheckPreviewService)
 	h.Mount(prefixChecks, NewCheckHandler(b.Logger, checkBackend))
+	h.Mount(checksPreviewPath, NewCheckPreviewHandler(b.Logger, checkBackend))
 
 	h.Mount(prefixChronograf, NewChronografHandler(b.ChronografService, b.HTTPErrorHandler))
 
@@ -146,6 +167,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	documentBackend := NewDocumentBackend(b.Logger.With(zap.String("handler", "document")), b)
 	h.Mount(prefixDocuments, NewDocumentHandler(documentBackend))
 
+	escalationPolicyBackend := NewEscalationPolicyBackend(b.Logger.With(zap.String("handler", "escalation_policy")), b)
+	escalationPolicyBackend.EscalationPolicyService = authorizer.NewEscalationPolicyService(b.EscalationPolicyService)
+	h.Mount(prefixEscalationPolicies, NewEscalationPolicyHandler(b.Logger, escalationPolicyBackend))
+
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
 	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
 
@@ -154,11 +179,15 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
 	notificationEndpointBackend.NotificationEndpointService = authorizer.NewNotificationEndpointService(b.NotificationEndpointService,
 		b.UserResourceMappingService, b.OrganizationService)
+	notificationEndpointBackend.NotificationEndpointTestService = authorizer.NewNotificationEndpointTestService(b.NotificationEndpointTestService,
+		b.NotificationEndpointService)
 	h.Mount(prefixNotificationEndpoints, NewNotificationEndpointHandler(notificationEndpointBackend.Logger(), notificationEndpointBackend))
 
 	notificationRuleBackend := NewNotificationRuleBackend(b.Logger.With(zap.String("handler", "notification_rule")), b)
 	notificationRuleBackend.NotificationRuleStore = authorizer.NewNotificationRuleStore(b.NotificationRuleStore,
 		b.UserResourceMappingService, b.OrganizationService)
+	notificationRuleBackend.NotificationRuleDryRunService = authorizer.NewNotificationRuleDryRunService(b.NotificationRuleDryRunService,
+		b.NotificationRuleStore)
 	h.Mount(prefixNotificationRules, NewNotificationRuleHandler(b.Logger, notificationRuleBackend))
 
 	orgBackend := NewOrgBackend(b.Logger.With(zap.String("handler", "org")), b)
@@ -179,6 +208,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	setupBackend := NewSetupBackend(b.Logger.With(zap.String("handler", "setup")), b)
 	h.Mount(prefixSetup, NewSetupHandler(b.Logger, setupBackend))
 
+	silenceBackend := NewSilenceBackend(b.Logger.With(zap.String("handler", "silence")), b)
+	silenceBackend.SilenceService = authorizer.NewSilenceService(b.SilenceService)
+	h.Mount(prefixSilences, NewSilenceHandler(b.Logger, silenceBackend))
+
 	sourceBackend := NewSourceBackend(b.Logger.With(zap.String("handler", "source")), b)
 	sourceBackend.SourceService = authorizer.NewSourceService(b.SourceService)
 	sourceBackend.BucketService = authorizer.NewBucketService(b.BucketService)
@@ -189,8 +222,12 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	taskLogger := b.Logger.With(zap.String("handler", "bucket"))
 	taskBackend := NewTaskBackend(taskLogger, b)
 	taskBackend.TaskService = authorizer.NewTaskService(taskLogger, b.TaskService)
+	taskBackend.TaskBackfillService = authorizer.NewTaskBackfillService(b.TaskBackfillService)
+	taskBackend.TaskDryRunService = authorizer.NewTaskDryRunService(b.TaskDryRunService)
+	taskBackend.TaskVersionService = authorizer.NewTaskVersionService(b.TaskVersionService)
 	taskHandler := NewTaskHandler(b.Logger, taskBackend)
 	h.Mount(prefixTasks, taskHandler)
+	h.Mount(tasksDryRunPath, NewTaskDryRunHandler(b.Logger, taskBackend))
 
 	telegrafBackend := NewTelegrafBackend(b.Logger.With(zap.String("handler", "telegraf")), b)
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
@@ -229,6 +266,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"alerts":         "/api/v2/alerts",
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
@@ -236,6 +274,7 @@ var apiLinks = map[string]interface{}{
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
+	"escalationPolicies":    "/api/v2/escalationPolicies",
 	"labels":                "/api/v2/labels",
 	"variables":             "/api/v2/variables",
 	"me":                    "/api/v2/me",
@@ -251,6 +290,7 @@ var apiLinks = map[string]interface{}{
 	"setup":    "/api/v2/setup",
 	"signin":   "/api/v2/signin",
 	"signout":  "/api/v2/signout",
+	"silences": "/api/v2/silences",
 	"sources":  "/api/v2/sources",
 	"scrapers": "/api/v2/scrapers",
 	"swagger":  "/api/v2/swagger.json",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +29,8 @@ type BucketBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	CardinalityService         influxdb.CardinalityService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
 }
 
 // NewBucketBackend returns a new instance of BucketBackend.
@@ -42,6 +45,8 @@ func NewBucketBackend(log *zap.Logger, b *APIBackend) *BucketBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		CardinalityService:         b.CardinalityService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
 	}
 }
 
@@ -57,12 +62,15 @@ type BucketHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	CardinalityService         influxdb.CardinalityService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
 }
 
 const (
 	prefixBuckets          = "/api/v2/buckets"
 	bucketsIDPath          = "/api/v2/buckets/:id"
 	bucketsIDLogPath       = "/api/v2/buckets/:id/logs"
+	bucketsIDCardinality   = "/api/v2/buckets/:id/cardinality"
 	bucketsIDMembersPath   = "/api/v2/buckets/:id/members"
 	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
 	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
@@ -84,15 +92,24 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		CardinalityService:         b.CardinalityService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
 	}
 
 	h.HandlerFunc("POST", prefixBuckets, h.handlePostBucket)
 	h.HandlerFunc("GET", prefixBuckets, h.handleGetBuckets)
 	h.HandlerFunc("GET", bucketsIDPath, h.handleGetBucket)
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
+	h.HandlerFunc("GET", bucketsIDCardinality, h.handleGetBucketCardinality)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
 
+	h.HandlerFunc("GET", bucketsIDSchemaMeasurementsPath, h.handleGetMeasurementSchemas)
+	h.HandlerFunc("POST", bucketsIDSchemaMeasurementsPath, h.handlePostMeasurementSchema)
+	h.HandlerFunc("GET", bucketsIDSchemaMeasurementsIDPath, h.handleGetMeasurementSchema)
+	h.HandlerFunc("PATCH", bucketsIDSchemaMeasurementsIDPath, h.handlePatchMeasurementSchema)
+	h.HandlerFunc("DELETE", bucketsIDSchemaMeasurementsIDPath, h.handleDeleteMeasurementSchema)
+
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		log:                        b.log.With(zap.String("handler", "member")),
@@ -139,6 +156,10 @@ type bucket struct {
 	Name                string          `json:"name"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	MaxSeries           int64           `json:"maxSeries,omitempty"`
+	SchemaType          string          `json:"schemaType,omitempty"`
+
+	DownsamplingPolicies []influxdb.DownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -186,7 +207,11 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		MaxSeries:           b.MaxSeries,
+		SchemaType:          influxdb.SchemaType(b.SchemaType),
 		CRUDLog:             b.CRUDLog,
+
+		DownsamplingPolicies: b.DownsamplingPolicies,
 	}, nil
 }
 
@@ -212,7 +237,11 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		MaxSeries:           pb.MaxSeries,
+		SchemaType:          string(pb.SchemaType),
 		CRUDLog:             pb.CRUDLog,
+
+		DownsamplingPolicies: pb.DownsamplingPolicies,
 	}
 }
 
@@ -221,6 +250,9 @@ type bucketUpdate struct {
 	Name           *string         `json:"name,omitempty"`
 	Description    *string         `json:"description,omitempty"`
 	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	MaxSeries      *int64          `json:"maxSeries,omitempty"`
+
+	DownsamplingPolicies *[]influxdb.DownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
 }
 
 func (b *bucketUpdate) OK() error {
@@ -230,6 +262,11 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
+	if b.MaxSeries != nil {
+		if err := validMaxSeries(*b.MaxSeries); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -248,6 +285,9 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
+		MaxSeries:       b.MaxSeries,
+
+		DownsamplingPolicies: b.DownsamplingPolicies,
 	}
 }
 
@@ -260,6 +300,9 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 		Name:           pb.Name,
 		Description:    pb.Description,
 		RetentionRules: []retentionRule{},
+		MaxSeries:      pb.MaxSeries,
+
+		DownsamplingPolicies: pb.DownsamplingPolicies,
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -341,6 +384,10 @@ type postBucketRequest struct {
 	Description         string          `json:"description"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	MaxSeries           int64           `json:"maxSeries,omitempty"`
+	SchemaType          string          `json:"schemaType,omitempty"`
+
+	DownsamplingPolicies []influxdb.DownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -361,6 +408,14 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := validMaxSeries(b.MaxSeries); err != nil {
+		return err
+	}
+
+	if err := influxdb.SchemaType(b.SchemaType).Valid(); err != nil {
+		return err
+	}
+
 	// names starting with an underscore are reserved for system buckets
 	if err := validBucketName(b.toInfluxDB()); err != nil {
 		return &influxdb.Error{
@@ -386,6 +441,10 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		MaxSeries:           b.MaxSeries,
+		SchemaType:          influxdb.SchemaType(b.SchemaType),
+
+		DownsamplingPolicies: b.DownsamplingPolicies,
 	}
 }
 
@@ -458,6 +517,71 @@ func newBucketLogResponse(id influxdb.ID, es []*influxdb.OperationLogEntry) *ope
 	}
 }
 
+// handleGetBucketCardinality is the HTTP handler for the GET /api/v2/buckets/:id/cardinality route.
+func (h *BucketHandler) handleGetBucketCardinality(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	opt, err := decodeCardinalityOptions(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	card, err := h.CardinalityService.BucketCardinality(ctx, b.OrgID, b.ID, opt)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.log.Debug("Bucket cardinality retrieved", zap.String("bucket", b.ID.String()), zap.Int64("series", card.SeriesN))
+
+	h.api.Respond(w, http.StatusOK, newBucketCardinalityResponse(card))
+}
+
+func decodeCardinalityOptions(r *http.Request) (influxdb.CardinalityOptions, error) {
+	opt := influxdb.CardinalityOptions{TopN: influxdb.DefaultCardinalityTopN}
+
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return opt, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be a positive integer",
+			}
+		}
+		opt.TopN = n
+	}
+
+	return opt, nil
+}
+
+type bucketCardinalityResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.BucketCardinality
+}
+
+func newBucketCardinalityResponse(card *influxdb.BucketCardinality) *bucketCardinalityResponse {
+	return &bucketCardinalityResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/cardinality", card.BucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", card.BucketID),
+		},
+		BucketCardinality: card,
+	}
+}
+
 // handleDeleteBucket is the HTTP handler for the DELETE /api/v2/buckets/:id route.
 func (h *BucketHandler) handleDeleteBucket(w http.ResponseWriter, r *http.Request) {
 	id, err := decodeIDFromCtx(r.Context(), "id")
@@ -792,3 +916,14 @@ func validBucketName(bucket *influxdb.Bucket) error {
 	}
 	return nil
 }
+
+// validMaxSeries reports any errors with a bucket series limit.
+func validMaxSeries(n int64) error {
+	if n < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "max series must be greater than or equal to zero",
+		}
+	}
+	return nil
+}
@@ -35,6 +35,7 @@ func NewMockBucketBackend(t *testing.T) *BucketBackend {
 		LabelService:               mock.NewLabelService(),
 		UserService:                mock.NewUserService(),
 		OrganizationService:        mock.NewOrganizationService(),
+		CardinalityService:         mock.NewCardinalityService(),
 	}
 }
 
@@ -367,6 +368,173 @@ func TestService_handleGetBucket(t *testing.T) {
 	}
 }
 
+func TestService_handleGetBucketCardinality(t *testing.T) {
+	type fields struct {
+		BucketService      platform.BucketService
+		CardinalityService platform.CardinalityService
+	}
+	type args struct {
+		id    string
+		limit string
+	}
+	type wants struct {
+		statusCode  int
+		contentType string
+		body        string
+	}
+
+	bucketService := &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			if id == platformtesting.MustIDBase16("020f755c3c082000") {
+				return &platform.Bucket{
+					ID:    platformtesting.MustIDBase16("020f755c3c082000"),
+					OrgID: platformtesting.MustIDBase16("020f755c3c082001"),
+					Name:  "hello",
+				}, nil
+			}
+
+			return nil, &platform.Error{
+				Code: platform.ENotFound,
+				Msg:  "bucket not found",
+			}
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "get cardinality of a bucket",
+			fields: fields{
+				BucketService: bucketService,
+				CardinalityService: &mock.CardinalityService{
+					BucketCardinalityF: func(ctx context.Context, orgID, bucketID platform.ID, opt platform.CardinalityOptions) (*platform.BucketCardinality, error) {
+						if orgID != platformtesting.MustIDBase16("020f755c3c082001") {
+							return nil, fmt.Errorf("unexpected org id %s", orgID)
+						}
+						if opt.TopN != 5 {
+							return nil, fmt.Errorf("unexpected top n %d", opt.TopN)
+						}
+						return &platform.BucketCardinality{
+							BucketID:     bucketID,
+							SeriesN:      12,
+							Measurements: []platform.MeasurementCardinality{{Name: "cpu", SeriesN: 12}},
+							TagKeys:      []platform.TagKeyCardinality{{Key: "host", ValuesN: 4}},
+							Estimated:    true,
+							Growth: &platform.CardinalityGrowth{
+								Since:   time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC),
+								SeriesN: 2,
+							},
+							SampledAt: time.Date(2019, 11, 1, 0, 1, 0, 0, time.UTC),
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id:    "020f755c3c082000",
+				limit: "5",
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "self": "/api/v2/buckets/020f755c3c082000/cardinality",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "bucketID": "020f755c3c082000",
+  "seriesN": 12,
+  "measurements": [{"name": "cpu", "seriesN": 12}],
+  "tagKeys": [{"key": "host", "valuesN": 4}],
+  "estimated": true,
+  "growth": {"since": "2019-11-01T00:00:00Z", "seriesN": 2},
+  "sampledAt": "2019-11-01T00:01:00Z"
+}
+`,
+			},
+		},
+		{
+			name: "invalid limit",
+			fields: fields{
+				BucketService:      bucketService,
+				CardinalityService: mock.NewCardinalityService(),
+			},
+			args: args{
+				id:    "020f755c3c082000",
+				limit: "-1",
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "bucket not found",
+			fields: fields{
+				BucketService:      bucketService,
+				CardinalityService: mock.NewCardinalityService(),
+			},
+			args: args{
+				id: "020f755c3c082002",
+			},
+			wants: wants{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketService = tt.fields.BucketService
+			bucketBackend.CardinalityService = tt.fields.CardinalityService
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			u := "http://any.url"
+			if tt.args.limit != "" {
+				u += "?limit=" + tt.args.limit
+			}
+			r := httptest.NewRequest("GET", u, nil)
+
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: tt.args.id,
+					},
+				}))
+
+			w := httptest.NewRecorder()
+
+			h.handleGetBucketCardinality(w, r)
+
+			res := w.Result()
+			content := res.Header.Get("Content-Type")
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handleGetBucketCardinality() = %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.contentType != "" && content != tt.wants.contentType {
+				t.Errorf("%q. handleGetBucketCardinality() = %v, want %v", tt.name, content, tt.wants.contentType)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%q, handleGetBucketCardinality(). error unmarshaling json %v", tt.name, err)
+				} else if !eq {
+					t.Errorf("%q. handleGetBucketCardinality() = ***%s***", tt.name, diff)
+				}
+			}
+		})
+	}
+}
+
 func TestService_handlePostBucket(t *testing.T) {
 	type fields struct {
 		BucketService       platform.BucketService
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	checksPreviewPath   = "/api/v2/checks/preview"
+	checksPreviewIDPath = "/api/v2/checks/preview/:id"
+)
+
+type postCheckPreviewRequest struct {
+	Check json.RawMessage `json:"check"`
+	Start time.Time       `json:"start"`
+	Stop  time.Time       `json:"stop"`
+}
+
+// CheckPreviewHandler is the HTTP handler for previews of checks. It is
+// mounted apart from the CheckHandler, whose router can't route a static path
+// alongside /api/v2/checks/:id.
+type CheckPreviewHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	CheckPreviewService influxdb.CheckPreviewService
+}
+
+// NewCheckPreviewHandler returns a new instance of CheckPreviewHandler.
+func NewCheckPreviewHandler(log *zap.Logger, b *CheckBackend) *CheckPreviewHandler {
+	h := &CheckPreviewHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		CheckPreviewService: b.CheckPreviewService,
+	}
+
+	h.HandlerFunc("POST", checksPreviewPath, h.handlePostCheckPreview)
+	h.HandlerFunc("GET", checksPreviewIDPath, h.handleGetCheckPreview)
+	return h
+}
+
+// handlePostCheckPreview is the HTTP handler for the POST /api/v2/checks/preview route.
+func (h *CheckPreviewHandler) handlePostCheckPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p, err := decodePostCheckPreviewRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res, err := h.CheckPreviewService.PreviewCheck(ctx, p)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Check preview started", zap.String("id", res.ID.String()), zap.Int("evaluations", res.Evaluations))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetCheckPreview is the HTTP handler for the GET /api/v2/checks/preview/:id route.
+func (h *CheckPreviewHandler) handleGetCheckPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeGetCheckRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res, err := h.CheckPreviewService.FindCheckPreviewByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Check preview retrieved", zap.String("status", res.Status), zap.Int("transitions", len(res.Transitions)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodePostCheckPreviewRequest(r *http.Request) (influxdb.CheckPreview, error) {
+	var req postCheckPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return influxdb.CheckPreview{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+			Err:  err,
+		}
+	}
+	if len(req.Check) == 0 {
+		return influxdb.CheckPreview{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "preview requires a check",
+		}
+	}
+
+	chk, err := check.UnmarshalJSON(req.Check)
+	if err != nil {
+		return influxdb.CheckPreview{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	return influxdb.CheckPreview{
+		Check: chk,
+		Start: req.Start,
+		Stop:  req.Stop,
+	}, nil
+}
+
+// CheckPreviewService connects to Influx via HTTP using tokens to preview checks.
+type CheckPreviewService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.CheckPreviewService = (*CheckPreviewService)(nil)
+
+// PreviewCheck starts to evaluate a check against historical data without writing its statuses.
+func (s *CheckPreviewService) PreviewCheck(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res influxdb.CheckPreviewResult
+	err := s.Client.
+		PostJSON(p, checksPreviewPath).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// FindCheckPreviewByID returns a preview, with its status transitions once it succeeded.
+func (s *CheckPreviewService) FindCheckPreviewByID(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res influxdb.CheckPreviewResult
+	err := s.Client.
+		Get(checksPreviewPath, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification/check"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCheckPreviewHandler_handlePostCheckPreview(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	stop := time.Date(2020, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	var got influxdb.CheckPreview
+	previewSvc := mock.NewCheckPreviewService()
+	previewSvc.PreviewCheckF = func(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+		got = p
+		return &influxdb.CheckPreviewResult{
+			ID:          10,
+			OrgID:       p.Check.GetOrgID(),
+			Status:      influxdb.CheckPreviewRunning,
+			Start:       p.Start,
+			Stop:        p.Stop,
+			Evaluations: 168,
+		}, nil
+	}
+	previewSvc.FindCheckPreviewByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+		if id != 10 {
+			return nil, influxdb.ErrCheckPreviewNotFound
+		}
+		return &influxdb.CheckPreviewResult{
+			ID:          10,
+			OrgID:       3,
+			Status:      influxdb.CheckPreviewSuccess,
+			Start:       start,
+			Stop:        stop,
+			Evaluations: 168,
+			Transitions: []*influxdb.CheckPreviewTransition{{
+				Time:          start.Add(2 * time.Hour),
+				Tags:          []influxdb.Tag{{Key: "host", Value: "a"}},
+				PreviousLevel: "OK",
+				Level:         "CRIT",
+				Message:       "cpu is high",
+			}},
+			Levels: map[string]int{"CRIT": 1},
+		}, nil
+	}
+
+	checkBackend := NewMockCheckBackend(t)
+	checkBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	checkBackend.CheckPreviewService = previewSvc
+	h := NewCheckPreviewHandler(zaptest.NewLogger(t), checkBackend)
+
+	body := `{
+  "check": {
+    "type": "threshold",
+    "name": "cpu",
+    "orgID": "0000000000000003",
+    "every": "1h",
+    "query": {"text": "from(bucket: \"telegraf\") |> range(start: -1h)"},
+    "statusMessageTemplate": "cpu is high",
+    "thresholds": [{"type": "greater", "level": "CRIT", "value": 90}]
+  },
+  "start": "2020-01-01T00:00:00Z",
+  "stop": "2020-01-08T00:00:00Z"
+}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/checks/preview", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	resBody, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("handlePostCheckPreview() = %v, want %v: %s", res.StatusCode, http.StatusAccepted, resBody)
+	}
+
+	if chk, ok := got.Check.(*check.Threshold); !ok || chk.Name != "cpu" || chk.OrgID != 3 || len(chk.Thresholds) != 1 {
+		t.Fatalf("unexpected check %+v", got.Check)
+	}
+	if !got.Start.Equal(start) || !got.Stop.Equal(stop) {
+		t.Fatalf("unexpected preview range %s - %s", got.Start, got.Stop)
+	}
+
+	expBody := `
+{
+  "id": "000000000000000a",
+  "orgID": "0000000000000003",
+  "status": "running",
+  "start": "2020-01-01T00:00:00Z",
+  "stop": "2020-01-08T00:00:00Z",
+  "evaluations": 168,
+  "transitions": null,
+  "levels": null
+}`
+	if eq, diff, err := jsonEqual(string(resBody), expBody); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handlePostCheckPreview() = ***%s***", diff)
+	}
+
+	t.Run("get preview", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/checks/preview/000000000000000a", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		resBody, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("handleGetCheckPreview() = %v, want %v: %s", res.StatusCode, http.StatusOK, resBody)
+		}
+
+		expBody := `
+{
+  "id": "000000000000000a",
+  "orgID": "0000000000000003",
+  "status": "success",
+  "start": "2020-01-01T00:00:00Z",
+  "stop": "2020-01-08T00:00:00Z",
+  "evaluations": 168,
+  "transitions": [
+    {
+      "time": "2020-01-01T02:00:00Z",
+      "tags": [{"key": "host", "value": "a"}],
+      "previousLevel": "OK",
+      "level": "CRIT",
+      "message": "cpu is high"
+    }
+  ],
+  "levels": {"CRIT": 1}
+}`
+		if eq, diff, err := jsonEqual(string(resBody), expBody); err != nil {
+			t.Fatalf("error unmarshaling json %v", err)
+		} else if !eq {
+			t.Errorf("handleGetCheckPreview() = ***%s***", diff)
+		}
+	})
+
+	t.Run("unknown preview", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://any.url/api/v2/checks/preview/000000000000000b", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("handleGetCheckPreview() = %v, want %v", w.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("invalid check", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://any.url/api/v2/checks/preview", bytes.NewBufferString(`{"check": {"type": "nope"}}`))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("handlePostCheckPreview() = %v, want %v", w.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -27,6 +27,7 @@ type CheckBackend struct {
 
 	TaskService                influxdb.TaskService
 	CheckService               influxdb.CheckService
+	CheckPreviewService        influxdb.CheckPreviewService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -41,6 +42,7 @@ func NewCheckBackend(log *zap.Logger, b *APIBackend) *CheckBackend {
 
 		TaskService:                b.TaskService,
 		CheckService:               b.CheckService,
+		CheckPreviewService:        b.CheckPreviewService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -30,6 +30,7 @@ func NewMockCheckBackend(t *testing.T) *CheckBackend {
 		log: zaptest.NewLogger(t),
 
 		CheckService:               mock.NewCheckService(),
+		CheckPreviewService:        mock.NewCheckPreviewService(),
 		UserResourceMappingService: mock.NewUserResourceMappingService(),
 		LabelService:               mock.NewLabelService(),
 		UserService:                mock.NewUserService(),
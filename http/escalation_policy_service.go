@@ -0,0 +1,348 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixEscalationPolicies = "/api/v2/escalationPolicies"
+)
+
+// EscalationPolicyBackend is all services and associated parameters required to construct
+// the EscalationPolicyHandler.
+type EscalationPolicyBackend struct {
+	influxdb.HTTPErrorHandler
+	log                     *zap.Logger
+	EscalationPolicyService influxdb.EscalationPolicyService
+}
+
+// NewEscalationPolicyBackend creates a backend used by the escalation policy handler.
+func NewEscalationPolicyBackend(log *zap.Logger, b *APIBackend) *EscalationPolicyBackend {
+	return &EscalationPolicyBackend{
+		HTTPErrorHandler:        b.HTTPErrorHandler,
+		log:                     log,
+		EscalationPolicyService: b.EscalationPolicyService,
+	}
+}
+
+// EscalationPolicyHandler is the handler for the escalation policy service
+type EscalationPolicyHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	EscalationPolicyService influxdb.EscalationPolicyService
+}
+
+// NewEscalationPolicyHandler creates a new EscalationPolicyHandler
+func NewEscalationPolicyHandler(log *zap.Logger, b *EscalationPolicyBackend) *EscalationPolicyHandler {
+	h := &EscalationPolicyHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		EscalationPolicyService: b.EscalationPolicyService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", prefixEscalationPolicies)
+
+	h.HandlerFunc("GET", prefixEscalationPolicies, h.handleGetEscalationPolicies)
+	h.HandlerFunc("POST", prefixEscalationPolicies, h.handlePostEscalationPolicy)
+	h.HandlerFunc("GET", entityPath, h.handleGetEscalationPolicy)
+	h.HandlerFunc("PATCH", entityPath, h.handlePatchEscalationPolicy)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteEscalationPolicy)
+
+	return h
+}
+
+type escalationPolicyLinks struct {
+	Self string `json:"self"`
+	Org  string `json:"org"`
+}
+
+type escalationPolicyResponse struct {
+	*influxdb.EscalationPolicy
+	Links escalationPolicyLinks `json:"links"`
+}
+
+func newEscalationPolicyResponse(s *influxdb.EscalationPolicy) escalationPolicyResponse {
+	return escalationPolicyResponse{
+		EscalationPolicy: s,
+		Links: escalationPolicyLinks{
+			Self: fmt.Sprintf("%s/%s", prefixEscalationPolicies, s.ID),
+			Org:  fmt.Sprintf("/api/v2/orgs/%s", s.OrgID),
+		},
+	}
+}
+
+type getEscalationPoliciesResponse struct {
+	EscalationPolicies []escalationPolicyResponse `json:"escalationPolicies"`
+	Links              *influxdb.PagingLinks      `json:"links"`
+}
+
+func (r getEscalationPoliciesResponse) toInfluxDB() []*influxdb.EscalationPolicy {
+	policies := make([]*influxdb.EscalationPolicy, len(r.EscalationPolicies))
+	for i := range r.EscalationPolicies {
+		policies[i] = r.EscalationPolicies[i].EscalationPolicy
+	}
+	return policies
+}
+
+func newGetEscalationPoliciesResponse(policies []*influxdb.EscalationPolicy, f influxdb.EscalationPolicyFilter, opts influxdb.FindOptions) getEscalationPoliciesResponse {
+	resp := getEscalationPoliciesResponse{
+		EscalationPolicies: make([]escalationPolicyResponse, 0, len(policies)),
+		Links:              newPagingLinks(prefixEscalationPolicies, opts, f, len(policies)),
+	}
+	for _, s := range policies {
+		resp.EscalationPolicies = append(resp.EscalationPolicies, newEscalationPolicyResponse(s))
+	}
+	return resp
+}
+
+type getEscalationPoliciesRequest struct {
+	filter influxdb.EscalationPolicyFilter
+	opts   influxdb.FindOptions
+}
+
+func decodeGetEscalationPoliciesRequest(r *http.Request) (*getEscalationPoliciesRequest, error) {
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &getEscalationPoliciesRequest{
+		opts: *opts,
+	}
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.OrgID = id
+	}
+	if org := qp.Get("org"); org != "" {
+		req.filter.Organization = &org
+	}
+	if req.filter.OrgID == nil && req.filter.Organization == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID or org is required",
+		}
+	}
+
+	return req, nil
+}
+
+func (h *EscalationPolicyHandler) handleGetEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetEscalationPoliciesRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	policies, _, err := h.EscalationPolicyService.FindEscalationPolicies(ctx, req.filter, req.opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("EscalationPolicies retrieved", zap.String("policies", fmt.Sprint(policies)))
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetEscalationPoliciesResponse(policies, req.filter, req.opts)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *EscalationPolicyHandler) handleGetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestEscalationPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	s, err := h.EscalationPolicyService.FindEscalationPolicyByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Escalation policy retrieved", zap.String("escalationPolicy", fmt.Sprint(s)))
+	if err := encodeResponse(ctx, w, http.StatusOK, newEscalationPolicyResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *EscalationPolicyHandler) handlePostEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	s := &influxdb.EscalationPolicy{}
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode escalation policy",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.EscalationPolicyService.CreateEscalationPolicy(ctx, s); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Escalation policy created", zap.String("escalationPolicy", fmt.Sprint(s)))
+	if err := encodeResponse(ctx, w, http.StatusCreated, newEscalationPolicyResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *EscalationPolicyHandler) handlePatchEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestEscalationPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.EscalationPolicyUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode escalation policy update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	s, err := h.EscalationPolicyService.UpdateEscalationPolicy(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Escalation policy updated", zap.String("escalationPolicy", fmt.Sprint(s)))
+	if err := encodeResponse(ctx, w, http.StatusOK, newEscalationPolicyResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *EscalationPolicyHandler) handleDeleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestEscalationPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.EscalationPolicyService.DeleteEscalationPolicy(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Escalation policy deleted", zap.String("escalationPolicyID", fmt.Sprint(id)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func requestEscalationPolicyID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := influxdb.IDFromString(urlID)
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return *id, nil
+}
+
+// EscalationPolicyService is an escalation policy service over HTTP to the influxdb server
+type EscalationPolicyService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.EscalationPolicyService = (*EscalationPolicyService)(nil)
+
+// FindEscalationPolicyByID finds a single escalation policy by its ID.
+func (s *EscalationPolicyService) FindEscalationPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+	var resp escalationPolicyResponse
+	err := s.Client.
+		Get(prefixEscalationPolicies, id.String()).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.EscalationPolicy, nil
+}
+
+// FindEscalationPolicies returns the policies that match filter.
+func (s *EscalationPolicyService) FindEscalationPolicies(ctx context.Context, filter influxdb.EscalationPolicyFilter, opts ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error) {
+	params := findOptionParams(opts...)
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Organization != nil {
+		params = append(params, [2]string{"org", *filter.Organization})
+	}
+
+	var resp getEscalationPoliciesResponse
+	err := s.Client.
+		Get(prefixEscalationPolicies).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	policies := resp.toInfluxDB()
+	return policies, len(policies), nil
+}
+
+// CreateEscalationPolicy creates an escalation policy and sets its ID.
+func (s *EscalationPolicyService) CreateEscalationPolicy(ctx context.Context, p *influxdb.EscalationPolicy) error {
+	var resp escalationPolicyResponse
+	err := s.Client.
+		PostJSON(p, prefixEscalationPolicies).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*p = *resp.EscalationPolicy
+	return nil
+}
+
+// UpdateEscalationPolicy updates an escalation policy with a changeset.
+func (s *EscalationPolicyService) UpdateEscalationPolicy(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error) {
+	var resp escalationPolicyResponse
+	err := s.Client.
+		PatchJSON(upd, prefixEscalationPolicies, id.String()).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.EscalationPolicy, nil
+}
+
+// DeleteEscalationPolicy removes an escalation policy by its ID.
+func (s *EscalationPolicyService) DeleteEscalationPolicy(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(prefixEscalationPolicies, id.String()).
+		Do(ctx)
+}
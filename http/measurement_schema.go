@@ -0,0 +1,338 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	bucketsIDSchemaMeasurementsPath   = "/api/v2/buckets/:id/schema/measurements"
+	bucketsIDSchemaMeasurementsIDPath = "/api/v2/buckets/:id/schema/measurements/:measurementID"
+)
+
+type measurementSchemaResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.MeasurementSchema
+}
+
+func newMeasurementSchemaResponse(ms *influxdb.MeasurementSchema) *measurementSchemaResponse {
+	return &measurementSchemaResponse{
+		Links: map[string]string{
+			"self":   measurementSchemaIDPath(ms.BucketID, ms.ID),
+			"bucket": bucketIDPath(ms.BucketID),
+		},
+		MeasurementSchema: ms,
+	}
+}
+
+type measurementSchemasResponse struct {
+	Links              map[string]string            `json:"links"`
+	MeasurementSchemas []*measurementSchemaResponse `json:"measurementSchemas"`
+}
+
+func newMeasurementSchemasResponse(bucketID influxdb.ID, schemas []*influxdb.MeasurementSchema) *measurementSchemasResponse {
+	res := &measurementSchemasResponse{
+		Links: map[string]string{
+			"self": measurementSchemasPath(bucketID),
+		},
+		MeasurementSchemas: make([]*measurementSchemaResponse, 0, len(schemas)),
+	}
+	for _, ms := range schemas {
+		res.MeasurementSchemas = append(res.MeasurementSchemas, newMeasurementSchemaResponse(ms))
+	}
+	return res
+}
+
+type postMeasurementSchemaRequest struct {
+	Name    string                             `json:"name"`
+	Columns []influxdb.MeasurementSchemaColumn `json:"columns"`
+}
+
+func (r *postMeasurementSchemaRequest) OK() error {
+	if r.Name == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "measurement schema name is required",
+		}
+	}
+	return nil
+}
+
+// handleGetMeasurementSchemas is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handleGetMeasurementSchemas(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	b, err := h.findMeasurementSchemaBucket(ctx)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	filter := influxdb.MeasurementSchemaFilter{BucketID: b.ID}
+	if name := r.URL.Query().Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	schemas, err := h.MeasurementSchemaService.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schemas retrieved", zap.String("bucket", b.ID.String()), zap.Int("schemas", len(schemas)))
+
+	h.api.Respond(w, http.StatusOK, newMeasurementSchemasResponse(b.ID, schemas))
+}
+
+// handlePostMeasurementSchema is the HTTP handler for the POST /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handlePostMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	b, err := h.findMeasurementSchemaBucket(ctx)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	var req postMeasurementSchemaRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ms := &influxdb.MeasurementSchema{
+		OrgID:    b.OrgID,
+		BucketID: b.ID,
+		Name:     req.Name,
+		Columns:  req.Columns,
+	}
+	if err := h.MeasurementSchemaService.CreateMeasurementSchema(ctx, ms); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema created", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusCreated, newMeasurementSchemaResponse(ms))
+}
+
+// handleGetMeasurementSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handleGetMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findMeasurementSchema(ctx)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema retrieved", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusOK, newMeasurementSchemaResponse(ms))
+}
+
+// handlePatchMeasurementSchema is the HTTP handler for the PATCH /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handlePatchMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findMeasurementSchema(ctx)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	var upd influxdb.MeasurementSchemaUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ms, err = h.MeasurementSchemaService.UpdateMeasurementSchema(ctx, ms.ID, upd)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema updated", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusOK, newMeasurementSchemaResponse(ms))
+}
+
+// handleDeleteMeasurementSchema is the HTTP handler for the DELETE /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handleDeleteMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ms, err := h.findMeasurementSchema(ctx)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	if err := h.MeasurementSchemaService.DeleteMeasurementSchema(ctx, ms.ID); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema deleted", zap.String("schemaID", ms.ID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findMeasurementSchemaBucket returns the bucket of the request path. Only
+// buckets with an explicit schema can have measurement schemas.
+func (h *BucketHandler) findMeasurementSchemaBucket(ctx context.Context) (*influxdb.Bucket, error) {
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.SchemaType != influxdb.SchemaTypeExplicit {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  fmt.Sprintf("bucket %q does not have an explicit schema", b.Name),
+		}
+	}
+	return b, nil
+}
+
+// findMeasurementSchema returns the measurement schema of the request path,
+// ensuring it belongs to the bucket of the path.
+func (h *BucketHandler) findMeasurementSchema(ctx context.Context) (*influxdb.MeasurementSchema, error) {
+	bucketID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := decodeIDFromCtx(ctx, "measurementID")
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := h.MeasurementSchemaService.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ms.BucketID != bucketID {
+		return nil, influxdb.ErrMeasurementSchemaNotFound
+	}
+	return ms, nil
+}
+
+func measurementSchemasPath(bucketID influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/buckets/%s/schema/measurements", bucketID)
+}
+
+func measurementSchemaIDPath(bucketID, id influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/buckets/%s/schema/measurements/%s", bucketID, id)
+}
+
+// MeasurementSchemaService connects to Influx via HTTP using tokens to manage
+// the measurement schemas of a bucket.
+type MeasurementSchemaService struct {
+	Client *httpc.Client
+	// BucketID is the bucket whose measurement schemas are managed. It is
+	// required because measurement schemas are a sub-resource of buckets.
+	BucketID influxdb.ID
+}
+
+var _ influxdb.MeasurementSchemaService = (*MeasurementSchemaService)(nil)
+
+// FindMeasurementSchemaByID returns a single measurement schema by ID.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res measurementSchemaResponse
+	err := s.Client.
+		Get(measurementSchemaIDPath(s.BucketID, id)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.MeasurementSchema, nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas of the bucket that match filter.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	bucketID := s.BucketID
+	if filter.BucketID.Valid() {
+		bucketID = filter.BucketID
+	}
+
+	var params [][2]string
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var res measurementSchemasResponse
+	err := s.Client.
+		Get(measurementSchemasPath(bucketID)).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]*influxdb.MeasurementSchema, 0, len(res.MeasurementSchemas))
+	for _, ms := range res.MeasurementSchemas {
+		schemas = append(schemas, ms.MeasurementSchema)
+	}
+	return schemas, nil
+}
+
+// CreateMeasurementSchema creates a new measurement schema and sets ms.ID with the new identifier.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	bucketID := s.BucketID
+	if ms.BucketID.Valid() {
+		bucketID = ms.BucketID
+	}
+
+	var res measurementSchemaResponse
+	err := s.Client.
+		PostJSON(postMeasurementSchemaRequest{Name: ms.Name, Columns: ms.Columns}, measurementSchemasPath(bucketID)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*ms = *res.MeasurementSchema
+	return nil
+}
+
+// UpdateMeasurementSchema adds columns to a measurement schema.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res measurementSchemaResponse
+	err := s.Client.
+		PatchJSON(upd, measurementSchemaIDPath(s.BucketID, id)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.MeasurementSchema, nil
+}
+
+// DeleteMeasurementSchema removes a measurement schema by ID.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(measurementSchemaIDPath(s.BucketID, id)).
+		Do(ctx)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 
@@ -22,11 +23,12 @@ type NotificationEndpointBackend struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
+	NotificationEndpointService     influxdb.NotificationEndpointService
+	NotificationEndpointTestService influxdb.NotificationEndpointTestService
+	UserResourceMappingService      influxdb.UserResourceMappingService
+	LabelService                    influxdb.LabelService
+	UserService                     influxdb.UserService
+	OrganizationService             influxdb.OrganizationService
 }
 
 // NewNotificationEndpointBackend returns a new instance of NotificationEndpointBackend.
@@ -35,11 +37,12 @@ func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend) *Notificatio
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
+		NotificationEndpointService:     b.NotificationEndpointService,
+		NotificationEndpointTestService: b.NotificationEndpointTestService,
+		UserResourceMappingService:      b.UserResourceMappingService,
+		LabelService:                    b.LabelService,
+		UserService:                     b.UserService,
+		OrganizationService:             b.OrganizationService,
 	}
 }
 
@@ -53,16 +56,18 @@ type NotificationEndpointHandler struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
+	NotificationEndpointService     influxdb.NotificationEndpointService
+	NotificationEndpointTestService influxdb.NotificationEndpointTestService
+	UserResourceMappingService      influxdb.UserResourceMappingService
+	LabelService                    influxdb.LabelService
+	UserService                     influxdb.UserService
+	OrganizationService             influxdb.OrganizationService
 }
 
 const (
 	prefixNotificationEndpoints          = "/api/v2/notificationEndpoints"
 	notificationEndpointsIDPath          = "/api/v2/notificationEndpoints/:id"
+	notificationEndpointsIDTestPath      = "/api/v2/notificationEndpoints/:id/test"
 	notificationEndpointsIDMembersPath   = "/api/v2/notificationEndpoints/:id/members"
 	notificationEndpointsIDMembersIDPath = "/api/v2/notificationEndpoints/:id/members/:userID"
 	notificationEndpointsIDOwnersPath    = "/api/v2/notificationEndpoints/:id/owners"
@@ -78,11 +83,12 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
+		NotificationEndpointService:     b.NotificationEndpointService,
+		NotificationEndpointTestService: b.NotificationEndpointTestService,
+		UserResourceMappingService:      b.UserResourceMappingService,
+		LabelService:                    b.LabelService,
+		UserService:                     b.UserService,
+		OrganizationService:             b.OrganizationService,
 	}
 	h.HandlerFunc("POST", prefixNotificationEndpoints, h.handlePostNotificationEndpoint)
 	h.HandlerFunc("GET", prefixNotificationEndpoints, h.handleGetNotificationEndpoints)
@@ -90,6 +96,7 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 	h.HandlerFunc("DELETE", notificationEndpointsIDPath, h.handleDeleteNotificationEndpoint)
 	h.HandlerFunc("PUT", notificationEndpointsIDPath, h.handlePutNotificationEndpoint)
 	h.HandlerFunc("PATCH", notificationEndpointsIDPath, h.handlePatchNotificationEndpoint)
+	h.HandlerFunc("POST", notificationEndpointsIDTestPath, h.handlePostNotificationEndpointTest)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -549,6 +556,37 @@ func (h *NotificationEndpointHandler) handleDeleteNotificationEndpoint(w http.Re
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handlePostNotificationEndpointTest sends a test message to a notification endpoint.
+// The body of the request is optional.
+func (h *NotificationEndpointHandler) handlePostNotificationEndpointTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var test influxdb.NotificationEndpointTest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&test); err != nil && err != io.EOF {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "failed to decode request",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	if err := h.NotificationEndpointTestService.TestNotificationEndpoint(ctx, id, test); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("NotificationEndpoint tested", zap.String("notificationEndpointID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // NotificationEndpointService is an http client for the influxdb.NotificationEndpointService server implementation.
 type NotificationEndpointService struct {
 	Client *httpc.Client
@@ -570,6 +608,7 @@ func NewNotificationEndpointService(client *httpc.Client) *NotificationEndpointS
 }
 
 var _ influxdb.NotificationEndpointService = (*NotificationEndpointService)(nil)
+var _ influxdb.NotificationEndpointTestService = (*NotificationEndpointService)(nil)
 
 // FindNotificationEndpointByID returns a single notification endpoint by ID.
 func (s *NotificationEndpointService) FindNotificationEndpointByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
@@ -682,6 +721,13 @@ func (s *NotificationEndpointService) DeleteNotificationEndpoint(ctx context.Con
 	return nil, 0, err
 }
 
+// TestNotificationEndpoint sends a test message to the notification endpoint of id.
+func (s *NotificationEndpointService) TestNotificationEndpoint(ctx context.Context, id influxdb.ID, t influxdb.NotificationEndpointTest) error {
+	return s.Client.
+		PostJSON(t, prefixNotificationEndpoints, id.String(), "test").
+		Do(ctx)
+}
+
 type notificationEndpointEncoder struct {
 	ne influxdb.NotificationEndpoint
 }
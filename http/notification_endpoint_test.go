@@ -1135,3 +1135,70 @@ func authCtxFn(userID influxdb.ID) func(context.Context) context.Context {
 		return pcontext.SetAuthorizer(ctx, &influxdb.Session{UserID: userID})
 	}
 }
+
+func TestService_handlePostNotificationEndpointTest(t *testing.T) {
+	t.Run("sends the test message", func(t *testing.T) {
+		var (
+			gotID   influxdb.ID
+			gotTest influxdb.NotificationEndpointTest
+		)
+		testSvc := mock.NewNotificationEndpointTestService()
+		testSvc.TestNotificationEndpointF = func(ctx context.Context, id influxdb.ID, test influxdb.NotificationEndpointTest) error {
+			gotID, gotTest = id, test
+			return nil
+		}
+		notificationEndpointBackend := NewMockNotificationEndpointBackend(t)
+		notificationEndpointBackend.NotificationEndpointTestService = testSvc
+
+		testttp.
+			PostJSON(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), influxdb.NotificationEndpointTest{Level: "CRIT", Text: "hello"}).
+			Do(NewNotificationEndpointHandler(zaptest.NewLogger(t), notificationEndpointBackend)).
+			ExpectStatus(http.StatusNoContent)
+
+		if gotID != influxTesting.MustIDBase16("020f755c3c082000") || gotTest.Level != "CRIT" || gotTest.Text != "hello" {
+			t.Fatalf("unexpected test of endpoint %s: %+v", gotID, gotTest)
+		}
+	})
+
+	t.Run("without a body", func(t *testing.T) {
+		var gotTest *influxdb.NotificationEndpointTest
+		testSvc := mock.NewNotificationEndpointTestService()
+		testSvc.TestNotificationEndpointF = func(ctx context.Context, id influxdb.ID, test influxdb.NotificationEndpointTest) error {
+			gotTest = &test
+			return nil
+		}
+		notificationEndpointBackend := NewMockNotificationEndpointBackend(t)
+		notificationEndpointBackend.NotificationEndpointTestService = testSvc
+
+		testttp.
+			Post(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), nil).
+			Do(NewNotificationEndpointHandler(zaptest.NewLogger(t), notificationEndpointBackend)).
+			ExpectStatus(http.StatusNoContent)
+
+		if gotTest == nil || *gotTest != (influxdb.NotificationEndpointTest{}) {
+			t.Fatalf("expected the default test message, got %+v", gotTest)
+		}
+	})
+
+	t.Run("endpoint error", func(t *testing.T) {
+		testSvc := mock.NewNotificationEndpointTestService()
+		testSvc.TestNotificationEndpointF = func(ctx context.Context, id influxdb.ID, test influxdb.NotificationEndpointTest) error {
+			return &influxdb.Error{
+				Code: influxdb.EUnavailable,
+				Msg:  "notification endpoint responded with status 404: no_team",
+			}
+		}
+		notificationEndpointBackend := NewMockNotificationEndpointBackend(t)
+		notificationEndpointBackend.NotificationEndpointTestService = testSvc
+
+		testttp.
+			Post(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), nil).
+			Do(NewNotificationEndpointHandler(zaptest.NewLogger(t), notificationEndpointBackend)).
+			ExpectStatus(http.StatusServiceUnavailable).
+			ExpectBody(func(body *bytes.Buffer) {
+				if !bytes.Contains(body.Bytes(), []byte("no_team")) {
+					t.Errorf("expected the error of the endpoint, got %s", body)
+				}
+			})
+	})
+}
@@ -25,13 +25,14 @@ type NotificationRuleBackend struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationRuleStore       influxdb.NotificationRuleStore
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
-	TaskService                 influxdb.TaskService
+	NotificationRuleStore         influxdb.NotificationRuleStore
+	NotificationRuleDryRunService influxdb.NotificationRuleDryRunService
+	NotificationEndpointService   influxdb.NotificationEndpointService
+	UserResourceMappingService    influxdb.UserResourceMappingService
+	LabelService                  influxdb.LabelService
+	UserService                   influxdb.UserService
+	OrganizationService           influxdb.OrganizationService
+	TaskService                   influxdb.TaskService
 }
 
 // NewNotificationRuleBackend returns a new instance of NotificationRuleBackend.
@@ -40,13 +41,14 @@ func NewNotificationRuleBackend(log *zap.Logger, b *APIBackend) *NotificationRul
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationRuleStore:       b.NotificationRuleStore,
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
-		TaskService:                 b.TaskService,
+		NotificationRuleStore:         b.NotificationRuleStore,
+		NotificationRuleDryRunService: b.NotificationRuleDryRunService,
+		NotificationEndpointService:   b.NotificationEndpointService,
+		UserResourceMappingService:    b.UserResourceMappingService,
+		LabelService:                  b.LabelService,
+		UserService:                   b.UserService,
+		OrganizationService:           b.OrganizationService,
+		TaskService:                   b.TaskService,
 	}
 }
 
@@ -56,19 +58,21 @@ type NotificationRuleHandler struct {
 	influxdb.HTTPErrorHandler
 	log *zap.Logger
 
-	NotificationRuleStore       influxdb.NotificationRuleStore
-	NotificationEndpointService influxdb.NotificationEndpointService
-	UserResourceMappingService  influxdb.UserResourceMappingService
-	LabelService                influxdb.LabelService
-	UserService                 influxdb.UserService
-	OrganizationService         influxdb.OrganizationService
-	TaskService                 influxdb.TaskService
+	NotificationRuleStore         influxdb.NotificationRuleStore
+	NotificationRuleDryRunService influxdb.NotificationRuleDryRunService
+	NotificationEndpointService   influxdb.NotificationEndpointService
+	UserResourceMappingService    influxdb.UserResourceMappingService
+	LabelService                  influxdb.LabelService
+	UserService                   influxdb.UserService
+	OrganizationService           influxdb.OrganizationService
+	TaskService                   influxdb.TaskService
 }
 
 const (
 	prefixNotificationRules          = "/api/v2/notificationRules"
 	notificationRulesIDPath          = "/api/v2/notificationRules/:id"
 	notificationRulesIDQueryPath     = "/api/v2/notificationRules/:id/query"
+	notificationRulesIDDryRunPath    = "/api/v2/notificationRules/:id/dryrun"
 	notificationRulesIDMembersPath   = "/api/v2/notificationRules/:id/members"
 	notificationRulesIDMembersIDPath = "/api/v2/notificationRules/:id/members/:userID"
 	notificationRulesIDOwnersPath    = "/api/v2/notificationRules/:id/owners"
@@ -84,18 +88,20 @@ func NewNotificationRuleHandler(log *zap.Logger, b *NotificationRuleBackend) *No
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		NotificationRuleStore:       b.NotificationRuleStore,
-		NotificationEndpointService: b.NotificationEndpointService,
-		UserResourceMappingService:  b.UserResourceMappingService,
-		LabelService:                b.LabelService,
-		UserService:                 b.UserService,
-		OrganizationService:         b.OrganizationService,
-		TaskService:                 b.TaskService,
+		NotificationRuleStore:         b.NotificationRuleStore,
+		NotificationRuleDryRunService: b.NotificationRuleDryRunService,
+		NotificationEndpointService:   b.NotificationEndpointService,
+		UserResourceMappingService:    b.UserResourceMappingService,
+		LabelService:                  b.LabelService,
+		UserService:                   b.UserService,
+		OrganizationService:           b.OrganizationService,
+		TaskService:                   b.TaskService,
 	}
 	h.HandlerFunc("POST", prefixNotificationRules, h.handlePostNotificationRule)
 	h.HandlerFunc("GET", prefixNotificationRules, h.handleGetNotificationRules)
 	h.HandlerFunc("GET", notificationRulesIDPath, h.handleGetNotificationRule)
 	h.HandlerFunc("GET", notificationRulesIDQueryPath, h.handleGetNotificationRuleQuery)
+	h.HandlerFunc("POST", notificationRulesIDDryRunPath, h.handlePostNotificationRuleDryRun)
 	h.HandlerFunc("DELETE", notificationRulesIDPath, h.handleDeleteNotificationRule)
 	h.HandlerFunc("PUT", notificationRulesIDPath, h.handlePutNotificationRule)
 	h.HandlerFunc("PATCH", notificationRulesIDPath, h.handlePatchNotificationRule)
@@ -315,6 +321,28 @@ func (h *NotificationRuleHandler) handleGetNotificationRuleQuery(w http.Response
 	}
 }
 
+// handlePostNotificationRuleDryRun evaluates a notification rule against the
+// recent statuses of its organization without sending its notifications.
+func (h *NotificationRuleHandler) handlePostNotificationRuleDryRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeGetNotificationRuleRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	res, err := h.NotificationRuleDryRunService.DryRunNotificationRule(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Notification rule dry run", zap.String("notificationRuleID", id.String()), zap.Int("notifications", len(res.Notifications)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 func (h *NotificationRuleHandler) handleGetNotificationRule(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, err := decodeGetNotificationRuleRequest(ctx, r)
@@ -374,6 +402,18 @@ func decodeNotificationRuleFilter(ctx context.Context, r *http.Request) (*influx
 		*f.Organization = orgNameStr
 	}
 
+	if taskIDStr := q.Get("taskID"); taskIDStr != "" {
+		taskID, err := influxdb.IDFromString(taskIDStr)
+		if err != nil {
+			return f, opts, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "taskID is invalid",
+				Err:  err,
+			}
+		}
+		f.TaskID = taskID
+	}
+
 	for _, tag := range q["tag"] {
 		tp, err := influxdb.NewTag(tag)
 		// ignore malformed tag pairs
@@ -1,14 +1,20 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/notification"
 	"github.com/influxdata/influxdb/notification/rule"
+	"github.com/influxdata/influxdb/pkg/testttp"
 	influxTesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -314,3 +320,63 @@ func Test_newNotificationRuleResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestService_handlePostNotificationRuleDryRun(t *testing.T) {
+	now := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	dryRunSvc := mock.NewNotificationRuleDryRunService()
+	dryRunSvc.DryRunNotificationRuleF = func(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error) {
+		return &influxdb.NotificationRuleDryRunResult{
+			RuleID: id,
+			Time:   now,
+			Notifications: []*influxdb.NotificationRuleDryRunNotification{
+				{
+					AlertStatus: influxdb.AlertStatus{
+						Time:      now.Add(-time.Minute),
+						CheckID:   10,
+						CheckName: "cpu",
+						CheckType: "threshold",
+						Level:     "CRIT",
+						Message:   "cpu is high",
+						Tags:      []influxdb.Tag{{Key: "host", Value: "a"}},
+					},
+					Sent: true,
+				},
+			},
+		}, nil
+	}
+	notificationRuleBackend := NewMockNotificationRuleBackend(t)
+	notificationRuleBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	notificationRuleBackend.NotificationRuleDryRunService = dryRunSvc
+
+	testttp.
+		Post(t, path.Join(prefixNotificationRules, "020f755c3c082000", "dryrun"), nil).
+		Do(NewNotificationRuleHandler(zaptest.NewLogger(t), notificationRuleBackend)).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			expected := `
+{
+  "ruleID": "020f755c3c082000",
+  "time": "2020-01-01T01:00:00Z",
+  "notifications": [
+    {
+      "time": "2020-01-01T00:59:00Z",
+      "checkID": "000000000000000a",
+      "checkName": "cpu",
+      "checkType": "threshold",
+      "level": "CRIT",
+      "message": "cpu is high",
+      "tags": [{"key": "host", "value": "a"}],
+      "sent": true,
+      "silenced": false,
+      "grouped": false
+    }
+  ]
+}`
+			if eq, diff, err := jsonEqual(body.String(), expected); err != nil {
+				t.Fatalf("error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handlePostNotificationRuleDryRun() = ***%s***", diff)
+			}
+		})
+}
@@ -0,0 +1,306 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	tasksIDBackfillPath   = "/api/v2/tasks/:id/backfill"
+	tasksIDBackfillIDPath = "/api/v2/tasks/:id/backfill/:bid"
+)
+
+type backfillResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.Backfill
+}
+
+func newBackfillResponse(b *influxdb.Backfill) *backfillResponse {
+	return &backfillResponse{
+		Links: map[string]string{
+			"self": taskBackfillIDPath(b.TaskID, b.ID),
+			"task": taskIDPath(b.TaskID),
+		},
+		Backfill: b,
+	}
+}
+
+type backfillsResponse struct {
+	Links     map[string]string   `json:"links"`
+	Backfills []*backfillResponse `json:"backfills"`
+}
+
+func newBackfillsResponse(taskID influxdb.ID, backfills []*influxdb.Backfill) *backfillsResponse {
+	res := &backfillsResponse{
+		Links: map[string]string{
+			"self": taskBackfillPath(taskID),
+		},
+		Backfills: make([]*backfillResponse, 0, len(backfills)),
+	}
+	for _, b := range backfills {
+		res.Backfills = append(res.Backfills, newBackfillResponse(b))
+	}
+	return res
+}
+
+type postBackfillRequest struct {
+	Start       time.Time `json:"start"`
+	Stop        time.Time `json:"stop"`
+	Concurrency int       `json:"concurrency,omitempty"`
+}
+
+// handleGetBackfills is the HTTP handler for the GET /api/v2/tasks/:id/backfill route.
+func (h *TaskHandler) handleGetBackfills(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	filter := influxdb.BackfillFilter{TaskID: &taskID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter.Status = &status
+	}
+
+	backfills, err := h.TaskBackfillService.FindBackfills(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Backfills retrieved", zap.String("taskID", taskID.String()), zap.Int("backfills", len(backfills)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillsResponse(taskID, backfills)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostBackfill is the HTTP handler for the POST /api/v2/tasks/:id/backfill route.
+func (h *TaskHandler) handlePostBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req postBackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	// the task is found to authorize the backfill against its organization.
+	t, err := h.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.TaskBackfillService.CreateBackfill(ctx, influxdb.BackfillCreate{
+		TaskID:      t.ID,
+		OrgID:       t.OrganizationID,
+		Start:       req.Start,
+		Stop:        req.Stop,
+		Concurrency: req.Concurrency,
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Backfill created", zap.String("backfill", fmt.Sprint(b)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetBackfill is the HTTP handler for the GET /api/v2/tasks/:id/backfill/:bid route.
+func (h *TaskHandler) handleGetBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	b, err := h.findBackfill(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Backfill retrieved", zap.String("backfill", fmt.Sprint(b)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleCancelBackfill is the HTTP handler for the DELETE /api/v2/tasks/:id/backfill/:bid route.
+func (h *TaskHandler) handleCancelBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	b, err := h.findBackfill(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err = h.TaskBackfillService.CancelBackfill(ctx, b.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Backfill canceled", zap.String("backfillID", b.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// findBackfill returns the backfill of the request path, ensuring it belongs
+// to the task of the path.
+func (h *TaskHandler) findBackfill(ctx context.Context) (*influxdb.Backfill, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := decodeIDFromCtx(ctx, "bid")
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := h.TaskBackfillService.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.TaskID != taskID {
+		return nil, influxdb.ErrBackfillNotFound
+	}
+	return b, nil
+}
+
+func taskBackfillPath(taskID influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/tasks/%s/backfill", taskID)
+}
+
+func taskBackfillIDPath(taskID, id influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/tasks/%s/backfill/%s", taskID, id)
+}
+
+// TaskBackfillService connects to Influx via HTTP using tokens to manage the
+// backfills of a task.
+type TaskBackfillService struct {
+	Client *httpc.Client
+	// TaskID is the task whose backfills are managed. It is required because
+	// backfills are a sub-resource of tasks.
+	TaskID influxdb.ID
+}
+
+var _ influxdb.TaskBackfillService = (*TaskBackfillService)(nil)
+
+// FindBackfillByID returns a single backfill by ID.
+func (s *TaskBackfillService) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res backfillResponse
+	err := s.Client.
+		Get(taskBackfillIDPath(s.TaskID, id)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Backfill, nil
+}
+
+// FindBackfills returns the backfills of the task that match filter.
+func (s *TaskBackfillService) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	taskID := s.TaskID
+	if filter.TaskID != nil {
+		taskID = *filter.TaskID
+	}
+
+	var params [][2]string
+	if filter.Status != nil {
+		params = append(params, [2]string{"status", *filter.Status})
+	}
+
+	var res backfillsResponse
+	err := s.Client.
+		Get(taskBackfillPath(taskID)).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backfills := make([]*influxdb.Backfill, 0, len(res.Backfills))
+	for _, b := range res.Backfills {
+		backfills = append(backfills, b.Backfill)
+	}
+	return backfills, nil
+}
+
+// CreateBackfill creates a backfill of the task and starts enqueueing its runs.
+func (s *TaskBackfillService) CreateBackfill(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	taskID := s.TaskID
+	if bc.TaskID.Valid() {
+		taskID = bc.TaskID
+	}
+
+	req := postBackfillRequest{
+		Start:       bc.Start,
+		Stop:        bc.Stop,
+		Concurrency: bc.Concurrency,
+	}
+
+	var res backfillResponse
+	err := s.Client.
+		PostJSON(req, taskBackfillPath(taskID)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Backfill, nil
+}
+
+// CancelBackfill cancels a running backfill of the task.
+func (s *TaskBackfillService) CancelBackfill(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res backfillResponse
+	err := s.Client.
+		Delete(taskBackfillIDPath(s.TaskID, id)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Backfill, nil
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskHandler_handleGetBackfill(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	backfill := &influxdb.Backfill{
+		ID:          2,
+		TaskID:      1,
+		OrgID:       3,
+		Start:       start,
+		Stop:        start.Add(3 * time.Hour),
+		Concurrency: 1,
+		Status:      influxdb.BackfillStatusRunning,
+		Total:       4,
+		Completed:   1,
+		Cursor:      start.Add(time.Hour),
+		CreatedAt:   start,
+		UpdatedAt:   start,
+	}
+
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name   string
+		taskID influxdb.ID
+		wants  wants
+	}{
+		{
+			name:   "get a backfill by id",
+			taskID: 1,
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000001/backfill/0000000000000002",
+    "task": "/api/v2/tasks/0000000000000001"
+  },
+  "id": "0000000000000002",
+  "taskID": "0000000000000001",
+  "orgID": "0000000000000003",
+  "start": "2020-01-01T00:00:00Z",
+  "stop": "2020-01-01T03:00:00Z",
+  "concurrency": 1,
+  "status": "running",
+  "total": 4,
+  "completed": 1,
+  "failed": 0,
+  "cursor": "2020-01-01T01:00:00Z",
+  "createdAt": "2020-01-01T00:00:00Z",
+  "updatedAt": "2020-01-01T00:00:00Z"
+}`,
+			},
+		},
+		{
+			name:   "backfill of another task",
+			taskID: 4,
+			wants: wants{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backfillSvc := mock.NewTaskBackfillService()
+			backfillSvc.FindBackfillByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+				return backfill, nil
+			}
+
+			r := httptest.NewRequest("GET", "http://any.url", nil)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{Key: "id", Value: tt.taskID.String()},
+					{Key: "bid", Value: backfill.ID.String()},
+				}))
+			w := httptest.NewRecorder()
+			taskBackend := NewMockTaskBackend(t)
+			taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			taskBackend.TaskBackfillService = backfillSvc
+			h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+			h.handleGetBackfill(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handleGetBackfill() = %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%q, handleGetBackfill(). error unmarshaling json %v", tt.name, err)
+				} else if !eq {
+					t.Errorf("%q. handleGetBackfill() = ***%s***", tt.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskHandler_handlePostBackfill(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var created influxdb.BackfillCreate
+	backfillSvc := mock.NewTaskBackfillService()
+	backfillSvc.CreateBackfillF = func(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+		created = bc
+		return &influxdb.Backfill{ID: 2, TaskID: bc.TaskID, OrgID: bc.OrgID, Start: bc.Start, Stop: bc.Stop}, nil
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+			return &influxdb.Task{ID: id, OrganizationID: 3}, nil
+		},
+	}
+	taskBackend.TaskBackfillService = backfillSvc
+	h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+
+	b, err := json.Marshal(postBackfillRequest{Start: start, Stop: start.Add(time.Hour), Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("POST", "http://any.url", bytes.NewReader(b))
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{{Key: "id", Value: influxdb.ID(1).String()}}))
+	w := httptest.NewRecorder()
+	h.handlePostBackfill(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("handlePostBackfill() = %v, want %v: %s", res.StatusCode, http.StatusCreated, body)
+	}
+	want := influxdb.BackfillCreate{TaskID: 1, OrgID: 3, Start: start, Stop: start.Add(time.Hour), Concurrency: 2}
+	if !created.Start.Equal(want.Start) || !created.Stop.Equal(want.Stop) {
+		t.Errorf("unexpected backfill range: got %s to %s", created.Start, created.Stop)
+	}
+	created.Start, created.Stop = want.Start, want.Stop
+	if created != want {
+		t.Errorf("unexpected backfill create: got %+v want %+v", created, want)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+const tasksIDDependenciesPath = "/api/v2/tasks/:id/dependencies"
+
+type taskDependenciesResponse struct {
+	Links map[string]string `json:"links"`
+	// Upstream are the tasks the task depends on.
+	Upstream []Task `json:"upstream"`
+	// Downstream are the tasks that depend on the task.
+	Downstream []Task `json:"downstream"`
+}
+
+func newTaskDependenciesResponse(taskID influxdb.ID, upstream, downstream []*influxdb.Task) *taskDependenciesResponse {
+	res := &taskDependenciesResponse{
+		Links: map[string]string{
+			"self": taskIDDependenciesPath(taskID),
+			"task": taskIDPath(taskID),
+		},
+		Upstream:   make([]Task, 0, len(upstream)),
+		Downstream: make([]Task, 0, len(downstream)),
+	}
+	for _, t := range upstream {
+		res.Upstream = append(res.Upstream, NewFrontEndTask(*t))
+	}
+	for _, t := range downstream {
+		res.Downstream = append(res.Downstream, NewFrontEndTask(*t))
+	}
+	return res
+}
+
+// handleGetTaskDependencies is the HTTP handler for the GET /api/v2/tasks/:id/dependencies route.
+func (h *TaskHandler) handleGetTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	upstream, err := h.findUpstreamTasks(ctx, task)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	downstream, err := h.findDownstreamTasks(ctx, task)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task dependencies retrieved", zap.String("taskID", taskID.String()), zap.Int("upstream", len(upstream)), zap.Int("downstream", len(downstream)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskDependenciesResponse(taskID, upstream, downstream)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// findUpstreamTasks returns the tasks task depends on. Tasks that were deleted,
+// or that the request isn't authorized to read, are left out.
+func (h *TaskHandler) findUpstreamTasks(ctx context.Context, task *influxdb.Task) ([]*influxdb.Task, error) {
+	upstream := make([]*influxdb.Task, 0, len(task.DependsOn))
+	for _, id := range task.DependsOn {
+		t, err := h.TaskService.FindTaskByID(ctx, id)
+		if code := influxdb.ErrorCode(err); code == influxdb.ENotFound || code == influxdb.EUnauthorized {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		upstream = append(upstream, t)
+	}
+	return upstream, nil
+}
+
+// findDownstreamTasks returns the tasks that depend on task.
+func (h *TaskHandler) findDownstreamTasks(ctx context.Context, task *influxdb.Task) ([]*influxdb.Task, error) {
+	var downstream []*influxdb.Task
+	filter := influxdb.TaskFilter{
+		DependsOn: &task.ID,
+		Limit:     influxdb.TaskMaxPageSize,
+	}
+	for {
+		tasks, _, err := h.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		downstream = append(downstream, tasks...)
+		if len(tasks) < filter.Limit {
+			return downstream, nil
+		}
+		filter.After = &tasks[len(tasks)-1].ID
+	}
+}
+
+func taskIDDependenciesPath(id influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/tasks/%s/dependencies", id)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskHandler_handleGetTaskDependencies(t *testing.T) {
+	tasks := map[influxdb.ID]*influxdb.Task{
+		1: {ID: 1, OrganizationID: 3, OwnerID: 5, Name: "raw", Status: "active", Every: "5m"},
+		2: {ID: 2, OrganizationID: 3, OwnerID: 5, Name: "5m", Status: "active", Every: "5m", DependsOn: []influxdb.ID{1, 9}},
+		4: {ID: 4, OrganizationID: 3, OwnerID: 5, Name: "1h", Status: "active", Every: "1h", DependsOn: []influxdb.ID{2}},
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+			if t, ok := tasks[id]; ok {
+				return t, nil
+			}
+			return nil, influxdb.ErrTaskNotFound
+		},
+		FindTasksFn: func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+			if filter.DependsOn == nil || *filter.DependsOn != 2 {
+				t.Fatalf("expected the tasks that depend on 2 to be looked up, got %+v", filter)
+			}
+			return []*influxdb.Task{tasks[4]}, 1, nil
+		},
+	}
+	h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{{Key: "id", Value: influxdb.ID(2).String()}}))
+	w := httptest.NewRecorder()
+	h.handleGetTaskDependencies(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetTaskDependencies() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000002/dependencies",
+    "task": "/api/v2/tasks/0000000000000002"
+  },
+  "upstream": [
+    {
+      "id": "0000000000000001",
+      "orgID": "0000000000000003",
+      "org": "",
+      "ownerID": "0000000000000005",
+      "name": "raw",
+      "status": "active",
+      "flux": "",
+      "every": "5m"
+    }
+  ],
+  "downstream": [
+    {
+      "id": "0000000000000004",
+      "orgID": "0000000000000003",
+      "org": "",
+      "ownerID": "0000000000000005",
+      "name": "1h",
+      "status": "active",
+      "flux": "",
+      "every": "1h",
+      "dependsOn": ["0000000000000002"]
+    }
+  ]
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handleGetTaskDependencies() = ***%s***", diff)
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const tasksDryRunPath = "/api/v2/tasks/dryrun"
+
+type postTaskDryRunRequest struct {
+	OrganizationID influxdb.ID `json:"orgID,omitempty"`
+	Organization   string      `json:"org,omitempty"`
+	Flux           string      `json:"flux"`
+	ScheduledFor   time.Time   `json:"scheduledFor,omitempty"`
+}
+
+// TaskDryRunHandler is the HTTP handler for dry runs of task scripts. It is
+// mounted apart from the TaskHandler, whose router can't route a static path
+// alongside /api/v2/tasks/:id.
+type TaskDryRunHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrganizationService influxdb.OrganizationService
+	TaskDryRunService   influxdb.TaskDryRunService
+}
+
+// NewTaskDryRunHandler returns a new instance of TaskDryRunHandler.
+func NewTaskDryRunHandler(log *zap.Logger, b *TaskBackend) *TaskDryRunHandler {
+	h := &TaskDryRunHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		OrganizationService: b.OrganizationService,
+		TaskDryRunService:   b.TaskDryRunService,
+	}
+
+	h.HandlerFunc("POST", tasksDryRunPath, h.handlePostTaskDryRun)
+	return h
+}
+
+// handlePostTaskDryRun is the HTTP handler for the POST /api/v2/tasks/dryrun route.
+func (h *TaskDryRunHandler) handlePostTaskDryRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postTaskDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if !req.OrganizationID.Valid() {
+		if req.Organization == "" {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "missing orgID and organization name",
+			}, w)
+			return
+		}
+		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &req.Organization})
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		req.OrganizationID = o.ID
+	}
+
+	res, err := h.TaskDryRunService.DryRunTask(ctx, influxdb.TaskDryRun{
+		OrganizationID: req.OrganizationID,
+		Flux:           req.Flux,
+		ScheduledFor:   req.ScheduledFor,
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task dry run", zap.String("orgID", req.OrganizationID.String()), zap.Int("tables", len(res.Tables)), zap.String("error", res.Error))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// TaskDryRunService connects to Influx via HTTP using tokens to dry run task scripts.
+type TaskDryRunService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.TaskDryRunService = (*TaskDryRunService)(nil)
+
+// DryRunTask executes a task script once without persisting what it writes.
+func (s *TaskDryRunService) DryRunTask(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	req := postTaskDryRunRequest{
+		OrganizationID: d.OrganizationID,
+		Flux:           d.Flux,
+		ScheduledFor:   d.ScheduledFor,
+	}
+
+	var res influxdb.TaskDryRunResult
+	err := s.Client.
+		PostJSON(req, tasksDryRunPath).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskDryRunHandler_handlePostTaskDryRun(t *testing.T) {
+	scheduledFor := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	var got influxdb.TaskDryRun
+	dryRunSvc := mock.NewTaskDryRunService()
+	dryRunSvc.DryRunTaskF = func(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error) {
+		got = d
+		return &influxdb.TaskDryRunResult{
+			ScheduledFor: d.ScheduledFor,
+			Tables: []*influxdb.TaskDryRunTable{{
+				OrganizationID: d.OrganizationID,
+				BucketID:       2,
+				Measurement:    "cpu",
+				Tags:           map[string]string{"host": "a"},
+				Rows: []influxdb.TaskDryRunRow{{
+					Time:   scheduledFor.Add(-time.Minute),
+					Fields: map[string]interface{}{"usage": 1.5},
+				}},
+			}},
+			Log: []influxdb.Log{{Time: "2020-01-01T01:00:00Z", Message: "Completed dry run, 1 series would have been written"}},
+		}, nil
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.TaskDryRunService = dryRunSvc
+	h := NewTaskDryRunHandler(zaptest.NewLogger(t), taskBackend)
+
+	body := `{"orgID": "0000000000000003", "flux": "option task = {name: \"t\", every: 1m}", "scheduledFor": "2020-01-01T01:00:00Z"}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/tasks/dryrun", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	resBody, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handlePostTaskDryRun() = %v, want %v: %s", res.StatusCode, http.StatusOK, resBody)
+	}
+
+	exp := influxdb.TaskDryRun{
+		OrganizationID: 3,
+		Flux:           `option task = {name: "t", every: 1m}`,
+		ScheduledFor:   scheduledFor,
+	}
+	if got.OrganizationID != exp.OrganizationID || got.Flux != exp.Flux || !got.ScheduledFor.Equal(exp.ScheduledFor) {
+		t.Fatalf("unexpected dry run %+v, want %+v", got, exp)
+	}
+
+	expBody := `
+{
+  "scheduledFor": "2020-01-01T01:00:00Z",
+  "tables": [
+    {
+      "orgID": "0000000000000003",
+      "bucketID": "0000000000000002",
+      "measurement": "cpu",
+      "tags": {"host": "a"},
+      "rows": [
+        {"time": "2020-01-01T00:59:00Z", "fields": {"usage": 1.5}}
+      ]
+    }
+  ],
+  "log": [
+    {"time": "2020-01-01T01:00:00Z", "message": "Completed dry run, 1 series would have been written"}
+  ]
+}`
+	if eq, diff, err := jsonEqual(string(resBody), expBody); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handlePostTaskDryRun() = ***%s***", diff)
+	}
+}
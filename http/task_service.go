@@ -34,6 +34,9 @@ type TaskBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	TaskBackfillService        influxdb.TaskBackfillService
+	TaskDryRunService          influxdb.TaskDryRunService
+	TaskVersionService         influxdb.TaskVersionService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -48,6 +51,9 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskBackfillService:        b.TaskBackfillService,
+		TaskDryRunService:          b.TaskDryRunService,
+		TaskVersionService:         b.TaskVersionService,
 	}
 }
 
@@ -64,6 +70,8 @@ type TaskHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	TaskBackfillService        influxdb.TaskBackfillService
+	TaskVersionService         influxdb.TaskVersionService
 }
 
 const (
@@ -96,6 +104,8 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskBackfillService:        b.TaskBackfillService,
+		TaskVersionService:         b.TaskVersionService,
 	}
 
 	h.HandlerFunc("GET", prefixTasks, h.handleGetTasks)
@@ -138,6 +148,18 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("GET", tasksIDDependenciesPath, h.handleGetTaskDependencies)
+
+	h.HandlerFunc("GET", tasksIDBackfillPath, h.handleGetBackfills)
+	h.HandlerFunc("POST", tasksIDBackfillPath, h.handlePostBackfill)
+	h.HandlerFunc("GET", tasksIDBackfillIDPath, h.handleGetBackfill)
+	h.HandlerFunc("DELETE", tasksIDBackfillIDPath, h.handleCancelBackfill)
+
+	h.HandlerFunc("GET", tasksIDVersionsPath, h.handleGetTaskVersions)
+	h.HandlerFunc("GET", tasksIDVersionsIDPath, h.handleGetTaskVersion)
+	h.HandlerFunc("GET", tasksIDVersionsIDDiffPath, h.handleGetTaskVersionDiff)
+	h.HandlerFunc("POST", tasksIDVersionsIDRollbackPath, h.handlePostTaskVersionRollback)
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -165,12 +187,18 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          string                 `json:"offset,omitempty"`
+	Location        string                 `json:"location,omitempty"`
+	DependsOn       []influxdb.ID          `json:"dependsOn,omitempty"`
+	Version         int                    `json:"version,omitempty"`
 	LatestCompleted string                 `json:"latestCompleted,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
 	CreatedAt       string                 `json:"createdAt,omitempty"`
 	UpdatedAt       string                 `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	Notification *influxdb.TaskNotification `json:"notification,omitempty"`
+	FailedRuns   int                        `json:"failedRuns,omitempty"`
 }
 
 type taskResponse struct {
@@ -210,12 +238,17 @@ func NewFrontEndTask(t influxdb.Task) Task {
 		Every:           t.Every,
 		Cron:            t.Cron,
 		Offset:          offset,
+		Location:        t.Location,
+		DependsOn:       t.DependsOn,
+		Version:         t.Version,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Metadata:        t.Metadata,
+		Notification:    t.Notification,
+		FailedRuns:      t.FailedRuns,
 	}
 }
 
@@ -335,14 +368,16 @@ type runResponse struct {
 // it uses a pointer to a time.Time instead of a time.Time so that we can pass a nil
 // value for empty time values
 type httpRun struct {
-	ID           influxdb.ID    `json:"id,omitempty"`
-	TaskID       influxdb.ID    `json:"taskID"`
-	Status       string         `json:"status"`
-	ScheduledFor *time.Time     `json:"scheduledFor"`
-	StartedAt    *time.Time     `json:"startedAt,omitempty"`
-	FinishedAt   *time.Time     `json:"finishedAt,omitempty"`
-	RequestedAt  *time.Time     `json:"requestedAt,omitempty"`
-	Log          []influxdb.Log `json:"log,omitempty"`
+	ID           influxdb.ID             `json:"id,omitempty"`
+	TaskID       influxdb.ID             `json:"taskID"`
+	Status       string                  `json:"status"`
+	ScheduledFor *time.Time              `json:"scheduledFor"`
+	StartedAt    *time.Time              `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time              `json:"finishedAt,omitempty"`
+	RequestedAt  *time.Time              `json:"requestedAt,omitempty"`
+	TaskVersion  int                     `json:"taskVersion,omitempty"`
+	Log          []influxdb.Log          `json:"log,omitempty"`
+	Statistics   *influxdb.RunStatistics `json:"statistics,omitempty"`
 }
 
 func newRunResponse(r influxdb.Run) runResponse {
@@ -352,6 +387,8 @@ func newRunResponse(r influxdb.Run) runResponse {
 		Status:       r.Status,
 		Log:          r.Log,
 		ScheduledFor: &r.ScheduledFor,
+		TaskVersion:  r.TaskVersion,
+		Statistics:   r.Statistics,
 	}
 
 	if !r.StartedAt.IsZero() {
@@ -377,10 +414,12 @@ func newRunResponse(r influxdb.Run) runResponse {
 
 func convertRun(r httpRun) *influxdb.Run {
 	run := &influxdb.Run{
-		ID:     r.ID,
-		TaskID: r.TaskID,
-		Status: r.Status,
-		Log:    r.Log,
+		ID:          r.ID,
+		TaskID:      r.TaskID,
+		Status:      r.Status,
+		TaskVersion: r.TaskVersion,
+		Log:         r.Log,
+		Statistics:  r.Statistics,
 	}
 
 	if r.StartedAt != nil {
@@ -496,6 +535,14 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs influxdb.O
 		req.filter.User = id
 	}
 
+	if dependsOn := qp.Get("dependsOn"); dependsOn != "" {
+		id, err := influxdb.IDFromString(dependsOn)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.DependsOn = id
+	}
+
 	if limit := qp.Get("limit"); limit != "" {
 		lim, err := strconv.Atoi(limit)
 		if err != nil {
@@ -518,8 +565,12 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs influxdb.O
 		req.filter.Status = &status
 	}
 
-	// the task api can only create or lookup system tasks.
+	// the task api can only create system tasks, but can look up the tasks
+	// that run bucket downsampling policies when they are requested.
 	req.filter.Type = &influxdb.TaskSystemType
+	if typ := qp.Get("type"); typ == influxdb.TaskDownsamplingType {
+		req.filter.Type = &influxdb.TaskDownsamplingType
+	}
 
 	if name := qp.Get("name"); name != "" {
 		req.filter.Name = &name
@@ -1027,6 +1078,22 @@ func decodeGetRunsRequest(ctx context.Context, r *http.Request) (*getRunsRequest
 		}
 	}
 
+	if n := qp.Get("minRowsWritten"); n != "" {
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.MinRowsWritten = &i
+	}
+
+	if n := qp.Get("maxRowsWritten"); n != "" {
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.MaxRowsWritten = &i
+	}
+
 	return req, nil
 }
 
@@ -1572,6 +1639,13 @@ func (t TaskService) FindRuns(ctx context.Context, filter influxdb.RunFilter) ([
 
 	params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
 
+	if filter.MinRowsWritten != nil {
+		params = append(params, [2]string{"minRowsWritten", strconv.FormatInt(*filter.MinRowsWritten, 10)})
+	}
+	if filter.MaxRowsWritten != nil {
+		params = append(params, [2]string{"maxRowsWritten", strconv.FormatInt(*filter.MaxRowsWritten, 10)})
+	}
+
 	var rs runsResponse
 	err := t.Client.
 		Get(taskIDRunsPath(filter.Task)).
@@ -259,6 +259,66 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
       "flux": ""
     }
   ]
+}`,
+			},
+		},
+		{
+			name:      "get downsampling tasks",
+			getParams: "type=downsampling",
+			fields: fields{
+				taskService: &mock.TaskService{
+					FindTasksFn: func(ctx context.Context, f influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+						if f.Type == nil || *f.Type != influxdb.TaskDownsamplingType {
+							return nil, 0, fmt.Errorf("unexpected task type filter: %v", f.Type)
+						}
+						tasks := []*influxdb.Task{
+							{
+								ID:              3,
+								Type:            influxdb.TaskDownsamplingType,
+								Name:            "task3",
+								OrganizationID:  1,
+								OwnerID:         1,
+								Organization:    "test",
+								AuthorizationID: 0x300,
+							},
+						}
+						return tasks, len(tasks), nil
+					},
+				},
+				labelService: &mock.LabelService{
+					FindResourceLabelsFn: func(ctx context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+						return []*influxdb.Label{}, nil
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "self": "/api/v2/tasks?limit=100&type=downsampling"
+  },
+  "tasks": [
+    {
+      "links": {
+        "self": "/api/v2/tasks/0000000000000003",
+        "owners": "/api/v2/tasks/0000000000000003/owners",
+        "members": "/api/v2/tasks/0000000000000003/members",
+        "labels": "/api/v2/tasks/0000000000000003/labels",
+        "runs": "/api/v2/tasks/0000000000000003/runs",
+        "logs": "/api/v2/tasks/0000000000000003/logs"
+      },
+      "id": "0000000000000003",
+      "name": "task3",
+      "labels": [],
+      "orgID": "0000000000000001",
+      "ownerID": "0000000000000001",
+      "org": "test",
+      "status": "",
+      "flux": ""
+    }
+  ]
 }`,
 			},
 		},
@@ -0,0 +1,356 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+)
+
+const (
+	tasksIDVersionsPath           = "/api/v2/tasks/:id/versions"
+	tasksIDVersionsIDPath         = "/api/v2/tasks/:id/versions/:version"
+	tasksIDVersionsIDDiffPath     = "/api/v2/tasks/:id/versions/:version/diff"
+	tasksIDVersionsIDRollbackPath = "/api/v2/tasks/:id/versions/:version/rollback"
+)
+
+type taskVersionResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.TaskVersion
+}
+
+func newTaskVersionResponse(v *influxdb.TaskVersion) *taskVersionResponse {
+	return &taskVersionResponse{
+		Links: map[string]string{
+			"self":     taskVersionIDPath(v.TaskID, v.Version),
+			"task":     taskIDPath(v.TaskID),
+			"diff":     taskVersionIDPath(v.TaskID, v.Version) + "/diff",
+			"rollback": taskVersionIDPath(v.TaskID, v.Version) + "/rollback",
+		},
+		TaskVersion: v,
+	}
+}
+
+type taskVersionsResponse struct {
+	Links    map[string]string      `json:"links"`
+	Versions []*taskVersionResponse `json:"versions"`
+}
+
+func newTaskVersionsResponse(taskID influxdb.ID, versions []*influxdb.TaskVersion) *taskVersionsResponse {
+	res := &taskVersionsResponse{
+		Links: map[string]string{
+			"self": taskVersionsPath(taskID),
+			"task": taskIDPath(taskID),
+		},
+		Versions: make([]*taskVersionResponse, 0, len(versions)),
+	}
+	for _, v := range versions {
+		res.Versions = append(res.Versions, newTaskVersionResponse(v))
+	}
+	return res
+}
+
+// TaskVersionDiff is a unified diff of the scripts of two versions of a task.
+type TaskVersionDiff struct {
+	TaskID influxdb.ID `json:"taskID"`
+	From   int         `json:"from"`
+	To     int         `json:"to"`
+	Diff   string      `json:"diff"`
+}
+
+func newTaskVersionDiff(from, to *influxdb.TaskVersion) (*TaskVersionDiff, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitScriptLines(from.Flux),
+		B:        splitScriptLines(to.Flux),
+		FromFile: fmt.Sprintf("version %d", from.Version),
+		ToFile:   fmt.Sprintf("version %d", to.Version),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TaskVersionDiff{
+		TaskID: to.TaskID,
+		From:   from.Version,
+		To:     to.Version,
+		Diff:   diff,
+	}, nil
+}
+
+// splitScriptLines splits a script into its lines, each ending in a newline.
+func splitScriptLines(script string) []string {
+	if script == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(script, "\n"))
+}
+
+// handleGetTaskVersions is the HTTP handler for the GET /api/v2/tasks/:id/versions route.
+func (h *TaskHandler) handleGetTaskVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	filter := influxdb.TaskVersionFilter{TaskID: taskID}
+	qp := r.URL.Query()
+	if after := qp.Get("after"); after != "" {
+		if filter.After, err = strconv.Atoi(after); err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "after must be a version number",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+	if limit := qp.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be a positive number",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	versions, err := h.TaskVersionService.FindTaskVersions(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task versions retrieved", zap.String("taskID", taskID.String()), zap.Int("versions", len(versions)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskVersionsResponse(taskID, versions)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetTaskVersion is the HTTP handler for the GET /api/v2/tasks/:id/versions/:version route.
+func (h *TaskHandler) handleGetTaskVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v, err := h.findTaskVersion(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task version retrieved", zap.String("taskID", v.TaskID.String()), zap.Int("version", v.Version))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskVersionResponse(v)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetTaskVersionDiff is the HTTP handler for the GET /api/v2/tasks/:id/versions/:version/diff route.
+// The version is compared to the version of the from query parameter, or the
+// version before it.
+func (h *TaskHandler) handleGetTaskVersionDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	to, err := h.findTaskVersion(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	fromVersion := to.Version - 1
+	if from := r.URL.Query().Get("from"); from != "" {
+		if fromVersion, err = decodeTaskVersion(from); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	from := &influxdb.TaskVersion{TaskID: to.TaskID}
+	if fromVersion > 0 {
+		if from, err = h.TaskVersionService.FindTaskVersion(ctx, to.TaskID, fromVersion); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	diff, err := newTaskVersionDiff(from, to)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task versions compared", zap.String("taskID", to.TaskID.String()), zap.Int("from", diff.From), zap.Int("to", diff.To))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, diff); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostTaskVersionRollback is the HTTP handler for the POST /api/v2/tasks/:id/versions/:version/rollback route.
+// The task is updated with the script of the version, which records it as the
+// latest version of the task.
+func (h *TaskHandler) handlePostTaskVersionRollback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v, err := h.findTaskVersion(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.UpdateTask(ctx, v.TaskID, influxdb.TaskUpdate{Flux: &v.Flux})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: task.ID})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Task rolled back", zap.String("taskID", task.ID.String()), zap.Int("from", v.Version), zap.Int("version", task.Version))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// findTaskVersion returns the task version of the request path.
+func (h *TaskHandler) findTaskVersion(ctx context.Context) (*influxdb.TaskVersion, error) {
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := decodeTaskVersion(httprouter.ParamsFromContext(ctx).ByName("version"))
+	if err != nil {
+		return nil, err
+	}
+
+	return h.TaskVersionService.FindTaskVersion(ctx, taskID, version)
+}
+
+func decodeTaskVersion(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 1 {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid task version %q", s),
+		}
+	}
+	return v, nil
+}
+
+func taskVersionsPath(taskID influxdb.ID) string {
+	return fmt.Sprintf("/api/v2/tasks/%s/versions", taskID)
+}
+
+func taskVersionIDPath(taskID influxdb.ID, version int) string {
+	return fmt.Sprintf("/api/v2/tasks/%s/versions/%d", taskID, version)
+}
+
+// TaskVersionService connects to Influx via HTTP using tokens to manage the
+// version history of tasks.
+type TaskVersionService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.TaskVersionService = (*TaskVersionService)(nil)
+
+// FindTaskVersion returns a single version of a task.
+func (s *TaskVersionService) FindTaskVersion(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res taskVersionResponse
+	err := s.Client.
+		Get(taskVersionIDPath(taskID, version)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.TaskVersion, nil
+}
+
+// FindTaskVersions returns the versions of a task, oldest first.
+func (s *TaskVersionService) FindTaskVersions(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.After > 0 {
+		params = append(params, [2]string{"after", strconv.Itoa(filter.After)})
+	}
+	if filter.Limit > 0 {
+		params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
+	}
+
+	var res taskVersionsResponse
+	err := s.Client.
+		Get(taskVersionsPath(filter.TaskID)).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*influxdb.TaskVersion, 0, len(res.Versions))
+	for _, v := range res.Versions {
+		versions = append(versions, v.TaskVersion)
+	}
+	return versions, nil
+}
+
+// DiffTaskVersions returns the diff of the script of version to of a task
+// from the script of version from, or of the version before it if from is 0.
+func (s *TaskVersionService) DiffTaskVersions(ctx context.Context, taskID influxdb.ID, from, to int) (*TaskVersionDiff, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if from > 0 {
+		params = append(params, [2]string{"from", strconv.Itoa(from)})
+	}
+
+	var res TaskVersionDiff
+	err := s.Client.
+		Get(taskVersionIDPath(taskID, to), "diff").
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RollbackTask updates a task with the script of one of its versions.
+func (s *TaskVersionService) RollbackTask(ctx context.Context, taskID influxdb.ID, version int) (*Task, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res taskResponse
+	err := s.Client.
+		Post(nil, taskVersionIDPath(taskID, version), "rollback").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Task, nil
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestTaskVersionService() *mock.TaskVersionService {
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := map[int]*influxdb.TaskVersion{
+		1: {TaskID: 1, OrganizationID: 3, Version: 1, Name: "t", Every: "1h", CreatedAt: createdAt,
+			Flux: "option task = {name: \"t\", every: 1h}\nfrom(bucket: \"b\")\n|> range(start: -1h)\n"},
+		2: {TaskID: 1, OrganizationID: 3, Version: 2, Name: "t", Every: "2h", AuthorID: 4, CreatedAt: createdAt.Add(time.Hour),
+			Flux: "option task = {name: \"t\", every: 2h}\nfrom(bucket: \"b\")\n|> range(start: -1h)\n"},
+	}
+
+	svc := mock.NewTaskVersionService()
+	svc.FindTaskVersionF = func(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+		v, ok := versions[version]
+		if !ok || taskID != v.TaskID {
+			return nil, influxdb.ErrTaskVersionNotFound
+		}
+		return v, nil
+	}
+	return svc
+}
+
+func TestTaskHandler_handleGetTaskVersion(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		wants   wants
+	}{
+		{
+			name:    "get a version",
+			version: "2",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000001/versions/2",
+    "task": "/api/v2/tasks/0000000000000001",
+    "diff": "/api/v2/tasks/0000000000000001/versions/2/diff",
+    "rollback": "/api/v2/tasks/0000000000000001/versions/2/rollback"
+  },
+  "taskID": "0000000000000001",
+  "orgID": "0000000000000003",
+  "version": 2,
+  "flux": "option task = {name: \"t\", every: 2h}\nfrom(bucket: \"b\")\n|> range(start: -1h)\n",
+  "name": "t",
+  "every": "2h",
+  "authorID": "0000000000000004",
+  "createdAt": "2020-01-01T01:00:00Z"
+}`,
+			},
+		},
+		{
+			name:    "missing version",
+			version: "3",
+			wants: wants{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "invalid version",
+			version: "0",
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://any.url", nil)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{Key: "id", Value: influxdb.ID(1).String()},
+					{Key: "version", Value: tt.version},
+				}))
+			w := httptest.NewRecorder()
+			taskBackend := NewMockTaskBackend(t)
+			taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			taskBackend.TaskVersionService = newTestTaskVersionService()
+			h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+			h.handleGetTaskVersion(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handleGetTaskVersion() = %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%q, handleGetTaskVersion(). error unmarshaling json %v", tt.name, err)
+				} else if !eq {
+					t.Errorf("%q. handleGetTaskVersion() = ***%s***", tt.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskHandler_handleGetTaskVersionDiff(t *testing.T) {
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.TaskVersionService = newTestTaskVersionService()
+	h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url?from=1", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{Key: "id", Value: influxdb.ID(1).String()},
+			{Key: "version", Value: "2"},
+		}))
+	w := httptest.NewRecorder()
+	h.handleGetTaskVersionDiff(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetTaskVersionDiff() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+
+	var diff TaskVersionDiff
+	if err := json.Unmarshal(body, &diff); err != nil {
+		t.Fatal(err)
+	}
+	want := `--- version 1
++++ version 2
+@@ -1,3 +1,3 @@
+-option task = {name: "t", every: 1h}
++option task = {name: "t", every: 2h}
+ from(bucket: "b")
+ |> range(start: -1h)
+`
+	if diff.From != 1 || diff.To != 2 || diff.Diff != want {
+		t.Errorf("unexpected diff %d..%d:\n%s", diff.From, diff.To, diff.Diff)
+	}
+}
+
+func TestTaskHandler_handlePostTaskVersionRollback(t *testing.T) {
+	var update influxdb.TaskUpdate
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.TaskVersionService = newTestTaskVersionService()
+	taskBackend.TaskService = &mock.TaskService{
+		UpdateTaskFn: func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+			update = upd
+			return &influxdb.Task{ID: id, OrganizationID: 3, OwnerID: 2, Name: "t", Flux: *upd.Flux, Every: "1h", Version: 3}, nil
+		},
+	}
+	h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+
+	r := httptest.NewRequest("POST", "http://any.url", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{Key: "id", Value: influxdb.ID(1).String()},
+			{Key: "version", Value: "1"},
+		}))
+	w := httptest.NewRecorder()
+	h.handlePostTaskVersionRollback(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handlePostTaskVersionRollback() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if update.Flux == nil || *update.Flux != "option task = {name: \"t\", every: 1h}\nfrom(bucket: \"b\")\n|> range(start: -1h)\n" {
+		t.Errorf("expected the task to be updated with the script of version 1, got %+v", update)
+	}
+
+	var task Task
+	if err := json.Unmarshal(body, &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Version != 3 {
+		t.Errorf("expected the rolled back task at version 3, got %d", task.Version)
+	}
+}
@@ -284,6 +284,11 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		options = append(options, req.Precision)
 	}
 
+	var lines []int
+	if bucket.SchemaType == influxdb.SchemaTypeExplicit {
+		options = append(options, models.WithParserLineNumbers(&lines))
+	}
+
 	points, err := models.ParsePointsWithOptions(data, mm, options...)
 	span.LogKV("values_total", len(points))
 	span.Finish()
@@ -301,7 +306,17 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if lines != nil {
+		// Schema errors refer to the lines of the request.
+		ctx = storage.WithPointLines(ctx, lines)
+	}
+
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EInvalid {
+			log.Debug("Points rejected by bucket schema", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
 		log.Error("Error writing points", zap.Error(err))
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
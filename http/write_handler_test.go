@@ -16,6 +16,7 @@ import (
 	httpmock "github.com/influxdata/influxdb/http/mock"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/storage"
 	influxtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -80,12 +81,13 @@ func TestWriteService_Write(t *testing.T) {
 func TestWriteHandler_handleWrite(t *testing.T) {
 	// state is the internal state of org and bucket services
 	type state struct {
-		org       *influxdb.Organization // org to return in org service
-		orgErr    error                  // err to return in org service
-		bucket    *influxdb.Bucket       // bucket to return in bucket service
-		bucketErr error                  // err to return in bucket service
-		writeErr  error                  // err to return from the points writer
-		opts      []WriteHandlerOption   // write handle configured options
+		org       *influxdb.Organization        // org to return in org service
+		orgErr    error                         // err to return in org service
+		bucket    *influxdb.Bucket              // bucket to return in bucket service
+		bucketErr error                         // err to return in bucket service
+		writeErr  error                         // err to return from the points writer
+		opts      []WriteHandlerOption          // write handle configured options
+		schemas   []*influxdb.MeasurementSchema // measurement schemas of the bucket
 	}
 
 	// want is the expected output of the HTTP endpoint
@@ -329,6 +331,41 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"request too large","message":"points: number of values exceeded"}`,
 			},
 		},
+		{
+			name: "points matching an explicit schema are accepted",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "cpu,host=a usage=1i,idle=0.5",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:     testOrg("043e0780ee2b1000"),
+				bucket:  testExplicitBucket("043e0780ee2b1000", "04504b356e23b000"),
+				schemas: []*influxdb.MeasurementSchema{testCPUSchema()},
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "points not matching an explicit schema are rejected by line",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "cpu,host=a usage=1i\ncpu,host=a usage=1.5,idle=0.5\ncpu,region=west usage=1i,idle=1\nmem free=1i",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:     testOrg("043e0780ee2b1000"),
+				bucket:  testExplicitBucket("043e0780ee2b1000", "04504b356e23b000"),
+				schemas: []*influxdb.MeasurementSchema{testCPUSchema()},
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"write does not match the schemas of its buckets:\nline 2: bucket \"b\": field \"usage\" is float, schema of measurement \"cpu\" requires integer\nline 3: bucket \"b\": tag \"region\" is not a tag of measurement \"cpu\"\nline 4: bucket \"b\": measurement \"mem\" has no schema"}`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -340,13 +377,20 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
 				return tt.state.bucket, tt.state.bucketErr
 			}
+			buckets.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				return []*influxdb.Bucket{tt.state.bucket}, 1, nil
+			}
+			schemas := mock.NewMeasurementSchemaService()
+			schemas.FindMeasurementSchemasF = func(context.Context, influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+				return tt.state.schemas, nil
+			}
 
 			b := &APIBackend{
 				HTTPErrorHandler:    DefaultErrorHandler,
 				Logger:              zaptest.NewLogger(t),
 				OrganizationService: orgs,
 				BucketService:       buckets,
-				PointsWriter:        &mock.PointsWriter{Err: tt.state.writeErr},
+				PointsWriter:        storage.NewSchemaPointsWriter(&mock.PointsWriter{Err: tt.state.writeErr}, buckets, schemas),
 				WriteEventRecorder:  &metric.NopEventRecorder{},
 			}
 			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b), tt.state.opts...)
@@ -413,3 +457,22 @@ func testBucket(org, bucket string) *influxdb.Bucket {
 		OrgID: oid,
 	}
 }
+
+func testExplicitBucket(org, bucket string) *influxdb.Bucket {
+	b := testBucket(org, bucket)
+	b.Name = "b"
+	b.SchemaType = influxdb.SchemaTypeExplicit
+	return b
+}
+
+func testCPUSchema() *influxdb.MeasurementSchema {
+	return &influxdb.MeasurementSchema{
+		Name: "cpu",
+		Columns: []influxdb.MeasurementSchemaColumn{
+			{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+			{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+			{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeInteger},
+			{Name: "idle", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+		},
+	}
+}
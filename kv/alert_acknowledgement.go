@@ -0,0 +1,142 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.AlertAcknowledgementService = (*Service)(nil)
+
+func newAlertAcknowledgementStore() *StoreBase {
+	const resource = "alert acknowledgement"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var a influxdb.AlertAcknowledgement
+		return key, &a, json.Unmarshal(val, &a)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		a, ok := v.(*influxdb.AlertAcknowledgement)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(a.ID), Body: a}, nil
+	}
+
+	return NewStoreBase(resource, []byte("alertacknowledgementsv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// FindAlertAcknowledgementByID retrieves an alert acknowledgement by id.
+func (s *Service) FindAlertAcknowledgementByID(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var a *influxdb.AlertAcknowledgement
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		a, err = s.findAlertAcknowledgementByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *Service) findAlertAcknowledgementByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+	v, err := s.alertAcknowledgementStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpFindAlertAcknowledgementByID,
+				Msg:  influxdb.ErrAlertAcknowledgementNotFound.Msg,
+			}
+		}
+		return nil, err
+	}
+	return v.(*influxdb.AlertAcknowledgement), nil
+}
+
+// FindAlertAcknowledgements returns the alert acknowledgements that match filter, in the order they were created.
+func (s *Service) FindAlertAcknowledgements(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+
+	acks := []*influxdb.AlertAcknowledgement{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.alertAcknowledgementStore.Find(ctx, tx, FindOpts{
+			Descending: o.Descending,
+			Offset:     o.Offset,
+			Limit:      o.Limit,
+			FilterEntFn: func(k []byte, v interface{}) bool {
+				a, ok := v.(*influxdb.AlertAcknowledgement)
+				return ok &&
+					(filter.OrgID == nil || a.OrgID == *filter.OrgID) &&
+					(filter.CheckID == nil || a.CheckID == *filter.CheckID)
+			},
+			CaptureFn: func(k []byte, v interface{}) error {
+				acks = append(acks, v.(*influxdb.AlertAcknowledgement))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return acks, len(acks), nil
+}
+
+// CreateAlertAcknowledgement creates an alert acknowledgement and sets a.ID with the new identifier.
+func (s *Service) CreateAlertAcknowledgement(ctx context.Context, a *influxdb.AlertAcknowledgement) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := a.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, a.OrgID); err != nil {
+			return err
+		}
+		chk, err := s.findCheckByID(ctx, tx, a.CheckID)
+		if err != nil {
+			return err
+		}
+		if chk.GetOrgID() != a.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   influxdb.OpCreateAlertAcknowledgement,
+				Msg:  "alert acknowledgement check must belong to its organization",
+			}
+		}
+
+		a.ID = s.IDGenerator.ID()
+		a.Level = strings.ToUpper(a.Level)
+		a.CreatedAt = s.TimeGenerator.Now()
+
+		return s.alertAcknowledgementStore.Put(ctx, tx, Entity{PK: EncID(a.ID), Body: a}, PutNew())
+	})
+}
+
+// DeleteAlertAcknowledgement removes an alert acknowledgement by id.
+func (s *Service) DeleteAlertAcknowledgement(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findAlertAcknowledgementByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.alertAcknowledgementStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)})
+	})
+}
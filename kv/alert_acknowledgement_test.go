@@ -0,0 +1,90 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+)
+
+func TestService_AlertAcknowledgements(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	every, _ := notification.FromTimeDuration(time.Minute)
+	chk := &check.Threshold{
+		Base: check.Base{
+			Name:  "cpu",
+			OrgID: ts.Org.ID,
+			Every: &every,
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "telegraf") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user")`,
+			},
+			StatusMessageTemplate: "cpu is high",
+		},
+		Thresholds: []check.ThresholdConfig{
+			&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 90},
+		},
+	}
+	if err := ts.Service.CreateCheck(ctx, influxdb.CheckCreate{Check: chk, Status: influxdb.Active}, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	ack := &influxdb.AlertAcknowledgement{
+		OrgID:   ts.Org.ID,
+		CheckID: chk.ID,
+		Tags:    []influxdb.Tag{{Key: "host", Value: "a"}},
+		Level:   "crit",
+		UserID:  ts.User.ID,
+	}
+	if err := ts.Service.CreateAlertAcknowledgement(ctx, ack); err != nil {
+		t.Fatal(err)
+	}
+	if !ack.ID.Valid() || ack.Level != "CRIT" || ack.CreatedAt.IsZero() {
+		t.Fatalf("expected the acknowledgement to be created, got %+v", ack)
+	}
+
+	t.Run("unknown check", func(t *testing.T) {
+		unknown := &influxdb.AlertAcknowledgement{
+			OrgID:   ts.Org.ID,
+			CheckID: influxdb.ID(1234),
+			Level:   "crit",
+		}
+		if err := ts.Service.CreateAlertAcknowledgement(ctx, unknown); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the check of the acknowledgement to be required, got %v", err)
+		}
+	})
+
+	t.Run("find", func(t *testing.T) {
+		acks, n, err := ts.Service.FindAlertAcknowledgements(ctx, influxdb.AlertAcknowledgementFilter{CheckID: &chk.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || acks[0].ID != ack.ID {
+			t.Fatalf("expected the acknowledgement of the check, got %+v", acks)
+		}
+
+		other := influxdb.ID(1234)
+		if acks, _, err = ts.Service.FindAlertAcknowledgements(ctx, influxdb.AlertAcknowledgementFilter{OrgID: &other}); err != nil || len(acks) != 0 {
+			t.Fatalf("expected no acknowledgements in another org, got %+v, %v", acks, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := ts.Service.DeleteAlertAcknowledgement(ctx, ack.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts.Service.FindAlertAcknowledgementByID(ctx, ack.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the acknowledgement to be deleted, got %v", err)
+		}
+	})
+}
@@ -465,10 +465,18 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) (
 		return err
 	}
 
+	if err := b.SchemaType.Valid(); err != nil {
+		return err
+	}
+
 	if b.ID, err = s.generateBucketID(ctx, tx); err != nil {
 		return err
 	}
 
+	if err := s.syncDownsamplingTasks(ctx, tx, b, nil); err != nil {
+		return err
+	}
+
 	b.CreatedAt = s.Now()
 	b.UpdatedAt = s.Now()
 
@@ -679,6 +687,10 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.Description = *upd.Description
 	}
 
+	if upd.MaxSeries != nil {
+		b.MaxSeries = *upd.MaxSeries
+	}
+
 	if upd.Name != nil {
 		b0, err := s.findBucketByName(ctx, tx, b.OrgID, *upd.Name)
 		if err == nil && b0.ID != id {
@@ -702,6 +714,18 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.Name = *upd.Name
 	}
 
+	// Task scripts include the bucket name, so they are compiled again on
+	// every update.
+	prev := b.DownsamplingPolicies
+	if upd.DownsamplingPolicies != nil {
+		b.DownsamplingPolicies = *upd.DownsamplingPolicies
+	} else {
+		b.DownsamplingPolicies = append([]influxdb.DownsamplingPolicy(nil), prev...)
+	}
+	if err := s.syncDownsamplingTasks(ctx, tx, b, prev); err != nil {
+		return nil, err
+	}
+
 	b.UpdatedAt = s.Now()
 
 	if err := s.appendBucketEventToLog(ctx, tx, b.ID, bucketUpdatedEvent); err != nil {
@@ -814,7 +838,16 @@ func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error
 		return err
 	}
 
-	return nil
+	for _, p := range b.DownsamplingPolicies {
+		if err := s.deleteDownsamplingTask(ctx, tx, p); err != nil {
+			return err
+		}
+	}
+	if err := s.deleteDownsamplingPoliciesTo(ctx, tx, b.OrgID, id); err != nil {
+		return err
+	}
+
+	return s.deleteBucketMeasurementSchemas(ctx, tx, id)
 }
 
 const bucketOperationLogKeyPrefix = "bucket"
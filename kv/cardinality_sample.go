@@ -0,0 +1,115 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.CardinalitySampleService = (*Service)(nil)
+
+func newCardinalitySampleStore() *StoreBase {
+	const resource = "cardinality sample"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var s influxdb.CardinalitySample
+		return key, &s, json.Unmarshal(val, &s)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		s, ok := v.(*influxdb.CardinalitySample)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: cardinalitySampleKey(s.BucketID, s.Time), Body: s}, nil
+	}
+
+	return NewStoreBase(resource, []byte("cardinalitysamplesv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// cardinalitySampleKey encodes the key of a sample. The samples of a bucket
+// share the bucket ID as a prefix and are ordered by time.
+func cardinalitySampleKey(bucketID influxdb.ID, t time.Time) EncodeFn {
+	return Encode(EncID(bucketID), func() ([]byte, error) {
+		return cardinalitySampleTime(t), nil
+	})
+}
+
+func cardinalitySampleTime(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano()))
+	return b
+}
+
+// AddCardinalitySample records a sample, and discards the samples of its
+// bucket older than the growth window.
+func (s *Service) AddCardinalitySample(ctx context.Context, sample *influxdb.CardinalitySample) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		expired, err := s.findCardinalitySamples(ctx, tx, sample.BucketID, func(t time.Time) bool {
+			return t.Before(sample.Time.Add(-influxdb.CardinalityGrowthWindow))
+		})
+		if err != nil {
+			return err
+		}
+		for _, e := range expired {
+			if err := s.cardinalitySampleStore.DeleteEnt(ctx, tx, Entity{PK: cardinalitySampleKey(e.BucketID, e.Time)}); err != nil {
+				return err
+			}
+		}
+
+		return s.cardinalitySampleStore.Put(ctx, tx, Entity{
+			PK:   cardinalitySampleKey(sample.BucketID, sample.Time),
+			Body: sample,
+		})
+	})
+}
+
+// FindCardinalitySamples returns the samples of a bucket taken at or after
+// since, oldest first.
+func (s *Service) FindCardinalitySamples(ctx context.Context, bucketID influxdb.ID, since time.Time) ([]*influxdb.CardinalitySample, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var samples []*influxdb.CardinalitySample
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		samples, err = s.findCardinalitySamples(ctx, tx, bucketID, func(t time.Time) bool {
+			return !t.Before(since)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func (s *Service) findCardinalitySamples(ctx context.Context, tx Tx, bucketID influxdb.ID, filterFn func(time.Time) bool) ([]*influxdb.CardinalitySample, error) {
+	prefix, err := EncID(bucketID)()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []*influxdb.CardinalitySample{}
+	err = s.cardinalitySampleStore.Find(ctx, tx, FindOpts{
+		Prefix: prefix,
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			sample, ok := v.(*influxdb.CardinalitySample)
+			return ok && sample.BucketID == bucketID && filterFn(sample.Time)
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			samples = append(samples, v.(*influxdb.CardinalitySample))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
@@ -0,0 +1,77 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltCardinalitySampleService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	testCardinalitySampleService(t, s)
+}
+
+func TestInmemCardinalitySampleService(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	testCardinalitySampleService(t, s)
+}
+
+func testCardinalitySampleService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing cardinality sample service: %v", err)
+	}
+
+	const bucket, other = influxdb.ID(1), influxdb.ID(2)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sample := func(id influxdb.ID, d time.Duration, n int64) *influxdb.CardinalitySample {
+		return &influxdb.CardinalitySample{BucketID: id, SeriesN: n, Time: start.Add(d)}
+	}
+
+	for _, s := range []*influxdb.CardinalitySample{
+		sample(bucket, 0, 10),
+		sample(other, time.Hour, 1),
+		sample(bucket, time.Hour, 20),
+		sample(bucket, 2*time.Hour, 30),
+	} {
+		if err := svc.AddCardinalitySample(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	samples, err := svc.FindCardinalitySamples(ctx, bucket, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []*influxdb.CardinalitySample{sample(bucket, time.Hour, 20), sample(bucket, 2*time.Hour, 30)}; !reflect.DeepEqual(samples, exp) {
+		t.Fatalf("unexpected samples: got %v, exp %v", samples, exp)
+	}
+
+	// Samples older than the growth window are discarded when one is added.
+	if err := svc.AddCardinalitySample(ctx, sample(bucket, influxdb.CardinalityGrowthWindow+90*time.Minute, 40)); err != nil {
+		t.Fatal(err)
+	}
+	samples, err = svc.FindCardinalitySamples(ctx, bucket, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []*influxdb.CardinalitySample{sample(bucket, 2*time.Hour, 30), sample(bucket, influxdb.CardinalityGrowthWindow+90*time.Minute, 40)}; !reflect.DeepEqual(samples, exp) {
+		t.Fatalf("unexpected samples: got %v, exp %v", samples, exp)
+	}
+}
@@ -0,0 +1,195 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// syncDownsamplingTasks compiles the downsampling policies of b into their
+// managed tasks and sets the task ID of each policy. prev are the policies b
+// had before, whose tasks are reused by the policy at the same position and
+// deleted when there is none.
+func (s *Service) syncDownsamplingTasks(ctx context.Context, tx Tx, b *influxdb.Bucket, prev []influxdb.DownsamplingPolicy) error {
+	var schemas []*influxdb.MeasurementSchema
+	if len(b.DownsamplingPolicies) > 0 && b.SchemaType == influxdb.SchemaTypeExplicit {
+		var err error
+		schemas, err = s.findMeasurementSchemas(ctx, tx, influxdb.MeasurementSchemaFilter{BucketID: b.ID})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range b.DownsamplingPolicies {
+		p := &b.DownsamplingPolicies[i]
+		if err := p.Valid(b.SchemaType); err != nil {
+			return err
+		}
+
+		dest, err := s.downsamplingDestination(ctx, tx, b, p.DestinationBucketID)
+		if err != nil {
+			return err
+		}
+
+		script, active, err := backend.DownsamplingFlux(b, dest, *p, schemas)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "could not compile downsampling policy",
+				Err:  err,
+			}
+		}
+		status := influxdb.TaskStatusInactive
+		if active {
+			status = influxdb.TaskStatusActive
+		}
+
+		p.TaskID = 0
+		if i < len(prev) && prev[i].TaskID.Valid() {
+			_, err := s.updateTask(ctx, tx, prev[i].TaskID, influxdb.TaskUpdate{
+				Flux:   &script,
+				Status: &status,
+			})
+			if err == nil {
+				p.TaskID = prev[i].TaskID
+			} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+				return err
+			}
+		}
+		if p.TaskID.Valid() {
+			continue
+		}
+
+		// The task runs with the permissions of the user that set the policy.
+		uid, err := icontext.GetUserID(ctx)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "downsampling policies can only be set by a user",
+				Err:  err,
+			}
+		}
+		t, err := s.createTask(ctx, tx, influxdb.TaskCreate{
+			Type:           influxdb.TaskDownsamplingType,
+			Flux:           script,
+			Status:         status,
+			OrganizationID: b.OrgID,
+			OwnerID:        uid,
+		})
+		if err != nil {
+			return err
+		}
+		p.TaskID = t.ID
+	}
+
+	for i := len(b.DownsamplingPolicies); i < len(prev); i++ {
+		if err := s.deleteDownsamplingTask(ctx, tx, prev[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recompileDownsamplingTasks compiles the downsampling policies of a bucket
+// into their tasks again, after the fields of its schema have changed.
+func (s *Service) recompileDownsamplingTasks(ctx context.Context, tx Tx, bucketID influxdb.ID) error {
+	b, err := s.findBucketByID(ctx, tx, bucketID)
+	if err != nil {
+		return err
+	}
+	if len(b.DownsamplingPolicies) == 0 {
+		return nil
+	}
+
+	prev := append([]influxdb.DownsamplingPolicy(nil), b.DownsamplingPolicies...)
+	return s.syncDownsamplingTasks(ctx, tx, b, prev)
+}
+
+// downsamplingDestination returns the destination bucket of a downsampling
+// policy of src.
+func (s *Service) downsamplingDestination(ctx context.Context, tx Tx, src *influxdb.Bucket, id influxdb.ID) (*influxdb.Bucket, error) {
+	if id == src.ID {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "downsampling policy cannot write to its own bucket",
+		}
+	}
+
+	dest, err := s.findBucketByID(ctx, tx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "downsampling policy destination bucket not found",
+			}
+		}
+		return nil, err
+	}
+	if dest.OrgID != src.OrgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "downsampling policy destination bucket must be in the same organization",
+		}
+	}
+	return dest, nil
+}
+
+// deleteDownsamplingTask deletes the task of a downsampling policy, if it
+// still exists.
+func (s *Service) deleteDownsamplingTask(ctx context.Context, tx Tx, p influxdb.DownsamplingPolicy) error {
+	if !p.TaskID.Valid() {
+		return nil
+	}
+	if err := s.deleteTask(ctx, tx, p.TaskID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+// deleteDownsamplingPoliciesTo removes the downsampling policies that write to
+// the bucket id, and their tasks, from the other buckets of its organization.
+func (s *Service) deleteDownsamplingPoliciesTo(ctx context.Context, tx Tx, orgID, id influxdb.ID) error {
+	var sources []*influxdb.Bucket
+	err := s.forEachBucket(ctx, tx, false, func(b *influxdb.Bucket) bool {
+		if b.OrgID != orgID {
+			return true
+		}
+		for _, p := range b.DownsamplingPolicies {
+			if p.DestinationBucketID == id {
+				sources = append(sources, b)
+				break
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, b := range sources {
+		policies := b.DownsamplingPolicies[:0]
+		for _, p := range b.DownsamplingPolicies {
+			if p.DestinationBucketID != id {
+				policies = append(policies, p)
+				continue
+			}
+			if err := s.deleteDownsamplingTask(ctx, tx, p); err != nil {
+				return err
+			}
+		}
+		b.DownsamplingPolicies = policies
+		b.UpdatedAt = s.Now()
+
+		v, err := json.Marshal(b)
+		if err != nil {
+			return influxdb.ErrInternalBucketServiceError(influxdb.OpDeleteBucket, err)
+		}
+		if err := s.putBucket(ctx, tx, b, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestInmemDownsamplingPolicies(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	rollup := &influxdb.Bucket{OrgID: org.ID, Name: "rollup"}
+	if err := svc.CreateBucket(ctx, rollup); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := influxdb.DownsamplingPolicy{
+		DestinationBucketID: rollup.ID,
+		Every:               influxdb.Duration{Duration: time.Hour},
+		Offset:              influxdb.Duration{Duration: 5 * time.Minute},
+		Aggregates: map[influxdb.SchemaColumnDataType]string{
+			influxdb.SchemaColumnDataTypeFloat:  "mean",
+			influxdb.SchemaColumnDataTypeString: "last",
+		},
+		TagFilter: map[string]string{"host": "a"},
+	}
+
+	// Per field type aggregates need the field types of an explicit schema.
+	implicit := &influxdb.Bucket{OrgID: org.ID, Name: "implicit", DownsamplingPolicies: []influxdb.DownsamplingPolicy{policy}}
+	if err := svc.CreateBucket(ctx, implicit); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error creating implicit bucket, got %v", err)
+	}
+
+	// A numeric aggregate of an implicit schema keeps the last value of
+	// string and boolean fields.
+	meanPolicy := policy
+	meanPolicy.Aggregates = map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "mean"}
+	implicit.DownsamplingPolicies = []influxdb.DownsamplingPolicy{meanPolicy}
+	if err := svc.CreateBucket(ctx, implicit); err != nil {
+		t.Fatal(err)
+	}
+	implicitTask, err := svc.FindTaskByID(ctx, implicit.DownsamplingPolicies[0].TaskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"downsample.numeric()", "fn: mean", "downsample.nonNumeric()", "fn: last"} {
+		if !strings.Contains(implicitTask.Flux, s) {
+			t.Errorf("expected implicit task script to contain %s:\n%s", s, implicitTask.Flux)
+		}
+	}
+
+	raw := &influxdb.Bucket{
+		OrgID:                org.ID,
+		Name:                 "raw",
+		SchemaType:           influxdb.SchemaTypeExplicit,
+		DownsamplingPolicies: []influxdb.DownsamplingPolicy{policy},
+	}
+	if err := svc.CreateBucket(ctx, raw); err != nil {
+		t.Fatal(err)
+	}
+
+	taskID := raw.DownsamplingPolicies[0].TaskID
+	task, err := svc.FindTaskByID(ctx, taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Type != influxdb.TaskDownsamplingType {
+		t.Errorf("unexpected task type: got %q", task.Type)
+	}
+	if task.Offset != 5*time.Minute {
+		t.Errorf("unexpected task offset: got %s", task.Offset)
+	}
+	// There are no fields to aggregate until the bucket has a schema.
+	if task.Status != influxdb.TaskStatusInactive {
+		t.Errorf("expected task to be inactive, got %q", task.Status)
+	}
+
+	tasks, _, err := svc.FindTasks(ctx, influxdb.TaskFilter{Type: &influxdb.TaskSystemType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected downsampling task to be hidden from system tasks, got %d tasks", len(tasks))
+	}
+
+	ms := &influxdb.MeasurementSchema{BucketID: raw.ID, Name: "cpu", Columns: []influxdb.MeasurementSchemaColumn{
+		{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+		{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+	}}
+	if err := svc.CreateMeasurementSchema(ctx, ms); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err = svc.FindTaskByID(ctx, taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != influxdb.TaskStatusActive {
+		t.Errorf("expected task to be active, got %q", task.Status)
+	}
+	for _, s := range []string{`r["host"] == "a"`, `r._field == "usage"`, "fn: mean", rollup.ID.String()} {
+		if !strings.Contains(task.Flux, s) {
+			t.Errorf("expected task script to contain %s:\n%s", s, task.Flux)
+		}
+	}
+	if strings.Contains(task.Flux, "fn: last") {
+		t.Errorf("unexpected aggregate of string fields without string fields:\n%s", task.Flux)
+	}
+
+	// Deleting the destination removes the policy and its task.
+	if err := svc.DeleteBucket(ctx, rollup.ID); err != nil {
+		t.Fatal(err)
+	}
+	raw, err = svc.FindBucketByID(ctx, raw.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.DownsamplingPolicies) != 0 {
+		t.Errorf("expected policies to be removed, got %+v", raw.DownsamplingPolicies)
+	}
+	if _, err := svc.FindTaskByID(ctx, taskID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected task to be deleted, got %v", err)
+	}
+}
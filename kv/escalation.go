@@ -0,0 +1,352 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var (
+	_ influxdb.EscalationPolicyService = (*Service)(nil)
+	_ influxdb.EscalationService       = (*Service)(nil)
+)
+
+func newEscalationPolicyStore() *StoreBase {
+	const resource = "escalation policy"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var p influxdb.EscalationPolicy
+		return key, &p, json.Unmarshal(val, &p)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		p, ok := v.(*influxdb.EscalationPolicy)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(p.ID), Body: p}, nil
+	}
+
+	return NewStoreBase(resource, []byte("escalationpoliciesv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+func newEscalationStore() *StoreBase {
+	const resource = "escalation"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var e influxdb.Escalation
+		return key, &e, json.Unmarshal(val, &e)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		e, ok := v.(*influxdb.Escalation)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(e.ID), Body: e}, nil
+	}
+
+	return NewStoreBase(resource, []byte("escalationsv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// FindEscalationPolicyByID retrieves an escalation policy by id.
+func (s *Service) FindEscalationPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var p *influxdb.EscalationPolicy
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		p, err = s.findEscalationPolicyByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Service) findEscalationPolicyByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+	v, err := s.escalationPolicyStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpFindEscalationPolicyByID,
+				Msg:  influxdb.ErrEscalationPolicyNotFound.Msg,
+			}
+		}
+		return nil, err
+	}
+	return v.(*influxdb.EscalationPolicy), nil
+}
+
+// FindEscalationPolicies returns the escalation policies that match filter, in the order they were created.
+func (s *Service) FindEscalationPolicies(ctx context.Context, filter influxdb.EscalationPolicyFilter, opt ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+
+	policies := []*influxdb.EscalationPolicy{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if filter.OrgID == nil && filter.Organization != nil {
+			o, err := s.findOrganizationByName(ctx, tx, *filter.Organization)
+			if err != nil {
+				return err
+			}
+			filter.OrgID = &o.ID
+		}
+
+		return s.escalationPolicyStore.Find(ctx, tx, FindOpts{
+			Descending: o.Descending,
+			Offset:     o.Offset,
+			Limit:      o.Limit,
+			FilterEntFn: func(k []byte, v interface{}) bool {
+				p, ok := v.(*influxdb.EscalationPolicy)
+				return ok && (filter.OrgID == nil || p.OrgID == *filter.OrgID)
+			},
+			CaptureFn: func(k []byte, v interface{}) error {
+				policies = append(policies, v.(*influxdb.EscalationPolicy))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return policies, len(policies), nil
+}
+
+// CreateEscalationPolicy creates an escalation policy and sets p.ID with the
+// new identifier. The endpoints of its steps must belong to its organization.
+func (s *Service) CreateEscalationPolicy(ctx context.Context, p *influxdb.EscalationPolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := p.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, p.OrgID); err != nil {
+			return err
+		}
+		if err := s.validEscalationSteps(ctx, tx, p); err != nil {
+			return err
+		}
+
+		p.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		p.CreatedAt = now
+		p.UpdatedAt = now
+
+		return s.escalationPolicyStore.Put(ctx, tx, Entity{PK: EncID(p.ID), Body: p}, PutNew())
+	})
+}
+
+// UpdateEscalationPolicy updates an escalation policy.
+func (s *Service) UpdateEscalationPolicy(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var p *influxdb.EscalationPolicy
+	err := s.kv.Update(ctx, func(tx Tx) (err error) {
+		p, err = s.findEscalationPolicyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := p.Apply(upd); err != nil {
+			return err
+		}
+		if err := s.validEscalationSteps(ctx, tx, p); err != nil {
+			return err
+		}
+		p.UpdatedAt = s.TimeGenerator.Now()
+
+		return s.escalationPolicyStore.Put(ctx, tx, Entity{PK: EncID(p.ID), Body: p}, PutUpdate())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteEscalationPolicy removes an escalation policy by id, unless a
+// notification rule escalates with it.
+func (s *Service) DeleteEscalationPolicy(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findEscalationPolicyByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		var used influxdb.NotificationRule
+		err := s.forEachNotificationRule(ctx, tx, false, func(nr influxdb.NotificationRule) bool {
+			if pid := nr.GetEscalationPolicyID(); pid != nil && *pid == id {
+				used = nr
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if used != nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Op:   influxdb.OpDeleteEscalationPolicy,
+				Msg:  fmt.Sprintf("escalation policy is used by notification rule %q", used.GetName()),
+			}
+		}
+
+		return s.escalationPolicyStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)})
+	})
+}
+
+// validEscalationSteps returns an error unless the endpoints of the steps of
+// a policy exist in its organization.
+func (s *Service) validEscalationSteps(ctx context.Context, tx Tx, p *influxdb.EscalationPolicy) error {
+	for i, step := range p.Steps {
+		for _, id := range step.EndpointIDs {
+			e, err := s.findNotificationEndpointByID(ctx, tx, id)
+			if err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("escalation policy step %d notifies an unknown endpoint %s", i+1, id),
+					Err:  err,
+				}
+			}
+			if e.GetOrgID() != p.OrgID {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("escalation policy step %d notifies endpoint %s of another organization", i+1, id),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validNotificationRuleEscalationPolicy returns an error unless the
+// escalation policy of a rule, if any, exists in the organization of the rule.
+func (s *Service) validNotificationRuleEscalationPolicy(ctx context.Context, tx Tx, nr influxdb.NotificationRule) error {
+	id := nr.GetEscalationPolicyID()
+	if id == nil {
+		return nil
+	}
+
+	p, err := s.findEscalationPolicyByID(ctx, tx, *id)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification rule escalation policy does not exist",
+			Err:  err,
+		}
+	}
+	if p.OrgID != nr.GetOrgID() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification rule escalation policy belongs to another organization",
+		}
+	}
+	return nil
+}
+
+// FindEscalations returns the escalations of the alerts of a notification rule.
+func (s *Service) FindEscalations(ctx context.Context, ruleID influxdb.ID) ([]*influxdb.Escalation, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var escalations []*influxdb.Escalation
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		escalations, err = s.findEscalations(ctx, tx, ruleID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return escalations, nil
+}
+
+func (s *Service) findEscalations(ctx context.Context, tx Tx, ruleID influxdb.ID) ([]*influxdb.Escalation, error) {
+	escalations := []*influxdb.Escalation{}
+	err := s.escalationStore.Find(ctx, tx, FindOpts{
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			e, ok := v.(*influxdb.Escalation)
+			return ok && e.RuleID == ruleID
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			escalations = append(escalations, v.(*influxdb.Escalation))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return escalations, nil
+}
+
+// PutEscalation creates or replaces the escalation of the alert of e, and
+// sets e.ID with the identifier of the escalation. It fails with
+// influxdb.ErrEscalationStepSent unless e escalates the alert with another
+// policy, or to more steps than the stored escalation.
+func (s *Service) PutEscalation(ctx context.Context, e *influxdb.Escalation) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		escalations, err := s.findEscalations(ctx, tx, e.RuleID)
+		if err != nil {
+			return err
+		}
+
+		e.ID = 0
+		for _, cur := range escalations {
+			if !cur.SameAlert(e) {
+				continue
+			}
+			if cur.PolicyID == e.PolicyID && cur.Steps >= e.Steps {
+				return influxdb.ErrEscalationStepSent
+			}
+			e.ID = cur.ID
+		}
+		if !e.ID.Valid() {
+			e.ID = s.IDGenerator.ID()
+		}
+		return s.escalationStore.Put(ctx, tx, Entity{PK: EncID(e.ID), Body: e})
+	})
+}
+
+// DeleteEscalation removes an escalation by id.
+func (s *Service) DeleteEscalation(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.escalationStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)})
+	})
+}
+
+// deleteNotificationRuleEscalations removes the escalations of the alerts of
+// a notification rule.
+func (s *Service) deleteNotificationRuleEscalations(ctx context.Context, tx Tx, ruleID influxdb.ID) error {
+	escalations, err := s.findEscalations(ctx, tx, ruleID)
+	if err != nil {
+		return err
+	}
+	for _, e := range escalations {
+		if err := s.escalationStore.DeleteEnt(ctx, tx, Entity{PK: EncID(e.ID)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
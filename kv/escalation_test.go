@@ -0,0 +1,146 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestService_EscalationPolicies(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	e := &endpoint.Slack{
+		Base: endpoint.Base{Name: "manager", OrgID: &ts.Org.ID, Status: influxdb.Active},
+		URL:  "http://localhost:7777",
+	}
+	if err := ts.Service.CreateNotificationEndpoint(ctx, e, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unknown endpoint", func(t *testing.T) {
+		p := &influxdb.EscalationPolicy{
+			OrgID: ts.Org.ID,
+			Name:  "on-call",
+			Steps: []influxdb.EscalationStep{{EndpointIDs: []influxdb.ID{1234}}},
+		}
+		if err := ts.Service.CreateEscalationPolicy(ctx, p); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected a policy notifying an unknown endpoint to be rejected, got %v", err)
+		}
+	})
+
+	p := &influxdb.EscalationPolicy{
+		OrgID: ts.Org.ID,
+		Name:  "on-call",
+		Steps: []influxdb.EscalationStep{{Delay: influxdb.Duration{Duration: 15 * time.Minute}, EndpointIDs: []influxdb.ID{*e.ID}}},
+	}
+	if err := ts.Service.CreateEscalationPolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	every, _ := notification.FromTimeDuration(time.Hour)
+	nr := &rule.Slack{
+		Base: rule.Base{
+			Name:               "crit",
+			OrgID:              ts.Org.ID,
+			EndpointID:         *e.ID,
+			Every:              &every,
+			StatusRules:        []notification.StatusRule{{CurrentLevel: notification.Critical}},
+			EscalationPolicyID: &p.ID,
+		},
+		MessageTemplate: "crit",
+	}
+	if err := ts.Service.CreateNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("find", func(t *testing.T) {
+		policies, n, err := ts.Service.FindEscalationPolicies(ctx, influxdb.EscalationPolicyFilter{OrgID: &ts.Org.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || policies[0].ID != p.ID {
+			t.Fatalf("expected the policy of the org, got %+v", policies)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		steps := []influxdb.EscalationStep{}
+		if _, err := ts.Service.UpdateEscalationPolicy(ctx, p.ID, influxdb.EscalationPolicyUpdate{Steps: &steps}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected a policy without steps to be rejected, got %v", err)
+		}
+
+		name := "primary"
+		updated, err := ts.Service.UpdateEscalationPolicy(ctx, p.ID, influxdb.EscalationPolicyUpdate{Name: &name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Name != name || len(updated.Steps) != 1 {
+			t.Fatalf("expected the name to be updated only, got %+v", updated)
+		}
+	})
+
+	t.Run("rules escalate with policies of their org", func(t *testing.T) {
+		other := influxdb.ID(1234)
+		nr.EscalationPolicyID = &other
+		if _, err := ts.Service.UpdateNotificationRule(ctx, nr.ID, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}, ts.User.ID); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected a rule with an unknown policy to be rejected, got %v", err)
+		}
+		nr.EscalationPolicyID = &p.ID
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := ts.Service.DeleteEscalationPolicy(ctx, p.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+			t.Fatalf("expected a policy used by a rule not to be deleted, got %v", err)
+		}
+
+		esc := &influxdb.Escalation{RuleID: nr.ID, PolicyID: p.ID, CheckID: 1, Level: "CRIT"}
+		if err := ts.Service.PutEscalation(ctx, esc); err != nil {
+			t.Fatal(err)
+		}
+		if escs, err := ts.Service.FindEscalations(ctx, nr.ID); err != nil || len(escs) != 1 || escs[0].ID != esc.ID {
+			t.Fatalf("expected the escalation of the rule, got %+v, %v", escs, err)
+		}
+
+		// each step of the policy is recorded once for the alert.
+		again := &influxdb.Escalation{RuleID: nr.ID, PolicyID: p.ID, CheckID: 1, Level: "CRIT"}
+		if err := ts.Service.PutEscalation(ctx, again); err != influxdb.ErrEscalationStepSent {
+			t.Fatalf("expected a step that was already recorded to fail, got %v", err)
+		}
+		next := &influxdb.Escalation{RuleID: nr.ID, PolicyID: p.ID, CheckID: 1, Level: "CRIT", Steps: 1}
+		if err := ts.Service.PutEscalation(ctx, next); err != nil {
+			t.Fatal(err)
+		}
+		if next.ID != esc.ID {
+			t.Fatalf("expected the escalation of the alert to be replaced, got %s", next.ID)
+		}
+		if err := ts.Service.PutEscalation(ctx, next); err != influxdb.ErrEscalationStepSent {
+			t.Fatalf("expected a step that was already recorded to fail, got %v", err)
+		}
+
+		if err := ts.Service.DeleteNotificationRule(ctx, nr.ID); err != nil {
+			t.Fatal(err)
+		}
+		if escs, err := ts.Service.FindEscalations(ctx, nr.ID); err != nil || len(escs) != 0 {
+			t.Fatalf("expected the escalations of the rule to be deleted with it, got %+v, %v", escs, err)
+		}
+
+		if err := ts.Service.DeleteEscalationPolicy(ctx, p.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts.Service.FindEscalationPolicyByID(ctx, p.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the policy to be deleted, got %v", err)
+		}
+	})
+}
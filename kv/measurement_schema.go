@@ -0,0 +1,222 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MeasurementSchemaService = (*Service)(nil)
+
+func newMeasurementSchemaStore() *IndexStore {
+	const resource = "measurement schema"
+
+	var decSchemaEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var ms influxdb.MeasurementSchema
+		return key, &ms, json.Unmarshal(val, &ms)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		ms, ok := v.(*influxdb.MeasurementSchema)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return measurementSchemaEnt(ms), nil
+	}
+
+	return &IndexStore{
+		Resource:   resource,
+		EntStore:   NewStoreBase(resource, []byte("measurementschemasv1"), EncIDKey, EncBodyJSON, decSchemaEntFn, decValToEntFn),
+		IndexStore: NewOrgNameKeyStore(resource, []byte("measurementschemaindexv1"), true),
+	}
+}
+
+// measurementSchemaEnt returns the entity of a measurement schema. Names are
+// unique within a bucket, so the bucket ID takes the place of the org ID in
+// the name index.
+func measurementSchemaEnt(ms *influxdb.MeasurementSchema) Entity {
+	return Entity{
+		PK:        EncID(ms.ID),
+		UniqueKey: Encode(EncID(ms.BucketID), EncString(ms.Name)),
+		Body:      ms,
+	}
+}
+
+// FindMeasurementSchemaByID retrieves a measurement schema by id.
+func (s *Service) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		ms, err = s.findMeasurementSchemaByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (s *Service) findMeasurementSchemaByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	v, err := s.measurementSchemaStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpFindMeasurementSchemaByID,
+				Msg:  influxdb.ErrMeasurementSchemaNotFound.Msg,
+			}
+		}
+		return nil, err
+	}
+	return v.(*influxdb.MeasurementSchema), nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas of a bucket. When a
+// name is provided, at most one schema is returned.
+func (s *Service) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var schemas []*influxdb.MeasurementSchema
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		schemas, err = s.findMeasurementSchemas(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (s *Service) findMeasurementSchemas(ctx context.Context, tx Tx, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	schemas := []*influxdb.MeasurementSchema{}
+	if filter.Name != nil {
+		v, err := s.measurementSchemaStore.FindEnt(ctx, tx, Entity{
+			UniqueKey: Encode(EncID(filter.BucketID), EncString(*filter.Name)),
+		})
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return schemas, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return append(schemas, v.(*influxdb.MeasurementSchema)), nil
+	}
+
+	err := s.measurementSchemaStore.Find(ctx, tx, FindOpts{
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			ms, ok := v.(*influxdb.MeasurementSchema)
+			return ok && ms.BucketID == filter.BucketID
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			schemas = append(schemas, v.(*influxdb.MeasurementSchema))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// CreateMeasurementSchema creates a measurement schema in the bucket given by
+// ms.BucketID and sets ms.ID with the new identifier.
+func (s *Service) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := ms.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		b, err := s.findBucketByID(ctx, tx, ms.BucketID)
+		if err != nil {
+			return err
+		}
+		ms.OrgID = b.OrgID
+
+		_, err = s.measurementSchemaStore.FindEnt(ctx, tx, Entity{
+			UniqueKey: Encode(EncID(ms.BucketID), EncString(ms.Name)),
+		})
+		if err == nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Op:   influxdb.OpCreateMeasurementSchema,
+				Msg:  fmt.Sprintf("measurement schema %q already exists in bucket %s", ms.Name, b.Name),
+			}
+		} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+
+		ms.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		ms.CreatedAt = now
+		ms.UpdatedAt = now
+
+		if err := s.measurementSchemaStore.Put(ctx, tx, measurementSchemaEnt(ms), PutNew()); err != nil {
+			return err
+		}
+		return s.recompileDownsamplingTasks(ctx, tx, b.ID)
+	})
+}
+
+// UpdateMeasurementSchema adds columns to a measurement schema.
+func (s *Service) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.Update(ctx, func(tx Tx) (err error) {
+		ms, err = s.findMeasurementSchemaByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := ms.Apply(upd); err != nil {
+			return err
+		}
+		ms.UpdatedAt = s.TimeGenerator.Now()
+
+		if err := s.measurementSchemaStore.Put(ctx, tx, measurementSchemaEnt(ms), PutUpdate()); err != nil {
+			return err
+		}
+		return s.recompileDownsamplingTasks(ctx, tx, ms.BucketID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// DeleteMeasurementSchema removes a measurement schema by id.
+func (s *Service) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		ms, err := s.findMeasurementSchemaByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.measurementSchemaStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)}); err != nil {
+			return err
+		}
+		return s.recompileDownsamplingTasks(ctx, tx, ms.BucketID)
+	})
+}
+
+// deleteBucketMeasurementSchemas removes all measurement schemas of a bucket.
+func (s *Service) deleteBucketMeasurementSchemas(ctx context.Context, tx Tx, bucketID influxdb.ID) error {
+	return s.measurementSchemaStore.Delete(ctx, tx, DeleteOpts{
+		FilterFn: func(k []byte, v interface{}) bool {
+			ms, ok := v.(*influxdb.MeasurementSchema)
+			return ok && ms.BucketID == bucketID
+		},
+	})
+}
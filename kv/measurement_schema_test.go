@@ -0,0 +1,100 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltMeasurementSchemaService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	testMeasurementSchemaService(t, s)
+}
+
+func TestInmemMeasurementSchemaService(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	testMeasurementSchemaService(t, s)
+}
+
+func testMeasurementSchemaService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing measurement schema service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{OrgID: org.ID, Name: "b", SchemaType: influxdb.SchemaTypeExplicit}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	columns := []influxdb.MeasurementSchemaColumn{
+		{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+		{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+		{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+	}
+	ms := &influxdb.MeasurementSchema{BucketID: bucket.ID, Name: "cpu", Columns: columns}
+	if err := svc.CreateMeasurementSchema(ctx, ms); err != nil {
+		t.Fatal(err)
+	}
+	if ms.OrgID != org.ID {
+		t.Errorf("unexpected org ID: got %s want %s", ms.OrgID, org.ID)
+	}
+
+	dup := &influxdb.MeasurementSchema{BucketID: bucket.ID, Name: "cpu", Columns: columns}
+	if err := svc.CreateMeasurementSchema(ctx, dup); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected conflict creating duplicate schema, got %v", err)
+	}
+
+	name := "cpu"
+	found, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: bucket.ID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != ms.ID {
+		t.Fatalf("unexpected schemas: %+v", found)
+	}
+
+	// Columns can be added...
+	added := append(append([]influxdb.MeasurementSchemaColumn{}, columns...),
+		influxdb.MeasurementSchemaColumn{Name: "idle", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat})
+	updated, err := svc.UpdateMeasurementSchema(ctx, ms.ID, influxdb.MeasurementSchemaUpdate{Columns: added})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(updated.Columns), 4; got != want {
+		t.Errorf("unexpected number of columns: got %d want %d", got, want)
+	}
+
+	// ...but not changed.
+	changed := append([]influxdb.MeasurementSchemaColumn{}, added...)
+	changed[2].DataType = influxdb.SchemaColumnDataTypeInteger
+	if _, err := svc.UpdateMeasurementSchema(ctx, ms.ID, influxdb.MeasurementSchemaUpdate{Columns: changed}); influxdb.ErrorCode(err) != influxdb.EUnprocessableEntity {
+		t.Errorf("expected unprocessable entity changing a column, got %v", err)
+	}
+
+	// Deleting the bucket removes its schemas.
+	if err := svc.DeleteBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindMeasurementSchemaByID(ctx, ms.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected schema to be deleted with its bucket, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.NotificationGroupService = (*Service)(nil)
+
+func newNotificationGroupStore() *StoreBase {
+	const resource = "notification groups"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var g influxdb.NotificationGroups
+		return key, &g, json.Unmarshal(val, &g)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		g, ok := v.(*influxdb.NotificationGroups)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(g.RuleID), Body: g}, nil
+	}
+
+	return NewStoreBase(resource, []byte("notificationgroupsv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// FindNotificationGroups returns the groups of the notifications of a
+// notification rule, which has none before its first grouped notification.
+func (s *Service) FindNotificationGroups(ctx context.Context, ruleID influxdb.ID) (*influxdb.NotificationGroups, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var g *influxdb.NotificationGroups
+	err := s.kv.View(ctx, func(tx Tx) error {
+		v, err := s.notificationGroupStore.FindEnt(ctx, tx, Entity{PK: EncID(ruleID)})
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				g = &influxdb.NotificationGroups{RuleID: ruleID, Groups: []*influxdb.NotificationGroup{}}
+				return nil
+			}
+			return err
+		}
+		g = v.(*influxdb.NotificationGroups)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// PutNotificationGroups replaces the groups of the notifications of a
+// notification rule, unless they changed since g was read.
+func (s *Service) PutNotificationGroups(ctx context.Context, g *influxdb.NotificationGroups) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		var version int
+		v, err := s.notificationGroupStore.FindEnt(ctx, tx, Entity{PK: EncID(g.RuleID)})
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		if err == nil {
+			version = v.(*influxdb.NotificationGroups).Version
+		}
+		if version != g.Version {
+			return influxdb.ErrNotificationGroupsChanged
+		}
+
+		g.Version++
+		if err := s.notificationGroupStore.Put(ctx, tx, Entity{PK: EncID(g.RuleID), Body: g}); err != nil {
+			g.Version--
+			return err
+		}
+		return nil
+	})
+}
+
+// deleteNotificationRuleGroups removes the groups of the notifications of a
+// notification rule.
+func (s *Service) deleteNotificationRuleGroups(ctx context.Context, tx Tx, ruleID influxdb.ID) error {
+	err := s.notificationGroupStore.DeleteEnt(ctx, tx, Entity{PK: EncID(ruleID)})
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestService_NotificationGroups(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	e := &endpoint.Slack{
+		Base: endpoint.Base{Name: "ops", OrgID: &ts.Org.ID, Status: influxdb.Active},
+		URL:  "http://localhost:7777",
+	}
+	if err := ts.Service.CreateNotificationEndpoint(ctx, e, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	every, _ := notification.FromTimeDuration(time.Hour)
+	nr := &rule.Slack{
+		Base: rule.Base{
+			Name:        "crit",
+			OrgID:       ts.Org.ID,
+			EndpointID:  *e.ID,
+			Every:       &every,
+			StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+			Grouping: &influxdb.NotificationGrouping{
+				By:   []string{"dc"},
+				Wait: influxdb.Duration{Duration: time.Minute},
+			},
+		},
+		MessageTemplate: "crit",
+	}
+	if err := ts.Service.CreateNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := ts.Service.FindNotificationGroups(ctx, nr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.RuleID != nr.ID || len(g.Groups) != 0 {
+		t.Fatalf("expected no groups before the first notification, got %+v", g)
+	}
+
+	g.Groups = append(g.Groups, &influxdb.NotificationGroup{
+		Tags:    []influxdb.Tag{{Key: "dc", Value: "east"}},
+		Pending: []*influxdb.AlertStatus{{CheckID: 1, Level: "CRIT"}},
+	})
+	if err := ts.Service.PutNotificationGroups(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := ts.Service.FindNotificationGroups(ctx, nr.ID); err != nil || len(g.Groups) != 1 || len(g.Groups[0].Pending) != 1 {
+		t.Fatalf("expected the group of the rule, got %+v, %v", g, err)
+	}
+
+	// groups read before another run changed them aren't put.
+	stale := &influxdb.NotificationGroups{RuleID: nr.ID}
+	if err := ts.Service.PutNotificationGroups(ctx, stale); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected a conflict putting groups of an earlier version, got %v", err)
+	}
+	g.Groups[0].Pending = nil
+	if err := ts.Service.PutNotificationGroups(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := ts.Service.FindNotificationGroups(ctx, nr.ID); err != nil || g.Version != 2 || len(g.Groups[0].Pending) != 0 {
+		t.Fatalf("expected the second version of the groups of the rule, got %+v, %v", g, err)
+	}
+
+	if err := ts.Service.DeleteNotificationRule(ctx, nr.ID); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := ts.Service.FindNotificationGroups(ctx, nr.ID); err != nil || len(g.Groups) != 0 {
+		t.Fatalf("expected the groups of the rule to be deleted with it, got %+v, %v", g, err)
+	}
+}
@@ -95,6 +95,10 @@ func (s *Service) createNotificationRule(ctx context.Context, tx Tx, nr influxdb
 		return err
 	}
 
+	if err := s.validNotificationRuleEscalationPolicy(ctx, tx, nr); err != nil {
+		return err
+	}
+
 	if err := s.putNotificationRule(ctx, tx, nr.NotificationRule); err != nil {
 		return err
 	}
@@ -108,13 +112,25 @@ func (s *Service) createNotificationRule(ctx context.Context, tx Tx, nr influxdb
 	return s.createUserResourceMapping(ctx, tx, urm)
 }
 
-func (s *Service) createNotificationTask(ctx context.Context, tx Tx, r influxdb.NotificationRuleCreate) (*influxdb.Task, error) {
+// generateNotificationRuleFlux generates the script of the task of a notification
+// rule, which honours the silences of the rule's organization.
+func (s *Service) generateNotificationRuleFlux(ctx context.Context, tx Tx, r influxdb.NotificationRule) (string, error) {
 	ep, err := s.findNotificationEndpointByID(ctx, tx, r.GetEndpointID())
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	script, err := r.GenerateFlux(ep)
+	silences, err := s.activeSilences(ctx, tx, r.GetOrgID())
+	if err != nil {
+		return "", err
+	}
+	r.SetSilences(silences)
+
+	return r.GenerateFlux(ep)
+}
+
+func (s *Service) createNotificationTask(ctx context.Context, tx Tx, r influxdb.NotificationRuleCreate) (*influxdb.Task, error) {
+	script, err := s.generateNotificationRuleFlux(ctx, tx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -138,12 +154,7 @@ func (s *Service) createNotificationTask(ctx context.Context, tx Tx, r influxdb.
 }
 
 func (s *Service) updateNotificationTask(ctx context.Context, tx Tx, r influxdb.NotificationRule, status *string) (*influxdb.Task, error) {
-	ep, err := s.findNotificationEndpointByID(ctx, tx, r.GetEndpointID())
-	if err != nil {
-		return nil, err
-	}
-
-	script, err := r.GenerateFlux(ep)
+	script, err := s.generateNotificationRuleFlux(ctx, tx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +210,10 @@ func (s *Service) updateNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return nil, err
 	}
 
+	if err := s.validNotificationRuleEscalationPolicy(ctx, tx, nr); err != nil {
+		return nil, err
+	}
+
 	_, err = s.updateNotificationTask(ctx, tx, nr, strPtr(string(nr.Status)))
 	if err != nil {
 		return nil, err
@@ -432,6 +447,17 @@ func (s *Service) forEachNotificationRule(ctx context.Context, tx Tx, descending
 }
 
 func filterNotificationRulesFn(idMap map[influxdb.ID]bool, filter influxdb.NotificationRuleFilter) func(nr influxdb.NotificationRule) bool {
+	if filter.TaskID != nil {
+		return func(nr influxdb.NotificationRule) bool {
+			if nr.GetTaskID() != *filter.TaskID || !nr.MatchesTags(filter.Tags) {
+				return false
+			}
+
+			_, ok := idMap[nr.GetID()]
+			return (filter.OrgID == nil || nr.GetOrgID() == *filter.OrgID) && ok
+		}
+	}
+
 	if filter.OrgID != nil {
 		return func(nr influxdb.NotificationRule) bool {
 			if !nr.MatchesTags(filter.Tags) {
@@ -492,6 +518,14 @@ func (s *Service) deleteNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return InternalNotificationRuleStoreError(err)
 	}
 
+	if err := s.deleteNotificationRuleEscalations(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := s.deleteNotificationRuleGroups(ctx, tx, id); err != nil {
+		return err
+	}
+
 	if err := s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
 		ResourceID:   id,
 		ResourceType: influxdb.NotificationRuleResourceType,
@@ -50,6 +50,18 @@ type Service struct {
 	checkStore    *IndexStore
 	endpointStore *IndexStore
 	variableStore *IndexStore
+
+	measurementSchemaStore    *IndexStore
+	backfillStore             *StoreBase
+	taskVersionStore          *StoreBase
+	taskNodeStore             *StoreBase
+	taskPartitionLeaseStore   *StoreBase
+	silenceStore              *StoreBase
+	alertAcknowledgementStore *StoreBase
+	escalationPolicyStore     *StoreBase
+	escalationStore           *StoreBase
+	notificationGroupStore    *StoreBase
+	cardinalitySampleStore    *StoreBase
 }
 
 // NewService returns an instance of a Service.
@@ -68,6 +80,18 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		endpointStore:  newEndpointStore(),
 		variableStore:  newVariableStore(),
 		indexer:        NewIndexer(log, kv),
+
+		measurementSchemaStore:    newMeasurementSchemaStore(),
+		backfillStore:             newBackfillStore(),
+		taskVersionStore:          newTaskVersionStore(),
+		taskNodeStore:             newTaskNodeStore(),
+		taskPartitionLeaseStore:   newTaskPartitionLeaseStore(),
+		silenceStore:              newSilenceStore(),
+		alertAcknowledgementStore: newAlertAcknowledgementStore(),
+		escalationPolicyStore:     newEscalationPolicyStore(),
+		escalationStore:           newEscalationStore(),
+		notificationGroupStore:    newNotificationGroupStore(),
+		cardinalitySampleStore:    newCardinalitySampleStore(),
 	}
 
 	if len(configs) > 0 {
@@ -178,6 +202,50 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.measurementSchemaStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.backfillStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.taskVersionStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.taskNodeStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.taskPartitionLeaseStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.silenceStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.alertAcknowledgementStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.escalationPolicyStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.escalationStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.notificationGroupStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.cardinalitySampleStore.Init(ctx, tx); err != nil {
+			return err
+		}
+
 		return s.initializeUsers(ctx, tx)
 	})
 
@@ -0,0 +1,226 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.SilenceService = (*Service)(nil)
+
+func newSilenceStore() *StoreBase {
+	const resource = "silence"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var sl influxdb.Silence
+		return key, &sl, json.Unmarshal(val, &sl)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		sl, ok := v.(*influxdb.Silence)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(sl.ID), Body: sl}, nil
+	}
+
+	return NewStoreBase(resource, []byte("silencesv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// FindSilenceByID retrieves a silence by id.
+func (s *Service) FindSilenceByID(ctx context.Context, id influxdb.ID) (*influxdb.Silence, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sl *influxdb.Silence
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		sl, err = s.findSilenceByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+func (s *Service) findSilenceByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Silence, error) {
+	v, err := s.silenceStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpFindSilenceByID,
+				Msg:  influxdb.ErrSilenceNotFound.Msg,
+			}
+		}
+		return nil, err
+	}
+	return v.(*influxdb.Silence), nil
+}
+
+// FindSilences returns the silences that match filter, in the order they were created.
+func (s *Service) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var silences []*influxdb.Silence
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if filter.OrgID == nil && filter.Organization != nil {
+			o, err := s.findOrganizationByName(ctx, tx, *filter.Organization)
+			if err != nil {
+				return err
+			}
+			filter.OrgID = &o.ID
+		}
+
+		var err error
+		silences, err = s.findSilences(ctx, tx, filter, opt...)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return silences, len(silences), nil
+}
+
+func (s *Service) findSilences(ctx context.Context, tx Tx, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, error) {
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+
+	silences := []*influxdb.Silence{}
+	err := s.silenceStore.Find(ctx, tx, FindOpts{
+		Descending: o.Descending,
+		Offset:     o.Offset,
+		Limit:      o.Limit,
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			sl, ok := v.(*influxdb.Silence)
+			return ok && (filter.OrgID == nil || sl.OrgID == *filter.OrgID)
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			silences = append(silences, v.(*influxdb.Silence))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// CreateSilence creates a silence and sets sl.ID with the new identifier. The
+// tasks of the notification rules of the organization are updated to honour it.
+func (s *Service) CreateSilence(ctx context.Context, sl *influxdb.Silence) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := sl.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, sl.OrgID); err != nil {
+			return err
+		}
+
+		sl.ID = s.IDGenerator.ID()
+		now := s.TimeGenerator.Now()
+		sl.CreatedAt = now
+		sl.UpdatedAt = now
+
+		if err := s.silenceStore.Put(ctx, tx, Entity{PK: EncID(sl.ID), Body: sl}, PutNew()); err != nil {
+			return err
+		}
+		return s.recompileNotificationRuleTasks(ctx, tx, sl.OrgID)
+	})
+}
+
+// UpdateSilence updates a silence and the tasks of the notification rules of
+// its organization.
+func (s *Service) UpdateSilence(ctx context.Context, id influxdb.ID, upd influxdb.SilenceUpdate) (*influxdb.Silence, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sl *influxdb.Silence
+	err := s.kv.Update(ctx, func(tx Tx) (err error) {
+		sl, err = s.findSilenceByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := sl.Apply(upd); err != nil {
+			return err
+		}
+		sl.UpdatedAt = s.TimeGenerator.Now()
+
+		if err := s.silenceStore.Put(ctx, tx, Entity{PK: EncID(sl.ID), Body: sl}, PutUpdate()); err != nil {
+			return err
+		}
+		return s.recompileNotificationRuleTasks(ctx, tx, sl.OrgID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// DeleteSilence removes a silence by id, and updates the tasks of the
+// notification rules of its organization.
+func (s *Service) DeleteSilence(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		sl, err := s.findSilenceByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.silenceStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)}); err != nil {
+			return err
+		}
+		return s.recompileNotificationRuleTasks(ctx, tx, sl.OrgID)
+	})
+}
+
+// activeSilences returns the silences of an organization that have windows
+// that did not close yet.
+func (s *Service) activeSilences(ctx context.Context, tx Tx, orgID influxdb.ID) ([]*influxdb.Silence, error) {
+	silences, err := s.findSilences(ctx, tx, influxdb.SilenceFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.TimeGenerator.Now()
+	var active []*influxdb.Silence
+	for _, sl := range silences {
+		if !sl.Expired(now) {
+			active = append(active, sl)
+		}
+	}
+	return active, nil
+}
+
+// recompileNotificationRuleTasks regenerates the scripts of the tasks of the
+// notification rules of an organization, so that they honour its silences.
+func (s *Service) recompileNotificationRuleTasks(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	var rules []influxdb.NotificationRule
+	err := s.forEachNotificationRule(ctx, tx, false, func(nr influxdb.NotificationRule) bool {
+		if nr.GetOrgID() == orgID {
+			rules = append(rules, nr)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, nr := range rules {
+		if _, err := s.updateNotificationTask(ctx, tx, nr, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
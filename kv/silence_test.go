@@ -0,0 +1,120 @@
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestService_Silences(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	e := &endpoint.Slack{
+		Base: endpoint.Base{Name: "on-call", OrgID: &ts.Org.ID, Status: influxdb.Active},
+		URL:  "http://localhost:7777",
+	}
+	if err := ts.Service.CreateNotificationEndpoint(ctx, e, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	every, _ := notification.FromTimeDuration(time.Hour)
+	nr := &rule.Slack{
+		Base: rule.Base{
+			Name:        "crit",
+			OrgID:       ts.Org.ID,
+			EndpointID:  *e.ID,
+			Every:       &every,
+			StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+		},
+		MessageTemplate: "crit",
+	}
+	if err := ts.Service.CreateNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: nr, Status: influxdb.Active}, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	taskFlux := func() string {
+		t.Helper()
+		task, err := ts.Service.FindTaskByID(ctx, nr.GetTaskID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task.Flux
+	}
+	if strings.Contains(taskFlux(), "silenced") {
+		t.Fatalf("expected the rule not to be silenced:\n%s", taskFlux())
+	}
+
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	sl := &influxdb.Silence{
+		OrgID:     ts.Org.ID,
+		Name:      "maintenance",
+		CheckName: "cpu",
+		Start:     start,
+		End:       start.Add(time.Hour),
+	}
+	if err := ts.Service.CreateSilence(ctx, sl); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(taskFlux(), `r._check_name == "cpu"`) {
+		t.Fatalf("expected the rule task to honour the silence:\n%s", taskFlux())
+	}
+
+	t.Run("find", func(t *testing.T) {
+		silences, n, err := ts.Service.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &ts.Org.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || silences[0].ID != sl.ID {
+			t.Fatalf("expected the silence of the org, got %+v", silences)
+		}
+
+		other := influxdb.ID(1234)
+		if silences, _, err = ts.Service.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &other}); err != nil || len(silences) != 0 {
+			t.Fatalf("expected no silences in another org, got %+v, %v", silences, err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		name := "mem"
+		updated, err := ts.Service.UpdateSilence(ctx, sl.ID, influxdb.SilenceUpdate{CheckName: &name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.CheckName != name {
+			t.Fatalf("expected the check name to be updated, got %q", updated.CheckName)
+		}
+		if !strings.Contains(taskFlux(), `r._check_name == "mem"`) {
+			t.Fatalf("expected the rule task to honour the updated silence:\n%s", taskFlux())
+		}
+
+		end := start.Add(-time.Hour)
+		if _, err := ts.Service.UpdateSilence(ctx, sl.ID, influxdb.SilenceUpdate{End: &end}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid silence to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := ts.Service.DeleteSilence(ctx, sl.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts.Service.FindSilenceByID(ctx, sl.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected the silence to be deleted, got %v", err)
+		}
+		if strings.Contains(taskFlux(), "silenced") {
+			t.Fatalf("expected the rule not to be silenced:\n%s", taskFlux())
+		}
+	})
+}
@@ -24,13 +24,16 @@ import (
 //   <taskID>/latestCompleted: run data for the latest completed run of a task
 // taskIndexBucket
 //   <orgID>/<taskID>: index for tasks by org
+// taskDependencyIndexBucket
+//   <upstreamTaskID>/<taskID>: index for tasks by the tasks they depend on
 
 // We may want to add a <taskName>/<taskID> index to allow us to look up tasks by task name.
 
 var (
-	taskBucket      = []byte("tasksv1")
-	taskRunBucket   = []byte("taskRunsv1")
-	taskIndexBucket = []byte("taskIndexsv1")
+	taskBucket                = []byte("tasksv1")
+	taskRunBucket             = []byte("taskRunsv1")
+	taskIndexBucket           = []byte("taskIndexsv1")
+	taskDependencyIndexBucket = []byte("taskDependencyIndexv1")
 )
 
 var _ influxdb.TaskService = (*Service)(nil)
@@ -51,11 +54,17 @@ type kvTask struct {
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
 	Offset          influxdb.Duration      `json:"offset,omitempty"`
+	Location        string                 `json:"location,omitempty"`
+	DependsOn       []influxdb.ID          `json:"dependsOn,omitempty"`
+	Version         int                    `json:"version,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	Notification *influxdb.TaskNotification `json:"notification,omitempty"`
+	FailedRuns   int                        `json:"failedRuns,omitempty"`
 }
 
 func kvToInfluxTask(k *kvTask) *influxdb.Task {
@@ -74,6 +83,11 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		LastRunStatus:   k.LastRunStatus,
 		LastRunError:    k.LastRunError,
 		Offset:          k.Offset.Duration,
+		Location:        k.Location,
+		DependsOn:       k.DependsOn,
+		Notification:    k.Notification,
+		FailedRuns:      k.FailedRuns,
+		Version:         k.Version,
 		LatestCompleted: k.LatestCompleted,
 		LatestScheduled: k.LatestScheduled,
 		CreatedAt:       k.CreatedAt,
@@ -92,6 +106,9 @@ func (s *Service) initializeTasks(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(taskIndexBucket); err != nil {
 		return err
 	}
+	if _, err := tx.Bucket(taskDependencyIndexBucket); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -267,6 +284,11 @@ func (s *Service) findTasks(ctx context.Context, tx Tx, filter influxdb.TaskFilt
 		}
 	}
 
+	// filter by the task the tasks depend on.
+	if filter.DependsOn != nil {
+		return s.findTasksByDependency(ctx, tx, filter, org)
+	}
+
 	// filter by user id.
 	if filter.User != nil {
 		return s.findTasksByUser(ctx, tx, filter)
@@ -450,6 +472,72 @@ type taskMatchFn func(*influxdb.Task) bool
 // newTaskMatchFn returns a function for validating
 // a task matches the filter. Will return nil if
 // the filter should match all tasks.
+// findTasksByDependency is a subset of the find tasks function. Used for cleanliness
+func (s *Service) findTasksByDependency(ctx context.Context, tx Tx, filter influxdb.TaskFilter, org *influxdb.Organization) ([]*influxdb.Task, int, error) {
+	indexBucket, err := tx.Bucket(taskDependencyIndexBucket)
+	if err != nil {
+		return nil, 0, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	prefix, err := filter.DependsOn.Encode()
+	if err != nil {
+		return nil, 0, influxdb.ErrInvalidTaskID
+	}
+	prefix = append(prefix, '/')
+
+	var (
+		key  = prefix
+		opts []CursorOption
+	)
+	if filter.After != nil {
+		key, err = taskDependencyKey(*filter.DependsOn, *filter.After)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		opts = append(opts, WithCursorSkipFirstItem())
+	}
+
+	c, err := indexBucket.ForwardCursor(
+		key,
+		append(opts, WithCursorPrefix(prefix))...,
+	)
+	if err != nil {
+		return nil, 0, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	// free cursor resources
+	defer c.Close()
+
+	var ts []*influxdb.Task
+	matchFn := newTaskMatchFn(filter, org)
+	for k, v := c.Next(); k != nil; k, v = c.Next() {
+		id, err := influxdb.IDFromString(string(v))
+		if err != nil {
+			return nil, 0, influxdb.ErrInvalidTaskID
+		}
+
+		t, err := s.findTaskByIDWithAuth(ctx, tx, *id)
+		if err != nil {
+			if err == influxdb.ErrTaskNotFound {
+				// we might have some crufty index's
+				continue
+			}
+			return nil, 0, err
+		}
+
+		if matchFn == nil || matchFn(t) {
+			ts = append(ts, t)
+			// Check if we are over running the limit
+			if len(ts) >= filter.Limit {
+				break
+			}
+		}
+	}
+
+	return ts, len(ts), c.Err()
+}
+
 func newTaskMatchFn(f influxdb.TaskFilter, org *influxdb.Organization) func(t *influxdb.Task) bool {
 	var fn taskMatchFn
 
@@ -603,9 +691,21 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		tc.Status = string(backend.TaskActive)
 	}
 
+	id := s.IDGenerator.ID()
+	dependsOn, err := s.taskDependencies(ctx, tx, org.ID, id, opt.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc.Notification != nil {
+		if err := s.validateTaskNotification(ctx, tx, org.ID, tc.Notification); err != nil {
+			return nil, err
+		}
+	}
+
 	createdAt := s.clock.Now().Truncate(time.Second).UTC()
 	task := &influxdb.Task{
-		ID:              s.IDGenerator.ID(),
+		ID:              id,
 		Type:            tc.Type,
 		OrganizationID:  org.ID,
 		Organization:    org.Name,
@@ -617,6 +717,9 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		Flux:            tc.Flux,
 		Every:           opt.Every.String(),
 		Cron:            opt.Cron,
+		Location:        opt.Location,
+		DependsOn:       dependsOn,
+		Notification:    tc.Notification,
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
@@ -631,6 +734,11 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 
 	}
 
+	uid, _ := icontext.GetUserID(ctx)
+	if err := s.createTaskVersion(ctx, tx, task, uid); err != nil {
+		return nil, err
+	}
+
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
@@ -668,6 +776,11 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// write the dependency index
+	if err := s.putTaskDependencyIndex(ctx, tx, task.ID, task.DependsOn); err != nil {
+		return nil, err
+	}
+
 	if err := s.createTaskURM(ctx, tx, task); err != nil {
 		s.log.Info("Error creating user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
 	}
@@ -682,7 +795,6 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		Permissions: ps,
 	}
 
-	uid, _ := icontext.GetUserID(ctx)
 	if err := s.audit.Log(resource.Change{
 		Type:           resource.Create,
 		ResourceID:     task.ID,
@@ -698,6 +810,86 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 	return task, nil
 }
 
+// validateTaskNotification returns an error if the notification of a task
+// is invalid, or its endpoint doesn't belong to the organization of the task.
+func (s *Service) validateTaskNotification(ctx context.Context, tx Tx, orgID influxdb.ID, n *influxdb.TaskNotification) error {
+	if err := n.Valid(); err != nil {
+		return err
+	}
+	e, err := s.findNotificationEndpointByID(ctx, tx, n.EndpointID)
+	if err != nil {
+		return err
+	}
+	if e.GetOrgID() != orgID {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task notification endpoint must belong to the organization of the task",
+		}
+	}
+	return nil
+}
+
+// taskDependencies returns the IDs of the upstream tasks deps of the task id.
+// Upstream tasks must belong to the organization of the task, and must not
+// depend on the task themselves.
+func (s *Service) taskDependencies(ctx context.Context, tx Tx, orgID, id influxdb.ID, deps []string) ([]influxdb.ID, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]influxdb.ID, 0, len(deps))
+	for _, dep := range deps {
+		depID, err := influxdb.IDFromString(dep)
+		if err != nil {
+			return nil, influxdb.ErrTaskDependencyInvalid(dep, "invalid task ID")
+		}
+		if *depID == id {
+			return nil, influxdb.ErrTaskDependencyCycle(id)
+		}
+
+		t, err := s.findTaskByID(ctx, tx, *depID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return nil, influxdb.ErrTaskDependencyInvalid(dep, "task not found")
+			}
+			return nil, err
+		}
+		if t.OrganizationID != orgID {
+			return nil, influxdb.ErrTaskDependencyInvalid(dep, "task belongs to another organization")
+		}
+		ids = append(ids, *depID)
+	}
+
+	// walk the upstream tasks of the dependencies, a cycle reaches the task again.
+	visited := map[influxdb.ID]bool{}
+	queue := append([]influxdb.ID(nil), ids...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if visited[next] {
+			continue
+		}
+		visited[next] = true
+
+		t, err := s.findTaskByID(ctx, tx, next)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			// a deleted upstream task no longer has dependencies.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, up := range t.DependsOn {
+			if up == id {
+				return nil, influxdb.ErrTaskDependencyCycle(id)
+			}
+			queue = append(queue, up)
+		}
+	}
+
+	return ids, nil
+}
+
 func (s *Service) createTaskURM(ctx context.Context, tx Tx, t *influxdb.Task) error {
 	// TODO(jsteenb2): should not be getting authorizer inside the store, should terminate at the
 	//  transport layer then pass user id everywhere else.
@@ -740,12 +932,23 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 	}
 
 	updatedAt := s.clock.Now().UTC()
+	uid, _ := icontext.GetUserID(ctx)
 
 	// update the flux script
+	var fluxChanged bool
 	if !upd.Options.IsZero() || upd.Flux != nil {
 		if err = upd.UpdateFlux(task.Flux); err != nil {
 			return nil, err
 		}
+
+		// tasks created before versions were recorded keep their script as
+		// their first version.
+		if task.Version == 0 {
+			if err := s.createTaskVersion(ctx, tx, task, 0); err != nil {
+				return nil, err
+			}
+		}
+		fluxChanged = task.Flux != *upd.Flux
 		task.Flux = *upd.Flux
 
 		options, err := options.FromScript(*upd.Flux)
@@ -755,6 +958,17 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.Name = options.Name
 		task.Every = options.Every.String()
 		task.Cron = options.Cron
+		task.Location = options.Location
+		if err := s.deleteTaskDependencyIndex(ctx, tx, task.ID, task.DependsOn); err != nil {
+			return nil, err
+		}
+		task.DependsOn, err = s.taskDependencies(ctx, tx, task.OrganizationID, task.ID, options.DependsOn)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.putTaskDependencyIndex(ctx, tx, task.ID, task.DependsOn); err != nil {
+			return nil, err
+		}
 
 		var off time.Duration
 		if options.Offset != nil {
@@ -772,6 +986,17 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.UpdatedAt = updatedAt
 	}
 
+	if upd.Notification != nil {
+		task.Notification = nil
+		if upd.Notification.EndpointID.Valid() {
+			if err := s.validateTaskNotification(ctx, tx, task.OrganizationID, upd.Notification); err != nil {
+				return nil, err
+			}
+			task.Notification = upd.Notification
+		}
+		task.UpdatedAt = updatedAt
+	}
+
 	if upd.Status != nil && task.Status != *upd.Status {
 		task.Status = *upd.Status
 		task.UpdatedAt = updatedAt
@@ -808,6 +1033,12 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 
 	if upd.LastRunStatus != nil {
 		task.LastRunStatus = *upd.LastRunStatus
+		switch *upd.LastRunStatus {
+		case "failed":
+			task.FailedRuns++
+		case "success":
+			task.FailedRuns = 0
+		}
 		if *upd.LastRunStatus == "failed" && upd.LastRunError != nil {
 			task.LastRunError = *upd.LastRunError
 		} else {
@@ -815,6 +1046,12 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		}
 	}
 
+	if fluxChanged {
+		if err := s.createTaskVersion(ctx, tx, task, uid); err != nil {
+			return nil, err
+		}
+	}
+
 	// save the updated task
 	bucket, err := tx.Bucket(taskBucket)
 	if err != nil {
@@ -835,7 +1072,6 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
-	uid, _ := icontext.GetUserID(ctx)
 	if err := s.audit.Log(resource.Change{
 		Type:           resource.Update,
 		ResourceID:     task.ID,
@@ -899,6 +1135,14 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// remove the dependency index of the task, and of the tasks that depend on it
+	if err := s.deleteTaskDependencyIndex(ctx, tx, task.ID, task.DependsOn); err != nil {
+		return err
+	}
+	if err := s.deleteDownstreamTaskIndex(ctx, tx, task.ID); err != nil {
+		return err
+	}
+
 	// remove latest completed
 	lastCompletedKey, err := taskLatestCompletedKey(task.ID)
 	if err != nil {
@@ -925,6 +1169,13 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 			return influxdb.ErrUnexpectedTaskBucketErr(err)
 		}
 	}
+	if err := s.deleteTaskBackfills(ctx, tx, task.ID); err != nil {
+		return err
+	}
+	if err := s.deleteTaskVersions(ctx, tx, task.ID); err != nil {
+		return err
+	}
+
 	// remove the task
 	key, err := taskKey(task.ID)
 	if err != nil {
@@ -1031,6 +1282,9 @@ func (s *Service) findRuns(ctx context.Context, tx Tx, filter influxdb.RunFilter
 		return nil, 0, err
 	}
 	for _, run := range manualRuns {
+		if !filter.MatchStatistics(run) {
+			continue
+		}
 		runs = append(runs, run)
 		if len(runs) >= filter.Limit {
 			return runs, len(runs), nil
@@ -1043,6 +1297,9 @@ func (s *Service) findRuns(ctx context.Context, tx Tx, filter influxdb.RunFilter
 		return nil, 0, err
 	}
 	for _, run := range currentlyRunning {
+		if !filter.MatchStatistics(run) {
+			continue
+		}
 		runs = append(runs, run)
 		if len(runs) >= filter.Limit {
 			return runs, len(runs), nil
@@ -1291,12 +1548,18 @@ func (s *Service) createRun(ctx context.Context, tx Tx, taskID influxdb.ID, sche
 	id := s.IDGenerator.ID()
 	t := time.Unix(scheduledFor.Unix(), 0).UTC()
 
+	version, err := s.currentTaskVersion(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
 	run := influxdb.Run{
 		ID:           id,
 		TaskID:       taskID,
 		ScheduledFor: t,
 		RunAt:        runAt,
 		Status:       backend.RunScheduled.String(),
+		TaskVersion:  version,
 		Log:          []influxdb.Log{},
 	}
 
@@ -1455,6 +1718,11 @@ func (s *Service) startManualRun(ctx context.Context, tx Tx, taskID, runID influ
 		return nil, influxdb.ErrRunNotFound
 	}
 
+	// a manual run executes the script of the task at the time it starts.
+	if run.TaskVersion, err = s.currentTaskVersion(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
 	// save manual runs
 	mRunsBytes, err := json.Marshal(mRuns)
 	if err != nil {
@@ -1514,6 +1782,14 @@ func (s *Service) finishRun(ctx context.Context, tx Tx, taskID, runID influxdb.I
 		return nil, err
 	}
 
+	// the failed runs of the task are read in the transaction that counts
+	// the run, so that every finished run sees its own count.
+	t, err := s.findTaskByID(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	r.FailedRuns = t.FailedRuns
+
 	// tell task to update latest completed
 	scheduled := r.ScheduledFor
 	_, err = s.updateTask(ctx, tx, taskID, influxdb.TaskUpdate{
@@ -1646,6 +1922,48 @@ func (s *Service) addRunLog(ctx context.Context, tx Tx, taskID, runID influxdb.I
 	return nil
 }
 
+// UpdateRunStatistics sets what the query of the run read and wrote.
+func (s *Service) UpdateRunStatistics(ctx context.Context, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		err := s.updateRunStatistics(ctx, tx, taskID, runID, stats)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *Service) updateRunStatistics(ctx context.Context, tx Tx, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error {
+	// find run
+	run, err := s.findRunByID(ctx, tx, taskID, runID)
+	if err != nil {
+		return err
+	}
+	run.Statistics = stats
+	// save run
+	b, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	runBytes, err := json.Marshal(run)
+	if err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+
+	runKey, err := taskRunKey(taskID, run.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(runKey, runBytes); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	return nil
+}
+
 func taskKey(taskID influxdb.ID) ([]byte, error) {
 	encodedID, err := taskID.Encode()
 	if err != nil {
@@ -1683,6 +2001,106 @@ func taskOrgKey(orgID, taskID influxdb.ID) ([]byte, error) {
 	return []byte(string(encodedOrgID) + "/" + string(encodedID)), nil
 }
 
+func taskDependencyKey(upstreamID, taskID influxdb.ID) ([]byte, error) {
+	encodedUpstreamID, err := upstreamID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+	encodedID, err := taskID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+
+	return []byte(string(encodedUpstreamID) + "/" + string(encodedID)), nil
+}
+
+// putTaskDependencyIndex indexes the task id by each of the upstream tasks it depends on.
+func (s *Service) putTaskDependencyIndex(ctx context.Context, tx Tx, id influxdb.ID, upstream []influxdb.ID) error {
+	if len(upstream) == 0 {
+		return nil
+	}
+
+	indexBucket, err := tx.Bucket(taskDependencyIndexBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	taskKey, err := taskKey(id)
+	if err != nil {
+		return err
+	}
+	for _, up := range upstream {
+		key, err := taskDependencyKey(up, id)
+		if err != nil {
+			return err
+		}
+		if err := indexBucket.Put(key, taskKey); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
+// deleteTaskDependencyIndex removes the task id from the index of the upstream tasks it depends on.
+func (s *Service) deleteTaskDependencyIndex(ctx context.Context, tx Tx, id influxdb.ID, upstream []influxdb.ID) error {
+	if len(upstream) == 0 {
+		return nil
+	}
+
+	indexBucket, err := tx.Bucket(taskDependencyIndexBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	for _, up := range upstream {
+		key, err := taskDependencyKey(up, id)
+		if err != nil {
+			return err
+		}
+		if err := indexBucket.Delete(key); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
+// deleteDownstreamTaskIndex removes the index of the tasks that depend on the task id.
+func (s *Service) deleteDownstreamTaskIndex(ctx context.Context, tx Tx, id influxdb.ID) error {
+	indexBucket, err := tx.Bucket(taskDependencyIndexBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	prefix, err := id.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidTaskID
+	}
+	prefix = append(prefix, '/')
+
+	c, err := indexBucket.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	var keys [][]byte
+	for k, _ := c.Next(); k != nil; k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.Close(); err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := indexBucket.Delete(k); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
 func taskRunKey(taskID, runID influxdb.ID) ([]byte, error) {
 	encodedID, err := taskID.Encode()
 	if err != nil {
@@ -0,0 +1,231 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.TaskBackfillService = (*Service)(nil)
+
+func newBackfillStore() *StoreBase {
+	const resource = "backfill"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var b influxdb.Backfill
+		return key, &b, json.Unmarshal(val, &b)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		b, ok := v.(*influxdb.Backfill)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncID(b.ID), Body: b}, nil
+	}
+
+	return NewStoreBase(resource, []byte("taskbackfillsv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// FindBackfillByID returns a single backfill.
+func (s *Service) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Backfill
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		b, err = s.findBackfillByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Service) findBackfillByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Backfill, error) {
+	v, err := s.backfillStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, influxdb.ErrBackfillNotFound
+		}
+		return nil, err
+	}
+	return v.(*influxdb.Backfill), nil
+}
+
+// FindBackfills returns the backfills that match a filter.
+func (s *Service) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var backfills []*influxdb.Backfill
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		backfills, err = s.findBackfills(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return backfills, nil
+}
+
+func (s *Service) findBackfills(ctx context.Context, tx Tx, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	backfills := []*influxdb.Backfill{}
+	err := s.backfillStore.Find(ctx, tx, FindOpts{
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			b, ok := v.(*influxdb.Backfill)
+			if !ok {
+				return false
+			}
+			if filter.TaskID != nil && b.TaskID != *filter.TaskID {
+				return false
+			}
+			return filter.Status == nil || b.Status == *filter.Status
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			backfills = append(backfills, v.(*influxdb.Backfill))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return backfills, nil
+}
+
+// CreateBackfill records a running backfill of a task. The runs of the
+// backfill are enqueued by the task system, which records its progress with
+// UpdateBackfill.
+func (s *Service) CreateBackfill(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := bc.Valid(); err != nil {
+		return nil, err
+	}
+
+	var b *influxdb.Backfill
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		t, err := s.findTaskByID(ctx, tx, bc.TaskID)
+		if err != nil {
+			return err
+		}
+		if bc.OrgID.Valid() && bc.OrgID != t.OrganizationID {
+			return influxdb.ErrTaskNotFound
+		}
+
+		now := s.Now()
+		b = &influxdb.Backfill{
+			ID:          s.IDGenerator.ID(),
+			TaskID:      t.ID,
+			OrgID:       t.OrganizationID,
+			Start:       bc.Start.UTC(),
+			Stop:        bc.Stop.UTC(),
+			Concurrency: bc.Concurrency,
+			Status:      influxdb.BackfillStatusRunning,
+			Cursor:      bc.Start.UTC(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if b.Concurrency == 0 {
+			b.Concurrency = influxdb.DefaultBackfillConcurrency
+		}
+
+		return s.backfillStore.Put(ctx, tx, Entity{PK: EncID(b.ID), Body: b}, PutNew())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UpdateBackfill records the progress of a backfill.
+func (s *Service) UpdateBackfill(ctx context.Context, id influxdb.ID, upd influxdb.BackfillUpdate) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Backfill
+	err := s.kv.Update(ctx, func(tx Tx) (err error) {
+		b, err = s.updateBackfill(ctx, tx, id, upd)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Service) updateBackfill(ctx context.Context, tx Tx, id influxdb.ID, upd influxdb.BackfillUpdate) (*influxdb.Backfill, error) {
+	b, err := s.findBackfillByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// a canceled backfill keeps its status, while its executing runs finish.
+	if upd.Status != nil && b.Status != influxdb.BackfillStatusCanceled {
+		b.Status = *upd.Status
+	}
+	if upd.Total != nil {
+		b.Total = *upd.Total
+	}
+	if upd.Completed != nil {
+		b.Completed = *upd.Completed
+	}
+	if upd.Failed != nil {
+		b.Failed = *upd.Failed
+	}
+	if upd.Cursor != nil {
+		b.Cursor = upd.Cursor.UTC()
+	}
+	b.UpdatedAt = s.Now()
+
+	if err := s.backfillStore.Put(ctx, tx, Entity{PK: EncID(b.ID), Body: b}, PutUpdate()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// CancelBackfill sets the status of a running backfill to canceled.
+func (s *Service) CancelBackfill(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Backfill
+	err := s.kv.Update(ctx, func(tx Tx) (err error) {
+		b, err = s.findBackfillByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if b.Status != influxdb.BackfillStatusRunning {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "backfill is not running",
+			}
+		}
+
+		b.Status = influxdb.BackfillStatusCanceled
+		b.UpdatedAt = s.Now()
+		return s.backfillStore.Put(ctx, tx, Entity{PK: EncID(b.ID), Body: b}, PutUpdate())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// deleteTaskBackfills deletes the backfills of a task.
+func (s *Service) deleteTaskBackfills(ctx context.Context, tx Tx, taskID influxdb.ID) error {
+	backfills, err := s.findBackfills(ctx, tx, influxdb.BackfillFilter{TaskID: &taskID})
+	if err != nil {
+		return err
+	}
+	for _, b := range backfills {
+		if err := s.backfillStore.DeleteEnt(ctx, tx, Entity{PK: EncID(b.ID)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltTaskBackfillService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	testTaskBackfillService(t, s)
+}
+
+func TestInmemTaskBackfillService(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	testTaskBackfillService(t, s)
+}
+
+func testTaskBackfillService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task backfill service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+		Flux:           `option task = {name: "a task", every: 1h} from(bucket: "b") |> range(start: -1h) |> to(bucket: "c", orgID: "0000000000000000")`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := svc.CreateBackfill(ctx, influxdb.BackfillCreate{TaskID: task.ID, Start: start, Stop: start.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.OrgID != org.ID || b.Status != influxdb.BackfillStatusRunning || b.Concurrency != influxdb.DefaultBackfillConcurrency {
+		t.Errorf("unexpected backfill: %+v", b)
+	}
+	if !b.Cursor.Equal(start) {
+		t.Errorf("unexpected cursor: got %s want %s", b.Cursor, start)
+	}
+
+	if _, err := svc.CreateBackfill(ctx, influxdb.BackfillCreate{TaskID: task.ID, OrgID: influxdb.ID(1), Start: start, Stop: start}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found creating a backfill of another org's task, got %v", err)
+	}
+
+	completed, cursor := 3, start.Add(3*time.Hour)
+	if _, err := svc.UpdateBackfill(ctx, b.ID, influxdb.BackfillUpdate{Completed: &completed, Cursor: &cursor}); err != nil {
+		t.Fatal(err)
+	}
+
+	status := influxdb.BackfillStatusRunning
+	found, err := svc.FindBackfills(ctx, influxdb.BackfillFilter{TaskID: &task.ID, Status: &status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Completed != 3 || !found[0].Cursor.Equal(cursor) {
+		t.Fatalf("unexpected backfills: %+v", found)
+	}
+
+	if _, err := svc.CancelBackfill(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CancelBackfill(ctx, b.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected conflict canceling a canceled backfill, got %v", err)
+	}
+
+	// A canceled backfill stays canceled while its executing runs finish.
+	status = influxdb.BackfillStatusCompleted
+	b, err = svc.UpdateBackfill(ctx, b.ID, influxdb.BackfillUpdate{Status: &status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != influxdb.BackfillStatusCanceled {
+		t.Errorf("unexpected status: got %s want %s", b.Status, influxdb.BackfillStatusCanceled)
+	}
+
+	// Deleting the task removes its backfills.
+	if err := svc.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindBackfillByID(ctx, b.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected backfill to be deleted with its task, got %v", err)
+	}
+}
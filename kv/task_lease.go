@@ -0,0 +1,216 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.TaskLeaseService = (*Service)(nil)
+
+func newTaskNodeStore() *StoreBase {
+	const resource = "task node"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var n influxdb.TaskNode
+		return key, &n, json.Unmarshal(val, &n)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		n, ok := v.(*influxdb.TaskNode)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: EncString(n.ID), Body: n}, nil
+	}
+
+	return NewStoreBase(resource, []byte("tasknodesv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+func newTaskPartitionLeaseStore() *StoreBase {
+	const resource = "task partition lease"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var l influxdb.TaskPartitionLease
+		return key, &l, json.Unmarshal(val, &l)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		l, ok := v.(*influxdb.TaskPartitionLease)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: taskPartitionKey(l.Partition), Body: l}, nil
+	}
+
+	return NewStoreBase(resource, []byte("taskpartitionleasesv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// taskPartitionKey encodes the key of the lease of a partition, so that
+// leases are ordered by partition.
+func taskPartitionKey(partition int) EncodeFn {
+	return func() ([]byte, error) {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(partition))
+		return b, nil
+	}
+}
+
+// HeartbeatTaskNode records that a node is alive for ttl, forgets the nodes
+// that missed their heartbeat, and returns the nodes that are alive.
+func (s *Service) HeartbeatTaskNode(ctx context.Context, nodeID string, ttl time.Duration) ([]*influxdb.TaskNode, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	now := s.Now().UTC()
+	var nodes []*influxdb.TaskNode
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		n := &influxdb.TaskNode{
+			ID:          nodeID,
+			HeartbeatAt: now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		if err := s.taskNodeStore.Put(ctx, tx, Entity{PK: EncString(n.ID), Body: n}); err != nil {
+			return err
+		}
+
+		err := s.taskNodeStore.Delete(ctx, tx, DeleteOpts{
+			FilterFn: func(k []byte, v interface{}) bool {
+				n, ok := v.(*influxdb.TaskNode)
+				return ok && !n.ExpiresAt.After(now)
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		nodes = []*influxdb.TaskNode{}
+		return s.taskNodeStore.Find(ctx, tx, FindOpts{
+			CaptureFn: func(k []byte, v interface{}) error {
+				nodes = append(nodes, v.(*influxdb.TaskNode))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// DeleteTaskNode forgets a node that stopped scheduling tasks.
+func (s *Service) DeleteTaskNode(ctx context.Context, nodeID string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		err := s.taskNodeStore.DeleteEnt(ctx, tx, Entity{PK: EncString(nodeID)})
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		return nil
+	})
+}
+
+// FindTaskPartitionLeases returns the leases of all partitions, ordered by partition.
+func (s *Service) FindTaskPartitionLeases(ctx context.Context) ([]*influxdb.TaskPartitionLease, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	leases := []*influxdb.TaskPartitionLease{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.taskPartitionLeaseStore.Find(ctx, tx, FindOpts{
+			CaptureFn: func(k []byte, v interface{}) error {
+				leases = append(leases, v.(*influxdb.TaskPartitionLease))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+// ClaimTaskPartition leases a partition to a node for ttl, unless another
+// node holds a lease on it that has not expired.
+func (s *Service) ClaimTaskPartition(ctx context.Context, nodeID string, partition int, ttl time.Duration) (*influxdb.TaskPartitionLease, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	now := s.Now().UTC()
+	l := &influxdb.TaskPartitionLease{
+		Partition: partition,
+		NodeID:    nodeID,
+		RenewedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		cur, err := s.findTaskPartitionLease(ctx, tx, partition)
+		if err != nil {
+			return err
+		}
+		if cur != nil && cur.NodeID != nodeID && !cur.Expired(now) {
+			return influxdb.ErrTaskPartitionLeased
+		}
+		return s.taskPartitionLeaseStore.Put(ctx, tx, Entity{PK: taskPartitionKey(partition), Body: l})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ReleaseTaskPartition deletes the lease of a node on a partition. Releasing a
+// partition that the node no longer holds the lease of does nothing.
+func (s *Service) ReleaseTaskPartition(ctx context.Context, nodeID string, partition int) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		cur, err := s.findTaskPartitionLease(ctx, tx, partition)
+		if err != nil {
+			return err
+		}
+		if cur == nil || cur.NodeID != nodeID {
+			return nil
+		}
+		return s.taskPartitionLeaseStore.DeleteEnt(ctx, tx, Entity{PK: taskPartitionKey(partition)})
+	})
+}
+
+// ClaimTaskRun advances the latest scheduled time of a task to scheduledFor,
+// unless the task was already scheduled or completed for scheduledFor or later.
+func (s *Service) ClaimTaskRun(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		t, err := s.findTaskByID(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+		if !scheduledFor.After(t.LatestScheduled) || !scheduledFor.After(t.LatestCompleted) {
+			return influxdb.ErrTaskRunClaimed
+		}
+		_, err = s.updateTask(ctx, tx, taskID, influxdb.TaskUpdate{LatestScheduled: &scheduledFor})
+		return err
+	})
+}
+
+// findTaskPartitionLease returns the lease of a partition, or nil if the
+// partition has never been claimed or was released.
+func (s *Service) findTaskPartitionLease(ctx context.Context, tx Tx, partition int) (*influxdb.TaskPartitionLease, error) {
+	v, err := s.taskPartitionLeaseStore.FindEnt(ctx, tx, Entity{PK: taskPartitionKey(partition)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return v.(*influxdb.TaskPartitionLease), nil
+}
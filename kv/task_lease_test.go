@@ -0,0 +1,148 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestInmemTaskLeaseService(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	ctx := context.Background()
+	tg := &mock.TimeGenerator{FakeValue: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	svc.TimeGenerator = tg
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task lease service: %v", err)
+	}
+
+	if _, err := svc.HeartbeatTaskNode(ctx, "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	tg.FakeValue = tg.FakeValue.Add(30 * time.Second)
+	nodes, err := svc.HeartbeatTaskNode(ctx, "b", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 live nodes, got %d", len(nodes))
+	}
+
+	if _, err := svc.ClaimTaskPartition(ctx, "a", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ClaimTaskPartition(ctx, "b", 1, time.Minute); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected a conflict claiming a partition leased by another node, got %v", err)
+	}
+	// the node that holds a lease renews it by claiming it again.
+	if _, err := svc.ClaimTaskPartition(ctx, "a", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// releasing the lease of another node does nothing.
+	if err := svc.ReleaseTaskPartition(ctx, "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	leases, err := svc.FindTaskPartitionLeases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases) != 1 || leases[0].NodeID != "a" {
+		t.Fatalf("expected partition 1 to be leased by a, got %+v", leases)
+	}
+
+	// a misses its heartbeat, and its lease expires.
+	tg.FakeValue = tg.FakeValue.Add(time.Minute)
+	nodes, err = svc.HeartbeatTaskNode(ctx, "b", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].ID != "b" {
+		t.Fatalf("expected only b to be alive, got %+v", nodes)
+	}
+	if _, err := svc.ClaimTaskPartition(ctx, "b", 1, time.Minute); err != nil {
+		t.Fatalf("expected b to claim the expired lease of a: %v", err)
+	}
+
+	if err := svc.ReleaseTaskPartition(ctx, "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	if leases, err = svc.FindTaskPartitionLeases(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(leases) != 0 {
+		t.Fatalf("expected the released lease to be deleted, got %+v", leases)
+	}
+
+	if err := svc.DeleteTaskNode(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if nodes, err = svc.HeartbeatTaskNode(ctx, "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].ID != "a" {
+		t.Fatalf("expected the deleted node to be forgotten, got %+v", nodes)
+	}
+}
+
+func TestInmemTaskLeaseService_ClaimTaskRun(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task lease service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	authCtx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+	task, err := svc.CreateTask(authCtx, influxdb.TaskCreate{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+		Flux:           `option task = {name: "task", every: 1h} from(bucket: "b") |> range(start: -1h) |> to(bucket: "c", orgID: "0000000000000000")`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduledFor := task.LatestScheduled.Add(time.Hour)
+	if err := svc.ClaimTaskRun(ctx, task.ID, scheduledFor); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ClaimTaskRun(ctx, task.ID, scheduledFor); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected a conflict claiming a run twice, got %v", err)
+	}
+	if err := svc.ClaimTaskRun(ctx, task.ID, task.LatestScheduled); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected a conflict claiming a run before the checkpoint, got %v", err)
+	}
+
+	task, err = svc.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.LatestScheduled.Equal(scheduledFor) {
+		t.Fatalf("expected the claimed run to checkpoint the task at %s, got %s", scheduledFor, task.LatestScheduled)
+	}
+}
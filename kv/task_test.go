@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification/endpoint"
 	_ "github.com/influxdata/influxdb/query/builtin"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/servicetest"
@@ -239,6 +242,196 @@ func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	}
 }
 
+func TestService_TaskDependencies(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	createTask := func(name string, deps ...influxdb.ID) (*influxdb.Task, error) {
+		return ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+			Flux:           taskScript(name, deps...),
+			OrganizationID: ts.Org.ID,
+			OwnerID:        ts.User.ID,
+		})
+	}
+
+	raw, err := createTask("raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollup, err := createTask("rollup", raw.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollup.DependsOn) != 1 || rollup.DependsOn[0] != raw.ID {
+		t.Fatalf("expected rollup to depend on %s, got %v", raw.ID, rollup.DependsOn)
+	}
+
+	found, err := ts.Service.FindTaskByID(ctx, rollup.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(found.DependsOn, rollup.DependsOn) {
+		t.Fatalf("unexpected dependencies -got/+exp\n%s", cmp.Diff(found.DependsOn, rollup.DependsOn))
+	}
+
+	t.Run("unknown task", func(t *testing.T) {
+		if _, err := createTask("unknown", influxdb.ID(1234)); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		flux := taskScript("raw", rollup.ID)
+		if _, err := ts.Service.UpdateTask(ctx, raw.ID, influxdb.TaskUpdate{Flux: &flux}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+
+		flux = taskScript("raw", raw.ID)
+		if _, err := ts.Service.UpdateTask(ctx, raw.ID, influxdb.TaskUpdate{Flux: &flux}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected invalid error depending on itself, got %v", err)
+		}
+	})
+
+	t.Run("downstream", func(t *testing.T) {
+		downstream := func(id influxdb.ID) []*influxdb.Task {
+			t.Helper()
+			tasks, _, err := ts.Service.FindTasks(ctx, influxdb.TaskFilter{DependsOn: &id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tasks
+		}
+
+		if tasks := downstream(raw.ID); len(tasks) != 1 || tasks[0].ID != rollup.ID {
+			t.Fatalf("expected rollup to depend on raw, got %v", tasks)
+		}
+		if tasks := downstream(rollup.ID); len(tasks) != 0 {
+			t.Fatalf("expected no task to depend on rollup, got %v", tasks)
+		}
+
+		other, err := createTask("other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		flux := taskScript("rollup", other.ID)
+		if _, err := ts.Service.UpdateTask(ctx, rollup.ID, influxdb.TaskUpdate{Flux: &flux}); err != nil {
+			t.Fatal(err)
+		}
+		if tasks := downstream(raw.ID); len(tasks) != 0 {
+			t.Fatalf("expected no task to depend on raw, got %v", tasks)
+		}
+		if tasks := downstream(other.ID); len(tasks) != 1 || tasks[0].ID != rollup.ID {
+			t.Fatalf("expected rollup to depend on other, got %v", tasks)
+		}
+
+		if err := ts.Service.DeleteTask(ctx, rollup.ID); err != nil {
+			t.Fatal(err)
+		}
+		if tasks := downstream(other.ID); len(tasks) != 0 {
+			t.Fatalf("expected no task to depend on other, got %v", tasks)
+		}
+	})
+}
+
+func TestService_TaskNotification(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	e := &endpoint.Slack{
+		Base: endpoint.Base{Name: "on-call", OrgID: &ts.Org.ID, Status: influxdb.Active},
+		URL:  "http://localhost:7777",
+	}
+	if err := ts.Service.CreateNotificationEndpoint(ctx, e, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	n := &influxdb.TaskNotification{EndpointID: *e.ID, Policy: influxdb.TaskNotifyConsecutiveFailures, Failures: 2}
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           taskScript("notified"),
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Notification:   n,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(task.Notification, n) {
+		t.Fatalf("unexpected notification -got/+exp\n%s", cmp.Diff(task.Notification, n))
+	}
+
+	t.Run("unknown endpoint", func(t *testing.T) {
+		upd := influxdb.TaskUpdate{Notification: &influxdb.TaskNotification{EndpointID: 1234, Policy: influxdb.TaskNotifyEveryFailure}}
+		if _, err := ts.Service.UpdateTask(ctx, task.ID, upd); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("failed runs", func(t *testing.T) {
+		finish := func(status backend.RunStatus, failedBefore int) *influxdb.Task {
+			t.Helper()
+			run, err := ts.Service.CreateRun(ctx, task.ID, time.Now(), time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ts.Service.UpdateRunState(ctx, task.ID, run.ID, time.Now(), status); err != nil {
+				t.Fatal(err)
+			}
+			finished, err := ts.Service.FinishRun(ctx, task.ID, run.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if finished.FailedRuns != failedBefore {
+				t.Fatalf("expected the finished run to follow %d failed runs, got %d", failedBefore, finished.FailedRuns)
+			}
+			found, err := ts.Service.FindTaskByID(ctx, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return found
+		}
+
+		finish(backend.RunFail, 0)
+		if found := finish(backend.RunFail, 1); found.FailedRuns != 2 {
+			t.Fatalf("expected 2 failed runs, got %d", found.FailedRuns)
+		}
+		if found := finish(backend.RunSuccess, 2); found.FailedRuns != 0 {
+			t.Fatalf("expected a successful run to reset the failed runs, got %d", found.FailedRuns)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		updated, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Notification: &influxdb.TaskNotification{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Notification != nil {
+			t.Fatalf("expected the notification to be removed, got %+v", updated.Notification)
+		}
+	})
+}
+
+func taskScript(name string, deps ...influxdb.ID) string {
+	dependsOn := ""
+	if len(deps) > 0 {
+		ids := make([]string, 0, len(deps))
+		for _, id := range deps {
+			ids = append(ids, fmt.Sprintf("%q", id))
+		}
+		dependsOn = fmt.Sprintf(", dependsOn: [%s]", strings.Join(ids, ", "))
+	}
+	return fmt.Sprintf(`option task = {name: %q, every: 1h%s} from(bucket:"test") |> range(start:-1h)`, name, dependsOn)
+}
+
 func TestTaskRunCancellation(t *testing.T) {
 	store, close, err := NewTestBoltStore(t)
 	if err != nil {
@@ -0,0 +1,163 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.TaskVersionService = (*Service)(nil)
+
+func newTaskVersionStore() *StoreBase {
+	const resource = "task version"
+
+	var decEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var v influxdb.TaskVersion
+		return key, &v, json.Unmarshal(val, &v)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, v interface{}) (Entity, error) {
+		tv, ok := v.(*influxdb.TaskVersion)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return Entity{PK: taskVersionKey(tv.TaskID, tv.Version), Body: tv}, nil
+	}
+
+	return NewStoreBase(resource, []byte("taskversionsv1"), EncIDKey, EncBodyJSON, decEntFn, decValToEntFn)
+}
+
+// taskVersionKey encodes the key of a version of a task. The versions of a
+// task share the task ID as a prefix and are ordered by version.
+func taskVersionKey(taskID influxdb.ID, version int) EncodeFn {
+	return Encode(EncID(taskID), func() ([]byte, error) {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(version))
+		return b, nil
+	})
+}
+
+// FindTaskVersion returns a single version of a task.
+func (s *Service) FindTaskVersion(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var v *influxdb.TaskVersion
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		v, err = s.findTaskVersion(ctx, tx, taskID, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *Service) findTaskVersion(ctx context.Context, tx Tx, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+	v, err := s.taskVersionStore.FindEnt(ctx, tx, Entity{PK: taskVersionKey(taskID, version)})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, influxdb.ErrTaskVersionNotFound
+		}
+		return nil, err
+	}
+	return v.(*influxdb.TaskVersion), nil
+}
+
+// FindTaskVersions returns the versions of a task, oldest first.
+func (s *Service) FindTaskVersions(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var versions []*influxdb.TaskVersion
+	err := s.kv.View(ctx, func(tx Tx) (err error) {
+		versions, err = s.findTaskVersions(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *Service) findTaskVersions(ctx context.Context, tx Tx, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+	prefix, err := EncID(filter.TaskID)()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+
+	versions := []*influxdb.TaskVersion{}
+	err = s.taskVersionStore.Find(ctx, tx, FindOpts{
+		Prefix: prefix,
+		Limit:  filter.Limit,
+		FilterEntFn: func(k []byte, v interface{}) bool {
+			tv, ok := v.(*influxdb.TaskVersion)
+			return ok && tv.Version > filter.After
+		},
+		CaptureFn: func(k []byte, v interface{}) error {
+			versions = append(versions, v.(*influxdb.TaskVersion))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// createTaskVersion records the script and options of t as its next version,
+// and sets the version of t to it.
+func (s *Service) createTaskVersion(ctx context.Context, tx Tx, t *influxdb.Task, authorID influxdb.ID) error {
+	v := &influxdb.TaskVersion{
+		TaskID:         t.ID,
+		OrganizationID: t.OrganizationID,
+		Version:        t.Version + 1,
+		Flux:           t.Flux,
+		Name:           t.Name,
+		Every:          t.Every,
+		Cron:           t.Cron,
+		Offset:         t.Offset,
+		Location:       t.Location,
+		AuthorID:       authorID,
+		CreatedAt:      t.UpdatedAt,
+	}
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = t.CreatedAt
+	}
+
+	if err := s.taskVersionStore.Put(ctx, tx, Entity{PK: taskVersionKey(v.TaskID, v.Version), Body: v}, PutNew()); err != nil {
+		return err
+	}
+	t.Version = v.Version
+	return nil
+}
+
+// currentTaskVersion returns the version of the script of a task, or 0 if the
+// task no longer exists.
+func (s *Service) currentTaskVersion(ctx context.Context, tx Tx, taskID influxdb.ID) (int, error) {
+	t, err := s.findTaskByID(ctx, tx, taskID)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return t.Version, nil
+}
+
+// deleteTaskVersions deletes the versions of a task.
+func (s *Service) deleteTaskVersions(ctx context.Context, tx Tx, taskID influxdb.ID) error {
+	versions, err := s.findTaskVersions(ctx, tx, influxdb.TaskVersionFilter{TaskID: taskID})
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if err := s.taskVersionStore.DeleteEnt(ctx, tx, Entity{PK: taskVersionKey(v.TaskID, v.Version)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltTaskVersionService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	testTaskVersionService(t, s)
+}
+
+func TestInmemTaskVersionService(t *testing.T) {
+	s, closeInmem, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeInmem()
+
+	testTaskVersionService(t, s)
+}
+
+func testTaskVersionService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing task version service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	const (
+		fluxV1 = `option task = {name: "a task", every: 1h} from(bucket: "b") |> range(start: -1h) |> to(bucket: "c", orgID: "0000000000000000")`
+		fluxV2 = `option task = {name: "a task", every: 2h} from(bucket: "b") |> range(start: -2h) |> to(bucket: "c", orgID: "0000000000000000")`
+	)
+
+	task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+		Flux:           fluxV1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Version != 1 {
+		t.Fatalf("expected a created task to be at version 1, got %d", task.Version)
+	}
+
+	v, err := svc.FindTaskVersion(ctx, task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Flux != fluxV1 || v.Every != "1h" || v.AuthorID != user.ID || v.OrganizationID != org.ID {
+		t.Errorf("unexpected first version: %+v", v)
+	}
+
+	run, err := svc.CreateRun(ctx, task.ID, time.Unix(3600, 0), time.Unix(3600, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.TaskVersion != 1 {
+		t.Errorf("expected run to reference version 1, got %d", run.TaskVersion)
+	}
+
+	// updates that leave the script unchanged don't create versions.
+	description := "described"
+	if task, err = svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Description: &description}); err != nil {
+		t.Fatal(err)
+	}
+	if task.Version != 1 {
+		t.Errorf("expected a description update to keep version 1, got %d", task.Version)
+	}
+
+	flux := fluxV2
+	if task, err = svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	}
+	if task.Version != 2 {
+		t.Fatalf("expected a script update to create version 2, got %d", task.Version)
+	}
+
+	run, err = svc.CreateRun(ctx, task.ID, time.Unix(7200, 0), time.Unix(7200, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.TaskVersion != 2 {
+		t.Errorf("expected run to reference version 2, got %d", run.TaskVersion)
+	}
+
+	// rolling back updates the task with the script of the earlier version.
+	if task, err = svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &v.Flux}); err != nil {
+		t.Fatal(err)
+	}
+	if task.Version != 3 || task.Flux != fluxV1 || task.Every != "1h" {
+		t.Errorf("unexpected task after rollback: %+v", task)
+	}
+
+	versions, err := svc.FindTaskVersions(ctx, influxdb.TaskVersionFilter{TaskID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	for i, want := range []string{fluxV1, fluxV2, fluxV1} {
+		if versions[i].Version != i+1 || versions[i].Flux != want {
+			t.Errorf("unexpected version %d: %+v", i+1, versions[i])
+		}
+	}
+
+	versions, err = svc.FindTaskVersions(ctx, influxdb.TaskVersionFilter{TaskID: task.ID, After: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Version != 2 {
+		t.Errorf("expected only version 2 after version 1, got %+v", versions)
+	}
+
+	if _, err := svc.FindTaskVersion(ctx, task.ID, 4); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found for a missing version, got %v", err)
+	}
+
+	if err := svc.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	versions, err = svc.FindTaskVersions(ctx, influxdb.TaskVersionFilter{TaskID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("expected the versions of a deleted task to be deleted, got %d", len(versions))
+	}
+}
@@ -0,0 +1,235 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// SchemaType determines how the data written to a bucket is validated.
+type SchemaType string
+
+const (
+	// SchemaTypeImplicit accepts any measurement, tag and field, and is the
+	// default for buckets that do not specify a schema type.
+	SchemaTypeImplicit SchemaType = "implicit"
+	// SchemaTypeExplicit only accepts points whose measurement has a
+	// MeasurementSchema and whose tags and fields match its columns.
+	SchemaTypeExplicit SchemaType = "explicit"
+)
+
+// Valid returns an error if the schema type is unknown. The empty schema
+// type is valid and equivalent to SchemaTypeImplicit.
+func (t SchemaType) Valid() error {
+	switch t {
+	case "", SchemaTypeImplicit, SchemaTypeExplicit:
+		return nil
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid schema type %q, must be %q or %q", t, SchemaTypeImplicit, SchemaTypeExplicit),
+		}
+	}
+}
+
+// SemanticColumnType is the role a column plays in a measurement schema.
+type SemanticColumnType string
+
+const (
+	SemanticColumnTypeTimestamp SemanticColumnType = "timestamp"
+	SemanticColumnTypeTag       SemanticColumnType = "tag"
+	SemanticColumnTypeField     SemanticColumnType = "field"
+)
+
+// SchemaColumnDataType is the data type of a field column.
+type SchemaColumnDataType string
+
+const (
+	SchemaColumnDataTypeFloat    SchemaColumnDataType = "float"
+	SchemaColumnDataTypeInteger  SchemaColumnDataType = "integer"
+	SchemaColumnDataTypeUnsigned SchemaColumnDataType = "unsigned"
+	SchemaColumnDataTypeString   SchemaColumnDataType = "string"
+	SchemaColumnDataTypeBoolean  SchemaColumnDataType = "boolean"
+)
+
+// Valid returns an error if the data type is unknown.
+func (t SchemaColumnDataType) Valid() error {
+	switch t {
+	case SchemaColumnDataTypeFloat, SchemaColumnDataTypeInteger, SchemaColumnDataTypeUnsigned,
+		SchemaColumnDataTypeString, SchemaColumnDataTypeBoolean:
+		return nil
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid column data type %q", t),
+		}
+	}
+}
+
+// ops for measurement schema errors.
+var (
+	OpFindMeasurementSchemaByID = "FindMeasurementSchemaByID"
+	OpFindMeasurementSchemas    = "FindMeasurementSchemas"
+	OpCreateMeasurementSchema   = "CreateMeasurementSchema"
+	OpUpdateMeasurementSchema   = "UpdateMeasurementSchema"
+	OpDeleteMeasurementSchema   = "DeleteMeasurementSchema"
+)
+
+// ErrMeasurementSchemaNotFound is returned when a measurement schema does not exist.
+var ErrMeasurementSchemaNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "measurement schema not found",
+}
+
+// MeasurementSchemaService manages the measurement schemas of buckets.
+type MeasurementSchemaService interface {
+	// FindMeasurementSchemaByID returns a single measurement schema by ID.
+	FindMeasurementSchemaByID(ctx context.Context, id ID) (*MeasurementSchema, error)
+
+	// FindMeasurementSchemas returns the measurement schemas that match filter.
+	FindMeasurementSchemas(ctx context.Context, filter MeasurementSchemaFilter) ([]*MeasurementSchema, error)
+
+	// CreateMeasurementSchema creates a new measurement schema and sets ms.ID with the new identifier.
+	CreateMeasurementSchema(ctx context.Context, ms *MeasurementSchema) error
+
+	// UpdateMeasurementSchema replaces the columns of a measurement schema.
+	// Existing columns may not be removed or changed.
+	UpdateMeasurementSchema(ctx context.Context, id ID, upd MeasurementSchemaUpdate) (*MeasurementSchema, error)
+
+	// DeleteMeasurementSchema removes a measurement schema by ID.
+	DeleteMeasurementSchema(ctx context.Context, id ID) error
+}
+
+// MeasurementSchema declares the columns of a measurement in a bucket with an
+// explicit schema.
+type MeasurementSchema struct {
+	ID       ID                        `json:"id,omitempty"`
+	OrgID    ID                        `json:"orgID"`
+	BucketID ID                        `json:"bucketID"`
+	Name     string                    `json:"name"`
+	Columns  []MeasurementSchemaColumn `json:"columns"`
+	CRUDLog
+}
+
+// MeasurementSchemaColumn is a column of a measurement schema. DataType is
+// only set for field columns.
+type MeasurementSchemaColumn struct {
+	Name     string               `json:"name"`
+	Type     SemanticColumnType   `json:"type"`
+	DataType SchemaColumnDataType `json:"dataType,omitempty"`
+}
+
+// MeasurementSchemaFilter restricts the measurement schemas returned by
+// FindMeasurementSchemas.
+type MeasurementSchemaFilter struct {
+	BucketID ID
+	Name     *string
+}
+
+// MeasurementSchemaUpdate is the new set of columns of a measurement schema.
+type MeasurementSchemaUpdate struct {
+	Columns []MeasurementSchemaColumn `json:"columns"`
+}
+
+// Valid returns an error if the measurement schema is malformed.
+func (m *MeasurementSchema) Valid() error {
+	if m.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema name is required",
+		}
+	}
+	return validColumns(m.Columns)
+}
+
+// Column returns the column with the given name.
+func (m *MeasurementSchema) Column(name string) (MeasurementSchemaColumn, bool) {
+	for _, c := range m.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return MeasurementSchemaColumn{}, false
+}
+
+// Apply updates the columns of the measurement schema. Columns can only be
+// added; removing or changing an existing column is an error because data may
+// already have been written with it.
+func (m *MeasurementSchema) Apply(upd MeasurementSchemaUpdate) error {
+	if err := validColumns(upd.Columns); err != nil {
+		return err
+	}
+
+	for _, c := range m.Columns {
+		nc, ok := (&MeasurementSchema{Columns: upd.Columns}).Column(c.Name)
+		if !ok {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  fmt.Sprintf("column %q cannot be removed from measurement schema %q", c.Name, m.Name),
+			}
+		}
+		if nc != c {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  fmt.Sprintf("column %q of measurement schema %q cannot be changed", c.Name, m.Name),
+			}
+		}
+	}
+
+	m.Columns = upd.Columns
+	return nil
+}
+
+// validColumns checks that the columns contain a single timestamp column named
+// "time", at least one field, and no duplicate names.
+func validColumns(columns []MeasurementSchemaColumn) error {
+	var (
+		seen             = make(map[string]bool, len(columns))
+		timeN, fieldN    int
+		invalidColumnErr = func(format string, args ...interface{}) error {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf(format, args...),
+			}
+		}
+	)
+
+	for _, c := range columns {
+		if c.Name == "" {
+			return invalidColumnErr("column name is required")
+		}
+		if seen[c.Name] {
+			return invalidColumnErr("duplicate column %q", c.Name)
+		}
+		seen[c.Name] = true
+
+		switch c.Type {
+		case SemanticColumnTypeTimestamp:
+			if c.Name != "time" {
+				return invalidColumnErr("timestamp column must be named %q", "time")
+			}
+			if c.DataType != "" {
+				return invalidColumnErr("timestamp column %q cannot have a data type", c.Name)
+			}
+			timeN++
+		case SemanticColumnTypeTag:
+			if c.DataType != "" {
+				return invalidColumnErr("tag column %q cannot have a data type", c.Name)
+			}
+		case SemanticColumnTypeField:
+			if err := c.DataType.Valid(); err != nil {
+				return invalidColumnErr("field column %q has invalid data type %q", c.Name, c.DataType)
+			}
+			fieldN++
+		default:
+			return invalidColumnErr("column %q has invalid type %q", c.Name, c.Type)
+		}
+	}
+
+	if timeN != 1 {
+		return invalidColumnErr("measurement schema requires exactly one timestamp column")
+	}
+	if fieldN == 0 {
+		return invalidColumnErr("measurement schema requires at least one field column")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestMeasurementSchema_Valid(t *testing.T) {
+	var (
+		timeCol  = influxdb.MeasurementSchemaColumn{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp}
+		hostCol  = influxdb.MeasurementSchemaColumn{Name: "host", Type: influxdb.SemanticColumnTypeTag}
+		usageCol = influxdb.MeasurementSchemaColumn{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat}
+	)
+
+	tests := []struct {
+		name    string
+		columns []influxdb.MeasurementSchemaColumn
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			columns: []influxdb.MeasurementSchemaColumn{timeCol, hostCol, usageCol},
+		},
+		{
+			name:    "missing timestamp",
+			columns: []influxdb.MeasurementSchemaColumn{hostCol, usageCol},
+			wantErr: true,
+		},
+		{
+			name:    "missing field",
+			columns: []influxdb.MeasurementSchemaColumn{timeCol, hostCol},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate column",
+			columns: []influxdb.MeasurementSchemaColumn{timeCol, usageCol, usageCol},
+			wantErr: true,
+		},
+		{
+			name: "field without data type",
+			columns: []influxdb.MeasurementSchemaColumn{timeCol,
+				{Name: "usage", Type: influxdb.SemanticColumnTypeField}},
+			wantErr: true,
+		},
+		{
+			name: "tag with data type",
+			columns: []influxdb.MeasurementSchemaColumn{timeCol, usageCol,
+				{Name: "host", Type: influxdb.SemanticColumnTypeTag, DataType: influxdb.SchemaColumnDataTypeString}},
+			wantErr: true,
+		},
+		{
+			name: "timestamp not named time",
+			columns: []influxdb.MeasurementSchemaColumn{usageCol,
+				{Name: "ts", Type: influxdb.SemanticColumnTypeTimestamp}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &influxdb.MeasurementSchema{BucketID: 1, Name: "cpu", Columns: tt.columns}
+			if err := ms.Valid(); (err != nil) != tt.wantErr {
+				t.Errorf("Valid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
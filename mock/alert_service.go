@@ -0,0 +1,86 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AlertService = &AlertService{}
+var _ influxdb.AlertAcknowledgementService = &AlertAcknowledgementService{}
+
+// AlertService is a mock alert service.
+type AlertService struct {
+	FindAlertStatesF  func(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error)
+	FindAlertHistoryF func(ctx context.Context, filter influxdb.AlertFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error)
+}
+
+// NewAlertService returns a mock AlertService where its methods will return
+// zero values.
+func NewAlertService() *AlertService {
+	return &AlertService{
+		FindAlertStatesF: func(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+			return nil, 0, nil
+		},
+		FindAlertHistoryF: func(ctx context.Context, filter influxdb.AlertFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+// FindAlertStates calls FindAlertStatesF.
+func (s *AlertService) FindAlertStates(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+	return s.FindAlertStatesF(ctx, filter)
+}
+
+// FindAlertHistory calls FindAlertHistoryF.
+func (s *AlertService) FindAlertHistory(ctx context.Context, filter influxdb.AlertFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error) {
+	return s.FindAlertHistoryF(ctx, filter, opt...)
+}
+
+// AlertAcknowledgementService is a mock alert acknowledgement service.
+type AlertAcknowledgementService struct {
+	FindAlertAcknowledgementByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error)
+	FindAlertAcknowledgementsF    func(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error)
+	CreateAlertAcknowledgementF   func(ctx context.Context, a *influxdb.AlertAcknowledgement) error
+	DeleteAlertAcknowledgementF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewAlertAcknowledgementService returns a mock AlertAcknowledgementService
+// where its methods will return zero values.
+func NewAlertAcknowledgementService() *AlertAcknowledgementService {
+	return &AlertAcknowledgementService{
+		FindAlertAcknowledgementByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+			return nil, nil
+		},
+		FindAlertAcknowledgementsF: func(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+			return nil, 0, nil
+		},
+		CreateAlertAcknowledgementF: func(ctx context.Context, a *influxdb.AlertAcknowledgement) error {
+			return nil
+		},
+		DeleteAlertAcknowledgementF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindAlertAcknowledgementByID calls FindAlertAcknowledgementByIDF.
+func (s *AlertAcknowledgementService) FindAlertAcknowledgementByID(ctx context.Context, id influxdb.ID) (*influxdb.AlertAcknowledgement, error) {
+	return s.FindAlertAcknowledgementByIDF(ctx, id)
+}
+
+// FindAlertAcknowledgements calls FindAlertAcknowledgementsF.
+func (s *AlertAcknowledgementService) FindAlertAcknowledgements(ctx context.Context, filter influxdb.AlertAcknowledgementFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+	return s.FindAlertAcknowledgementsF(ctx, filter, opt...)
+}
+
+// CreateAlertAcknowledgement calls CreateAlertAcknowledgementF.
+func (s *AlertAcknowledgementService) CreateAlertAcknowledgement(ctx context.Context, a *influxdb.AlertAcknowledgement) error {
+	return s.CreateAlertAcknowledgementF(ctx, a)
+}
+
+// DeleteAlertAcknowledgement calls DeleteAlertAcknowledgementF.
+func (s *AlertAcknowledgementService) DeleteAlertAcknowledgement(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteAlertAcknowledgementF(ctx, id)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CardinalityService = &CardinalityService{}
+
+// CardinalityService is a mock cardinality service.
+type CardinalityService struct {
+	BucketCardinalityF func(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error)
+}
+
+// NewCardinalityService returns a mock CardinalityService where its methods will return
+// zero values.
+func NewCardinalityService() *CardinalityService {
+	return &CardinalityService{
+		BucketCardinalityF: func(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error) {
+			return &influxdb.BucketCardinality{BucketID: bucketID}, nil
+		},
+	}
+}
+
+// BucketCardinality calls BucketCardinalityF.
+func (s *CardinalityService) BucketCardinality(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error) {
+	return s.BucketCardinalityF(ctx, orgID, bucketID, opt)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CheckPreviewService = &CheckPreviewService{}
+
+// CheckPreviewService is a mock check preview service.
+type CheckPreviewService struct {
+	PreviewCheckF         func(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error)
+	FindCheckPreviewByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error)
+}
+
+// NewCheckPreviewService returns a mock CheckPreviewService where its methods
+// will return zero values.
+func NewCheckPreviewService() *CheckPreviewService {
+	return &CheckPreviewService{
+		PreviewCheckF: func(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+			return nil, nil
+		},
+		FindCheckPreviewByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+			return nil, nil
+		},
+	}
+}
+
+// PreviewCheck calls PreviewCheckF.
+func (s *CheckPreviewService) PreviewCheck(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+	return s.PreviewCheckF(ctx, p)
+}
+
+// FindCheckPreviewByID calls FindCheckPreviewByIDF.
+func (s *CheckPreviewService) FindCheckPreviewByID(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+	return s.FindCheckPreviewByIDF(ctx, id)
+}
@@ -0,0 +1,104 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.EscalationPolicyService = &EscalationPolicyService{}
+var _ influxdb.EscalationService = &EscalationService{}
+
+// EscalationPolicyService is a mock escalation policy service.
+type EscalationPolicyService struct {
+	FindEscalationPolicyByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error)
+	FindEscalationPoliciesF   func(ctx context.Context, filter influxdb.EscalationPolicyFilter, opt ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error)
+	CreateEscalationPolicyF   func(ctx context.Context, p *influxdb.EscalationPolicy) error
+	UpdateEscalationPolicyF   func(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error)
+	DeleteEscalationPolicyF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewEscalationPolicyService returns a mock EscalationPolicyService where its
+// methods will return zero values.
+func NewEscalationPolicyService() *EscalationPolicyService {
+	return &EscalationPolicyService{
+		FindEscalationPolicyByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+			return nil, nil
+		},
+		FindEscalationPoliciesF: func(ctx context.Context, filter influxdb.EscalationPolicyFilter, opt ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error) {
+			return nil, 0, nil
+		},
+		CreateEscalationPolicyF: func(ctx context.Context, p *influxdb.EscalationPolicy) error {
+			return nil
+		},
+		UpdateEscalationPolicyF: func(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error) {
+			return nil, nil
+		},
+		DeleteEscalationPolicyF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindEscalationPolicyByID calls FindEscalationPolicyByIDF.
+func (s *EscalationPolicyService) FindEscalationPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+	return s.FindEscalationPolicyByIDF(ctx, id)
+}
+
+// FindEscalationPolicies calls FindEscalationPoliciesF.
+func (s *EscalationPolicyService) FindEscalationPolicies(ctx context.Context, filter influxdb.EscalationPolicyFilter, opt ...influxdb.FindOptions) ([]*influxdb.EscalationPolicy, int, error) {
+	return s.FindEscalationPoliciesF(ctx, filter, opt...)
+}
+
+// CreateEscalationPolicy calls CreateEscalationPolicyF.
+func (s *EscalationPolicyService) CreateEscalationPolicy(ctx context.Context, p *influxdb.EscalationPolicy) error {
+	return s.CreateEscalationPolicyF(ctx, p)
+}
+
+// UpdateEscalationPolicy calls UpdateEscalationPolicyF.
+func (s *EscalationPolicyService) UpdateEscalationPolicy(ctx context.Context, id influxdb.ID, upd influxdb.EscalationPolicyUpdate) (*influxdb.EscalationPolicy, error) {
+	return s.UpdateEscalationPolicyF(ctx, id, upd)
+}
+
+// DeleteEscalationPolicy calls DeleteEscalationPolicyF.
+func (s *EscalationPolicyService) DeleteEscalationPolicy(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteEscalationPolicyF(ctx, id)
+}
+
+// EscalationService is a mock escalation service.
+type EscalationService struct {
+	FindEscalationsF  func(ctx context.Context, ruleID influxdb.ID) ([]*influxdb.Escalation, error)
+	PutEscalationF    func(ctx context.Context, e *influxdb.Escalation) error
+	DeleteEscalationF func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewEscalationService returns a mock EscalationService where its methods
+// will return zero values.
+func NewEscalationService() *EscalationService {
+	return &EscalationService{
+		FindEscalationsF: func(ctx context.Context, ruleID influxdb.ID) ([]*influxdb.Escalation, error) {
+			return nil, nil
+		},
+		PutEscalationF: func(ctx context.Context, e *influxdb.Escalation) error {
+			return nil
+		},
+		DeleteEscalationF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindEscalations calls FindEscalationsF.
+func (s *EscalationService) FindEscalations(ctx context.Context, ruleID influxdb.ID) ([]*influxdb.Escalation, error) {
+	return s.FindEscalationsF(ctx, ruleID)
+}
+
+// PutEscalation calls PutEscalationF.
+func (s *EscalationService) PutEscalation(ctx context.Context, e *influxdb.Escalation) error {
+	return s.PutEscalationF(ctx, e)
+}
+
+// DeleteEscalation calls DeleteEscalationF.
+func (s *EscalationService) DeleteEscalation(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteEscalationF(ctx, id)
+}
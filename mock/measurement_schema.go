@@ -0,0 +1,65 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MeasurementSchemaService = &MeasurementSchemaService{}
+
+// MeasurementSchemaService is a mock measurement schema service.
+type MeasurementSchemaService struct {
+	FindMeasurementSchemaByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error)
+	FindMeasurementSchemasF    func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error)
+	CreateMeasurementSchemaF   func(ctx context.Context, ms *influxdb.MeasurementSchema) error
+	UpdateMeasurementSchemaF   func(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error)
+	DeleteMeasurementSchemaF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewMeasurementSchemaService returns a mock MeasurementSchemaService where its methods will return
+// zero values.
+func NewMeasurementSchemaService() *MeasurementSchemaService {
+	return &MeasurementSchemaService{
+		FindMeasurementSchemaByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+			return nil, nil
+		},
+		FindMeasurementSchemasF: func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+			return nil, nil
+		},
+		CreateMeasurementSchemaF: func(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+			return nil
+		},
+		UpdateMeasurementSchemaF: func(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+			return nil, nil
+		},
+		DeleteMeasurementSchemaF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindMeasurementSchemaByID calls FindMeasurementSchemaByIDF.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	return s.FindMeasurementSchemaByIDF(ctx, id)
+}
+
+// FindMeasurementSchemas calls FindMeasurementSchemasF.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	return s.FindMeasurementSchemasF(ctx, filter)
+}
+
+// CreateMeasurementSchema calls CreateMeasurementSchemaF.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	return s.CreateMeasurementSchemaF(ctx, ms)
+}
+
+// UpdateMeasurementSchema calls UpdateMeasurementSchemaF.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	return s.UpdateMeasurementSchemaF(ctx, id, upd)
+}
+
+// DeleteMeasurementSchema calls DeleteMeasurementSchemaF.
+func (s *MeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteMeasurementSchemaF(ctx, id)
+}
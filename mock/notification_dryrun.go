@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.NotificationEndpointTestService = &NotificationEndpointTestService{}
+var _ influxdb.NotificationRuleDryRunService = &NotificationRuleDryRunService{}
+
+// NotificationEndpointTestService is a mock notification endpoint test service.
+type NotificationEndpointTestService struct {
+	TestNotificationEndpointF func(ctx context.Context, id influxdb.ID, t influxdb.NotificationEndpointTest) error
+}
+
+// NewNotificationEndpointTestService returns a mock NotificationEndpointTestService
+// where its methods will return zero values.
+func NewNotificationEndpointTestService() *NotificationEndpointTestService {
+	return &NotificationEndpointTestService{
+		TestNotificationEndpointF: func(ctx context.Context, id influxdb.ID, t influxdb.NotificationEndpointTest) error {
+			return nil
+		},
+	}
+}
+
+// TestNotificationEndpoint calls TestNotificationEndpointF.
+func (s *NotificationEndpointTestService) TestNotificationEndpoint(ctx context.Context, id influxdb.ID, t influxdb.NotificationEndpointTest) error {
+	return s.TestNotificationEndpointF(ctx, id, t)
+}
+
+// NotificationRuleDryRunService is a mock notification rule dry run service.
+type NotificationRuleDryRunService struct {
+	DryRunNotificationRuleF func(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error)
+}
+
+// NewNotificationRuleDryRunService returns a mock NotificationRuleDryRunService
+// where its methods will return zero values.
+func NewNotificationRuleDryRunService() *NotificationRuleDryRunService {
+	return &NotificationRuleDryRunService{
+		DryRunNotificationRuleF: func(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error) {
+			return nil, nil
+		},
+	}
+}
+
+// DryRunNotificationRule calls DryRunNotificationRuleF.
+func (s *NotificationRuleDryRunService) DryRunNotificationRule(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error) {
+	return s.DryRunNotificationRuleF(ctx, id)
+}
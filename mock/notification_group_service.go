@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.NotificationGroupService = &NotificationGroupService{}
+
+// NotificationGroupService is a mock notification group service.
+type NotificationGroupService struct {
+	FindNotificationGroupsF func(ctx context.Context, ruleID influxdb.ID) (*influxdb.NotificationGroups, error)
+	PutNotificationGroupsF  func(ctx context.Context, g *influxdb.NotificationGroups) error
+}
+
+// NewNotificationGroupService returns a mock NotificationGroupService where
+// its methods will return zero values.
+func NewNotificationGroupService() *NotificationGroupService {
+	return &NotificationGroupService{
+		FindNotificationGroupsF: func(ctx context.Context, ruleID influxdb.ID) (*influxdb.NotificationGroups, error) {
+			return &influxdb.NotificationGroups{RuleID: ruleID}, nil
+		},
+		PutNotificationGroupsF: func(ctx context.Context, g *influxdb.NotificationGroups) error {
+			return nil
+		},
+	}
+}
+
+// FindNotificationGroups calls FindNotificationGroupsF.
+func (s *NotificationGroupService) FindNotificationGroups(ctx context.Context, ruleID influxdb.ID) (*influxdb.NotificationGroups, error) {
+	return s.FindNotificationGroupsF(ctx, ruleID)
+}
+
+// PutNotificationGroups calls PutNotificationGroupsF.
+func (s *NotificationGroupService) PutNotificationGroups(ctx context.Context, g *influxdb.NotificationGroups) error {
+	return s.PutNotificationGroupsF(ctx, g)
+}
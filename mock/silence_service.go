@@ -0,0 +1,65 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SilenceService = &SilenceService{}
+
+// SilenceService is a mock silence service.
+type SilenceService struct {
+	FindSilenceByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.Silence, error)
+	FindSilencesF    func(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error)
+	CreateSilenceF   func(ctx context.Context, s *influxdb.Silence) error
+	UpdateSilenceF   func(ctx context.Context, id influxdb.ID, upd influxdb.SilenceUpdate) (*influxdb.Silence, error)
+	DeleteSilenceF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewSilenceService returns a mock SilenceService where its methods will
+// return zero values.
+func NewSilenceService() *SilenceService {
+	return &SilenceService{
+		FindSilenceByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.Silence, error) {
+			return nil, nil
+		},
+		FindSilencesF: func(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+			return nil, 0, nil
+		},
+		CreateSilenceF: func(ctx context.Context, s *influxdb.Silence) error {
+			return nil
+		},
+		UpdateSilenceF: func(ctx context.Context, id influxdb.ID, upd influxdb.SilenceUpdate) (*influxdb.Silence, error) {
+			return nil, nil
+		},
+		DeleteSilenceF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindSilenceByID calls FindSilenceByIDF.
+func (s *SilenceService) FindSilenceByID(ctx context.Context, id influxdb.ID) (*influxdb.Silence, error) {
+	return s.FindSilenceByIDF(ctx, id)
+}
+
+// FindSilences calls FindSilencesF.
+func (s *SilenceService) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	return s.FindSilencesF(ctx, filter, opt...)
+}
+
+// CreateSilence calls CreateSilenceF.
+func (s *SilenceService) CreateSilence(ctx context.Context, sl *influxdb.Silence) error {
+	return s.CreateSilenceF(ctx, sl)
+}
+
+// UpdateSilence calls UpdateSilenceF.
+func (s *SilenceService) UpdateSilence(ctx context.Context, id influxdb.ID, upd influxdb.SilenceUpdate) (*influxdb.Silence, error) {
+	return s.UpdateSilenceF(ctx, id, upd)
+}
+
+// DeleteSilence calls DeleteSilenceF.
+func (s *SilenceService) DeleteSilence(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteSilenceF(ctx, id)
+}
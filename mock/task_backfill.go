@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskBackfillService = &TaskBackfillService{}
+
+// TaskBackfillService is a mock task backfill service.
+type TaskBackfillService struct {
+	FindBackfillByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error)
+	FindBackfillsF    func(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error)
+	CreateBackfillF   func(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error)
+	CancelBackfillF   func(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error)
+}
+
+// NewTaskBackfillService returns a mock TaskBackfillService where its methods will return
+// zero values.
+func NewTaskBackfillService() *TaskBackfillService {
+	return &TaskBackfillService{
+		FindBackfillByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		FindBackfillsF: func(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		CreateBackfillF: func(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		CancelBackfillF: func(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindBackfillByID calls FindBackfillByIDF.
+func (s *TaskBackfillService) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.FindBackfillByIDF(ctx, id)
+}
+
+// FindBackfills calls FindBackfillsF.
+func (s *TaskBackfillService) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	return s.FindBackfillsF(ctx, filter)
+}
+
+// CreateBackfill calls CreateBackfillF.
+func (s *TaskBackfillService) CreateBackfill(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	return s.CreateBackfillF(ctx, bc)
+}
+
+// CancelBackfill calls CancelBackfillF.
+func (s *TaskBackfillService) CancelBackfill(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.CancelBackfillF(ctx, id)
+}
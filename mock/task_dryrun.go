@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskDryRunService = &TaskDryRunService{}
+
+// TaskDryRunService is a mock task dry run service.
+type TaskDryRunService struct {
+	DryRunTaskF func(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error)
+}
+
+// NewTaskDryRunService returns a mock TaskDryRunService where its methods will return
+// zero values.
+func NewTaskDryRunService() *TaskDryRunService {
+	return &TaskDryRunService{
+		DryRunTaskF: func(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error) {
+			return nil, nil
+		},
+	}
+}
+
+// DryRunTask calls DryRunTaskF.
+func (s *TaskDryRunService) DryRunTask(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error) {
+	return s.DryRunTaskF(ctx, d)
+}
@@ -137,6 +137,8 @@ type TaskControlService struct {
 	FinishRunFn        func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
 	UpdateRunStateFn   func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state backend.RunStatus) error
 	AddRunLogFn        func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
+
+	UpdateRunStatisticsFn func(ctx context.Context, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error
 }
 
 func (tcs *TaskControlService) CreateRun(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error) {
@@ -160,3 +162,6 @@ func (tcs *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID
 func (tcs *TaskControlService) AddRunLog(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error {
 	return tcs.AddRunLogFn(ctx, taskID, runID, when, log)
 }
+func (tcs *TaskControlService) UpdateRunStatistics(ctx context.Context, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error {
+	return tcs.UpdateRunStatisticsFn(ctx, taskID, runID, stats)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskVersionService = &TaskVersionService{}
+
+// TaskVersionService is a mock task version service.
+type TaskVersionService struct {
+	FindTaskVersionF  func(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error)
+	FindTaskVersionsF func(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error)
+}
+
+// NewTaskVersionService returns a mock TaskVersionService where its methods will return
+// zero values.
+func NewTaskVersionService() *TaskVersionService {
+	return &TaskVersionService{
+		FindTaskVersionF: func(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+			return nil, nil
+		},
+		FindTaskVersionsF: func(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindTaskVersion calls FindTaskVersionF.
+func (s *TaskVersionService) FindTaskVersion(ctx context.Context, taskID influxdb.ID, version int) (*influxdb.TaskVersion, error) {
+	return s.FindTaskVersionF(ctx, taskID, version)
+}
+
+// FindTaskVersions calls FindTaskVersionsF.
+func (s *TaskVersionService) FindTaskVersions(ctx context.Context, filter influxdb.TaskVersionFilter) ([]*influxdb.TaskVersion, error) {
+	return s.FindTaskVersionsF(ctx, filter)
+}
@@ -75,6 +75,15 @@ func WithParserStats(s *ParserStats) ParserOption {
 	}
 }
 
+// WithParserLineNumbers specifies that lines will contain the 1-based line number
+// of the source buffer from which each parsed point was read. The line numbers are
+// in the same order as the returned points.
+func WithParserLineNumbers(lines *[]int) ParserOption {
+	return func(pp *pointsParser) {
+		pp.lines = lines
+	}
+}
+
 type parserState int
 
 const (
@@ -94,6 +103,7 @@ type pointsParser struct {
 	points      []Point
 	state       parserState
 	stats       *ParserStats
+	lines       *[]int
 }
 
 func newPointsParser(orgBucket []byte, opts ...ParserOption) *pointsParser {
@@ -130,8 +140,18 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 		pos    int
 		block  []byte
 		failed []string
+
+		line, lineOff = 1, 0
 	)
+	if pp.lines != nil {
+		*pp.lines = make([]int, 0, lineCount+1)
+	}
 	for pos < len(buf) && pp.state == parserStateOK {
+		if pp.lines != nil {
+			line += bytes.Count(buf[lineOff:pos], []byte{'\n'})
+			lineOff = pos
+		}
+
 		pos, block = scanLine(buf, pos)
 		pos++
 
@@ -156,7 +176,13 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 			block = block[:len(block)-1]
 		}
 
+		n := len(pp.points)
 		err = pp.parsePointsAppend(block[start:])
+		if pp.lines != nil {
+			for range pp.points[n:] {
+				*pp.lines = append(*pp.lines, line)
+			}
+		}
 		if err != nil {
 			if errors.Is(err, errLimit) {
 				break
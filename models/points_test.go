@@ -2515,6 +2515,23 @@ func TestParsePointsWithOptions(t *testing.T) {
 	}
 }
 
+func TestParsePointsWithOptions_LineNumbers(t *testing.T) {
+	buf := []byte("# comment\ncpu value=1,other=2 1\n\nmem value=3,s=\"a\nb\" 1\ndisk value=4 1")
+
+	var lines []int
+	points, err := models.ParsePointsWithOptions(buf, []byte("m"), models.WithParserLineNumbers(&lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := len(lines), len(points); got != exp {
+		t.Fatalf("unexpected number of line numbers; got %d, exp %d", got, exp)
+	}
+	if exp := []int{2, 2, 4, 4, 6}; !cmp.Equal(lines, exp) {
+		t.Errorf("unexpected line numbers; -got/+exp\n%s", cmp.Diff(lines, exp))
+	}
+}
+
 func TestNewPointsWithBytesWithCorruptData(t *testing.T) {
 	corrupted := []byte{0, 0, 0, 3, 102, 111, 111, 0, 0, 0, 4, 61, 34, 65, 34, 1, 0, 0, 0, 14, 206, 86, 119, 24, 32, 72, 233, 168, 2, 148}
 	p, err := models.NewPointFromBytes(corrupted)
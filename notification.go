@@ -29,8 +29,21 @@ type NotificationRule interface {
 	GetTaskID() ID
 	GetEndpointID() ID
 	GetLimit() *Limit
+	// SetSilences sets the silences that the flux generated for the rule honours.
+	SetSilences(silences []*Silence)
 	GenerateFlux(NotificationEndpoint) (string, error)
+	// GenerateDryRunFlux generates a flux script that yields the statuses
+	// the rule would notify without sending them.
+	GenerateDryRunFlux() (string, error)
 	MatchesTags(tags []Tag) bool
+	// GetEscalationPolicyID returns the ID of the escalation policy of the rule, or nil.
+	GetEscalationPolicyID() *ID
+	// Escalates returns whether the rule escalates the alert of a series with tags at level.
+	Escalates(tags []Tag, level string) bool
+	// EscalatedAlerts returns the filter of the alerts that the rule may escalate.
+	EscalatedAlerts() AlertFilter
+	// GetGrouping returns the grouping of the notifications of the rule, or nil.
+	GetGrouping() *NotificationGrouping
 }
 
 // NotificationRuleStore represents a service for managing notification rule.
@@ -75,7 +88,9 @@ type Limit struct {
 type NotificationRuleFilter struct {
 	OrgID        *ID
 	Organization *string
-	Tags         []Tag
+	// TaskID matches the rule whose task has the ID.
+	TaskID *ID
+	Tags   []Tag
 	UserResourceMappingFilter
 }
 
@@ -91,6 +106,10 @@ func (f NotificationRuleFilter) QueryParams() map[string][]string {
 		qp["org"] = []string{*f.Organization}
 	}
 
+	if f.TaskID != nil {
+		qp["taskID"] = []string{f.TaskID.String()}
+	}
+
 	qp["tag"] = []string{}
 	for _, tp := range f.Tags {
 		qp["tag"] = append(qp["tag"], tp.QueryParam())
@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+var _ influxdb.NotificationRuleDryRunService = (*DryRunner)(nil)
+
+// DryRunner is an influxdb.NotificationRuleDryRunService that evaluates
+// notification rules against the statuses of the monitoring bucket of their
+// organization, with the read only access of the alert service.
+type DryRunner struct {
+	alerts   *Service
+	rules    influxdb.NotificationRuleStore
+	silences influxdb.SilenceService
+}
+
+// NewDryRunner creates a dry runner that evaluates the rules of rules, which
+// honour the silences of silences, with the queries of alerts.
+func NewDryRunner(alerts *Service, rules influxdb.NotificationRuleStore, silences influxdb.SilenceService) *DryRunner {
+	return &DryRunner{
+		alerts:   alerts,
+		rules:    rules,
+		silences: silences,
+	}
+}
+
+// DryRunNotificationRule evaluates the rule of id as a run of its task would
+// now, and returns the statuses it would have notified. Nothing is sent to the
+// endpoint of the rule, and nothing is written to the monitoring bucket.
+func (d *DryRunner) DryRunNotificationRule(ctx context.Context, id influxdb.ID) (*influxdb.NotificationRuleDryRunResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	nr, err := d.rules.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	orgID := nr.GetOrgID()
+	now := d.alerts.Now().UTC()
+
+	silences, _, err := d.silences.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	var active []*influxdb.Silence
+	for _, s := range silences {
+		if !s.Expired(now) {
+			active = append(active, s)
+		}
+	}
+	nr.SetSilences(active)
+
+	script, err := nr.GenerateDryRunFlux()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := d.alerts.bs.FindBucketByName(ctx, orgID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &influxdb.NotificationRuleDryRunResult{
+		RuleID:        id,
+		Time:          now,
+		Notifications: []*influxdb.NotificationRuleDryRunNotification{},
+	}
+	err = d.alerts.queryResults(ctx, b, script, func(r flux.Result) error {
+		name := r.Name()
+		return r.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					status, err := readStatus(cr, i)
+					if err != nil {
+						return err
+					}
+					res.Notifications = append(res.Notifications, &influxdb.NotificationRuleDryRunNotification{
+						AlertStatus: *status,
+						Sent:        name == rule.DryRunSentResult,
+						Silenced:    name == rule.DryRunSilencedResult,
+						Grouped:     name == rule.DryRunGroupedResult,
+					})
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpDryRunNotificationRule,
+			Err: err,
+		}
+	}
+
+	sort.SliceStable(res.Notifications, func(i, j int) bool {
+		return res.Notifications[i].Time.Before(res.Notifications[j].Time)
+	})
+	return res, nil
+}
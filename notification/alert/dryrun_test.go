@@ -0,0 +1,104 @@
+package alert_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/alert"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestDryRunner_DryRunNotificationRule(t *testing.T) {
+	// the dry run script yields the statuses to results named by what would
+	// have been done with them.
+	resultCSV := func(name string) string {
+		return strings.Replace(stateCSV, "#default,_result", "#default,"+name, 1)
+	}
+	results := resultCSV("sent") +
+		",,0,2020-01-01T00:50:00Z,a crit again," + checkIDs + ",cpu,threshold,crit,a\n" +
+		",,0,2020-01-01T00:20:00Z,a crit," + checkIDs + ",cpu,threshold,crit,a\n" +
+		"\n" + resultCSV("silenced") +
+		",,0,2020-01-01T00:30:00Z,b crit," + checkIDs + ",cpu,threshold,crit,b\n"
+
+	var scripts []string
+	s := newService(t, results, nil, &scripts)
+
+	every, err := parser.ParseDuration("1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nr := &rule.Slack{
+		Base: rule.Base{
+			ID:          30,
+			OrgID:       orgID,
+			Name:        "cpu rule",
+			Every:       (*notification.Duration)(every),
+			StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+		},
+		MessageTemplate: "msg",
+	}
+	rules := mock.NewNotificationRuleStore()
+	rules.FindNotificationRuleByIDF = func(_ context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
+		return nr, nil
+	}
+	silences := mock.NewSilenceService()
+	silences.FindSilencesF = func(_ context.Context, filter influxdb.SilenceFilter, _ ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+		if *filter.OrgID != orgID {
+			t.Fatalf("unexpected silences of org %s", filter.OrgID)
+		}
+		return []*influxdb.Silence{
+			{
+				TagRules: []influxdb.TagRule{{Tag: influxdb.Tag{Key: "host", Value: "b"}, Operator: influxdb.Equal}},
+				Start:    mustTime("2020-01-01T00:00:00Z"),
+				End:      mustTime("2020-01-02T00:00:00Z"),
+			},
+			{
+				Start: mustTime("2019-01-01T00:00:00Z"),
+				End:   mustTime("2019-01-02T00:00:00Z"),
+			},
+		}, 2, nil
+	}
+
+	res, err := alert.NewDryRunner(s, rules, silences).DryRunNotificationRule(context.Background(), nr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := func(tm, msg, host string) influxdb.AlertStatus {
+		return influxdb.AlertStatus{
+			Time:      mustTime(tm),
+			CheckID:   checkID,
+			CheckName: "cpu",
+			CheckType: "threshold",
+			Level:     "CRIT",
+			Message:   msg,
+			Tags:      []influxdb.Tag{{Key: "host", Value: host}},
+		}
+	}
+	expected := &influxdb.NotificationRuleDryRunResult{
+		RuleID: nr.ID,
+		Time:   mustTime(nowString),
+		Notifications: []*influxdb.NotificationRuleDryRunNotification{
+			{AlertStatus: status("2020-01-01T00:20:00Z", "a crit", "a"), Sent: true},
+			{AlertStatus: status("2020-01-01T00:30:00Z", "b crit", "b"), Silenced: true},
+			{AlertStatus: status("2020-01-01T00:50:00Z", "a crit again", "a"), Sent: true},
+		},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("unexpected dry run -want/+got:\n%s", diff)
+	}
+
+	script := scripts[0]
+	if !strings.Contains(script, `r.host == "b" and now() >= 2020-01-01T00:00:00Z`) || strings.Contains(script, "2019-01-01") {
+		t.Fatalf("expected the script to honour the active silence only, got:\n%s", script)
+	}
+	if strings.Contains(script, "monitor.notify") || strings.Contains(script, "slack") {
+		t.Fatalf("expected the script not to notify the endpoint, got:\n%s", script)
+	}
+}
@@ -0,0 +1,315 @@
+package alert
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/snowflake"
+	"github.com/influxdata/influxdb/task/options"
+)
+
+var _ influxdb.CheckPreviewService = (*Previewer)(nil)
+
+const (
+	// previewTimeout is how long a preview may run.
+	previewTimeout = 5 * time.Minute
+	// previewRetention is how long a preview is kept after it finished.
+	previewRetention = time.Hour
+	// maxRunningPreviews is the most previews that run at once.
+	maxRunningPreviews = 8
+)
+
+// Previewer is an influxdb.CheckPreviewService that evaluates checks with
+// queries of qs, with the authorizer of the context of the preview. Previews
+// run in the background, and are kept in memory until previewRetention after
+// they finished.
+type Previewer struct {
+	qs    query.QueryService
+	idGen influxdb.IDGenerator
+
+	mu       sync.Mutex
+	previews map[influxdb.ID]*preview
+	running  int
+
+	now func() time.Time
+}
+
+// preview is a preview that runs or ran on the previewer.
+type preview struct {
+	res        *influxdb.CheckPreviewResult
+	finishedAt time.Time
+}
+
+// NewPreviewer creates a check previewer that queries qs.
+func NewPreviewer(qs query.QueryService) *Previewer {
+	return &Previewer{
+		qs:       qs,
+		idGen:    snowflake.NewIDGenerator(),
+		previews: map[influxdb.ID]*preview{},
+		now:      time.Now,
+	}
+}
+
+// PreviewCheck starts to evaluate the check of p at each time aligned to the
+// every of the check between the start and stop of p, as its task would have
+// run, and returns the running preview. Once it succeeded, the preview has the
+// changes of level of each series. The statuses of the check are read from the
+// script instead of written to the monitoring bucket, and stateful checks read
+// the statuses of the earlier evaluations of the preview instead of the
+// monitoring bucket.
+func (pr *Previewer) PreviewCheck(ctx context.Context, p influxdb.CheckPreview) (*influxdb.CheckPreviewResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+
+	script, err := check.GeneratePreviewFlux(p.Check, nil)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpPreviewCheck,
+			Err:  err,
+		}
+	}
+
+	times, err := evaluationTimes(script, p.Start.UTC(), p.Stop.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &influxdb.CheckPreviewResult{
+		ID:          pr.idGen.ID(),
+		OrgID:       p.Check.GetOrgID(),
+		Status:      influxdb.CheckPreviewRunning,
+		Start:       p.Start.UTC(),
+		Stop:        p.Stop.UTC(),
+		Evaluations: len(times),
+	}
+
+	pr.mu.Lock()
+	pr.prune()
+	if pr.running >= maxRunningPreviews {
+		pr.mu.Unlock()
+		return nil, &influxdb.Error{
+			Code: influxdb.ETooManyRequests,
+			Op:   influxdb.OpPreviewCheck,
+			Msg:  "too many check previews are running, try again later",
+		}
+	}
+	pr.previews[res.ID] = &preview{res: res}
+	pr.running++
+	pr.mu.Unlock()
+
+	// the preview outlives the request that started it, so it only keeps its
+	// authorizer.
+	bctx := context.Background()
+	a, err := icontext.GetAuthorizer(ctx)
+	if err == nil {
+		bctx = icontext.SetAuthorizer(bctx, a)
+	}
+	go pr.run(bctx, a, *res, p.Check, times)
+
+	cp := *res
+	return &cp, nil
+}
+
+// FindCheckPreviewByID returns a preview that runs, or that finished less than
+// previewRetention ago.
+func (pr *Previewer) FindCheckPreviewByID(ctx context.Context, id influxdb.ID) (*influxdb.CheckPreviewResult, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.prune()
+	p, ok := pr.previews[id]
+	if !ok {
+		return nil, influxdb.ErrCheckPreviewNotFound
+	}
+	cp := *p.res
+	return &cp, nil
+}
+
+// run evaluates c at times, and records the outcome of the preview res.
+func (pr *Previewer) run(ctx context.Context, a influxdb.Authorizer, res influxdb.CheckPreviewResult, c influxdb.Check, times []time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+
+	auth, _ := a.(*influxdb.Authorization)
+	transitions, levels, err := pr.transitions(ctx, auth, c, times)
+	if err != nil {
+		res.Status = influxdb.CheckPreviewFailed
+		res.Error = err.Error()
+	} else {
+		res.Status = influxdb.CheckPreviewSuccess
+		res.Transitions = transitions
+		res.Levels = levels
+	}
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.previews[res.ID] = &preview{res: &res, finishedAt: pr.now()}
+	pr.running--
+}
+
+// transitions evaluates c at times, and returns the changes of level of each
+// series and the number of transitions to each level. Each evaluation reads
+// the statuses of the previous ones, as the evaluations of its task would
+// have read them from the monitoring bucket.
+func (pr *Previewer) transitions(ctx context.Context, auth *influxdb.Authorization, c influxdb.Check, times []time.Time) ([]*influxdb.CheckPreviewTransition, map[string]int, error) {
+	transitions := []*influxdb.CheckPreviewTransition{}
+	counts := map[string]int{}
+	levels := map[string]string{}
+	var previous []*influxdb.AlertStatus
+	for _, t := range times {
+		script, err := check.GeneratePreviewFlux(c, previous)
+		if err != nil {
+			return nil, nil, &influxdb.Error{
+				Op:  influxdb.OpPreviewCheck,
+				Err: err,
+			}
+		}
+		statuses, err := pr.evaluate(ctx, auth, c.GetOrgID(), script, t)
+		if err != nil {
+			return nil, nil, &influxdb.Error{
+				Op:  influxdb.OpPreviewCheck,
+				Err: err,
+			}
+		}
+		// the statuses are read back as written by the run of the task at t.
+		for _, s := range statuses {
+			s.Time = t
+		}
+		previous = append(previous, statuses...)
+
+		for _, s := range statuses {
+			key := seriesKey(s.Tags)
+			previous, ok := levels[key]
+			if ok && previous == s.Level {
+				continue
+			}
+			levels[key] = s.Level
+			counts[s.Level]++
+			transitions = append(transitions, &influxdb.CheckPreviewTransition{
+				Time:          t,
+				Tags:          s.Tags,
+				PreviousLevel: previous,
+				Level:         s.Level,
+				Message:       s.Message,
+			})
+		}
+	}
+	return transitions, counts, nil
+}
+
+// prune forgets the previews that finished more than previewRetention ago.
+// pr.mu must be held.
+func (pr *Previewer) prune() {
+	now := pr.now()
+	for id, p := range pr.previews {
+		if !p.finishedAt.IsZero() && now.Sub(p.finishedAt) > previewRetention {
+			delete(pr.previews, id)
+		}
+	}
+}
+
+// evaluate runs script as of now, and returns the last status of each series,
+// in the order the series were read. The task writes every status of a series
+// at now, so the last one is the status the series would have been left at.
+func (pr *Previewer) evaluate(ctx context.Context, auth *influxdb.Authorization, orgID influxdb.ID, script string, now time.Time) ([]*influxdb.AlertStatus, error) {
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: orgID,
+		Compiler:       lang.FluxCompiler{Now: now, Query: script},
+	}
+	itr, err := pr.qs.Query(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Release()
+
+	var statuses []*influxdb.AlertStatus
+	series := map[string]int{}
+	for itr.More() {
+		err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					s, err := readStatus(cr, i)
+					if err != nil {
+						return err
+					}
+					key := seriesKey(s.Tags)
+					if j, ok := series[key]; ok {
+						statuses[j] = s
+						continue
+					}
+					series[key] = len(statuses)
+					statuses = append(statuses, s)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return statuses, itr.Err()
+}
+
+// evaluationTimes returns the times between start and stop at which the task
+// of script would run.
+func evaluationTimes(script string, start, stop time.Time) ([]time.Time, error) {
+	opts, err := options.FromScript(script)
+	if err != nil {
+		return nil, influxdb.ErrTaskOptionParse(err)
+	}
+	if opts.Every.IsZero() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpPreviewCheck,
+			Msg:  "preview requires a check that runs every interval",
+		}
+	}
+	every, err := opts.Every.DurationFrom(start)
+	if err != nil {
+		return nil, err
+	}
+
+	first := start.Truncate(every)
+	if first.Before(start) {
+		first = first.Add(every)
+	}
+	if !first.After(stop) {
+		if n := int(stop.Sub(first)/every) + 1; n > influxdb.MaxCheckPreviewEvaluations {
+			return nil, influxdb.ErrCheckPreviewTooLong(n)
+		}
+	}
+
+	var times []time.Time
+	for t := first; !t.After(stop); t = t.Add(every) {
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+func seriesKey(tags []influxdb.Tag) string {
+	var b strings.Builder
+	for _, t := range tags {
+		b.WriteString(t.Key)
+		b.WriteByte('=')
+		b.WriteString(t.Value)
+		b.WriteByte(',')
+	}
+	return b.String()
+}
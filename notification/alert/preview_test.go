@@ -0,0 +1,217 @@
+package alert_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/alert"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/query"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	qmock "github.com/influxdata/influxdb/query/mock"
+)
+
+func TestPreviewer_PreviewCheck(t *testing.T) {
+	const previewCSV = "#datatype,string,long,dateTime:RFC3339,string,string,string\n#group,false,false,false,false,true,true\n#default,_result,,,,,\n,result,table,_time,_message,_level,host\n"
+
+	// the statuses of each evaluation, by the time of the evaluation.
+	evaluations := map[string]string{
+		"2020-01-01T01:00:00Z": ",,0,2020-01-01T01:00:00Z,a ok,ok,a\n,,1,2020-01-01T01:00:00Z,b ok,ok,b\n",
+		"2020-01-01T02:00:00Z": ",,0,2020-01-01T02:00:00Z,a ok,ok,a\n,,0,2020-01-01T02:00:00Z,a crit,crit,a\n,,1,2020-01-01T02:00:00Z,b ok,ok,b\n",
+		"2020-01-01T03:00:00Z": ",,0,2020-01-01T03:00:00Z,a crit,crit,a\n,,1,2020-01-01T03:00:00Z,b warn,warn,b\n",
+		"2020-01-01T04:00:00Z": ",,0,2020-01-01T04:00:00Z,a ok,ok,a\n,,1,2020-01-01T04:00:00Z,b warn,warn,b\n",
+	}
+
+	auth := &influxdb.Authorization{ID: 100, OrgID: orgID, Status: influxdb.Active}
+	var scripts []string
+	qs := &qmock.QueryService{
+		QueryF: func(_ context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.OrganizationID != orgID || req.Authorization != auth {
+				t.Errorf("expected the query to be authorized by the authorizer of the preview, got %+v", req.Authorization)
+			}
+			c := req.Compiler.(lang.FluxCompiler)
+			scripts = append(scripts, c.Query)
+			results, ok := evaluations[c.Now.UTC().Format("2006-01-02T15:04:05Z")]
+			if !ok {
+				return nil, fmt.Errorf("unexpected evaluation at %s", c.Now)
+			}
+			return csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(strings.NewReader(previewCSV + results)))
+		},
+	}
+
+	every, err := parser.ParseDuration("1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chk := &check.Threshold{
+		Base: check.Base{
+			Name:                  "cpu",
+			OrgID:                 orgID,
+			Every:                 (*notification.Duration)(every),
+			StatusMessageTemplate: "msg",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r._field == "usage_user")`,
+			},
+		},
+		Thresholds: []check.ThresholdConfig{
+			check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical},
+				Value:               90,
+			},
+		},
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), auth)
+	previewer := alert.NewPreviewer(qs)
+
+	started, err := previewer.PreviewCheck(ctx, influxdb.CheckPreview{
+		Check: chk,
+		Start: mustTime("2020-01-01T00:30:00Z"),
+		Stop:  mustTime("2020-01-01T04:00:00Z"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.Status != influxdb.CheckPreviewRunning || started.OrgID != orgID {
+		t.Fatalf("expected a running preview of the organization of the check, got %+v", started)
+	}
+	res := waitPreview(t, previewer, started.ID)
+
+	transition := func(tm, previous, level, msg, host string) *influxdb.CheckPreviewTransition {
+		return &influxdb.CheckPreviewTransition{
+			Time:          mustTime(tm),
+			Tags:          []influxdb.Tag{{Key: "host", Value: host}},
+			PreviousLevel: previous,
+			Level:         level,
+			Message:       msg,
+		}
+	}
+	expected := &influxdb.CheckPreviewResult{
+		ID:          started.ID,
+		OrgID:       orgID,
+		Status:      influxdb.CheckPreviewSuccess,
+		Start:       mustTime("2020-01-01T00:30:00Z"),
+		Stop:        mustTime("2020-01-01T04:00:00Z"),
+		Evaluations: 4,
+		Transitions: []*influxdb.CheckPreviewTransition{
+			transition("2020-01-01T01:00:00Z", "", "OK", "a ok", "a"),
+			transition("2020-01-01T01:00:00Z", "", "OK", "b ok", "b"),
+			transition("2020-01-01T02:00:00Z", "OK", "CRIT", "a crit", "a"),
+			transition("2020-01-01T03:00:00Z", "OK", "WARN", "b warn", "b"),
+			transition("2020-01-01T04:00:00Z", "CRIT", "OK", "a ok", "a"),
+		},
+		Levels: map[string]int{"OK": 3, "CRIT": 1, "WARN": 1},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("unexpected preview -want/+got:\n%s", diff)
+	}
+
+	if !strings.Contains(scripts[0], "option monitor.write = (tables=<-) =>") {
+		t.Fatalf("expected the script not to write the statuses, got:\n%s", scripts[0])
+	}
+
+	t.Run("too many evaluations", func(t *testing.T) {
+		_, err := previewer.PreviewCheck(ctx, influxdb.CheckPreview{
+			Check: chk,
+			Start: mustTime("2020-01-01T00:00:00Z"),
+			Stop:  mustTime("2020-06-01T00:00:00Z"),
+		})
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+	})
+
+	t.Run("stateful threshold", func(t *testing.T) {
+		exit := 80.0
+		stateful := *chk
+		stateful.Thresholds = []check.ThresholdConfig{
+			check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical},
+				Value:               90,
+				ExitValue:           &exit,
+			},
+		}
+
+		scripts = nil
+		started, err := previewer.PreviewCheck(ctx, influxdb.CheckPreview{
+			Check: &stateful,
+			Start: mustTime("2020-01-01T00:30:00Z"),
+			Stop:  mustTime("2020-01-01T04:00:00Z"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := waitPreview(t, previewer, started.ID); res.Status != influxdb.CheckPreviewSuccess {
+			t.Fatalf("expected the preview of a stateful check to succeed, got %+v", res)
+		}
+
+		// each evaluation reads the statuses of the earlier ones, at the
+		// times they ran.
+		if len(scripts) != 4 {
+			t.Fatalf("expected 4 evaluations, got %d", len(scripts))
+		}
+		if strings.Contains(scripts[0], "T01:00:00Z") {
+			t.Fatalf("expected the first evaluation to read no statuses, got:\n%s", scripts[0])
+		}
+		for _, row := range []string{"2020-01-01T01:00:00Z,,ok,a\n", "2020-01-01T02:00:00Z,,crit,a\n", "2020-01-01T03:00:00Z,,warn,b\n"} {
+			if !strings.Contains(scripts[3], row) {
+				t.Fatalf("expected the last evaluation to read the status %q, got:\n%s", row, scripts[3])
+			}
+		}
+	})
+
+	t.Run("failed query", func(t *testing.T) {
+		failing := alert.NewPreviewer(&qmock.QueryService{
+			QueryF: func(context.Context, *query.Request) (flux.ResultIterator, error) {
+				return nil, fmt.Errorf("query failed")
+			},
+		})
+		started, err := failing.PreviewCheck(ctx, influxdb.CheckPreview{
+			Check: chk,
+			Start: mustTime("2020-01-01T00:30:00Z"),
+			Stop:  mustTime("2020-01-01T04:00:00Z"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := waitPreview(t, failing, started.ID)
+		if res.Status != influxdb.CheckPreviewFailed || !strings.Contains(res.Error, "query failed") {
+			t.Fatalf("expected the preview to fail with the error of its query, got %+v", res)
+		}
+	})
+
+	t.Run("unknown preview", func(t *testing.T) {
+		_, err := previewer.FindCheckPreviewByID(ctx, influxdb.ID(1))
+		if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected a not found error, got %v", err)
+		}
+	})
+}
+
+// waitPreview waits for the preview id to finish, and returns it.
+func waitPreview(t *testing.T, previewer *alert.Previewer, id influxdb.ID) *influxdb.CheckPreviewResult {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		res, err := previewer.FindCheckPreviewByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != influxdb.CheckPreviewRunning {
+			return res
+		}
+	}
+	t.Fatalf("preview %s did not finish", id)
+	return nil
+}
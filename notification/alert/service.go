@@ -0,0 +1,368 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+)
+
+// columns of the statuses written by the monitor package.
+const (
+	timeColumn      = "_time"
+	valueColumn     = "_value"
+	messageColumn   = "_message"
+	levelColumn     = "_level"
+	checkIDColumn   = "_check_id"
+	checkNameColumn = "_check_name"
+	checkTypeColumn = "_type"
+)
+
+var _ influxdb.AlertService = (*Service)(nil)
+
+// Service is an influxdb.AlertService that queries the statuses that checks
+// write to the monitoring bucket of their organization.
+type Service struct {
+	bs influxdb.BucketService
+	as influxdb.AlertAcknowledgementService
+	qs query.QueryService
+
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewService creates an alert service that reads statuses with qs, and
+// attaches the acknowledgements of as to the alert states it finds.
+func NewService(bs influxdb.BucketService, as influxdb.AlertAcknowledgementService, qs query.QueryService) *Service {
+	return &Service{
+		bs:  bs,
+		as:  as,
+		qs:  qs,
+		Now: time.Now,
+	}
+}
+
+// FindAlertStates returns the current state of each series of the checks that
+// match filter. The levels of the filter match the current level of a series,
+// and the state of a series is attached the acknowledgement that applies to it.
+func (s *Service) FindAlertStates(ctx context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := filter.Valid(); err != nil {
+		return nil, 0, err
+	}
+
+	// every status of a series is needed to know since when it is at its
+	// current level, the levels are matched once the states are known.
+	levels := filter.Levels
+	filter.Levels = nil
+
+	b, err := s.bs.FindBucketByName(ctx, filter.OrgID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	script := s.statusesScript(b.ID, filter) + `
+	|> group(columns: ["_start", "_stop", "_time", "_value", "_level"], mode: "except")
+	|> sort(columns: ["_time"])`
+
+	sr := &stateReader{}
+	if err := s.query(ctx, b, script, sr.readTable); err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindAlertStates,
+			Err: err,
+		}
+	}
+
+	acks, _, err := s.as.FindAlertAcknowledgements(ctx, influxdb.AlertAcknowledgementFilter{
+		OrgID:   &filter.OrgID,
+		CheckID: filter.CheckID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	states := make([]*influxdb.AlertState, 0, len(sr.states))
+	for _, st := range sr.states {
+		if !matchLevel(levels, st.Level) {
+			continue
+		}
+		for _, a := range acks {
+			if a.Acknowledges(st) {
+				st.Acknowledgement = a
+			}
+		}
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].CheckName != states[j].CheckName {
+			return states[i].CheckName < states[j].CheckName
+		}
+		return tagsString(states[i].Tags) < tagsString(states[j].Tags)
+	})
+	return states, len(states), nil
+}
+
+// FindAlertHistory returns the statuses that match filter, most recent first.
+// The history is paginated with the offset and limit of the find options.
+func (s *Service) FindAlertHistory(ctx context.Context, filter influxdb.AlertFilter, opt ...influxdb.FindOptions) ([]*influxdb.AlertStatus, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := filter.Valid(); err != nil {
+		return nil, 0, err
+	}
+
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if o.Limit == 0 {
+		o.Limit = influxdb.DefaultPageSize
+	}
+	if o.Limit < 0 || o.Limit > influxdb.MaxPageSize || o.Offset < 0 {
+		return nil, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpFindAlertHistory,
+			Msg:  fmt.Sprintf("limit must be between 1 and %d, and offset can't be negative", influxdb.MaxPageSize),
+		}
+	}
+
+	b, err := s.bs.FindBucketByName(ctx, filter.OrgID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	script := s.statusesScript(b.ID, filter) + fmt.Sprintf(`
+	|> group()
+	|> sort(columns: ["_time"], desc: true)
+	|> limit(n: %d, offset: %d)`, o.Limit, o.Offset)
+
+	hr := &historyReader{}
+	if err := s.query(ctx, b, script, hr.readTable); err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindAlertHistory,
+			Err: err,
+		}
+	}
+	return hr.statuses, len(hr.statuses), nil
+}
+
+// statusesScript returns the flux that reads the status messages that match
+// filter from the monitoring bucket.
+func (s *Service) statusesScript(bucketID influxdb.ID, filter influxdb.AlertFilter) string {
+	stop := s.Now().UTC()
+	if filter.Stop != nil {
+		stop = filter.Stop.UTC()
+	}
+	start := stop.Add(-influxdb.DefaultAlertLookback)
+	if filter.Start != nil {
+		start = filter.Start.UTC()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `from(bucketID: %s)
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r._measurement == "statuses" and r._field == "_message")`,
+		fluxString(bucketID.String()), start.Format(time.RFC3339Nano), stop.Format(time.RFC3339Nano))
+
+	if filter.CheckID != nil {
+		fmt.Fprintf(&sb, `
+	|> filter(fn: (r) => r._check_id == %s)`, fluxString(filter.CheckID.String()))
+	}
+	if len(filter.Levels) > 0 {
+		exprs := make([]string, 0, len(filter.Levels))
+		for _, l := range filter.Levels {
+			exprs = append(exprs, fmt.Sprintf("r._level == %s", fluxString(strings.ToLower(l))))
+		}
+		fmt.Fprintf(&sb, `
+	|> filter(fn: (r) => %s)`, strings.Join(exprs, " or "))
+	}
+	for _, t := range filter.Tags {
+		fmt.Fprintf(&sb, `
+	|> filter(fn: (r) => r[%s] == %s)`, fluxString(t.Key), fluxString(t.Value))
+	}
+	return sb.String()
+}
+
+// query runs script with read access to the monitoring bucket b only, and
+// reads each table of the results with fn.
+func (s *Service) query(ctx context.Context, b *influxdb.Bucket, script string, fn func(flux.Table) error) error {
+	return s.queryResults(ctx, b, script, func(r flux.Result) error {
+		return r.Tables().Do(fn)
+	})
+}
+
+// queryResults runs script with read access to the monitoring bucket b only,
+// and reads each of the results with fn.
+func (s *Service) queryResults(ctx context.Context, b *influxdb.Bucket, script string, fn func(flux.Result) error) error {
+	orgID := b.OrgID
+	// At this point we are behind authorization
+	// so we are faking a read only permission to the org's system bucket
+	bucketID := b.ID
+	auth := &influxdb.Authorization{
+		ID:     b.ID,
+		Status: influxdb.Active,
+		OrgID:  orgID,
+		Permissions: []influxdb.Permission{
+			{
+				Action: influxdb.ReadAction,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: &orgID,
+					ID:    &bucketID,
+				},
+			},
+		},
+	}
+	request := &query.Request{Authorization: auth, OrganizationID: orgID, Compiler: lang.FluxCompiler{Now: s.Now(), Query: script}}
+
+	itr, err := s.qs.Query(ctx, request)
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	for itr.More() {
+		if err := fn(itr.Next()); err != nil {
+			return err
+		}
+	}
+	return itr.Err()
+}
+
+// stateReader reads the statuses of each series in a table of their own,
+// sorted by time, and keeps the last state of each series.
+type stateReader struct {
+	states []*influxdb.AlertState
+}
+
+func (sr *stateReader) readTable(tbl flux.Table) error {
+	var st *influxdb.AlertState
+	err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			status, err := readStatus(cr, i)
+			if err != nil {
+				return err
+			}
+			if st == nil {
+				st = &influxdb.AlertState{
+					CheckID:   status.CheckID,
+					CheckName: status.CheckName,
+					CheckType: status.CheckType,
+					Tags:      status.Tags,
+				}
+			}
+			if st.Level != status.Level {
+				st.Level = status.Level
+				st.Since = status.Time
+			}
+			st.LastMessage = status.Message
+			st.LastTime = status.Time
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if st != nil {
+		sr.states = append(sr.states, st)
+	}
+	return nil
+}
+
+// historyReader reads the statuses of a table in order.
+type historyReader struct {
+	statuses []*influxdb.AlertStatus
+}
+
+func (hr *historyReader) readTable(tbl flux.Table) error {
+	return tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			status, err := readStatus(cr, i)
+			if err != nil {
+				return err
+			}
+			hr.statuses = append(hr.statuses, status)
+		}
+		return nil
+	})
+}
+
+// readStatus reads the status in row i. The tags of the status are its string
+// columns that are not named with a leading underscore.
+func readStatus(cr flux.ColReader, i int) (*influxdb.AlertStatus, error) {
+	status := &influxdb.AlertStatus{
+		Tags: []influxdb.Tag{},
+	}
+	for j, col := range cr.Cols() {
+		if col.Label == timeColumn && col.Type == flux.TTime {
+			status.Time = values.Time(cr.Times(j).Value(i)).Time().UTC()
+			continue
+		}
+		if col.Type != flux.TString || cr.Strings(j).IsNull(i) {
+			continue
+		}
+
+		v := cr.Strings(j).ValueString(i)
+		switch col.Label {
+		case valueColumn, messageColumn:
+			status.Message = v
+		case levelColumn:
+			status.Level = strings.ToUpper(v)
+		case checkIDColumn:
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return nil, err
+			}
+			status.CheckID = *id
+		case checkNameColumn:
+			status.CheckName = v
+		case checkTypeColumn:
+			status.CheckType = v
+		default:
+			if !strings.HasPrefix(col.Label, "_") {
+				status.Tags = append(status.Tags, influxdb.Tag{Key: col.Label, Value: v})
+			}
+		}
+	}
+	sort.Slice(status.Tags, func(i, j int) bool {
+		return status.Tags[i].Key < status.Tags[j].Key
+	})
+	return status, nil
+}
+
+func matchLevel(levels []string, level string) bool {
+	if len(levels) == 0 {
+		return true
+	}
+	for _, l := range levels {
+		if strings.EqualFold(l, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func tagsString(tags []influxdb.Tag) string {
+	ss := make([]string, 0, len(tags))
+	for _, t := range tags {
+		ss = append(ss, t.QueryParam())
+	}
+	return strings.Join(ss, ",")
+}
+
+// fluxString returns s as a flux string literal.
+func fluxString(s string) string {
+	return strings.Replace(strconv.Quote(s), "${", `\${`, -1)
+}
@@ -0,0 +1,223 @@
+package alert_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification/alert"
+	"github.com/influxdata/influxdb/query"
+	qmock "github.com/influxdata/influxdb/query/mock"
+)
+
+const (
+	orgID     = influxdb.ID(1)
+	bucketID  = influxdb.ID(2)
+	checkID   = influxdb.ID(10)
+	userID    = influxdb.ID(20)
+	checkIDs  = "000000000000000a"
+	stateCSV  = "#datatype,string,long,dateTime:RFC3339,string,string,string,string,string,string\n#group,false,false,false,false,true,true,true,false,true\n#default,_result,,,,,,,,\n,result,table,_time,_value,_check_id,_check_name,_type,_level,host\n"
+	nowString = "2020-01-01T01:00:00Z"
+)
+
+// newService returns an alert service that answers queries with the
+// statuses of results, and that records the scripts it runs in scripts.
+func newService(t *testing.T, results string, acks []*influxdb.AlertAcknowledgement, scripts *[]string) *alert.Service {
+	t.Helper()
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if id != orgID || name != influxdb.MonitoringSystemBucketName {
+			t.Fatalf("unexpected bucket %s of org %s", name, id)
+		}
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+	}
+
+	as := mock.NewAlertAcknowledgementService()
+	as.FindAlertAcknowledgementsF = func(_ context.Context, filter influxdb.AlertAcknowledgementFilter, _ ...influxdb.FindOptions) ([]*influxdb.AlertAcknowledgement, int, error) {
+		return acks, len(acks), nil
+	}
+
+	qs := &qmock.QueryService{
+		QueryF: func(_ context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.OrganizationID != orgID || len(req.Authorization.Permissions) != 1 || *req.Authorization.Permissions[0].Resource.ID != bucketID {
+				t.Fatalf("expected the query to be authorized to read the monitoring bucket only, got %+v", req.Authorization)
+			}
+			*scripts = append(*scripts, req.Compiler.(lang.FluxCompiler).Query)
+			return csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(strings.NewReader(results)))
+		},
+	}
+
+	s := alert.NewService(bs, as, qs)
+	s.Now = func() time.Time {
+		now, _ := time.Parse(time.RFC3339, nowString)
+		return now
+	}
+	return s
+}
+
+func mustTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestService_FindAlertStates(t *testing.T) {
+	results := stateCSV +
+		",,0,2020-01-01T00:10:00Z,a ok," + checkIDs + ",cpu,threshold,ok,a\n" +
+		",,0,2020-01-01T00:20:00Z,a crit," + checkIDs + ",cpu,threshold,crit,a\n" +
+		",,0,2020-01-01T00:50:00Z,a crit again," + checkIDs + ",cpu,threshold,crit,a\n" +
+		",,1,2020-01-01T00:30:00Z,b ok," + checkIDs + ",cpu,threshold,ok,b\n"
+
+	acks := []*influxdb.AlertAcknowledgement{
+		{
+			ID:        1,
+			CheckID:   checkID,
+			Tags:      []influxdb.Tag{{Key: "host", Value: "a"}},
+			Level:     "CRIT",
+			UserID:    userID,
+			CreatedAt: mustTime("2020-01-01T00:30:00Z"),
+		},
+		{
+			ID:        2,
+			CheckID:   checkID,
+			Tags:      []influxdb.Tag{{Key: "host", Value: "a"}},
+			Level:     "CRIT",
+			UserID:    userID,
+			CreatedAt: mustTime("2020-01-01T00:15:00Z"),
+		},
+	}
+
+	var scripts []string
+	s := newService(t, results, acks, &scripts)
+
+	states, n, err := s.FindAlertStates(context.Background(), influxdb.AlertFilter{OrgID: orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*influxdb.AlertState{
+		{
+			CheckID:         checkID,
+			CheckName:       "cpu",
+			CheckType:       "threshold",
+			Tags:            []influxdb.Tag{{Key: "host", Value: "a"}},
+			Level:           "CRIT",
+			Since:           mustTime("2020-01-01T00:20:00Z"),
+			LastMessage:     "a crit again",
+			LastTime:        mustTime("2020-01-01T00:50:00Z"),
+			Acknowledgement: acks[0],
+		},
+		{
+			CheckID:     checkID,
+			CheckName:   "cpu",
+			CheckType:   "threshold",
+			Tags:        []influxdb.Tag{{Key: "host", Value: "b"}},
+			Level:       "OK",
+			Since:       mustTime("2020-01-01T00:30:00Z"),
+			LastMessage: "b ok",
+			LastTime:    mustTime("2020-01-01T00:30:00Z"),
+		},
+	}
+	if diff := cmp.Diff(expected, states); n != 2 || diff != "" {
+		t.Fatalf("unexpected states -want/+got:\n%s", diff)
+	}
+
+	script := `from(bucketID: "0000000000000002")
+	|> range(start: 2019-12-31T01:00:00Z, stop: 2020-01-01T01:00:00Z)
+	|> filter(fn: (r) => r._measurement == "statuses" and r._field == "_message")
+	|> group(columns: ["_start", "_stop", "_time", "_value", "_level"], mode: "except")
+	|> sort(columns: ["_time"])`
+	if scripts[0] != script {
+		t.Fatalf("expected script:\n%s\ngot:\n%s", script, scripts[0])
+	}
+
+	t.Run("levels match the current level", func(t *testing.T) {
+		states, _, err := s.FindAlertStates(context.Background(), influxdb.AlertFilter{OrgID: orgID, Levels: []string{"ok"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(states) != 1 || states[0].Tags[0].Value != "b" {
+			t.Fatalf("expected the state of host b only, got %+v", states)
+		}
+		if strings.Contains(scripts[1], "_level ==") {
+			t.Fatalf("expected the statuses of every level to be queried, got:\n%s", scripts[1])
+		}
+	})
+}
+
+func TestService_FindAlertHistory(t *testing.T) {
+	results := stateCSV +
+		",,0,2020-01-01T00:50:00Z,a crit again," + checkIDs + ",cpu,threshold,crit,a\n" +
+		",,0,2020-01-01T00:20:00Z,a crit," + checkIDs + ",cpu,threshold,crit,a\n"
+
+	var scripts []string
+	s := newService(t, results, nil, &scripts)
+
+	start := mustTime("2019-12-31T00:00:00Z")
+	id := checkID
+	statuses, n, err := s.FindAlertHistory(context.Background(), influxdb.AlertFilter{
+		OrgID:   orgID,
+		CheckID: &id,
+		Levels:  []string{"CRIT", "warn"},
+		Tags:    []influxdb.Tag{{Key: "host", Value: "a"}},
+		Start:   &start,
+	}, influxdb.FindOptions{Offset: 10, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*influxdb.AlertStatus{
+		{
+			Time:      mustTime("2020-01-01T00:50:00Z"),
+			CheckID:   checkID,
+			CheckName: "cpu",
+			CheckType: "threshold",
+			Level:     "CRIT",
+			Message:   "a crit again",
+			Tags:      []influxdb.Tag{{Key: "host", Value: "a"}},
+		},
+		{
+			Time:      mustTime("2020-01-01T00:20:00Z"),
+			CheckID:   checkID,
+			CheckName: "cpu",
+			CheckType: "threshold",
+			Level:     "CRIT",
+			Message:   "a crit",
+			Tags:      []influxdb.Tag{{Key: "host", Value: "a"}},
+		},
+	}
+	if diff := cmp.Diff(expected, statuses); n != 2 || diff != "" {
+		t.Fatalf("unexpected statuses -want/+got:\n%s", diff)
+	}
+
+	script := `from(bucketID: "0000000000000002")
+	|> range(start: 2019-12-31T00:00:00Z, stop: 2020-01-01T01:00:00Z)
+	|> filter(fn: (r) => r._measurement == "statuses" and r._field == "_message")
+	|> filter(fn: (r) => r._check_id == "000000000000000a")
+	|> filter(fn: (r) => r._level == "crit" or r._level == "warn")
+	|> filter(fn: (r) => r["host"] == "a")
+	|> group()
+	|> sort(columns: ["_time"], desc: true)
+	|> limit(n: 2, offset: 10)`
+	if scripts[0] != script {
+		t.Fatalf("expected script:\n%s\ngot:\n%s", script, scripts[0])
+	}
+
+	t.Run("limit out of bounds", func(t *testing.T) {
+		_, _, err := s.FindAlertHistory(context.Background(), influxdb.AlertFilter{OrgID: orgID}, influxdb.FindOptions{Limit: influxdb.MaxPageSize + 1})
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid limit to be rejected, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,449 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+var _ influxdb.Check = (*Anomaly)(nil)
+
+// AnomalyBaseline is the method used to compute the expected value of a series.
+type AnomalyBaseline string
+
+// Baselines of the anomaly check.
+const (
+	// BaselineStdDev compares values to the mean of the series over the
+	// window, in standard deviations.
+	BaselineStdDev AnomalyBaseline = "stddev"
+	// BaselineMAD compares values to the median of the series over the
+	// window, in median absolute deviations.
+	BaselineMAD AnomalyBaseline = "mad"
+	// BaselineSeasonal compares values to the mean of the series over the
+	// window that ended one period ago, in standard deviations.
+	BaselineSeasonal AnomalyBaseline = "seasonal"
+)
+
+// Anomaly is the anomaly detection check. Instead of comparing the values of a
+// series to static thresholds, it compares them to a baseline computed from the
+// history of the same series.
+type Anomaly struct {
+	Base
+	Baseline AnomalyBaseline `json:"baseline"`
+	// Window is the length of the history used to compute the baseline. It
+	// defaults to the interval of the check for seasonal baselines.
+	Window *notification.Duration `json:"window,omitempty"`
+	// Period is how far back the history of seasonal baselines ends.
+	Period        *notification.Duration `json:"period,omitempty"`
+	Sensitivities []AnomalySensitivity   `json:"sensitivities"`
+}
+
+// AnomalySensitivity is the number of deviations from the baseline that a value
+// must exceed to report the level.
+type AnomalySensitivity struct {
+	Level      notification.CheckLevel `json:"level"`
+	Deviations float64                 `json:"deviations"`
+}
+
+// Type returns the type of the check.
+func (c Anomaly) Type() string {
+	return "anomaly"
+}
+
+// Valid returns error if something is invalid.
+func (c Anomaly) Valid() error {
+	if err := c.Base.Valid(); err != nil {
+		return err
+	}
+
+	switch c.Baseline {
+	case BaselineStdDev, BaselineMAD:
+		if c.Window == nil || len(c.Window.Values) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check window must exist",
+			}
+		}
+		if c.Window.TimeDuration() <= c.Every.TimeDuration() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check window must be greater than the interval",
+			}
+		}
+	case BaselineSeasonal:
+		if c.Period == nil || len(c.Period.Values) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check period must exist",
+			}
+		}
+		if c.Window != nil && len(c.Window.Values) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check window can't be empty",
+			}
+		}
+		if c.Period.TimeDuration() < c.seasonalWindow().TimeDuration() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check period must not be less than the window",
+			}
+		}
+	default:
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid anomaly baseline %q", c.Baseline),
+		}
+	}
+
+	if len(c.Sensitivities) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Anomaly check must have at least one sensitivity",
+		}
+	}
+	levels := make(map[notification.CheckLevel]bool, len(c.Sensitivities))
+	for _, s := range c.Sensitivities {
+		switch s.Level {
+		case notification.Info, notification.Warn, notification.Critical:
+		default:
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid anomaly sensitivity level %s", s.Level),
+			}
+		}
+		if levels[s.Level] {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("duplicate anomaly sensitivity level %s", s.Level),
+			}
+		}
+		levels[s.Level] = true
+		if s.Deviations <= 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly sensitivity deviations must be greater than 0",
+			}
+		}
+	}
+	return nil
+}
+
+func (c Anomaly) seasonalWindow() *notification.Duration {
+	if c.Window != nil {
+		return c.Window
+	}
+	return c.Every
+}
+
+// GenerateFlux returns a flux script for the anomaly check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (c Anomaly) GenerateFlux() (string, error) {
+	p, err := c.GenerateFluxAST()
+	if err != nil {
+		return "", err
+	}
+
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST returns a flux AST for the anomaly check provided. The query
+// of the check is evaluated twice: over the interval of the check for the
+// values that are checked, and over the history that the baseline is computed
+// from.
+func (c Anomaly) GenerateFluxAST() (*ast.Package, error) {
+	p := parser.ParseSource(c.Query.Text)
+	replaceDurationsWithEvery(p, c.Every)
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// TODO(desa): this is a hack that we had to do as a result of https://github.com/influxdata/flux/issues/1701
+	// when it is fixed we should use a separate file and not manipulate the existing one.
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	history, err := c.generateFluxASTHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "math")...)
+	f.Body = append(f.Body, history)
+	f.Body = append(f.Body, c.generateFluxASTBody(fields[0])...)
+
+	return p, nil
+}
+
+// generateFluxASTHistory defines history as the query of the check over the
+// range that the baseline is computed from.
+func (c Anomaly) generateFluxASTHistory() (ast.Statement, error) {
+	var start, stop ast.Expression
+	switch c.Baseline {
+	case BaselineSeasonal:
+		// Flux adds up the values of a duration literal, i.e. 1d1h is 25 hours.
+		values := append(append([]ast.Duration{}, c.Period.Values...), c.seasonalWindow().Values...)
+		start = flux.Negative(&ast.DurationLiteral{Values: values})
+		stop = flux.Negative((*ast.DurationLiteral)(c.Period))
+	default:
+		start = flux.Negative((*ast.DurationLiteral)(c.Window))
+		stop = flux.Negative((*ast.DurationLiteral)(c.Every))
+	}
+
+	p := parser.ParseSource(c.Query.Text)
+	replaceDurationsWithEvery(p, c.Every)
+	addCreateEmptyFalseToAggregateWindow(p)
+	setRange(p, start, stop)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	f := p.Files[0]
+	if err := assignPipelineToData(f); err != nil {
+		return nil, err
+	}
+	history := f.Body[0].(*ast.VariableAssignment)
+	history.ID = flux.Identifier("history")
+	history.Init = flux.Pipe(history.Init, dropColumns("_start", "_stop"))
+
+	return history, nil
+}
+
+// setRange sets the start and stop of every range in the query. The stop is
+// left out when it is nil.
+func setRange(pkg *ast.Package, start, stop ast.Expression) {
+	ast.Visit(pkg, func(n ast.Node) {
+		if call, ok := n.(*ast.CallExpression); ok {
+			if id, ok := call.Callee.(*ast.Identifier); ok && id.Name == "range" {
+				for _, args := range call.Arguments {
+					if obj, ok := args.(*ast.ObjectExpression); ok {
+						obj.Properties = []*ast.Property{flux.Property("start", start)}
+						if stop != nil {
+							obj.Properties = append(obj.Properties, flux.Property("stop", stop))
+						}
+					}
+				}
+			}
+		}
+	})
+}
+
+func (c Anomaly) generateFluxASTBody(field string) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("anomaly"))
+	statements = append(statements, c.generateFluxASTBaseline()...)
+	statements = append(statements, c.generateFluxASTSensitivityFunctions(field)...)
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements, c.generateFluxASTChecksFunction(field))
+	return statements
+}
+
+// anomalyEpoch is the time of the rows that carry the baseline and the spread
+// of a series, so that they sort before the rows of the series.
+var anomalyEpoch = time.Unix(0, 0).UTC()
+
+// generateFluxASTBaseline defines baseline and spread as streams with a single
+// row per series, that carry the expected value of the series and the size of
+// a deviation from it respectively.
+func (c Anomaly) generateFluxASTBaseline() []ast.Statement {
+	if c.Baseline == BaselineMAD {
+		baseline := flux.Pipe(
+			flux.Identifier("history"),
+			append([]*ast.CallExpression{flux.Call(flux.Identifier("median"), flux.Object())}, statisticRow("_baseline")...)...,
+		)
+
+		// The median of the absolute deviations from the median of the series.
+		deviations := flux.Pipe(
+			flux.Call(flux.Identifier("union"), flux.Object(flux.Property("tables", flux.Array(
+				flux.Identifier("baseline"),
+				flux.Identifier("history"),
+			)))),
+			sortByTime(),
+			flux.Call(flux.Identifier("cumulativeSum"), flux.Object(flux.Property("columns", stringArray("_baseline")))),
+			filterFn(flux.Exists(flux.Member("r", "_value"))),
+			flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(
+				flux.FunctionParams("r"),
+				flux.ObjectWith("r", flux.Property("_value", absDeviation(flux.Member("r", "_value")))),
+			)))),
+			flux.Call(flux.Identifier("median"), flux.Object()),
+		)
+		spread := flux.Pipe(deviations, statisticRow("_spread")...)
+
+		return []ast.Statement{
+			flux.DefineVariable("baseline", baseline),
+			flux.DefineVariable("spread", spread),
+		}
+	}
+
+	baseline := flux.Pipe(
+		flux.Identifier("history"),
+		append([]*ast.CallExpression{flux.Call(flux.Identifier("mean"), flux.Object())}, statisticRow("_baseline")...)...,
+	)
+	stddev := flux.Call(flux.Identifier("stddev"), flux.Object(flux.Property("mode", flux.String("population"))))
+	spread := flux.Pipe(
+		flux.Identifier("history"),
+		append([]*ast.CallExpression{stddev}, statisticRow("_spread")...)...,
+	)
+
+	return []ast.Statement{
+		flux.DefineVariable("baseline", baseline),
+		flux.DefineVariable("spread", spread),
+	}
+}
+
+// statisticRow returns the calls that turn the result of an aggregate into a
+// row that carries it in column, and counts it in _n.
+func statisticRow(column string) []*ast.CallExpression {
+	return []*ast.CallExpression{
+		filterFn(flux.Exists(flux.Member("r", "_value"))),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(
+			flux.FunctionParams("r"),
+			flux.ObjectWith("r",
+				flux.Property("_time", flux.Time(anomalyEpoch)),
+				flux.Property(column, toFloat(flux.Member("r", "_value"))),
+				flux.Property("_n", flux.Integer(1)),
+			),
+		)))),
+		dropColumns("_value"),
+	}
+}
+
+// zeroStatistics adds the columns of the statistics to the rows of the series,
+// so that they exist for the series that have no history.
+func zeroStatistics() *ast.CallExpression {
+	return flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(
+		flux.FunctionParams("r"),
+		flux.ObjectWith("r",
+			flux.Property("_baseline", flux.Float(0)),
+			flux.Property("_spread", flux.Float(0)),
+			flux.Property("_n", flux.Integer(0)),
+		),
+	))))
+}
+
+func (c Anomaly) generateFluxASTSensitivityFunctions(field string) []ast.Statement {
+	statements := make([]ast.Statement, len(c.Sensitivities))
+
+	// This assumes that the sensitivities we've been provided do not have duplicates.
+	for k, s := range c.Sensitivities {
+		fnBody := flux.GreaterThan(
+			absDeviation(flux.Member("r", field)),
+			flux.Multiply(flux.Float(s.Deviations), flux.Member("r", "_spread")),
+		)
+		fn := flux.Function(flux.FunctionParams("r"), fnBody)
+
+		lvl := strings.ToLower(s.Level.String())
+		statements[k] = flux.DefineVariable(lvl, fn)
+	}
+	return statements
+}
+
+// generateFluxASTChecksFunction carries the baseline and the spread of each
+// series onto the rows of the series by summing them up in time order, before
+// checking the rows of the series that have both.
+func (c Anomaly) generateFluxASTChecksFunction(field string) ast.Statement {
+	data := flux.Pipe(flux.Identifier("data"), dropColumns("_start", "_stop"), zeroStatistics())
+	union := flux.Call(flux.Identifier("union"), flux.Object(flux.Property("tables", flux.Array(
+		flux.Identifier("baseline"),
+		flux.Identifier("spread"),
+		data,
+	))))
+
+	return flux.ExpressionStatement(flux.Pipe(
+		union,
+		sortByTime(),
+		flux.Call(flux.Identifier("cumulativeSum"), flux.Object(flux.Property("columns", stringArray("_baseline", "_spread", "_n")))),
+		filterFn(flux.And(
+			flux.Exists(flux.Member("r", "_value")),
+			flux.Equal(flux.Member("r", "_n"), flux.Integer(2)),
+		)),
+		dropColumns("_field", "_n"),
+		flux.Call(flux.Identifier("rename"), flux.Object(flux.Property("columns", flux.Object(
+			flux.Property("_value", flux.String(field)),
+		)))),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+func (c Anomaly) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	// This assumes that the sensitivities we've been provided do not have duplicates.
+	for _, s := range c.Sensitivities {
+		lvl := strings.ToLower(s.Level.String())
+		objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+	}
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+// absDeviation returns math.abs(x: float(v: e) - r._baseline).
+func absDeviation(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Member("math", "abs"), flux.Object(
+		flux.Property("x", flux.Subtract(toFloat(e), flux.Member("r", "_baseline"))),
+	))
+}
+
+func toFloat(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("float"), flux.Object(flux.Property("v", e)))
+}
+
+func filterFn(e ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), e))))
+}
+
+func sortByTime() *ast.CallExpression {
+	return flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", stringArray("_time"))))
+}
+
+func dropColumns(columns ...string) *ast.CallExpression {
+	return flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("columns", stringArray(columns...))))
+}
+
+func stringArray(ss ...string) *ast.ArrayExpression {
+	es := make([]ast.Expression, len(ss))
+	for i, s := range ss {
+		es[i] = flux.String(s)
+	}
+	return flux.Array(es...)
+}
+
+type anomalyAlias Anomaly
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Anomaly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			anomalyAlias
+			Type string `json:"type"`
+		}{
+			anomalyAlias: anomalyAlias(c),
+			Type:         c.Type(),
+		})
+}
@@ -0,0 +1,264 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+)
+
+func TestAnomaly_GenerateFlux(t *testing.T) {
+	base := check.Base{
+		ID:   10,
+		Name: "moo",
+		Tags: []influxdb.Tag{
+			{Key: "aaa", Value: "vaaa"},
+		},
+		Every:                 mustDuration("1h"),
+		StatusMessageTemplate: "whoa! {r.usage_user}",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean) |> yield()`,
+		},
+	}
+	sensitivities := []check.AnomalySensitivity{
+		{Level: notification.Critical, Deviations: 3},
+		{Level: notification.Warn, Deviations: 2},
+	}
+
+	tests := []struct {
+		name    string
+		anomaly check.Anomaly
+		script  string
+	}{
+		{
+			name: "stddev",
+			anomaly: check.Anomaly{
+				Base:          base,
+				Baseline:      check.BaselineStdDev,
+				Window:        mustDuration("1d"),
+				Sensitivities: sensitivities,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "math"
+
+data = from(bucket: "foo")
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+history = from(bucket: "foo")
+	|> range(start: -1d, stop: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+	|> drop(columns: ["_start", "_stop"])
+
+option task = {name: "moo", every: 1h}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+baseline = history
+	|> mean()
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _baseline: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+spread = history
+	|> stddev(mode: "population")
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _spread: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+crit = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 3.0 * r._spread)
+warn = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 2.0 * r._spread)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+
+union(tables: [baseline, spread, data
+	|> drop(columns: ["_start", "_stop"])
+	|> map(fn: (r) =>
+		({r with _baseline: 0.0, _spread: 0.0, _n: 0}))])
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_baseline", "_spread", "_n"])
+	|> filter(fn: (r) =>
+		(exists r._value and r._n == 2))
+	|> drop(columns: ["_field", "_n"])
+	|> rename(columns: {_value: "usage_user"})
+	|> monitor.check(
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+		{
+			name: "mad",
+			anomaly: check.Anomaly{
+				Base:          base,
+				Baseline:      check.BaselineMAD,
+				Window:        mustDuration("1d"),
+				Sensitivities: sensitivities,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "math"
+
+data = from(bucket: "foo")
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+history = from(bucket: "foo")
+	|> range(start: -1d, stop: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+	|> drop(columns: ["_start", "_stop"])
+
+option task = {name: "moo", every: 1h}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+baseline = history
+	|> median()
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _baseline: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+spread = union(tables: [baseline, history])
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_baseline"])
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _value: math.abs(x: float(v: r._value) - r._baseline)}))
+	|> median()
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _spread: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+crit = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 3.0 * r._spread)
+warn = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 2.0 * r._spread)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+
+union(tables: [baseline, spread, data
+	|> drop(columns: ["_start", "_stop"])
+	|> map(fn: (r) =>
+		({r with _baseline: 0.0, _spread: 0.0, _n: 0}))])
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_baseline", "_spread", "_n"])
+	|> filter(fn: (r) =>
+		(exists r._value and r._n == 2))
+	|> drop(columns: ["_field", "_n"])
+	|> rename(columns: {_value: "usage_user"})
+	|> monitor.check(
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+		{
+			name: "seasonal",
+			anomaly: check.Anomaly{
+				Base:          base,
+				Baseline:      check.BaselineSeasonal,
+				Period:        mustDuration("7d"),
+				Sensitivities: sensitivities,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "math"
+
+data = from(bucket: "foo")
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+history = from(bucket: "foo")
+	|> range(start: -7d1h, stop: -7d)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+	|> drop(columns: ["_start", "_stop"])
+
+option task = {name: "moo", every: 1h}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+baseline = history
+	|> mean()
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _baseline: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+spread = history
+	|> stddev(mode: "population")
+	|> filter(fn: (r) =>
+		(exists r._value))
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _spread: float(v: r._value), _n: 1}))
+	|> drop(columns: ["_value"])
+crit = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 3.0 * r._spread)
+warn = (r) =>
+	(math.abs(x: float(v: r.usage_user) - r._baseline) > 2.0 * r._spread)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+
+union(tables: [baseline, spread, data
+	|> drop(columns: ["_start", "_stop"])
+	|> map(fn: (r) =>
+		({r with _baseline: 0.0, _spread: 0.0, _n: 0}))])
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_baseline", "_spread", "_n"])
+	|> filter(fn: (r) =>
+		(exists r._value and r._n == 2))
+	|> drop(columns: ["_field", "_n"])
+	|> rename(columns: {_value: "usage_user"})
+	|> monitor.check(
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.anomaly.GenerateFlux()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if exp, got := tt.script, s; exp != got {
+				t.Errorf("expected:\n%v\n\ngot:\n%v\n", exp, got)
+			}
+		})
+	}
+}
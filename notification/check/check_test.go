@@ -37,6 +37,7 @@ var goodBase = check.Base{
 }
 
 func TestValidCheck(t *testing.T) {
+	low, high := 10.0, 100.0
 	cases := []struct {
 		name string
 		src  influxdb.Check
@@ -154,6 +155,233 @@ func TestValidCheck(t *testing.T) {
 				Msg:  "range threshold min can't be larger than max",
 			},
 		},
+		{
+			name: "empty threshold for",
+			src: &check.Threshold{
+				Base: goodBase,
+				For:  &notification.Duration{},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Check For can't be empty",
+			},
+		},
+		{
+			name: "threshold for shorter than every",
+			src: &check.Threshold{
+				Base: goodBase,
+				For:  mustDuration("30s"),
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Check For must be at least its Every",
+			},
+		},
+		{
+			name: "negative threshold for evaluations",
+			src: &check.Threshold{
+				Base:           goodBase,
+				ForEvaluations: -1,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Check ForEvaluations can't be negative",
+			},
+		},
+		{
+			name: "threshold for and for evaluations",
+			src: &check.Threshold{
+				Base:           goodBase,
+				For:            mustDuration("5m"),
+				ForEvaluations: 3,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Check For and ForEvaluations can't both be set",
+			},
+		},
+		{
+			name: "greater threshold exit value above value",
+			src: &check.Threshold{
+				Base: goodBase,
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{Value: 90, ExitValue: &high},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "greater threshold exit value can't be larger than value",
+			},
+		},
+		{
+			name: "lesser threshold exit value below value",
+			src: &check.Threshold{
+				Base: goodBase,
+				Thresholds: []check.ThresholdConfig{
+					&check.Lesser{Value: 20, ExitValue: &low},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "lesser threshold exit value can't be smaller than value",
+			},
+		},
+		{
+			name: "range threshold with exit min only",
+			src: &check.Threshold{
+				Base: goodBase,
+				Thresholds: []check.ThresholdConfig{
+					&check.Range{Min: 20, Max: 90, ExitMin: &low},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "range threshold exit min and exit max must be set together",
+			},
+		},
+		{
+			name: "range threshold exit range within the range",
+			src: &check.Threshold{
+				Base: goodBase,
+				Thresholds: []check.ThresholdConfig{
+					&check.Range{Min: 20, Max: 90, ExitMin: &low, ExitMax: &high, Within: false},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "range threshold exit range must be within the range",
+			},
+		},
+		{
+			name: "good stateful threshold",
+			src: &check.Threshold{
+				Base: goodBase,
+				For:  mustDuration("5m"),
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{Value: 90, ExitValue: &low},
+					&check.Range{Min: 20, Max: 90, ExitMin: &low, ExitMax: &high, Within: true},
+				},
+			},
+		},
+		{
+			name: "anomaly window not greater than the interval",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Baseline: check.BaselineStdDev,
+				Window:   mustDuration("1m"),
+				Sensitivities: []check.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check window must be greater than the interval",
+			},
+		},
+		{
+			name: "anomaly without period",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Baseline: check.BaselineSeasonal,
+				Sensitivities: []check.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Anomaly check period must exist",
+			},
+		},
+		{
+			name: "anomaly duplicate sensitivity",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Baseline: check.BaselineMAD,
+				Window:   mustDuration("1d"),
+				Sensitivities: []check.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3},
+					{Level: notification.Critical, Deviations: 2},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "duplicate anomaly sensitivity level CRIT",
+			},
+		},
+		{
+			name: "good anomaly",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Baseline: check.BaselineSeasonal,
+				Period:   mustDuration("7d"),
+				Sensitivities: []check.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3},
+					{Level: notification.Warn, Deviations: 2},
+				},
+			},
+		},
+		{
+			name: "composite with a single condition",
+			src: &check.Composite{
+				Base: goodBase,
+				Conditions: []check.CompositeCondition{
+					{Name: "cpu", Threshold: &check.Greater{Value: 90}},
+				},
+				Operator: check.CompositeAnd,
+				Level:    notification.Critical,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Composite check must have at least two conditions",
+			},
+		},
+		{
+			name: "composite condition named after a join tag",
+			src: &check.Composite{
+				Base: goodBase,
+				Conditions: []check.CompositeCondition{
+					{Name: "cpu", Threshold: &check.Greater{Value: 90}},
+					{Name: "host", Threshold: &check.Greater{Value: 8}},
+				},
+				Operator: check.CompositeOr,
+				JoinOn:   []string{"host"},
+				Level:    notification.Critical,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `composite condition name "host" is not unique`,
+			},
+		},
+		{
+			name: "composite condition without threshold",
+			src: &check.Composite{
+				Base: goodBase,
+				Conditions: []check.CompositeCondition{
+					{Name: "cpu", Threshold: &check.Greater{Value: 90}},
+					{Name: "load"},
+				},
+				Operator: check.CompositeAnd,
+				Level:    notification.Critical,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `composite condition "load" must have a threshold`,
+			},
+		},
+		{
+			name: "good composite",
+			src: &check.Composite{
+				Base: goodBase,
+				Conditions: []check.CompositeCondition{
+					{Name: "cpu", Threshold: &check.Greater{Value: 90}},
+					{Name: "load", Threshold: &check.Greater{Value: 8}, Not: true},
+				},
+				Operator: check.CompositeAnd,
+				JoinOn:   []string{"host"},
+				For:      3,
+				Level:    notification.Warn,
+			},
+		},
 	}
 	for _, c := range cases {
 		got := c.src.Valid()
@@ -174,6 +402,7 @@ func mustDuration(d string) *notification.Duration {
 }
 
 func TestJSON(t *testing.T) {
+	exitValue, exitMin, exitMax := 80.0, -100.0, 600.0
 	cases := []struct {
 		name string
 		src  influxdb.Check
@@ -262,6 +491,178 @@ func TestJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "stateful threshold",
+			src: &check.Threshold{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1h"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key    string   `json:"key"`
+								Values []string `json:"values"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				For: mustDuration("3h"),
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 90, ExitValue: &exitValue},
+					&check.Range{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Min: -10000, Max: 500, ExitMin: &exitMin, ExitMax: &exitMax},
+				},
+			},
+		},
+		{
+			name: "threshold for evaluations",
+			src: &check.Threshold{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1h"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key    string   `json:"key"`
+								Values []string `json:"values"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				ForEvaluations: 3,
+				Thresholds: []check.ThresholdConfig{
+					&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 90},
+				},
+			},
+		},
+		{
+			name: "simple anomaly",
+			src: &check.Anomaly{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1h"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key    string   `json:"key"`
+								Values []string `json:"values"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Baseline: check.BaselineSeasonal,
+				Window:   mustDuration("2h"),
+				Period:   mustDuration("1d"),
+				Sensitivities: []check.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3.5},
+				},
+			},
+		},
+		{
+			name: "simple composite",
+			src: &check.Composite{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1m"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key    string   `json:"key"`
+								Values []string `json:"values"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Conditions: []check.CompositeCondition{
+					{
+						Name: "cpu",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1m)`,
+							BuilderConfig: influxdb.BuilderConfig{
+								Buckets: []string{},
+								Tags: []struct {
+									Key    string   `json:"key"`
+									Values []string `json:"values"`
+								}{},
+								Functions: []struct {
+									Name string `json:"name"`
+								}{},
+							},
+						},
+						Threshold: &check.Greater{Value: 90},
+					},
+					{
+						Name: "mem",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "bar") |> range(start: -1m)`,
+							BuilderConfig: influxdb.BuilderConfig{
+								Buckets: []string{},
+								Tags: []struct {
+									Key    string   `json:"key"`
+									Values []string `json:"values"`
+								}{},
+								Functions: []struct {
+									Name string `json:"name"`
+								}{},
+							},
+						},
+						Threshold: &check.Range{Min: 1, Max: 2, Within: true},
+						Not:       true,
+					},
+				},
+				Operator: check.CompositeOr,
+				JoinOn:   []string{"host"},
+				For:      2,
+				Level:    notification.Critical,
+			},
+		},
 	}
 	for _, c := range cases {
 		fn := func(t *testing.T) {
@@ -0,0 +1,377 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+var _ influxdb.Check = (*Composite)(nil)
+
+// CompositeOperator combines the conditions of a composite check.
+type CompositeOperator string
+
+// Operators of the composite check.
+const (
+	CompositeAnd CompositeOperator = "and"
+	CompositeOr  CompositeOperator = "or"
+)
+
+// Composite is the composite check. It evaluates several queries, joins their
+// series on tags and reports its level for the joined series that meet the
+// combination of the conditions.
+type Composite struct {
+	Base
+	Conditions []CompositeCondition `json:"conditions"`
+	Operator   CompositeOperator    `json:"operator"`
+	// JoinOn are the tags that the series of the conditions are joined on, the
+	// series are also joined on their time.
+	JoinOn []string `json:"joinOn"`
+	// For is the number of consecutive evaluations the conditions must be met
+	// for before the level is reported.
+	For   int                     `json:"for,omitempty"`
+	Level notification.CheckLevel `json:"level"`
+}
+
+// CompositeCondition is a threshold on the single field of a query. The value
+// of the field is named after the condition in the joined series, and in the
+// statuses of the check.
+type CompositeCondition struct {
+	Name      string                  `json:"name"`
+	Query     influxdb.DashboardQuery `json:"query"`
+	Threshold ThresholdConfig         `json:"threshold"`
+	// Not negates the threshold.
+	Not bool `json:"not,omitempty"`
+}
+
+var compositeConditionName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// Type returns the type of the check.
+func (c Composite) Type() string {
+	return "composite"
+}
+
+// Valid returns error if something is invalid.
+func (c Composite) Valid() error {
+	if err := c.Base.Valid(); err != nil {
+		return err
+	}
+	if len(c.Conditions) < 2 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Composite check must have at least two conditions",
+		}
+	}
+	if c.Operator != CompositeAnd && c.Operator != CompositeOr {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid composite operator %q", c.Operator),
+		}
+	}
+	if c.For < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Composite check for can't be negative",
+		}
+	}
+	switch c.Level {
+	case notification.Info, notification.Warn, notification.Critical:
+	default:
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid composite check level %s", c.Level),
+		}
+	}
+
+	names := make(map[string]bool, len(c.Conditions)+len(c.JoinOn))
+	for _, tag := range c.JoinOn {
+		names[tag] = true
+	}
+	for _, cond := range c.Conditions {
+		if !compositeConditionName.MatchString(cond.Name) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid composite condition name %q", cond.Name),
+			}
+		}
+		if names[cond.Name] {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("composite condition name %q is not unique", cond.Name),
+			}
+		}
+		names[cond.Name] = true
+		if cond.Threshold == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("composite condition %q must have a threshold", cond.Name),
+			}
+		}
+		if err := cond.Threshold.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type compositeDecode struct {
+	Base
+	Conditions []compositeConditionDecode `json:"conditions"`
+	Operator   CompositeOperator          `json:"operator"`
+	JoinOn     []string                   `json:"joinOn"`
+	For        int                        `json:"for"`
+	Level      notification.CheckLevel    `json:"level"`
+}
+
+type compositeConditionDecode struct {
+	Name      string                  `json:"name"`
+	Query     influxdb.DashboardQuery `json:"query"`
+	Threshold *thresholdConfigDecode  `json:"threshold"`
+	Not       bool                    `json:"not"`
+}
+
+// UnmarshalJSON implement json.Unmarshaler interface.
+func (c *Composite) UnmarshalJSON(b []byte) error {
+	cdRaw := new(compositeDecode)
+	if err := json.Unmarshal(b, cdRaw); err != nil {
+		return err
+	}
+	c.Base = cdRaw.Base
+	c.Operator = cdRaw.Operator
+	c.JoinOn = cdRaw.JoinOn
+	c.For = cdRaw.For
+	c.Level = cdRaw.Level
+	c.Conditions = nil
+	for _, condRaw := range cdRaw.Conditions {
+		cond := CompositeCondition{
+			Name:  condRaw.Name,
+			Query: condRaw.Query,
+			Not:   condRaw.Not,
+		}
+		if condRaw.Threshold != nil {
+			td, err := condRaw.Threshold.thresholdConfig()
+			if err != nil {
+				return err
+			}
+			cond.Threshold = td
+		}
+		c.Conditions = append(c.Conditions, cond)
+	}
+	return nil
+}
+
+// GenerateFlux returns a flux script for the composite check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (c Composite) GenerateFlux() (string, error) {
+	p, err := c.GenerateFluxAST()
+	if err != nil {
+		return "", err
+	}
+
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST returns a flux AST for the composite check provided. The
+// query of each condition is assigned to its own variable, and evaluated over
+// the intervals that the conditions must be met for.
+func (c Composite) GenerateFluxAST() (*ast.Package, error) {
+	var (
+		imports    []*ast.ImportDeclaration
+		statements []ast.Statement
+		seen       = make(map[string]bool)
+	)
+	for _, cond := range c.Conditions {
+		f, err := c.generateFluxASTConditionData(cond)
+		if err != nil {
+			return nil, fmt.Errorf("condition %s: %v", cond.Name, err)
+		}
+		for _, imp := range f.Imports {
+			if !seen[imp.Path.Value] {
+				seen[imp.Path.Value] = true
+				imports = append(imports, imp)
+			}
+		}
+		statements = append(statements, f.Body...)
+	}
+	if !seen["influxdata/influxdb/monitor"] {
+		imports = append(imports, flux.ImportDeclaration("influxdata/influxdb/monitor"))
+	}
+
+	f := flux.File("", imports, append(statements, c.generateFluxASTBody()...))
+	f.Package = &ast.PackageClause{Name: flux.Identifier("main")}
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+// evaluations returns the number of intervals that the conditions are
+// evaluated over.
+func (c Composite) evaluations() int64 {
+	if c.For > 1 {
+		return int64(c.For)
+	}
+	return 1
+}
+
+// generateFluxASTConditionData parses the query of the condition, and assigns
+// the value of its field to the column named after the condition.
+func (c Composite) generateFluxASTConditionData(cond CompositeCondition) (*ast.File, error) {
+	p := parser.ParseSource(cond.Query.Text)
+	replaceDurationsWithEvery(p, c.Every)
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	start := &ast.DurationLiteral{}
+	for _, d := range c.Every.Values {
+		start.Values = append(start.Values, ast.Duration{Magnitude: d.Magnitude * c.evaluations(), Unit: d.Unit})
+	}
+	setRange(p, flux.Negative(start), nil)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	f := p.Files[0]
+	if err := assignPipelineToData(f); err != nil {
+		return nil, err
+	}
+
+	columns := append([]string{"_time"}, c.JoinOn...)
+	columns = append(columns, cond.Name)
+
+	data := f.Body[0].(*ast.VariableAssignment)
+	data.ID = flux.Identifier(conditionData(cond))
+	data.Init = flux.Pipe(data.Init,
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(
+			flux.FunctionParams("r"),
+			flux.ObjectWith("r", flux.Property(cond.Name, flux.Member("r", "_value"))),
+		)))),
+		flux.Call(flux.Identifier("keep"), flux.Object(flux.Property("columns", stringArray(columns...)))),
+	)
+	return f, nil
+}
+
+func conditionData(cond CompositeCondition) string {
+	return "data_" + cond.Name
+}
+
+func (c Composite) generateFluxASTBody() []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("composite"))
+	statements = append(statements, c.generateLevelFn())
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	return append(statements, c.generateFluxASTChecksFunction())
+}
+
+// generateLevelFn reports the level when the conditions were met in all of the
+// evaluations, as counted in _met.
+func (c Composite) generateLevelFn() ast.Statement {
+	fnBody := flux.GreaterThanEqual(flux.Member("r", "_met"), flux.Integer(c.evaluations()))
+	fn := flux.Function(flux.FunctionParams("r"), fnBody)
+
+	lvl := strings.ToLower(c.Level.String())
+
+	return flux.DefineVariable(lvl, fn)
+}
+
+// generateFluxASTJoin joins the data of the conditions two at a time, which
+// is all that join supports.
+func (c Composite) generateFluxASTJoin() ast.Expression {
+	on := stringArray(append([]string{"_time"}, c.JoinOn...)...)
+
+	var joined ast.Expression = flux.Identifier(conditionData(c.Conditions[0]))
+	name := c.Conditions[0].Name
+	for _, cond := range c.Conditions[1:] {
+		joined = flux.Call(flux.Identifier("join"), flux.Object(
+			flux.Property("tables", flux.Object(
+				flux.Property(name, joined),
+				flux.Property(cond.Name, flux.Identifier(conditionData(cond))),
+			)),
+			flux.Property("on", on),
+		))
+		name = "_joined"
+	}
+	return joined
+}
+
+// generateFluxASTConditions combines the thresholds of the conditions with the
+// operator of the check.
+func (c Composite) generateFluxASTConditions() ast.Expression {
+	var expr ast.Expression
+	for _, cond := range c.Conditions {
+		e := cond.Threshold.generateFluxASTThresholdExpression(cond.Name)
+		if cond.Not {
+			e = flux.Not(e)
+		}
+
+		switch {
+		case expr == nil:
+			expr = e
+		case c.Operator == CompositeOr:
+			expr = flux.Or(expr, e)
+		default:
+			expr = flux.And(expr, e)
+		}
+	}
+	return expr
+}
+
+// generateFluxASTChecksFunction counts the evaluations in which each joined
+// series met the conditions, and checks the last evaluation of the series.
+func (c Composite) generateFluxASTChecksFunction() ast.Statement {
+	met := flux.If(c.generateFluxASTConditions(), flux.Integer(1), flux.Integer(0))
+
+	return flux.ExpressionStatement(flux.Pipe(
+		c.generateFluxASTJoin(),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(
+			flux.FunctionParams("r"),
+			flux.ObjectWith("r",
+				flux.Property("_measurement", flux.String("composite")),
+				flux.Property("_met", met),
+			),
+		)))),
+		sortByTime(),
+		flux.Call(flux.Identifier("cumulativeSum"), flux.Object(flux.Property("columns", stringArray("_met")))),
+		flux.Call(flux.Identifier("tail"), flux.Object(flux.Property("n", flux.Integer(1)))),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+func (c Composite) generateFluxASTChecksCall() *ast.CallExpression {
+	lvl := strings.ToLower(c.Level.String())
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(
+		flux.Property("data", flux.Identifier("check")),
+		flux.Property("messageFn", flux.Identifier("messageFn")),
+		flux.Property(lvl, flux.Identifier(lvl)),
+	))
+}
+
+type compositeAlias Composite
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Composite) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			compositeAlias
+			Type string `json:"type"`
+		}{
+			compositeAlias: compositeAlias(c),
+			Type:           c.Type(),
+		})
+}
@@ -0,0 +1,171 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+)
+
+func TestComposite_GenerateFlux(t *testing.T) {
+	base := check.Base{
+		ID:   10,
+		Name: "moo",
+		Tags: []influxdb.Tag{
+			{Key: "aaa", Value: "vaaa"},
+		},
+		Every:                 mustDuration("1m"),
+		StatusMessageTemplate: "whoa! {r.cpu}",
+	}
+	cpu := check.CompositeCondition{
+		Name: "cpu",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean) |> yield()`,
+		},
+		Threshold: check.Greater{Value: 90},
+	}
+	load := check.CompositeCondition{
+		Name: "load",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "load1") |> aggregateWindow(every: 1m, fn: max)`,
+		},
+		Threshold: check.Greater{Value: 8},
+	}
+	mem := check.CompositeCondition{
+		Name: "mem",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "used") |> aggregateWindow(every: 1m, fn: max)`,
+		},
+		Threshold: check.Range{Min: 1, Max: 2, Within: true},
+		Not:       true,
+	}
+
+	tests := []struct {
+		name      string
+		composite check.Composite
+		script    string
+	}{
+		{
+			name: "and for three evaluations",
+			composite: check.Composite{
+				Base:       base,
+				Conditions: []check.CompositeCondition{cpu, load, mem},
+				Operator:   check.CompositeAnd,
+				JoinOn:     []string{"host"},
+				For:        3,
+				Level:      notification.Critical,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+
+data_cpu = from(bucket: "foo")
+	|> range(start: -3m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+	|> map(fn: (r) =>
+		({r with cpu: r._value}))
+	|> keep(columns: ["_time", "host", "cpu"])
+data_load = from(bucket: "foo")
+	|> range(start: -3m)
+	|> filter(fn: (r) =>
+		(r._field == "load1"))
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+	|> map(fn: (r) =>
+		({r with load: r._value}))
+	|> keep(columns: ["_time", "host", "load"])
+data_mem = from(bucket: "foo")
+	|> range(start: -3m)
+	|> filter(fn: (r) =>
+		(r._field == "used"))
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+	|> map(fn: (r) =>
+		({r with mem: r._value}))
+	|> keep(columns: ["_time", "host", "mem"])
+
+option task = {name: "moo", every: 1m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "composite",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(r._met >= 3)
+messageFn = (r) =>
+	("whoa! {r.cpu}")
+
+join(tables: {_joined: join(tables: {cpu: data_cpu, load: data_load}, on: ["_time", "host"]), mem: data_mem}, on: ["_time", "host"])
+	|> map(fn: (r) =>
+		({r with _measurement: "composite", _met: if r.cpu > 90.0 and r.load > 8.0 and not (r.mem < 2.0 and r.mem > 1.0) then 1 else 0}))
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_met"])
+	|> tail(n: 1)
+	|> monitor.check(data: check, messageFn: messageFn, crit: crit)`,
+		},
+		{
+			name: "or",
+			composite: check.Composite{
+				Base:       base,
+				Conditions: []check.CompositeCondition{cpu, load},
+				Operator:   check.CompositeOr,
+				Level:      notification.Warn,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+
+data_cpu = from(bucket: "foo")
+	|> range(start: -1m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+	|> map(fn: (r) =>
+		({r with cpu: r._value}))
+	|> keep(columns: ["_time", "cpu"])
+data_load = from(bucket: "foo")
+	|> range(start: -1m)
+	|> filter(fn: (r) =>
+		(r._field == "load1"))
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+	|> map(fn: (r) =>
+		({r with load: r._value}))
+	|> keep(columns: ["_time", "load"])
+
+option task = {name: "moo", every: 1m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "composite",
+	tags: {aaa: "vaaa"},
+}
+warn = (r) =>
+	(r._met >= 1)
+messageFn = (r) =>
+	("whoa! {r.cpu}")
+
+join(tables: {cpu: data_cpu, load: data_load}, on: ["_time"])
+	|> map(fn: (r) =>
+		({r with _measurement: "composite", _met: if r.cpu > 90.0 or r.load > 8.0 then 1 else 0}))
+	|> sort(columns: ["_time"])
+	|> cumulativeSum(columns: ["_met"])
+	|> tail(n: 1)
+	|> monitor.check(data: check, messageFn: messageFn, warn: warn)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.composite.GenerateFlux()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if exp, got := tt.script, s; exp != got {
+				t.Errorf("expected:\n%v\n\ngot:\n%v\n", exp, got)
+			}
+		})
+	}
+}
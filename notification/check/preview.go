@@ -0,0 +1,179 @@
+package check
+
+import (
+	"encoding/csv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+const (
+	monitorPackage = "influxdata/influxdb/monitor"
+	csvPackage     = "csv"
+)
+
+// GeneratePreviewFlux returns the flux script of c with the monitor.write
+// option overridden to pass the statuses of the check through, so that the
+// statuses are returned by the script instead of written to the monitoring
+// bucket. The script of a stateful threshold check reads the statuses of its
+// previous evaluations with monitor.from, which is replaced to read previous,
+// the statuses the previous evaluations of the preview returned, instead.
+func GeneratePreviewFlux(c influxdb.Check, previous []*influxdb.AlertStatus) (string, error) {
+	script, err := c.GenerateFlux()
+	if err != nil {
+		return "", err
+	}
+
+	p := parser.ParseSource(script)
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return "", multiError(errs)
+	}
+	if len(p.Files) != 1 {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "expect a single file to be returned from query parsing",
+		}
+	}
+
+	f := p.Files[0]
+	if !importsPackage(f, monitorPackage) {
+		f.Imports = append(f.Imports, flux.ImportDeclaration(monitorPackage))
+	}
+	if t, ok := c.(*Threshold); ok && t.Stateful() {
+		statuses, err := previewStatusesCSV(previous)
+		if err != nil {
+			return "", err
+		}
+		for _, st := range f.Body {
+			if v, ok := st.(*ast.VariableAssignment); ok {
+				v.Init = replaceMonitorFrom(v.Init, statuses)
+			}
+		}
+		if !importsPackage(f, csvPackage) {
+			f.Imports = append(f.Imports, flux.ImportDeclaration(csvPackage))
+		}
+	}
+	f.Body = append([]ast.Statement{previewWriteOption()}, f.Body...)
+
+	return ast.Format(p), nil
+}
+
+// previewWriteOption returns option monitor.write = (tables=<-) => tables.
+func previewWriteOption() ast.Statement {
+	return &ast.OptionStatement{
+		Assignment: &ast.MemberAssignment{
+			Member: flux.Member("monitor", "write"),
+			Init:   flux.Function(flux.PipeFunctionParams("tables"), flux.Identifier("tables")),
+		},
+	}
+}
+
+// previewStatusesCSV returns previous as annotated CSV in a single table, with
+// the columns of statuses that stateful threshold checks read: their time,
+// check, level and tags.
+func previewStatusesCSV(previous []*influxdb.AlertStatus) (string, error) {
+	var keys []string
+	seen := map[string]bool{}
+	for _, st := range previous {
+		for _, tag := range st.Tags {
+			if !seen[tag.Key] {
+				seen[tag.Key] = true
+				keys = append(keys, tag.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	datatypes := []string{"#datatype", "string", "long", "dateTime:RFC3339Nano", "string", "string"}
+	groups := []string{"#group", "false", "false", "false", "false", "false"}
+	defaults := []string{"#default", "_result", "", "", "", ""}
+	columns := []string{"", "result", "table", "_time", "_check_id", "_level"}
+	for _, k := range keys {
+		datatypes = append(datatypes, "string")
+		groups = append(groups, "false")
+		defaults = append(defaults, "")
+		columns = append(columns, k)
+	}
+	rows := [][]string{datatypes, groups, defaults, columns}
+	if len(previous) == 0 {
+		// a table needs a row, which is out of the range of any check.
+		rows = append(rows, []string{"", "", "0", time.Unix(0, 0).UTC().Format(time.RFC3339Nano), "", ""})
+	}
+	for _, st := range previous {
+		row := []string{"", "", "0", st.Time.UTC().Format(time.RFC3339Nano), st.CheckID.String(), strings.ToLower(st.Level)}
+		for _, k := range keys {
+			var v string
+			for _, tag := range st.Tags {
+				if tag.Key == k {
+					v = tag.Value
+				}
+			}
+			row = append(row, v)
+		}
+		rows = append(rows, row)
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// replaceMonitorFrom replaces the calls to monitor.from at the start of the
+// pipes of e with csv.from(csv: statuses) |> range(start) |> filter(fn), with
+// the start and fn of the call.
+func replaceMonitorFrom(e ast.Expression, statuses string) ast.Expression {
+	switch e := e.(type) {
+	case *ast.PipeExpression:
+		e.Argument = replaceMonitorFrom(e.Argument, statuses)
+	case *ast.CallExpression:
+		m, ok := e.Callee.(*ast.MemberExpression)
+		if !ok || len(e.Arguments) != 1 {
+			return e
+		}
+		obj, ok := m.Object.(*ast.Identifier)
+		if !ok || obj.Name != "monitor" || m.Property.Key() != "from" {
+			return e
+		}
+		args, ok := e.Arguments[0].(*ast.ObjectExpression)
+		if !ok {
+			return e
+		}
+
+		var start, fn ast.Expression
+		for _, p := range args.Properties {
+			switch p.Key.Key() {
+			case "start":
+				start = p.Value
+			case "fn":
+				fn = p.Value
+			}
+		}
+		if start == nil {
+			return e
+		}
+
+		calls := []*ast.CallExpression{flux.Call(flux.Identifier("range"), flux.Object(flux.Property("start", start)))}
+		if fn != nil {
+			calls = append(calls, flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", fn))))
+		}
+		return flux.Pipe(flux.Call(flux.Member("csv", "from"), flux.Object(flux.Property("csv", flux.String(statuses)))), calls...)
+	}
+	return e
+}
+
+func importsPackage(f *ast.File, pkg string) bool {
+	for _, imp := range f.Imports {
+		if imp.Path.Value == pkg {
+			return true
+		}
+	}
+	return false
+}
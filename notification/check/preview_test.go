@@ -0,0 +1,135 @@
+package check_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/check"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePreviewFlux(t *testing.T) {
+	c := &check.Threshold{
+		Base: check.Base{
+			ID:                    10,
+			Name:                  "moo",
+			Every:                 mustDuration("1h"),
+			StatusMessageTemplate: "whoa! {r.usage_user}",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "usage_user")`,
+			},
+		},
+		Thresholds: []check.ThresholdConfig{
+			check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{
+					Level: notification.Critical,
+				},
+				Value: 40,
+			},
+		},
+	}
+
+	script, err := check.GeneratePreviewFlux(c, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+
+option monitor.write = (tables=<-) =>
+	(tables)
+
+data = from(bucket: "foo")
+	|> range(start: -1h)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+
+option task = {name: "moo", every: 1h}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "threshold",
+	tags: {},
+}
+crit = (r) =>
+	(r.usage_user > 40.0)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+
+data
+	|> v1.fieldsAsCols()
+	|> monitor.check(data: check, messageFn: messageFn, crit: crit)`
+	assert.Equal(t, want, script)
+
+	t.Run("invalid query", func(t *testing.T) {
+		c := &check.Threshold{
+			Base: check.Base{
+				Name:  "moo",
+				Every: mustDuration("1h"),
+				Query: influxdb.DashboardQuery{Text: `from(bucket: "foo") |> range(start: -1d`},
+			},
+		}
+		if _, err := check.GeneratePreviewFlux(c, nil); err == nil {
+			t.Fatal("expected an error for the invalid query")
+		}
+	})
+
+	t.Run("stateful threshold", func(t *testing.T) {
+		exit := 30.0
+		c := &check.Threshold{
+			Base: c.Base,
+			Thresholds: []check.ThresholdConfig{
+				check.Greater{
+					ThresholdConfigBase: check.ThresholdConfigBase{
+						Level: notification.Critical,
+					},
+					Value:     40,
+					ExitValue: &exit,
+				},
+			},
+		}
+		previous := []*influxdb.AlertStatus{{
+			Time:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			CheckID: 10,
+			Level:   "CRIT",
+			Tags:    []influxdb.Tag{{Key: "host", Value: "a,b"}},
+		}}
+		script, err := check.GeneratePreviewFlux(c, previous)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := `previous = csv.from(csv: "#datatype,string,long,dateTime:RFC3339Nano,string,string,string
+#group,false,false,false,false,false,false
+#default,_result,,,,,
+,result,table,_time,_check_id,_level,host
+,,0,2020-01-01T00:00:00Z,000000000000000a,crit,\"a,b\"
+")
+	|> range(start: -2h0m0s)
+	|> filter(fn: (r) =>
+		(r._check_id == "000000000000000a"))
+	|> drop(`
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected the previous statuses to be read from the preview, got:\n%s", script)
+		}
+		if strings.Contains(script, "monitor.from") || !strings.Contains(script, `import "csv"`) {
+			t.Fatalf("expected the monitoring bucket not to be read, got:\n%s", script)
+		}
+
+		t.Run("without previous statuses", func(t *testing.T) {
+			script, err := check.GeneratePreviewFlux(c, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(script, ",result,table,_time,_check_id,_level\n,,0,1970-01-01T00:00:00Z,,\n") {
+				t.Fatalf("expected a table of no statuses of the check, got:\n%s", script)
+			}
+		})
+	})
+}
@@ -3,7 +3,9 @@ package check
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/parser"
@@ -18,6 +20,12 @@ var _ influxdb.Check = (*Threshold)(nil)
 type Threshold struct {
 	Base
 	Thresholds []ThresholdConfig `json:"thresholds"`
+	// For is the duration the condition of a level must hold before a series
+	// enters that level.
+	For *notification.Duration `json:"for,omitempty"`
+	// ForEvaluations is the number of consecutive evaluations the condition
+	// of a level must hold at before a series enters that level.
+	ForEvaluations int `json:"forEvaluations,omitempty"`
 }
 
 // Type returns the type of the check.
@@ -30,6 +38,30 @@ func (t Threshold) Valid() error {
 	if err := t.Base.Valid(); err != nil {
 		return err
 	}
+	if t.For != nil && len(t.For.Values) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check For can't be empty",
+		}
+	}
+	if t.For != nil && t.For.TimeDuration() < t.Every.TimeDuration() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check For must be at least its Every",
+		}
+	}
+	if t.ForEvaluations < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check ForEvaluations can't be negative",
+		}
+	}
+	if t.For != nil && t.ForEvaluations != 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check For and ForEvaluations can't both be set",
+		}
+	}
 	for _, cc := range t.Thresholds {
 		if err := cc.Valid(); err != nil {
 			return err
@@ -40,16 +72,21 @@ func (t Threshold) Valid() error {
 
 type thresholdDecode struct {
 	Base
-	Thresholds []thresholdConfigDecode `json:"thresholds"`
+	Thresholds     []thresholdConfigDecode `json:"thresholds"`
+	For            *notification.Duration  `json:"for"`
+	ForEvaluations int                     `json:"forEvaluations"`
 }
 
 type thresholdConfigDecode struct {
 	ThresholdConfigBase
-	Type   string  `json:"type"`
-	Value  float64 `json:"value"`
-	Min    float64 `json:"min"`
-	Max    float64 `json:"max"`
-	Within bool    `json:"within"`
+	Type      string   `json:"type"`
+	Value     float64  `json:"value"`
+	ExitValue *float64 `json:"exitValue"`
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+	ExitMin   *float64 `json:"exitMin"`
+	ExitMax   *float64 `json:"exitMax"`
+	Within    bool     `json:"within"`
 }
 
 // UnmarshalJSON implement json.Unmarshaler interface.
@@ -59,38 +96,49 @@ func (t *Threshold) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	t.Base = tdRaws.Base
+	t.For = tdRaws.For
+	t.ForEvaluations = tdRaws.ForEvaluations
 	for _, tdRaw := range tdRaws.Thresholds {
-		switch tdRaw.Type {
-		case "lesser":
-			td := &Lesser{
-				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
-				Value:               tdRaw.Value,
-			}
-			t.Thresholds = append(t.Thresholds, td)
-		case "greater":
-			td := &Greater{
-				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
-				Value:               tdRaw.Value,
-			}
-			t.Thresholds = append(t.Thresholds, td)
-		case "range":
-			td := &Range{
-				ThresholdConfigBase: tdRaw.ThresholdConfigBase,
-				Min:                 tdRaw.Min,
-				Max:                 tdRaw.Max,
-				Within:              tdRaw.Within,
-			}
-			t.Thresholds = append(t.Thresholds, td)
-		default:
-			return &influxdb.Error{
-				Msg: fmt.Sprintf("invalid threshold type %s", tdRaw.Type),
-			}
+		td, err := tdRaw.thresholdConfig()
+		if err != nil {
+			return err
 		}
+		t.Thresholds = append(t.Thresholds, td)
 	}
 
 	return nil
 }
 
+func (tdRaw thresholdConfigDecode) thresholdConfig() (ThresholdConfig, error) {
+	switch tdRaw.Type {
+	case "lesser":
+		return &Lesser{
+			ThresholdConfigBase: tdRaw.ThresholdConfigBase,
+			Value:               tdRaw.Value,
+			ExitValue:           tdRaw.ExitValue,
+		}, nil
+	case "greater":
+		return &Greater{
+			ThresholdConfigBase: tdRaw.ThresholdConfigBase,
+			Value:               tdRaw.Value,
+			ExitValue:           tdRaw.ExitValue,
+		}, nil
+	case "range":
+		return &Range{
+			ThresholdConfigBase: tdRaw.ThresholdConfigBase,
+			Min:                 tdRaw.Min,
+			Max:                 tdRaw.Max,
+			ExitMin:             tdRaw.ExitMin,
+			ExitMax:             tdRaw.ExitMax,
+			Within:              tdRaw.Within,
+		}, nil
+	default:
+		return nil, &influxdb.Error{
+			Msg: fmt.Sprintf("invalid threshold type %s", tdRaw.Type),
+		}
+	}
+}
+
 func multiError(errs []error) error {
 	var b strings.Builder
 
@@ -263,10 +311,33 @@ func (t Threshold) generateFluxASTBody(field string) []ast.Statement {
 	statements = append(statements, t.generateFluxASTCheckDefinition("threshold"))
 	statements = append(statements, t.generateFluxASTThresholdFunctions(field)...)
 	statements = append(statements, t.generateFluxASTMessageFunction())
+	if t.Stateful() {
+		statements = append(statements, t.generateFluxASTState(field)...)
+		statements = append(statements, t.generateFluxASTStatefulChecksFunction(field))
+		return statements
+	}
 	statements = append(statements, t.generateFluxASTChecksFunction())
 	return statements
 }
 
+// Stateful reports whether the level of a series depends on the previous
+// statuses of the check, which is the case when the check has a for, in a
+// duration or in evaluations, or a threshold with exit values.
+func (t Threshold) Stateful() bool {
+	return t.For != nil || t.ForEvaluations > 0 || t.hasHysteresis()
+}
+
+// hasHysteresis returns whether a threshold of the check has exit values, in
+// which case the level of each series depends on its previous level.
+func (t Threshold) hasHysteresis() bool {
+	for _, c := range t.Thresholds {
+		if c.generateFluxASTExitExpression("") != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (t Threshold) generateFluxASTChecksFunction() ast.Statement {
 	return flux.ExpressionStatement(flux.Pipe(
 		flux.Identifier("data"),
@@ -275,6 +346,197 @@ func (t Threshold) generateFluxASTChecksFunction() ast.Statement {
 	))
 }
 
+// generateFluxASTState defines previous, the statuses of each series read
+// from the monitoring bucket with their level as the previous level, series,
+// a row of each series of the data with an empty previous level so that the
+// column of the previous level exists for every series, and current, the data
+// of the check marked as current. Every row is marked as current or not, as
+// null booleans don't survive being sorted.
+func (t Threshold) generateFluxASTState(field string) []ast.Statement {
+	var tags []ast.Expression
+	for _, tag := range t.Tags {
+		tags = append(tags, flux.String(tag.Key))
+	}
+
+	// the columns of the check are dropped, as are the tags it sets on its
+	// statuses, which the data doesn't have.
+	column := flux.Identifier("column")
+	var dropped ast.Expression = flux.And(
+		&ast.BinaryExpression{Operator: ast.RegexpMatchOperator, Left: column, Right: &ast.RegexpLiteral{Value: regexp.MustCompile("^_")}},
+		flux.And(flux.NotEqual(column, flux.String("_time")), flux.NotEqual(column, flux.String("_level"))),
+	)
+	if len(tags) > 0 {
+		dropped = flux.Or(dropped, flux.Call(flux.Identifier("contains"), flux.Object(
+			flux.Property("value", column),
+			flux.Property("set", flux.Array(tags...)),
+		)))
+	}
+	previous := flux.Pipe(
+		flux.Call(flux.Member("monitor", "from"), flux.Object(
+			flux.Property("start", flux.Negative(t.lookback())),
+			flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.Equal(flux.Member("r", "_check_id"), flux.String(t.ID.String())))),
+		)),
+		flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("column"), dropped)))),
+		flux.Call(flux.Identifier("rename"), flux.Object(flux.Property("columns", flux.Object(
+			flux.Property("_level", flux.String(previousLevelColumn)),
+		)))),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+			flux.Property(currentColumn, flux.Bool(false)),
+		))))),
+	)
+
+	series := flux.Pipe(
+		flux.Identifier("data"),
+		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		flux.Call(flux.Identifier("last"), flux.Object(flux.Property("column", flux.String("_time")))),
+		flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("columns", flux.Array(flux.String(field))))),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+			flux.Property("_time", flux.Time(time.Unix(0, 0).UTC())),
+			flux.Property(previousLevelColumn, flux.String("")),
+			flux.Property(currentColumn, flux.Bool(false)),
+		))))),
+	)
+
+	current := flux.Pipe(
+		flux.Identifier("data"),
+		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+			flux.Property(currentColumn, flux.Bool(true)),
+		))))),
+	)
+
+	return []ast.Statement{
+		flux.DefineVariable("previous", previous),
+		flux.DefineVariable("series", series),
+		flux.DefineVariable("current", current),
+	}
+}
+
+// lookback returns how far back the previous statuses of the check are read:
+// the evaluations a condition must hold at, or its for, and the evaluation
+// before, but at least two evaluations, so that the last status of a series
+// is read when an evaluation runs late.
+func (t Threshold) lookback() *ast.DurationLiteral {
+	every := t.Every.TimeDuration()
+	d := 2 * every
+	if n := time.Duration(t.ForEvaluations) * every; n > d {
+		d = n
+	}
+	if t.For != nil && t.For.TimeDuration()+every > d {
+		d = t.For.TimeDuration() + every
+	}
+	lookback, _ := notification.FromTimeDuration(d)
+	return (*ast.DurationLiteral)(&lookback)
+}
+
+// generateFluxASTStatefulChecksFunction merges the data of the check with its
+// previous statuses by series, tracks how long the condition of each level has
+// held, and keeps the current row of each series with its previous level. The
+// level of each series is set before the check is called, so that the columns
+// of the state are dropped instead of written with the statuses; the check
+// only copies it. The statuses are grouped by the columns of their series only
+// to be merged, and the group key of the data is restored afterwards.
+func (t Threshold) generateFluxASTStatefulChecksFunction(field string) ast.Statement {
+	stateColumns := t.stateColumns()
+	calls := []*ast.CallExpression{
+		flux.Call(flux.Identifier("group"), flux.Object(
+			flux.Property("columns", flux.Array(append([]ast.Expression{flux.String("_start"), flux.String("_stop"), flux.String("_measurement"), flux.String("_time"), flux.String(field)}, stateColumns...)...)),
+			flux.Property("mode", flux.String("except")),
+		)),
+		flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", flux.Array(flux.String("_time"))))),
+		flux.Call(flux.Identifier("fill"), flux.Object(
+			flux.Property("column", flux.String(previousLevelColumn)),
+			flux.Property("usePrevious", flux.Bool(true)),
+		)),
+		flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.Exists(flux.Member("r", field)))))),
+	}
+	for _, c := range t.Thresholds {
+		col := t.countColumn(strings.ToLower(c.GetLevel().String()))
+		if col == "" {
+			continue
+		}
+		fn := "stateCount"
+		if t.For != nil {
+			fn = "stateDuration"
+		}
+		calls = append(calls, flux.Call(flux.Identifier(fn), flux.Object(
+			flux.Property("fn", flux.Function(flux.FunctionParams("r"), c.generateFluxASTThresholdExpression(field))),
+			flux.Property("column", flux.String(col)),
+		)))
+	}
+	calls = append(calls,
+		flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.Member("r", currentColumn))))),
+		flux.Call(flux.Identifier("last"), flux.Object(flux.Property("column", flux.String(field)))),
+		flux.Call(flux.Identifier("group"), flux.Object(
+			flux.Property("columns", flux.Array(append([]ast.Expression{flux.String("_time"), flux.String(field)}, stateColumns...)...)),
+			flux.Property("mode", flux.String("except")),
+		)),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+			flux.Property("_level", t.generateFluxASTLevelExpression()),
+		))))),
+		flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("columns", flux.Array(stateColumns...)))),
+		t.generateFluxASTLevelChecksCall(),
+	)
+
+	return flux.ExpressionStatement(flux.Pipe(
+		flux.Call(flux.Identifier("union"), flux.Object(flux.Property("tables", flux.Array(
+			flux.Identifier("series"), flux.Identifier("previous"), flux.Identifier("current"),
+		)))),
+		calls...,
+	))
+}
+
+// stateColumns returns the columns of the state of the check.
+func (t Threshold) stateColumns() []ast.Expression {
+	columns := []ast.Expression{flux.String(previousLevelColumn), flux.String(currentColumn)}
+	for _, c := range t.Thresholds {
+		if col := t.countColumn(strings.ToLower(c.GetLevel().String())); col != "" {
+			columns = append(columns, flux.String(col))
+		}
+	}
+	return columns
+}
+
+// thresholdLevels are the levels of thresholds in the order monitor.check
+// tries them.
+var thresholdLevels = []notification.CheckLevel{notification.Critical, notification.Warn, notification.Info, notification.Ok}
+
+// generateFluxASTLevelExpression returns the level of a series, the first
+// level whose function holds, as monitor.check would set it.
+func (t Threshold) generateFluxASTLevelExpression() ast.Expression {
+	configured := map[notification.CheckLevel]bool{}
+	for _, c := range t.Thresholds {
+		configured[c.GetLevel()] = true
+	}
+
+	var e ast.Expression = flux.String("ok")
+	if configured[notification.Ok] {
+		e = flux.String("unknown")
+	}
+	for i := len(thresholdLevels) - 1; i >= 0; i-- {
+		if !configured[thresholdLevels[i]] {
+			continue
+		}
+		lvl := strings.ToLower(thresholdLevels[i].String())
+		e = flux.If(flux.Call(flux.Identifier(lvl), flux.Object(flux.Property("r", flux.Identifier("r")))), flux.String(lvl), e)
+	}
+	return e
+}
+
+// generateFluxASTLevelChecksCall calls monitor.check with functions that
+// keep the level the series were set to.
+func (t Threshold) generateFluxASTLevelChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	for _, c := range t.Thresholds {
+		lvl := strings.ToLower(c.GetLevel().String())
+		objectProps = append(objectProps, flux.Property(lvl, flux.Function(flux.FunctionParams("r"), flux.Equal(flux.Member("r", "_level"), flux.String(lvl)))))
+	}
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
 func (t Threshold) generateFluxASTChecksCall() *ast.CallExpression {
 	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
 	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
@@ -293,46 +555,114 @@ func (t Threshold) generateFluxASTThresholdFunctions(field string) []ast.Stateme
 
 	// This assumes that the ThresholdConfigs we've been provided do not have duplicates.
 	for k, v := range t.Thresholds {
-		thresholdStatements[k] = v.generateFluxASTThresholdFunction(field)
+		if !t.Stateful() {
+			thresholdStatements[k] = v.generateFluxASTThresholdFunction(field)
+			continue
+		}
+		thresholdStatements[k] = t.statefulThresholdFunction(v, field)
 	}
 	return thresholdStatements
 }
 
+// statefulThresholdFunction defines the function of the level of the
+// threshold, which holds once the condition of the level has held for the for
+// of the check, or while a series at the level meets its exit value.
+func (t Threshold) statefulThresholdFunction(td ThresholdConfig, field string) ast.Statement {
+	lvl := strings.ToLower(td.GetLevel().String())
+
+	e := td.generateFluxASTThresholdExpression(field)
+	switch {
+	case t.For != nil:
+		e = flux.GreaterThanEqual(flux.Member("r", t.countColumn(lvl)), flux.Integer(int64(t.For.TimeDuration()/time.Second)))
+	case t.ForEvaluations > 0:
+		e = flux.GreaterThanEqual(flux.Member("r", t.countColumn(lvl)), flux.Integer(int64(t.ForEvaluations)))
+	}
+	if exit := td.generateFluxASTExitExpression(field); exit != nil {
+		e = flux.Or(e, flux.And(flux.Equal(flux.Member("r", previousLevelColumn), flux.String(lvl)), exit))
+	}
+
+	return flux.DefineVariable(lvl, flux.Function(flux.FunctionParams("r"), e))
+}
+
+// columns of the state of stateful threshold checks, which are not written
+// with the statuses.
+const (
+	previousLevelColumn = "_previous_level"
+	currentColumn       = "_current"
+)
+
+// countColumn returns the column of how long the condition of a level has
+// held, in seconds with a for and in evaluations with a for in evaluations,
+// or "" if the check has neither.
+func (t Threshold) countColumn(lvl string) string {
+	switch {
+	case t.For != nil:
+		return "_" + lvl + "_duration"
+	case t.ForEvaluations > 0:
+		return "_" + lvl + "_count"
+	}
+	return ""
+}
+
 func (td Greater) generateFluxASTThresholdFunction(field string) ast.Statement {
-	fnBody := flux.GreaterThan(flux.Member("r", field), flux.Float(td.Value))
-	fn := flux.Function(flux.FunctionParams("r"), fnBody)
+	return thresholdFunction(td, field)
+}
 
-	lvl := strings.ToLower(td.Level.String())
+func (td Greater) generateFluxASTThresholdExpression(field string) ast.Expression {
+	return flux.GreaterThan(flux.Member("r", field), flux.Float(td.Value))
+}
 
-	return flux.DefineVariable(lvl, fn)
+func (td Greater) generateFluxASTExitExpression(field string) ast.Expression {
+	if td.ExitValue == nil {
+		return nil
+	}
+	return flux.GreaterThan(flux.Member("r", field), flux.Float(*td.ExitValue))
 }
 
 func (td Lesser) generateFluxASTThresholdFunction(field string) ast.Statement {
-	fnBody := flux.LessThan(flux.Member("r", field), flux.Float(td.Value))
-	fn := flux.Function(flux.FunctionParams("r"), fnBody)
+	return thresholdFunction(td, field)
+}
 
-	lvl := strings.ToLower(td.Level.String())
+func (td Lesser) generateFluxASTThresholdExpression(field string) ast.Expression {
+	return flux.LessThan(flux.Member("r", field), flux.Float(td.Value))
+}
 
-	return flux.DefineVariable(lvl, fn)
+func (td Lesser) generateFluxASTExitExpression(field string) ast.Expression {
+	if td.ExitValue == nil {
+		return nil
+	}
+	return flux.LessThan(flux.Member("r", field), flux.Float(*td.ExitValue))
 }
 
 func (td Range) generateFluxASTThresholdFunction(field string) ast.Statement {
-	var fnBody *ast.LogicalExpression
+	return thresholdFunction(td, field)
+}
+
+func (td Range) generateFluxASTThresholdExpression(field string) ast.Expression {
 	if !td.Within {
-		fnBody = flux.Or(
+		return flux.Or(
 			flux.LessThan(flux.Member("r", field), flux.Float(td.Min)),
 			flux.GreaterThan(flux.Member("r", field), flux.Float(td.Max)),
 		)
-	} else {
-		fnBody = flux.And(
-			flux.LessThan(flux.Member("r", field), flux.Float(td.Max)),
-			flux.GreaterThan(flux.Member("r", field), flux.Float(td.Min)),
-		)
 	}
+	return flux.And(
+		flux.LessThan(flux.Member("r", field), flux.Float(td.Max)),
+		flux.GreaterThan(flux.Member("r", field), flux.Float(td.Min)),
+	)
+}
 
-	fn := flux.Function(flux.FunctionParams("r"), fnBody)
+func (td Range) generateFluxASTExitExpression(field string) ast.Expression {
+	if td.ExitMin == nil || td.ExitMax == nil {
+		return nil
+	}
+	return Range{Min: *td.ExitMin, Max: *td.ExitMax, Within: td.Within}.generateFluxASTThresholdExpression(field)
+}
 
-	lvl := strings.ToLower(td.Level.String())
+// thresholdFunction defines the function of the level of the threshold.
+func thresholdFunction(td ThresholdConfig, field string) ast.Statement {
+	fn := flux.Function(flux.FunctionParams("r"), td.generateFluxASTThresholdExpression(field))
+
+	lvl := strings.ToLower(td.GetLevel().String())
 
 	return flux.DefineVariable(lvl, fn)
 }
@@ -357,6 +687,10 @@ type ThresholdConfig interface {
 	Valid() error
 	Type() string
 	generateFluxASTThresholdFunction(string) ast.Statement
+	generateFluxASTThresholdExpression(string) ast.Expression
+	// generateFluxASTExitExpression returns the condition a series at the
+	// level must meet to stay at it, nil if the threshold has no exit value.
+	generateFluxASTExitExpression(string) ast.Expression
 	GetLevel() notification.CheckLevel
 }
 
@@ -381,6 +715,9 @@ func (b ThresholdConfigBase) GetLevel() notification.CheckLevel {
 type Lesser struct {
 	ThresholdConfigBase
 	Value float64 `json:"value,omitempty"`
+	// ExitValue is the value a series at the level must stay lesser than to
+	// stay at it, once it went lesser than Value.
+	ExitValue *float64 `json:"exitValue,omitempty"`
 }
 
 // Type of the threshold config.
@@ -405,6 +742,9 @@ func (td Lesser) MarshalJSON() ([]byte, error) {
 type Greater struct {
 	ThresholdConfigBase
 	Value float64 `json:"value,omitempty"`
+	// ExitValue is the value a series at the level must stay greater than to
+	// stay at it, once it went greater than Value.
+	ExitValue *float64 `json:"exitValue,omitempty"`
 }
 
 // Type of the threshold config.
@@ -428,9 +768,13 @@ func (td Greater) MarshalJSON() ([]byte, error) {
 // Range threshold type.
 type Range struct {
 	ThresholdConfigBase
-	Min    float64 `json:"min,omitempty"`
-	Max    float64 `json:"max,omitempty"`
-	Within bool    `json:"within"`
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+	// ExitMin and ExitMax are the range a series at the level must stay
+	// within, or outside of, to stay at it.
+	ExitMin *float64 `json:"exitMin,omitempty"`
+	ExitMax *float64 `json:"exitMax,omitempty"`
+	Within  bool     `json:"within"`
 }
 
 // Type of the threshold config.
@@ -459,5 +803,54 @@ func (td Range) Valid() error {
 			Msg:  "range threshold min can't be larger than max",
 		}
 	}
+	if (td.ExitMin == nil) != (td.ExitMax == nil) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "range threshold exit min and exit max must be set together",
+		}
+	}
+	if td.ExitMin == nil {
+		return nil
+	}
+	if *td.ExitMin > *td.ExitMax {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "range threshold exit min can't be larger than exit max",
+		}
+	}
+	if td.Within && (*td.ExitMin > td.Min || *td.ExitMax < td.Max) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "range threshold exit range must contain the range",
+		}
+	}
+	if !td.Within && (*td.ExitMin < td.Min || *td.ExitMax > td.Max) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "range threshold exit range must be within the range",
+		}
+	}
+	return nil
+}
+
+// Valid overwrite the base threshold.
+func (td Greater) Valid() error {
+	if td.ExitValue != nil && *td.ExitValue > td.Value {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "greater threshold exit value can't be larger than value",
+		}
+	}
+	return nil
+}
+
+// Valid overwrite the base threshold.
+func (td Lesser) Valid() error {
+	if td.ExitValue != nil && *td.ExitValue < td.Value {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "lesser threshold exit value can't be smaller than value",
+		}
+	}
 	return nil
 }
@@ -20,6 +20,7 @@ func TestThreshold_GenerateFlux(t *testing.T) {
 
 	var l float64 = 10
 	var u float64 = 40
+	var exit float64 = 80
 
 	tests := []struct {
 		name  string
@@ -299,6 +300,265 @@ data
 	)`,
 			},
 		},
+		{
+			name: "for duration",
+			args: args{
+				threshold: check.Threshold{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("10m"),
+						StatusMessageTemplate: "whoa! {r.usage_user}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "usage_user")`,
+						},
+					},
+					For: mustDuration("20m"),
+					Thresholds: []check.ThresholdConfig{
+						check.Greater{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Critical,
+							},
+							Value: 90,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+
+data = from(bucket: "foo")
+	|> range(start: -10m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+
+option task = {name: "moo", every: 10m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "threshold",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(r._crit_duration >= 1200)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+previous = monitor.from(start: -30m0s, fn: (r) =>
+	(r._check_id == "000000000000000a"))
+	|> drop(fn: (column) =>
+		(column =~ /^_/ and (column != "_time" and column != "_level") or contains(value: column, set: ["aaa"])))
+	|> rename(columns: {_level: "_previous_level"})
+	|> map(fn: (r) =>
+		({r with _current: false}))
+series = data
+	|> v1.fieldsAsCols()
+	|> last(column: "_time")
+	|> drop(columns: ["usage_user"])
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _previous_level: "", _current: false}))
+current = data
+	|> v1.fieldsAsCols()
+	|> map(fn: (r) =>
+		({r with _current: true}))
+
+union(tables: [series, previous, current])
+	|> group(columns: ["_start", "_stop", "_measurement", "_time", "usage_user", "_previous_level", "_current", "_crit_duration"], mode: "except")
+	|> sort(columns: ["_time"])
+	|> fill(column: "_previous_level", usePrevious: true)
+	|> filter(fn: (r) =>
+		(exists r.usage_user))
+	|> stateDuration(fn: (r) =>
+		(r.usage_user > 90.0), column: "_crit_duration")
+	|> filter(fn: (r) =>
+		(r._current))
+	|> last(column: "usage_user")
+	|> group(columns: ["_time", "usage_user", "_previous_level", "_current", "_crit_duration"], mode: "except")
+	|> map(fn: (r) =>
+		({r with _level: if crit(r: r) then "crit" else "ok"}))
+	|> drop(columns: ["_previous_level", "_current", "_crit_duration"])
+	|> monitor.check(data: check, messageFn: messageFn, crit: (r) =>
+		(r._level == "crit"))`,
+			},
+		},
+		{
+			name: "for duration with exit value",
+			args: args{
+				threshold: check.Threshold{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("10m"),
+						StatusMessageTemplate: "whoa! {r.usage_user}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "usage_user")`,
+						},
+					},
+					For: mustDuration("20m"),
+					Thresholds: []check.ThresholdConfig{
+						check.Greater{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Critical,
+							},
+							Value:     90,
+							ExitValue: &exit,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+
+data = from(bucket: "foo")
+	|> range(start: -10m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+
+option task = {name: "moo", every: 10m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "threshold",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(r._crit_duration >= 1200 or r._previous_level == "crit" and r.usage_user > 80.0)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+previous = monitor.from(start: -30m0s, fn: (r) =>
+	(r._check_id == "000000000000000a"))
+	|> drop(fn: (column) =>
+		(column =~ /^_/ and (column != "_time" and column != "_level") or contains(value: column, set: ["aaa"])))
+	|> rename(columns: {_level: "_previous_level"})
+	|> map(fn: (r) =>
+		({r with _current: false}))
+series = data
+	|> v1.fieldsAsCols()
+	|> last(column: "_time")
+	|> drop(columns: ["usage_user"])
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _previous_level: "", _current: false}))
+current = data
+	|> v1.fieldsAsCols()
+	|> map(fn: (r) =>
+		({r with _current: true}))
+
+union(tables: [series, previous, current])
+	|> group(columns: ["_start", "_stop", "_measurement", "_time", "usage_user", "_previous_level", "_current", "_crit_duration"], mode: "except")
+	|> sort(columns: ["_time"])
+	|> fill(column: "_previous_level", usePrevious: true)
+	|> filter(fn: (r) =>
+		(exists r.usage_user))
+	|> stateDuration(fn: (r) =>
+		(r.usage_user > 90.0), column: "_crit_duration")
+	|> filter(fn: (r) =>
+		(r._current))
+	|> last(column: "usage_user")
+	|> group(columns: ["_time", "usage_user", "_previous_level", "_current", "_crit_duration"], mode: "except")
+	|> map(fn: (r) =>
+		({r with _level: if crit(r: r) then "crit" else "ok"}))
+	|> drop(columns: ["_previous_level", "_current", "_crit_duration"])
+	|> monitor.check(data: check, messageFn: messageFn, crit: (r) =>
+		(r._level == "crit"))`,
+			},
+		},
+		{
+			name: "for evaluations",
+			args: args{
+				threshold: check.Threshold{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+						},
+						Every:                 mustDuration("10m"),
+						StatusMessageTemplate: "whoa! {r.usage_user}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._field == "usage_user")`,
+						},
+					},
+					ForEvaluations: 3,
+					Thresholds: []check.ThresholdConfig{
+						check.Greater{
+							ThresholdConfigBase: check.ThresholdConfigBase{
+								Level: notification.Critical,
+							},
+							Value: 90,
+						},
+					},
+				},
+			},
+			wants: wants{
+				script: `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+
+data = from(bucket: "foo")
+	|> range(start: -10m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+
+option task = {name: "moo", every: 10m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "threshold",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(r._crit_count >= 3)
+messageFn = (r) =>
+	("whoa! {r.usage_user}")
+previous = monitor.from(start: -30m0s, fn: (r) =>
+	(r._check_id == "000000000000000a"))
+	|> drop(fn: (column) =>
+		(column =~ /^_/ and (column != "_time" and column != "_level") or contains(value: column, set: ["aaa"])))
+	|> rename(columns: {_level: "_previous_level"})
+	|> map(fn: (r) =>
+		({r with _current: false}))
+series = data
+	|> v1.fieldsAsCols()
+	|> last(column: "_time")
+	|> drop(columns: ["usage_user"])
+	|> map(fn: (r) =>
+		({r with _time: 1970-01-01T00:00:00Z, _previous_level: "", _current: false}))
+current = data
+	|> v1.fieldsAsCols()
+	|> map(fn: (r) =>
+		({r with _current: true}))
+
+union(tables: [series, previous, current])
+	|> group(columns: ["_start", "_stop", "_measurement", "_time", "usage_user", "_previous_level", "_current", "_crit_count"], mode: "except")
+	|> sort(columns: ["_time"])
+	|> fill(column: "_previous_level", usePrevious: true)
+	|> filter(fn: (r) =>
+		(exists r.usage_user))
+	|> stateCount(fn: (r) =>
+		(r.usage_user > 90.0), column: "_crit_count")
+	|> filter(fn: (r) =>
+		(r._current))
+	|> last(column: "usage_user")
+	|> group(columns: ["_time", "usage_user", "_previous_level", "_current", "_crit_count"], mode: "except")
+	|> map(fn: (r) =>
+		({r with _level: if crit(r: r) then "crit" else "ok"}))
+	|> drop(columns: ["_previous_level", "_current", "_crit_count"])
+	|> monitor.check(data: check, messageFn: messageFn, crit: (r) =>
+		(r._level == "crit"))`,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,106 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+)
+
+var _ influxdb.NotificationEndpoint = &Opsgenie{}
+
+const opsgenieAPIKeySuffix = "-api-key"
+
+// DefaultOpsgenieURL is the url of the Opsgenie alert API.
+const DefaultOpsgenieURL = "https://api.opsgenie.com/v2/alerts"
+
+// opsgenieMessageLength is the maximum length of the message of an alert.
+const opsgenieMessageLength = 130
+
+// OpsgeniePriority returns the priority of the Opsgenie alert of a level,
+// from P1 for crit to P5 for info and ok.
+func OpsgeniePriority(level notification.CheckLevel) string {
+	switch level {
+	case notification.Critical:
+		return "P1"
+	case notification.Warn:
+		return "P3"
+	}
+	return "P5"
+}
+
+// Opsgenie is the notification endpoint config of opsgenie.
+type Opsgenie struct {
+	Base
+	// URL is the url of the Opsgenie alert API, DefaultOpsgenieURL when
+	// empty. Accounts in the EU region use https://api.eu.opsgenie.com/v2/alerts.
+	URL string `json:"url,omitempty"`
+	// APIKey is the key of an API integration of Opsgenie.
+	APIKey influxdb.SecretField `json:"apiKey"`
+}
+
+// AlertURL returns the url of the Opsgenie alert API.
+func (s Opsgenie) AlertURL() string {
+	if s.URL == "" {
+		return DefaultOpsgenieURL
+	}
+	return s.URL
+}
+
+// BackfillSecretKeys fill back fill the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *Opsgenie) BackfillSecretKeys() {
+	if s.APIKey.Key == "" && s.APIKey.Value != nil {
+		s.APIKey.Key = s.idStr() + opsgenieAPIKeySuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s Opsgenie) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{
+		s.APIKey,
+	}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL != "" {
+		if _, err := url.Parse(s.URL); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("opsgenie endpoint URL is invalid: %s", err.Error()),
+			}
+		}
+	}
+	if s.APIKey.Key == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "opsgenie api key is invalid",
+		}
+	}
+	return nil
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Opsgenie) Type() string {
+	return OpsgenieType
+}
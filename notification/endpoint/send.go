@@ -0,0 +1,308 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+)
+
+// DefaultPagerDutyURL is the url of the PagerDuty events API.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Message is a notification that is sent to an endpoint outside of the
+// notification rules, which send their notifications from flux.
+type Message struct {
+	// Level is the level of the message, CRIT for a problem and OK once
+	// the problem is resolved.
+	Level notification.CheckLevel `json:"level"`
+	// Text is the text of the message.
+	Text string `json:"text"`
+	// Source is what the message is about, e.g. the name of a task.
+	Source string `json:"source"`
+	// DedupKey identifies the problem the message is about, so that the
+	// message that resolves the problem is matched with the message that
+	// reported it.
+	DedupKey string    `json:"dedupKey"`
+	Time     time.Time `json:"time"`
+}
+
+// Sender sends messages to notification endpoints, with the secrets of the
+// endpoints loaded from a SecretService.
+type Sender struct {
+	Client  *http.Client
+	Secrets influxdb.SecretService
+
+	// PagerDutyURL is the url of the PagerDuty events API.
+	PagerDutyURL string
+}
+
+// NewSender returns a Sender that loads the secrets of endpoints from secrets.
+func NewSender(secrets influxdb.SecretService) *Sender {
+	return &Sender{
+		Client:       &http.Client{Timeout: 30 * time.Second},
+		Secrets:      secrets,
+		PagerDutyURL: DefaultPagerDutyURL,
+	}
+}
+
+// Send sends a message to an endpoint.
+func (s *Sender) Send(ctx context.Context, e influxdb.NotificationEndpoint, msg Message) error {
+	if e.GetStatus() != influxdb.Active {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("notification endpoint %s is inactive", e.GetID()),
+		}
+	}
+
+	switch e := e.(type) {
+	case *Slack:
+		return s.sendSlack(ctx, e, msg)
+	case *PagerDuty:
+		return s.sendPagerDuty(ctx, e, msg)
+	case *HTTP:
+		return s.sendHTTP(ctx, e, msg)
+	case *Teams:
+		return s.sendTeams(ctx, e, msg)
+	case *Opsgenie:
+		return s.sendOpsgenie(ctx, e, msg)
+	case *Telegram:
+		return s.sendTelegram(ctx, e, msg)
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("cannot send messages to notification endpoints of type %s", e.Type()),
+	}
+}
+
+func (s *Sender) sendSlack(ctx context.Context, e *Slack, msg Message) error {
+	color := "danger"
+	if msg.Level == notification.Ok {
+		color = "good"
+	}
+	body := map[string]interface{}{
+		"as_user": false,
+		"attachments": []map[string]interface{}{{
+			"color":     color,
+			"text":      msg.Text,
+			"mrkdwn_in": []string{"text"},
+		}},
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, e.URL, body)
+	if err != nil {
+		return err
+	}
+	if e.Token.Key != "" {
+		token, err := s.loadSecret(ctx, e.GetOrgID(), e.Token)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.do(req)
+}
+
+func (s *Sender) sendPagerDuty(ctx context.Context, e *PagerDuty, msg Message) error {
+	routingKey, err := s.loadSecret(ctx, e.GetOrgID(), e.RoutingKey)
+	if err != nil {
+		return err
+	}
+
+	action, severity := "trigger", "critical"
+	switch msg.Level {
+	case notification.Ok:
+		action, severity = "resolve", "info"
+	case notification.Info:
+		severity = "info"
+	case notification.Warn:
+		severity = "warning"
+	}
+	body := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": action,
+		"dedup_key":    msg.DedupKey,
+		"client":       "influxdata",
+		"client_url":   e.ClientURL,
+		"payload": map[string]interface{}{
+			"summary":   msg.Text,
+			"source":    msg.Source,
+			"severity":  severity,
+			"timestamp": msg.Time.Format(time.RFC3339Nano),
+		},
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, s.PagerDutyURL, body)
+	if err != nil {
+		return err
+	}
+	return s.do(req)
+}
+
+func (s *Sender) sendHTTP(ctx context.Context, e *HTTP, msg Message) error {
+	var body interface{} = msg
+	if e.Method == http.MethodGet {
+		body = nil
+	}
+	req, err := newJSONRequest(ctx, e.Method, e.URL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	switch e.AuthMethod {
+	case "basic":
+		username, err := s.loadSecret(ctx, e.GetOrgID(), e.Username)
+		if err != nil {
+			return err
+		}
+		password, err := s.loadSecret(ctx, e.GetOrgID(), e.Password)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(username, password)
+	case "bearer":
+		token, err := s.loadSecret(ctx, e.GetOrgID(), e.Token)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.do(req)
+}
+
+func (s *Sender) sendTeams(ctx context.Context, e *Teams, msg Message) error {
+	body := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": TeamsThemeColor(msg.Level),
+		"summary":    msg.Source,
+		"title":      msg.Source,
+		"text":       msg.Text,
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, e.URL, body)
+	if err != nil {
+		return err
+	}
+	return s.do(req)
+}
+
+func (s *Sender) sendOpsgenie(ctx context.Context, e *Opsgenie, msg Message) error {
+	apiKey, err := s.loadSecret(ctx, e.GetOrgID(), e.APIKey)
+	if err != nil {
+		return err
+	}
+
+	// the alert of a problem is closed once the problem is resolved.
+	u := e.AlertURL()
+	body := map[string]interface{}{
+		"source": msg.Source,
+	}
+	if msg.Level == notification.Ok {
+		u += "/" + url.PathEscape(msg.DedupKey) + "/close?identifierType=alias"
+		body["note"] = msg.Text
+	} else {
+		body["message"] = truncate(msg.Text, opsgenieMessageLength)
+		body["description"] = msg.Text
+		body["alias"] = msg.DedupKey
+		body["priority"] = OpsgeniePriority(msg.Level)
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "GenieKey "+apiKey)
+	return s.do(req)
+}
+
+func (s *Sender) sendTelegram(ctx context.Context, e *Telegram, msg Message) error {
+	token, err := s.loadSecret(ctx, e.GetOrgID(), e.Token)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"chat_id": e.ChatID,
+		"text":    msg.Text,
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, e.APIURL()+"/bot"+token+"/sendMessage", body)
+	if err != nil {
+		return err
+	}
+	return s.do(req)
+}
+
+// truncate returns the first n runes of text.
+func truncate(text string, n int) string {
+	if r := []rune(text); len(r) > n {
+		return string(r[:n])
+	}
+	return text
+}
+
+// loadSecret returns the value of a secret field of an endpoint.
+func (s *Sender) loadSecret(ctx context.Context, orgID influxdb.ID, f influxdb.SecretField) (string, error) {
+	if f.Value != nil {
+		return *f.Value, nil
+	}
+	if f.Key == "" {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification endpoint secret is missing",
+		}
+	}
+	return s.Secrets.LoadSecret(ctx, orgID, f.Key)
+}
+
+func (s *Sender) do(req *http.Request) error {
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to send notification",
+			Err:  err,
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("notification endpoint responded with status %d: %s", resp.StatusCode, body),
+		}
+	}
+	return nil
+}
+
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid notification endpoint url",
+			Err:  err,
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req.WithContext(ctx), nil
+}
@@ -0,0 +1,162 @@
+package endpoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+func TestSender_Send(t *testing.T) {
+	var (
+		req  *http.Request
+		body map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body = nil
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	secrets := mock.NewSecretService()
+	secrets.LoadSecretFn = func(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+		return k + "-value", nil
+	}
+	s := endpoint.NewSender(secrets)
+	s.PagerDutyURL = srv.URL
+
+	msg := endpoint.Message{
+		Level:    notification.Critical,
+		Text:     "task failed",
+		Source:   "task",
+		DedupKey: "0000000000000001",
+		Time:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("slack", func(t *testing.T) {
+		e := &endpoint.Slack{Base: goodBase, URL: srv.URL, Token: influxdb.SecretField{Key: "slack"}}
+		if err := s.Send(context.Background(), e, msg); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer slack-value" {
+			t.Fatalf("unexpected authorization %q", got)
+		}
+		attachment := body["attachments"].([]interface{})[0].(map[string]interface{})
+		if attachment["text"] != "task failed" || attachment["color"] != "danger" {
+			t.Fatalf("unexpected slack attachment %v", attachment)
+		}
+	})
+
+	t.Run("pagerduty", func(t *testing.T) {
+		e := &endpoint.PagerDuty{Base: goodBase, ClientURL: "http://localhost:9999", RoutingKey: influxdb.SecretField{Key: "pd"}}
+		ok := msg
+		ok.Level = notification.Ok
+		if err := s.Send(context.Background(), e, ok); err != nil {
+			t.Fatal(err)
+		}
+		if body["routing_key"] != "pd-value" || body["event_action"] != "resolve" || body["dedup_key"] != msg.DedupKey {
+			t.Fatalf("unexpected pagerduty event %v", body)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		e := &endpoint.HTTP{
+			Base:       goodBase,
+			URL:        srv.URL,
+			Method:     http.MethodPut,
+			AuthMethod: "basic",
+			Username:   influxdb.SecretField{Key: "user"},
+			Password:   influxdb.SecretField{Key: "pass"},
+			Headers:    map[string]string{"X-Custom": "custom"},
+		}
+		if err := s.Send(context.Background(), e, msg); err != nil {
+			t.Fatal(err)
+		}
+		if req.Method != http.MethodPut || req.Header.Get("X-Custom") != "custom" {
+			t.Fatalf("unexpected request %s %v", req.Method, req.Header)
+		}
+		if u, p, _ := req.BasicAuth(); u != "user-value" || p != "pass-value" {
+			t.Fatalf("unexpected basic auth %q:%q", u, p)
+		}
+		if body["text"] != "task failed" || body["level"] != "CRIT" {
+			t.Fatalf("unexpected message %v", body)
+		}
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		e := &endpoint.Teams{Base: goodBase, URL: srv.URL}
+		if err := s.Send(context.Background(), e, msg); err != nil {
+			t.Fatal(err)
+		}
+		if body["@type"] != "MessageCard" || body["text"] != "task failed" || body["themeColor"] != "A30200" {
+			t.Fatalf("unexpected teams card %v", body)
+		}
+	})
+
+	t.Run("opsgenie", func(t *testing.T) {
+		e := &endpoint.Opsgenie{Base: goodBase, URL: srv.URL + "/v2/alerts", APIKey: influxdb.SecretField{Key: "og"}}
+		if err := s.Send(context.Background(), e, msg); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); got != "GenieKey og-value" {
+			t.Fatalf("unexpected authorization %q", got)
+		}
+		if req.URL.Path != "/v2/alerts" || body["priority"] != "P1" || body["alias"] != msg.DedupKey {
+			t.Fatalf("unexpected opsgenie alert %s %v", req.URL, body)
+		}
+
+		ok := msg
+		ok.Level = notification.Ok
+		if err := s.Send(context.Background(), e, ok); err != nil {
+			t.Fatal(err)
+		}
+		if req.URL.Path != "/v2/alerts/"+msg.DedupKey+"/close" || req.URL.Query().Get("identifierType") != "alias" {
+			t.Fatalf("expected the alert to be closed, got %s", req.URL)
+		}
+	})
+
+	t.Run("telegram", func(t *testing.T) {
+		e := &endpoint.Telegram{Base: goodBase, URL: srv.URL, Token: influxdb.SecretField{Key: "bot"}, ChatID: "@alerts"}
+		if err := s.Send(context.Background(), e, msg); err != nil {
+			t.Fatal(err)
+		}
+		if req.URL.Path != "/botbot-value/sendMessage" || body["chat_id"] != "@alerts" || body["text"] != "task failed" {
+			t.Fatalf("unexpected telegram message %s %v", req.URL, body)
+		}
+	})
+
+	t.Run("inactive", func(t *testing.T) {
+		base := goodBase
+		base.Status = influxdb.Inactive
+		e := &endpoint.Slack{Base: base, URL: srv.URL}
+		if err := s.Send(context.Background(), e, msg); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected inactive endpoints to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestSender_SendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	s := endpoint.NewSender(mock.NewSecretService())
+	e := &endpoint.Slack{Base: goodBase, URL: srv.URL}
+	if err := s.Send(context.Background(), e, endpoint.Message{Text: "x"}); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected an unavailable error, got %v", err)
+	}
+}
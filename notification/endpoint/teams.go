@@ -0,0 +1,77 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+)
+
+var _ influxdb.NotificationEndpoint = &Teams{}
+
+// TeamsThemeColor returns the theme color of the Teams message card of a level.
+func TeamsThemeColor(level notification.CheckLevel) string {
+	switch level {
+	case notification.Critical:
+		return "A30200"
+	case notification.Warn:
+		return "DAA038"
+	}
+	return "2EB886"
+}
+
+// Teams is the notification endpoint config of Microsoft Teams.
+type Teams struct {
+	Base
+	// URL is the url of an incoming webhook of a Teams channel.
+	URL string `json:"url"`
+}
+
+// BackfillSecretKeys does nothing, the teams endpoint has no secret fields.
+func (s *Teams) BackfillSecretKeys() {}
+
+// SecretFields return available secret fields.
+func (s Teams) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Teams) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "teams endpoint URL must be provided",
+		}
+	}
+	if _, err := url.Parse(s.URL); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("teams endpoint URL is invalid: %s", err.Error()),
+		}
+	}
+	return nil
+}
+
+type teamsAlias Teams
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Teams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			teamsAlias
+			Type string `json:"type"`
+		}{
+			teamsAlias: teamsAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Teams) Type() string {
+	return TeamsType
+}
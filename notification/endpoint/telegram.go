@@ -0,0 +1,99 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.NotificationEndpoint = &Telegram{}
+
+const telegramTokenSuffix = "-token"
+
+// DefaultTelegramURL is the url of the Telegram bot API.
+const DefaultTelegramURL = "https://api.telegram.org"
+
+// Telegram is the notification endpoint config of telegram.
+type Telegram struct {
+	Base
+	// URL is the url of the Telegram bot API, DefaultTelegramURL when empty.
+	URL string `json:"url,omitempty"`
+	// Token is the token of the bot that sends the messages.
+	Token influxdb.SecretField `json:"token"`
+	// ChatID is the ID of the chat, or the @username of the channel, that
+	// the messages are sent to.
+	ChatID string `json:"chatID"`
+}
+
+// APIURL returns the url of the Telegram bot API, without a trailing slash.
+func (s Telegram) APIURL() string {
+	if s.URL == "" {
+		return DefaultTelegramURL
+	}
+	return strings.TrimSuffix(s.URL, "/")
+}
+
+// BackfillSecretKeys fill back fill the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *Telegram) BackfillSecretKeys() {
+	if s.Token.Key == "" && s.Token.Value != nil {
+		s.Token.Key = s.idStr() + telegramTokenSuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s Telegram) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{
+		s.Token,
+	}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Telegram) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL != "" {
+		if _, err := url.Parse(s.URL); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("telegram endpoint URL is invalid: %s", err.Error()),
+			}
+		}
+	}
+	if s.Token.Key == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "telegram bot token is invalid",
+		}
+	}
+	if s.ChatID == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "telegram chat ID must be provided",
+		}
+	}
+	return nil
+}
+
+type telegramAlias Telegram
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Telegram) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			telegramAlias
+			Type string `json:"type"`
+		}{
+			telegramAlias: telegramAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Telegram) Type() string {
+	return TelegramType
+}
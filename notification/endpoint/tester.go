@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/notification"
+)
+
+var _ influxdb.NotificationEndpointTestService = (*Tester)(nil)
+
+// Tester sends test messages to the notification endpoints of a
+// NotificationEndpointService with a Sender.
+type Tester struct {
+	endpoints influxdb.NotificationEndpointService
+	sender    *Sender
+
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewTester returns a Tester that sends the test messages of the endpoints
+// of endpoints with sender.
+func NewTester(endpoints influxdb.NotificationEndpointService, sender *Sender) *Tester {
+	return &Tester{
+		endpoints: endpoints,
+		sender:    sender,
+		Now:       time.Now,
+	}
+}
+
+// TestNotificationEndpoint sends a test message to the endpoint of id, as
+// the notifications of the server are sent, with the secrets of the endpoint.
+func (t *Tester) TestNotificationEndpoint(ctx context.Context, id influxdb.ID, test influxdb.NotificationEndpointTest) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := test.Valid(); err != nil {
+		return err
+	}
+
+	e, err := t.endpoints.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	level := notification.Info
+	if test.Level != "" {
+		level = notification.ParseCheckLevel(strings.ToUpper(test.Level))
+	}
+	text := test.Text
+	if text == "" {
+		text = fmt.Sprintf("This is a test notification from InfluxDB to the notification endpoint %q.", e.GetName())
+	}
+
+	msg := Message{
+		Level:    level,
+		Text:     text,
+		Source:   "influxdb",
+		DedupKey: "influxdb-test-" + id.String(),
+		Time:     t.Now().UTC(),
+	}
+	if err := t.sender.Send(ctx, e, msg); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpTestNotificationEndpoint,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package endpoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification/endpoint"
+)
+
+func TestTester_TestNotificationEndpoint(t *testing.T) {
+	var body map[string]interface{}
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	e := &endpoint.Slack{Base: goodBase, URL: srv.URL}
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		if id != *goodBase.ID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+		return e, nil
+	}
+	tester := endpoint.NewTester(endpoints, endpoint.NewSender(mock.NewSecretService()))
+
+	attachment := func() map[string]interface{} {
+		return body["attachments"].([]interface{})[0].(map[string]interface{})
+	}
+
+	t.Run("default message", func(t *testing.T) {
+		if err := tester.TestNotificationEndpoint(context.Background(), *goodBase.ID, influxdb.NotificationEndpointTest{}); err != nil {
+			t.Fatal(err)
+		}
+		want := `This is a test notification from InfluxDB to the notification endpoint "name1".`
+		if attachment()["text"] != want {
+			t.Fatalf("unexpected test message %v", body)
+		}
+	})
+
+	t.Run("level and text", func(t *testing.T) {
+		test := influxdb.NotificationEndpointTest{Level: "ok", Text: "hello"}
+		if err := tester.TestNotificationEndpoint(context.Background(), *goodBase.ID, test); err != nil {
+			t.Fatal(err)
+		}
+		if attachment()["text"] != "hello" || attachment()["color"] != "good" {
+			t.Fatalf("unexpected test message %v", body)
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		test := influxdb.NotificationEndpointTest{Level: "loud"}
+		if err := tester.TestNotificationEndpoint(context.Background(), *goodBase.ID, test); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+	})
+
+	t.Run("endpoint error", func(t *testing.T) {
+		status = http.StatusNotFound
+		defer func() { status = http.StatusOK }()
+
+		err := tester.TestNotificationEndpoint(context.Background(), *goodBase.ID, influxdb.NotificationEndpointTest{})
+		if influxdb.ErrorCode(err) != influxdb.EUnavailable {
+			t.Fatalf("expected the error of the endpoint, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+// Package escalation escalates the alerts of notification rules according to
+// their escalation policies.
+package escalation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+// Timeout is how long escalating the alerts of a rule after a run of its
+// task may take.
+const Timeout = time.Minute
+
+// Sender sends messages to notification endpoints.
+type Sender interface {
+	Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+}
+
+// Escalator escalates the alerts of a notification rule after each run of
+// its task. An alert is the state of a series that the rule escalates; its
+// escalation stops once the series recovers or the alert is acknowledged,
+// and pauses while a silence of its organization matches it.
+//
+// Each step of a policy is recorded before it is sent, so that it is sent at
+// most once for an alert, even when runs of the task finish at once.
+type Escalator struct {
+	rules       influxdb.NotificationRuleStore
+	policies    influxdb.EscalationPolicyService
+	escalations influxdb.EscalationService
+	alerts      influxdb.AlertService
+	silences    influxdb.SilenceService
+	endpoints   influxdb.NotificationEndpointService
+	sender      Sender
+
+	Now func() time.Time
+}
+
+// NewEscalator returns an Escalator that reads the alerts of rules from
+// alerts, keeps their escalations in escalations, and sends the steps of
+// their policies with sender unless silences mute them.
+func NewEscalator(
+	rules influxdb.NotificationRuleStore,
+	policies influxdb.EscalationPolicyService,
+	escalations influxdb.EscalationService,
+	alerts influxdb.AlertService,
+	silences influxdb.SilenceService,
+	endpoints influxdb.NotificationEndpointService,
+	sender Sender,
+) *Escalator {
+	return &Escalator{
+		rules:       rules,
+		policies:    policies,
+		escalations: escalations,
+		alerts:      alerts,
+		silences:    silences,
+		endpoints:   endpoints,
+		sender:      sender,
+		Now:         time.Now,
+	}
+}
+
+// Escalate escalates the alerts of the notification rule of a task. It does
+// nothing for the tasks of anything but notification rules.
+func (e *Escalator) Escalate(ctx context.Context, task *influxdb.Task) error {
+	if !rule.IsType(task.Type) {
+		return nil
+	}
+
+	nr, err := rule.FindByTask(ctx, e.rules, task)
+	if err != nil || nr == nil {
+		return err
+	}
+
+	escalations, err := e.escalations.FindEscalations(ctx, nr.GetID())
+	if err != nil {
+		return err
+	}
+
+	if nr.GetEscalationPolicyID() == nil {
+		// the policy was removed from the rule.
+		return e.stop(ctx, escalations)
+	}
+	policy, err := e.policies.FindEscalationPolicyByID(ctx, *nr.GetEscalationPolicyID())
+	if err != nil {
+		return err
+	}
+
+	// only the alerts that the rule may escalate are read.
+	states, _, err := e.alerts.FindAlertStates(ctx, nr.EscalatedAlerts())
+	if err != nil {
+		return err
+	}
+	orgID := nr.GetOrgID()
+	silences, _, err := e.silences.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]*influxdb.Escalation, len(escalations))
+	for _, esc := range escalations {
+		current[alertKey(esc.CheckID, esc.Tags)] = esc
+	}
+
+	now := e.Now().UTC()
+	var firstErr error
+	for _, st := range states {
+		if st.Acknowledgement != nil || !nr.Escalates(st.Tags, st.Level) {
+			continue
+		}
+
+		key := alertKey(st.CheckID, st.Tags)
+		esc, ok := current[key]
+		delete(current, key)
+		if silenced(silences, st, now) {
+			// the escalation resumes once the silence ends.
+			continue
+		}
+		if !ok {
+			esc = &influxdb.Escalation{
+				RuleID:   nr.GetID(),
+				PolicyID: policy.ID,
+				CheckID:  st.CheckID,
+				Tags:     st.Tags,
+				Level:    st.Level,
+				Since:    st.Since,
+			}
+			if err := e.escalations.PutEscalation(ctx, esc); err != nil {
+				if influxdb.ErrorCode(err) != influxdb.EConflict && firstErr == nil {
+					firstErr = err
+				}
+				// another run started the escalation of the alert.
+				continue
+			}
+		}
+		if esc.PolicyID != policy.ID {
+			// the rule escalates with another policy, that starts over.
+			esc.PolicyID = policy.ID
+			esc.Steps = 0
+		}
+		esc.Level = st.Level
+
+		if err := e.notify(ctx, nr, policy, esc, st, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// the alerts left have recovered or were acknowledged.
+	stopped := make([]*influxdb.Escalation, 0, len(current))
+	for _, esc := range current {
+		stopped = append(stopped, esc)
+	}
+	if err := e.stop(ctx, stopped); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// notify sends the steps of policy that are due at now for an alert. Each
+// step is recorded before it is sent, and is not sent when another run of
+// the task recorded it first. The steps count as sent even if sending them
+// to some of their endpoints failed, so that they are not sent again to the
+// endpoints that were notified.
+func (e *Escalator) notify(ctx context.Context, nr influxdb.NotificationRule, policy *influxdb.EscalationPolicy, esc *influxdb.Escalation, st *influxdb.AlertState, now time.Time) error {
+	var firstErr error
+	for esc.Steps < len(policy.Steps) && !now.Before(policy.StepAt(esc.Steps, esc.Since)) {
+		msg := stepMessage(nr, policy, esc, st, now)
+		step := policy.Steps[esc.Steps]
+
+		esc.Steps++
+		esc.NotifiedAt = now
+		if err := e.escalations.PutEscalation(ctx, esc); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EConflict {
+				// another run sent the step.
+				return firstErr
+			}
+			return err
+		}
+
+		for _, id := range step.EndpointIDs {
+			if err := e.send(ctx, id, msg); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (e *Escalator) send(ctx context.Context, endpointID influxdb.ID, msg endpoint.Message) error {
+	ep, err := e.endpoints.FindNotificationEndpointByID(ctx, endpointID)
+	if err != nil {
+		return err
+	}
+	return e.sender.Send(ctx, ep, msg)
+}
+
+// stop stops escalating alerts.
+func (e *Escalator) stop(ctx context.Context, escalations []*influxdb.Escalation) error {
+	for _, esc := range escalations {
+		if err := e.escalations.DeleteEscalation(ctx, esc.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepMessage returns the message that the step esc.Steps of policy sends
+// for the alert of st.
+func stepMessage(nr influxdb.NotificationRule, policy *influxdb.EscalationPolicy, esc *influxdb.Escalation, st *influxdb.AlertState, now time.Time) endpoint.Message {
+	return endpoint.Message{
+		Level: notification.ParseCheckLevel(strings.ToUpper(st.Level)),
+		Text: fmt.Sprintf("Escalated by %q (step %d of %d): check %q is %s for %s since %s, not acknowledged: %s",
+			policy.Name, esc.Steps+1, len(policy.Steps), st.CheckName, st.Level, notification.TagsString(st.Tags),
+			esc.Since.Format(time.RFC3339), st.LastMessage),
+		Source:   st.CheckName,
+		DedupKey: nr.GetID().String() + "/" + alertKey(st.CheckID, st.Tags),
+		Time:     now,
+	}
+}
+
+// silenced returns whether one of silences mutes the alert of st at now.
+func silenced(silences []*influxdb.Silence, st *influxdb.AlertState, now time.Time) bool {
+	for _, s := range silences {
+		if s.ActiveAt(now) && s.Matches(st.CheckName, st.Tags, st.Level) {
+			return true
+		}
+	}
+	return false
+}
+
+// alertKey identifies the alert of the series with tags of a check.
+func alertKey(checkID influxdb.ID, tags []influxdb.Tag) string {
+	return checkID.String() + "," + notification.TagsString(tags)
+}
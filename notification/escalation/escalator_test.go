@@ -0,0 +1,237 @@
+package escalation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/escalation"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+const (
+	orgID     = influxdb.ID(1)
+	ruleID    = influxdb.ID(2)
+	taskID    = influxdb.ID(3)
+	policyID  = influxdb.ID(4)
+	checkID   = influxdb.ID(5)
+	managerID = influxdb.ID(10)
+	teamID    = influxdb.ID(11)
+)
+
+type senderFunc func(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+
+func (f senderFunc) Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+	return f(ctx, e, msg)
+}
+
+func TestEscalator_Escalate(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := []influxdb.Tag{{Key: "host", Value: "a"}}
+
+	policy := policyID
+	nr := &rule.Slack{
+		Base: rule.Base{
+			ID:                 ruleID,
+			OrgID:              orgID,
+			TaskID:             taskID,
+			StatusRules:        []notification.StatusRule{{CurrentLevel: notification.Critical}},
+			EscalationPolicyID: &policy,
+		},
+	}
+	rules := mock.NewNotificationRuleStore()
+	rules.FindNotificationRulesF = func(_ context.Context, filter influxdb.NotificationRuleFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		if filter.TaskID == nil || *filter.TaskID != taskID {
+			t.Fatalf("expected the rule of task %s, got %v", taskID, filter.TaskID)
+		}
+		return []influxdb.NotificationRule{nr}, 1, nil
+	}
+
+	policies := mock.NewEscalationPolicyService()
+	policies.FindEscalationPolicyByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.EscalationPolicy, error) {
+		return &influxdb.EscalationPolicy{
+			ID:    policyID,
+			OrgID: orgID,
+			Name:  "on-call",
+			Steps: []influxdb.EscalationStep{
+				{Delay: influxdb.Duration{Duration: 5 * time.Minute}, EndpointIDs: []influxdb.ID{managerID}},
+				{Delay: influxdb.Duration{Duration: 10 * time.Minute}, EndpointIDs: []influxdb.ID{teamID}},
+			},
+		}, nil
+	}
+
+	stored := map[influxdb.ID]*influxdb.Escalation{}
+	escalations := mock.NewEscalationService()
+	escalations.FindEscalationsF = func(_ context.Context, id influxdb.ID) ([]*influxdb.Escalation, error) {
+		var escs []*influxdb.Escalation
+		for _, e := range stored {
+			copied := *e
+			escs = append(escs, &copied)
+		}
+		return escs, nil
+	}
+	escalations.PutEscalationF = func(_ context.Context, e *influxdb.Escalation) error {
+		e.ID = 0
+		for _, cur := range stored {
+			if !cur.SameAlert(e) {
+				continue
+			}
+			if cur.PolicyID == e.PolicyID && cur.Steps >= e.Steps {
+				return influxdb.ErrEscalationStepSent
+			}
+			e.ID = cur.ID
+		}
+		if !e.ID.Valid() {
+			e.ID = influxdb.ID(100 + len(stored))
+		}
+		copied := *e
+		stored[e.ID] = &copied
+		return nil
+	}
+	escalations.DeleteEscalationF = func(_ context.Context, id influxdb.ID) error {
+		delete(stored, id)
+		return nil
+	}
+
+	state := &influxdb.AlertState{
+		CheckID:     checkID,
+		CheckName:   "cpu",
+		Tags:        tags,
+		Level:       "CRIT",
+		Since:       since,
+		LastMessage: "cpu is high",
+	}
+	alerts := mock.NewAlertService()
+	alerts.FindAlertStatesF = func(_ context.Context, filter influxdb.AlertFilter) ([]*influxdb.AlertState, int, error) {
+		if filter.OrgID != orgID {
+			t.Fatalf("expected the alerts of org %s, got %s", orgID, filter.OrgID)
+		}
+		if len(filter.Levels) != 1 || filter.Levels[0] != "CRIT" {
+			t.Fatalf("expected only the alerts the rule escalates, got levels %v", filter.Levels)
+		}
+		return []*influxdb.AlertState{state}, 1, nil
+	}
+
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointByIDF = func(_ context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		return &endpoint.Slack{Base: endpoint.Base{ID: &id, Status: influxdb.Active}}, nil
+	}
+
+	var sent []influxdb.ID
+	sender := senderFunc(func(_ context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+		if msg.Level != notification.Critical || msg.Source != "cpu" {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+		sent = append(sent, e.GetID())
+		return nil
+	})
+
+	var active []*influxdb.Silence
+	silences := mock.NewSilenceService()
+	silences.FindSilencesF = func(_ context.Context, filter influxdb.SilenceFilter, _ ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+		return active, len(active), nil
+	}
+
+	e := escalation.NewEscalator(rules, policies, escalations, alerts, silences, endpoints, sender)
+	escalate := func(at time.Duration) {
+		t.Helper()
+		e.Now = func() time.Time { return since.Add(at) }
+		if err := e.Escalate(context.Background(), &influxdb.Task{ID: taskID, OrganizationID: orgID, Type: "slack"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectSent := func(ids ...influxdb.ID) {
+		t.Helper()
+		if len(sent) != len(ids) {
+			t.Fatalf("expected %v to be notified, got %v", ids, sent)
+		}
+		for i := range ids {
+			if sent[i] != ids[i] {
+				t.Fatalf("expected %v to be notified, got %v", ids, sent)
+			}
+		}
+	}
+
+	escalate(time.Minute)
+	expectSent()
+	if len(stored) != 1 {
+		t.Fatalf("expected the escalation of the alert to start, got %v", stored)
+	}
+
+	escalate(6 * time.Minute)
+	expectSent(managerID)
+
+	escalate(7 * time.Minute)
+	expectSent(managerID)
+
+	// a step recorded by another run of the task is not sent again.
+	stale := escalations.FindEscalationsF
+	escalations.FindEscalationsF = func(ctx context.Context, id influxdb.ID) ([]*influxdb.Escalation, error) {
+		escs, err := stale(ctx, id)
+		for _, esc := range escs {
+			esc.Steps = 0
+		}
+		return escs, err
+	}
+	escalate(8 * time.Minute)
+	expectSent(managerID)
+	escalations.FindEscalationsF = stale
+
+	// silenced alerts are not escalated until the silence ends.
+	active = []*influxdb.Silence{{
+		OrgID:     orgID,
+		CheckName: "cpu",
+		Start:     since,
+		End:       since.Add(30 * time.Minute),
+	}}
+	escalate(20 * time.Minute)
+	expectSent(managerID)
+	if len(stored) != 1 {
+		t.Fatalf("expected the escalation of the silenced alert to be kept, got %v", stored)
+	}
+	active = nil
+
+	escalate(20 * time.Minute)
+	expectSent(managerID, teamID)
+
+	escalate(time.Hour)
+	expectSent(managerID, teamID)
+
+	t.Run("acknowledged alerts stop escalating", func(t *testing.T) {
+		state.Acknowledgement = &influxdb.AlertAcknowledgement{ID: 1}
+		defer func() { state.Acknowledgement = nil }()
+
+		escalate(2 * time.Hour)
+		if len(stored) != 0 {
+			t.Fatalf("expected the escalation to stop, got %v", stored)
+		}
+	})
+
+	t.Run("recovered alerts stop escalating", func(t *testing.T) {
+		sent = nil
+		state.Since = since.Add(3 * time.Hour)
+		escalate(3*time.Hour + 5*time.Minute)
+		expectSent(managerID)
+
+		state.Level = "OK"
+		escalate(4 * time.Hour)
+		if len(stored) != 0 {
+			t.Fatalf("expected the escalation to stop, got %v", stored)
+		}
+		expectSent(managerID)
+	})
+
+	t.Run("tasks of checks are not escalated", func(t *testing.T) {
+		rules.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+			t.Fatal("expected no rule to be looked up")
+			return nil, 0, nil
+		}
+		if err := e.Escalate(context.Background(), &influxdb.Task{ID: taskID, OrganizationID: orgID, Type: "threshold"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
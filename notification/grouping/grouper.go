@@ -0,0 +1,376 @@
+// Package grouping sends the notifications of notification rules in groups.
+package grouping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+	"github.com/influxdata/influxdb/query"
+)
+
+// columns of the notifications logged by the monitor package.
+const (
+	messageColumn         = "_message"
+	levelColumn           = "_level"
+	checkIDColumn         = "_check_id"
+	checkNameColumn       = "_check_name"
+	checkTypeColumn       = "_type"
+	statusTimestampColumn = "_status_timestamp"
+)
+
+// Timeout is how long sending the grouped notifications of a rule after a
+// run of its task may take.
+const Timeout = time.Minute
+
+// Sender sends messages to notification endpoints.
+type Sender interface {
+	Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+}
+
+// Grouper sends the statuses that the task of a grouped notification rule
+// logged in a run to the endpoint of the rule, in groups.
+type Grouper struct {
+	rules     influxdb.NotificationRuleStore
+	groups    influxdb.NotificationGroupService
+	bs        influxdb.BucketService
+	qs        query.QueryService
+	endpoints influxdb.NotificationEndpointService
+	sender    Sender
+}
+
+// NewGrouper returns a Grouper that reads the statuses logged by the tasks of
+// rules with qs, keeps their groups in groups, and sends them with sender.
+func NewGrouper(
+	rules influxdb.NotificationRuleStore,
+	groups influxdb.NotificationGroupService,
+	bs influxdb.BucketService,
+	qs query.QueryService,
+	endpoints influxdb.NotificationEndpointService,
+	sender Sender,
+) *Grouper {
+	return &Grouper{
+		rules:     rules,
+		groups:    groups,
+		bs:        bs,
+		qs:        qs,
+		endpoints: endpoints,
+		sender:    sender,
+	}
+}
+
+// Group adds the statuses that a run of the task of a grouped notification
+// rule logged to the groups of the rule, and sends the groups that are due
+// at the time the run was scheduled for. It does nothing for the tasks of
+// anything but grouped notification rules.
+//
+// The groups are stored before their notifications are sent, and are read
+// again when another run of the task changed them first, so that each
+// notification is sent once when runs of the task finish at once.
+func (g *Grouper) Group(ctx context.Context, task *influxdb.Task, run *influxdb.Run) error {
+	if !rule.IsType(task.Type) {
+		return nil
+	}
+
+	nr, err := rule.FindByTask(ctx, g.rules, task)
+	if err != nil || nr == nil || nr.GetGrouping() == nil {
+		return err
+	}
+
+	statuses, err := g.findStatuses(ctx, nr, run.ScheduledFor)
+	if err != nil {
+		return err
+	}
+
+	var msgs []endpoint.Message
+	for {
+		groups, err := g.groups.FindNotificationGroups(ctx, nr.GetID())
+		if err != nil {
+			return err
+		}
+		msgs = update(nr, groups, statuses, run.ScheduledFor)
+
+		err = g.groups.PutNotificationGroups(ctx, groups)
+		if err == nil {
+			break
+		}
+		if influxdb.ErrorCode(err) != influxdb.EConflict {
+			return err
+		}
+		// another run changed the groups since they were read.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ep, err := g.endpoints.FindNotificationEndpointByID(ctx, nr.GetEndpointID())
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, msg := range msgs {
+		if err := g.sender.Send(ctx, ep, msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// update adds the statuses that arrived at now to the groups of a rule, and
+// returns the notifications of the groups that are due at now, which count
+// as sent. The groups that would hold nothing back are forgotten.
+func update(nr influxdb.NotificationRule, groups *influxdb.NotificationGroups, statuses []*influxdb.AlertStatus, now time.Time) []endpoint.Message {
+	grouping := nr.GetGrouping()
+	for _, st := range statuses {
+		addStatus(groupOf(groups, grouping, st), st, now)
+	}
+
+	var msgs []endpoint.Message
+	kept := groups.Groups[:0]
+	for _, group := range groups.Groups {
+		if len(group.Pending) > 0 && due(grouping, group, now) {
+			if fp := fingerprint(group.Pending); fp != group.Fingerprint || !now.Before(group.SentAt.Add(grouping.TTL.Duration)) {
+				msgs = append(msgs, groupMessage(nr, group, now))
+				group.SentAt = now
+				group.Fingerprint = fp
+			}
+			// the statuses of an identical notification are dropped.
+			group.Pending = nil
+			group.PendingSince = time.Time{}
+		}
+
+		// groups are forgotten once nothing would hold their statuses back.
+		idle := len(group.Pending) == 0 &&
+			!now.Before(group.SentAt.Add(grouping.Interval.Duration)) &&
+			!now.Before(group.SentAt.Add(grouping.TTL.Duration))
+		if !idle {
+			kept = append(kept, group)
+		}
+	}
+	groups.Groups = kept
+	return msgs
+}
+
+// findStatuses returns the statuses that the task of a grouped rule logged
+// in its run scheduled for now, the time of the notifications of the run.
+func (g *Grouper) findStatuses(ctx context.Context, nr influxdb.NotificationRule, now time.Time) ([]*influxdb.AlertStatus, error) {
+	b, err := g.bs.FindBucketByName(ctx, nr.GetOrgID(), influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(`import "influxdata/influxdb/v1"
+
+from(bucketID: %q)
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r._measurement == "notifications" and r._notification_rule_id == %q)
+	|> v1.fieldsAsCols()
+	|> filter(fn: (r) => r._grouped == "true")`,
+		b.ID.String(),
+		now.UTC().Format(time.RFC3339Nano),
+		now.Add(time.Nanosecond).UTC().Format(time.RFC3339Nano),
+		nr.GetID().String())
+
+	var statuses []*influxdb.AlertStatus
+	err = g.query(ctx, b, script, func(tbl flux.Table) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			for i := 0; i < cr.Len(); i++ {
+				st, err := readStatus(tbl.Key(), cr, i)
+				if err != nil {
+					return err
+				}
+				statuses = append(statuses, st)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (g *Grouper) query(ctx context.Context, b *influxdb.Bucket, script string, fn func(flux.Table) error) error {
+	orgID := b.OrgID
+	// At this point we are behind authorization
+	// so we are faking a read only permission to the org's system bucket
+	bucketID := b.ID
+	auth := &influxdb.Authorization{
+		ID:     b.ID,
+		Status: influxdb.Active,
+		OrgID:  orgID,
+		Permissions: []influxdb.Permission{
+			{
+				Action: influxdb.ReadAction,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: &orgID,
+					ID:    &bucketID,
+				},
+			},
+		},
+	}
+	request := &query.Request{Authorization: auth, OrganizationID: orgID, Compiler: lang.FluxCompiler{Query: script}}
+
+	itr, err := g.qs.Query(ctx, request)
+	if err != nil {
+		return err
+	}
+	defer itr.Release()
+
+	for itr.More() {
+		if err := itr.Next().Tables().Do(fn); err != nil {
+			return err
+		}
+	}
+	return itr.Err()
+}
+
+// readStatus reads the status of the row i of a table of notifications. The
+// tags of the status are the tags of the group key of the table.
+func readStatus(key flux.GroupKey, cr flux.ColReader, i int) (*influxdb.AlertStatus, error) {
+	st := &influxdb.AlertStatus{
+		Tags: []influxdb.Tag{},
+	}
+	for j, col := range cr.Cols() {
+		if col.Label == statusTimestampColumn && col.Type == flux.TInt {
+			st.Time = values.Time(cr.Ints(j).Value(i)).Time().UTC()
+			continue
+		}
+		if col.Type != flux.TString || cr.Strings(j).IsNull(i) {
+			continue
+		}
+
+		v := cr.Strings(j).ValueString(i)
+		switch col.Label {
+		case messageColumn:
+			st.Message = v
+		case levelColumn:
+			st.Level = strings.ToUpper(v)
+		case checkIDColumn:
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return nil, err
+			}
+			st.CheckID = *id
+		case checkNameColumn:
+			st.CheckName = v
+		case checkTypeColumn:
+			st.CheckType = v
+		default:
+			if !strings.HasPrefix(col.Label, "_") && key.HasCol(col.Label) {
+				st.Tags = append(st.Tags, influxdb.Tag{Key: col.Label, Value: v})
+			}
+		}
+	}
+	sort.Slice(st.Tags, func(i, j int) bool {
+		return st.Tags[i].Key < st.Tags[j].Key
+	})
+	return st, nil
+}
+
+// groupOf returns the group of a status, which is added to groups if it is
+// the first status of its group.
+func groupOf(groups *influxdb.NotificationGroups, grouping *influxdb.NotificationGrouping, st *influxdb.AlertStatus) *influxdb.NotificationGroup {
+	tags := make([]influxdb.Tag, 0, len(grouping.By))
+	for _, k := range grouping.By {
+		t := influxdb.Tag{Key: k}
+		for _, st := range st.Tags {
+			if st.Key == k {
+				t.Value = st.Value
+			}
+		}
+		tags = append(tags, t)
+	}
+
+	for _, group := range groups.Groups {
+		if notification.TagsString(group.Tags) == notification.TagsString(tags) {
+			return group
+		}
+	}
+	group := &influxdb.NotificationGroup{Tags: tags}
+	groups.Groups = append(groups.Groups, group)
+	return group
+}
+
+// addStatus adds a status that arrived at now to the pending statuses of a
+// group, in place of an earlier status of the same series.
+func addStatus(group *influxdb.NotificationGroup, st *influxdb.AlertStatus, now time.Time) {
+	if len(group.Pending) == 0 {
+		group.PendingSince = now
+	}
+	for i, p := range group.Pending {
+		if p.CheckID == st.CheckID && notification.TagsString(p.Tags) == notification.TagsString(st.Tags) {
+			if !st.Time.Before(p.Time) {
+				group.Pending[i] = st
+			}
+			return
+		}
+	}
+	group.Pending = append(group.Pending, st)
+}
+
+// due returns whether the pending statuses of a group are due at now: Wait
+// after the first of them for the first notification of the group, and
+// Interval after the last notification for the next ones.
+func due(grouping *influxdb.NotificationGrouping, group *influxdb.NotificationGroup, now time.Time) bool {
+	if group.SentAt.IsZero() {
+		return !now.Before(group.PendingSince.Add(grouping.Wait.Duration))
+	}
+	return !now.Before(group.SentAt.Add(grouping.Interval.Duration))
+}
+
+// fingerprint identifies the series and levels of statuses.
+func fingerprint(statuses []*influxdb.AlertStatus) string {
+	keys := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		keys = append(keys, st.CheckID.String()+"/"+notification.TagsString(st.Tags)+"/"+st.Level)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
+
+// groupMessage returns the message that lists the pending statuses of a
+// group, at the highest of their levels.
+func groupMessage(nr influxdb.NotificationRule, group *influxdb.NotificationGroup, now time.Time) endpoint.Message {
+	pending := append([]*influxdb.AlertStatus(nil), group.Pending...)
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].CheckName != pending[j].CheckName {
+			return pending[i].CheckName < pending[j].CheckName
+		}
+		return notification.TagsString(pending[i].Tags) < notification.TagsString(pending[j].Tags)
+	})
+
+	level := notification.Unknown
+	lines := make([]string, 0, len(pending))
+	for _, st := range pending {
+		if l := notification.ParseCheckLevel(st.Level); l > level {
+			level = l
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s (%s): %s", st.Level, st.CheckName, notification.TagsString(st.Tags), st.Message))
+	}
+
+	series := "all series"
+	if len(group.Tags) > 0 {
+		series = notification.TagsString(group.Tags)
+	}
+	return endpoint.Message{
+		Level:    level,
+		Text:     fmt.Sprintf("%d series of %q for %s:\n%s", len(pending), nr.GetName(), series, strings.Join(lines, "\n")),
+		Source:   nr.GetName(),
+		DedupKey: nr.GetID().String() + "/" + notification.TagsString(group.Tags),
+		Time:     now,
+	}
+}
@@ -0,0 +1,204 @@
+package grouping_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/grouping"
+	"github.com/influxdata/influxdb/notification/rule"
+	"github.com/influxdata/influxdb/query"
+	qmock "github.com/influxdata/influxdb/query/mock"
+)
+
+const (
+	orgID      = influxdb.ID(1)
+	ruleID     = influxdb.ID(2)
+	taskID     = influxdb.ID(3)
+	bucketID   = influxdb.ID(4)
+	endpointID = influxdb.ID(5)
+	checkIDs   = "000000000000000a"
+	statusCSV  = "#datatype,string,long,dateTime:RFC3339,string,long,string,string,string,string,string,string\n#group,false,false,false,false,false,true,true,true,true,true,true\n#default,_result,,,,,,,,,,\n,result,table,_time,_message,_status_timestamp,_check_id,_check_name,_type,_level,dc,host\n"
+)
+
+type senderFunc func(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+
+func (f senderFunc) Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+	return f(ctx, e, msg)
+}
+
+// status returns a row of statusCSV of the series of a host at a level.
+func status(table, dc, host, level string) string {
+	return ",," + table + ",2020-01-01T00:00:00Z," + host + " is " + level + ",1577836800000000000," + checkIDs + ",cpu,threshold," + level + "," + dc + "," + host + "\n"
+}
+
+func TestGrouper_Group(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	nr := &rule.Slack{
+		Base: rule.Base{
+			ID:          ruleID,
+			OrgID:       orgID,
+			TaskID:      taskID,
+			Name:        "hosts",
+			EndpointID:  endpointID,
+			StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+			Grouping: &influxdb.NotificationGrouping{
+				By:       []string{"dc"},
+				Wait:     influxdb.Duration{Duration: 5 * time.Minute},
+				Interval: influxdb.Duration{Duration: 10 * time.Minute},
+				TTL:      influxdb.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	rules := mock.NewNotificationRuleStore()
+	rules.FindNotificationRulesF = func(_ context.Context, filter influxdb.NotificationRuleFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		if filter.TaskID == nil || *filter.TaskID != taskID {
+			t.Fatalf("expected the rule of task %s, got %v", taskID, filter.TaskID)
+		}
+		return []influxdb.NotificationRule{nr}, 1, nil
+	}
+
+	// the groups are stored encoded, as they are read back as copies.
+	var stored *influxdb.NotificationGroups
+	groups := mock.NewNotificationGroupService()
+	groups.FindNotificationGroupsF = func(_ context.Context, id influxdb.ID) (*influxdb.NotificationGroups, error) {
+		if stored == nil {
+			return &influxdb.NotificationGroups{RuleID: id}, nil
+		}
+		b, err := json.Marshal(stored)
+		if err != nil {
+			return nil, err
+		}
+		var g influxdb.NotificationGroups
+		return &g, json.Unmarshal(b, &g)
+	}
+	putGroups := func(_ context.Context, g *influxdb.NotificationGroups) error {
+		stored = g
+		return nil
+	}
+	groups.PutNotificationGroupsF = putGroups
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+	}
+
+	var results string
+	qs := &qmock.QueryService{
+		QueryF: func(_ context.Context, req *query.Request) (flux.ResultIterator, error) {
+			return csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(strings.NewReader(results)))
+		},
+	}
+
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointByIDF = func(_ context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		return &endpoint.Slack{Base: endpoint.Base{ID: &id, Status: influxdb.Active}}, nil
+	}
+
+	var sent []endpoint.Message
+	sender := senderFunc(func(_ context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+		if e.GetID() != endpointID {
+			t.Fatalf("expected the endpoint of the rule to be notified, got %s", e.GetID())
+		}
+		sent = append(sent, msg)
+		return nil
+	})
+
+	g := grouping.NewGrouper(rules, groups, bs, qs, endpoints, sender)
+	run := func(at time.Duration, rows ...string) {
+		t.Helper()
+		results = ""
+		if len(rows) > 0 {
+			results = statusCSV + strings.Join(rows, "")
+		}
+		r := &influxdb.Run{TaskID: taskID, ScheduledFor: start.Add(at)}
+		if err := g.Group(context.Background(), &influxdb.Task{ID: taskID, OrganizationID: orgID, Type: "slack"}, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectSent := func(n int) {
+		t.Helper()
+		if len(sent) != n {
+			t.Fatalf("expected %d notifications, got %d: %+v", n, len(sent), sent)
+		}
+	}
+
+	run(0, status("0", "east", "a", "crit"), status("1", "east", "b", "crit"), status("2", "west", "c", "crit"))
+	run(time.Minute, status("0", "east", "a", "crit"))
+	expectSent(0)
+
+	run(5 * time.Minute)
+	expectSent(2)
+	if !strings.Contains(sent[0].Text, "2 series") || !strings.Contains(sent[0].Text, "dc=east") || sent[0].Level != notification.Critical {
+		t.Fatalf("expected the series of dc east in one notification, got %+v", sent[0])
+	}
+
+	t.Run("identical notifications are suppressed", func(t *testing.T) {
+		run(6*time.Minute, status("0", "east", "a", "crit"), status("1", "east", "b", "crit"))
+		run(15 * time.Minute)
+		expectSent(2)
+	})
+
+	t.Run("notifications are sent at most every interval", func(t *testing.T) {
+		run(16*time.Minute, status("0", "east", "a", "ok"))
+		expectSent(3)
+
+		run(17*time.Minute, status("1", "east", "b", "ok"))
+		expectSent(3)
+
+		run(26 * time.Minute)
+		expectSent(4)
+		if !strings.Contains(sent[3].Text, "1 series") || sent[3].Level != notification.Ok {
+			t.Fatalf("expected the recovery of b, got %+v", sent[3])
+		}
+	})
+
+	t.Run("idle groups are forgotten", func(t *testing.T) {
+		run(2 * time.Hour)
+		if len(stored.Groups) != 0 {
+			t.Fatalf("expected no groups, got %+v", stored.Groups)
+		}
+	})
+
+	t.Run("groups changed by another run are read again", func(t *testing.T) {
+		run(2*time.Hour+time.Minute, status("0", "east", "a", "crit"))
+		conflicts := 1
+		groups.PutNotificationGroupsF = func(ctx context.Context, g *influxdb.NotificationGroups) error {
+			expectSent(4)
+			if conflicts > 0 {
+				conflicts--
+				return influxdb.ErrNotificationGroupsChanged
+			}
+			return putGroups(ctx, g)
+		}
+		defer func() { groups.PutNotificationGroupsF = putGroups }()
+
+		run(2*time.Hour + 6*time.Minute)
+		expectSent(5)
+		if conflicts != 0 || !strings.Contains(sent[4].Text, "1 series") {
+			t.Fatalf("expected the group to be sent once it was stored, got %+v", sent[4])
+		}
+	})
+
+	t.Run("tasks of ungrouped rules are not grouped", func(t *testing.T) {
+		nr.Grouping = nil
+		defer func() { stored = nil }()
+		stored = &influxdb.NotificationGroups{}
+		groups.PutNotificationGroupsF = func(context.Context, *influxdb.NotificationGroups) error {
+			t.Fatal("expected no groups to be stored")
+			return nil
+		}
+		run(3*time.Hour, status("0", "east", "a", "crit"))
+		expectSent(5)
+	})
+}
@@ -0,0 +1,198 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+// Opsgenie is the notification rule config of opsgenie. The statuses create
+// alerts with a priority from their level, P1 for crit, P3 for warn and P5
+// for info, and the ok statuses close the alerts of their series.
+type Opsgenie struct {
+	Base
+	MessageTemplate string `json:"messageTemplate"`
+}
+
+// GenerateFlux generates a flux script for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	opsgenieEndpoint, ok := e.(*endpoint.Opsgenie)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Opsgenie endpoint", e.Type())
+	}
+	p, err := s.GenerateFluxAST(opsgenieEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST generates a flux AST for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFluxAST(e *endpoint.Opsgenie) (*ast.Package, error) {
+	f := flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "http", "json", "pagerduty", "influxdata/influxdb/secrets", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+func (s *Opsgenie) generateFluxASTBody(e *endpoint.Opsgenie) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTSecrets(e))
+	statements = append(statements, s.generateHeaders())
+	statements = append(statements, s.generateFluxASTEndpoint())
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe(e)...)
+
+	return statements
+}
+
+func (s *Opsgenie) generateFluxASTSecrets(e *endpoint.Opsgenie) ast.Statement {
+	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.APIKey.Key))))
+
+	return flux.DefineVariable("opsgenie_secret", call)
+}
+
+func (s *Opsgenie) generateHeaders() ast.Statement {
+	return flux.DefineVariable("headers", flux.Object(
+		flux.Dictionary("Content-Type", flux.String("application/json")),
+		flux.Dictionary("Authorization", flux.Add(flux.String("GenieKey "), flux.Identifier("opsgenie_secret"))),
+	))
+}
+
+// generateFluxASTEndpoint defines the endpoint of the alert API. The API
+// answers with 202 Accepted, which http.endpoint does not count as sent. The
+// alias of the alerts is the hash of the group key of the series, which
+// pagerduty.dedupKey computes for the dedup keys of PagerDuty.
+func (s *Opsgenie) generateFluxASTEndpoint() ast.Statement {
+	post := flux.Call(
+		flux.Member("http", "post"),
+		flux.Object(
+			flux.Property("url", flux.Member("obj", "url")),
+			flux.Property("headers", flux.Identifier("headers")),
+			flux.Property("data", flux.Call(
+				flux.Member("json", "encode"),
+				flux.Object(flux.Property("v", flux.Member("obj", "data"))),
+			)),
+		),
+	)
+	sent := flux.Call(
+		flux.Identifier("string"),
+		flux.Object(flux.Property("v", flux.Equal(
+			flux.Integer(2),
+			&ast.BinaryExpression{Operator: ast.DivisionOperator, Left: post, Right: flux.Integer(100)},
+		))),
+	)
+
+	mapFn := flux.FuncBlock(flux.FunctionParams("r"),
+		flux.DefineVariable("obj", flux.Call(flux.Identifier("mapFn"), flux.Object(flux.Property("r", flux.Identifier("r"))))),
+		&ast.ReturnStatement{
+			Argument: flux.ObjectWith("r", flux.Property("_sent", sent)),
+		},
+	)
+
+	endpoint := flux.Function(flux.FunctionParams("mapFn"),
+		flux.Function(flux.PipeFunctionParams("tables"),
+			flux.Pipe(
+				flux.Identifier("tables"),
+				flux.Call(flux.Member("pagerduty", "dedupKey"), flux.Object()),
+				flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", mapFn))),
+			),
+		),
+	)
+
+	return flux.DefineVariable("opsgenie_endpoint", endpoint)
+}
+
+func (s *Opsgenie) generateFluxASTNotifyPipe(e *endpoint.Opsgenie) []ast.Statement {
+	level := flux.Member("r", "_level")
+	alias := flux.Member("r", "_pagerdutyDedupKey")
+
+	// ok statuses close the alert of their series.
+	u := flux.If(
+		flux.Equal(level, flux.String("ok")),
+		flux.Add(
+			flux.Add(flux.String(e.AlertURL()+"/"), alias),
+			flux.String("/close?identifierType=alias"),
+		),
+		flux.String(e.AlertURL()),
+	)
+
+	alert := flux.Object(
+		flux.Property("message", flux.String(s.MessageTemplate)),
+		flux.Property("description", flux.Member("r", "_message")),
+		flux.Property("alias", alias),
+		flux.Property("priority", s.generatePriority()),
+		flux.Property("source", flux.Member("notification", "_notification_rule_name")),
+		flux.Property("note", flux.Member("r", "_message")),
+	)
+
+	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(
+		flux.Property("url", u),
+		flux.Property("data", alert),
+	))
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("opsgenie_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return s.generateNotifyStatements(call)
+}
+
+func (s *Opsgenie) generatePriority() ast.Expression {
+	level := flux.Member("r", "_level")
+	return flux.If(
+		flux.Equal(level, flux.String("crit")),
+		flux.String(endpoint.OpsgeniePriority(notification.Critical)),
+		flux.If(
+			flux.Equal(level, flux.String("warn")),
+			flux.String(endpoint.OpsgeniePriority(notification.Warn)),
+			flux.String(endpoint.OpsgeniePriority(notification.Info)),
+		),
+	)
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "opsgenie invalid message template",
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the rule config.
+func (s Opsgenie) Type() string {
+	return "opsgenie"
+}
@@ -0,0 +1,99 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/rule"
+)
+
+func TestOpsgenie_GenerateFlux(t *testing.T) {
+	want := `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "http"
+import "json"
+import "pagerduty"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+opsgenie_secret = secrets.get(key: "opsgenie_key")
+headers = {"Content-Type": "application/json", "Authorization": "GenieKey " + opsgenie_secret}
+opsgenie_endpoint = (mapFn) =>
+	((tables=<-) =>
+		(tables
+			|> pagerduty.dedupKey()
+			|> map(fn: (r) => {
+				obj = mapFn(r: r)
+
+				return {r with _sent: string(v: 2 == http.post(url: obj.url, headers: headers, data: json.encode(v: obj.data)) / 100)}
+			})))
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor.from(start: -2h)
+warn = statuses
+	|> filter(fn: (r) =>
+		(r._level == "warn"))
+ok = statuses
+	|> filter(fn: (r) =>
+		(r._level == "ok"))
+all_statuses = union(tables: [warn, ok])
+	|> sort(columns: ["_time"])
+	|> filter(fn: (r) =>
+		(r._time > experimental.subDuration(from: now(), d: 1h)))
+
+all_statuses
+	|> monitor.notify(data: notification, endpoint: opsgenie_endpoint(mapFn: (r) =>
+		({url: if r._level == "ok" then "https://api.eu.opsgenie.com/v2/alerts/" + r._pagerdutyDedupKey + "/close?identifierType=alias" else "https://api.eu.opsgenie.com/v2/alerts", data: {
+			message: "blah",
+			description: r._message,
+			alias: r._pagerdutyDedupKey,
+			priority: if r._level == "crit" then "P1" else if r._level == "warn" then "P3" else "P5",
+			source: notification._notification_rule_name,
+			note: r._message,
+		}})))`
+
+	s := &rule.Opsgenie{
+		MessageTemplate: "blah",
+		Base: rule.Base{
+			ID:         1,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			EndpointID: 2,
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Warn,
+				},
+				{
+					CurrentLevel: notification.Ok,
+				},
+			},
+		},
+	}
+
+	e := &endpoint.Opsgenie{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		URL:    "https://api.eu.opsgenie.com/v2/alerts",
+		APIKey: influxdb.SecretField{Key: "opsgenie_key"},
+	}
+
+	f, err := s.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f != want {
+		t.Errorf("scripts did not match. want:\n%v\n\ngot:\n%v", want, f)
+	}
+}
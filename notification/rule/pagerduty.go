@@ -80,7 +80,7 @@ func (s *PagerDuty) generateFluxASTBody(e *endpoint.PagerDuty) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe(e.ClientURL))
+	statements = append(statements, s.generateFluxASTNotifyPipe(e.ClientURL)...)
 
 	return statements
 }
@@ -99,7 +99,7 @@ func (s *PagerDuty) generateFluxASTEndpoint(e *endpoint.PagerDuty) ast.Statement
 	return flux.DefineVariable("pagerduty_endpoint", call)
 }
 
-func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
+func (s *PagerDuty) generateFluxASTNotifyPipe(url string) []ast.Statement {
 	endpointProps := []*ast.Property{}
 
 	// routing_key:
@@ -171,7 +171,7 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return s.generateNotifyStatements(call)
 }
 
 func severityFromLevel() *ast.CallExpression {
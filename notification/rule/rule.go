@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -16,6 +17,28 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"slack":     func() influxdb.NotificationRule { return &Slack{} },
 	"pagerduty": func() influxdb.NotificationRule { return &PagerDuty{} },
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
+	"teams":     func() influxdb.NotificationRule { return &Teams{} },
+	"opsgenie":  func() influxdb.NotificationRule { return &Opsgenie{} },
+	"telegram":  func() influxdb.NotificationRule { return &Telegram{} },
+}
+
+// IsType returns whether typ is the type of a notification rule. The task of
+// a notification rule has the type of the rule.
+func IsType(typ string) bool {
+	_, ok := typeToRule[typ]
+	return ok
+}
+
+// FindByTask returns the notification rule of a task, or nil if it has none.
+func FindByTask(ctx context.Context, rules influxdb.NotificationRuleStore, task *influxdb.Task) (influxdb.NotificationRule, error) {
+	found, _, err := rules.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID:  &task.OrganizationID,
+		TaskID: &task.ID,
+	})
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return found[0], nil
 }
 
 // UnmarshalJSON will convert
@@ -48,7 +71,7 @@ type Base struct {
 	OrgID       influxdb.ID `json:"orgID,omitempty"`
 	OwnerID     influxdb.ID `json:"ownerID,omitempty"`
 	TaskID      influxdb.ID `json:"taskID,omitempty"`
-	// SleepUntil is an optional sleeptime to start a task.
+	// SleepUntil silences all of the notifications of the rule until the time.
 	SleepUntil *time.Time             `json:"sleepUntil,omitempty"`
 	Every      *notification.Duration `json:"every,omitempty"`
 	// Offset represents a delay before execution.
@@ -57,8 +80,19 @@ type Base struct {
 	RunbookLink string                    `json:"runbookLink"`
 	TagRules    []notification.TagRule    `json:"tagRules,omitempty"`
 	StatusRules []notification.StatusRule `json:"statusRules,omitempty"`
+	// EscalationPolicyID is the policy that escalates the alerts of the
+	// rule that are not acknowledged.
+	EscalationPolicyID *influxdb.ID `json:"escalationPolicyID,omitempty"`
+	// Grouping batches the notifications of the rule. The task of a grouped
+	// rule logs the statuses it notifies, and they are sent in groups after
+	// its runs.
+	Grouping *influxdb.NotificationGrouping `json:"grouping,omitempty"`
 	*influxdb.Limit
 	influxdb.CRUDLog
+
+	// Silences are the silences of the organization that the generated flux
+	// honours. They are not stored with the rule.
+	Silences []*influxdb.Silence `json:"-"`
 }
 
 func (b Base) valid() error {
@@ -92,6 +126,17 @@ func (b Base) valid() error {
 			Msg:  "Notification Rule EndpointID is invalid",
 		}
 	}
+	if b.EscalationPolicyID != nil && !b.EscalationPolicyID.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Rule EscalationPolicyID is invalid",
+		}
+	}
+	if b.Grouping != nil {
+		if err := b.Grouping.Valid(); err != nil {
+			return err
+		}
+	}
 	if b.Offset != nil && b.Every != nil && b.Offset.TimeDuration() >= b.Every.TimeDuration() {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -242,6 +287,202 @@ func (b *Base) generateLevelCheck(r notification.StatusRule) (ast.Statement, *as
 	return flux.DefineVariable(name, pipe), flux.Identifier(name)
 }
 
+// generateNotifyStatements returns the statements that notify the statuses of
+// all_statuses with notify, a call of monitor.notify. When the rule is silenced
+// the silenced statuses are not sent to the endpoint, but are still logged to
+// the notifications in _monitoring, with _sent set to "false" and _silenced
+// set to "true".
+func (b *Base) generateNotifyStatements(notify *ast.CallExpression) []ast.Statement {
+	if b.Grouping != nil {
+		// the statuses of grouped rules are sent in groups after the run.
+		notify = generateLogNotify("_grouped")
+	}
+	return b.generateSilencedStatements(notify, generateLogNotify("_silenced"))
+}
+
+// generateSilencedStatements returns the statements that pipe the statuses of
+// all_statuses that are not silenced to notify, and those that are silenced
+// to silencedNotify.
+func (b *Base) generateSilencedStatements(notify, silencedNotify *ast.CallExpression) []ast.Statement {
+	silenced := b.generateSilencedExpression()
+	if silenced == nil {
+		return []ast.Statement{
+			flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), notify)),
+		}
+	}
+
+	isSilenced := flux.Call(flux.Identifier("silenced"), flux.Object(flux.Property("r", flux.Identifier("r"))))
+
+	return []ast.Statement{
+		flux.DefineVariable("silenced", flux.Function(flux.FunctionParams("r"), silenced)),
+		flux.ExpressionStatement(flux.Pipe(
+			flux.Identifier("all_statuses"),
+			flux.Call(
+				flux.Identifier("filter"),
+				flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.Not(isSilenced)))),
+			),
+			notify,
+		)),
+		flux.ExpressionStatement(flux.Pipe(
+			flux.Identifier("all_statuses"),
+			flux.Call(
+				flux.Identifier("filter"),
+				flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), isSilenced))),
+			),
+			silencedNotify,
+		)),
+	}
+}
+
+// Results of the dry run flux of a rule, that the statuses the rule would
+// have notified are yielded to.
+const (
+	DryRunSentResult     = "sent"
+	DryRunSilencedResult = "silenced"
+	DryRunGroupedResult  = "grouped"
+)
+
+// GenerateDryRunFlux generates a flux script that selects the statuses the
+// rule would notify, like the script of its task, but yields them instead of
+// sending them to the endpoint. The statuses that would be sent are yielded
+// to DryRunSentResult, those that are silenced to DryRunSilencedResult, and
+// those that would be sent in groups to DryRunGroupedResult.
+func (b *Base) GenerateDryRunFlux() (string, error) {
+	if b.Every == nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Rule every is required to dry run it",
+		}
+	}
+
+	notify := generateDryRunYield(DryRunSentResult)
+	if b.Grouping != nil {
+		notify = generateDryRunYield(DryRunGroupedResult)
+	}
+
+	var statements []ast.Statement
+	statements = append(statements, b.generateFluxASTStatuses())
+	statements = append(statements, b.generateLevelChecks()...)
+	statements = append(statements, b.generateSilencedStatements(notify, generateDryRunYield(DryRunSilencedResult))...)
+
+	f := flux.File(b.Name, flux.Imports("influxdata/influxdb/monitor", "experimental"), statements)
+	return ast.Format(&ast.Package{Package: "main", Files: []*ast.File{f}}), nil
+}
+
+func generateDryRunYield(name string) *ast.CallExpression {
+	return flux.Call(flux.Identifier("yield"), flux.Object(flux.Property("name", flux.String(name))))
+}
+
+// generateLogNotify returns a call of monitor.notify that logs the statuses
+// without sending them, marked with a column named marker set to "true".
+func generateLogNotify(marker string) *ast.CallExpression {
+	logEndpoint := flux.Function(
+		flux.PipeFunctionParams("tables"),
+		flux.Pipe(
+			flux.Identifier("tables"),
+			flux.Call(
+				flux.Identifier("map"),
+				flux.Object(
+					flux.Property("fn", flux.Function(
+						flux.FunctionParams("r"),
+						flux.ObjectWith("r",
+							flux.Property("_sent", flux.String("false")),
+							flux.Property(marker, flux.String("true")),
+						),
+					)),
+				),
+			),
+		),
+	)
+
+	return flux.Call(
+		flux.Member("monitor", "notify"),
+		flux.Object(
+			flux.Property("data", flux.Identifier("notification")),
+			flux.Property("endpoint", logEndpoint),
+		),
+	)
+}
+
+// generateSilencedExpression returns an expression of r that is true when the
+// status r is silenced, by SleepUntil or by one of the silences of the rule.
+// It returns nil when the rule is not silenced.
+func (b *Base) generateSilencedExpression() ast.Expression {
+	var exprs []ast.Expression
+	if b.SleepUntil != nil {
+		exprs = append(exprs, flux.LessThan(generateNow(), flux.Time(*b.SleepUntil)))
+	}
+	for _, s := range b.Silences {
+		exprs = append(exprs, generateSilenceExpression(s))
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	expr := exprs[0]
+	for _, e := range exprs[1:] {
+		expr = flux.Or(expr, e)
+	}
+	return expr
+}
+
+// generateSilenceExpression returns an expression of r that is true when a
+// silence matches the status r and one of its windows is open.
+func generateSilenceExpression(s *influxdb.Silence) ast.Expression {
+	var exprs []ast.Expression
+	if s.CheckName != "" {
+		exprs = append(exprs, flux.Equal(flux.Member("r", "_check_name"), flux.String(s.CheckName)))
+	}
+	for _, tr := range s.TagRules {
+		if tr.Operator == influxdb.NotEqual {
+			exprs = append(exprs, flux.NotEqual(flux.Member("r", tr.Key), flux.String(tr.Value)))
+			continue
+		}
+		exprs = append(exprs, flux.Equal(flux.Member("r", tr.Key), flux.String(tr.Value)))
+	}
+	if len(s.Levels) > 0 {
+		var levels ast.Expression
+		for _, l := range s.Levels {
+			e := flux.Equal(flux.Member("r", "_level"), flux.String(strings.ToLower(l)))
+			if levels == nil {
+				levels = e
+				continue
+			}
+			levels = flux.Or(levels, e)
+		}
+		exprs = append(exprs, levels)
+	}
+
+	exprs = append(exprs, flux.GreaterThanEqual(generateNow(), flux.Time(s.Start)))
+	if s.Recurrence == nil {
+		exprs = append(exprs, flux.LessThan(generateNow(), flux.Time(s.End)))
+	} else {
+		if s.Recurrence.Until != nil {
+			exprs = append(exprs, flux.LessThan(generateNow(), flux.Time(*s.Recurrence.Until)))
+		}
+		// the window is open when the time since the start of the silence
+		// modulo its recurrence is shorter than the window.
+		sinceStart := flux.Subtract(
+			flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", generateNow()))),
+			flux.Integer(s.Start.UnixNano()),
+		)
+		exprs = append(exprs, flux.LessThan(
+			flux.Modulo(sinceStart, flux.Integer(int64(s.Recurrence.Every.Duration))),
+			flux.Integer(int64(s.End.Sub(s.Start))),
+		))
+	}
+
+	expr := exprs[0]
+	for _, e := range exprs[1:] {
+		expr = flux.And(expr, e)
+	}
+	return expr
+}
+
+func generateNow() ast.Expression {
+	return flux.Call(flux.Identifier("now"), flux.Object())
+}
+
 // increaseDur increases the duration of leading duration in a duration literal.
 // It is used so that we will have overlapping windows. If the unit of the literal
 // is `s`, we double the interval; otherwise we increase the value by 1. The reason
@@ -367,6 +608,81 @@ func (b *Base) MatchesTags(tags []influxdb.Tag) bool {
 	return true
 }
 
+// Escalates returns whether the rule escalates the alert of a series with
+// tags at level: the tags must match the tag rules of the rule, and the
+// level must be the current level of one of its status rules. OK is never
+// escalated.
+func (b *Base) Escalates(tags []influxdb.Tag, level string) bool {
+	l := notification.ParseCheckLevel(strings.ToUpper(level))
+	if l == notification.Ok {
+		return false
+	}
+
+	var matched bool
+	for _, r := range b.StatusRules {
+		matched = matched || r.CurrentLevel == notification.Any || r.CurrentLevel == l
+	}
+	if !matched {
+		return false
+	}
+
+	for _, tr := range b.TagRules {
+		// a series without the tag doesn't have the value of a notequal rule.
+		ok := tr.Operator == influxdb.NotEqual
+		for _, t := range tags {
+			if t.Key != tr.Key {
+				continue
+			}
+			switch tr.Operator {
+			case influxdb.NotEqual:
+				ok = t.Value != tr.Value
+			default:
+				ok = t.Value == tr.Value
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// GetGrouping returns the grouping of the notifications of the rule, or nil.
+func (b Base) GetGrouping() *influxdb.NotificationGrouping {
+	return b.Grouping
+}
+
+// EscalatedAlerts returns the filter of the alerts that the rule may
+// escalate: the alerts of its organization at the levels of its status
+// rules, of the series with the tags of its equal tag rules. The alerts it
+// returns are matched with Escalates.
+func (b *Base) EscalatedAlerts() influxdb.AlertFilter {
+	filter := influxdb.AlertFilter{OrgID: b.OrgID}
+	for _, r := range b.StatusRules {
+		if r.CurrentLevel == notification.Any {
+			filter.Levels = nil
+			break
+		}
+		filter.Levels = append(filter.Levels, r.CurrentLevel.String())
+	}
+	for _, tr := range b.TagRules {
+		if tr.Operator == influxdb.Equal {
+			filter.Tags = append(filter.Tags, tr.Tag)
+		}
+	}
+	return filter
+}
+
+// GetEscalationPolicyID returns the ID of the escalation policy of the rule, or nil.
+func (b Base) GetEscalationPolicyID() *influxdb.ID {
+	return b.EscalationPolicyID
+}
+
+// SetSilences sets the silences that the generated flux honours.
+func (b *Base) SetSilences(silences []*influxdb.Silence) {
+	b.Silences = silences
+}
+
 // GetOwnerID returns the owner id.
 func (b Base) GetOwnerID() influxdb.ID {
 	return b.OwnerID
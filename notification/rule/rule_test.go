@@ -2,6 +2,7 @@ package rule_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -505,3 +506,136 @@ func TestMatchingRules(t *testing.T) {
 		})
 	}
 }
+
+func TestBase_GenerateDryRunFlux(t *testing.T) {
+	b := rule.Base{
+		ID:         1,
+		EndpointID: 2,
+		Name:       "foo",
+		Every:      mustDuration("1h"),
+		SleepUntil: timePtr(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		TagRules: []notification.TagRule{
+			{
+				Tag:      influxdb.Tag{Key: "foo", Value: "bar"},
+				Operator: influxdb.Equal,
+			},
+		},
+		StatusRules: []notification.StatusRule{
+			{
+				CurrentLevel: notification.Critical,
+			},
+		},
+	}
+
+	want := `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "experimental"
+
+statuses = monitor.from(start: -2h, fn: (r) =>
+	(r.foo == "bar"))
+crit = statuses
+	|> filter(fn: (r) =>
+		(r._level == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r._time > experimental.subDuration(from: now(), d: 1h)))
+silenced = (r) =>
+	(now() < 2020-01-01T00:00:00Z)
+
+all_statuses
+	|> filter(fn: (r) =>
+		(not silenced(r: r)))
+	|> yield(name: "sent")
+all_statuses
+	|> filter(fn: (r) =>
+		(silenced(r: r)))
+	|> yield(name: "silenced")`
+
+	got, err := b.GenerateDryRunFlux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("scripts did not match. want:\n%s\n\ngot:\n%s", want, got)
+	}
+
+	t.Run("grouped", func(t *testing.T) {
+		b := b
+		b.SleepUntil = nil
+		b.Grouping = &influxdb.NotificationGrouping{Wait: influxdb.Duration{Duration: time.Minute}}
+
+		got, err := b.GenerateDryRunFlux()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(got, `|> yield(name: "grouped")`) {
+			t.Errorf("expected the statuses to be yielded as grouped, got:\n%s", got)
+		}
+	})
+
+	t.Run("every is required", func(t *testing.T) {
+		b := b
+		b.Every = nil
+		if _, err := b.GenerateDryRunFlux(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected an invalid error, got %v", err)
+		}
+	})
+}
+
+func TestBase_Escalates(t *testing.T) {
+	b := &rule.Base{
+		StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+		TagRules: []notification.TagRule{
+			{Tag: influxdb.Tag{Key: "region", Value: "us"}, Operator: influxdb.Equal},
+			{Tag: influxdb.Tag{Key: "env", Value: "dev"}, Operator: influxdb.NotEqual},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		tags  []influxdb.Tag
+		level string
+		want  bool
+	}{
+		{
+			name:  "matching tags",
+			tags:  []influxdb.Tag{{Key: "region", Value: "us"}, {Key: "env", Value: "prod"}},
+			level: "CRIT",
+			want:  true,
+		},
+		{
+			name:  "without the tag of a notequal rule",
+			tags:  []influxdb.Tag{{Key: "region", Value: "us"}},
+			level: "crit",
+			want:  true,
+		},
+		{
+			name:  "with the value of a notequal rule",
+			tags:  []influxdb.Tag{{Key: "region", Value: "us"}, {Key: "env", Value: "dev"}},
+			level: "CRIT",
+		},
+		{
+			name:  "without the tag of an equal rule",
+			tags:  []influxdb.Tag{{Key: "env", Value: "prod"}},
+			level: "CRIT",
+		},
+		{
+			name:  "another level",
+			tags:  []influxdb.Tag{{Key: "region", Value: "us"}},
+			level: "WARN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Escalates(tt.tags, tt.level); got != tt.want {
+				t.Errorf("expected Escalates to return %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	filter := b.EscalatedAlerts()
+	if !cmp.Equal(filter.Levels, []string{"CRIT"}) || !cmp.Equal(filter.Tags, []influxdb.Tag{{Key: "region", Value: "us"}}) {
+		t.Errorf("unexpected escalated alerts filter %+v", filter)
+	}
+}
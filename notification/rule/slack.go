@@ -50,7 +50,7 @@ func (s *Slack) generateFluxASTBody(e *endpoint.Slack) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe())
+	statements = append(statements, s.generateFluxASTNotifyPipe()...)
 
 	return statements
 }
@@ -74,7 +74,7 @@ func (s *Slack) generateFluxASTEndpoint(e *endpoint.Slack) ast.Statement {
 	return flux.DefineVariable("slack_endpoint", call)
 }
 
-func (s *Slack) generateFluxASTNotifyPipe() ast.Statement {
+func (s *Slack) generateFluxASTNotifyPipe() []ast.Statement {
 	endpointProps := []*ast.Property{}
 	endpointProps = append(endpointProps, flux.Property("channel", flux.String(s.Channel)))
 	// TODO(desa): are these values correct?
@@ -89,7 +89,7 @@ func (s *Slack) generateFluxASTNotifyPipe() ast.Statement {
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return s.generateNotifyStatements(call)
 }
 
 func (s *Slack) generateSlackColors() ast.Expression {
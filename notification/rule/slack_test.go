@@ -2,6 +2,7 @@ package rule_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
@@ -28,6 +29,10 @@ func idPtr(i int) *influxdb.ID {
 	return &id
 }
 
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
 func TestSlack_GenerateFlux(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -339,6 +344,145 @@ all_statuses
 				},
 			},
 		},
+		{
+			name: "with silences",
+			want: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "slack"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack.endpoint(url: "http://localhost:7777")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor.from(start: -2h)
+any = statuses
+	|> filter(fn: (r) =>
+		(true))
+all_statuses = any
+	|> filter(fn: (r) =>
+		(r._time > experimental.subDuration(from: now(), d: 1h)))
+silenced = (r) =>
+	(now() < 2020-01-01T00:00:00Z or r._check_name == "cpu" and r.host == "a" and (r._level == "crit" or r._level == "warn") and now() >= 2020-01-01T02:00:00Z and (int(v: now()) - 1577844000000000000) % 86400000000000 < 7200000000000)
+
+all_statuses
+	|> filter(fn: (r) =>
+		(not silenced(r: r)))
+	|> monitor.notify(data: notification, endpoint: slack_endpoint(mapFn: (r) =>
+		({channel: "bar", text: "blah", color: if r._level == "crit" then "danger" else if r._level == "warn" then "warning" else "good"})))
+all_statuses
+	|> filter(fn: (r) =>
+		(silenced(r: r)))
+	|> monitor.notify(data: notification, endpoint: (tables=<-) =>
+		(tables
+			|> map(fn: (r) =>
+				({r with _sent: "false", _silenced: "true"}))))`,
+			rule: &rule.Slack{
+				Channel:         "bar",
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					SleepUntil: timePtr(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Any,
+						},
+					},
+					Silences: []*influxdb.Silence{
+						{
+							CheckName: "cpu",
+							TagRules: []influxdb.TagRule{
+								{
+									Tag:      influxdb.Tag{Key: "host", Value: "a"},
+									Operator: influxdb.Equal,
+								},
+							},
+							Levels: []string{"CRIT", "WARN"},
+							Start:  time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+							End:    time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC),
+							Recurrence: &influxdb.SilenceRecurrence{
+								Every: influxdb.Duration{Duration: 24 * time.Hour},
+							},
+						},
+					},
+				},
+			},
+			endpoint: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:   idPtr(2),
+					Name: "foo",
+				},
+				URL: "http://localhost:7777",
+			},
+		},
+		{
+			name: "grouped",
+			want: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "slack"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack.endpoint(url: "http://localhost:7777")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor.from(start: -2h)
+crit = statuses
+	|> filter(fn: (r) =>
+		(r._level == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r._time > experimental.subDuration(from: now(), d: 1h)))
+
+all_statuses
+	|> monitor.notify(data: notification, endpoint: (tables=<-) =>
+		(tables
+			|> map(fn: (r) =>
+				({r with _sent: "false", _grouped: "true"}))))`,
+			rule: &rule.Slack{
+				Channel:         "bar",
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					Grouping: &influxdb.NotificationGrouping{
+						By:   []string{"host"},
+						Wait: influxdb.Duration{Duration: time.Minute},
+					},
+				},
+			},
+			endpoint: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:   idPtr(2),
+					Name: "foo",
+				},
+				URL: "http://localhost:7777",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,149 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/notification/flux"
+)
+
+// Teams is the notification rule config of Microsoft Teams.
+type Teams struct {
+	Base
+	// Title is the title of the message card, the name of the check when empty.
+	Title           string `json:"title,omitempty"`
+	MessageTemplate string `json:"messageTemplate"`
+}
+
+// GenerateFlux generates a flux script for the teams notification rule.
+func (s *Teams) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	teamsEndpoint, ok := e.(*endpoint.Teams)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not a Teams endpoint", e.Type())
+	}
+	p, err := s.GenerateFluxAST(teamsEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST generates a flux AST for the teams notification rule.
+func (s *Teams) GenerateFluxAST(e *endpoint.Teams) (*ast.Package, error) {
+	f := flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "http", "json", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+func (s *Teams) generateFluxASTBody(e *endpoint.Teams) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, flux.DefineVariable("headers", flux.Object(
+		flux.Dictionary("Content-Type", flux.String("application/json")),
+	)))
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe()...)
+
+	return statements
+}
+
+func (s *Teams) generateFluxASTEndpoint(e *endpoint.Teams) ast.Statement {
+	call := flux.Call(flux.Member("http", "endpoint"), flux.Object(flux.Property("url", flux.String(e.URL))))
+
+	return flux.DefineVariable("teams_endpoint", call)
+}
+
+func (s *Teams) generateFluxASTNotifyPipe() []ast.Statement {
+	var title ast.Expression = flux.Member("r", "_check_name")
+	if s.Title != "" {
+		title = flux.String(s.Title)
+	}
+
+	// the message card of the incoming webhook of the channel.
+	card := flux.Object(
+		flux.Dictionary("@type", flux.String("MessageCard")),
+		flux.Dictionary("@context", flux.String("https://schema.org/extensions")),
+		flux.Property("themeColor", s.generateThemeColor()),
+		flux.Property("summary", title),
+		flux.Property("title", title),
+		flux.Property("text", flux.String(s.MessageTemplate)),
+	)
+
+	endpointFn := flux.FuncBlock(flux.FunctionParams("r"),
+		flux.DefineVariable("body", card),
+		&ast.ReturnStatement{
+			Argument: flux.Object(
+				flux.Property("headers", flux.Identifier("headers")),
+				flux.Property("data", flux.Call(
+					flux.Member("json", "encode"),
+					flux.Object(flux.Property("v", flux.Identifier("body"))),
+				)),
+			),
+		},
+	)
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("teams_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return s.generateNotifyStatements(call)
+}
+
+func (s *Teams) generateThemeColor() ast.Expression {
+	level := flux.Member("r", "_level")
+	return flux.If(
+		flux.Equal(level, flux.String("crit")),
+		flux.String(endpoint.TeamsThemeColor(notification.Critical)),
+		flux.If(
+			flux.Equal(level, flux.String("warn")),
+			flux.String(endpoint.TeamsThemeColor(notification.Warn)),
+			flux.String(endpoint.TeamsThemeColor(notification.Ok)),
+		),
+	)
+}
+
+type teamsAlias Teams
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Teams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			teamsAlias
+			Type string `json:"type"`
+		}{
+			teamsAlias: teamsAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Teams) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "teams msg template is empty",
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the rule config.
+func (s Teams) Type() string {
+	return "teams"
+}
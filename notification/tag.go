@@ -1,6 +1,9 @@
 package notification
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/notification/flux"
@@ -27,3 +30,13 @@ func (tr TagRule) GenerateFluxAST() ast.Expression {
 
 	return flux.Equal(k, v)
 }
+
+// TagsString formats tags as key=value pairs, sorted by key.
+func TagsString(tags []influxdb.Tag) string {
+	pairs := make([]string, 0, len(tags))
+	for _, t := range tags {
+		pairs = append(pairs, t.Key+"="+t.Value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,73 @@
+package influxdb
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ops for notification test and dry run errors.
+var (
+	OpTestNotificationEndpoint = "TestNotificationEndpoint"
+	OpDryRunNotificationRule   = "DryRunNotificationRule"
+)
+
+// NotificationEndpointTest is a synthetic message to send to a notification
+// endpoint, to verify that it is configured correctly.
+type NotificationEndpointTest struct {
+	// Level is the level of the message, one of CRIT, WARN, INFO or OK.
+	// It defaults to INFO.
+	Level string `json:"level,omitempty"`
+	// Text is the text of the message, it defaults to a message naming the endpoint.
+	Text string `json:"text,omitempty"`
+}
+
+// Valid returns an error if the test message is malformed.
+func (t NotificationEndpointTest) Valid() error {
+	switch strings.ToUpper(t.Level) {
+	case "", "CRIT", "WARN", "INFO", "OK":
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  "test message level must be one of CRIT, WARN, INFO or OK",
+	}
+}
+
+// NotificationEndpointTestService sends test messages to notification endpoints.
+type NotificationEndpointTestService interface {
+	// TestNotificationEndpoint sends a test message to the notification
+	// endpoint of id, with the secrets of the endpoint. It returns the error
+	// the endpoint answered with, if any.
+	TestNotificationEndpoint(ctx context.Context, id ID, t NotificationEndpointTest) error
+}
+
+// NotificationRuleDryRunResult is the outcome of a dry run of a notification rule.
+type NotificationRuleDryRunResult struct {
+	RuleID ID `json:"ruleID"`
+	// Time is the time the rule was evaluated at.
+	Time time.Time `json:"time"`
+	// Notifications are the statuses the rule would have notified, in time order.
+	Notifications []*NotificationRuleDryRunNotification `json:"notifications"`
+}
+
+// NotificationRuleDryRunNotification is a status that a notification rule
+// would have notified.
+type NotificationRuleDryRunNotification struct {
+	AlertStatus
+	// Sent is whether the status would have been sent to the endpoint by the run of the rule.
+	Sent bool `json:"sent"`
+	// Silenced is whether the status would have been silenced.
+	Silenced bool `json:"silenced"`
+	// Grouped is whether the status would have been sent in a group after the run.
+	Grouped bool `json:"grouped"`
+}
+
+// NotificationRuleDryRunService evaluates notification rules without sending
+// their notifications.
+type NotificationRuleDryRunService interface {
+	// DryRunNotificationRule evaluates the notification rule of id against
+	// the recent statuses of its organization, and returns the notifications
+	// it would have sent.
+	DryRunNotificationRule(ctx context.Context, id ID) (*NotificationRuleDryRunResult, error)
+}
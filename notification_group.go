@@ -0,0 +1,89 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationGrouping batches the notifications of a notification rule.
+// The statuses the rule notifies are grouped by the values of the By tag
+// keys, and the statuses of a group are sent together in one notification
+// that lists the affected series.
+//
+// The first notification of a group is sent Wait after its first status, so
+// that the statuses that arrive together are batched. Further statuses of
+// the group are sent at most every Interval. A notification that is
+// identical to the last one of its group, the same series at the same
+// levels, is suppressed for TTL after it.
+//
+// Notifications are sent after the runs of the task of the rule, so the
+// durations are rounded up to its schedule.
+type NotificationGrouping struct {
+	By       []string `json:"by,omitempty"`
+	Wait     Duration `json:"wait"`
+	Interval Duration `json:"interval"`
+	TTL      Duration `json:"ttl"`
+}
+
+// Valid returns an error if the grouping is malformed.
+func (g *NotificationGrouping) Valid() error {
+	for _, k := range g.By {
+		if k == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "notification grouping tag keys can't be empty",
+			}
+		}
+	}
+	if g.Wait.Duration < 0 || g.Interval.Duration < 0 || g.TTL.Duration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "notification grouping durations can't be negative",
+		}
+	}
+	return nil
+}
+
+// NotificationGroupService keeps the groups of the notifications of
+// notification rules between the runs of their tasks.
+type NotificationGroupService interface {
+	// FindNotificationGroups returns the groups of the notifications of a
+	// notification rule, which has none before its first grouped notification.
+	FindNotificationGroups(ctx context.Context, ruleID ID) (*NotificationGroups, error)
+
+	// PutNotificationGroups replaces the groups of the notifications of a
+	// notification rule, and increments g.Version. It fails with
+	// ErrNotificationGroupsChanged unless g has the version of the stored
+	// groups, so that the groups a run read are not replaced after another
+	// run of the task changed them.
+	PutNotificationGroups(ctx context.Context, g *NotificationGroups) error
+}
+
+// ErrNotificationGroupsChanged is returned when the groups of the
+// notifications of a rule changed since they were read.
+var ErrNotificationGroupsChanged = &Error{
+	Code: EConflict,
+	Msg:  "notification groups were changed since they were read",
+}
+
+// NotificationGroups are the groups of the notifications of a notification rule.
+type NotificationGroups struct {
+	RuleID ID                   `json:"ruleID"`
+	Groups []*NotificationGroup `json:"groups"`
+	// Version counts the changes to the groups.
+	Version int `json:"version"`
+}
+
+// NotificationGroup is a group of the notifications of a notification rule.
+type NotificationGroup struct {
+	// Tags are the values of the tag keys the rule groups by.
+	Tags []Tag `json:"tags"`
+	// Pending are the latest statuses of the series of the group that were
+	// not sent yet, and PendingSince is when the first of them arrived.
+	Pending      []*AlertStatus `json:"pending,omitempty"`
+	PendingSince time.Time      `json:"pendingSince,omitempty"`
+	// SentAt is when the last notification of the group was sent, and
+	// Fingerprint identifies the series and levels it listed.
+	SentAt      time.Time `json:"sentAt,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+}
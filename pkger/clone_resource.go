@@ -57,7 +57,7 @@ func uniqResourcesToClone(resources []ResourceToClone) []ResourceToClone {
 	return out
 }
 
-func bucketToObject(bkt influxdb.Bucket, name string) Object {
+func bucketToObject(bkt influxdb.Bucket, schemas []*influxdb.MeasurementSchema, policies []downsamplingPolicy, name string) Object {
 	if name == "" {
 		name = bkt.Name
 	}
@@ -67,10 +67,56 @@ func bucketToObject(bkt influxdb.Bucket, name string) Object {
 		Metadata:   convertToMetadataResource(name),
 		Spec:       make(Resource),
 	}
-	assignNonZeroStrings(k.Spec, map[string]string{fieldDescription: bkt.Description})
+	assignNonZeroStrings(k.Spec, map[string]string{
+		fieldDescription:      bkt.Description,
+		fieldBucketSchemaType: string(bkt.SchemaType),
+	})
 	if bkt.RetentionPeriod != 0 {
 		k.Spec[fieldBucketRetentionRules] = retentionRules{newRetentionRule(bkt.RetentionPeriod)}
 	}
+
+	var msResources []Resource
+	for _, ms := range schemas {
+		columns := make([]Resource, 0, len(ms.Columns))
+		for _, c := range ms.Columns {
+			col := Resource{
+				fieldName: c.Name,
+				fieldType: string(c.Type),
+			}
+			assignNonZeroStrings(col, map[string]string{fieldMeasurementColumnType: string(c.DataType)})
+			columns = append(columns, col)
+		}
+		msResources = append(msResources, Resource{
+			fieldName:                     ms.Name,
+			fieldMeasurementSchemaColumns: columns,
+		})
+	}
+	if len(msResources) > 0 {
+		k.Spec[fieldBucketMeasurementSchemas] = msResources
+	}
+
+	var dpResources []Resource
+	for _, dp := range policies {
+		aggregates := make(map[string]string, len(dp.Aggregates))
+		for typ, fn := range dp.Aggregates {
+			aggregates[string(typ)] = fn
+		}
+		r := Resource{
+			fieldDownsamplingDestination: dp.Destination,
+			fieldEvery:                   dp.Every.String(),
+			fieldDownsamplingAggregates:  aggregates,
+		}
+		if dp.Offset.Duration != 0 {
+			r[fieldOffset] = dp.Offset.String()
+		}
+		if len(dp.TagFilter) > 0 {
+			r[fieldDownsamplingTagFilter] = dp.TagFilter
+		}
+		dpResources = append(dpResources, r)
+	}
+	if len(dpResources) > 0 {
+		k.Spec[fieldBucketDownsamplingPolicies] = dpResources
+	}
 	return k
 }
 
@@ -128,6 +174,22 @@ func checkToObject(ch influxdb.Check, name string) Object {
 			thresholds = append(thresholds, convertThreshold(th))
 		}
 		k.Spec[fieldCheckThresholds] = thresholds
+	case *icheck.Anomaly:
+		k.Type = KindCheckAnomaly
+		assignBase(cT.Base)
+		k.Spec[fieldCheckBaseline] = string(cT.Baseline)
+		assignNonZeroFluxDurs(k.Spec, map[string]*notification.Duration{
+			fieldCheckWindow: cT.Window,
+			fieldCheckPeriod: cT.Period,
+		})
+		var sensitivities []Resource
+		for _, s := range cT.Sensitivities {
+			sensitivities = append(sensitivities, Resource{
+				fieldLevel:           s.Level.String(),
+				fieldCheckDeviations: s.Deviations,
+			})
+		}
+		k.Spec[fieldCheckSensitivities] = sensitivities
 	}
 	return k
 }
@@ -454,6 +516,22 @@ func endpointKind(e influxdb.NotificationEndpoint, name string) Object {
 		assignNonZeroSecrets(k.Spec, map[string]influxdb.SecretField{
 			fieldNotificationEndpointToken: actual.Token,
 		})
+	case *endpoint.Opsgenie:
+		k.Type = KindNotificationEndpointOpsgenie
+		assignNonZeroStrings(k.Spec, map[string]string{fieldNotificationEndpointURL: actual.URL})
+		assignNonZeroSecrets(k.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointAPIKey: actual.APIKey,
+		})
+	case *endpoint.Teams:
+		k.Type = KindNotificationEndpointTeams
+		k.Spec[fieldNotificationEndpointURL] = actual.URL
+	case *endpoint.Telegram:
+		k.Type = KindNotificationEndpointTelegram
+		k.Spec[fieldNotificationEndpointChatID] = actual.ChatID
+		assignNonZeroStrings(k.Spec, map[string]string{fieldNotificationEndpointURL: actual.URL})
+		assignNonZeroSecrets(k.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointToken: actual.Token,
+		})
 	}
 
 	return k
@@ -518,6 +596,16 @@ func ruleToObject(iRule influxdb.NotificationRule, endpointName, name string) Ob
 		assignBase(t.Base)
 		k.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
 		assignNonZeroStrings(k.Spec, map[string]string{fieldNotificationRuleChannel: t.Channel})
+	case *rule.Opsgenie:
+		assignBase(t.Base)
+		k.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+	case *rule.Teams:
+		assignBase(t.Base)
+		k.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(k.Spec, map[string]string{fieldNotificationRuleTitle: t.Title})
+	case *rule.Telegram:
+		assignBase(t.Base)
+		k.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
 	}
 
 	return k
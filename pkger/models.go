@@ -23,14 +23,18 @@ const (
 	KindUnknown                       Kind = ""
 	KindBucket                        Kind = "Bucket"
 	KindCheck                         Kind = "Check"
+	KindCheckAnomaly                  Kind = "CheckAnomaly"
 	KindCheckDeadman                  Kind = "CheckDeadman"
 	KindCheckThreshold                Kind = "CheckThreshold"
 	KindDashboard                     Kind = "Dashboard"
 	KindLabel                         Kind = "Label"
 	KindNotificationEndpoint          Kind = "NotificationEndpoint"
 	KindNotificationEndpointHTTP      Kind = "NotificationEndpointHTTP"
+	KindNotificationEndpointOpsgenie  Kind = "NotificationEndpointOpsgenie"
 	KindNotificationEndpointPagerDuty Kind = "NotificationEndpointPagerDuty"
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
+	KindNotificationEndpointTeams     Kind = "NotificationEndpointTeams"
+	KindNotificationEndpointTelegram  Kind = "NotificationEndpointTelegram"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
 	KindTask                          Kind = "Task"
@@ -41,14 +45,18 @@ const (
 var kinds = map[Kind]bool{
 	KindBucket:                        true,
 	KindCheck:                         true,
+	KindCheckAnomaly:                  true,
 	KindCheckDeadman:                  true,
 	KindCheckThreshold:                true,
 	KindDashboard:                     true,
 	KindLabel:                         true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
+	KindNotificationEndpointOpsgenie:  true,
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
+	KindNotificationEndpointTeams:     true,
+	KindNotificationEndpointTelegram:  true,
 	KindNotificationRule:              true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
@@ -58,13 +66,17 @@ var kinds = map[Kind]bool{
 var kindsUniqByName = map[Kind]bool{
 	KindBucket:                        true,
 	KindCheck:                         true,
+	KindCheckAnomaly:                  true,
 	KindCheckDeadman:                  true,
 	KindCheckThreshold:                true,
 	KindLabel:                         true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
+	KindNotificationEndpointOpsgenie:  true,
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
+	KindNotificationEndpointTeams:     true,
+	KindNotificationEndpointTelegram:  true,
 	KindVariable:                      true,
 }
 
@@ -98,7 +110,7 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	switch k {
 	case KindBucket:
 		return influxdb.BucketsResourceType
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		return influxdb.ChecksResourceType
 	case KindDashboard:
 		return influxdb.DashboardsResourceType
@@ -106,8 +118,11 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		return influxdb.LabelsResourceType
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams,
+		KindNotificationEndpointTelegram:
 		return influxdb.NotificationEndpointResourceType
 	case KindNotificationRule:
 		return influxdb.NotificationRuleResourceType
@@ -531,8 +546,28 @@ type SummaryBucket struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	// TODO: return retention rules?
-	RetentionPeriod   time.Duration  `json:"retentionPeriod"`
-	LabelAssociations []SummaryLabel `json:"labelAssociations"`
+	RetentionPeriod    time.Duration              `json:"retentionPeriod"`
+	SchemaType         influxdb.SchemaType        `json:"schemaType,omitempty"`
+	MeasurementSchemas []SummaryMeasurementSchema `json:"measurementSchemas,omitempty"`
+	LabelAssociations  []SummaryLabel             `json:"labelAssociations"`
+
+	DownsamplingPolicies []SummaryDownsamplingPolicy `json:"downsamplingPolicies,omitempty"`
+}
+
+// SummaryMeasurementSchema provides a summary of a measurement schema of a pkg bucket.
+type SummaryMeasurementSchema struct {
+	Name    string                             `json:"name"`
+	Columns []influxdb.MeasurementSchemaColumn `json:"columns"`
+}
+
+// SummaryDownsamplingPolicy provides a summary of a downsampling policy of a
+// pkg bucket. Destination is the name of the bucket data is downsampled into.
+type SummaryDownsamplingPolicy struct {
+	Destination string                                   `json:"destination"`
+	Every       influxdb.Duration                        `json:"every"`
+	Offset      influxdb.Duration                        `json:"offset"`
+	Aggregates  map[influxdb.SchemaColumnDataType]string `json:"aggregates"`
+	TagFilter   map[string]string                        `json:"tagFilter,omitempty"`
 }
 
 // SummaryCheck provides a summary of a pkg check.
@@ -784,16 +819,32 @@ const (
 )
 
 const (
-	fieldBucketRetentionRules = "retentionRules"
+	fieldBucketRetentionRules     = "retentionRules"
+	fieldBucketSchemaType         = "schemaType"
+	fieldBucketMeasurementSchemas = "measurementSchemas"
+	fieldMeasurementSchemaColumns = "columns"
+	fieldMeasurementColumnType    = "dataType"
+
+	fieldBucketDownsamplingPolicies = "downsamplingPolicies"
+	fieldDownsamplingDestination    = "destination"
+	fieldDownsamplingAggregates     = "aggregates"
+	fieldDownsamplingTagFilter      = "tagFilter"
 )
 
 type bucket struct {
-	id             influxdb.ID
-	OrgID          influxdb.ID
-	Description    string
-	name           *references
-	RetentionRules retentionRules
-	labels         sortedLabels
+	id                 influxdb.ID
+	OrgID              influxdb.ID
+	Description        string
+	name               *references
+	RetentionRules     retentionRules
+	SchemaType         influxdb.SchemaType
+	MeasurementSchemas []measurementSchema
+	labels             sortedLabels
+
+	DownsamplingPolicies []downsamplingPolicy
+	// influxPolicies are the downsampling policies with their destinations
+	// resolved, set when the pkg is applied.
+	influxPolicies []influxdb.DownsamplingPolicy
 
 	// existing provides context for a resource that already
 	// exists in the platform. If a resource already exists
@@ -825,25 +876,116 @@ func (b *bucket) Exists() bool {
 }
 
 func (b *bucket) summarize() SummaryBucket {
-	return SummaryBucket{
+	sum := SummaryBucket{
 		ID:                SafeID(b.ID()),
 		OrgID:             SafeID(b.OrgID),
 		Name:              b.Name(),
 		Description:       b.Description,
 		RetentionPeriod:   b.RetentionRules.RP(),
+		SchemaType:        b.SchemaType,
 		LabelAssociations: toSummaryLabels(b.labels...),
 	}
+	for _, ms := range b.MeasurementSchemas {
+		sum.MeasurementSchemas = append(sum.MeasurementSchemas, SummaryMeasurementSchema(ms))
+	}
+	for _, dp := range b.DownsamplingPolicies {
+		sum.DownsamplingPolicies = append(sum.DownsamplingPolicies, SummaryDownsamplingPolicy(dp))
+	}
+	return sum
 }
 
 func (b *bucket) valid() []validationErr {
-	return b.RetentionRules.valid()
+	vErrs := b.RetentionRules.valid()
+	if err := b.SchemaType.Valid(); err != nil {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldBucketSchemaType,
+			Msg:   influxdb.ErrorMessage(err),
+		})
+	}
+	if len(b.MeasurementSchemas) > 0 && b.SchemaType != influxdb.SchemaTypeExplicit {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldBucketMeasurementSchemas,
+			Msg:   "measurement schemas require a schemaType of " + string(influxdb.SchemaTypeExplicit),
+		})
+	}
+	for i, ms := range b.MeasurementSchemas {
+		if err := ms.influxSchema(b.OrgID, b.ID()).Valid(); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldBucketMeasurementSchemas,
+				Index: intPtr(i),
+				Msg:   influxdb.ErrorMessage(err),
+			})
+		}
+	}
+	for i, dp := range b.DownsamplingPolicies {
+		if msg := dp.valid(b.Name(), b.SchemaType); msg != "" {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldBucketDownsamplingPolicies,
+				Index: intPtr(i),
+				Msg:   msg,
+			})
+		}
+	}
+	return vErrs
 }
 
 func (b *bucket) shouldApply() bool {
 	return b.existing == nil ||
 		b.Description != b.existing.Description ||
 		b.Name() != b.existing.Name ||
-		b.RetentionRules.RP() != b.existing.RetentionPeriod
+		b.RetentionRules.RP() != b.existing.RetentionPeriod ||
+		len(b.MeasurementSchemas) > 0 ||
+		len(b.DownsamplingPolicies) > 0
+}
+
+type measurementSchema struct {
+	Name    string
+	Columns []influxdb.MeasurementSchemaColumn
+}
+
+func (m measurementSchema) influxSchema(orgID, bucketID influxdb.ID) *influxdb.MeasurementSchema {
+	return &influxdb.MeasurementSchema{
+		OrgID:    orgID,
+		BucketID: bucketID,
+		Name:     m.Name,
+		Columns:  m.Columns,
+	}
+}
+
+type downsamplingPolicy struct {
+	Destination string
+	Every       influxdb.Duration
+	Offset      influxdb.Duration
+	Aggregates  map[influxdb.SchemaColumnDataType]string
+	TagFilter   map[string]string
+}
+
+// valid returns a message describing why the policy of the bucket named source
+// is invalid, or an empty string if it is valid.
+func (d downsamplingPolicy) valid(source string, schemaType influxdb.SchemaType) string {
+	switch d.Destination {
+	case "":
+		return "destination bucket name is required"
+	case source:
+		return "bucket cannot be downsampled into itself"
+	}
+
+	// The destination is resolved when the pkg is applied, any valid ID will do
+	// to validate the rest of the policy.
+	if err := d.influxPolicy(1).Valid(schemaType); err != nil {
+		return influxdb.ErrorMessage(err)
+	}
+	return ""
+}
+
+func (d downsamplingPolicy) influxPolicy(destID influxdb.ID) influxdb.DownsamplingPolicy {
+	return influxdb.DownsamplingPolicy{
+		DestinationBucketID: destID,
+		Every:               d.Every,
+		Offset:              d.Offset,
+		Aggregates:          d.Aggregates,
+		TagFilter:           d.TagFilter,
+	}
 }
 
 type mapperBuckets []*bucket
@@ -924,16 +1066,22 @@ type checkKind int
 const (
 	checkKindDeadman checkKind = iota + 1
 	checkKindThreshold
+	checkKindAnomaly
 )
 
 const (
 	fieldCheckAllValues             = "allValues"
+	fieldCheckBaseline              = "baseline"
+	fieldCheckDeviations            = "deviations"
+	fieldCheckPeriod                = "period"
 	fieldCheckReportZero            = "reportZero"
+	fieldCheckSensitivities         = "sensitivities"
 	fieldCheckStaleTime             = "staleTime"
 	fieldCheckStatusMessageTemplate = "statusMessageTemplate"
 	fieldCheckTags                  = "tags"
 	fieldCheckThresholds            = "thresholds"
 	fieldCheckTimeSince             = "timeSince"
+	fieldCheckWindow                = "window"
 )
 
 type check struct {
@@ -954,6 +1102,11 @@ type check struct {
 	timeSince     time.Duration
 	thresholds    []threshold
 
+	baseline      string
+	window        time.Duration
+	period        time.Duration
+	sensitivities []sensitivity
+
 	labels sortedLabels
 
 	existing influxdb.Check
@@ -1023,6 +1176,19 @@ func (c *check) summarize() SummaryCheck {
 			StaleTime:  toNotificationDuration(c.staleTime),
 			TimeSince:  toNotificationDuration(c.timeSince),
 		}
+	case checkKindAnomaly:
+		anomaly := &icheck.Anomaly{
+			Base:          base,
+			Baseline:      icheck.AnomalyBaseline(c.baseline),
+			Sensitivities: toInfluxSensitivities(c.sensitivities...),
+		}
+		if c.window > 0 {
+			anomaly.Window = toNotificationDuration(c.window)
+		}
+		if c.period > 0 {
+			anomaly.Period = toNotificationDuration(c.period)
+		}
+		sum.Check = anomaly
 	}
 	return sum
 }
@@ -1068,6 +1234,40 @@ func (c *check) valid() []validationErr {
 				vErrs = append(vErrs, fail)
 			}
 		}
+	case checkKindAnomaly:
+		switch icheck.AnomalyBaseline(c.baseline) {
+		case icheck.BaselineStdDev, icheck.BaselineMAD:
+			if c.window <= c.every {
+				vErrs = append(vErrs, validationErr{
+					Field: fieldCheckWindow,
+					Msg:   "duration value must be provided that is > every",
+				})
+			}
+		case icheck.BaselineSeasonal:
+			if c.period == 0 {
+				vErrs = append(vErrs, validationErr{
+					Field: fieldCheckPeriod,
+					Msg:   "duration value must be provided for a seasonal baseline",
+				})
+			}
+		default:
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckBaseline,
+				Msg:   fmt.Sprintf("must be 1 in [stddev, mad, seasonal]; got=%q", c.baseline),
+			})
+		}
+		if len(c.sensitivities) == 0 {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckSensitivities,
+				Msg:   "must provide at least 1 sensitivity entry",
+			})
+		}
+		for i, sens := range c.sensitivities {
+			for _, fail := range sens.valid() {
+				fail.Index = intPtr(i)
+				vErrs = append(vErrs, fail)
+			}
+		}
 	}
 	return vErrs
 }
@@ -1159,6 +1359,41 @@ func toInfluxThresholds(thresholds ...threshold) []icheck.ThresholdConfig {
 	return iThresh
 }
 
+type sensitivity struct {
+	level      string
+	deviations float64
+}
+
+func (s sensitivity) valid() []validationErr {
+	var vErrs []validationErr
+	switch notification.ParseCheckLevel(s.level) {
+	case notification.Critical, notification.Warn, notification.Info:
+	default:
+		vErrs = append(vErrs, validationErr{
+			Field: fieldLevel,
+			Msg:   fmt.Sprintf("must be 1 in [CRIT, WARN, INFO]; got=%q", s.level),
+		})
+	}
+	if s.deviations <= 0 {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckDeviations,
+			Msg:   "must be > 0",
+		})
+	}
+	return vErrs
+}
+
+func toInfluxSensitivities(sensitivities ...sensitivity) []icheck.AnomalySensitivity {
+	var iSens []icheck.AnomalySensitivity
+	for _, s := range sensitivities {
+		iSens = append(iSens, icheck.AnomalySensitivity{
+			Level:      notification.ParseCheckLevel(s.level),
+			Deviations: s.deviations,
+		})
+	}
+	return iSens
+}
+
 type assocMapKey struct {
 	resType influxdb.ResourceType
 	name    string
@@ -1324,8 +1559,11 @@ type notificationKind int
 
 const (
 	notificationKindHTTP notificationKind = iota + 1
+	notificationKindOpsgenie
 	notificationKindPagerDuty
 	notificationKindSlack
+	notificationKindTeams
+	notificationKindTelegram
 )
 
 const (
@@ -1335,6 +1573,8 @@ const (
 )
 
 const (
+	fieldNotificationEndpointAPIKey     = "apiKey"
+	fieldNotificationEndpointChatID     = "chatID"
 	fieldNotificationEndpointHTTPMethod = "method"
 	fieldNotificationEndpointPassword   = "password"
 	fieldNotificationEndpointRoutingKey = "routingKey"
@@ -1348,6 +1588,8 @@ type notificationEndpoint struct {
 	id          influxdb.ID
 	OrgID       influxdb.ID
 	name        *references
+	apiKey      *references
+	chatID      string
 	description string
 	method      string
 	password    *references
@@ -1441,6 +1683,24 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 			URL:   n.url,
 			Token: n.token.SecretField(),
 		}
+	case notificationKindOpsgenie:
+		sum.NotificationEndpoint = &endpoint.Opsgenie{
+			Base:   base,
+			URL:    n.url,
+			APIKey: n.apiKey.SecretField(),
+		}
+	case notificationKindTeams:
+		sum.NotificationEndpoint = &endpoint.Teams{
+			Base: base,
+			URL:  n.url,
+		}
+	case notificationKindTelegram:
+		sum.NotificationEndpoint = &endpoint.Telegram{
+			Base:   base,
+			URL:    n.url,
+			Token:  n.token.SecretField(),
+			ChatID: n.chatID,
+		}
 	}
 	return sum
 }
@@ -1457,7 +1717,9 @@ var validEndpointHTTPMethods = map[string]bool{
 
 func (n *notificationEndpoint) valid() []validationErr {
 	var failures []validationErr
-	if _, err := url.Parse(n.url); err != nil || n.url == "" {
+	// the opsgenie and telegram endpoints default to the urls of their APIs.
+	optionalURL := n.kind == notificationKindOpsgenie || n.kind == notificationKindTelegram
+	if _, err := url.Parse(n.url); err != nil || (n.url == "" && !optionalURL) {
 		failures = append(failures, validationErr{
 			Field: fieldNotificationEndpointURL,
 			Msg:   "must be valid url",
@@ -1480,6 +1742,26 @@ func (n *notificationEndpoint) valid() []validationErr {
 				Msg:   "must be provide",
 			})
 		}
+	case notificationKindOpsgenie:
+		if !n.apiKey.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointAPIKey,
+				Msg:   "must provide non empty string",
+			})
+		}
+	case notificationKindTelegram:
+		if !n.token.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointToken,
+				Msg:   "must provide non empty string",
+			})
+		}
+		if n.chatID == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointChatID,
+				Msg:   "must provide non empty string",
+			})
+		}
 	case notificationKindHTTP:
 		if !validEndpointHTTPMethods[n.method] {
 			failures = append(failures, validationErr{
@@ -1544,6 +1826,7 @@ const (
 	fieldNotificationRulePreviousLevel   = "previousLevel"
 	fieldNotificationRuleStatusRules     = "statusRules"
 	fieldNotificationRuleTagRules        = "tagRules"
+	fieldNotificationRuleTitle           = "title"
 )
 
 type notificationRule struct {
@@ -1559,6 +1842,7 @@ type notificationRule struct {
 	status      string
 	statusRules []struct{ curLvl, prevLvl string }
 	tagRules    []struct{ k, v, op string }
+	title       string
 
 	endpointID   influxdb.ID
 	endpointName *references
@@ -1657,6 +1941,22 @@ func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
 			Channel:         r.channel,
 			MessageTemplate: r.msgTemplate,
 		}
+	case "opsgenie":
+		return &rule.Opsgenie{
+			Base:            base,
+			MessageTemplate: r.msgTemplate,
+		}
+	case "teams":
+		return &rule.Teams{
+			Base:            base,
+			Title:           r.title,
+			MessageTemplate: r.msgTemplate,
+		}
+	case "telegram":
+		return &rule.Telegram{
+			Base:            base,
+			MessageTemplate: r.msgTemplate,
+		}
 	}
 	return nil
 }
@@ -2439,8 +2739,8 @@ type color struct {
 }
 
 // TODO:
-//  - verify templates are desired
-//  - template colors so references can be shared
+//   - verify templates are desired
+//   - template colors so references can be shared
 type colors []*color
 
 func (c colors) influxViewColors() []influxdb.ViewColor {
@@ -2474,8 +2774,9 @@ func (c colors) strings() []string {
 }
 
 // TODO: looks like much of these are actually getting defaults in
-//  the UI. looking at sytem charts, seeign lots of failures for missing
-//  color types or no colors at all.
+//
+//	the UI. looking at sytem charts, seeign lots of failures for missing
+//	color types or no colors at all.
 func (c colors) hasTypes(types ...string) []validationErr {
 	tMap := make(map[string]bool)
 	for _, cc := range c {
@@ -643,6 +643,7 @@ func (p *Pkg) graphBuckets() *parseErr {
 		bkt := &bucket{
 			name:        nameRef,
 			Description: o.Spec.stringShort(fieldDescription),
+			SchemaType:  influxdb.SchemaType(o.Spec.stringShort(fieldBucketSchemaType)),
 		}
 		if rules, ok := o.Spec[fieldBucketRetentionRules].(retentionRules); ok {
 			bkt.RetentionRules = rules
@@ -654,6 +655,32 @@ func (p *Pkg) graphBuckets() *parseErr {
 				})
 			}
 		}
+		for _, r := range o.Spec.slcResource(fieldBucketMeasurementSchemas) {
+			ms := measurementSchema{Name: r.Name()}
+			for _, c := range r.slcResource(fieldMeasurementSchemaColumns) {
+				ms.Columns = append(ms.Columns, influxdb.MeasurementSchemaColumn{
+					Name:     c.Name(),
+					Type:     influxdb.SemanticColumnType(c.stringShort(fieldType)),
+					DataType: influxdb.SchemaColumnDataType(c.stringShort(fieldMeasurementColumnType)),
+				})
+			}
+			bkt.MeasurementSchemas = append(bkt.MeasurementSchemas, ms)
+		}
+		for _, r := range o.Spec.slcResource(fieldBucketDownsamplingPolicies) {
+			dp := downsamplingPolicy{
+				Destination: r.stringShort(fieldDownsamplingDestination),
+				Every:       influxdb.Duration{Duration: r.durationShort(fieldEvery)},
+				Offset:      influxdb.Duration{Duration: r.durationShort(fieldOffset)},
+				TagFilter:   r.mapStrStr(fieldDownsamplingTagFilter),
+			}
+			for typ, fn := range r.mapStrStr(fieldDownsamplingAggregates) {
+				if dp.Aggregates == nil {
+					dp.Aggregates = make(map[influxdb.SchemaColumnDataType]string)
+				}
+				dp.Aggregates[influxdb.SchemaColumnDataType(typ)] = fn
+			}
+			bkt.DownsamplingPolicies = append(bkt.DownsamplingPolicies, dp)
+		}
 		p.setRefs(bkt.name)
 
 		failures := p.parseNestedLabels(o.Spec, func(l *label) error {
@@ -701,6 +728,7 @@ func (p *Pkg) graphChecks() *parseErr {
 	}{
 		{kind: KindCheckThreshold, checkKind: checkKindThreshold},
 		{kind: KindCheckDeadman, checkKind: checkKindDeadman},
+		{kind: KindCheckAnomaly, checkKind: checkKindAnomaly},
 	}
 	var pErr parseErr
 	for _, checkKind := range checkKinds {
@@ -726,6 +754,9 @@ func (p *Pkg) graphChecks() *parseErr {
 				status:        normStr(o.Spec.stringShort(fieldStatus)),
 				statusMessage: o.Spec.stringShort(fieldCheckStatusMessageTemplate),
 				timeSince:     o.Spec.durationShort(fieldCheckTimeSince),
+				baseline:      normStr(o.Spec.stringShort(fieldCheckBaseline)),
+				window:        o.Spec.durationShort(fieldCheckWindow),
+				period:        o.Spec.durationShort(fieldCheckPeriod),
 			}
 			for _, tagRes := range o.Spec.slcResource(fieldCheckTags) {
 				ch.tags = append(ch.tags, struct{ k, v string }{
@@ -744,6 +775,13 @@ func (p *Pkg) graphChecks() *parseErr {
 				})
 			}
 
+			for _, sens := range o.Spec.slcResource(fieldCheckSensitivities) {
+				ch.sensitivities = append(ch.sensitivities, sensitivity{
+					level:      strings.TrimSpace(strings.ToUpper(sens.stringShort(fieldLevel))),
+					deviations: sens.float64Short(fieldCheckDeviations),
+				})
+			}
+
 			failures := p.parseNestedLabels(o.Spec, func(l *label) error {
 				ch.labels = append(ch.labels, l)
 				p.mLabels[l.Name()].setMapping(ch, false)
@@ -812,6 +850,10 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointHTTP,
 			notificationKind: notificationKindHTTP,
 		},
+		{
+			kind:             KindNotificationEndpointOpsgenie,
+			notificationKind: notificationKindOpsgenie,
+		},
 		{
 			kind:             KindNotificationEndpointPagerDuty,
 			notificationKind: notificationKindPagerDuty,
@@ -820,6 +862,14 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointSlack,
 			notificationKind: notificationKindSlack,
 		},
+		{
+			kind:             KindNotificationEndpointTeams,
+			notificationKind: notificationKindTeams,
+		},
+		{
+			kind:             KindNotificationEndpointTelegram,
+			notificationKind: notificationKindTelegram,
+		},
 	}
 
 	var pErr parseErr
@@ -836,6 +886,8 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 			endpoint := &notificationEndpoint{
 				kind:        nk.notificationKind,
 				name:        nameRef,
+				apiKey:      o.Spec.references(fieldNotificationEndpointAPIKey),
+				chatID:      o.Spec.stringShort(fieldNotificationEndpointChatID),
 				description: o.Spec.stringShort(fieldDescription),
 				method:      strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationEndpointHTTPMethod))),
 				httpType:    normStr(o.Spec.stringShort(fieldType)),
@@ -853,7 +905,7 @@ func (p *Pkg) graphNotificationEndpoints() *parseErr {
 			})
 			sort.Sort(endpoint.labels)
 
-			p.setRefs(nameRef, endpoint.password, endpoint.routingKey, endpoint.token, endpoint.username)
+			p.setRefs(nameRef, endpoint.apiKey, endpoint.password, endpoint.routingKey, endpoint.token, endpoint.username)
 
 			p.mNotificationEndpoints[endpoint.Name()] = endpoint
 			return append(failures, endpoint.valid()...)
@@ -880,6 +932,7 @@ func (p *Pkg) graphNotificationRules() *parseErr {
 			msgTemplate:  o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
 			offset:       o.Spec.durationShort(fieldOffset),
 			status:       normStr(o.Spec.stringShort(fieldStatus)),
+			title:        o.Spec.stringShort(fieldNotificationRuleTitle),
 		}
 
 		for _, sRule := range o.Spec.slcResource(fieldNotificationRuleStatusRules) {
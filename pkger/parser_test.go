@@ -35,6 +35,44 @@ func TestParse(t *testing.T) {
 			})
 		})
 
+		t.Run("with measurement schemas should be valid", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_schema.yml", func(t *testing.T, pkg *Pkg) {
+				buckets := pkg.Summary().Buckets
+				require.Len(t, buckets, 1)
+
+				actual := buckets[0]
+				assert.Equal(t, influxdb.SchemaTypeExplicit, actual.SchemaType)
+				require.Len(t, actual.MeasurementSchemas, 1)
+				assert.Equal(t, SummaryMeasurementSchema{
+					Name: "cpu",
+					Columns: []influxdb.MeasurementSchemaColumn{
+						{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+						{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+						{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+					},
+				}, actual.MeasurementSchemas[0])
+			})
+		})
+
+		t.Run("with downsampling policies should be valid", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_downsampling.yml", func(t *testing.T, pkg *Pkg) {
+				buckets := pkg.Summary().Buckets
+				require.Len(t, buckets, 2)
+
+				actual := buckets[0]
+				assert.Equal(t, "rucket_raw", actual.Name)
+				require.Len(t, actual.DownsamplingPolicies, 1)
+				assert.Equal(t, SummaryDownsamplingPolicy{
+					Destination: "rucket_rollup",
+					Every:       influxdb.Duration{Duration: time.Hour},
+					Offset:      influxdb.Duration{Duration: 5 * time.Minute},
+					Aggregates:  map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "mean"},
+					TagFilter:   map[string]string{"host": "a"},
+				}, actual.DownsamplingPolicies[0])
+				assert.Empty(t, buckets[1].DownsamplingPolicies)
+			})
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []testPkgResourceError{
 				{
@@ -45,6 +83,38 @@ func TestParse(t *testing.T) {
 kind: Bucket
 metadata:
 spec:
+`,
+				},
+				{
+					name:           "downsampling policy without destination",
+					validationErrs: 1,
+					valFields:      []string{fieldBucketDownsamplingPolicies},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name:  rucket_11
+spec:
+  downsamplingPolicies:
+    - every: 1h
+      aggregates:
+        float: mean
+`,
+				},
+				{
+					name:           "downsampling policy with aggregates per type for implicit schema",
+					validationErrs: 1,
+					valFields:      []string{fieldBucketDownsamplingPolicies},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name:  rucket_11
+spec:
+  downsamplingPolicies:
+    - destination: rucket_22
+      every: 1h
+      aggregates:
+        float: mean
+        string: last
 `,
 				},
 				{
@@ -413,11 +483,107 @@ spec:
 			})
 		})
 
+		t.Run("with anomaly checks", func(t *testing.T) {
+			testfileRunner(t, "testdata/checks_anomaly.yml", func(t *testing.T, pkg *Pkg) {
+				sum := pkg.Summary()
+				require.Len(t, sum.Checks, 2)
+
+				stddevCheck, ok := sum.Checks[0].Check.(*icheck.Anomaly)
+				require.Truef(t, ok, "got: %#v", sum.Checks[0])
+				assert.Equal(t, "check_anomaly_0", stddevCheck.Name)
+				assert.Equal(t, icheck.BaselineStdDev, stddevCheck.Baseline)
+				assert.Equal(t, mustDuration(t, 24*time.Hour), stddevCheck.Window)
+				assert.Nil(t, stddevCheck.Period)
+				assert.Equal(t, []icheck.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 3},
+					{Level: notification.Warn, Deviations: 2},
+				}, stddevCheck.Sensitivities)
+
+				seasonalCheck, ok := sum.Checks[1].Check.(*icheck.Anomaly)
+				require.Truef(t, ok, "got: %#v", sum.Checks[1])
+				assert.Equal(t, "check_anomaly_1", seasonalCheck.Name)
+				assert.Equal(t, icheck.BaselineSeasonal, seasonalCheck.Baseline)
+				assert.Equal(t, mustDuration(t, time.Hour), seasonalCheck.Window)
+				assert.Equal(t, mustDuration(t, 7*24*time.Hour), seasonalCheck.Period)
+				assert.Equal(t, []icheck.AnomalySensitivity{
+					{Level: notification.Critical, Deviations: 4.5},
+				}, seasonalCheck.Sensitivities)
+			})
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
 				resErr testPkgResourceError
 			}{
+				{
+					kind: KindCheckAnomaly,
+					resErr: testPkgResourceError{
+						name:           "invalid baseline",
+						validationErrs: 1,
+						valFields:      []string{fieldCheckBaseline},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check_1
+spec:
+  baseline: average
+  every: 5m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  sensitivities:
+    - level: crit
+      deviations: 3.0
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testPkgResourceError{
+						name:           "window not greater than every",
+						validationErrs: 1,
+						valFields:      []string{fieldCheckWindow},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check_1
+spec:
+  baseline: mad
+  every: 5m
+  window: 5m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  sensitivities:
+    - level: crit
+      deviations: 3.0
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testPkgResourceError{
+						name:           "invalid sensitivity",
+						validationErrs: 1,
+						valFields:      []string{fieldCheckDeviations},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check_1
+spec:
+  baseline: seasonal
+  every: 5m
+  period: 24h
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  sensitivities:
+    - level: crit
+      deviations: 0
+`,
+					},
+				},
 				{
 					kind: KindCheckDeadman,
 					resErr: testPkgResourceError{
@@ -2362,6 +2528,62 @@ spec:
 			})
 		})
 
+		t.Run("teams, opsgenie and telegram", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_endpoint_teams_opsgenie_telegram", func(t *testing.T, pkg *Pkg) {
+				expectedEndpoints := []SummaryNotificationEndpoint{
+					{
+						NotificationEndpoint: &endpoint.Opsgenie{
+							Base: endpoint.Base{
+								Name:        "opsgenie_notification_endpoint",
+								Description: "opsgenie desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							APIKey: influxdb.SecretField{Value: strPtr("secret api-key")},
+						},
+					},
+					{
+						NotificationEndpoint: &endpoint.Teams{
+							Base: endpoint.Base{
+								Name:        "teams_notification_endpoint",
+								Description: "teams desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							URL: "https://outlook.office.com/webhook/bip/IncomingWebhook/piddy/boppidy",
+						},
+					},
+					{
+						NotificationEndpoint: &endpoint.Telegram{
+							Base: endpoint.Base{
+								Name:        "telegram_notification_endpoint",
+								Description: "telegram desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							Token:  influxdb.SecretField{Value: strPtr("secret bot-token")},
+							ChatID: "@alerts",
+						},
+					},
+				}
+
+				sum := pkg.Summary()
+				endpoints := sum.NotificationEndpoints
+				require.Len(t, endpoints, len(expectedEndpoints))
+				require.Len(t, sum.LabelMappings, len(expectedEndpoints))
+
+				for i := range expectedEndpoints {
+					expected, actual := expectedEndpoints[i], endpoints[i]
+					assert.Equalf(t, expected.NotificationEndpoint, actual.NotificationEndpoint, "index=%d", i)
+					require.Len(t, actual.LabelAssociations, 1)
+					assert.Equal(t, "label_1", actual.LabelAssociations[0].Name)
+
+					containsLabelMappings(t, sum.LabelMappings, labelMapping{
+						labelName: "label_1",
+						resName:   expected.NotificationEndpoint.GetName(),
+						resType:   influxdb.NotificationEndpointResourceType,
+					})
+				}
+			})
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
@@ -2392,6 +2614,49 @@ kind: NotificationEndpointPagerDuty
 metadata:
   name: pager_duty_notification_endpoint
 spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTeams,
+					resErr: testPkgResourceError{
+						name:           "missing teams url",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointURL},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams_notification_endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointOpsgenie,
+					resErr: testPkgResourceError{
+						name:           "missing opsgenie api key",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointAPIKey},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie_notification_endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTelegram,
+					resErr: testPkgResourceError{
+						name:           "missing telegram token and chat id",
+						validationErrs: 1,
+						valFields:      []string{fieldNotificationEndpointToken, fieldNotificationEndpointChatID},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTelegram
+metadata:
+  name: telegram_notification_endpoint
+spec:
+  url: http://localhost:7777
 `,
 					},
 				},
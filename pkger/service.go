@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -42,6 +43,7 @@ type serviceOpt struct {
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
+	schemaSVC   influxdb.MeasurementSchemaService
 }
 
 // ServiceSetterFn is a means of setting dependencies on the Service type.
@@ -124,6 +126,13 @@ func WithVariableSVC(varSVC influxdb.VariableService) ServiceSetterFn {
 	}
 }
 
+// WithMeasurementSchemaSVC sets the measurement schema service.
+func WithMeasurementSchemaSVC(schemaSVC influxdb.MeasurementSchemaService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.schemaSVC = schemaSVC
+	}
+}
+
 // Service provides the pkger business logic including all the dependencies to make
 // this resource sausage.
 type Service struct {
@@ -139,6 +148,7 @@ type Service struct {
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
+	schemaSVC   influxdb.MeasurementSchemaService
 
 	applyReqLimit int
 }
@@ -167,6 +177,7 @@ func NewService(opts ...ServiceSetterFn) *Service {
 		taskSVC:       opt.taskSVC,
 		teleSVC:       opt.teleSVC,
 		varSVC:        opt.varSVC,
+		schemaSVC:     opt.schemaSVC,
 		applyReqLimit: opt.applyReqLimit,
 	}
 }
@@ -247,15 +258,19 @@ func (s *Service) CreatePkg(ctx context.Context, setters ...CreatePkgSetFn) (*Pk
 	var kindPriorities = map[Kind]int{
 		KindLabel:                         1,
 		KindBucket:                        2,
-		KindCheckDeadman:                  3,
-		KindCheckThreshold:                4,
-		KindNotificationEndpointHTTP:      5,
-		KindNotificationEndpointPagerDuty: 6,
-		KindNotificationEndpointSlack:     7,
-		KindNotificationRule:              8,
-		KindVariable:                      9,
-		KindTelegraf:                      10,
-		KindDashboard:                     11,
+		KindCheckAnomaly:                  3,
+		KindCheckDeadman:                  4,
+		KindCheckThreshold:                5,
+		KindNotificationEndpointHTTP:      6,
+		KindNotificationEndpointOpsgenie:  7,
+		KindNotificationEndpointPagerDuty: 8,
+		KindNotificationEndpointSlack:     9,
+		KindNotificationEndpointTeams:     10,
+		KindNotificationEndpointTelegram:  11,
+		KindNotificationRule:              12,
+		KindVariable:                      13,
+		KindTelegraf:                      14,
+		KindDashboard:                     15,
 	}
 
 	sort.Slice(pkg.Objects, func(i, j int) bool {
@@ -538,8 +553,30 @@ func (s *Service) resourceCloneToKind(ctx context.Context, r ResourceToClone, cF
 		if err != nil {
 			return nil, err
 		}
-		newKind = bucketToObject(*bkt, r.Name)
+		var schemas []*influxdb.MeasurementSchema
+		if bkt.SchemaType == influxdb.SchemaTypeExplicit && s.schemaSVC != nil {
+			schemas, err = s.schemaSVC.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: bkt.ID})
+			if err != nil {
+				return nil, err
+			}
+		}
+		var policies []downsamplingPolicy
+		for _, p := range bkt.DownsamplingPolicies {
+			dest, err := s.bucketSVC.FindBucketByID(ctx, p.DestinationBucketID)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, downsamplingPolicy{
+				Destination: dest.Name,
+				Every:       p.Every,
+				Offset:      p.Offset,
+				Aggregates:  p.Aggregates,
+				TagFilter:   p.TagFilter,
+			})
+		}
+		newKind = bucketToObject(*bkt, schemas, policies, r.Name)
 	case r.Kind.is(KindCheck),
+		r.Kind.is(KindCheckAnomaly),
 		r.Kind.is(KindCheckDeadman),
 		r.Kind.is(KindCheckThreshold):
 		ch, err := s.checkSVC.FindCheckByID(ctx, r.ID)
@@ -561,8 +598,11 @@ func (s *Service) resourceCloneToKind(ctx context.Context, r ResourceToClone, cF
 		newKind = labelToObject(*l, r.Name)
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
+		r.Kind.is(KindNotificationEndpointOpsgenie),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
-		r.Kind.is(KindNotificationEndpointSlack):
+		r.Kind.is(KindNotificationEndpointSlack),
+		r.Kind.is(KindNotificationEndpointTeams),
+		r.Kind.is(KindNotificationEndpointTelegram):
 		e, err := s.endpointSVC.FindNotificationEndpointByID(ctx, r.ID)
 		if err != nil {
 			return nil, err
@@ -1197,6 +1237,16 @@ func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg
 		return Summary{}, err
 	}
 
+	// downsampling policies can refer to any bucket, so they are applied once
+	// all buckets exist.
+	app, err = s.applyDownsamplingPoliciesGenerator(ctx, orgID, pkg.buckets())
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := coordinator.runTilEnd(ctx, orgID, userID, app); err != nil {
+		return Summary{}, err
+	}
+
 	// secondary resources
 	// this last grouping relies on the above 2 steps having completely successfully
 	secondary := []applier{s.applyLabelMappings(pkg.labelMappings())}
@@ -1283,8 +1333,16 @@ func (s *Service) rollbackBuckets(buckets []*bucket) error {
 }
 
 func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, error) {
+	if len(b.MeasurementSchemas) > 0 && s.schemaSVC == nil {
+		return influxdb.Bucket{}, errors.New("measurement schemas are not supported")
+	}
+
 	rp := b.RetentionRules.RP()
 	if b.existing != nil {
+		if b.SchemaType != "" && b.SchemaType != b.existing.SchemaType {
+			return influxdb.Bucket{}, fmt.Errorf("schema type of existing bucket cannot be changed to %q", b.SchemaType)
+		}
+
 		influxBucket, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), influxdb.BucketUpdate{
 			Description:     &b.Description,
 			RetentionPeriod: &rp,
@@ -1292,6 +1350,9 @@ func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, e
 		if err != nil {
 			return influxdb.Bucket{}, err
 		}
+		if err := s.applyMeasurementSchemas(ctx, influxBucket.OrgID, influxBucket.ID, b.MeasurementSchemas); err != nil {
+			return influxdb.Bucket{}, err
+		}
 		return *influxBucket, nil
 	}
 
@@ -1300,15 +1361,161 @@ func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, e
 		Description:     b.Description,
 		Name:            b.Name(),
 		RetentionPeriod: rp,
+		SchemaType:      b.SchemaType,
 	}
 	err := s.bucketSVC.CreateBucket(ctx, &influxBucket)
 	if err != nil {
 		return influxdb.Bucket{}, err
 	}
 
+	if err := s.applyMeasurementSchemas(ctx, influxBucket.OrgID, influxBucket.ID, b.MeasurementSchemas); err != nil {
+		// the rollback only knows about buckets that were applied successfully,
+		// so remove the partially applied bucket here.
+		if delErr := s.bucketSVC.DeleteBucket(ctx, influxBucket.ID); delErr != nil {
+			s.log.Error("failed to delete bucket after measurement schema error", zap.Error(delErr))
+		}
+		return influxdb.Bucket{}, err
+	}
+
 	return influxBucket, nil
 }
 
+// applyMeasurementSchemas creates the measurement schemas of a bucket that do not
+// exist yet and adds any new columns to those that do.
+func (s *Service) applyMeasurementSchemas(ctx context.Context, orgID, bucketID influxdb.ID, schemas []measurementSchema) error {
+	for _, ms := range schemas {
+		name := ms.Name
+		existing, err := s.schemaSVC.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{
+			BucketID: bucketID,
+			Name:     &name,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(existing) == 0 {
+			if err := s.schemaSVC.CreateMeasurementSchema(ctx, ms.influxSchema(orgID, bucketID)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(existing[0].Columns, ms.Columns) {
+			continue
+		}
+		_, err = s.schemaSVC.UpdateMeasurementSchema(ctx, existing[0].ID, influxdb.MeasurementSchemaUpdate{Columns: ms.Columns})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) applyDownsamplingPoliciesGenerator(ctx context.Context, orgID influxdb.ID, buckets []*bucket) (applier, error) {
+	var (
+		sources []*bucket
+		errs    applyErrs
+	)
+	for _, b := range buckets {
+		if len(b.DownsamplingPolicies) == 0 {
+			continue
+		}
+
+		var policies []influxdb.DownsamplingPolicy
+		for _, dp := range b.DownsamplingPolicies {
+			dest, err := s.bucketSVC.FindBucketByName(ctx, orgID, dp.Destination)
+			if err != nil {
+				errs = append(errs, &applyErrBody{
+					name: b.Name(),
+					msg:  fmt.Sprintf("destination bucket dependency does not exist; destination=%q", dp.Destination),
+				})
+				break
+			}
+			policies = append(policies, dp.influxPolicy(dest.ID))
+		}
+		if len(policies) == len(b.DownsamplingPolicies) {
+			b.influxPolicies = policies
+			sources = append(sources, b)
+		}
+	}
+
+	if err := errs.toError("bucket", "failed to find dependency"); err != nil {
+		return applier{}, err
+	}
+
+	return s.applyDownsamplingPolicies(sources), nil
+}
+
+func (s *Service) applyDownsamplingPolicies(buckets []*bucket) applier {
+	const resource = "bucket downsampling policies"
+
+	mutex := new(doMutex)
+	rollbackBuckets := make([]*bucket, 0, len(buckets))
+
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
+		var b bucket
+		mutex.Do(func() {
+			b = *buckets[i]
+		})
+
+		_, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), influxdb.BucketUpdate{
+			DownsamplingPolicies: &b.influxPolicies,
+		})
+		if err != nil {
+			return &applyErrBody{
+				name: b.Name(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			rollbackBuckets = append(rollbackBuckets, buckets[i])
+		})
+
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(buckets),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn: func(_ influxdb.ID) error {
+				return s.rollbackDownsamplingPolicies(rollbackBuckets)
+			},
+		},
+	}
+}
+
+func (s *Service) rollbackDownsamplingPolicies(buckets []*bucket) error {
+	var errs []string
+	for _, b := range buckets {
+		// new buckets are deleted along with their policies by the bucket rollback.
+		if b.existing == nil {
+			continue
+		}
+
+		policies := b.existing.DownsamplingPolicies
+		if policies == nil {
+			policies = []influxdb.DownsamplingPolicy{}
+		}
+		_, err := s.bucketSVC.UpdateBucket(context.Background(), b.ID(), influxdb.BucketUpdate{
+			DownsamplingPolicies: &policies,
+		})
+		if err != nil {
+			errs = append(errs, b.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`bucket_ids=[%s] err="unable to restore downsampling policies"`, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyChecks(checks []*check) applier {
 	const resource = "check"
 
@@ -1047,6 +1047,33 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("successfully creates teams, opsgenie and telegram endpoints", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_endpoint_teams_opsgenie_telegram.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
+						return nil
+					}
+
+					svc := newTestService(WithNotificationEndpointSVC(fakeEndpointSVC))
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.NotificationEndpoints, 3)
+					expectedNames := []string{
+						"opsgenie_notification_endpoint",
+						"teams_notification_endpoint",
+						"telegram_notification_endpoint",
+					}
+					for i, expectedName := range expectedNames {
+						actual := sum.NotificationEndpoints[i].NotificationEndpoint
+						assert.NotZero(t, actual.GetID())
+						assert.Equal(t, expectedName, actual.GetName())
+					}
+				})
+			})
+
 			t.Run("rolls back all created notifications on an error", func(t *testing.T) {
 				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
 					fakeEndpointSVC := mock.NewNotificationEndpointService()
@@ -1530,6 +1557,17 @@ func TestService(t *testing.T) {
 							Level:      notification.Critical,
 						},
 					},
+					{
+						name: "anomaly",
+						expected: &icheck.Anomaly{
+							Base:     newThresholdBase(2),
+							Baseline: icheck.BaselineStdDev,
+							Window:   mustDuration(t, 24*time.Hour),
+							Sensitivities: []icheck.AnomalySensitivity{
+								{Level: notification.Critical, Deviations: 3},
+							},
+						},
+					},
 				}
 
 				for _, tt := range tests {
@@ -1566,6 +1604,13 @@ func TestService(t *testing.T) {
 							expectedName = tt.newName
 						}
 						assert.Equal(t, expectedName, actual.GetName())
+						if expected, ok := tt.expected.(*icheck.Anomaly); ok {
+							anomaly, ok := actual.(*icheck.Anomaly)
+							require.Truef(t, ok, "got: %#v", actual)
+							assert.Equal(t, expected.Baseline, anomaly.Baseline)
+							assert.Equal(t, expected.Window, anomaly.Window)
+							assert.Equal(t, expected.Sensitivities, anomaly.Sensitivities)
+						}
 					}
 					t.Run(tt.name, fn)
 				}
@@ -1991,6 +2036,40 @@ func TestService(t *testing.T) {
 							Token: influxdb.SecretField{Key: "tokne"},
 						},
 					},
+					{
+						name: "teams",
+						expected: &endpoint.Teams{
+							Base: endpoint.Base{
+								Name:        "teams-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							URL: "http://example.com",
+						},
+					},
+					{
+						name: "opsgenie",
+						expected: &endpoint.Opsgenie{
+							Base: endpoint.Base{
+								Name:        "opsgenie-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							APIKey: influxdb.SecretField{Key: "-api-key"},
+						},
+					},
+					{
+						name: "telegram",
+						expected: &endpoint.Telegram{
+							Base: endpoint.Base{
+								Name:        "telegram-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							Token:  influxdb.SecretField{Key: "-token"},
+							ChatID: "-12345",
+						},
+					},
 					{
 						name: "http basic",
 						expected: &endpoint.HTTP{
@@ -2138,6 +2217,56 @@ func TestService(t *testing.T) {
 							MessageTemplate: "SLACK TEMPlate",
 						},
 					},
+					{
+						name: "teams",
+						endpoint: &endpoint.Teams{
+							Base: endpoint.Base{
+								ID:          newTestIDPtr(13),
+								Name:        "endpoint_0",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							URL: "http://example.com",
+						},
+						rule: &rule.Teams{
+							Base:            newRuleBase(13),
+							Title:           "Title",
+							MessageTemplate: "Template",
+						},
+					},
+					{
+						name: "opsgenie",
+						endpoint: &endpoint.Opsgenie{
+							Base: endpoint.Base{
+								ID:          newTestIDPtr(13),
+								Name:        "endpoint_0",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							APIKey: influxdb.SecretField{Key: "-api-key"},
+						},
+						rule: &rule.Opsgenie{
+							Base:            newRuleBase(13),
+							MessageTemplate: "Template",
+						},
+					},
+					{
+						name: "telegram",
+						endpoint: &endpoint.Telegram{
+							Base: endpoint.Base{
+								ID:          newTestIDPtr(13),
+								Name:        "endpoint_0",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							Token:  influxdb.SecretField{Key: "-token"},
+							ChatID: "-12345",
+						},
+						rule: &rule.Telegram{
+							Base:            newRuleBase(13),
+							MessageTemplate: "Template",
+						},
+					},
 					{
 						name: "http none",
 						endpoint: &endpoint.HTTP{
@@ -2232,6 +2361,15 @@ func TestService(t *testing.T) {
 						case *rule.Slack:
 							baseEqual(t, p.Base)
 							assert.Equal(t, p.MessageTemplate, actualRule.MessageTemplate)
+						case *rule.Teams:
+							baseEqual(t, p.Base)
+							assert.Equal(t, p.MessageTemplate, actualRule.MessageTemplate)
+						case *rule.Opsgenie:
+							baseEqual(t, p.Base)
+							assert.Equal(t, p.MessageTemplate, actualRule.MessageTemplate)
+						case *rule.Telegram:
+							baseEqual(t, p.Base)
+							assert.Equal(t, p.MessageTemplate, actualRule.MessageTemplate)
 						}
 
 						require.Len(t, pkg.Summary().NotificationEndpoints, 1)
@@ -267,7 +267,7 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 		cache:    cache,
 		spec:     spec.Spec,
 		deps:     deps,
-		buf:      storage.NewBufferedPointsWriter(influxdb.DefaultBufferSize, deps.PointsWriter),
+		buf:      storage.NewBufferedPointsWriter(influxdb.DefaultBufferSize, deps.PointsWriterFor(ctx)),
 	}, nil
 }
 
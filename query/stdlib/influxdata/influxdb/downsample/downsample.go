@@ -0,0 +1,160 @@
+// Package downsample provides the Flux functions used by the tasks that run
+// downsampling policies.
+package downsample
+
+import (
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/plan"
+)
+
+// PackagePath is the import path of the package in Flux.
+const PackagePath = "influxdata/influxdb/downsample"
+
+const (
+	NumericKind    = "downsampleNumeric"
+	NonNumericKind = "downsampleNonNumeric"
+)
+
+const source = `package downsample
+
+// numeric keeps the tables whose _value column holds numbers.
+builtin numeric
+
+// nonNumeric keeps the tables whose _value column holds strings or booleans.
+builtin nonNumeric
+`
+
+func init() {
+	pkg := parser.ParseSource(source)
+	pkg.Path = PackagePath
+	flux.RegisterPackage(pkg)
+
+	signature := flux.FunctionSignature(nil, nil)
+	flux.RegisterPackageValue(PackagePath, "numeric", flux.FunctionValue(NumericKind, createNumericOpSpec, signature))
+	flux.RegisterPackageValue(PackagePath, "nonNumeric", flux.FunctionValue(NonNumericKind, createNonNumericOpSpec, signature))
+	flux.RegisterOpSpec(NumericKind, func() flux.OperationSpec { return &NumericOpSpec{Numeric: true} })
+	flux.RegisterOpSpec(NonNumericKind, func() flux.OperationSpec { return &NumericOpSpec{} })
+	plan.RegisterProcedureSpec(NumericKind, newNumericProcedure, NumericKind)
+	plan.RegisterProcedureSpec(NonNumericKind, newNumericProcedure, NonNumericKind)
+	execute.RegisterTransformation(NumericKind, createNumericTransformation)
+	execute.RegisterTransformation(NonNumericKind, createNumericTransformation)
+}
+
+// NumericOpSpec keeps the tables whose _value column holds numbers, or those
+// whose _value column does not when Numeric is false.
+type NumericOpSpec struct {
+	Numeric bool `json:"numeric"`
+}
+
+func createNumericOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	return &NumericOpSpec{Numeric: true}, nil
+}
+
+func createNonNumericOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	return &NumericOpSpec{}, nil
+}
+
+func (s *NumericOpSpec) Kind() flux.OperationKind {
+	if s.Numeric {
+		return NumericKind
+	}
+	return NonNumericKind
+}
+
+type NumericProcedureSpec struct {
+	plan.DefaultCost
+	Numeric bool
+}
+
+func newNumericProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*NumericOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &NumericProcedureSpec{Numeric: spec.Numeric}, nil
+}
+
+func (s *NumericProcedureSpec) Kind() plan.ProcedureKind {
+	if s.Numeric {
+		return NumericKind
+	}
+	return NonNumericKind
+}
+
+func (s *NumericProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *NumericProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createNumericTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*NumericProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	t, d := NewNumericTransformation(id, s.Numeric)
+	return t, d, nil
+}
+
+type numericTransformation struct {
+	d       *execute.PassthroughDataset
+	numeric bool
+}
+
+// NewNumericTransformation returns a transformation that passes on the tables
+// whose _value column holds numbers when numeric is true, and the other
+// tables when it is false. Tables without a _value column are dropped.
+func NewNumericTransformation(id execute.DatasetID, numeric bool) (execute.Transformation, execute.Dataset) {
+	t := &numericTransformation{
+		d:       execute.NewPassthroughDataset(id),
+		numeric: numeric,
+	}
+	return t, t.d
+}
+
+func (t *numericTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *numericTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	idx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+	if idx < 0 || isNumeric(tbl.Cols()[idx].Type) != t.numeric {
+		tbl.Done()
+		return nil
+	}
+	return t.d.Process(tbl)
+}
+
+func (t *numericTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *numericTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *numericTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+func isNumeric(typ flux.ColType) bool {
+	switch typ {
+	case flux.TFloat, flux.TInt, flux.TUInt:
+		return true
+	}
+	return false
+}
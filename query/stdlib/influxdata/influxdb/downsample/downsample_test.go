@@ -0,0 +1,135 @@
+package downsample_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/universe"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/downsample"
+)
+
+func TestNumeric_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "numeric and non-numeric branches",
+			Raw: `import "influxdata/influxdb/downsample"
+data = from(bucket:"mydb") |> range(start: -1h)
+data |> downsample.numeric()
+data |> downsample.nonNumeric()`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID:   "influxDBFrom0",
+						Spec: &influxdb.FromOpSpec{Bucket: "mydb"},
+					},
+					{
+						ID: "range1",
+						Spec: &universe.RangeOpSpec{
+							Start:       flux.Time{IsRelative: true, Relative: -time.Hour},
+							Stop:        flux.Time{IsRelative: true},
+							TimeColumn:  "_time",
+							StartColumn: "_start",
+							StopColumn:  "_stop",
+						},
+					},
+					{
+						ID:   "downsampleNumeric2",
+						Spec: &downsample.NumericOpSpec{Numeric: true},
+					},
+					{
+						ID:   "downsampleNonNumeric3",
+						Spec: &downsample.NumericOpSpec{},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "influxDBFrom0", Child: "range1"},
+					{Parent: "range1", Child: "downsampleNumeric2"},
+					{Parent: "range1", Child: "downsampleNonNumeric3"},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestNumeric_Process(t *testing.T) {
+	cols := func(typ flux.ColType) []flux.ColMeta {
+		return []flux.ColMeta{
+			{Label: "_field", Type: flux.TString},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: typ},
+		}
+	}
+	tables := func() []*executetest.Table {
+		return []*executetest.Table{
+			{
+				KeyCols: []string{"_field"},
+				ColMeta: cols(flux.TFloat),
+				Data:    [][]interface{}{{"usage", execute.Time(1), 1.5}},
+			},
+			{
+				KeyCols: []string{"_field"},
+				ColMeta: cols(flux.TInt),
+				Data:    [][]interface{}{{"count", execute.Time(1), int64(2)}},
+			},
+			{
+				KeyCols: []string{"_field"},
+				ColMeta: cols(flux.TString),
+				Data:    [][]interface{}{{"state", execute.Time(1), "ok"}},
+			},
+			{
+				KeyCols: []string{"_field"},
+				ColMeta: cols(flux.TBool),
+				Data:    [][]interface{}{{"up", execute.Time(1), true}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		numeric bool
+		want    []*executetest.Table
+	}{
+		{
+			name:    "numeric",
+			numeric: true,
+			want:    tables()[:2],
+		},
+		{
+			name:    "non-numeric",
+			numeric: false,
+			want:    tables()[2:],
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var data []flux.Table
+			for _, tbl := range tables() {
+				data = append(data, tbl)
+			}
+			executetest.ProcessTestHelper2(
+				t,
+				data,
+				tc.want,
+				nil,
+				func(id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+					return downsample.NewNumericTransformation(id, tc.numeric)
+				},
+			)
+		})
+	}
+}
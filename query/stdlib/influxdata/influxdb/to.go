@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/influxdata/flux"
@@ -278,6 +279,7 @@ type ToTransformation struct {
 	implicitTagColumns bool
 	deps               ToDependencies
 	buf                *storage.BufferedPointsWriter
+	stats              *WriteStatistics
 }
 
 // RetractTable retracts the table for the transformation for the `to` flux function.
@@ -352,7 +354,8 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 		spec:               toSpec,
 		implicitTagColumns: spec.TagColumns == nil,
 		deps:               deps,
-		buf:                storage.NewBufferedPointsWriter(DefaultBufferSize, deps.PointsWriter),
+		buf:                storage.NewBufferedPointsWriter(DefaultBufferSize, deps.PointsWriterFor(ctx)),
+		stats:              writeStatisticsFrom(ctx),
 	}, nil
 }
 
@@ -483,6 +486,69 @@ func (d ToDependencies) Validate() error {
 	return nil
 }
 
+type pointsSinkKey struct{}
+
+// WithPointsSink returns a context in which the `to` function writes its
+// points to w instead of to its PointsWriter dependency. It allows a query to
+// be executed without persisting what it would have written.
+func WithPointsSink(ctx context.Context, w storage.PointsWriter) context.Context {
+	return context.WithValue(ctx, pointsSinkKey{}, w)
+}
+
+// PointsWriterFor returns the writer the points of a query executing with ctx
+// are written to: the sink of ctx if it has one, and d.PointsWriter otherwise.
+func (d ToDependencies) PointsWriterFor(ctx context.Context) storage.PointsWriter {
+	if w, ok := ctx.Value(pointsSinkKey{}).(storage.PointsWriter); ok {
+		return w
+	}
+	return d.PointsWriter
+}
+
+type writeStatisticsKey struct{}
+
+// WriteStatistics counts the rows that the `to` function writes to each
+// bucket. It is safe for concurrent use.
+type WriteStatistics struct {
+	mu   sync.Mutex
+	rows map[platform.ID]int64
+}
+
+// NewWriteStatistics returns WriteStatistics that haven't counted any rows.
+func NewWriteStatistics() *WriteStatistics {
+	return &WriteStatistics{rows: map[platform.ID]int64{}}
+}
+
+// WithWriteStatistics returns a context in which the `to` function counts
+// the rows it writes in s.
+func WithWriteStatistics(ctx context.Context, s *WriteStatistics) context.Context {
+	return context.WithValue(ctx, writeStatisticsKey{}, s)
+}
+
+func writeStatisticsFrom(ctx context.Context) *WriteStatistics {
+	s, _ := ctx.Value(writeStatisticsKey{}).(*WriteStatistics)
+	return s
+}
+
+// RowsWritten returns the number of rows written to each bucket.
+func (s *WriteStatistics) RowsWritten() map[platform.ID]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := make(map[platform.ID]int64, len(s.rows))
+	for id, n := range s.rows {
+		rows[id] = n
+	}
+	return rows
+}
+
+func (s *WriteStatistics) add(bucketID platform.ID, n int) {
+	if s == nil || n == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.rows[bucketID] += int64(n)
+	s.mu.Unlock()
+}
+
 type Stats struct {
 	NRows    int
 	Latest   time.Time
@@ -670,7 +736,11 @@ func writeTable(ctx context.Context, t *ToTransformation, tbl flux.Table) (err e
 			}
 		}
 
-		return t.buf.WritePoints(ctx, points)
+		if err := t.buf.WritePoints(ctx, points); err != nil {
+			return err
+		}
+		t.stats.add(t.BucketID, len(points))
+		return nil
 	})
 }
 
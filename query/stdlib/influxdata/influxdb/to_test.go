@@ -827,6 +827,119 @@ m,tag1=c,tag2=ee _value=4 41`),
 	}
 }
 
+func TestTo_PointsSink(t *testing.T) {
+	oid, _ := mock.OrganizationLookup{}.Lookup(context.Background(), "my-org")
+	bid, _ := mock.BucketLookup{}.Lookup(context.Background(), oid, "my-bucket")
+	spec := &influxdb.ToProcedureSpec{
+		Spec: &influxdb.ToOpSpec{
+			Org:               "my-org",
+			Bucket:            "my-bucket",
+			TimeColumn:        "_time",
+			MeasurementColumn: "_measurement",
+		},
+	}
+	table := func() *executetest.Table {
+		return &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(11), "a", "_value", 2.0},
+				{execute.Time(21), "b", "_value", 1.0},
+			},
+		}
+	}
+
+	deps := influxdb.Dependencies{
+		FluxDeps: dependenciestest.Default(),
+		StorageDeps: influxdb.StorageDependencies{
+			ToDeps: mockDependencies(),
+		},
+	}
+	sink := new(mock.PointsWriter)
+	executetest.ProcessTestHelper(
+		t,
+		[]flux.Table{executetest.MustCopyTable(table())},
+		[]*executetest.Table{table()},
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			ctx := influxdb.WithPointsSink(deps.Inject(context.Background()), sink)
+			newT, err := influxdb.NewToTransformation(ctx, d, c, spec, deps.StorageDeps.ToDeps)
+			if err != nil {
+				t.Error(err)
+			}
+			return newT
+		},
+	)
+
+	if pw := deps.StorageDeps.ToDeps.PointsWriter.(*mock.PointsWriter); len(pw.Points) != 0 {
+		t.Errorf("expected no points to be written to the points writer, got %d", len(pw.Points))
+	}
+	gotStr := pointsToStr(sink.Points)
+	wantStr := pointsToStr(mockPoints(oid, bid, "a _value=2 11\nb _value=1 21"))
+	if !cmp.Equal(gotStr, wantStr) {
+		t.Errorf("got other than expected %s", cmp.Diff(gotStr, wantStr))
+	}
+}
+
+func TestTo_WriteStatistics(t *testing.T) {
+	oid, _ := mock.OrganizationLookup{}.Lookup(context.Background(), "my-org")
+	bid, _ := mock.BucketLookup{}.Lookup(context.Background(), oid, "my-bucket")
+	spec := &influxdb.ToProcedureSpec{
+		Spec: &influxdb.ToOpSpec{
+			Org:               "my-org",
+			Bucket:            "my-bucket",
+			TimeColumn:        "_time",
+			MeasurementColumn: "_measurement",
+		},
+	}
+	table := func() *executetest.Table {
+		return &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(11), "a", "_value", 2.0},
+				{execute.Time(21), "b", "_value", 1.0},
+				{execute.Time(31), "c", "_value", 3.0},
+			},
+		}
+	}
+
+	deps := influxdb.Dependencies{
+		FluxDeps: dependenciestest.Default(),
+		StorageDeps: influxdb.StorageDependencies{
+			ToDeps: mockDependencies(),
+		},
+	}
+	stats := influxdb.NewWriteStatistics()
+	executetest.ProcessTestHelper(
+		t,
+		[]flux.Table{executetest.MustCopyTable(table())},
+		[]*executetest.Table{table()},
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			ctx := influxdb.WithWriteStatistics(deps.Inject(context.Background()), stats)
+			newT, err := influxdb.NewToTransformation(ctx, d, c, spec, deps.StorageDeps.ToDeps)
+			if err != nil {
+				t.Error(err)
+			}
+			return newT
+		},
+	)
+
+	want := map[platform.ID]int64{bid: 3}
+	if got := stats.RowsWritten(); !cmp.Equal(got, want) {
+		t.Errorf("unexpected rows written: %s", cmp.Diff(want, got))
+	}
+}
+
 func mockDependencies() influxdb.ToDependencies {
 	return influxdb.ToDependencies{
 		BucketLookup:       mock.BucketLookup{},
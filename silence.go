@@ -0,0 +1,257 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ops for silence errors.
+var (
+	OpFindSilenceByID = "FindSilenceByID"
+	OpFindSilences    = "FindSilences"
+	OpCreateSilence   = "CreateSilence"
+	OpUpdateSilence   = "UpdateSilence"
+	OpDeleteSilence   = "DeleteSilence"
+)
+
+// ErrSilenceNotFound is returned when a silence does not exist.
+var ErrSilenceNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "silence not found",
+}
+
+// SilenceService manages the silences of organizations.
+type SilenceService interface {
+	// FindSilenceByID returns a single silence by ID.
+	FindSilenceByID(ctx context.Context, id ID) (*Silence, error)
+
+	// FindSilences returns the silences that match filter and the total count of matching silences.
+	FindSilences(ctx context.Context, filter SilenceFilter, opt ...FindOptions) ([]*Silence, int, error)
+
+	// CreateSilence creates a new silence and sets s.ID with the new identifier.
+	CreateSilence(ctx context.Context, s *Silence) error
+
+	// UpdateSilence updates a single silence with changeset.
+	// Returns the new silence after update.
+	UpdateSilence(ctx context.Context, id ID, upd SilenceUpdate) (*Silence, error)
+
+	// DeleteSilence removes a silence by ID.
+	DeleteSilence(ctx context.Context, id ID) error
+}
+
+// Silence mutes the notifications of the statuses it matches while one of its
+// windows is open. A status matches a silence when its check name, tags and
+// level match all of the matchers of the silence that are set.
+//
+// The first window of a silence opens at Start and closes at End. A silence
+// with a recurrence opens a window of the same length every Recurrence.Every
+// after Start, until Recurrence.Until.
+type Silence struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// CheckName matches the name of the check of a status. It matches the
+	// statuses of all checks when empty.
+	CheckName string    `json:"checkName,omitempty"`
+	TagRules  []TagRule `json:"tagRules,omitempty"`
+	// Levels matches the level of a status, one of UNKNOWN, OK, INFO, WARN
+	// or CRIT. It matches all levels when empty.
+	Levels []string `json:"levels,omitempty"`
+
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+	Recurrence *SilenceRecurrence `json:"recurrence,omitempty"`
+	CRUDLog
+}
+
+// SilenceRecurrence repeats the window of a silence.
+type SilenceRecurrence struct {
+	// Every is the time between the starts of windows.
+	Every Duration `json:"every"`
+	// Until is when the silence stops opening windows. The silence recurs
+	// forever when Until is nil.
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// SilenceFilter restricts the silences returned by FindSilences.
+type SilenceFilter struct {
+	OrgID        *ID
+	Organization *string
+}
+
+// QueryParams converts SilenceFilter fields to url query params.
+func (f SilenceFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.Organization != nil {
+		qp["org"] = []string{*f.Organization}
+	}
+	return qp
+}
+
+// SilenceUpdate is the changeset of a silence. Fields that are nil are left
+// unchanged.
+type SilenceUpdate struct {
+	Name        *string            `json:"name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	CheckName   *string            `json:"checkName,omitempty"`
+	TagRules    *[]TagRule         `json:"tagRules,omitempty"`
+	Levels      *[]string          `json:"levels,omitempty"`
+	Start       *time.Time         `json:"start,omitempty"`
+	End         *time.Time         `json:"end,omitempty"`
+	Recurrence  *SilenceRecurrence `json:"recurrence,omitempty"`
+}
+
+// Apply applies an update to a silence. A recurrence whose Every is zero
+// removes the recurrence of the silence.
+func (s *Silence) Apply(upd SilenceUpdate) error {
+	if upd.Name != nil {
+		s.Name = *upd.Name
+	}
+	if upd.Description != nil {
+		s.Description = *upd.Description
+	}
+	if upd.CheckName != nil {
+		s.CheckName = *upd.CheckName
+	}
+	if upd.TagRules != nil {
+		s.TagRules = *upd.TagRules
+	}
+	if upd.Levels != nil {
+		s.Levels = *upd.Levels
+	}
+	if upd.Start != nil {
+		s.Start = *upd.Start
+	}
+	if upd.End != nil {
+		s.End = *upd.End
+	}
+	if upd.Recurrence != nil {
+		s.Recurrence = upd.Recurrence
+		if upd.Recurrence.Every.Duration == 0 {
+			s.Recurrence = nil
+		}
+	}
+	return s.Valid()
+}
+
+var silenceLevels = map[string]bool{
+	"UNKNOWN": true,
+	"OK":      true,
+	"INFO":    true,
+	"WARN":    true,
+	"CRIT":    true,
+}
+
+// Valid returns an error if the silence is malformed.
+func (s *Silence) Valid() error {
+	if s.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "silence name is required",
+		}
+	}
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "silence orgID is invalid",
+		}
+	}
+	for _, tr := range s.TagRules {
+		if err := tr.Valid(); err != nil {
+			return err
+		}
+	}
+	for _, l := range s.Levels {
+		if !silenceLevels[strings.ToUpper(l)] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid silence level %q", l),
+			}
+		}
+	}
+	if !s.End.After(s.Start) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "silence end must be after its start",
+		}
+	}
+	if r := s.Recurrence; r != nil {
+		if r.Every.Duration <= s.End.Sub(s.Start) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "silence recurrence must be longer than its window",
+			}
+		}
+		if r.Until != nil && !r.Until.After(s.Start) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "silence recurrence must end after the silence starts",
+			}
+		}
+	}
+	return nil
+}
+
+// ActiveAt returns whether one of the windows of the silence is open at t.
+func (s *Silence) ActiveAt(t time.Time) bool {
+	if t.Before(s.Start) {
+		return false
+	}
+	if s.Recurrence == nil {
+		return t.Before(s.End)
+	}
+	if s.Recurrence.Until != nil && !t.Before(*s.Recurrence.Until) {
+		return false
+	}
+	return t.Sub(s.Start)%s.Recurrence.Every.Duration < s.End.Sub(s.Start)
+}
+
+// Expired returns whether all of the windows of the silence closed before t.
+func (s *Silence) Expired(t time.Time) bool {
+	if s.Recurrence == nil {
+		return !t.Before(s.End)
+	}
+	return s.Recurrence.Until != nil && !t.Before(*s.Recurrence.Until)
+}
+
+// Matches returns whether the silence matches a status of the check named
+// checkName, with tags and level.
+func (s *Silence) Matches(checkName string, tags []Tag, level string) bool {
+	if s.CheckName != "" && s.CheckName != checkName {
+		return false
+	}
+	if len(s.Levels) > 0 {
+		var ok bool
+		for _, l := range s.Levels {
+			ok = ok || strings.EqualFold(l, level)
+		}
+		if !ok {
+			return false
+		}
+	}
+	for _, tr := range s.TagRules {
+		var ok bool
+		for _, t := range tags {
+			if t.Key != tr.Key {
+				continue
+			}
+			switch tr.Operator {
+			case NotEqual:
+				ok = t.Value != tr.Value
+			default:
+				ok = t.Value == tr.Value
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
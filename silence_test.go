@@ -0,0 +1,132 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestSilence_Valid(t *testing.T) {
+	start := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+	silence := func(fn func(s *influxdb.Silence)) *influxdb.Silence {
+		s := &influxdb.Silence{
+			OrgID: 1,
+			Name:  "maintenance",
+			Start: start,
+			End:   start.Add(2 * time.Hour),
+		}
+		fn(s)
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		silence *influxdb.Silence
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			silence: silence(func(s *influxdb.Silence) {}),
+		},
+		{
+			name: "recurring",
+			silence: silence(func(s *influxdb.Silence) {
+				s.Levels = []string{"crit", "WARN"}
+				s.Recurrence = &influxdb.SilenceRecurrence{Every: influxdb.Duration{Duration: 24 * time.Hour}}
+			}),
+		},
+		{
+			name:    "missing name",
+			silence: silence(func(s *influxdb.Silence) { s.Name = "" }),
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			silence: silence(func(s *influxdb.Silence) { s.End = start }),
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			silence: silence(func(s *influxdb.Silence) { s.Levels = []string{"ANY"} }),
+			wantErr: true,
+		},
+		{
+			name: "recurrence shorter than the window",
+			silence: silence(func(s *influxdb.Silence) {
+				s.Recurrence = &influxdb.SilenceRecurrence{Every: influxdb.Duration{Duration: time.Hour}}
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.silence.Valid()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestSilence_ActiveAt(t *testing.T) {
+	start := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+	until := start.Add(48 * time.Hour)
+	s := &influxdb.Silence{
+		Start: start,
+		End:   start.Add(2 * time.Hour),
+		Recurrence: &influxdb.SilenceRecurrence{
+			Every: influxdb.Duration{Duration: 24 * time.Hour},
+			Until: &until,
+		},
+	}
+
+	tests := []struct {
+		t      time.Time
+		active bool
+	}{
+		{t: start.Add(-time.Minute)},
+		{t: start, active: true},
+		{t: start.Add(2 * time.Hour)},
+		{t: start.Add(25 * time.Hour), active: true},
+		{t: start.Add(27 * time.Hour)},
+		{t: until.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		if got := s.ActiveAt(tt.t); got != tt.active {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.t, got, tt.active)
+		}
+	}
+
+	if s.Expired(start.Add(30 * time.Hour)) {
+		t.Error("expected the silence to recur until its recurrence ends")
+	}
+	if !s.Expired(until) {
+		t.Error("expected the silence to expire when its recurrence ends")
+	}
+}
+
+func TestSilence_Matches(t *testing.T) {
+	s := &influxdb.Silence{
+		CheckName: "cpu",
+		Levels:    []string{"CRIT"},
+		TagRules: []influxdb.TagRule{
+			{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.Equal},
+			{Tag: influxdb.Tag{Key: "region", Value: "west"}, Operator: influxdb.NotEqual},
+		},
+	}
+	tags := []influxdb.Tag{{Key: "host", Value: "a"}, {Key: "region", Value: "east"}}
+
+	if !s.Matches("cpu", tags, "crit") {
+		t.Error("expected the silence to match")
+	}
+	if s.Matches("mem", tags, "crit") {
+		t.Error("expected the silence not to match another check")
+	}
+	if s.Matches("cpu", tags, "warn") {
+		t.Error("expected the silence not to match another level")
+	}
+	if s.Matches("cpu", []influxdb.Tag{{Key: "host", Value: "a"}, {Key: "region", Value: "west"}}, "crit") {
+		t.Error("expected the silence not to match an excluded tag")
+	}
+}
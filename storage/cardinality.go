@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.CardinalityService = (*Engine)(nil)
+
+// BucketCardinality returns a report of the series cardinality of a bucket
+// computed from the live index. The report does not include growth, which
+// is tracked by a CardinalityService.
+//
+// The number of series of the bucket is exact. The number of series of each
+// measurement and of values of each tag key are estimated with HyperLogLog
+// sketches, which are built from the index the first time the bucket is
+// reported, and kept up to date as series are created.
+func (e *Engine) BucketCardinality(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	if e.closing == nil {
+		e.mu.RUnlock()
+		return nil, ErrEngineClosed
+	}
+	ref, err := e.index.Acquire()
+	e.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	defer ref.Release()
+
+	topN := opt.TopN
+	if topN <= 0 {
+		topN = influxdb.DefaultCardinalityTopN
+	}
+
+	name := tsdb.EncodeNameSlice(orgID, bucketID)
+	card := &influxdb.BucketCardinality{
+		BucketID:  bucketID,
+		Estimated: true,
+		SampledAt: time.Now().UTC(),
+	}
+	if card.SeriesN, err = e.index.MeasurementSeriesN(name); err != nil {
+		return nil, err
+	}
+	if card.Measurements, card.TagKeys, err = e.sketches.Cardinalities(name, func(add func(tags models.Tags, key []byte)) error {
+		return e.sketchSeries(ctx, name, add)
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(card.Measurements, func(i, j int) bool {
+		a, b := card.Measurements[i], card.Measurements[j]
+		return a.SeriesN > b.SeriesN || a.SeriesN == b.SeriesN && a.Name < b.Name
+	})
+	if len(card.Measurements) > topN {
+		card.Measurements = card.Measurements[:topN]
+	}
+
+	sort.Slice(card.TagKeys, func(i, j int) bool {
+		a, b := card.TagKeys[i], card.TagKeys[j]
+		return a.ValuesN > b.ValuesN || a.ValuesN == b.ValuesN && a.Key < b.Key
+	})
+	if len(card.TagKeys) > topN {
+		card.TagKeys = card.TagKeys[:topN]
+	}
+
+	return card, nil
+}
+
+// sketchSeries calls add with the tags and the key of every series of the
+// bucket with the encoded name.
+func (e *Engine) sketchSeries(ctx context.Context, name []byte, add func(tags models.Tags, key []byte)) error {
+	itr, err := e.index.MeasurementSeriesIDIterator(name)
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	var tags models.Tags
+	for {
+		elem, err := itr.Next()
+		if err != nil {
+			return err
+		} else if elem.SeriesID.IsZero() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := e.sfile.SeriesKey(elem.SeriesID)
+		if len(key) == 0 {
+			continue
+		}
+		_, tags = tsdb.ParseSeriesKeyInto(key, tags[:0])
+		add(tags, key)
+	}
+}
+
+// cardinalitySketches keeps HyperLogLog sketches of the series of each
+// measurement and of the values of each tag key of buckets, so that their
+// cardinality is estimated without reading every series of a bucket on every
+// report. Like the counts of a seriesLimiter, the sketches of a bucket are
+// built the first time they are needed, and maintained by SeriesCreated.
+type cardinalitySketches struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketSketches // By encoded name.
+}
+
+// bucketSketches are the sketches of a bucket.
+type bucketSketches struct {
+	measurements map[string]*hll.Plus // Series keys, by measurement.
+	tagKeys      map[string]*hll.Plus // Tag values, by tag key.
+}
+
+func newCardinalitySketches() *cardinalitySketches {
+	return &cardinalitySketches{buckets: make(map[string]*bucketSketches)}
+}
+
+// Cardinalities returns the estimated number of series of each measurement
+// and of values of each tag key of the bucket with the encoded name. The
+// sketches of the bucket are built with buildFn the first time, which must
+// call add with every series of the bucket.
+//
+// The sketches are registered before they are built, so series created while
+// they are built are added too. Series added twice are only counted once.
+func (s *cardinalitySketches) Cardinalities(name []byte, buildFn func(add func(tags models.Tags, key []byte)) error) ([]influxdb.MeasurementCardinality, []influxdb.TagKeyCardinality, error) {
+	s.mu.Lock()
+	b, ok := s.buckets[string(name)]
+	s.mu.Unlock()
+
+	if !ok {
+		b = &bucketSketches{
+			measurements: make(map[string]*hll.Plus),
+			tagKeys:      make(map[string]*hll.Plus),
+		}
+		s.mu.Lock()
+		s.buckets[string(name)] = b
+		s.mu.Unlock()
+
+		if err := buildFn(func(tags models.Tags, key []byte) {
+			s.mu.Lock()
+			b.add(tags, key)
+			s.mu.Unlock()
+		}); err != nil {
+			s.Reset(name)
+			return nil, nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	measurements := make([]influxdb.MeasurementCardinality, 0, len(b.measurements))
+	for m, sketch := range b.measurements {
+		measurements = append(measurements, influxdb.MeasurementCardinality{Name: m, SeriesN: int64(sketch.Count())})
+	}
+	tagKeys := make([]influxdb.TagKeyCardinality, 0, len(b.tagKeys))
+	for k, sketch := range b.tagKeys {
+		tagKeys = append(tagKeys, influxdb.TagKeyCardinality{Key: k, ValuesN: int64(sketch.Count())})
+	}
+	return measurements, tagKeys, nil
+}
+
+// SeriesCreated adds a series created in the bucket with the encoded name to
+// its sketches. Buckets whose sketches have not been built yet are ignored.
+func (s *cardinalitySketches) SeriesCreated(name []byte, tags models.Tags) {
+	if len(name) != encodedNameLen {
+		return
+	}
+
+	s.mu.Lock()
+	if b, ok := s.buckets[string(name)]; ok {
+		b.add(tags, tsdb.AppendSeriesKey(nil, name, tags))
+	}
+	s.mu.Unlock()
+}
+
+// Reset discards the sketches of the bucket with the encoded name, so that
+// they are built again. It must be called when series are deleted from the
+// bucket, as series can't be removed from a sketch.
+func (s *cardinalitySketches) Reset(name []byte) {
+	s.mu.Lock()
+	delete(s.buckets, string(name))
+	s.mu.Unlock()
+}
+
+// add adds a series to the sketches of its measurement and tag keys.
+func (b *bucketSketches) add(tags models.Tags, key []byte) {
+	for _, t := range tags {
+		switch {
+		case bytes.Equal(t.Key, models.MeasurementTagKeyBytes):
+			sketchOf(b.measurements, t.Value).Add(key)
+		case bytes.Equal(t.Key, models.FieldKeyTagKeyBytes):
+		default:
+			sketchOf(b.tagKeys, t.Key).Add(t.Value)
+		}
+	}
+}
+
+// sketchOf returns the sketch of k in sketches, adding it if it is missing.
+func sketchOf(sketches map[string]*hll.Plus, k []byte) *hll.Plus {
+	sketch, ok := sketches[string(k)]
+	if !ok {
+		sketch = hll.NewDefaultPlus()
+		sketches[string(k)] = sketch
+	}
+	return sketch
+}
+
+// CardinalityService reports the cardinality of buckets along with their
+// growth over the growth window. Each report is recorded as a sample of its
+// bucket, at most once per sample interval, so that the growth reported does
+// not depend on who asked for a report before, and survives restarts.
+type CardinalityService struct {
+	influxdb.CardinalityService
+	Samples influxdb.CardinalitySampleService
+
+	log *zap.Logger
+	now func() time.Time
+}
+
+// NewCardinalityService returns a CardinalityService reporting the cardinality
+// of buckets from s and their growth from the samples of samples.
+func NewCardinalityService(log *zap.Logger, s influxdb.CardinalityService, samples influxdb.CardinalitySampleService) *CardinalityService {
+	return &CardinalityService{
+		CardinalityService: s,
+		Samples:            samples,
+		log:                log,
+		now:                time.Now,
+	}
+}
+
+// BucketCardinality returns the current cardinality of a bucket, and its growth
+// since the oldest sample of the bucket in the growth window.
+func (s *CardinalityService) BucketCardinality(ctx context.Context, orgID, bucketID influxdb.ID, opt influxdb.CardinalityOptions) (*influxdb.BucketCardinality, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	card, err := s.CardinalityService.BucketCardinality(ctx, orgID, bucketID, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	samples, err := s.Samples.FindCardinalitySamples(ctx, bucketID, now.Add(-influxdb.CardinalityGrowthWindow))
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) > 0 {
+		oldest := samples[0]
+		card.Growth = &influxdb.CardinalityGrowth{
+			Since:   oldest.Time,
+			SeriesN: card.SeriesN - oldest.SeriesN,
+		}
+	}
+
+	if len(samples) == 0 || now.Sub(samples[len(samples)-1].Time) >= influxdb.CardinalitySampleInterval {
+		sample := &influxdb.CardinalitySample{BucketID: bucketID, SeriesN: card.SeriesN, Time: now}
+		if err := s.Samples.AddCardinalitySample(ctx, sample); err != nil {
+			// The report is still valid without the sample.
+			s.log.Info("Unable to record bucket cardinality sample", zap.Stringer("bucket_id", bucketID), zap.Error(err))
+		}
+	}
+
+	return card, nil
+}
@@ -61,6 +61,9 @@ type Engine struct {
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
+	seriesLimiter *seriesLimiter
+	sketches      *cardinalitySketches
+
 	defaultMetricLabels prometheus.Labels
 
 	// Tracks all goroutines started by the Engine.
@@ -124,6 +127,15 @@ func WithRetentionEnforcerLimiter(f runnable) Option {
 	}
 }
 
+// WithSeriesLimits enforces the series limits configured on buckets when
+// writing points. Points that would create new series in a bucket that has
+// reached its limit are dropped.
+func WithSeriesLimits(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.seriesLimiter = newSeriesLimiter(finder)
+	}
+}
+
 // WithFileStoreObserver makes the engine have the provided file store observer.
 func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	return func(e *Engine) {
@@ -163,6 +175,7 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 		path:                path,
 		defaultMetricLabels: prometheus.Labels{},
 		logger:              zap.NewNop(),
+		sketches:            newCardinalitySketches(),
 	}
 
 	// Initialize series file.
@@ -171,7 +184,8 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 
 	// Initialise index.
 	e.index = tsi1.NewIndex(e.sfile, c.Index,
-		tsi1.WithPath(c.GetIndexPath(path)))
+		tsi1.WithPath(c.GetIndexPath(path)),
+		tsi1.WithSeriesCreatedFn(e.seriesCreated))
 
 	// Initialize WAL
 	e.wal = wal.NewWAL(c.GetWALPath(path))
@@ -268,6 +282,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		e.runRetentionEnforcer()
 	}
 
+	if e.config.Engine.Tiering.ColdPath != "" {
+		e.runTiering()
+	}
+
 	return nil
 }
 
@@ -513,6 +531,13 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	}
 	collection.Truncate(j)
 
+	// Look up the series limits before taking the lock, since it may need to
+	// consult the bucket service.
+	var limits map[string]int64
+	if e.seriesLimiter != nil {
+		limits = e.seriesLimits(ctx, collection)
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -520,6 +545,12 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		return ErrEngineClosed
 	}
 
+	if len(limits) > 0 {
+		if err := e.enforceSeriesLimitsLocked(collection, limits, dropPoint); err != nil {
+			return err
+		}
+	}
+
 	// Convert the collection to values for adding to the WAL/Cache.
 	values, err := tsm1.CollectionToValues(collection)
 	if err != nil {
@@ -664,9 +695,25 @@ func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID pl
 	encoded := tsdb.EncodeName(orgID, bucketID)
 	name := models.EscapeMeasurement(encoded[:])
 
+	// Series may be removed from the index, so the bucket must be counted
+	// again before its series limit is next enforced, and sketched again
+	// before its cardinality is next reported.
+	if e.seriesLimiter != nil {
+		defer e.seriesLimiter.Reset(encoded[:])
+	}
+	defer e.sketches.Reset(encoded[:])
+
 	return e.engine.DeletePrefixRange(ctx, name, min, max, pred)
 }
 
+// seriesCreated is called by the index for every series it creates.
+func (e *Engine) seriesCreated(name []byte, tags models.Tags) {
+	if e.seriesLimiter != nil {
+		e.seriesLimiter.SeriesCreated(name)
+	}
+	e.sketches.SeriesCreated(name, tags)
+}
+
 // CreateBackup creates a "snapshot" of all TSM data in the Engine.
 //   1) Snapshot the cache to ensure the backup includes all data written before now.
 //   2) Create hard links to all TSM files, in a new directory within the engine root directory.
@@ -2,22 +2,26 @@ package storage_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/prom/promtest"
+	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestEngine_WriteAndIndex(t *testing.T) {
@@ -490,6 +494,247 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 	}
 }
 
+func TestEngine_WriteSeriesLimit(t *testing.T) {
+	path, _ := ioutil.TempDir("", "storage_engine_test")
+	defer os.RemoveAll(path)
+
+	org, bucket := influxdb.ID(0x3131313131313131), influxdb.ID(0x3232323232323232)
+	buckets := mock.NewBucketService()
+	buckets.FindBucketsFn = func(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		if filter.ID == nil || *filter.ID != bucket {
+			return nil, 0, nil
+		}
+		return []*influxdb.Bucket{{ID: bucket, OrgID: org, MaxSeries: 2}}, 1, nil
+	}
+
+	engine := storage.NewEngine(path, storage.NewConfig(), storage.WithEngineID(rand.Int()), storage.WithNodeID(rand.Int()), storage.WithSeriesLimits(buckets))
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	point := func(bucket influxdb.ID, host string) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(org, bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	// The first two series fit within the limit.
+	if err := engine.WritePoints(context.Background(), []models.Point{point(bucket, "a"), point(bucket, "b")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes to existing series are accepted, new series are dropped.
+	err := engine.WritePoints(context.Background(), []models.Point{point(bucket, "a"), point(bucket, "c"), point(bucket, "d")})
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("expected partial write error, got: %v", err)
+	}
+	if got, exp := perr.Dropped, 2; got != exp {
+		t.Fatalf("got %d dropped points, exp %d", got, exp)
+	}
+	if got, exp := perr.Reason, `max series limit of 2 exceeded for bucket 3232323232323232: measurement "cpu", tag "host"`; got != exp {
+		t.Fatalf("unexpected reason:\n got: %s\nexp: %s", got, exp)
+	}
+
+	// Buckets without a limit are unaffected.
+	other := influxdb.ID(0x3333333333333333)
+	if err := engine.WritePoints(context.Background(), []models.Point{point(other, "a"), point(other, "b"), point(other, "c")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(5); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Deleting the series of the bucket makes room for new ones.
+	if err := engine.DeleteBucket(context.Background(), org, bucket); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.WritePoints(context.Background(), []models.Point{point(bucket, "c"), point(bucket, "d")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_WriteSeriesLimit_BucketLookupError(t *testing.T) {
+	path, _ := ioutil.TempDir("", "storage_engine_test")
+	defer os.RemoveAll(path)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketsFn = func(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return nil, 0, errors.New("bucket service unavailable")
+	}
+
+	engine := storage.NewEngine(path, storage.NewConfig(), storage.WithEngineID(rand.Int()), storage.WithNodeID(rand.Int()), storage.WithSeriesLimits(buckets))
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	// A bucket whose limit cannot be looked up is written to as if unlimited.
+	err := engine.WritePoints(context.Background(), []models.Point{models.MustNewPoint(
+		tsdb.EncodeNameString(influxdb.ID(0x3131313131313131), influxdb.ID(0x3232323232323232)),
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "a"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_BucketCardinality(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	point := func(m, host, region string) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "host": host, "region": region}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("cpu", "a", "west"),
+		point("cpu", "b", "west"),
+		point("cpu", "c", "east"),
+		point("mem", "a", "west"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	card, err := engine.BucketCardinality(context.Background(), engine.org, engine.bucket, influxdb.CardinalityOptions{TopN: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := card.SeriesN, int64(4); got != exp {
+		t.Fatalf("got %d series, exp %d", got, exp)
+	}
+	if exp := []influxdb.MeasurementCardinality{{Name: "cpu", SeriesN: 3}}; !reflect.DeepEqual(card.Measurements, exp) {
+		t.Fatalf("unexpected measurements: got %v, exp %v", card.Measurements, exp)
+	}
+	if exp := []influxdb.TagKeyCardinality{{Key: "host", ValuesN: 3}}; !reflect.DeepEqual(card.TagKeys, exp) {
+		t.Fatalf("unexpected tag keys: got %v, exp %v", card.TagKeys, exp)
+	}
+	if !card.Estimated {
+		t.Fatal("expected the counts of measurements and tag keys to be estimated")
+	}
+	if card.Growth != nil {
+		t.Fatalf("unexpected growth from the engine: %v", card.Growth)
+	}
+
+	// series created after the bucket was sketched are added to its sketches.
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("mem", "b", "west"),
+		point("mem", "c", "west"),
+		point("mem", "d", "north"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	card, err = engine.BucketCardinality(context.Background(), engine.org, engine.bucket, influxdb.CardinalityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []influxdb.MeasurementCardinality{{Name: "mem", SeriesN: 4}, {Name: "cpu", SeriesN: 3}}; !reflect.DeepEqual(card.Measurements, exp) {
+		t.Fatalf("unexpected measurements: got %v, exp %v", card.Measurements, exp)
+	}
+	if exp := []influxdb.TagKeyCardinality{{Key: "host", ValuesN: 4}, {Key: "region", ValuesN: 3}}; !reflect.DeepEqual(card.TagKeys, exp) {
+		t.Fatalf("unexpected tag keys: got %v, exp %v", card.TagKeys, exp)
+	}
+
+	// deleted series are removed from the sketches of the bucket.
+	if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	card, err = engine.BucketCardinality(context.Background(), engine.org, engine.bucket, influxdb.CardinalityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(card.Measurements) != 0 || len(card.TagKeys) != 0 {
+		t.Fatalf("expected no measurements and tag keys after the bucket was deleted, got %v and %v", card.Measurements, card.TagKeys)
+	}
+}
+
+func TestCardinalityService_Growth(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	point := func(host string) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	samples := &cardinalitySamples{}
+	svc := storage.NewCardinalityService(zaptest.NewLogger(t), engine, samples)
+
+	// The first report has nothing to grow from and is recorded as a sample.
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{point("a")}); err != nil {
+		t.Fatal(err)
+	}
+	card, err := svc.BucketCardinality(context.Background(), engine.org, engine.bucket, influxdb.CardinalityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card.Growth != nil {
+		t.Fatalf("unexpected growth on first sample: %v", card.Growth)
+	}
+	if got, exp := len(samples.samples), 1; got != exp {
+		t.Fatalf("got %d samples, exp %d", got, exp)
+	}
+	first := samples.samples[0]
+
+	// Later reports grow from the oldest sample, and are not recorded until the
+	// sample interval has passed.
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{point("b"), point("c")}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		card, err := svc.BucketCardinality(context.Background(), engine.org, engine.bucket, influxdb.CardinalityOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := (&influxdb.CardinalityGrowth{Since: first.Time, SeriesN: 2}); !reflect.DeepEqual(card.Growth, exp) {
+			t.Fatalf("unexpected growth: got %v, exp %v", card.Growth, exp)
+		}
+	}
+	if got, exp := len(samples.samples), 1; got != exp {
+		t.Fatalf("got %d samples, exp %d", got, exp)
+	}
+}
+
+// cardinalitySamples is an in-memory influxdb.CardinalitySampleService.
+type cardinalitySamples struct {
+	samples []*influxdb.CardinalitySample
+}
+
+func (s *cardinalitySamples) AddCardinalitySample(ctx context.Context, sample *influxdb.CardinalitySample) error {
+	s.samples = append(s.samples, sample)
+	return nil
+}
+
+func (s *cardinalitySamples) FindCardinalitySamples(ctx context.Context, bucketID influxdb.ID, since time.Time) ([]*influxdb.CardinalitySample, error) {
+	var found []*influxdb.CardinalitySample
+	for _, sample := range s.samples {
+		if sample.BucketID == bucketID && !sample.Time.Before(since) {
+			found = append(found, sample)
+		}
+	}
+	return found, nil
+}
+
 // BenchmarkWritePoints_100K demonstrates the impact that batch size has on
 // writing a fixed number of points into storage. In this case 100K points are
 // written according to varying batch sizes.
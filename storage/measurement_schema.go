@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// maxSchemaErrors is the maximum number of rejected points reported when a
+// write does not match the schema of its bucket.
+const maxSchemaErrors = 100
+
+// bucketSchemaTTL is how long the schema of a bucket is cached before it is
+// looked up again.
+const bucketSchemaTTL = 30 * time.Second
+
+// SchemaPointsWriter is a PointsWriter that rejects writes to buckets with an
+// explicit schema when any of their points does not match the measurement
+// schemas of the bucket. Every writer of points goes through it, so that
+// writes from tasks are held to the schema as well as writes over HTTP.
+type SchemaPointsWriter struct {
+	PointsWriter
+
+	BucketService            BucketFinder
+	MeasurementSchemaService influxdb.MeasurementSchemaService
+
+	mu      sync.Mutex
+	schemas map[influxdb.ID]cachedBucketSchema
+
+	now func() time.Time
+}
+
+// cachedBucketSchema is a cached bucket schema.
+type cachedBucketSchema struct {
+	schema  *bucketSchema
+	expires time.Time
+}
+
+// NewSchemaPointsWriter returns a SchemaPointsWriter that writes the points
+// that match the schemas of their buckets to w.
+func NewSchemaPointsWriter(w PointsWriter, buckets BucketFinder, schemas influxdb.MeasurementSchemaService) *SchemaPointsWriter {
+	return &SchemaPointsWriter{
+		PointsWriter:             w,
+		BucketService:            buckets,
+		MeasurementSchemaService: schemas,
+		schemas:                  make(map[influxdb.ID]cachedBucketSchema),
+		now:                      time.Now,
+	}
+}
+
+// SchemaService returns a MeasurementSchemaService that changes schemas
+// through the MeasurementSchemaService of w, and drops the cached schemas of
+// w whenever it does so. Schemas changed through other services are picked up
+// once their cached schema expires.
+func (w *SchemaPointsWriter) SchemaService() influxdb.MeasurementSchemaService {
+	return &invalidatingSchemaService{MeasurementSchemaService: w.MeasurementSchemaService, w: w}
+}
+
+// invalidateSchemas drops all cached bucket schemas.
+func (w *SchemaPointsWriter) invalidateSchemas() {
+	w.mu.Lock()
+	w.schemas = make(map[influxdb.ID]cachedBucketSchema)
+	w.mu.Unlock()
+}
+
+type pointLinesKey struct{}
+
+// WithPointLines returns a context that carries the line of the line protocol
+// each point of a write was parsed from, so that schema errors can refer to
+// lines rather than to points.
+func WithPointLines(ctx context.Context, lines []int) context.Context {
+	return context.WithValue(ctx, pointLinesKey{}, lines)
+}
+
+// WritePoints writes the points if they all match the schemas of their
+// buckets. Otherwise nothing is written, and an invalid error that lists the
+// offending points is returned.
+func (w *SchemaPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.validate(ctx, points); err != nil {
+		return err
+	}
+	return w.PointsWriter.WritePoints(ctx, points)
+}
+
+// bucketSchema is the schema of an explicit bucket, by measurement name.
+type bucketSchema struct {
+	name         string
+	measurements map[string]*influxdb.MeasurementSchema
+}
+
+func (w *SchemaPointsWriter) validate(ctx context.Context, points []models.Point) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	lines, _ := ctx.Value(pointLinesKey{}).([]int)
+	position := func(i int) string {
+		if i < len(lines) {
+			return fmt.Sprintf("line %d", lines[i])
+		}
+		return fmt.Sprintf("point %d", i+1)
+	}
+
+	var (
+		buckets = make(map[string]*bucketSchema) // nil for buckets without an explicit schema.
+		failed  []string
+		failedN int
+		lastPos string
+		lastMsg string
+	)
+	for i, p := range points {
+		name := p.Name()
+		s, ok := buckets[string(name)]
+		if !ok {
+			var err error
+			if s, err = w.findBucketSchema(ctx, name); err != nil {
+				return err
+			}
+			buckets[string(name)] = s
+		}
+		if s == nil {
+			continue
+		}
+
+		msg := validatePointSchema(p, s.measurements)
+		if msg == "" {
+			continue
+		}
+
+		// Fields of the same line are separate points, so a problem with the
+		// measurement or tags would otherwise be reported once per field.
+		pos := position(i)
+		if pos == lastPos && msg == lastMsg {
+			continue
+		}
+		lastPos, lastMsg = pos, msg
+
+		failedN++
+		if len(failed) < maxSchemaErrors {
+			failed = append(failed, fmt.Sprintf("%s: bucket %q: %s", pos, s.name, msg))
+		}
+	}
+
+	if failedN == 0 {
+		return nil
+	}
+	if failedN > len(failed) {
+		failed = append(failed, fmt.Sprintf("and %d more", failedN-len(failed)))
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Op:   "storage/WritePoints",
+		Msg:  "write does not match the schemas of its buckets:\n" + strings.Join(failed, "\n"),
+	}
+}
+
+// findBucketSchema returns the schema of the bucket with the encoded name, or
+// nil if the bucket does not have an explicit schema. Schemas are cached so
+// that the bucket and schema services are not consulted on every write.
+func (w *SchemaPointsWriter) findBucketSchema(ctx context.Context, name []byte) (*bucketSchema, error) {
+	if len(name) != encodedNameLen {
+		return nil, nil
+	}
+	_, bucketID := tsdb.DecodeNameSlice(name)
+	now := w.now()
+
+	w.mu.Lock()
+	cached, ok := w.schemas[bucketID]
+	w.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.schema, nil
+	}
+
+	s, err := w.lookupBucketSchema(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.schemas[bucketID] = cachedBucketSchema{schema: s, expires: now.Add(bucketSchemaTTL)}
+	w.mu.Unlock()
+	return s, nil
+}
+
+// lookupBucketSchema looks up the schema of a bucket, or returns nil if the
+// bucket does not have an explicit schema.
+func (w *SchemaPointsWriter) lookupBucketSchema(ctx context.Context, bucketID influxdb.ID) (*bucketSchema, error) {
+	buckets, _, err := w.BucketService.FindBuckets(ctx, influxdb.BucketFilter{ID: &bucketID})
+	if err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 || buckets[0].SchemaType != influxdb.SchemaTypeExplicit {
+		return nil, nil
+	}
+
+	found, err := w.MeasurementSchemaService.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: bucketID})
+	if err != nil {
+		return nil, err
+	}
+	s := &bucketSchema{
+		name:         buckets[0].Name,
+		measurements: make(map[string]*influxdb.MeasurementSchema, len(found)),
+	}
+	for _, ms := range found {
+		s.measurements[ms.Name] = ms
+	}
+	return s, nil
+}
+
+// invalidatingSchemaService is a MeasurementSchemaService that drops the
+// cached schemas of a SchemaPointsWriter when schemas change.
+type invalidatingSchemaService struct {
+	influxdb.MeasurementSchemaService
+	w *SchemaPointsWriter
+}
+
+func (s *invalidatingSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	defer s.w.invalidateSchemas()
+	return s.MeasurementSchemaService.CreateMeasurementSchema(ctx, ms)
+}
+
+func (s *invalidatingSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	defer s.w.invalidateSchemas()
+	return s.MeasurementSchemaService.UpdateMeasurementSchema(ctx, id, upd)
+}
+
+func (s *invalidatingSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	defer s.w.invalidateSchemas()
+	return s.MeasurementSchemaService.DeleteMeasurementSchema(ctx, id)
+}
+
+// validatePointSchema returns a description of why a point does not match its
+// measurement schema, or the empty string if it does.
+func validatePointSchema(p models.Point, schemas map[string]*influxdb.MeasurementSchema) string {
+	tags := p.Tags()
+	name := tags.Get(models.MeasurementTagKeyBytes)
+
+	ms, ok := schemas[string(name)]
+	if !ok {
+		return fmt.Sprintf("measurement %q has no schema", name)
+	}
+
+	for _, t := range tags {
+		if bytes.Equal(t.Key, models.MeasurementTagKeyBytes) || bytes.Equal(t.Key, models.FieldKeyTagKeyBytes) {
+			continue
+		}
+		if c, ok := ms.Column(string(t.Key)); !ok || c.Type != influxdb.SemanticColumnTypeTag {
+			return fmt.Sprintf("tag %q is not a tag of measurement %q", t.Key, name)
+		}
+	}
+
+	itr := p.FieldIterator()
+	if !itr.Next() {
+		return ""
+	}
+	key, got := itr.FieldKey(), schemaDataType(itr.Type())
+
+	c, ok := ms.Column(string(key))
+	if !ok || c.Type != influxdb.SemanticColumnTypeField {
+		return fmt.Sprintf("field %q is not a field of measurement %q", key, name)
+	}
+	if c.DataType != got {
+		return fmt.Sprintf("field %q is %s, schema of measurement %q requires %s", key, got, name, c.DataType)
+	}
+	return ""
+}
+
+// schemaDataType returns the schema data type of a line protocol field type.
+func schemaDataType(typ models.FieldType) influxdb.SchemaColumnDataType {
+	switch typ {
+	case models.Float:
+		return influxdb.SchemaColumnDataTypeFloat
+	case models.Integer:
+		return influxdb.SchemaColumnDataTypeInteger
+	case models.Unsigned:
+		return influxdb.SchemaColumnDataTypeUnsigned
+	case models.String:
+		return influxdb.SchemaColumnDataTypeString
+	case models.Boolean:
+		return influxdb.SchemaColumnDataTypeBoolean
+	default:
+		return influxdb.SchemaColumnDataType(strings.ToLower(typ.String()))
+	}
+}
@@ -0,0 +1,114 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestSchemaPointsWriter(t *testing.T) {
+	const org, explicit, implicit = influxdb.ID(0x3131313131313131), influxdb.ID(0x3232323232323232), influxdb.ID(0x3333333333333333)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketsFn = func(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		b := &influxdb.Bucket{ID: *filter.ID, OrgID: org, Name: "implicit"}
+		if *filter.ID == explicit {
+			b.Name, b.SchemaType = "explicit", influxdb.SchemaTypeExplicit
+		}
+		return []*influxdb.Bucket{b}, 1, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasF = func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+		return []*influxdb.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+				{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+				{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+			},
+		}}, nil
+	}
+
+	point := func(bucket influxdb.ID, tags map[string]string, value interface{}) models.Point {
+		tags[models.FieldKeyTagKey] = "usage"
+		tags[models.MeasurementTagKey] = "cpu"
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(org, bucket),
+			models.NewTags(tags),
+			map[string]interface{}{"usage": value},
+			time.Unix(1, 2),
+		)
+	}
+
+	t.Run("points matching the schema are written", func(t *testing.T) {
+		pw := &mock.PointsWriter{}
+		w := storage.NewSchemaPointsWriter(pw, buckets, schemas)
+		if err := w.WritePoints(context.Background(), []models.Point{
+			point(explicit, map[string]string{"host": "a"}, 1.5),
+			point(implicit, map[string]string{"region": "west"}, "anything"),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := len(pw.Points), 2; got != exp {
+			t.Fatalf("got %d points written, exp %d", got, exp)
+		}
+	})
+
+	t.Run("points not matching the schema reject the write", func(t *testing.T) {
+		pw := &mock.PointsWriter{}
+		w := storage.NewSchemaPointsWriter(pw, buckets, schemas)
+		err := w.WritePoints(context.Background(), []models.Point{
+			point(implicit, map[string]string{"host": "a"}, int64(1)),
+			point(explicit, map[string]string{"host": "a"}, int64(1)),
+			point(explicit, map[string]string{"region": "west"}, 1.5),
+		})
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected an invalid error, got %v", err)
+		}
+		exp := "write does not match the schemas of its buckets:\npoint 2: bucket \"explicit\": field \"usage\" is integer, schema of measurement \"cpu\" requires float\npoint 3: bucket \"explicit\": tag \"region\" is not a tag of measurement \"cpu\""
+		if got := influxdb.ErrorMessage(err); got != exp {
+			t.Fatalf("unexpected error message:\ngot: %s\nexp: %s", got, exp)
+		}
+		if len(pw.Points) != 0 {
+			t.Fatalf("expected no points to be written, got %d", len(pw.Points))
+		}
+	})
+	t.Run("schemas are cached until they change", func(t *testing.T) {
+		var lookups int
+		counted := mock.NewMeasurementSchemaService()
+		counted.FindMeasurementSchemasF = func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+			lookups++
+			return schemas.FindMeasurementSchemasF(ctx, filter)
+		}
+
+		w := storage.NewSchemaPointsWriter(&mock.PointsWriter{}, buckets, counted)
+		write := func() {
+			t.Helper()
+			if err := w.WritePoints(context.Background(), []models.Point{
+				point(explicit, map[string]string{"host": "a"}, 1.5),
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		write()
+		write()
+		if lookups != 1 {
+			t.Fatalf("expected the schema to be looked up once, got %d lookups", lookups)
+		}
+
+		if err := w.SchemaService().CreateMeasurementSchema(context.Background(), &influxdb.MeasurementSchema{Name: "mem"}); err != nil {
+			t.Fatal(err)
+		}
+		write()
+		if lookups != 2 {
+			t.Fatalf("expected the schema to be looked up again once it changed, got %d lookups", lookups)
+		}
+	})
+}
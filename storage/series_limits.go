@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// seriesLimitTTL is how long a bucket's series limit is cached before it is
+// looked up again.
+const seriesLimitTTL = 30 * time.Second
+
+// encodedNameLen is the length of an org and bucket pair encoded as a
+// measurement name.
+const encodedNameLen = 16
+
+// A seriesLimiter provides the series limits configured on buckets and keeps a
+// running count of the series in each limited bucket. Limits are cached so
+// that the bucket service is not consulted on every write, and counts are
+// updated as the index creates series so that buckets are not recounted on
+// every write.
+type seriesLimiter struct {
+	// BucketService provides an API for retrieving buckets.
+	BucketService BucketFinder
+
+	mu     sync.Mutex
+	limits map[influxdb.ID]seriesLimit
+	counts map[string]int64 // Series in each limited bucket, by encoded name.
+
+	now func() time.Time
+}
+
+// seriesLimit is a cached bucket series limit.
+type seriesLimit struct {
+	max     int64
+	expires time.Time
+}
+
+// newSeriesLimiter returns a new seriesLimiter that looks up bucket series
+// limits via finder.
+func newSeriesLimiter(finder BucketFinder) *seriesLimiter {
+	return &seriesLimiter{
+		BucketService: finder,
+		limits:        make(map[influxdb.ID]seriesLimit),
+		counts:        make(map[string]int64),
+		now:           time.Now,
+	}
+}
+
+// MaxSeries returns the maximum number of series allowed in the bucket. Zero
+// indicates that the bucket is unlimited. If the bucket cannot be looked up,
+// the last known limit of the bucket is returned along with the error.
+func (l *seriesLimiter) MaxSeries(ctx context.Context, bucketID influxdb.ID) (int64, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	limit, ok := l.limits[bucketID]
+	l.mu.Unlock()
+	if ok && now.Before(limit.expires) {
+		return limit.max, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
+	defer cancel()
+
+	buckets, _, err := l.BucketService.FindBuckets(ctx, influxdb.BucketFilter{ID: &bucketID})
+	if err != nil {
+		return limit.max, err
+	}
+
+	// An unknown bucket is treated as unlimited. This matches how writes
+	// behave when there is no limiter at all.
+	limit = seriesLimit{expires: now.Add(seriesLimitTTL)}
+	if len(buckets) > 0 {
+		limit.max = buckets[0].MaxSeries
+	}
+
+	l.mu.Lock()
+	l.limits[bucketID] = limit
+	l.mu.Unlock()
+
+	return limit.max, nil
+}
+
+// SeriesN returns the number of series in the bucket with the encoded name.
+// The bucket is counted with countFn the first time, and after that the count
+// is maintained by SeriesCreated.
+func (l *seriesLimiter) SeriesN(name []byte, countFn func() (int64, error)) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n, ok := l.counts[string(name)]; ok {
+		return n, nil
+	}
+	n, err := countFn()
+	if err != nil {
+		return 0, err
+	}
+	l.counts[string(name)] = n
+	return n, nil
+}
+
+// SeriesCreated records that a series was created in the bucket with the
+// encoded name. Buckets that have not been counted yet are ignored.
+func (l *seriesLimiter) SeriesCreated(name []byte) {
+	if len(name) != encodedNameLen {
+		return
+	}
+
+	l.mu.Lock()
+	if n, ok := l.counts[string(name)]; ok {
+		l.counts[string(name)] = n + 1
+	}
+	l.mu.Unlock()
+}
+
+// Reset discards the count of the bucket with the encoded name, so that it is
+// counted again. It must be called when series are deleted from the bucket.
+func (l *seriesLimiter) Reset(name []byte) {
+	l.mu.Lock()
+	delete(l.counts, string(name))
+	l.mu.Unlock()
+}
+
+// seriesLimits returns the series limit of each bucket written to by the
+// collection, by encoded name, omitting unlimited buckets. It must be called
+// without holding the engine lock. A bucket whose limit cannot be looked up
+// keeps its last known limit rather than failing the write.
+func (e *Engine) seriesLimits(ctx context.Context, collection *tsdb.SeriesCollection) map[string]int64 {
+	var limits map[string]int64
+	seen := make(map[string]struct{})
+	for iter := collection.Iterator(); iter.Next(); {
+		name := iter.Name()
+		if len(name) != encodedNameLen {
+			continue
+		} else if _, ok := seen[string(name)]; ok {
+			continue
+		}
+		seen[string(name)] = struct{}{}
+
+		_, bucketID := tsdb.DecodeNameSlice(name)
+		max, err := e.seriesLimiter.MaxSeries(ctx, bucketID)
+		if err != nil {
+			e.logger.Info("Unable to look up bucket series limit", zap.Stringer("bucket_id", bucketID), zap.Error(err))
+		}
+		if max > 0 {
+			if limits == nil {
+				limits = make(map[string]int64)
+			}
+			limits[string(name)] = max
+		}
+	}
+	return limits
+}
+
+// enforceSeriesLimitsLocked drops any points in the collection that would
+// create new series in a bucket that has reached its series limit. Points for
+// series that already exist are always accepted. It must be called under the
+// engine lock, with the limits returned by seriesLimits.
+//
+// The limit is a soft one: concurrent writes to the same bucket may each be
+// accepted and together exceed it by a small margin.
+func (e *Engine) enforceSeriesLimitsLocked(collection *tsdb.SeriesCollection, limits map[string]int64, dropPoint func(key []byte, reason string)) error {
+	type bucketSeries struct {
+		max     int64
+		n       int64               // Current number of series including those accepted in this batch.
+		created map[string]struct{} // New series accepted in this batch.
+		reason  string              // Reason for the first point dropped from the bucket.
+	}
+	buckets := make(map[string]*bucketSeries)
+
+	var (
+		buf []byte
+		err error
+	)
+	j := 0
+	for iter := collection.Iterator(); iter.Next(); {
+		name := iter.Name()
+		max, ok := limits[string(name)]
+		if !ok {
+			collection.Copy(j, iter.Index())
+			j++
+			continue
+		}
+
+		b, ok := buckets[string(name)]
+		if !ok {
+			b = &bucketSeries{max: max, created: make(map[string]struct{})}
+			if b.n, err = e.seriesLimiter.SeriesN(name, func() (int64, error) {
+				return e.index.MeasurementSeriesN(name)
+			}); err != nil {
+				return err
+			}
+			buckets[string(name)] = b
+		}
+
+		key := iter.Key()
+		if _, ok := b.created[string(key)]; !ok && !e.seriesExists(name, iter.Tags(), &buf) {
+			if b.n >= b.max {
+				if b.reason == "" {
+					_, bucketID := tsdb.DecodeNameSlice(name)
+					if b.reason, err = e.seriesLimitReason(bucketID, b.max, name, iter.Tags()); err != nil {
+						return err
+					}
+				}
+				dropPoint(key, b.reason)
+				continue
+			}
+			b.created[string(key)] = struct{}{}
+			b.n++
+		}
+
+		collection.Copy(j, iter.Index())
+		j++
+	}
+	collection.Truncate(j)
+	return nil
+}
+
+// seriesExists returns true if the series is present and not deleted in the
+// series file.
+func (e *Engine) seriesExists(name []byte, tags models.Tags, buf *[]byte) bool {
+	*buf = tsdb.AppendSeriesKey((*buf)[:0], name, tags)
+	id := e.sfile.SeriesIDTypedBySeriesKey(*buf).SeriesID()
+	return !id.IsZero() && !e.sfile.IsDeleted(id)
+}
+
+// seriesLimitReason describes a point dropped because its bucket reached its
+// series limit. It names the measurement and the tag with the most distinct
+// values in that bucket, which is most likely responsible for the growth.
+func (e *Engine) seriesLimitReason(bucketID influxdb.ID, max int64, name []byte, tags models.Tags) (string, error) {
+	var (
+		measurement = tags.Get(models.MeasurementTagKeyBytes)
+		tagKey      []byte
+		tagN        = -1
+	)
+	for _, t := range tags {
+		if bytes.Equal(t.Key, models.MeasurementTagKeyBytes) || bytes.Equal(t.Key, models.FieldKeyTagKeyBytes) {
+			continue
+		}
+
+		n, err := e.tagValueN(name, t.Key)
+		if err != nil {
+			return "", err
+		} else if n > tagN {
+			tagKey, tagN = t.Key, n
+		}
+	}
+
+	if tagKey == nil {
+		return fmt.Sprintf("max series limit of %d exceeded for bucket %s: measurement %q", max, bucketID, measurement), nil
+	}
+	return fmt.Sprintf("max series limit of %d exceeded for bucket %s: measurement %q, tag %q", max, bucketID, measurement, tagKey), nil
+}
+
+// tagValueN returns the number of distinct values for the tag key.
+func (e *Engine) tagValueN(name, key []byte) (int, error) {
+	itr, err := e.index.TagValueIterator(name, key)
+	if err != nil {
+		return 0, err
+	} else if itr == nil {
+		return 0, nil
+	}
+	defer itr.Close()
+
+	var n int
+	for {
+		v, err := itr.Next()
+		if err != nil {
+			return 0, err
+		} else if v == nil {
+			return n, nil
+		}
+		n++
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+// runTiering periodically moves TSM files whose data is older than the
+// configured cold age to the cold tier, in a separate goroutine.
+func (e *Engine) runTiering() {
+	config := e.config.Engine.Tiering
+	interval, age := time.Duration(config.CheckInterval), time.Duration(config.ColdAge)
+
+	if interval <= 0 || age <= 0 {
+		e.logger.Error("Invalid tiering configuration",
+			logger.DurationLiteral("check_interval", interval),
+			logger.DurationLiteral("cold_age", age))
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "tiering"),
+		zap.String("cold_path", config.ColdPath),
+		logger.DurationLiteral("check_interval", interval),
+		logger.DurationLiteral("cold_age", age))
+	l.Info("Starting")
+
+	ticker := time.NewTicker(interval)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			// It's safe to read closing without a lock because it's never
+			// modified if this goroutine is active.
+			select {
+			case <-e.closing:
+				l.Info("Stopping")
+				return
+			case <-ticker.C:
+				e.tierColdFiles(l, time.Now().Add(-age))
+			}
+		}
+	}()
+}
+
+// tierColdFiles moves the TSM files whose data is all older than before to the
+// cold tier.
+func (e *Engine) tierColdFiles(l *zap.Logger, before time.Time) {
+	span, ctx := tracing.StartSpanFromContext(context.Background())
+	defer span.Finish()
+
+	log, logEnd := logger.NewOperation(ctx, l, "Cold tier check", "cold_tier_check",
+		zap.Time("before", before))
+	defer logEnd()
+
+	moved, err := e.engine.TierColdFiles(ctx, before.UnixNano())
+	if err != nil {
+		log.Error("Unable to move files to cold tier", zap.Int("moved", moved), zap.Error(err))
+		tracing.LogError(span, err)
+		return
+	}
+	if moved > 0 {
+		log.Info("Moved files to cold tier", zap.Int("moved", moved))
+	}
+}
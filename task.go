@@ -27,6 +27,9 @@ const (
 var (
 	// TaskSystemType is the type set in tasks' for all crud requests
 	TaskSystemType = "system"
+
+	// TaskDownsamplingType is the type of the tasks that run bucket downsampling policies.
+	TaskDownsamplingType = "downsampling"
 )
 
 // TODO: these are temporary functions until we can work through optimizing auth
@@ -57,10 +60,15 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          time.Duration          `json:"offset,omitempty"`
+	Location        string                 `json:"location,omitempty"`
+	DependsOn       []ID                   `json:"dependsOn,omitempty"`
+	Notification    *TaskNotification      `json:"notification,omitempty"`
+	Version         int                    `json:"version,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
+	FailedRuns      int                    `json:"failedRuns,omitempty"` // FailedRuns is the number of runs in a row that failed, up to the latest run
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
@@ -81,6 +89,71 @@ func (t *Task) EffectiveCron() string {
 	return ""
 }
 
+// TaskNotifyPolicy is when the runs of a task are notified to the endpoint
+// of its notification.
+type TaskNotifyPolicy string
+
+const (
+	// TaskNotifyEveryFailure notifies every run that fails.
+	TaskNotifyEveryFailure TaskNotifyPolicy = "every"
+	// TaskNotifyConsecutiveFailures notifies once when a number of runs in a
+	// row have failed.
+	TaskNotifyConsecutiveFailures TaskNotifyPolicy = "consecutive"
+	// TaskNotifyRecovery notifies the first run that fails after a run that
+	// succeeded, and the first run that succeeds after runs that failed.
+	TaskNotifyRecovery TaskNotifyPolicy = "recovery"
+)
+
+// TaskNotification sends notifications to a notification endpoint when the
+// runs of a task fail.
+type TaskNotification struct {
+	EndpointID ID               `json:"endpointID"`
+	Policy     TaskNotifyPolicy `json:"policy"`
+	// Failures is the number of runs in a row that fail before a
+	// notification is sent with TaskNotifyConsecutiveFailures.
+	Failures int `json:"failures,omitempty"`
+}
+
+// Valid returns an error if the notification is invalid.
+func (n *TaskNotification) Valid() error {
+	if !n.EndpointID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task notification endpoint ID is invalid",
+		}
+	}
+	switch n.Policy {
+	case TaskNotifyEveryFailure, TaskNotifyRecovery:
+	case TaskNotifyConsecutiveFailures:
+		if n.Failures < 1 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "task notification must notify after at least 1 failure",
+			}
+		}
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid task notification policy %q", n.Policy),
+		}
+	}
+	return nil
+}
+
+// Notifies reports whether a run of the task that finished with status,
+// after failed runs in a row had failed before it, is notified.
+func (n *TaskNotification) Notifies(status string, failed int) bool {
+	switch n.Policy {
+	case TaskNotifyEveryFailure:
+		return status == "failed"
+	case TaskNotifyConsecutiveFailures:
+		return status == "failed" && failed+1 == n.Failures
+	case TaskNotifyRecovery:
+		return (status == "failed" && failed == 0) || (status == "success" && failed > 0)
+	}
+	return false
+}
+
 // Run is a record createId when a run of a task is scheduled.
 type Run struct {
 	ID           ID        `json:"id,omitempty"`
@@ -91,7 +164,39 @@ type Run struct {
 	StartedAt    time.Time `json:"startedAt,omitempty"`   // StartedAt is the time the executor begins running the task
 	FinishedAt   time.Time `json:"finishedAt,omitempty"`  // FinishedAt is the time the executor finishes running the task
 	RequestedAt  time.Time `json:"requestedAt,omitempty"` // RequestedAt is the time the coordinator told the scheduler to schedule the task
+	TaskVersion  int       `json:"taskVersion,omitempty"` // TaskVersion is the version of the task's script the run executes
 	Log          []Log     `json:"log,omitempty"`
+
+	// Statistics is what the query of the run read and wrote, once the run
+	// has finished.
+	Statistics *RunStatistics `json:"statistics,omitempty"`
+
+	// FailedRuns is the number of runs of the task in a row that failed
+	// before the run, once the run has finished.
+	FailedRuns int `json:"failedRuns,omitempty"`
+}
+
+// RunStatistics is what the query of a run read and wrote.
+type RunStatistics struct {
+	// ScannedValues and ScannedBytes are read from storage.
+	ScannedValues int64 `json:"scannedValues"`
+	ScannedBytes  int64 `json:"scannedBytes"`
+
+	// MaxAllocated is the most memory the query held at once, and
+	// TotalAllocated is all the memory it allocated, in bytes.
+	MaxAllocated   int64 `json:"maxAllocated"`
+	TotalAllocated int64 `json:"totalAllocated"`
+
+	// RowsWritten is the number of rows that the query wrote with to(), of
+	// which Writes holds the number per bucket.
+	RowsWritten int64            `json:"rowsWritten"`
+	Writes      []RunBucketWrite `json:"writes,omitempty"`
+}
+
+// RunBucketWrite is the number of rows that a run wrote to a bucket.
+type RunBucketWrite struct {
+	BucketID ID    `json:"bucketID"`
+	Rows     int64 `json:"rows"`
 }
 
 // Log represents a link to a log resource
@@ -154,6 +259,7 @@ type TaskCreate struct {
 	Organization   string                 `json:"org,omitempty"`
 	OwnerID        ID                     `json:"-"`
 	Metadata       map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+	Notification   *TaskNotification      `json:"notification,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
@@ -164,6 +270,8 @@ func (t TaskCreate) Validate() error {
 		return errors.New("missing orgID and org")
 	case t.Status != "" && t.Status != TaskStatusActive && t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", t.Status)
+	case t.Notification != nil:
+		return t.Notification.Valid()
 	}
 	return nil
 }
@@ -174,6 +282,10 @@ type TaskUpdate struct {
 	Status      *string `json:"status,omitempty"`
 	Description *string `json:"description,omitempty"`
 
+	// Notification replaces the notification of the task. A notification
+	// without an endpoint removes it.
+	Notification *TaskNotification `json:"notification,omitempty"`
+
 	// LatestCompleted us to set latest completed on startup to skip task catchup
 	LatestCompleted *time.Time             `json:"-"`
 	LatestScheduled *time.Time             `json:"-"`
@@ -207,6 +319,11 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		// Location is the IANA time zone in which the cron schedule is evaluated.
+		Location string `json:"location,omitempty"`
+
+		Notification *TaskNotification `json:"notification,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -222,6 +339,8 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	}
 	t.Options.Concurrency = jo.Concurrency
 	t.Options.Retry = jo.Retry
+	t.Options.Location = jo.Location
+	t.Notification = jo.Notification
 	t.Flux = jo.Flux
 	t.Status = jo.Status
 	return nil
@@ -246,6 +365,11 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		// Location is the IANA time zone in which the cron schedule is evaluated.
+		Location string `json:"location,omitempty"`
+
+		Notification *TaskNotification `json:"notification,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
@@ -257,12 +381,20 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 	}
 	jo.Concurrency = t.Options.Concurrency
 	jo.Retry = t.Options.Retry
+	jo.Location = t.Options.Location
+	jo.Notification = t.Notification
 	jo.Flux = t.Flux
 	jo.Status = t.Status
 	return json.Marshal(jo)
 }
 
 func (t *TaskUpdate) Validate() error {
+	if t.Options.Location != "" {
+		if _, err := time.LoadLocation(t.Options.Location); err != nil {
+			return fmt.Errorf("location: %s is invalid", err)
+		}
+	}
+
 	switch {
 	case !t.Options.Every.IsZero() && t.Options.Cron != "":
 		return errors.New("cannot specify both every and cron")
@@ -274,10 +406,12 @@ func (t *TaskUpdate) Validate() error {
 		if _, err := time.ParseDuration(t.Options.Offset.String()); err != nil {
 			return fmt.Errorf("offset: %s, %s is invalid, the largest unit supported is h", t.Options.Offset.String(), err)
 		}
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero():
+	case t.Flux == nil && t.Status == nil && t.Notification == nil && t.Options.IsZero():
 		return errors.New("cannot update task without content")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
+	case t.Notification != nil && t.Notification.EndpointID.Valid():
+		return t.Notification.Valid()
 	}
 	return nil
 }
@@ -328,6 +462,9 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) (err error) {
 	if t.Options.Cron != "" {
 		op["cron"] = &ast.StringLiteral{Value: t.Options.Cron}
 	}
+	if t.Options.Location != "" {
+		op["location"] = &ast.StringLiteral{Value: t.Options.Location}
+	}
 	if t.Options.Offset != nil {
 		if !t.Options.Offset.IsZero() {
 			op["offset"] = &t.Options.Offset.Node
@@ -357,6 +494,11 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) (err error) {
 						delete(op, "name")
 						p.Value = name
 					}
+				case "location":
+					if location, ok := op["location"]; ok && t.Options.Location != "" {
+						delete(op, "location")
+						p.Value = location
+					}
 				case "offset":
 					if offset, ok := op["offset"]; ok && t.Options.Offset != nil {
 						delete(op, "offset")
@@ -418,6 +560,8 @@ type TaskFilter struct {
 	User           *ID
 	Limit          int
 	Status         *string
+	// DependsOn filters the tasks that depend on the task with the given ID.
+	DependsOn *ID
 }
 
 // QueryParams Converts TaskFilter fields to url query params.
@@ -439,10 +583,19 @@ func (f TaskFilter) QueryParams() map[string][]string {
 		qp["user"] = []string{f.User.String()}
 	}
 
+	if f.DependsOn != nil {
+		qp["dependsOn"] = []string{f.DependsOn.String()}
+	}
+
 	if f.Limit > 0 {
 		qp["limit"] = []string{strconv.Itoa(f.Limit)}
 	}
 
+	// System tasks are listed by default.
+	if f.Type != nil && *f.Type != TaskSystemType {
+		qp["type"] = []string{*f.Type}
+	}
+
 	return qp
 }
 
@@ -455,6 +608,30 @@ type RunFilter struct {
 	Limit      int
 	AfterTime  string
 	BeforeTime string
+
+	// MinRowsWritten and MaxRowsWritten limit the runs to those that wrote
+	// at least, and at most, that many rows. Runs without statistics don't
+	// match either.
+	MinRowsWritten *int64
+	MaxRowsWritten *int64
+}
+
+// MatchStatistics reports whether the statistics of a run are within the
+// bounds of the filter.
+func (f RunFilter) MatchStatistics(r *Run) bool {
+	if f.MinRowsWritten == nil && f.MaxRowsWritten == nil {
+		return true
+	}
+	if r.Statistics == nil {
+		return false
+	}
+	if f.MinRowsWritten != nil && r.Statistics.RowsWritten < *f.MinRowsWritten {
+		return false
+	}
+	if f.MaxRowsWritten != nil && r.Statistics.RowsWritten > *f.MaxRowsWritten {
+		return false
+	}
+	return true
 }
 
 // LogFilter represents a set of filters that restrict the returned log results.
@@ -22,6 +22,8 @@ const (
 	finishedAtField   = "finishedAt"
 	requestedAtField  = "requestedAt"
 	logField          = "logs"
+	statisticsField   = "statistics"
+	rowsWrittenField  = "rowsWritten"
 
 	taskIDTag = "taskID"
 	statusTag = "status"
@@ -152,6 +154,15 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 		filterPart = fmt.Sprintf(`|> filter(fn: (r) => r.runID > %q)`, filter.After.String())
 	}
 
+	// runs recorded without statistics have no rowsWritten, and never match.
+	statisticsPart := ""
+	if filter.MinRowsWritten != nil {
+		statisticsPart += fmt.Sprintf(`|> filter(fn: (r) => r.rowsWritten >= %d)`, *filter.MinRowsWritten)
+	}
+	if filter.MaxRowsWritten != nil {
+		statisticsPart += fmt.Sprintf(`|> filter(fn: (r) => r.rowsWritten <= %d)`, *filter.MaxRowsWritten)
+	}
+
 	// the data will be stored for 7 days in the system bucket so pulling 14d's is sufficient.
 	runsScript := fmt.Sprintf(`from(bucketID: %q)
 	  |> range(start: -14d)
@@ -159,11 +170,12 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 	  |> filter(fn: (r) => r._measurement == "runs" and r.taskID == %q)
 	  %s
 	  |> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+	  %s
 	  |> group(columns: ["taskID"])
 	  |> sort(columns:["scheduledFor"], desc: true)
 	  |> limit(n:%d)
 
-	  `, sb.ID.String(), filter.Task.String(), filterPart, filter.Limit-len(runs))
+	  `, sb.ID.String(), filter.Task.String(), filterPart, statisticsPart, filter.Limit-len(runs))
 
 	// At this point we are behind authorization
 	// so we are faking a read only permission to the org's system bucket
@@ -407,6 +419,16 @@ func (re *runReader) readRuns(cr flux.ColReader) error {
 						re.log.Info("Failed to parse log data", zap.Error(err), zap.ByteString("log_bytes", logBytes))
 					}
 				}
+			case statisticsField:
+				statsBytes := bytes.TrimSpace(cr.Strings(j).Value(i))
+				if len(statsBytes) != 0 {
+					var stats influxdb.RunStatistics
+					if err := json.Unmarshal(statsBytes, &stats); err != nil {
+						re.log.Info("Failed to parse statistics", zap.Error(err), zap.ByteString("statistics_bytes", statsBytes))
+						continue
+					}
+					r.Statistics = &stats
+				}
 			}
 		}
 
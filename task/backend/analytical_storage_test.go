@@ -109,6 +109,64 @@ func TestDeduplicateRuns(t *testing.T) {
 	}
 }
 
+func TestRunStatistics(t *testing.T) {
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	ab := newAnalyticalBackend(t, svc, svc)
+	defer ab.Close(t)
+
+	mockTS := &mock.TaskService{
+		FindTaskByIDFn: func(context.Context, influxdb.ID) (*influxdb.Task, error) {
+			return &influxdb.Task{ID: 1, OrganizationID: 20}, nil
+		},
+		FindRunsFn: func(context.Context, influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+			return nil, 0, nil
+		},
+		FindRunByIDFn: func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Run, error) {
+			return nil, influxdb.ErrRunNotFound
+		},
+	}
+	stats := map[influxdb.ID]*influxdb.RunStatistics{
+		2: {ScannedValues: 10, ScannedBytes: 80},
+		3: {ScannedValues: 10, ScannedBytes: 80, RowsWritten: 4, Writes: []influxdb.RunBucketWrite{{BucketID: 30, Rows: 4}}},
+	}
+	mockTCS := &mock.TaskControlService{
+		FinishRunFn: func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+			sf := time.Now().Add(-time.Duration(runID) * time.Second)
+			return &influxdb.Run{ID: runID, TaskID: 1, Status: "success", ScheduledFor: sf, StartedAt: sf.Add(1), FinishedAt: sf.Add(2), Statistics: stats[runID]}, nil
+		},
+	}
+	mockBS := mock.NewBucketService()
+
+	svcStack := backend.NewAnalyticalStorage(zaptest.NewLogger(t), mockTS, mockBS, mockTCS, ab.PointsWriter(), ab.QueryService())
+
+	for _, runID := range []influxdb.ID{2, 3} {
+		if _, err := svcStack.FinishRun(context.Background(), 1, runID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zero := int64(0)
+	runs, _, err := svcStack.FindRuns(context.Background(), influxdb.RunFilter{Task: 1, MaxRowsWritten: &zero})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ID != 2 {
+		t.Fatalf("expected only the run that wrote no rows, got %+v", runs)
+	}
+
+	run, err := svcStack.FindRunByID(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Statistics == nil || run.Statistics.RowsWritten != 4 || len(run.Statistics.Writes) != 1 || run.Statistics.Writes[0].BucketID != 30 {
+		t.Fatalf("expected the statistics of the run to be recorded, got %+v", run.Statistics)
+	}
+}
+
 type analyticalBackend struct {
 	queryController *control.Controller
 	rootDir         string
@@ -0,0 +1,334 @@
+// Package backfill runs tasks over a range of their schedule.
+package backfill
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend/executor"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.TaskBackfillService = (*Service)(nil)
+var _ Executor = (*executor.Executor)(nil)
+
+// Executor is an abstraction of the task executor with only the functions
+// needed to execute the runs of a backfill.
+type Executor interface {
+	BackfillRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (executor.Promise, error)
+	Cancel(ctx context.Context, runID influxdb.ID) error
+}
+
+// Store persists backfills and their progress.
+type Store interface {
+	influxdb.TaskBackfillService
+	UpdateBackfill(ctx context.Context, id influxdb.ID, upd influxdb.BackfillUpdate) (*influxdb.Backfill, error)
+}
+
+// Service is a TaskBackfillService that enqueues the runs of each backfill as
+// manual runs of its task, and executes them with the executor.
+type Service struct {
+	log   *zap.Logger
+	store Store
+	ts    influxdb.TaskService
+	ex    Executor
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	cancels map[influxdb.ID]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New constructs a backfill service. ts creates the manual runs of backfills,
+// and must not be wrapped by a coordinator.
+func New(log *zap.Logger, store Store, ts influxdb.TaskService, ex Executor) *Service {
+	return &Service{
+		log:     log,
+		store:   store,
+		ts:      ts,
+		ex:      ex,
+		now:     time.Now,
+		cancels: make(map[influxdb.ID]context.CancelFunc),
+	}
+}
+
+// FindBackfillByID returns a single backfill.
+func (s *Service) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.store.FindBackfillByID(ctx, id)
+}
+
+// FindBackfills returns the backfills that match a filter.
+func (s *Service) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	return s.store.FindBackfills(ctx, filter)
+}
+
+// CreateBackfill creates a backfill of a task and starts enqueueing its runs.
+func (s *Service) CreateBackfill(ctx context.Context, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	if err := bc.Valid(); err != nil {
+		return nil, err
+	}
+	if bc.Stop.After(s.now()) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "backfill stop must not be in the future",
+		}
+	}
+
+	t, err := s.ts.FindTaskByID(ctx, bc.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	sch, from, err := taskSchedule(t, bc.Start)
+	if err != nil {
+		return nil, err
+	}
+	total, err := countRuns(sch, from, bc.Stop)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.store.CreateBackfill(ctx, bc)
+	if err != nil {
+		return nil, err
+	}
+	b, err = s.store.UpdateBackfill(ctx, b.ID, influxdb.BackfillUpdate{Total: &total})
+	if err != nil {
+		return nil, err
+	}
+
+	s.start(b, sch, from)
+	return b, nil
+}
+
+// CancelBackfill cancels a backfill, and the runs of it that are executing.
+func (s *Service) CancelBackfill(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	b, err := s.store.CancelBackfill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.cancels[id]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return b, nil
+}
+
+// Resume continues the backfills that were running when the service last
+// stopped, from their cursors.
+func (s *Service) Resume(ctx context.Context) error {
+	status := influxdb.BackfillStatusRunning
+	backfills, err := s.store.FindBackfills(ctx, influxdb.BackfillFilter{Status: &status})
+	if err != nil {
+		return err
+	}
+
+	for _, b := range backfills {
+		t, err := s.ts.FindTaskByID(ctx, b.TaskID)
+		if err != nil {
+			s.log.Info("Failed to resume backfill", zap.Stringer("backfillID", b.ID), zap.Error(err))
+			continue
+		}
+		sch, from, err := taskSchedule(t, b.Cursor)
+		if err != nil {
+			s.log.Info("Failed to resume backfill", zap.Stringer("backfillID", b.ID), zap.Error(err))
+			continue
+		}
+		s.start(b, sch, from)
+	}
+	return nil
+}
+
+// Close stops enqueueing the runs of backfills, and waits for their executing
+// runs to be canceled. The backfills remain running, to be resumed.
+func (s *Service) Close() {
+	s.mu.Lock()
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Service) start(b *influxdb.Backfill, sch scheduler.Schedule, from time.Time) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	s.cancels[b.ID] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, b.ID)
+			s.mu.Unlock()
+			cancel()
+		}()
+
+		s.run(ctx, b, sch, from)
+	}()
+}
+
+// run enqueues the runs of b scheduled after from, at most b.Concurrency at a
+// time, and records its progress.
+func (s *Service) run(ctx context.Context, b *influxdb.Backfill, sch scheduler.Schedule, from time.Time) {
+	log := s.log.With(zap.Stringer("backfillID", b.ID), zap.Stringer("taskID", b.TaskID))
+
+	var (
+		wg   sync.WaitGroup
+		slot = make(chan struct{}, b.Concurrency)
+
+		mu        sync.Mutex
+		completed = b.Completed
+		failed    = b.Failed
+		// the scheduled times of the runs that are executing, and of the next
+		// run to enqueue, determine the cursor.
+		executing = make(map[int64]bool)
+		pending   time.Time
+	)
+
+	progress := func(scheduledFor time.Time, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delete(executing, scheduledFor.Unix())
+		if ctx.Err() != nil {
+			// canceled runs are neither completed nor failed.
+			return
+		}
+		if err != nil {
+			failed++
+			log.Info("Backfill run failed", zap.Time("scheduledFor", scheduledFor), zap.Error(err))
+		} else {
+			completed++
+		}
+
+		cursor := pending
+		for sf := range executing {
+			if t := time.Unix(sf, 0); t.Before(cursor) {
+				cursor = t
+			}
+		}
+		upd := influxdb.BackfillUpdate{Completed: &completed, Failed: &failed, Cursor: &cursor}
+		if _, err := s.store.UpdateBackfill(context.Background(), b.ID, upd); err != nil {
+			log.Error("Failed to record backfill progress", zap.Error(err))
+		}
+	}
+
+	next, err := sch.Next(from)
+	for ; err == nil && !next.After(b.Stop); next, err = sch.Next(next) {
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		scheduledFor := next
+		mu.Lock()
+		executing[scheduledFor.Unix()] = true
+		pending = scheduledFor.Add(time.Second)
+		mu.Unlock()
+
+		p, err := s.enqueue(ctx, b.TaskID, scheduledFor)
+		if err != nil {
+			<-slot
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				// the task was deleted, along with the backfill.
+				log.Info("Backfill task not found", zap.Error(err))
+				return
+			}
+			progress(scheduledFor, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slot }()
+
+			select {
+			case <-p.Done():
+			case <-ctx.Done():
+				if err := s.ex.Cancel(context.Background(), p.ID()); err != nil {
+					log.Info("Failed to cancel backfill run", zap.Error(err))
+				}
+				<-p.Done()
+			}
+			progress(scheduledFor, p.Error())
+		}()
+	}
+	if err != nil {
+		log.Error("Failed to schedule backfill", zap.Error(err))
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	status := influxdb.BackfillStatusCompleted
+	if _, err := s.store.UpdateBackfill(context.Background(), b.ID, influxdb.BackfillUpdate{Status: &status, Cursor: &b.Stop}); err != nil {
+		log.Error("Failed to complete backfill", zap.Error(err))
+	}
+}
+
+// enqueue creates a manual run of the task scheduled for t, and executes it.
+func (s *Service) enqueue(ctx context.Context, taskID influxdb.ID, t time.Time) (executor.Promise, error) {
+	r, err := s.ts.ForceRun(ctx, taskID, t.Unix())
+	if err != nil {
+		return nil, err
+	}
+	return s.ex.BackfillRun(ctx, taskID, r.ID)
+}
+
+// taskSchedule returns the schedule of t, and the time from which its next
+// scheduled time is the first at or after start.
+func taskSchedule(t *influxdb.Task, start time.Time) (scheduler.Schedule, time.Time, error) {
+	// every schedules are relative to the aligned time returned.
+	sch, from, err := scheduler.NewScheduleInLocation(t.EffectiveCron(), t.Location, start.Add(-time.Second))
+	if err != nil {
+		return scheduler.Schedule{}, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task does not have a schedule to backfill",
+			Err:  err,
+		}
+	}
+	return sch, from, nil
+}
+
+// countRuns returns the number of times sch is scheduled for after from
+// through stop.
+func countRuns(sch scheduler.Schedule, from, stop time.Time) (int, error) {
+	var n int
+	next, err := sch.Next(from)
+	for ; err == nil && !next.After(stop); next, err = sch.Next(next) {
+		n++
+		if n > influxdb.MaxBackfillRuns {
+			return 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "backfill exceeds the maximum number of runs",
+			}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task is not scheduled between backfill start and stop",
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,233 @@
+package backfill_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/task/backend/backfill"
+	"github.com/influxdata/influxdb/task/backend/executor"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakePromise struct {
+	id   influxdb.ID
+	done chan struct{}
+	err  error
+}
+
+func (p *fakePromise) ID() influxdb.ID            { return p.id }
+func (p *fakePromise) Cancel(ctx context.Context) {}
+func (p *fakePromise) Done() <-chan struct{}      { return p.done }
+func (p *fakePromise) Error() error               { <-p.done; return p.err }
+func (p *fakePromise) finish(err error)           { p.err = err; close(p.done) }
+
+// fakeExecutor starts the manual runs of backfills, and finishes them with the
+// result of its run func.
+type fakeExecutor struct {
+	tcs *kv.Service
+	run func(r *influxdb.Run, p *fakePromise)
+
+	mu       sync.Mutex
+	started  []time.Time
+	promises map[influxdb.ID]*fakePromise
+}
+
+func (e *fakeExecutor) BackfillRun(ctx context.Context, id, runID influxdb.ID) (executor.Promise, error) {
+	r, err := e.tcs.StartManualRun(ctx, id, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &fakePromise{id: runID, done: make(chan struct{})}
+	e.mu.Lock()
+	e.started = append(e.started, r.ScheduledFor)
+	e.promises[runID] = p
+	e.mu.Unlock()
+
+	go e.run(r, p)
+	return p, nil
+}
+
+func (e *fakeExecutor) Cancel(ctx context.Context, runID influxdb.ID) error {
+	e.mu.Lock()
+	p := e.promises[runID]
+	e.mu.Unlock()
+
+	p.finish(influxdb.ErrRunCanceled)
+	return nil
+}
+
+func (e *fakeExecutor) startedRuns() []time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]time.Time(nil), e.started...)
+}
+
+func newTestService(t *testing.T, run func(r *influxdb.Run, p *fakePromise)) (*backfill.Service, *fakeExecutor, *influxdb.Task, context.Context) {
+	t.Helper()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+		Flux:           `option task = {name: "rollup", every: 1h} from(bucket: "one") |> range(start: -1h) |> to(bucket: "two", orgID: "0000000000000000")`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex := &fakeExecutor{tcs: svc, run: run, promises: make(map[influxdb.ID]*fakePromise)}
+	return backfill.New(zaptest.NewLogger(t), svc, svc, ex), ex, task, ctx
+}
+
+func waitForStatus(t *testing.T, s *backfill.Service, id influxdb.ID, status string) *influxdb.Backfill {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		b, err := s.FindBackfillByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Status == status {
+			return b
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("backfill did not become %s in time", status)
+	return nil
+}
+
+func TestService_CreateBackfill(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	failAt := start.Add(2 * time.Hour)
+
+	s, ex, task, ctx := newTestService(t, func(r *influxdb.Run, p *fakePromise) {
+		if r.ScheduledFor.Equal(failAt) {
+			p.finish(errors.New("query failed"))
+			return
+		}
+		p.finish(nil)
+	})
+	defer s.Close()
+
+	b, err := s.CreateBackfill(ctx, influxdb.BackfillCreate{
+		TaskID:      task.ID,
+		Start:       start.Add(-30 * time.Minute),
+		Stop:        start.Add(3 * time.Hour),
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Total != 4 {
+		t.Fatalf("expected 4 runs, got %d", b.Total)
+	}
+
+	b = waitForStatus(t, s, b.ID, influxdb.BackfillStatusCompleted)
+	if b.Completed != 3 || b.Failed != 1 {
+		t.Errorf("expected 3 completed and 1 failed run, got %d and %d", b.Completed, b.Failed)
+	}
+
+	started := ex.startedRuns()
+	if len(started) != 4 {
+		t.Fatalf("expected 4 runs, got %v", started)
+	}
+	for i, sf := range started {
+		if exp := start.Add(time.Duration(i) * time.Hour); !sf.Equal(exp) {
+			t.Errorf("run %d: expected scheduledFor %s, got %s", i, exp, sf)
+		}
+	}
+}
+
+func TestService_CancelBackfill(t *testing.T) {
+	// runs execute until they are canceled.
+	s, ex, task, ctx := newTestService(t, func(r *influxdb.Run, p *fakePromise) {})
+	defer s.Close()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := s.CreateBackfill(ctx, influxdb.BackfillCreate{
+		TaskID:      task.ID,
+		Start:       start,
+		Stop:        start.Add(24 * time.Hour),
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100 && len(ex.startedRuns()) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := s.CancelBackfill(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	b = waitForStatus(t, s, b.ID, influxdb.BackfillStatusCanceled)
+
+	s.Close()
+	if started := ex.startedRuns(); len(started) != 2 {
+		t.Errorf("expected the backfill to stop after 2 runs, got %d", len(started))
+	}
+
+	if _, err := s.CancelBackfill(ctx, b.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected conflict canceling a canceled backfill, got %v", err)
+	}
+}
+
+func TestService_CreateBackfill_Invalid(t *testing.T) {
+	s, _, task, ctx := newTestService(t, func(r *influxdb.Run, p *fakePromise) { p.finish(nil) })
+	defer s.Close()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		bc   influxdb.BackfillCreate
+	}{
+		{
+			name: "stop before start",
+			bc:   influxdb.BackfillCreate{TaskID: task.ID, Start: start, Stop: start.Add(-time.Hour)},
+		},
+		{
+			name: "stop in the future",
+			bc:   influxdb.BackfillCreate{TaskID: task.ID, Start: start, Stop: time.Now().Add(time.Hour)},
+		},
+		{
+			name: "no scheduled times",
+			bc:   influxdb.BackfillCreate{TaskID: task.ID, Start: start.Add(time.Minute), Stop: start.Add(30 * time.Minute)},
+		},
+		{
+			name: "concurrency too high",
+			bc:   influxdb.BackfillCreate{TaskID: task.ID, Start: start, Stop: start.Add(time.Hour), Concurrency: influxdb.MaxBackfillConcurrency + 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.CreateBackfill(ctx, tt.bc); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
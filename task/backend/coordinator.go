@@ -97,6 +97,10 @@ func TaskNotifyCoordinatorOfExisting(ctx context.Context, ts TaskService, tcs Ta
 				return err
 			}
 			for i := range runs {
+				if runs[i].Status == RunWaiting.String() {
+					// waiting runs are executed once the runs they wait for succeed.
+					continue
+				}
 				if err := exec(ctx, runs[i].TaskID, runs[i].ID); err != nil {
 					return err
 				}
@@ -25,11 +25,23 @@ type Executor interface {
 	Cancel(ctx context.Context, runID influxdb.ID) error
 }
 
+// Dependencies is an abstraction of the scheduler.DependencyExecutor with only the functions needed by the coordinator
+type Dependencies interface {
+	SetDependencies(id scheduler.ID, upstream []scheduler.ID)
+	Release(id scheduler.ID)
+}
+
+type noopDependencies struct{}
+
+func (noopDependencies) SetDependencies(scheduler.ID, []scheduler.ID) {}
+func (noopDependencies) Release(scheduler.ID)                         {}
+
 // Coordinator is the intermediary between the scheduling/executing system and the rest of the task system
 type Coordinator struct {
-	log *zap.Logger
-	sch scheduler.Scheduler
-	ex  Executor
+	log  *zap.Logger
+	sch  scheduler.Scheduler
+	ex   Executor
+	deps Dependencies
 
 	limit int
 }
@@ -68,6 +80,26 @@ func WithLimitOpt(i int) CoordinatorOption {
 	}
 }
 
+// WithDependencies sets the Dependencies that are kept up to date with the
+// upstream tasks of the tasks of the coordinator.
+func WithDependencies(d Dependencies) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.deps = d
+	}
+}
+
+// dependsOn returns the upstream tasks of task as scheduler IDs.
+func dependsOn(task *influxdb.Task) []scheduler.ID {
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+	ids := make([]scheduler.ID, 0, len(task.DependsOn))
+	for _, id := range task.DependsOn {
+		ids = append(ids, scheduler.ID(id))
+	}
+	return ids
+}
+
 // NewSchedulableTask transforms an influxdb task to a schedulable task type
 func NewSchedulableTask(task *influxdb.Task) (SchedulableTask, error) {
 
@@ -84,7 +116,7 @@ func NewSchedulableTask(task *influxdb.Task) (SchedulableTask, error) {
 
 	var sch scheduler.Schedule
 	var err error
-	sch, ts, err = scheduler.NewSchedule(effCron, ts)
+	sch, ts, err = scheduler.NewScheduleInLocation(effCron, task.Location, ts)
 	if err != nil {
 		return SchedulableTask{}, err
 	}
@@ -96,6 +128,7 @@ func NewCoordinator(log *zap.Logger, scheduler scheduler.Scheduler, executor Exe
 		log:   log,
 		sch:   scheduler,
 		ex:    executor,
+		deps:  noopDependencies{},
 		limit: DefaultLimit,
 	}
 
@@ -113,6 +146,8 @@ func (c *Coordinator) TaskCreated(ctx context.Context, task *influxdb.Task) erro
 	if err != nil {
 		return err
 	}
+	c.deps.SetDependencies(t.ID(), dependsOn(task))
+
 	// func new schedulable task
 	// catch errors from offset and last scheduled
 	if err = c.sch.Schedule(t); err != nil {
@@ -129,6 +164,7 @@ func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *influxdb.Task)
 	if err != nil {
 		return err
 	}
+	c.deps.SetDependencies(sid, dependsOn(to))
 
 	// if disabling the task, release it before schedule update
 	if to.Status != from.Status && to.Status == string(backend.TaskInactive) {
@@ -147,6 +183,7 @@ func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *influxdb.Task)
 //TaskDeleted asks the Scheduler to release the deleted task
 func (c *Coordinator) TaskDeleted(ctx context.Context, id influxdb.ID) error {
 	tid := scheduler.ID(id)
+	c.deps.Release(tid)
 	if err := c.sch.Release(tid); err != nil && err != influxdb.ErrTaskNotClaimed {
 		return err
 	}
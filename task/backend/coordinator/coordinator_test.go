@@ -259,3 +259,34 @@ func Test_Coordinator_Scheduler_Methods(t *testing.T) {
 		})
 	}
 }
+
+func Test_Coordinator_Dependencies(t *testing.T) {
+	var (
+		now      = time.Now().UTC()
+		upstream = &influxdb.Task{ID: influxdb.ID(1), Status: "active", CreatedAt: now, Every: "5m"}
+		original = &influxdb.Task{ID: influxdb.ID(2), Status: "active", CreatedAt: now, Every: "1h"}
+		updated  = &influxdb.Task{ID: influxdb.ID(2), Status: "active", CreatedAt: now, Every: "1h", DependsOn: []influxdb.ID{upstream.ID}}
+
+		deps  = &dependenciesD{}
+		coord = NewCoordinator(zaptest.NewLogger(t), &schedulerC{}, &executorE{}, WithDependencies(deps))
+	)
+
+	if err := coord.TaskCreated(context.Background(), original); err != nil {
+		t.Fatal(err)
+	}
+	if err := coord.TaskUpdated(context.Background(), original, updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := coord.TaskDeleted(context.Background(), updated.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []interface{}{
+		setDependenciesCall{TaskID: 2},
+		setDependenciesCall{TaskID: 2, Upstream: []scheduler.ID{1}},
+		releaseCallD{TaskID: 2},
+	}
+	if diff := cmp.Diff(exp, deps.calls); diff != "" {
+		t.Errorf("unexpected dependencies calls -want/+got\n%s", diff)
+	}
+}
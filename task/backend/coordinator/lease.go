@@ -0,0 +1,241 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultPartitions is the number of partitions that the tasks are
+	// divided into between the nodes that schedule them.
+	DefaultPartitions = 64
+
+	// DefaultLeaseTTL is how long a node holds the lease of a partition, and
+	// is considered alive, after its last heartbeat.
+	DefaultLeaseTTL = 30 * time.Second
+)
+
+// Leases tracks the partitions of the tasks that a node holds the lease of.
+// Every node claims an even share of the partitions, and the partitions of a
+// node that stops renewing its leases are claimed by the other nodes once
+// its leases expire.
+//
+// Leases are recorded by the TaskLeaseService, so nodes only share the tasks
+// when they share its store. The bolt and in-memory stores are each opened by
+// a single process, so with them a single node holds all of the leases.
+type Leases struct {
+	log        *zap.Logger
+	nodeID     string
+	svc        influxdb.TaskLeaseService
+	partitions int
+	ttl        time.Duration
+
+	mu sync.RWMutex
+	// owned is when the lease of each owned partition expires, by the clock of the node.
+	owned map[int]time.Time
+	now   func() time.Time
+}
+
+// NewLeases returns the Leases of a node on partitions partitions of the
+// tasks, that are held for ttl after they are renewed.
+func NewLeases(log *zap.Logger, nodeID string, svc influxdb.TaskLeaseService, partitions int, ttl time.Duration) *Leases {
+	if partitions <= 0 {
+		partitions = DefaultPartitions
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	return &Leases{
+		log:        log,
+		nodeID:     nodeID,
+		svc:        svc,
+		partitions: partitions,
+		ttl:        ttl,
+		owned:      map[int]time.Time{},
+		now:        time.Now,
+	}
+}
+
+// NodeID returns the ID of the node that holds the leases.
+func (l *Leases) NodeID() string {
+	return l.nodeID
+}
+
+// TTL returns how long a lease is held after it is renewed.
+func (l *Leases) TTL() time.Duration {
+	return l.ttl
+}
+
+// Partition returns the partition of a task.
+func (l *Leases) Partition(id influxdb.ID) int {
+	buf := [8]byte{}
+	binary.LittleEndian.PutUint64(buf[:], uint64(id))
+	return int(xxhash.Sum64(buf[:]) % uint64(l.partitions))
+}
+
+// Owns reports whether the node holds the lease of the partition of a task.
+func (l *Leases) Owns(id influxdb.ID) bool {
+	p := l.Partition(id)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	expiresAt, ok := l.owned[p]
+	return ok && l.now().Before(expiresAt)
+}
+
+// Owned returns the partitions that the node holds the lease of, in order.
+func (l *Leases) Owned() []int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.ownedPartitions()
+}
+
+// Renew sends the heartbeat of the node, renews its leases, and balances the
+// partitions between the nodes that are alive. It returns the partitions that
+// the node acquired and lost.
+//
+// Partitions are lost when their leases are taken over by other nodes, when
+// they can't be renewed before they expire, and when the node holds more than
+// its share of the partitions. The caller must stop scheduling the tasks of
+// lost partitions, and then Release them, so that the partitions the node
+// gave up can be claimed by other nodes.
+func (l *Leases) Renew(ctx context.Context) (acquired, lost []int, err error) {
+	start := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	defer func() {
+		// partitions that could not be renewed in time are lost, even when
+		// the lease service is unreachable.
+		for _, p := range l.ownedPartitions() {
+			if !start.Before(l.owned[p]) {
+				delete(l.owned, p)
+				lost = append(lost, p)
+			}
+		}
+	}()
+
+	nodes, err := l.svc.HeartbeatTaskNode(ctx, l.nodeID, l.ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+	// leases are stamped by the lease service with its own clock, which for
+	// the kv service is the local clock of this process rather than a clock
+	// shared by the nodes. The leases of other nodes are compared with the
+	// time it recorded the heartbeat at, so that both come from that clock.
+	now := start
+	for _, n := range nodes {
+		if n.ID == l.nodeID {
+			now = n.HeartbeatAt
+		}
+	}
+	share := l.partitions
+	if len(nodes) > 1 {
+		share = (l.partitions + len(nodes) - 1) / len(nodes)
+	}
+
+	for _, p := range l.ownedPartitions() {
+		if _, err := l.svc.ClaimTaskPartition(ctx, l.nodeID, p, l.ttl); err != nil {
+			if influxdb.ErrorCode(err) != influxdb.EConflict {
+				return nil, lost, err
+			}
+			l.log.Info("Lost the lease of task partition", zap.Int("partition", p))
+			delete(l.owned, p)
+			lost = append(lost, p)
+			continue
+		}
+		l.owned[p] = start.Add(l.ttl)
+	}
+
+	// give up the partitions beyond the share of the node, so that nodes that
+	// joined can claim them.
+	for owned := l.ownedPartitions(); len(owned) > share; owned = owned[:len(owned)-1] {
+		p := owned[len(owned)-1]
+		delete(l.owned, p)
+		lost = append(lost, p)
+	}
+
+	if len(l.owned) >= share {
+		return nil, lost, nil
+	}
+
+	leases, err := l.svc.FindTaskPartitionLeases(ctx)
+	if err != nil {
+		return nil, lost, err
+	}
+	leased := make(map[int]bool, len(leases))
+	for _, lease := range leases {
+		leased[lease.Partition] = lease.NodeID != l.nodeID && !lease.Expired(now)
+	}
+
+	// nodes start looking for free partitions at different partitions, so
+	// that they rarely try to claim the same ones.
+	offset := int(xxhash.Sum64String(l.nodeID) % uint64(l.partitions))
+	for i := 0; i < l.partitions && len(l.owned) < share; i++ {
+		p := (offset + i) % l.partitions
+		if _, ok := l.owned[p]; ok || leased[p] {
+			continue
+		}
+		if _, err := l.svc.ClaimTaskPartition(ctx, l.nodeID, p, l.ttl); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EConflict {
+				continue
+			}
+			return acquired, lost, err
+		}
+		l.log.Info("Acquired the lease of task partition", zap.Int("partition", p))
+		l.owned[p] = start.Add(l.ttl)
+		acquired = append(acquired, p)
+	}
+	return acquired, lost, nil
+}
+
+// ClaimRun claims the run of a task for scheduledFor, so that it is executed
+// once even when the partition of the task moves to another node. Claiming a
+// run that was already claimed fails with influxdb.ErrTaskRunClaimed.
+func (l *Leases) ClaimRun(ctx context.Context, id influxdb.ID, scheduledFor time.Time) error {
+	return l.svc.ClaimTaskRun(ctx, id, scheduledFor)
+}
+
+// Release gives up the leases of partitions, so that other nodes can claim
+// them right away. The leases of the partitions are no longer held by the
+// node once it returns, even if it fails.
+func (l *Leases) Release(ctx context.Context, partitions ...int) error {
+	l.mu.Lock()
+	for _, p := range partitions {
+		delete(l.owned, p)
+	}
+	l.mu.Unlock()
+
+	for _, p := range partitions {
+		if err := l.svc.ReleaseTaskPartition(ctx, l.nodeID, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Leave releases all leases of the node and forgets the node, so that the
+// other nodes share the partitions right away.
+func (l *Leases) Leave(ctx context.Context) error {
+	if err := l.Release(ctx, l.Owned()...); err != nil {
+		return err
+	}
+	return l.svc.DeleteTaskNode(ctx, l.nodeID)
+}
+
+// ownedPartitions returns the owned partitions in order. l.mu must be held.
+func (l *Leases) ownedPartitions() []int {
+	owned := make([]int, 0, len(l.owned))
+	for p := range l.owned {
+		owned = append(owned, p)
+	}
+	sort.Ints(owned)
+	return owned
+}
@@ -0,0 +1,397 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
+	"go.uber.org/zap/zaptest"
+)
+
+// schedulerS is a scheduler that keeps track of the scheduled tasks.
+type schedulerS struct {
+	mu        sync.Mutex
+	scheduled map[scheduler.ID]scheduler.Schedulable
+}
+
+func newSchedulerS() *schedulerS {
+	return &schedulerS{scheduled: map[scheduler.ID]scheduler.Schedulable{}}
+}
+
+func (s *schedulerS) Schedule(sch scheduler.Schedulable) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduled[sch.ID()] = sch
+	return nil
+}
+
+func (s *schedulerS) Release(id scheduler.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.scheduled, id)
+	return nil
+}
+
+func (s *schedulerS) get(id scheduler.ID) (scheduler.Schedulable, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sch, ok := s.scheduled[id]
+	return sch, ok
+}
+
+func (s *schedulerS) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.scheduled)
+}
+
+type executorFunc func(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error
+
+func (fn executorFunc) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	return fn(ctx, id, scheduledFor, runAt)
+}
+
+type leaseNode struct {
+	svc    *kv.Service
+	leases *Leases
+	sch    *schedulerS
+	ls     *LeasedScheduler
+}
+
+func newLeaseNode(t *testing.T, store kv.Store, tg *mock.TimeGenerator, id string) *leaseNode {
+	t.Helper()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.TimeGenerator = tg
+	leases := NewLeases(zaptest.NewLogger(t), id, svc, 8, time.Minute)
+	sch := newSchedulerS()
+	return &leaseNode{
+		svc:    svc,
+		leases: leases,
+		sch:    sch,
+		ls:     NewLeasedScheduler(zaptest.NewLogger(t), leases, sch, svc, nil),
+	}
+}
+
+func (n *leaseNode) heartbeat(t *testing.T) {
+	t.Helper()
+	if err := n.ls.Heartbeat(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_LeasedScheduler_SharesTasks(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	tg := &mock.TimeGenerator{FakeValue: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.TimeGenerator = tg
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	authCtx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	var tasks []*influxdb.Task
+	for i := 0; i < 20; i++ {
+		task, err := svc.CreateTask(authCtx, influxdb.TaskCreate{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			Flux:           fmt.Sprintf(`option task = {name: "task %d", every: 1h} from(bucket: "b") |> range(start: -1h) |> to(bucket: "c", orgID: "0000000000000000")`, i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	a := newLeaseNode(t, store, tg, "a")
+	b := newLeaseNode(t, store, tg, "b")
+
+	// a single node schedules every task.
+	a.heartbeat(t)
+	if got := len(a.leases.Owned()); got != 8 {
+		t.Fatalf("expected the only node to own all 8 partitions, got %d", got)
+	}
+	if got := a.sch.len(); got != len(tasks) {
+		t.Fatalf("expected the only node to schedule all %d tasks, got %d", len(tasks), got)
+	}
+
+	// a node that joins gets its share once the other node gives it up.
+	b.heartbeat(t)
+	if got := len(b.leases.Owned()); got != 0 {
+		t.Fatalf("expected the partitions leased by a to not be claimed by b, got %v", b.leases.Owned())
+	}
+	a.heartbeat(t)
+	b.heartbeat(t)
+	if got, want := len(a.leases.Owned()), 4; got != want {
+		t.Fatalf("expected a to own %d partitions, got %v", want, a.leases.Owned())
+	}
+	if got, want := len(b.leases.Owned()), 4; got != want {
+		t.Fatalf("expected b to own %d partitions, got %v", want, b.leases.Owned())
+	}
+	assertScheduledOnce(t, tasks, a, b)
+
+	// a run on b checkpoints its task.
+	var moved *influxdb.Task
+	for _, task := range tasks {
+		if b.leases.Owns(task.ID) {
+			moved = task
+			break
+		}
+	}
+	checkpoint := time.Now().UTC().Truncate(time.Hour).Add(5 * time.Hour)
+	if _, err := b.svc.UpdateTask(ctx, moved.ID, influxdb.TaskUpdate{LatestScheduled: &checkpoint, LatestCompleted: &checkpoint}); err != nil {
+		t.Fatal(err)
+	}
+
+	// b dies, and a takes over its partitions once their leases expire.
+	tg.FakeValue = tg.FakeValue.Add(30 * time.Second)
+	a.heartbeat(t)
+	if got := len(a.leases.Owned()); got != 4 {
+		t.Fatalf("expected a to not claim the partitions of b before they expire, got %v", a.leases.Owned())
+	}
+	tg.FakeValue = tg.FakeValue.Add(time.Minute)
+	a.heartbeat(t)
+	if got := len(a.leases.Owned()); got != 8 {
+		t.Fatalf("expected a to own all 8 partitions after b died, got %v", a.leases.Owned())
+	}
+	if got := a.sch.len(); got != len(tasks) {
+		t.Fatalf("expected a to schedule all %d tasks after b died, got %d", len(tasks), got)
+	}
+
+	// the task that moved resumes from its checkpoint.
+	sch, _ := a.sch.get(scheduler.ID(moved.ID))
+	if got := sch.LastScheduled(); !got.Equal(checkpoint) {
+		t.Errorf("expected the moved task to resume from %v, got %v", checkpoint, got)
+	}
+
+	// tasks are deleted through the coordinator, which releases them.
+	if err := a.svc.DeleteTask(ctx, moved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ls.Release(scheduler.ID(moved.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.sch.get(scheduler.ID(moved.ID)); ok {
+		t.Error("expected a deleted task to be released")
+	}
+
+	// partitions that don't change hands aren't scheduled again.
+	kept := tasks[0]
+	if kept.ID == moved.ID {
+		kept = tasks[1]
+	}
+	a.sch.Release(scheduler.ID(kept.ID))
+	a.heartbeat(t)
+	if _, ok := a.sch.get(scheduler.ID(kept.ID)); ok {
+		t.Error("expected the tasks of partitions a already owned to not be scheduled again")
+	}
+
+	// a node that shuts down releases its partitions right away.
+	if err := a.ls.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c := newLeaseNode(t, store, tg, "c")
+	c.heartbeat(t)
+	if got := len(c.leases.Owned()); got != 8 {
+		t.Fatalf("expected c to own all 8 partitions after a shut down, got %v", c.leases.Owned())
+	}
+	if got := c.sch.len(); got != len(tasks)-1 {
+		t.Fatalf("expected c to schedule the %d remaining tasks, got %d", len(tasks)-1, got)
+	}
+}
+
+func Test_LeasedScheduler_SeesTasksOfOtherNodes(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	tg := &mock.TimeGenerator{FakeValue: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.TimeGenerator = tg
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	authCtx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	a := newLeaseNode(t, store, tg, "a")
+	b := newLeaseNode(t, store, tg, "b")
+	a.heartbeat(t)
+	b.heartbeat(t)
+	a.heartbeat(t)
+	b.heartbeat(t)
+	if len(b.leases.Owned()) == 0 {
+		t.Fatal("expected b to own partitions")
+	}
+
+	// a task is created through the coordinator of a, in a partition of b.
+	var task *influxdb.Task
+	for i := 0; task == nil; i++ {
+		created, err := a.svc.CreateTask(authCtx, influxdb.TaskCreate{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			Flux:           fmt.Sprintf(`option task = {name: "task %d", every: 1h} from(bucket: "b") |> range(start: -1h) |> to(bucket: "c", orgID: "0000000000000000")`, i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.leases.Owns(created.ID) {
+			task = created
+		}
+	}
+	id := scheduler.ID(task.ID)
+	sch, err := NewSchedulableTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ls.Schedule(sch); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.sch.get(id); ok {
+		t.Fatal("expected a to not schedule a task of a partition of b")
+	}
+
+	// b runs the task from its next heartbeat.
+	b.heartbeat(t)
+	if _, ok := b.sch.get(id); !ok {
+		t.Fatal("expected b to schedule the task created through a")
+	}
+
+	// an update of its schedule through a is picked up by b.
+	every := "30m"
+	updated, err := a.svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Options: options.Options{Every: *options.MustParseDuration(every)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.heartbeat(t)
+	if sch, _ := b.sch.get(id); sch.(SchedulableTask).Every != updated.Every {
+		t.Fatalf("expected b to schedule the task every %s, got %v", every, sch)
+	}
+
+	// deactivating the task through a releases it on b.
+	inactive := string(backend.TaskInactive)
+	if _, err := a.svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Status: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+	b.heartbeat(t)
+	if _, ok := b.sch.get(id); ok {
+		t.Fatal("expected b to release the task deactivated through a")
+	}
+
+	// so does deleting it.
+	active := string(backend.TaskActive)
+	if _, err := a.svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Status: &active}); err != nil {
+		t.Fatal(err)
+	}
+	b.heartbeat(t)
+	if _, ok := b.sch.get(id); !ok {
+		t.Fatal("expected b to schedule the task activated through a")
+	}
+	if err := a.svc.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	b.heartbeat(t)
+	if _, ok := b.sch.get(id); ok {
+		t.Fatal("expected b to release the task deleted through a")
+	}
+}
+
+func assertScheduledOnce(t *testing.T, tasks []*influxdb.Task, nodes ...*leaseNode) {
+	t.Helper()
+	for _, task := range tasks {
+		var n int
+		for _, node := range nodes {
+			if _, ok := node.sch.get(scheduler.ID(task.ID)); ok {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("expected task %s to be scheduled by a single node, got %d", task.ID, n)
+		}
+	}
+}
+
+// mockRunClaimer claims the runs of tasks in memory.
+type mockRunClaimer struct {
+	influxdb.TaskLeaseService
+	claimed map[influxdb.ID]time.Time
+}
+
+func (m *mockRunClaimer) ClaimTaskRun(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time) error {
+	if !scheduledFor.After(m.claimed[taskID]) {
+		return influxdb.ErrTaskRunClaimed
+	}
+	m.claimed[taskID] = scheduledFor
+	return nil
+}
+
+func Test_LeasedExecutor_ClaimsRuns(t *testing.T) {
+	var (
+		id         = influxdb.ID(1)
+		checkpoint = time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC)
+		executed   []time.Time
+	)
+
+	claimer := &mockRunClaimer{claimed: map[influxdb.ID]time.Time{id: checkpoint}}
+	leases := NewLeases(zaptest.NewLogger(t), "a", claimer, 1, time.Minute)
+	ex := NewLeasedExecutor(leases, executorFunc(func(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+		executed = append(executed, scheduledFor)
+		return nil
+	}))
+
+	// runs of tasks of partitions the node doesn't own aren't executed, nor checkpointed.
+	if err := ex.Execute(context.Background(), scheduler.ID(id), checkpoint.Add(time.Hour), checkpoint.Add(time.Hour)); err != scheduler.ErrSkipCheckpoint {
+		t.Fatalf("expected a run of an unowned task to skip its checkpoint, got %v", err)
+	}
+	if len(executed) != 0 {
+		t.Fatalf("expected a run of an unowned task to be skipped, got %v", executed)
+	}
+
+	leases.owned[0] = time.Now().Add(time.Minute)
+	for _, scheduledFor := range []time.Time{checkpoint.Add(-time.Hour), checkpoint} {
+		if err := ex.Execute(context.Background(), scheduler.ID(id), scheduledFor, scheduledFor); err != scheduler.ErrSkipCheckpoint {
+			t.Fatalf("expected a claimed run to skip its checkpoint, got %v", err)
+		}
+	}
+	if err := ex.Execute(context.Background(), scheduler.ID(id), checkpoint.Add(time.Hour), checkpoint.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(executed) != 1 || !executed[0].Equal(checkpoint.Add(time.Hour)) {
+		t.Errorf("expected only the run after the checkpoint to be executed, got %v", executed)
+	}
+
+	// a run is only executed by the first node to claim it.
+	if err := ex.Execute(context.Background(), scheduler.ID(id), checkpoint.Add(time.Hour), checkpoint.Add(time.Hour)); err != scheduler.ErrSkipCheckpoint {
+		t.Fatalf("expected a run claimed twice to skip its checkpoint, got %v", err)
+	}
+	if len(executed) != 1 {
+		t.Errorf("expected the run to be executed once, got %v", executed)
+	}
+}
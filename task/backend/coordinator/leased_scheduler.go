@@ -0,0 +1,293 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+var (
+	_ scheduler.Scheduler = (*LeasedScheduler)(nil)
+	_ scheduler.Executor  = (*LeasedExecutor)(nil)
+)
+
+// TaskFinder is the part of the task service that a LeasedScheduler and a
+// LeasedExecutor read tasks with.
+type TaskFinder interface {
+	FindTaskByID(ctx context.Context, id influxdb.ID) (*influxdb.Task, error)
+	FindTasks(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error)
+}
+
+// LeasedScheduler is a Scheduler that only schedules the tasks of the
+// partitions that its node holds the leases of.
+//
+// Tasks that are created, updated and deleted through the coordinator of the
+// node are scheduled and released as they change. The tasks of the partitions
+// of the node are read from the task service on every heartbeat, so that the
+// tasks changed through the coordinators of other nodes are scheduled and
+// released within a heartbeat. Tasks are scheduled from their latest
+// checkpoint, so the runs that the node which owned the partition before
+// already claimed are not run again.
+//
+// The runs of tasks that depend on other tasks are only held back for their
+// upstream runs when both tasks are in the partitions of the same node.
+type LeasedScheduler struct {
+	log    *zap.Logger
+	leases *Leases
+	sch    scheduler.Scheduler
+	tasks  TaskFinder
+	deps   Dependencies
+
+	mu sync.Mutex
+	// scheduled is the key of the schedule of each task that is scheduled
+	// with sch, so that tasks are only rescheduled when their schedule changes.
+	scheduled map[scheduler.ID]string
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewLeasedScheduler returns a LeasedScheduler that schedules the tasks of the
+// partitions leased by leases with sch. The upstream tasks of the tasks it
+// schedules are set on deps, which may be nil.
+func NewLeasedScheduler(log *zap.Logger, leases *Leases, sch scheduler.Scheduler, tasks TaskFinder, deps Dependencies) *LeasedScheduler {
+	if deps == nil {
+		deps = noopDependencies{}
+	}
+	return &LeasedScheduler{
+		log:       log,
+		leases:    leases,
+		sch:       sch,
+		tasks:     tasks,
+		deps:      deps,
+		scheduled: map[scheduler.ID]string{},
+		done:      make(chan struct{}),
+	}
+}
+
+// Open starts sending heartbeats, every third of the lease TTL.
+func (s *LeasedScheduler) Open(ctx context.Context) {
+	if err := s.Heartbeat(ctx); err != nil {
+		s.log.Error("Failed to renew task partition leases", zap.Error(err))
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.leases.TTL() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				if err := s.Heartbeat(ctx); err != nil {
+					s.log.Error("Failed to renew task partition leases", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops sending heartbeats, stops scheduling tasks, and releases the
+// leases of the node, so that other nodes take over its tasks right away.
+func (s *LeasedScheduler) Close(ctx context.Context) error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.releasePartitions(s.leases.Owned())
+	return s.leases.Leave(ctx)
+}
+
+// Heartbeat renews the leases of the node, stops scheduling the tasks of the
+// partitions it lost, and brings the scheduled tasks of the partitions it
+// owns in line with the task service.
+func (s *LeasedScheduler) Heartbeat(ctx context.Context) error {
+	_, lost, err := s.leases.Renew(ctx)
+	if len(lost) > 0 {
+		s.releasePartitions(lost)
+		if err := s.leases.Release(ctx, lost...); err != nil {
+			s.log.Error("Failed to release task partitions", zap.Error(err))
+		}
+	}
+	if owned := s.leases.Owned(); len(owned) > 0 {
+		if err := s.schedulePartitions(ctx, owned); err != nil {
+			s.log.Error("Failed to schedule the tasks of task partitions", zap.Error(err))
+		}
+	}
+	return err
+}
+
+// Schedule schedules a task if its node holds the lease of its partition.
+// Otherwise it is scheduled by the node that does.
+func (s *LeasedScheduler) Schedule(sch scheduler.Schedulable) error {
+	id := sch.ID()
+	if !s.leases.Owns(influxdb.ID(id)) {
+		return s.Release(id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.sch.Schedule(sch); err != nil {
+		return err
+	}
+	var key string
+	if t, ok := sch.(SchedulableTask); ok {
+		key = scheduleKey(t.Task)
+	}
+	s.scheduled[id] = key
+	return nil
+}
+
+// Release stops scheduling a task.
+func (s *LeasedScheduler) Release(id scheduler.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scheduled[id]; !ok {
+		return nil
+	}
+	delete(s.scheduled, id)
+	return s.sch.Release(id)
+}
+
+// schedulePartitions schedules the active tasks of partitions that are not
+// scheduled on their current schedule, and releases the scheduled tasks of
+// partitions that were deleted or deactivated.
+func (s *LeasedScheduler) schedulePartitions(ctx context.Context, partitions []int) error {
+	owned := make(map[int]bool, len(partitions))
+	for _, p := range partitions {
+		owned[p] = true
+	}
+
+	// only the tasks scheduled before the scan are released, so that tasks
+	// scheduled through the coordinator meanwhile are kept.
+	s.mu.Lock()
+	scheduled := make(map[scheduler.ID]bool, len(s.scheduled))
+	for id := range s.scheduled {
+		if owned[s.leases.Partition(influxdb.ID(id))] {
+			scheduled[id] = true
+		}
+	}
+	s.mu.Unlock()
+
+	found := map[scheduler.ID]bool{}
+	tasks, _, err := s.tasks.FindTasks(ctx, influxdb.TaskFilter{})
+	for ; err == nil && len(tasks) > 0; tasks, _, err = s.tasks.FindTasks(ctx, influxdb.TaskFilter{After: &tasks[len(tasks)-1].ID}) {
+		for _, task := range tasks {
+			if !owned[s.leases.Partition(task.ID)] {
+				continue
+			}
+			found[scheduler.ID(task.ID)] = true
+			if task.Status != string(backend.TaskActive) {
+				continue
+			}
+			delete(scheduled, scheduler.ID(task.ID))
+			if err := s.scheduleTask(task); err != nil {
+				s.log.Error("Failed to schedule task", zap.String("taskID", task.ID.String()), zap.Error(err))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for id := range scheduled {
+		if !found[id] {
+			s.deps.Release(id)
+		}
+		if err := s.Release(id); err != nil {
+			s.log.Error("Failed to release task", zap.String("taskID", influxdb.ID(id).String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// scheduleTask schedules a task unless it is already scheduled on its
+// current schedule.
+func (s *LeasedScheduler) scheduleTask(task *influxdb.Task) error {
+	id := scheduler.ID(task.ID)
+	s.mu.Lock()
+	key, ok := s.scheduled[id]
+	s.mu.Unlock()
+	if ok && key == scheduleKey(task) {
+		return nil
+	}
+
+	st, err := NewSchedulableTask(task)
+	if err != nil {
+		return err
+	}
+	s.deps.SetDependencies(id, dependsOn(task))
+	return s.Schedule(st)
+}
+
+// releasePartitions stops scheduling the tasks of partitions.
+func (s *LeasedScheduler) releasePartitions(partitions []int) {
+	lost := make(map[int]bool, len(partitions))
+	for _, p := range partitions {
+		lost[p] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.scheduled {
+		if !lost[s.leases.Partition(influxdb.ID(id))] {
+			continue
+		}
+		delete(s.scheduled, id)
+		if err := s.sch.Release(id); err != nil {
+			s.log.Error("Failed to release task", zap.String("taskID", influxdb.ID(id).String()), zap.Error(err))
+		}
+	}
+}
+
+// scheduleKey returns the options of a task that its schedule depends on.
+func scheduleKey(task *influxdb.Task) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%v", task.EffectiveCron(), task.Offset, task.Location, task.Status, task.DependsOn)
+}
+
+// LeasedExecutor is an Executor that only executes the runs of the tasks of
+// the partitions that its node holds the leases of. It claims each run
+// before executing it, so that a run is executed once when the partition of
+// its task moves between nodes.
+//
+// A claimed run is checkpointed, even if its executor holds it back, so a
+// node that stops after claiming a run and before it is recorded loses it.
+type LeasedExecutor struct {
+	leases   *Leases
+	executor scheduler.Executor
+}
+
+// NewLeasedExecutor returns a LeasedExecutor that executes the runs of the
+// tasks owned according to leases with executor.
+func NewLeasedExecutor(leases *Leases, executor scheduler.Executor) *LeasedExecutor {
+	return &LeasedExecutor{
+		leases:   leases,
+		executor: executor,
+	}
+}
+
+// Execute claims and executes a run if the node owns its task. Runs that the
+// node doesn't own, or that another node claimed, are not executed and
+// return scheduler.ErrSkipCheckpoint.
+func (e *LeasedExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	if !e.leases.Owns(influxdb.ID(id)) {
+		return scheduler.ErrSkipCheckpoint
+	}
+
+	if err := e.leases.ClaimRun(ctx, influxdb.ID(id), scheduledFor); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EConflict {
+			// another node already ran the task for scheduledFor.
+			return scheduler.ErrSkipCheckpoint
+		}
+		return err
+	}
+	return e.executor.Execute(ctx, id, scheduledFor, runAt)
+}
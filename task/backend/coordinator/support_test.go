@@ -41,6 +41,21 @@ type (
 	}
 )
 
+type (
+	dependenciesD struct {
+		calls []interface{}
+	}
+
+	setDependenciesCall struct {
+		TaskID   scheduler.ID
+		Upstream []scheduler.ID
+	}
+
+	releaseCallD struct {
+		TaskID scheduler.ID
+	}
+)
+
 type (
 	promise struct {
 		run *influxdb.Run
@@ -94,6 +109,14 @@ func (s *schedulerC) Release(taskID scheduler.ID) error {
 	return nil
 }
 
+func (d *dependenciesD) SetDependencies(id scheduler.ID, upstream []scheduler.ID) {
+	d.calls = append(d.calls, setDependenciesCall{id, upstream})
+}
+
+func (d *dependenciesD) Release(id scheduler.ID) {
+	d.calls = append(d.calls, releaseCallD{id})
+}
+
 func (e *executorE) ManualRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (executor.Promise, error) {
 	e.calls = append(e.calls, manualRunCall{id, runID})
 	ctx, cancel := context.WithCancel(ctx)
@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification/flux"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/downsample"
+)
+
+// DownsamplingFlux compiles the downsampling policy p of bucket src, which
+// writes to dest, into a task script. schemas are the measurement schemas of
+// src; when src has an explicit schema they determine which fields each
+// aggregate function applies to.
+//
+// The task aggregates the window of data that ends at its scheduled time, and
+// runs once the policy's offset has passed so that late points are included.
+// active is false when there are no fields to aggregate yet, in which case the
+// task should not run until the script is compiled again.
+func DownsamplingFlux(src, dest *influxdb.Bucket, p influxdb.DownsamplingPolicy, schemas []*influxdb.MeasurementSchema) (script string, active bool, err error) {
+	every, err := parser.ParseDuration(p.Every.String())
+	if err != nil {
+		return "", false, err
+	}
+	taskProps := []*ast.Property{
+		flux.Property("name", flux.String(fmt.Sprintf("Downsample %s to %s every %s", src.Name, dest.Name, p.Every))),
+		flux.Property("every", every),
+	}
+	if p.Offset.Duration > 0 {
+		offset, err := parser.ParseDuration(p.Offset.String())
+		if err != nil {
+			return "", false, err
+		}
+		taskProps = append(taskProps, flux.Property("offset", offset))
+	}
+
+	data := flux.Pipe(
+		flux.Call(flux.Identifier("from"), flux.Object(flux.Property("bucketID", flux.String(src.ID.String())))),
+		flux.Call(flux.Identifier("range"), flux.Object(flux.Property("start", flux.Negative(flux.Member("task", "every"))))),
+	)
+	if tags := tagFilterExpr(p.TagFilter); tags != nil {
+		data = flux.Pipe(data, flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", tags))))
+	}
+
+	body := []ast.Statement{
+		flux.DefineTaskOption(flux.Object(taskProps...)),
+		flux.DefineVariable("data", data),
+	}
+
+	var imports []*ast.ImportDeclaration
+	for _, fn := range p.AggregateFuncs() {
+		var data ast.Expression = flux.Identifier("data")
+		if src.SchemaType == influxdb.SchemaTypeExplicit {
+			fields := fieldFilterExpr(schemas, p, fn)
+			if fields == nil {
+				continue
+			}
+			data = flux.Pipe(data, flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", fields))))
+		} else if !influxdb.DownsamplingAggregateAllowed(influxdb.SchemaColumnDataTypeString, fn) {
+			// The field types of an implicit schema are not known, so only
+			// numeric fields are aggregated with fn and the others keep their
+			// last value.
+			imports = flux.Imports(downsample.PackagePath)
+			numeric := flux.Pipe(data, flux.Call(flux.Member("downsample", "numeric"), flux.Object()))
+			nonNumeric := flux.Pipe(data, flux.Call(flux.Member("downsample", "nonNumeric"), flux.Object()))
+			body = append(body,
+				flux.ExpressionStatement(aggregateExpr(numeric, fn, dest)),
+				flux.ExpressionStatement(aggregateExpr(nonNumeric, "last", dest)),
+			)
+			active = true
+			continue
+		}
+
+		body = append(body, flux.ExpressionStatement(aggregateExpr(data, fn, dest)))
+		active = true
+	}
+
+	f := flux.File("", imports, body)
+	return ast.Format(f), active, nil
+}
+
+// aggregateExpr returns the pipeline that aggregates data with fn over the
+// task's window and writes the result to dest.
+func aggregateExpr(data ast.Expression, fn string, dest *influxdb.Bucket) *ast.PipeExpression {
+	return flux.Pipe(data,
+		flux.Call(flux.Identifier("aggregateWindow"), flux.Object(
+			flux.Property("every", flux.Member("task", "every")),
+			flux.Property("fn", flux.Identifier(fn)),
+			flux.Property("createEmpty", flux.Bool(false)),
+		)),
+		flux.Call(flux.Identifier("to"), flux.Object(
+			flux.Property("bucketID", flux.String(dest.ID.String())),
+			flux.Property("orgID", flux.String(dest.OrgID.String())),
+		)),
+		flux.Call(flux.Identifier("yield"), flux.Object(flux.Property("name", flux.String(fn)))),
+	)
+}
+
+// tagFilterExpr returns a predicate function matching the tag values of
+// filter, or nil if filter is empty.
+func tagFilterExpr(filter map[string]string) *ast.FunctionExpression {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pred ast.Expression
+	for _, k := range keys {
+		pred = andExpr(pred, flux.Equal(columnExpr(k), flux.String(filter[k])))
+	}
+	if pred == nil {
+		return nil
+	}
+	return flux.Function(flux.FunctionParams("r"), pred)
+}
+
+// fieldFilterExpr returns a predicate function matching the fields of schemas
+// whose data type p aggregates with fn, or nil if there are none.
+func fieldFilterExpr(schemas []*influxdb.MeasurementSchema, p influxdb.DownsamplingPolicy, fn string) *ast.FunctionExpression {
+	sorted := make([]*influxdb.MeasurementSchema, len(schemas))
+	copy(sorted, schemas)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var pred ast.Expression
+	for _, ms := range sorted {
+		var fields ast.Expression
+		for _, c := range ms.Columns {
+			if c.Type == influxdb.SemanticColumnTypeField && p.Aggregates[c.DataType] == fn {
+				fields = orExpr(fields, flux.Equal(flux.Member("r", "_field"), flux.String(c.Name)))
+			}
+		}
+		if fields == nil {
+			continue
+		}
+		pred = orExpr(pred, flux.And(flux.Equal(flux.Member("r", "_measurement"), flux.String(ms.Name)), fields))
+	}
+	if pred == nil {
+		return nil
+	}
+	return flux.Function(flux.FunctionParams("r"), pred)
+}
+
+// columnExpr returns the member expression r["name"], which is valid for any tag key.
+func columnExpr(name string) *ast.MemberExpression {
+	return &ast.MemberExpression{
+		Object:   flux.Identifier("r"),
+		Property: flux.String(name),
+	}
+}
+
+func andExpr(lhs, rhs ast.Expression) ast.Expression {
+	if lhs == nil {
+		return rhs
+	}
+	return flux.And(lhs, rhs)
+}
+
+func orExpr(lhs, rhs ast.Expression) ast.Expression {
+	if lhs == nil {
+		return rhs
+	}
+	return flux.Or(lhs, rhs)
+}
@@ -0,0 +1,139 @@
+package backend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+func TestDownsamplingFlux(t *testing.T) {
+	var (
+		src  = &influxdb.Bucket{ID: 1, OrgID: 3, Name: "raw"}
+		dest = &influxdb.Bucket{ID: 2, OrgID: 3, Name: "rollup"}
+	)
+
+	policy := influxdb.DownsamplingPolicy{
+		DestinationBucketID: dest.ID,
+		Every:               influxdb.Duration{Duration: time.Hour},
+		Offset:              influxdb.Duration{Duration: 5 * time.Minute},
+		Aggregates:          map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "mean"},
+		TagFilter:           map[string]string{"host": "a"},
+	}
+
+	t.Run("implicit schema", func(t *testing.T) {
+		script, active, err := backend.DownsamplingFlux(src, dest, policy, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !active {
+			t.Error("expected task to be active")
+		}
+
+		// mean cannot be applied to string and boolean fields, which keep
+		// their last value instead.
+		exp := `import "influxdata/influxdb/downsample"
+
+option task = {name: "Downsample raw to rollup every 1h0m0s", every: 1h0m0s, offset: 5m0s}
+
+data = from(bucketID: "0000000000000001")
+	|> range(start: -task.every)
+	|> filter(fn: (r) =>
+		(r["host"] == "a"))
+
+data
+	|> downsample.numeric()
+	|> aggregateWindow(every: task.every, fn: mean, createEmpty: false)
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000003")
+	|> yield(name: "mean")
+data
+	|> downsample.nonNumeric()
+	|> aggregateWindow(every: task.every, fn: last, createEmpty: false)
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000003")
+	|> yield(name: "last")`
+		if script != exp {
+			t.Errorf("unexpected script:\n%s\nwant:\n%s", script, exp)
+		}
+	})
+
+	t.Run("implicit schema with aggregate of any type", func(t *testing.T) {
+		p := policy
+		p.TagFilter = nil
+		p.Aggregates = map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "count"}
+
+		script, _, err := backend.DownsamplingFlux(src, dest, p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp := `option task = {name: "Downsample raw to rollup every 1h0m0s", every: 1h0m0s, offset: 5m0s}
+
+data = from(bucketID: "0000000000000001")
+	|> range(start: -task.every)
+
+data
+	|> aggregateWindow(every: task.every, fn: count, createEmpty: false)
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000003")
+	|> yield(name: "count")`
+		if script != exp {
+			t.Errorf("unexpected script:\n%s\nwant:\n%s", script, exp)
+		}
+	})
+
+	t.Run("explicit schema", func(t *testing.T) {
+		explicit := *src
+		explicit.SchemaType = influxdb.SchemaTypeExplicit
+
+		p := policy
+		p.TagFilter = nil
+		p.Aggregates = map[influxdb.SchemaColumnDataType]string{
+			influxdb.SchemaColumnDataTypeFloat:  "mean",
+			influxdb.SchemaColumnDataTypeString: "last",
+		}
+
+		// Without fields there is nothing to aggregate.
+		if _, active, err := backend.DownsamplingFlux(&explicit, dest, p, nil); err != nil {
+			t.Fatal(err)
+		} else if active {
+			t.Error("expected task without fields to be inactive")
+		}
+
+		schemas := []*influxdb.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+				{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat},
+				{Name: "state", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeString},
+			},
+		}}
+		script, active, err := backend.DownsamplingFlux(&explicit, dest, p, schemas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !active {
+			t.Error("expected task to be active")
+		}
+
+		exp := `option task = {name: "Downsample raw to rollup every 1h0m0s", every: 1h0m0s, offset: 5m0s}
+
+data = from(bucketID: "0000000000000001")
+	|> range(start: -task.every)
+
+data
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "state"))
+	|> aggregateWindow(every: task.every, fn: last, createEmpty: false)
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000003")
+	|> yield(name: "last")
+data
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "usage"))
+	|> aggregateWindow(every: task.every, fn: mean, createEmpty: false)
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000003")
+	|> yield(name: "mean")`
+		if script != exp {
+			t.Errorf("unexpected script:\n%s\nwant:\n%s", script, exp)
+		}
+	})
+}
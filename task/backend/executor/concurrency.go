@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+)
+
+// taskRuns are the runs of a single task that are executing, or queued until
+// one of them completes.
+type taskRuns struct {
+	running int
+	// queued is ordered by scheduledFor, so that older runs start first.
+	queued []*promise
+}
+
+// concurrencyLimiter enforces the concurrency option of each task, by queueing
+// the runs of a task that would exceed it.
+type concurrencyLimiter struct {
+	mu    sync.Mutex
+	tasks map[influxdb.ID]*taskRuns
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{tasks: make(map[influxdb.ID]*taskRuns)}
+}
+
+// acquire reports whether p may start executing. If the task of p is at its
+// concurrency limit, p is queued and handed out by release once a run of the
+// task completes.
+func (l *concurrencyLimiter) acquire(p *promise) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tr, ok := l.tasks[p.task.ID]
+	if !ok {
+		tr = &taskRuns{}
+		l.tasks[p.task.ID] = tr
+	}
+	if tr.running < p.concurrency {
+		tr.running++
+		return true
+	}
+
+	i := sort.Search(len(tr.queued), func(i int) bool {
+		return tr.queued[i].run.ScheduledFor.After(p.run.ScheduledFor)
+	})
+	tr.queued = append(tr.queued, nil)
+	copy(tr.queued[i+1:], tr.queued[i:])
+	tr.queued[i] = p
+	return false
+}
+
+// release frees the slot p held and returns the next queued run of its task,
+// which takes over the slot, or nil if there is none.
+func (l *concurrencyLimiter) release(p *promise) *promise {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tr, ok := l.tasks[p.task.ID]
+	if !ok {
+		return nil
+	}
+	tr.running--
+
+	var next *promise
+	if len(tr.queued) > 0 && tr.running < tr.queued[0].concurrency {
+		next = tr.queued[0]
+		tr.queued = tr.queued[1:]
+		tr.running++
+	}
+	if tr.running <= 0 && len(tr.queued) == 0 {
+		delete(l.tasks, p.task.ID)
+	}
+	return next
+}
+
+// remove removes p from the queue of its task, reporting whether it was queued.
+func (l *concurrencyLimiter) remove(p *promise) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tr, ok := l.tasks[p.task.ID]
+	if !ok {
+		return false
+	}
+	for i, q := range tr.queued {
+		if q == p {
+			tr.queued = append(tr.queued[:i], tr.queued[i+1:]...)
+			if tr.running <= 0 && len(tr.queued) == 0 {
+				delete(l.tasks, p.task.ID)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// taskRunCounts are the number of running and queued runs of a task.
+type taskRunCounts struct {
+	taskID  influxdb.ID
+	running int
+	queued  int
+}
+
+// counts returns the number of running and queued runs of each task that has
+// any. The counts are copied, so that they can be used without holding the
+// lock of the limiter.
+func (l *concurrencyLimiter) counts() []taskRunCounts {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := make([]taskRunCounts, 0, len(l.tasks))
+	for id, tr := range l.tasks {
+		counts = append(counts, taskRunCounts{taskID: id, running: tr.running, queued: len(tr.queued)})
+	}
+	return counts
+}
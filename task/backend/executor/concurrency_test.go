@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	task := &influxdb.Task{ID: 1}
+	newPromise := func(id influxdb.ID, scheduledFor int64) *promise {
+		return &promise{
+			run:         &influxdb.Run{ID: id, TaskID: task.ID, ScheduledFor: time.Unix(scheduledFor, 0)},
+			task:        task,
+			concurrency: 2,
+		}
+	}
+
+	var (
+		l  = newConcurrencyLimiter()
+		p1 = newPromise(1, 100)
+		p2 = newPromise(2, 200)
+		p3 = newPromise(3, 400)
+		p4 = newPromise(4, 300)
+		p5 = newPromise(5, 500)
+	)
+
+	for _, p := range []*promise{p1, p2} {
+		if !l.acquire(p) {
+			t.Fatalf("expected run %s to start", p.run.ID)
+		}
+	}
+	for _, p := range []*promise{p3, p4, p5} {
+		if l.acquire(p) {
+			t.Fatalf("expected run %s to be queued", p.run.ID)
+		}
+	}
+
+	assertCounts := func(running, queued int) {
+		t.Helper()
+		counts := l.counts()
+		for _, c := range counts {
+			if c.taskID != task.ID || c.running != running || c.queued != queued {
+				t.Fatalf("unexpected counts for task %s: running %d, queued %d", c.taskID, c.running, c.queued)
+			}
+		}
+		if len(counts) == 0 && (running > 0 || queued > 0) {
+			t.Fatal("expected counts for task")
+		}
+	}
+	assertCounts(2, 3)
+
+	if !l.remove(p5) {
+		t.Fatal("expected queued run to be removed")
+	}
+	if l.remove(p1) {
+		t.Fatal("expected running run not to be removed")
+	}
+	assertCounts(2, 2)
+
+	// queued runs start in scheduledFor order.
+	if next := l.release(p1); next != p4 {
+		t.Fatalf("expected run 4 to start next, got %v", next)
+	}
+	if next := l.release(p2); next != p3 {
+		t.Fatalf("expected run 3 to start next, got %v", next)
+	}
+	assertCounts(2, 0)
+
+	if next := l.release(p4); next != nil {
+		t.Fatalf("expected no run to start, got %v", next)
+	}
+	if next := l.release(p3); next != nil {
+		t.Fatalf("expected no run to start, got %v", next)
+	}
+	assertCounts(0, 0)
+	if len(l.tasks) != 0 {
+		t.Fatalf("expected task without runs to be removed, got %d tasks", len(l.tasks))
+	}
+}
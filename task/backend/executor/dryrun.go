@@ -0,0 +1,212 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	storageflux "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/options"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+var _ influxdb.TaskDryRunService = (*Executor)(nil)
+
+// DryRunTask executes the script of d once, as a run scheduled for
+// d.ScheduledFor would, with the points it writes kept in memory instead of
+// written to storage. The query runs with the authorizer of ctx, and no run is
+// created. Errors of the script are reported in the result.
+func (e *Executor) DryRunTask(ctx context.Context, d influxdb.TaskDryRun) (*influxdb.TaskDryRunResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := d.Valid(); err != nil {
+		return nil, err
+	}
+	if d.ScheduledFor.IsZero() {
+		d.ScheduledFor = time.Now()
+	}
+	sf := d.ScheduledFor.UTC()
+
+	res := &influxdb.TaskDryRunResult{
+		ScheduledFor: sf,
+		Tables:       []*influxdb.TaskDryRunTable{},
+		Log:          []influxdb.Log{},
+	}
+	logf := func(format string, args ...interface{}) {
+		res.Log = append(res.Log, influxdb.Log{
+			Time:    time.Now().UTC().Format(time.RFC3339Nano),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	fail := func(err error) (*influxdb.TaskDryRunResult, error) {
+		res.Error = err.Error()
+		logf("Dry run failed: %v", err)
+		return res, nil
+	}
+
+	opts, err := options.FromScript(d.Flux)
+	if err != nil {
+		return fail(influxdb.ErrTaskOptionParse(err))
+	}
+
+	pkg, err := flux.Parse(d.Flux)
+	if err != nil {
+		return fail(influxdb.ErrFluxParseError(err))
+	}
+	if opts.Location != "" && !declaresOption(pkg, "location") {
+		pkg.Files = append([]*ast.File{locationFile(opts.Location)}, pkg.Files...)
+	}
+
+	logf("Started dry run of task %q scheduled for %s", opts.Name, sf.Format(time.RFC3339))
+
+	// the request carries the authorization of token authorizers, like the
+	// query handler; the authorizer of ctx authorizes the query either way.
+	var auth *influxdb.Authorization
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		auth, _ = a.(*influxdb.Authorization)
+	}
+
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: d.OrganizationID,
+		Compiler: lang.ASTCompiler{
+			AST: pkg,
+			Now: sf,
+		},
+	}
+	req.WithReturnNoContent(true)
+
+	sink := newPointsSink()
+	it, err := e.qs.Query(storageflux.WithPointsSink(ctx, sink), req)
+	if err != nil {
+		return fail(influxdb.ErrQueryError(err))
+	}
+
+	var runErr error
+	for it.More() {
+		if err := exhaustResultIterators(it.Next()); err != nil && runErr == nil {
+			runErr = err
+		}
+	}
+	it.Release()
+
+	res.Tables = sink.tables()
+	if traceID, isSampled, ok := tracing.InfoFromSpan(span); ok {
+		logf("trace_id=%s is_sampled=%t", traceID, isSampled)
+	}
+
+	if runErr != nil {
+		return fail(influxdb.ErrRunExecutionError(runErr))
+	}
+	if it.Err() != nil {
+		return fail(influxdb.ErrResultIteratorError(it.Err()))
+	}
+
+	logf("Completed dry run, %d series would have been written", len(res.Tables))
+	return res, nil
+}
+
+// pointsSink is a storage.PointsWriter that keeps the points written to it
+// as the series of a dry run.
+type pointsSink struct {
+	mu     sync.Mutex
+	series map[string]*influxdb.TaskDryRunTable
+	// order is the keys of series in the order they were first written.
+	order []string
+	// rows indexes the rows of each series by time.
+	rows map[string]map[int64]int
+}
+
+func newPointsSink() *pointsSink {
+	return &pointsSink{
+		series: map[string]*influxdb.TaskDryRunTable{},
+		rows:   map[string]map[int64]int{},
+	}
+}
+
+// WritePoints adds points to the series of the sink. The points are those
+// written by the to() function, with the organization and bucket encoded as
+// their name, and the measurement and field as tags.
+func (s *pointsSink) WritePoints(ctx context.Context, points []models.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range points {
+		var name [16]byte
+		copy(name[:], p.Name())
+		orgID, bucketID := tsdb.DecodeName(name)
+
+		var (
+			measurement string
+			tags        = map[string]string{}
+			seriesTags  models.Tags
+		)
+		for _, t := range p.Tags() {
+			switch string(t.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(t.Value)
+			case models.FieldKeyTagKey:
+				// the field is recorded with the fields of the row.
+			default:
+				tags[string(t.Key)] = string(t.Value)
+				seriesTags = append(seriesTags, t)
+			}
+		}
+
+		key := string(models.MakeKey(append(name[:], measurement...), seriesTags))
+		table, ok := s.series[key]
+		if !ok {
+			table = &influxdb.TaskDryRunTable{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Measurement:    measurement,
+				Tags:           tags,
+			}
+			s.series[key] = table
+			s.rows[key] = map[int64]int{}
+			s.order = append(s.order, key)
+		}
+
+		fields, err := p.Fields()
+		if err != nil {
+			return err
+		}
+
+		ts := p.UnixNano()
+		i, ok := s.rows[key][ts]
+		if !ok {
+			i = len(table.Rows)
+			s.rows[key][ts] = i
+			table.Rows = append(table.Rows, influxdb.TaskDryRunRow{
+				Time:   p.Time().UTC(),
+				Fields: map[string]interface{}{},
+			})
+		}
+		for k, v := range fields {
+			table.Rows[i].Fields[k] = v
+		}
+	}
+	return nil
+}
+
+// tables returns the series written to the sink in the order they were first written.
+func (s *pointsSink) tables() []*influxdb.TaskDryRunTable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tables := make([]*influxdb.TaskDryRunTable, 0, len(s.order))
+	for _, key := range s.order {
+		tables = append(tables, s.series[key])
+	}
+	return tables
+}
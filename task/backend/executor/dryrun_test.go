@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/models"
+	storageflux "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestExecutor_DryRunTask(t *testing.T) {
+	t.Run("Success", testDryRunSuccess)
+	t.Run("QueryFailure", testDryRunQueryFailure)
+	t.Run("InvalidScript", testDryRunInvalidScript)
+}
+
+func testDryRunSuccess(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+
+	type result struct {
+		res *influxdb.TaskDryRunResult
+		err error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		res, err := tes.ex.DryRunTask(ctx, influxdb.TaskDryRun{
+			OrganizationID: tes.tc.OrgID,
+			Flux:           script,
+			ScheduledFor:   time.Unix(123, 0),
+		})
+		resc <- result{res, err}
+	}()
+	tes.svc.WaitForQueryLive(t, script)
+
+	// write as the to() function would, through the context of the query.
+	tes.svc.mu.Lock()
+	qctx := tes.svc.mostRecentCtx
+	tes.svc.mu.Unlock()
+	name := tsdb.EncodeName(tes.tc.OrgID, influxdb.ID(2))
+	points := []models.Point{
+		models.MustNewPoint(string(name[:]), models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": "a", models.FieldKeyTagKey: "usage"}), models.Fields{"usage": 1.0}, time.Unix(1, 0)),
+		models.MustNewPoint(string(name[:]), models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": "a", models.FieldKeyTagKey: "idle"}), models.Fields{"idle": 2.0}, time.Unix(1, 0)),
+	}
+	if err := (storageflux.ToDependencies{}).PointsWriterFor(qctx).WritePoints(qctx, points); err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.SucceedQuery(script)
+
+	r := <-resc
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.res.Error != "" {
+		t.Fatalf("unexpected dry run error: %s", r.res.Error)
+	}
+
+	exp := []*influxdb.TaskDryRunTable{{
+		OrganizationID: tes.tc.OrgID,
+		BucketID:       influxdb.ID(2),
+		Measurement:    "cpu",
+		Tags:           map[string]string{"host": "a"},
+		Rows: []influxdb.TaskDryRunRow{{
+			Time:   time.Unix(1, 0).UTC(),
+			Fields: map[string]interface{}{"usage": 1.0, "idle": 2.0},
+		}},
+	}}
+	if diff := cmp.Diff(exp, r.res.Tables); diff != "" {
+		t.Fatalf("unexpected dry run tables -want/+got\n%s", diff)
+	}
+}
+
+func testDryRunQueryFailure(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+
+	tes.svc.FailNextQuery(errors.New("forced failure"))
+	res, err := tes.ex.DryRunTask(ctx, influxdb.TaskDryRun{
+		OrganizationID: tes.tc.OrgID,
+		Flux:           script,
+		ScheduledFor:   time.Unix(123, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error == "" {
+		t.Fatal("expected the dry run to report the query failure")
+	}
+	if len(res.Log) == 0 {
+		t.Fatal("expected the dry run to log its failure")
+	}
+}
+
+func testDryRunInvalidScript(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+
+	res, err := tes.ex.DryRunTask(ctx, influxdb.TaskDryRun{
+		OrganizationID: tes.tc.OrgID,
+		Flux:           `from(bucket: "b") |> range(start: -1h)`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error == "" {
+		t.Fatal("expected the dry run to report the missing task options")
+	}
+
+	if _, err := tes.ex.DryRunTask(ctx, influxdb.TaskDryRun{OrganizationID: tes.tc.OrgID}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error for a dry run without a script, got %v", err)
+	}
+}
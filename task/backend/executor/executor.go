@@ -3,17 +3,22 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/query"
+	storageflux "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 )
 
@@ -41,6 +46,20 @@ func MultiLimit(limits ...LimitFunc) LimitFunc {
 // LimitFunc is a function the executor will use to
 type LimitFunc func(*influxdb.Task, *influxdb.Run) error
 
+// FinishFunc is a function the executor calls once a run of a task has
+// finished with its status, and the error it failed with if any.
+type FinishFunc func(*influxdb.Task, *influxdb.Run, backend.RunStatus, error)
+
+const (
+	// DefaultRetryBackoff is the time the executor waits before the first
+	// retry of a failed run.
+	DefaultRetryBackoff = time.Second
+
+	// DefaultMaxRetryBackoff is the longest time the executor waits between
+	// retries of a failed run.
+	DefaultMaxRetryBackoff = time.Minute
+)
+
 // NewExecutor creates a new task executor
 func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, ts influxdb.TaskService, tcs backend.TaskControlService) (*Executor, *ExecutorMetrics) {
 	e := &Executor{
@@ -54,6 +73,10 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.Authorizati
 		promiseQueue:    make(chan *promise, 1000),                                //TODO(lh): make this configurable
 		workerLimit:     make(chan struct{}, 100),                                 //TODO(lh): make this configurable
 		limitFunc:       func(*influxdb.Task, *influxdb.Run) error { return nil }, // noop
+		finishFunc:      func(*influxdb.Task, *influxdb.Run, backend.RunStatus, error) {},
+		concurrency:     newConcurrencyLimiter(),
+		retryBackoff:    DefaultRetryBackoff,
+		maxRetryBackoff: DefaultMaxRetryBackoff,
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -85,6 +108,20 @@ type Executor struct {
 
 	limitFunc LimitFunc
 
+	// finishFunc is called after each run finishes.
+	finishFunc FinishFunc
+
+	// notifier sends the notifications of tasks about their runs, if set.
+	notifier *Notifier
+
+	// queues the runs of tasks that are at their concurrency limit
+	concurrency *concurrencyLimiter
+
+	// the backoff before the first retry of a failed run, which doubles with
+	// each retry up to maxRetryBackoff.
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
 	workerLimit chan struct{}
@@ -95,6 +132,37 @@ func (e *Executor) SetLimitFunc(l LimitFunc) {
 	e.limitFunc = l
 }
 
+// SetFinishFunc sets the func called after each run of this task executor finishes.
+func (e *Executor) SetFinishFunc(f FinishFunc) {
+	e.finishFunc = f
+}
+
+// SetNotifier sets the notifier that sends the notifications of tasks about
+// the runs that finish.
+func (e *Executor) SetNotifier(n *Notifier) {
+	e.notifier = n
+}
+
+// SetRetryBackoff sets the backoff before the first retry of a failed run,
+// and the maximum backoff it doubles up to with each further retry.
+func (e *Executor) SetRetryBackoff(initial, max time.Duration) {
+	e.retryBackoff = initial
+	e.maxRetryBackoff = max
+}
+
+// retryBackoffFor returns the backoff before the given retry of a run, where
+// the first retry is 1.
+func (e *Executor) retryBackoffFor(retry int) time.Duration {
+	backoff := e.retryBackoff
+	for i := 1; i < retry && backoff < e.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > e.maxRetryBackoff {
+		backoff = e.maxRetryBackoff
+	}
+	return backoff
+}
+
 // Execute is a executor to satisfy the needs of tasks
 func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
 	_, err := e.PromisedExecute(ctx, id, scheduledFor, runAt)
@@ -119,12 +187,23 @@ func (e *Executor) PromisedExecute(ctx context.Context, id scheduler.ID, schedul
 }
 
 func (e *Executor) ManualRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (Promise, error) {
+	return e.manualRun(ctx, id, runID, false)
+}
+
+// BackfillRun executes a manual run of a backfill. Backfills limit the number
+// of their runs that execute at once themselves, so the run does not take one
+// of the task's concurrency slots, leaving them to the task's live schedule.
+func (e *Executor) BackfillRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (Promise, error) {
+	return e.manualRun(ctx, id, runID, true)
+}
+
+func (e *Executor) manualRun(ctx context.Context, id influxdb.ID, runID influxdb.ID, backfill bool) (Promise, error) {
 	// create promises for any manual runs
 	r, err := e.tcs.StartManualRun(ctx, id, runID)
 	if err != nil {
 		return nil, err
 	}
-	p, err := e.createPromise(ctx, r)
+	p, err := e.createPromise(ctx, r, backfill)
 
 	e.startWorker()
 	e.metrics.manualRunsCounter.WithLabelValues(id.String()).Inc()
@@ -143,7 +222,7 @@ func (e *Executor) ResumeCurrentRun(ctx context.Context, id influxdb.ID, runID i
 				continue
 			}
 
-			p, err := e.createPromise(ctx, run)
+			p, err := e.createPromise(ctx, run, false)
 
 			e.startWorker()
 			e.metrics.resumeRunsCounter.WithLabelValues(id.String()).Inc()
@@ -159,7 +238,7 @@ func (e *Executor) createRun(ctx context.Context, id influxdb.ID, scheduledFor t
 		return nil, err
 	}
 
-	return e.createPromise(ctx, r)
+	return e.createPromise(ctx, r, false)
 }
 
 func (e *Executor) startWorker() {
@@ -185,6 +264,20 @@ func (e *Executor) startWorker() {
 	}
 }
 
+// cancelQueued cancels a run that was queued by its task's concurrency limit
+// before it started.
+func (e *Executor) cancelQueued(p *promise) {
+	e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), "Run canceled")
+	e.tcs.UpdateRunState(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), backend.RunCanceled)
+	if _, err := e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
+		e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+
+	p.err = influxdb.ErrRunCanceled
+	close(p.done)
+	e.currentPromises.Delete(p.run.ID)
+}
+
 // Cancel a run of a specific task.
 func (e *Executor) Cancel(ctx context.Context, runID influxdb.ID) error {
 	// find the promise
@@ -194,13 +287,18 @@ func (e *Executor) Cancel(ctx context.Context, runID influxdb.ID) error {
 	}
 	promise := val.(*promise)
 
+	// a run waiting for its task's concurrency limit has no worker to cancel it.
+	if e.concurrency.remove(promise) {
+		e.cancelQueued(promise)
+	}
+
 	// call cancel on it.
 	promise.Cancel(ctx)
 
 	return nil
 }
 
-func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promise, error) {
+func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run, backfill bool) (*promise, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -209,16 +307,24 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 		return nil, err
 	}
 
+	// the concurrency option is the number of runs of the task that may execute at once.
+	concurrency := 1
+	if opts, err := options.FromScript(t.Flux); err == nil && opts.Concurrency != nil {
+		concurrency = int(*opts.Concurrency)
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	// create promise
 	p := &promise{
-		run:        run,
-		task:       t,
-		auth:       t.Authorization,
-		createdAt:  time.Now().UTC(),
-		done:       make(chan struct{}),
-		ctx:        ctx,
-		cancelFunc: cancel,
+		run:         run,
+		task:        t,
+		auth:        t.Authorization,
+		concurrency: concurrency,
+		backfill:    backfill,
+		createdAt:   time.Now().UTC(),
+		done:        make(chan struct{}),
+		ctx:         ctx,
+		cancelFunc:  cancel,
 	}
 
 	// insert promise into queue to be worked
@@ -247,54 +353,79 @@ type worker struct {
 }
 
 func (w *worker) work() {
+	// the next run of a task whose concurrency slot this worker released
+	var next *promise
+
 	// loop until we have no more work to do in the promise queue
 	for {
-		var prom *promise
-		// check to see if we can execute
-		select {
-		case p, ok := <-w.e.promiseQueue:
-
-			if !ok {
-				// the promiseQueue has been closed
+		prom := next
+		next = nil
+		if prom == nil {
+			// check to see if we can execute
+			select {
+			case p, ok := <-w.e.promiseQueue:
+
+				if !ok {
+					// the promiseQueue has been closed
+					return
+				}
+				prom = p
+			default:
+				// if nothing is left in the queue we are done
 				return
 			}
-			prom = p
-		default:
-			// if nothing is left in the queue we are done
-			return
+
+			// runs over their task's concurrency limit wait for a running run of the task to complete.
+			// a retried run still holds the slot of its first attempt.
+			if !prom.backfill && prom.attempts == 0 && !w.e.concurrency.acquire(prom) {
+				continue
+			}
 		}
 
 		// check to make sure we are below the limits.
-		for {
-			err := w.e.limitFunc(prom.task, prom.run)
-			if err == nil {
-				break
+		if w.waitForLimit(prom) {
+			// execute the promise
+			if w.executeQuery(prom) {
+				// the run is retried after a backoff, without holding this worker.
+				continue
 			}
 
-			// add to the run log
-			w.e.tcs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), fmt.Sprintf("Task limit reached: %s", err.Error()))
+			// close promise done channel and set appropriate error
+			close(prom.done)
+		}
 
-			// sleep
-			select {
-			// If done the promise was canceled
-			case <-prom.ctx.Done():
-				w.e.tcs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), "Run canceled")
-				w.e.tcs.UpdateRunState(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), backend.RunCanceled)
-				prom.err = influxdb.ErrRunCanceled
-				close(prom.done)
-				return
-			case <-time.After(time.Second):
-			}
+		// remove promise from registry
+		w.e.currentPromises.Delete(prom.run.ID)
+
+		if !prom.backfill {
+			next = w.e.concurrency.release(prom)
 		}
+	}
+}
 
-		// execute the promise
-		w.executeQuery(prom)
+// waitForLimit waits until the limit func allows p to execute. It returns
+// false if p was canceled while waiting.
+func (w *worker) waitForLimit(p *promise) bool {
+	for {
+		err := w.e.limitFunc(p.task, p.run)
+		if err == nil {
+			return true
+		}
 
-		// close promise done channel and set appropriate error
-		close(prom.done)
+		// add to the run log
+		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Task limit reached: %s", err.Error()))
 
-		// remove promise from registry
-		w.e.currentPromises.Delete(prom.run.ID)
+		// sleep
+		select {
+		// If done the promise was canceled
+		case <-p.ctx.Done():
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), "Run canceled")
+			w.e.tcs.UpdateRunState(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), backend.RunCanceled)
+			p.err = influxdb.ErrRunCanceled
+			close(p.done)
+			return false
+		case <-time.After(time.Second):
+		}
 	}
 }
 
@@ -351,24 +482,137 @@ func (w *worker) finish(p *promise, rs backend.RunStatus, err error) {
 		w.e.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
 
-	if _, err := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
-		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	finished, ferr := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID)
+	if ferr != nil {
+		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(ferr))
+	}
+
+	w.e.finishFunc(p.task, p.run, rs, err)
+
+	if ferr == nil && w.e.notifier != nil && p.task.Notification != nil {
+		// the failed runs before the run are counted when it is finished, so
+		// that runs that finish at once don't see the same count.
+		go w.notify(p, finished.FailedRuns, rs, err)
+	}
+}
+
+// notify sends the notification of the task of p about its run, that
+// finished with status rs after failed runs in a row had failed.
+func (w *worker) notify(p *promise, failed int, rs backend.RunStatus, runErr error) {
+	ctx, cancel := context.WithTimeout(p.ctx, notifyTimeout)
+	defer cancel()
+
+	// the notification of the task may have changed since the run started.
+	task, err := w.e.ts.FindTaskByID(ctx, p.task.ID)
+	if err == nil {
+		err = w.e.notifier.Notify(ctx, task, p.run, rs, failed, runErr)
+	}
+	if err != nil {
+		w.e.log.Error("Failed to send task notification", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
 }
 
-func (w *worker) executeQuery(p *promise) {
+// executeQuery makes an attempt at executing the query of p. It returns true
+// if the attempt failed and p is queued again to be retried after a backoff.
+func (w *worker) executeQuery(p *promise) (retrying bool) {
 	span, ctx := tracing.StartSpanFromContext(p.ctx)
 	defer span.Finish()
 
-	// start
-	w.start(p)
+	if p.attempts == 0 {
+		// start
+		w.start(p)
+	} else if p.ctx.Err() != nil {
+		// the run was canceled while it waited to be retried.
+		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), "Run canceled")
+		w.finish(p, backend.RunCanceled, influxdb.ErrRunCanceled)
+		return false
+	}
 
 	pkg, err := flux.Parse(p.task.Flux)
 	if err != nil {
 		w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
-		return
+		return false
 	}
 
+	// the location of the task is exposed to the run as the location option,
+	// unless the script declares it itself.
+	if p.task.Location != "" && !declaresOption(pkg, "location") {
+		pkg.Files = append([]*ast.File{locationFile(p.task.Location)}, pkg.Files...)
+	}
+
+	// the retry option is the number of times a run is attempted before it fails.
+	attempts := 1
+	if opts, err := options.FromScript(p.task.Flux); err == nil && opts.Retry != nil {
+		attempts = int(*opts.Retry)
+	}
+
+	p.attempts++
+	err = w.runQuery(ctx, span, p, pkg)
+	if err == nil {
+		w.finish(p, backend.RunSuccess, nil)
+		return false
+	}
+	if p.attempts >= attempts || backend.IsUnrecoverable(err) || p.ctx.Err() != nil {
+		w.finish(p, backend.RunFail, err)
+		return false
+	}
+
+	backoff := w.e.retryBackoffFor(p.attempts)
+	w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Attempt %d of %d failed, retrying in %s: %v", p.attempts, attempts, backoff, err))
+	w.e.metrics.LogRetry(p.task)
+	w.e.retryAfter(p, backoff)
+	return true
+}
+
+// retryAfter queues p to be worked again once backoff has passed, or as soon
+// as it is canceled.
+func (e *Executor) retryAfter(p *promise, backoff time.Duration) {
+	go func() {
+		timer := time.NewTimer(backoff)
+		defer timer.Stop()
+
+		select {
+		case <-p.ctx.Done():
+		case <-timer.C:
+		}
+
+		e.promiseQueue <- p
+		e.startWorker()
+	}()
+}
+
+// declaresOption reports whether any file of pkg declares the option name.
+func declaresOption(pkg *ast.Package, name string) bool {
+	for _, f := range pkg.Files {
+		for _, stmt := range f.Body {
+			opt, ok := stmt.(*ast.OptionStatement)
+			if !ok {
+				continue
+			}
+			if a, ok := opt.Assignment.(*ast.VariableAssignment); ok && a.ID.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// locationFile returns a file declaring the location option as location.
+func locationFile(location string) *ast.File {
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID:   &ast.Identifier{Name: "location"},
+					Init: &ast.StringLiteral{Value: location},
+				},
+			},
+		},
+	}
+}
+
+// runQuery makes a single attempt at executing the query of p.
+func (w *worker) runQuery(ctx context.Context, span opentracing.Span, p *promise, pkg *ast.Package) error {
 	sf := p.run.ScheduledFor
 
 	req := &query.Request{
@@ -381,11 +625,11 @@ func (w *worker) executeQuery(p *promise) {
 	}
 	req.WithReturnNoContent(true)
 	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
-	it, err := w.e.qs.Query(ctx, req)
+	ws := storageflux.NewWriteStatistics()
+	it, err := w.e.qs.Query(storageflux.WithWriteStatistics(ctx, ws), req)
 	if err != nil {
 		// Assume the error should not be part of the runResult.
-		w.finish(p, backend.RunFail, influxdb.ErrQueryError(err))
-		return
+		return influxdb.ErrQueryError(err)
 	}
 
 	var runErr error
@@ -400,6 +644,12 @@ func (w *worker) executeQuery(p *promise) {
 
 	it.Release()
 
+	// record what the attempt read and wrote, whether or not it succeeded.
+	stats := runStatistics(it.Statistics(), ws)
+	if err := w.e.tcs.UpdateRunStatistics(p.ctx, p.task.ID, p.run.ID, stats); err != nil {
+		w.e.log.Error("Failed to update run statistics", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+
 	// log the trace id and whether or not it was sampled into the run log
 	if traceID, isSampled, ok := tracing.InfoFromSpan(span); ok {
 		msg := fmt.Sprintf("trace_id=%s is_sampled=%t", traceID, isSampled)
@@ -407,16 +657,47 @@ func (w *worker) executeQuery(p *promise) {
 	}
 
 	if runErr != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrRunExecutionError(runErr))
-		return
+		return influxdb.ErrRunExecutionError(runErr)
 	}
 
 	if it.Err() != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrResultIteratorError(it.Err()))
-		return
+		return influxdb.ErrResultIteratorError(it.Err())
 	}
+	return nil
+}
 
-	w.finish(p, backend.RunSuccess, nil)
+// runStatistics returns the statistics of a run from the statistics of its
+// query and the rows it wrote.
+func runStatistics(qs flux.Statistics, ws *storageflux.WriteStatistics) *influxdb.RunStatistics {
+	stats := &influxdb.RunStatistics{
+		ScannedValues:  sumMetadata(qs.Metadata, "influxdb/scanned-values"),
+		ScannedBytes:   sumMetadata(qs.Metadata, "influxdb/scanned-bytes"),
+		MaxAllocated:   qs.MaxAllocated,
+		TotalAllocated: qs.TotalAllocated,
+	}
+	for bucketID, rows := range ws.RowsWritten() {
+		stats.Writes = append(stats.Writes, influxdb.RunBucketWrite{BucketID: bucketID, Rows: rows})
+		stats.RowsWritten += rows
+	}
+	sort.Slice(stats.Writes, func(i, j int) bool {
+		return stats.Writes[i].BucketID < stats.Writes[j].BucketID
+	})
+	return stats
+}
+
+// sumMetadata sums the integer values of key in the metadata of a query,
+// which has a value for each source that reported it.
+func sumMetadata(md flux.Metadata, key string) int64 {
+	var sum int64
+	for _, v := range md[key] {
+		switch v := v.(type) {
+		case int:
+			sum += int64(v)
+		case int64:
+			sum += v
+		}
+	}
+	return sum
 }
 
 // RunsActive returns the current number of workers, which is equivalent to
@@ -441,6 +722,13 @@ type promise struct {
 	task *influxdb.Task
 	auth *influxdb.Authorization
 
+	// the number of runs of task that may execute at once
+	concurrency int
+	// backfill runs are not limited by the concurrency of task
+	backfill bool
+	// the number of times the query of run was attempted
+	attempts int
+
 	done chan struct{}
 	err  error
 
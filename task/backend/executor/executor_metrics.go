@@ -16,6 +16,7 @@ type ExecutorMetrics struct {
 	errorsCounter        *prometheus.CounterVec
 	manualRunsCounter    *prometheus.CounterVec
 	resumeRunsCounter    *prometheus.CounterVec
+	retriesCounter       *prometheus.CounterVec
 	unrecoverableCounter *prometheus.CounterVec
 	runLatency           *prometheus.HistogramVec
 }
@@ -24,6 +25,8 @@ type runCollector struct {
 	totalRunsActive   *prometheus.Desc
 	workersBusy       *prometheus.Desc
 	promiseQueueUsage *prometheus.Desc
+	taskRunsRunning   *prometheus.Desc
+	taskRunsQueued    *prometheus.Desc
 	ex                *Executor
 }
 
@@ -85,6 +88,13 @@ func NewExecutorMetrics(ex *Executor) *ExecutorMetrics {
 			Help:      "Total number of runs resumed by task ID",
 		}, []string{"taskID"}),
 
+		retriesCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retries_counter",
+			Help:      "Total number of failed run attempts that were retried, by task type",
+		}, []string{"task_type"}),
+
 		runLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -115,6 +125,18 @@ func NewRunCollector(ex *Executor) prometheus.Collector {
 			nil,
 			prometheus.Labels{},
 		),
+		taskRunsRunning: prometheus.NewDesc(
+			"task_executor_task_runs_running",
+			"Number of runs of a task that are currently running",
+			[]string{"taskID"},
+			prometheus.Labels{},
+		),
+		taskRunsQueued: prometheus.NewDesc(
+			"task_executor_task_runs_queued",
+			"Number of runs of a task waiting for the task's concurrency limit",
+			[]string{"taskID"},
+			prometheus.Labels{},
+		),
 		ex: ex,
 	}
 }
@@ -129,6 +151,7 @@ func (em *ExecutorMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.runDuration,
 		em.manualRunsCounter,
 		em.resumeRunsCounter,
+		em.retriesCounter,
 		em.unrecoverableCounter,
 		em.runLatency,
 	}
@@ -161,6 +184,11 @@ func (em *ExecutorMetrics) LogError(taskType string, err error) {
 	}
 }
 
+// LogRetry increments the count of failed run attempts that are retried.
+func (em *ExecutorMetrics) LogRetry(task *influxdb.Task) {
+	em.retriesCounter.WithLabelValues(task.Type).Inc()
+}
+
 // LogUnrecoverableError increments the count of unrecoverable errors, which require admin intervention to resolve or deactivate
 // This count is separate from the errors count so that the errors metric can be used to identify only internal, rather than user errors
 // and so that unrecoverable errors can be quickly identified for deactivation
@@ -178,6 +206,8 @@ func (r *runCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- r.workersBusy
 	ch <- r.promiseQueueUsage
 	ch <- r.totalRunsActive
+	ch <- r.taskRunsRunning
+	ch <- r.taskRunsQueued
 }
 
 // Collect returns the current state of all metrics of the run collector.
@@ -187,4 +217,9 @@ func (r *runCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(r.promiseQueueUsage, prometheus.GaugeValue, r.ex.PromiseQueueUsage())
 
 	ch <- prometheus.MustNewConstMetric(r.totalRunsActive, prometheus.GaugeValue, float64(r.ex.RunsActive()))
+
+	for _, c := range r.ex.concurrency.counts() {
+		ch <- prometheus.MustNewConstMetric(r.taskRunsRunning, prometheus.GaugeValue, float64(c.running), c.taskID.String())
+		ch <- prometheus.MustNewConstMetric(r.taskRunsQueued, prometheus.GaugeValue, float64(c.queued), c.taskID.String())
+	}
 }
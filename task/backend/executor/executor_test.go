@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
@@ -18,10 +20,14 @@ import (
 	"github.com/influxdata/influxdb/kit/prom/promtest"
 	tracetest "github.com/influxdata/influxdb/kit/tracing/testing"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
 	"github.com/influxdata/influxdb/query"
+	storageflux "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
 	"github.com/opentracing/opentracing-go"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/uber/jaeger-client-go"
 	"go.uber.org/zap/zaptest"
 )
@@ -56,6 +62,9 @@ func taskExecutorSystem(t *testing.T) tes {
 		tcs         = &taskControlService{TaskControlService: i}
 		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, i, i, tcs)
 	)
+	if err := i.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	return tes{
 		svc:     aqs,
 		ex:      ex,
@@ -69,13 +78,21 @@ func taskExecutorSystem(t *testing.T) tes {
 func TestTaskExecutor(t *testing.T) {
 	t.Run("QuerySuccess", testQuerySuccess)
 	t.Run("QueryFailure", testQueryFailure)
+	t.Run("QueryRetry", testQueryRetry)
+	t.Run("QueryRetryExhausted", testQueryRetryExhausted)
+	t.Run("QueryRetryReleasesWorker", testQueryRetryReleasesWorker)
+	t.Run("Location", testLocation)
 	t.Run("ManualRun", testManualRun)
 	t.Run("ResumeRun", testResumingRun)
+	t.Run("HeldRun", testHeldRun)
 	t.Run("WorkerLimit", testWorkerLimit)
 	t.Run("LimitFunc", testLimitFunc)
+	t.Run("FinishFunc", testFinishFunc)
+	t.Run("ConcurrencyLimit", testConcurrencyLimit)
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("Notification", testNotification)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -139,6 +156,10 @@ func testQuerySuccess(t *testing.T) {
 		t.Fatalf("expected 3 run logs, found %d", len(run.Log))
 	}
 
+	if run.Statistics == nil {
+		t.Fatal("expected the statistics of the run to be recorded")
+	}
+
 	sctx := span.Context().(jaeger.SpanContext)
 	expectedMessage := fmt.Sprintf("trace_id=%s is_sampled=true", sctx.TraceID())
 	if expectedMessage != run.Log[1].Message {
@@ -182,6 +203,373 @@ func testQueryFailure(t *testing.T) {
 	}
 }
 
+type senderFunc func(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+
+func (f senderFunc) Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+	return f(ctx, e, msg)
+}
+
+func testNotification(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	sent := make(chan endpoint.Message, 1)
+	tes.ex.SetNotifier(NewNotifier(tes.i, senderFunc(func(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error {
+		sent <- msg
+		return nil
+	})))
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	e := &endpoint.HTTP{
+		Base: endpoint.Base{
+			Name:   "on-call",
+			OrgID:  &tes.tc.OrgID,
+			Status: influxdb.Active,
+		},
+		URL:        "http://localhost:7777",
+		Method:     "POST",
+		AuthMethod: "none",
+	}
+	if err := tes.i.CreateNotificationEndpoint(ctx, e, tes.tc.Auth.GetUserID()); err != nil {
+		t.Fatal(err)
+	}
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: tes.tc.OrgID,
+		OwnerID:        tes.tc.Auth.GetUserID(),
+		Flux:           script,
+		Notification:   &influxdb.TaskNotification{EndpointID: *e.ID, Policy: influxdb.TaskNotifyRecovery},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake query service only knows the queries of runs scheduled for 123.
+	execute := func(fail bool) {
+		t.Helper()
+		promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tes.svc.WaitForQueryLive(t, script)
+		if fail {
+			tes.svc.FailQuery(script, errors.New("blargyblargblarg"))
+		} else {
+			tes.svc.SucceedQuery(script)
+		}
+		<-promise.Done()
+	}
+	expectMessage := func(level notification.CheckLevel) {
+		t.Helper()
+		select {
+		case msg := <-sent:
+			if msg.Level != level || msg.DedupKey != task.ID.String() {
+				t.Fatalf("unexpected notification %+v", msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a notification to be sent")
+		}
+	}
+	expectNoMessage := func() {
+		t.Helper()
+		select {
+		case msg := <-sent:
+			t.Fatalf("unexpected notification %+v", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	execute(true)
+	expectMessage(notification.Critical)
+	execute(true)
+	expectNoMessage()
+	execute(false)
+	expectMessage(notification.Ok)
+	execute(false)
+	expectNoMessage()
+}
+
+// fmtTestRetryScript is fmtTestScript with runs attempted twice.
+const fmtTestRetryScript = `
+option task = {
+			name: %q,
+			every: 1m,
+			retry: 2,
+}
+from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
+
+func testQueryRetry(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.SetRetryBackoff(time.Millisecond, time.Millisecond)
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first attempt fails, the retry succeeds
+	tes.svc.FailNextQuery(errors.New("bucket temporarily unavailable"))
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	run := tes.tcs.run
+	if run == nil {
+		t.Fatal("expected run returned by FinishRun to not be nil")
+	}
+	if run.Status != backend.RunSuccess.String() {
+		t.Fatalf("expected run to succeed, got %q", run.Status)
+	}
+
+	var retried bool
+	for _, l := range run.Log {
+		if strings.HasPrefix(l.Message, "Attempt 1 of 2 failed") {
+			retried = true
+		}
+	}
+	if !retried {
+		t.Fatalf("expected the failed attempt in the run logs, got %+v", run.Log)
+	}
+
+	task, err = tes.i.FindTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.LastRunStatus != backend.RunSuccess.String() {
+		t.Fatalf("expected last run status to be success, got %q", task.LastRunStatus)
+	}
+}
+
+func testQueryRetryExhausted(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.SetRetryBackoff(time.Millisecond, time.Millisecond)
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		tes.svc.WaitForQueryLive(t, script)
+		tes.svc.FailQuery(script, errors.New("bucket temporarily unavailable"))
+	}
+
+	<-promise.Done()
+
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	task, err = tes.i.FindTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.LastRunStatus != backend.RunFail.String() {
+		t.Fatalf("expected last run status to be failed, got %q", task.LastRunStatus)
+	}
+}
+
+func testQueryRetryReleasesWorker(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.SetRetryBackoff(time.Hour, time.Hour)
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.FailNextQuery(errors.New("bucket temporarily unavailable"))
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the worker is released while the run waits for its retry.
+	for i := 0; len(tes.ex.workerLimit) > 0; i++ {
+		if i == 100 {
+			t.Fatal("expected the worker to be released during the retry backoff")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-promise.Done():
+		t.Fatal("expected the run to wait for its retry")
+	default:
+	}
+
+	// a run waiting for its retry can be canceled.
+	if err := tes.ex.Cancel(ctx, promise.ID()); err != nil {
+		t.Fatal(err)
+	}
+	<-promise.Done()
+	if got := promise.Error(); got != influxdb.ErrRunCanceled {
+		t.Fatalf("expected run to be canceled, got %v", got)
+	}
+	if run := tes.tcs.run; run == nil || run.Status != backend.RunCanceled.String() {
+		t.Fatalf("expected canceled run, got %+v", run)
+	}
+}
+
+func TestExecutor_retryBackoffFor(t *testing.T) {
+	e := &Executor{retryBackoff: time.Second, maxRetryBackoff: 5 * time.Second}
+
+	for retry, exp := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if got := e.retryBackoffFor(retry); got != exp {
+			t.Errorf("retry %d: expected backoff %s, got %s", retry, exp, got)
+		}
+	}
+}
+
+func TestExecutor_runStatistics(t *testing.T) {
+	qs := flux.Statistics{
+		MaxAllocated:   64,
+		TotalAllocated: 128,
+		Metadata: flux.Metadata{
+			// one value for each source of the query.
+			"influxdb/scanned-values": []interface{}{3, 4},
+			"influxdb/scanned-bytes":  []interface{}{24, int64(32)},
+		},
+	}
+
+	stats := runStatistics(qs, storageflux.NewWriteStatistics())
+	exp := &influxdb.RunStatistics{
+		ScannedValues:  7,
+		ScannedBytes:   56,
+		MaxAllocated:   64,
+		TotalAllocated: 128,
+	}
+	if !reflect.DeepEqual(stats, exp) {
+		t.Fatalf("expected statistics %+v, got %+v", exp, stats)
+	}
+}
+
+func TestExecutor_locationOption(t *testing.T) {
+	for _, tt := range []struct {
+		script string
+		exp    string
+	}{
+		{script: `option task = {name: "a", cron: "0 2 * * *"}`, exp: "America/New_York"},
+		{script: `option location = "Europe/Paris"
+option task = {name: "a", cron: "0 2 * * *"}`, exp: "Europe/Paris"},
+	} {
+		pkg, err := flux.Parse(tt.script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !declaresOption(pkg, "location") {
+			pkg.Files = append([]*ast.File{locationFile("America/New_York")}, pkg.Files...)
+		}
+
+		_, scope, err := flux.EvalAST(context.Background(), pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc, ok := scope.Lookup("location")
+		if !ok {
+			t.Fatal("expected location option to be declared")
+		}
+		if loc.Str() != tt.exp {
+			t.Errorf("expected location %q, got %q", tt.exp, loc.Str())
+		}
+	}
+}
+
+// fmtTestLocationScript is fmtTestScript scheduled at 02:00 in New York.
+const fmtTestLocationScript = `
+option task = {
+			name: %q,
+			cron: "0 2 * * *",
+			location: "America/New_York",
+}
+from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
+
+func testLocation(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestLocationScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the task is scheduled the way the coordinator schedules it, across the
+	// start of daylight saving time in New York on 2020-03-08.
+	sch, last, err := scheduler.NewScheduleInLocation(task.EffectiveCron(), task.Location, time.Date(2020, 3, 7, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{
+		// 02:00 does not exist on the day the clocks spring forward.
+		"2020-03-08T03:00:00-04:00",
+		"2020-03-09T02:00:00-04:00",
+	} {
+		next, err := sch.Next(last)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), next, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tes.svc.WaitForQueryLiveAt(t, script, "America/New_York", next)
+
+		run, err := tes.i.FindRunByID(ctx, task.ID, promise.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := run.ScheduledFor.In(loc).Format(time.RFC3339); got != exp {
+			t.Errorf("expected run scheduled for %s, got %s", exp, got)
+		}
+
+		if err := tes.ex.Cancel(ctx, promise.ID()); err != nil {
+			t.Fatal(err)
+		}
+		<-promise.Done()
+		last = next
+	}
+}
+
 func testManualRun(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
@@ -272,6 +660,88 @@ func testResumingRun(t *testing.T) {
 	}
 }
 
+func testHeldRun(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := scheduler.ID(task.ID)
+	t1, t2 := time.Unix(123, 0).UTC(), time.Unix(183, 0).UTC()
+	for _, sf := range []time.Time{t1, t1, t2} {
+		if err := tes.ex.HoldRun(ctx, id, sf, sf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// holding a run twice doesn't create another run.
+	runs, err := tes.i.CurrentlyRunning(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 waiting runs, got %d", len(runs))
+	}
+	var held *influxdb.Run
+	for _, r := range runs {
+		if r.Status != backend.RunWaiting.String() {
+			t.Fatalf("expected run to be waiting, got %q", r.Status)
+		}
+		if len(r.Log) != 1 {
+			t.Fatalf("expected waiting run to have a log, got %v", r.Log)
+		}
+		if r.ScheduledFor.Equal(t1) {
+			held = r
+		}
+	}
+	if sfs, err := tes.ex.HeldRuns(ctx, id); err != nil || len(sfs) != 2 {
+		t.Fatalf("expected 2 held runs, got %v: %v", sfs, err)
+	}
+
+	// the waiting run is executed when it is released.
+	released, err := tes.ex.ReleaseRun(ctx, id, t1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !released {
+		t.Fatal("expected run to be released")
+	}
+	if released, _ := tes.ex.ReleaseRun(ctx, id, t1); released {
+		t.Fatal("expected run to be released once")
+	}
+	p, ok := tes.ex.currentPromises.Load(held.ID)
+	if !ok {
+		t.Fatal("expected released run to have a promise")
+	}
+	promise := p.(*promise)
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+	<-promise.Done()
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	// a dropped run is finished without executing.
+	if err := tes.ex.DropRun(ctx, id, t2, "dropped"); err != nil {
+		t.Fatal(err)
+	}
+	if sfs, err := tes.ex.HeldRuns(ctx, id); err != nil || len(sfs) != 0 {
+		t.Fatalf("expected no held runs, got %v: %v", sfs, err)
+	}
+	runs, err = tes.i.CurrentlyRunning(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected no current runs, got %d", len(runs))
+	}
+}
+
 func testWorkerLimit(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
@@ -341,6 +811,104 @@ func testLimitFunc(t *testing.T) {
 	}
 }
 
+func testFinishFunc(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type finished struct {
+		taskID       influxdb.ID
+		scheduledFor time.Time
+		status       backend.RunStatus
+	}
+	finishedc := make(chan finished, 1)
+	tes.ex.SetFinishFunc(func(task *influxdb.Task, run *influxdb.Run, rs backend.RunStatus, err error) {
+		finishedc <- finished{taskID: task.ID, scheduledFor: run.ScheduledFor, status: rs}
+	})
+
+	if _, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0)); err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	select {
+	case f := <-finishedc:
+		exp := finished{taskID: task.ID, scheduledFor: time.Unix(123, 0).UTC(), status: backend.RunSuccess}
+		if f.taskID != exp.taskID || !f.scheduledFor.Equal(exp.scheduledFor) || f.status != exp.status {
+			t.Fatalf("expected finish func to be called with %v, got %v", exp, f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("finish func was not called")
+	}
+}
+
+func testConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	metrics := tes.metrics
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	// the task has the default concurrency of 1
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.WaitForQueryLive(t, script)
+
+	queued, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(183, 0), time.Unix(186, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for a worker to queue the second run
+	var mg []*dto.MetricFamily
+	for i := 0; i < 100; i++ {
+		mg = promtest.MustGather(t, reg)
+		if m := promtest.FindMetric(mg, "task_executor_task_runs_queued", map[string]string{"taskID": task.ID.String()}); m != nil && *m.Gauge.Value > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	m := promtest.MustFindMetric(t, mg, "task_executor_task_runs_running", map[string]string{"taskID": task.ID.String()})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run running, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mg, "task_executor_task_runs_queued", map[string]string{"taskID": task.ID.String()})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run queued, got %v", got)
+	}
+
+	// a queued run can be canceled before it starts
+	if err := tes.ex.Cancel(ctx, queued.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if got := queued.Error(); got != influxdb.ErrRunCanceled {
+		t.Fatalf("expected queued run to be canceled, got %v", got)
+	}
+
+	tes.svc.SucceedQuery(script)
+	if got := running.Error(); got != nil {
+		t.Fatal(got)
+	}
+}
+
 func testMetrics(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
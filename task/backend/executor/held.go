@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+)
+
+var _ scheduler.HeldRunService = (*Executor)(nil)
+
+// HoldRun creates a run of the task id for scheduledFor that waits for the
+// runs of the tasks it depends on, unless the run is already waiting.
+func (e *Executor) HoldRun(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	iid := influxdb.ID(id)
+	if r, err := e.heldRun(ctx, iid, scheduledFor); err != nil || r != nil {
+		return err
+	}
+
+	r, err := e.tcs.CreateRun(ctx, iid, scheduledFor.UTC(), runAt.UTC())
+	if err != nil {
+		return err
+	}
+	if err := e.tcs.UpdateRunState(ctx, iid, r.ID, time.Now().UTC(), backend.RunWaiting); err != nil {
+		return err
+	}
+	return e.tcs.AddRunLog(ctx, iid, r.ID, time.Now().UTC(), "Waiting for the runs of the tasks this task depends on to succeed")
+}
+
+// HeldRuns returns the scheduled times of the waiting runs of the task id.
+func (e *Executor) HeldRuns(ctx context.Context, id scheduler.ID) ([]time.Time, error) {
+	runs, err := e.tcs.CurrentlyRunning(ctx, influxdb.ID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var held []time.Time
+	for _, r := range runs {
+		if r.Status == backend.RunWaiting.String() {
+			held = append(held, r.ScheduledFor)
+		}
+	}
+	return held, nil
+}
+
+// ReleaseRun executes the waiting run of the task id for scheduledFor, and
+// reports whether there was such a run.
+func (e *Executor) ReleaseRun(ctx context.Context, id scheduler.ID, scheduledFor time.Time) (bool, error) {
+	iid := influxdb.ID(id)
+	r, err := e.heldRun(ctx, iid, scheduledFor)
+	if err != nil || r == nil {
+		return false, err
+	}
+	if _, ok := e.currentPromises.Load(r.ID); ok {
+		return false, nil
+	}
+
+	e.tcs.AddRunLog(ctx, iid, r.ID, time.Now().UTC(), "The runs of the tasks this task depends on succeeded")
+	if _, err := e.createPromise(ctx, r, false); err != nil {
+		return false, err
+	}
+	e.startWorker()
+	return true, nil
+}
+
+// DropRun cancels the waiting run of the task id for scheduledFor, logging reason.
+func (e *Executor) DropRun(ctx context.Context, id scheduler.ID, scheduledFor time.Time, reason string) error {
+	iid := influxdb.ID(id)
+	r, err := e.heldRun(ctx, iid, scheduledFor)
+	if err != nil || r == nil {
+		return err
+	}
+	if _, ok := e.currentPromises.Load(r.ID); ok {
+		// the run was released.
+		return nil
+	}
+
+	e.tcs.AddRunLog(ctx, iid, r.ID, time.Now().UTC(), reason)
+	if err := e.tcs.UpdateRunState(ctx, iid, r.ID, time.Now().UTC(), backend.RunCanceled); err != nil {
+		return err
+	}
+	_, err = e.tcs.FinishRun(ctx, iid, r.ID)
+	return err
+}
+
+// SucceededRuns returns the scheduled times of the succeeded runs of the task
+// id scheduled at or after since, among its most recent runs.
+func (e *Executor) SucceededRuns(ctx context.Context, id scheduler.ID, since time.Time) ([]time.Time, error) {
+	runs, _, err := e.ts.FindRuns(ctx, influxdb.RunFilter{Task: influxdb.ID(id), Limit: influxdb.TaskMaxPageSize})
+	if err != nil {
+		return nil, err
+	}
+
+	var succeeded []time.Time
+	for _, r := range runs {
+		if r.Status == backend.RunSuccess.String() && !r.ScheduledFor.Before(since) {
+			succeeded = append(succeeded, r.ScheduledFor)
+		}
+	}
+	return succeeded, nil
+}
+
+// heldRun returns the waiting run of the task id for scheduledFor, or nil if
+// there is none.
+func (e *Executor) heldRun(ctx context.Context, id influxdb.ID, scheduledFor time.Time) (*influxdb.Run, error) {
+	runs, err := e.tcs.CurrentlyRunning(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range runs {
+		if r.Status == backend.RunWaiting.String() && r.ScheduledFor.Equal(scheduledFor) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/options"
 )
 
@@ -20,11 +21,19 @@ func ConcurrencyLimit(exec *Executor) LimitFunc {
 			return nil
 		}
 
-		runs, err := exec.tcs.CurrentlyRunning(context.Background(), t.ID)
+		current, err := exec.tcs.CurrentlyRunning(context.Background(), t.ID)
 		if err != nil {
 			return err
 		}
 
+		// waiting runs don't take a concurrency slot until they are released.
+		runs := current[:0]
+		for _, run := range current {
+			if run.Status != backend.RunWaiting.String() {
+				runs = append(runs, run)
+			}
+		}
+
 		// sort by scheduledFor time because we want to make sure older scheduled for times
 		// are higher priority
 		sort.SliceStable(runs, func(i, j int) bool {
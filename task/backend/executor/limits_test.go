@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
 )
 
 var (
@@ -55,3 +56,26 @@ func TestTaskConcurrency(t *testing.T) {
 	// TODO(lh): add testing around infinite concurrency once the task options
 	// are not setting a default concurrency to 1.
 }
+
+func TestTaskConcurrency_waitingRuns(t *testing.T) {
+	tes := taskExecutorSystem(t)
+	te := tes.ex
+	ctx := context.Background()
+
+	// a run waiting for the tasks it depends on doesn't take the slot.
+	waiting, err := te.tcs.CreateRun(ctx, taskWith1Concurrency.ID, time.Now().Add(-4*time.Second), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := te.tcs.UpdateRunState(ctx, taskWith1Concurrency.ID, waiting.ID, time.Now(), backend.RunWaiting); err != nil {
+		t.Fatal(err)
+	}
+	r1, err := te.tcs.CreateRun(ctx, taskWith1Concurrency.ID, time.Now().Add(-3*time.Second), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ConcurrencyLimit(te)(taskWith1Concurrency, r1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	"github.com/influxdata/influxdb/notification/endpoint"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// notifyTimeout is how long sending the notification about a run may take.
+const notifyTimeout = 30 * time.Second
+
+// Sender sends messages to notification endpoints.
+type Sender interface {
+	Send(ctx context.Context, e influxdb.NotificationEndpoint, msg endpoint.Message) error
+}
+
+// Notifier sends notifications about the runs of tasks to the notification
+// endpoints of the tasks, as set by the policies of their notifications.
+type Notifier struct {
+	endpoints influxdb.NotificationEndpointService
+	sender    Sender
+}
+
+// NewNotifier returns a Notifier that finds the endpoints of the tasks with
+// endpoints, and sends notifications to them with sender.
+func NewNotifier(endpoints influxdb.NotificationEndpointService, sender Sender) *Notifier {
+	return &Notifier{
+		endpoints: endpoints,
+		sender:    sender,
+	}
+}
+
+// Notify sends the notification of a task about a run that finished with
+// status rs, if the policy of the notification notifies it. failed is the
+// number of runs of the task in a row that failed before the run.
+func (n *Notifier) Notify(ctx context.Context, task *influxdb.Task, run *influxdb.Run, rs backend.RunStatus, failed int, runErr error) error {
+	if task.Notification == nil || !task.Notification.Notifies(rs.String(), failed) {
+		return nil
+	}
+
+	e, err := n.endpoints.FindNotificationEndpointByID(ctx, task.Notification.EndpointID)
+	if err != nil {
+		return err
+	}
+	return n.sender.Send(ctx, e, notificationMessage(task, run, rs, failed, runErr))
+}
+
+// notificationMessage returns the message that notifies a run of a task.
+func notificationMessage(task *influxdb.Task, run *influxdb.Run, rs backend.RunStatus, failed int, runErr error) endpoint.Message {
+	msg := endpoint.Message{
+		Level:    notification.Critical,
+		Source:   task.Name,
+		DedupKey: task.ID.String(),
+		Time:     time.Now().UTC(),
+	}
+
+	if rs == backend.RunSuccess {
+		msg.Level = notification.Ok
+		msg.Text = fmt.Sprintf("Task %q (%s) recovered: the run scheduled for %s succeeded after %d failed runs.",
+			task.Name, task.ID, run.ScheduledFor.Format(time.RFC3339), failed)
+		return msg
+	}
+
+	reason := "unknown error"
+	if runErr != nil {
+		reason = runErr.Error()
+	}
+	if failed > 0 {
+		msg.Text = fmt.Sprintf("Task %q (%s) failed %d runs in a row, the run scheduled for %s failed: %s",
+			task.Name, task.ID, failed+1, run.ScheduledFor.Format(time.RFC3339), reason)
+		return msg
+	}
+	msg.Text = fmt.Sprintf("Task %q (%s) failed, the run scheduled for %s failed: %s",
+		task.Name, task.ID, run.ScheduledFor.Format(time.RFC3339), reason)
+	return msg
+}
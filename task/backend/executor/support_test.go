@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	fluxast "github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/memory"
@@ -160,6 +161,31 @@ func (s *fakeQueryService) WaitForQueryLive(t *testing.T, script string) {
 	t.Fatalf("Did not see live query %q in time", script)
 }
 
+// WaitForQueryLiveAt ensures that the query of script executed at now, with
+// the location option declared as location, has made it into the service.
+func (s *fakeQueryService) WaitForQueryLiveAt(t *testing.T, script, location string, now time.Time) {
+	t.Helper()
+
+	ast := makeAST(script)
+	ast.AST.Files = append([]*fluxast.File{locationFile(location)}, ast.AST.Files...)
+	ast.Now = now.UTC()
+	spec := makeASTString(ast)
+	for i := 0; i < 10; i++ {
+		if i != 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		s.mu.Lock()
+		_, ok := s.queries[spec]
+		s.mu.Unlock()
+		if ok {
+			return
+		}
+	}
+
+	t.Fatalf("Did not see live query %q in %s at %s in time", script, location, now)
+}
+
 type fakeQuery struct {
 	results     chan flux.Result
 	wait        chan struct{} // Blocks Ready from returning.
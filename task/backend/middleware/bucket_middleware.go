@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// downsamplingCoordinator publishes the changes that writes to buckets and
+// measurement schemas make to the managed tasks of downsampling policies.
+// Those tasks are changed in the same transaction as their buckets, so their
+// changes are found by comparing the tasks before and after the write.
+type downsamplingCoordinator struct {
+	buckets     influxdb.BucketService
+	tasks       influxdb.TaskService
+	coordinator Coordinator
+	now         func() time.Time
+}
+
+// downsamplingTasks returns the tasks of the downsampling policies of the
+// buckets of an organization, by ID. Writes to a bucket can change the
+// policies of the other buckets of its organization that write to it.
+func (d *downsamplingCoordinator) downsamplingTasks(ctx context.Context, orgID influxdb.ID) (map[influxdb.ID]*influxdb.Task, error) {
+	buckets, _, err := d.buckets.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[influxdb.ID]*influxdb.Task)
+	for _, b := range buckets {
+		for _, p := range b.DownsamplingPolicies {
+			if !p.TaskID.Valid() {
+				continue
+			}
+			t, err := d.tasks.FindTaskByID(ctx, p.TaskID)
+			if err != nil {
+				if influxdb.ErrorCode(err) == influxdb.ENotFound {
+					continue
+				}
+				return nil, err
+			}
+			tasks[t.ID] = t
+		}
+	}
+	return tasks, nil
+}
+
+// publish tells the coordinator about the tasks that were created, updated
+// or deleted between from and to.
+func (d *downsamplingCoordinator) publish(ctx context.Context, from, to map[influxdb.ID]*influxdb.Task) error {
+	for id, toTask := range to {
+		fromTask, ok := from[id]
+		if !ok {
+			if err := d.coordinator.TaskCreated(ctx, toTask); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// if the update is to activate and the previous task was inactive we should add a "latest completed" update
+		// this allows us to see not run the task for inactive time
+		if fromTask.Status == string(backend.TaskInactive) && toTask.Status == string(backend.TaskActive) {
+			toTask.LatestCompleted = d.now()
+		}
+		if err := d.coordinator.TaskUpdated(ctx, fromTask, toTask); err != nil {
+			return err
+		}
+	}
+
+	for id := range from {
+		if _, ok := to[id]; ok {
+			continue
+		}
+		if err := d.coordinator.TaskDeleted(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sync runs fn, and publishes the changes it made to the downsampling tasks of
+// an organization.
+func (d *downsamplingCoordinator) sync(ctx context.Context, orgID influxdb.ID, fn func() error) error {
+	from, err := d.downsamplingTasks(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	to, err := d.downsamplingTasks(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	return d.publish(ctx, from, to)
+}
+
+// CoordinatingBucketService acts as a BucketService decorator that publishes
+// the changes to the tasks of the downsampling policies of buckets, so that
+// they can be scheduled.
+type CoordinatingBucketService struct {
+	influxdb.BucketService
+	downsampling *downsamplingCoordinator
+}
+
+// NewBucketService constructs a new coordinating bucket service
+func NewBucketService(bs influxdb.BucketService, ts influxdb.TaskService, coordinator Coordinator) *CoordinatingBucketService {
+	return &CoordinatingBucketService{
+		BucketService: bs,
+		downsampling: &downsamplingCoordinator{
+			buckets:     bs,
+			tasks:       ts,
+			coordinator: coordinator,
+			now: func() time.Time {
+				return time.Now().UTC()
+			},
+		},
+	}
+}
+
+// CreateBucket creates a bucket and publishes the tasks of its downsampling policies so they can be scheduled.
+func (bs *CoordinatingBucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	return bs.downsampling.sync(ctx, b.OrgID, func() error {
+		return bs.BucketService.CreateBucket(ctx, b)
+	})
+}
+
+// UpdateBucket updates a bucket and publishes the changes to the tasks of its downsampling policies.
+func (bs *CoordinatingBucketService) UpdateBucket(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+	from, err := bs.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var b *influxdb.Bucket
+	err = bs.downsampling.sync(ctx, from.OrgID, func() error {
+		var err error
+		b, err = bs.BucketService.UpdateBucket(ctx, id, upd)
+		return err
+	})
+	return b, err
+}
+
+// DeleteBucket deletes a bucket and publishes the deletion of the tasks of the downsampling policies
+// of the bucket, and of the policies of other buckets that wrote to it.
+func (bs *CoordinatingBucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	b, err := bs.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return bs.downsampling.sync(ctx, b.OrgID, func() error {
+		return bs.BucketService.DeleteBucket(ctx, id)
+	})
+}
+
+// CoordinatingMeasurementSchemaService acts as a MeasurementSchemaService decorator that
+// publishes the changes to the tasks of the downsampling policies of buckets, which are
+// compiled again when the fields of their schemas change.
+type CoordinatingMeasurementSchemaService struct {
+	influxdb.MeasurementSchemaService
+	downsampling *downsamplingCoordinator
+}
+
+// NewMeasurementSchemaService constructs a new coordinating measurement schema service
+func NewMeasurementSchemaService(ms influxdb.MeasurementSchemaService, bs influxdb.BucketService, ts influxdb.TaskService, coordinator Coordinator) *CoordinatingMeasurementSchemaService {
+	return &CoordinatingMeasurementSchemaService{
+		MeasurementSchemaService: ms,
+		downsampling: &downsamplingCoordinator{
+			buckets:     bs,
+			tasks:       ts,
+			coordinator: coordinator,
+			now: func() time.Time {
+				return time.Now().UTC()
+			},
+		},
+	}
+}
+
+// CreateMeasurementSchema creates a measurement schema and publishes the changes to the downsampling tasks of its bucket.
+func (ss *CoordinatingMeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	return ss.downsampling.sync(ctx, ms.OrgID, func() error {
+		return ss.MeasurementSchemaService.CreateMeasurementSchema(ctx, ms)
+	})
+}
+
+// UpdateMeasurementSchema updates a measurement schema and publishes the changes to the downsampling tasks of its bucket.
+func (ss *CoordinatingMeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	from, err := ss.MeasurementSchemaService.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms *influxdb.MeasurementSchema
+	err = ss.downsampling.sync(ctx, from.OrgID, func() error {
+		var err error
+		ms, err = ss.MeasurementSchemaService.UpdateMeasurementSchema(ctx, id, upd)
+		return err
+	})
+	return ms, err
+}
+
+// DeleteMeasurementSchema deletes a measurement schema and publishes the changes to the downsampling tasks of its bucket.
+func (ss *CoordinatingMeasurementSchemaService) DeleteMeasurementSchema(ctx context.Context, id influxdb.ID) error {
+	from, err := ss.MeasurementSchemaService.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ss.downsampling.sync(ctx, from.OrgID, func() error {
+		return ss.MeasurementSchemaService.DeleteMeasurementSchema(ctx, id)
+	})
+}
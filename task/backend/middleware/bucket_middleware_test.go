@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/task/backend/middleware"
+	"go.uber.org/zap/zaptest"
+)
+
+// recordingCoordinator records the tasks it is told about.
+type recordingCoordinator struct {
+	pipingCoordinator
+	created, updated, deleted []influxdb.ID
+}
+
+func (r *recordingCoordinator) TaskCreated(_ context.Context, t *influxdb.Task) error {
+	r.created = append(r.created, t.ID)
+	return nil
+}
+func (r *recordingCoordinator) TaskUpdated(_ context.Context, from, to *influxdb.Task) error {
+	r.updated = append(r.updated, to.ID)
+	return nil
+}
+func (r *recordingCoordinator) TaskDeleted(_ context.Context, id influxdb.ID) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *recordingCoordinator) reset() {
+	r.created, r.updated, r.deleted = nil, nil, nil
+}
+
+func TestBucketDownsamplingTasks(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID, OrgID: org.ID})
+
+	coord := &recordingCoordinator{}
+	buckets := middleware.NewBucketService(svc, svc, coord)
+
+	rollup := &influxdb.Bucket{OrgID: org.ID, Name: "rollup"}
+	if err := buckets.CreateBucket(ctx, rollup); err != nil {
+		t.Fatal(err)
+	}
+	if len(coord.created) != 0 {
+		t.Fatalf("expected no tasks for a bucket without policies, got %v", coord.created)
+	}
+
+	policy := influxdb.DownsamplingPolicy{
+		DestinationBucketID: rollup.ID,
+		Every:               influxdb.Duration{Duration: time.Hour},
+		Aggregates:          map[influxdb.SchemaColumnDataType]string{influxdb.SchemaColumnDataTypeFloat: "mean"},
+	}
+	raw := &influxdb.Bucket{OrgID: org.ID, Name: "raw", DownsamplingPolicies: []influxdb.DownsamplingPolicy{policy}}
+	if err := buckets.CreateBucket(ctx, raw); err != nil {
+		t.Fatal(err)
+	}
+	taskID := raw.DownsamplingPolicies[0].TaskID
+	if len(coord.created) != 1 || coord.created[0] != taskID {
+		t.Fatalf("expected the task of the policy %s to be created, got %v", taskID, coord.created)
+	}
+
+	coord.reset()
+	policy.Every = influxdb.Duration{Duration: time.Minute}
+	if _, err := buckets.UpdateBucket(ctx, raw.ID, influxdb.BucketUpdate{DownsamplingPolicies: &[]influxdb.DownsamplingPolicy{policy}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(coord.updated) != 1 || coord.updated[0] != taskID || len(coord.created) != 0 || len(coord.deleted) != 0 {
+		t.Fatalf("expected the task of the policy %s to be updated, got created %v, updated %v, deleted %v", taskID, coord.created, coord.updated, coord.deleted)
+	}
+
+	// Deleting the destination of a policy deletes the policy and its task.
+	coord.reset()
+	if err := buckets.DeleteBucket(ctx, rollup.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(coord.deleted) != 1 || coord.deleted[0] != taskID {
+		t.Fatalf("expected the task of the policy %s to be deleted, got %v", taskID, coord.deleted)
+	}
+}
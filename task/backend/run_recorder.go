@@ -61,6 +61,16 @@ func (s *StoragePointsWriterRecorder) Record(ctx context.Context, orgID influxdb
 	}
 	fields[logField] = string(logBytes)
 
+	if run.Statistics != nil {
+		statsBytes, err := json.Marshal(run.Statistics)
+		if err != nil {
+			return err
+		}
+		fields[statisticsField] = string(statsBytes)
+		// rowsWritten is a field of its own so that runs can be filtered by it.
+		fields[rowsWrittenField] = run.Statistics.RowsWritten
+	}
+
 	point, err := models.NewPoint("runs", tags, fields, startedAt)
 	if err != nil {
 		return err
@@ -0,0 +1,302 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDependencyRetention is the default duration for which a
+// DependencyExecutor remembers the succeeded runs of upstream schedulables,
+// and holds back the runs of their downstream schedulables.
+const DefaultDependencyRetention = 24 * time.Hour
+
+// DependencyExecutor is an Executor that holds back the runs of schedulables
+// that depend on other, upstream, schedulables. A run of a downstream
+// schedulable is executed once the runs of all of its upstream schedulables
+// for the same scheduled time have succeeded.
+//
+// Held back runs are persisted by a HeldRunService and are not checkpointed
+// until they are released, so they survive a restart. The succeeded runs of
+// upstream schedulables are read back from the HeldRunService when the
+// DependencyExecutor is opened. Runs that are held back
+// longer than the retention, because an upstream run failed or never ran, are
+// dropped with the reason in their log.
+type DependencyExecutor struct {
+	executor     Executor
+	held         HeldRunService
+	checkpointer SchedulableService
+	onErr        ErrorFunc
+	retention    time.Duration
+	now          func() time.Time
+
+	mu sync.Mutex
+	// upstream is the schedulables that each schedulable depends on.
+	upstream map[ID][]ID
+	// succeeded is the scheduled times of the succeeded runs of each upstream schedulable.
+	succeeded map[ID]map[int64]struct{}
+}
+
+var _ Executor = (*DependencyExecutor)(nil)
+
+// NewDependencyExecutor returns a DependencyExecutor that executes the runs
+// whose dependencies are satisfied with executor, and holds back the others
+// with held. Released runs are checkpointed with checkpointer, and their
+// errors are passed to onErr.
+func NewDependencyExecutor(executor Executor, held HeldRunService, checkpointer SchedulableService, onErr ErrorFunc) *DependencyExecutor {
+	if onErr == nil {
+		onErr = func(_ context.Context, _ ID, _ time.Time, _ error) {}
+	}
+	return &DependencyExecutor{
+		executor:     executor,
+		held:         held,
+		checkpointer: checkpointer,
+		onErr:        onErr,
+		retention:    DefaultDependencyRetention,
+		now:          time.Now,
+		upstream:     map[ID][]ID{},
+		succeeded:    map[ID]map[int64]struct{}{},
+	}
+}
+
+// Open rebuilds the succeeded runs of the upstream schedulables within the
+// retention, which are lost when the process restarts, from the runs
+// persisted by the HeldRunService. It then executes the held back runs whose
+// dependencies succeeded before the restart. The dependencies of the
+// schedulables must be set before it is called.
+func (d *DependencyExecutor) Open(ctx context.Context) error {
+	d.mu.Lock()
+	upstream := map[ID]struct{}{}
+	downstream := make([]ID, 0, len(d.upstream))
+	for down, ups := range d.upstream {
+		downstream = append(downstream, down)
+		for _, up := range ups {
+			upstream[up] = struct{}{}
+		}
+	}
+	d.mu.Unlock()
+
+	since := d.now().Add(-d.retention)
+	for up := range upstream {
+		sfs, err := d.held.SucceededRuns(ctx, up, since)
+		if err != nil {
+			return err
+		}
+
+		d.mu.Lock()
+		runs, ok := d.succeeded[up]
+		if !ok {
+			runs = map[int64]struct{}{}
+			d.succeeded[up] = runs
+		}
+		for _, sf := range sfs {
+			runs[sf.Unix()] = struct{}{}
+		}
+		d.mu.Unlock()
+	}
+
+	for _, down := range downstream {
+		held, err := d.held.HeldRuns(ctx, down)
+		if err != nil {
+			return err
+		}
+		for _, sf := range held {
+			d.mu.Lock()
+			ok := d.satisfied(down, sf)
+			d.mu.Unlock()
+			if ok {
+				d.release(ctx, down, sf)
+			}
+		}
+	}
+	return nil
+}
+
+// SetDependencies sets the upstream schedulables of id. A schedulable
+// without upstream schedulables is executed as soon as it is scheduled.
+func (d *DependencyExecutor) SetDependencies(id ID, upstream []ID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(upstream) == 0 {
+		delete(d.upstream, id)
+		return
+	}
+	d.upstream[id] = append([]ID(nil), upstream...)
+}
+
+// Release forgets the dependencies of id, and removes id from the upstream
+// schedulables of the schedulables that depend on it.
+func (d *DependencyExecutor) Release(id ID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.upstream, id)
+	delete(d.succeeded, id)
+	for down, ups := range d.upstream {
+		if !contains(ups, id) {
+			continue
+		}
+		remaining := make([]ID, 0, len(ups)-1)
+		for _, up := range ups {
+			if up != id {
+				remaining = append(remaining, up)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(d.upstream, down)
+			continue
+		}
+		d.upstream[down] = remaining
+	}
+}
+
+// Execute executes the run of id if the runs of all of its upstream
+// schedulables for scheduledFor have succeeded. Otherwise it holds the run
+// back and returns ErrSkipCheckpoint.
+func (d *DependencyExecutor) Execute(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error {
+	d.mu.Lock()
+	_, dependent := d.upstream[id]
+	if d.satisfied(id, scheduledFor) {
+		d.mu.Unlock()
+		if dependent {
+			d.expire(ctx, id, scheduledFor)
+		}
+		return d.executor.Execute(ctx, id, scheduledFor, runAt)
+	}
+
+	// the run is held while d.mu is held, so that a run of an upstream
+	// schedulable succeeding meanwhile finds it to release.
+	err := d.held.HoldRun(ctx, id, scheduledFor, runAt)
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	d.expire(ctx, id, scheduledFor)
+	return ErrSkipCheckpoint
+}
+
+// Succeeded records that the run of id for scheduledFor has succeeded, and
+// executes the held back runs of its downstream schedulables for scheduledFor
+// whose dependencies are now all satisfied.
+func (d *DependencyExecutor) Succeeded(id ID, scheduledFor time.Time) {
+	d.mu.Lock()
+	var downstream []ID
+	for down, ups := range d.upstream {
+		if contains(ups, id) {
+			downstream = append(downstream, down)
+		}
+	}
+	if len(downstream) == 0 {
+		// nothing depends on id.
+		d.mu.Unlock()
+		return
+	}
+
+	runs, ok := d.succeeded[id]
+	if !ok {
+		runs = map[int64]struct{}{}
+		d.succeeded[id] = runs
+	}
+	runs[scheduledFor.Unix()] = struct{}{}
+	d.prune(scheduledFor.Add(-d.retention))
+
+	var ready []ID
+	for _, down := range downstream {
+		if d.satisfied(down, scheduledFor) {
+			ready = append(ready, down)
+		}
+	}
+	d.mu.Unlock()
+
+	// like the TreeScheduler, runs are executed without the context of the caller.
+	ctx := context.Background()
+	for _, down := range ready {
+		d.release(ctx, down, scheduledFor)
+	}
+}
+
+// release executes the held back run of id for scheduledFor, if there is
+// one, and checkpoints it.
+func (d *DependencyExecutor) release(ctx context.Context, id ID, scheduledFor time.Time) {
+	released, err := d.held.ReleaseRun(ctx, id, scheduledFor)
+	if err != nil {
+		d.onErr(ctx, id, scheduledFor, err)
+		return
+	}
+	if !released {
+		// the run isn't scheduled yet, or was already released.
+		return
+	}
+	if err := d.checkpointer.UpdateLastScheduled(ctx, id, scheduledFor); err != nil {
+		d.onErr(ctx, id, scheduledFor, err)
+	}
+}
+
+// expire drops the held back runs of id scheduled longer than the retention
+// before scheduledFor.
+func (d *DependencyExecutor) expire(ctx context.Context, id ID, scheduledFor time.Time) {
+	held, err := d.held.HeldRuns(ctx, id)
+	if err != nil {
+		d.onErr(ctx, id, scheduledFor, err)
+		return
+	}
+
+	var last time.Time
+	before := scheduledFor.Add(-d.retention)
+	reason := fmt.Sprintf("Dropped run, the runs it depends on did not succeed within %s", d.retention)
+	for _, sf := range held {
+		if !sf.Before(before) {
+			continue
+		}
+		if err := d.held.DropRun(ctx, id, sf, reason); err != nil {
+			d.onErr(ctx, id, sf, err)
+			return
+		}
+		if sf.After(last) {
+			last = sf
+		}
+	}
+	if last.IsZero() {
+		return
+	}
+	// the runs scheduled before the dropped runs were executed or are held,
+	// so none of them is lost by checkpointing the dropped runs.
+	if err := d.checkpointer.UpdateLastScheduled(ctx, id, last); err != nil {
+		d.onErr(ctx, id, last, err)
+	}
+}
+
+// satisfied reports whether the runs of all of the upstream schedulables of
+// id for scheduledFor have succeeded. d.mu must be held.
+func (d *DependencyExecutor) satisfied(id ID, scheduledFor time.Time) bool {
+	for _, up := range d.upstream[id] {
+		if _, ok := d.succeeded[up][scheduledFor.Unix()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// prune drops the succeeded runs scheduled before t. d.mu must be held.
+func (d *DependencyExecutor) prune(t time.Time) {
+	before := t.Unix()
+	for _, runs := range d.succeeded {
+		for sf := range runs {
+			if sf < before {
+				delete(runs, sf)
+			}
+		}
+	}
+}
+
+func contains(ids []ID, id ID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
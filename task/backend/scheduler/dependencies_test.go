@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type executedRun struct {
+	id           ID
+	scheduledFor time.Time
+}
+
+// mockHeldRunService holds runs in memory, and executes them with executor
+// when they are released.
+type mockHeldRunService struct {
+	executor Executor
+
+	mu        sync.Mutex
+	held      map[ID]map[int64]time.Time
+	dropped   []executedRun
+	succeeded map[ID][]time.Time
+}
+
+func (m *mockHeldRunService) HoldRun(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.held[id] == nil {
+		m.held[id] = map[int64]time.Time{}
+	}
+	m.held[id][scheduledFor.Unix()] = runAt
+	return nil
+}
+
+func (m *mockHeldRunService) HeldRuns(ctx context.Context, id ID) ([]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var held []time.Time
+	for sf := range m.held[id] {
+		held = append(held, time.Unix(sf, 0).UTC())
+	}
+	return held, nil
+}
+
+func (m *mockHeldRunService) ReleaseRun(ctx context.Context, id ID, scheduledFor time.Time) (bool, error) {
+	m.mu.Lock()
+	runAt, ok := m.held[id][scheduledFor.Unix()]
+	delete(m.held[id], scheduledFor.Unix())
+	m.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, m.executor.Execute(ctx, id, scheduledFor, runAt)
+}
+
+func (m *mockHeldRunService) DropRun(ctx context.Context, id ID, scheduledFor time.Time, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.held[id], scheduledFor.Unix())
+	m.dropped = append(m.dropped, executedRun{id: id, scheduledFor: scheduledFor})
+	return nil
+}
+
+func (m *mockHeldRunService) SucceededRuns(ctx context.Context, id ID, since time.Time) ([]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var succeeded []time.Time
+	for _, sf := range m.succeeded[id] {
+		if !sf.Before(since) {
+			succeeded = append(succeeded, sf)
+		}
+	}
+	return succeeded, nil
+}
+
+type checkpoints map[ID]time.Time
+
+func (c checkpoints) UpdateLastScheduled(ctx context.Context, id ID, t time.Time) error {
+	if t.After(c[id]) {
+		c[id] = t
+	}
+	return nil
+}
+
+func newTestDependencyExecutor() (*DependencyExecutor, *mockHeldRunService, checkpoints, func() []executedRun) {
+	var (
+		mu   sync.Mutex
+		runs []executedRun
+	)
+	ex := &mockExecutor{fn: func(_ *sync.Mutex, _ context.Context, id ID, scheduledFor time.Time) {
+		mu.Lock()
+		runs = append(runs, executedRun{id: id, scheduledFor: scheduledFor})
+		mu.Unlock()
+	}}
+	executed := func() []executedRun {
+		mu.Lock()
+		defer mu.Unlock()
+		res := runs
+		runs = nil
+		return res
+	}
+	held := &mockHeldRunService{executor: ex, held: map[ID]map[int64]time.Time{}, succeeded: map[ID][]time.Time{}}
+	cp := checkpoints{}
+	return NewDependencyExecutor(ex, held, cp, nil), held, cp, executed
+}
+
+func TestDependencyExecutor(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("without dependencies", func(t *testing.T) {
+		d, _, _, executed := newTestDependencyExecutor()
+		if err := d.Execute(ctx, 1, t0, t0); err != nil {
+			t.Fatal(err)
+		}
+		if runs := executed(); len(runs) != 1 || runs[0].id != 1 {
+			t.Fatalf("expected run of 1 to execute, got %v", runs)
+		}
+	})
+
+	t.Run("held until all upstream runs succeed", func(t *testing.T) {
+		d, held, cp, executed := newTestDependencyExecutor()
+		d.SetDependencies(3, []ID{1, 2})
+
+		if err := d.Execute(ctx, 3, t0, t0); err != ErrSkipCheckpoint {
+			t.Fatalf("expected held run to skip its checkpoint, got %v", err)
+		}
+		if runs := executed(); len(runs) != 0 {
+			t.Fatalf("expected run of 3 to be held, got %v", runs)
+		}
+		if sfs, _ := held.HeldRuns(ctx, 3); len(sfs) != 1 || !sfs[0].Equal(t0) {
+			t.Fatalf("expected run of 3 for %s to be held, got %v", t0, sfs)
+		}
+
+		d.Succeeded(1, t0)
+		// a run for another scheduled time doesn't satisfy the dependency.
+		d.Succeeded(2, t0.Add(-time.Hour))
+		if runs := executed(); len(runs) != 0 {
+			t.Fatalf("expected run of 3 to be held, got %v", runs)
+		}
+		if _, ok := cp[3]; ok {
+			t.Fatalf("expected held run of 3 not to be checkpointed, got %s", cp[3])
+		}
+
+		d.Succeeded(2, t0)
+		runs := executed()
+		if len(runs) != 1 || runs[0].id != 3 || !runs[0].scheduledFor.Equal(t0) {
+			t.Fatalf("expected run of 3 for %s to execute, got %v", t0, runs)
+		}
+		if !cp[3].Equal(t0) {
+			t.Fatalf("expected released run of 3 to be checkpointed at %s, got %s", t0, cp[3])
+		}
+
+		// the run is only triggered once.
+		d.Succeeded(1, t0)
+		if runs := executed(); len(runs) != 0 {
+			t.Fatalf("expected no more runs, got %v", runs)
+		}
+	})
+
+	t.Run("upstream succeeded first", func(t *testing.T) {
+		d, _, _, executed := newTestDependencyExecutor()
+		d.SetDependencies(2, []ID{1})
+
+		d.Succeeded(1, t0)
+		if err := d.Execute(ctx, 2, t0, t0.Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if runs := executed(); len(runs) != 1 || runs[0].id != 2 {
+			t.Fatalf("expected run of 2 to execute, got %v", runs)
+		}
+	})
+
+	t.Run("released upstream", func(t *testing.T) {
+		d, _, _, executed := newTestDependencyExecutor()
+		d.SetDependencies(2, []ID{1})
+		d.Release(1)
+
+		if err := d.Execute(ctx, 2, t0, t0); err != nil {
+			t.Fatal(err)
+		}
+		if runs := executed(); len(runs) != 1 || runs[0].id != 2 {
+			t.Fatalf("expected run of 2 to execute, got %v", runs)
+		}
+	})
+
+	t.Run("held runs expire", func(t *testing.T) {
+		d, held, cp, executed := newTestDependencyExecutor()
+		d.SetDependencies(2, []ID{1})
+
+		if err := d.Execute(ctx, 2, t0, t0); err != ErrSkipCheckpoint {
+			t.Fatalf("expected held run to skip its checkpoint, got %v", err)
+		}
+		later := t0.Add(DefaultDependencyRetention + time.Hour)
+		if err := d.Execute(ctx, 2, later, later); err != ErrSkipCheckpoint {
+			t.Fatalf("expected held run to skip its checkpoint, got %v", err)
+		}
+		if len(held.dropped) != 1 || !held.dropped[0].scheduledFor.Equal(t0) {
+			t.Fatalf("expected the run of 2 for %s to be dropped, got %v", t0, held.dropped)
+		}
+		if !cp[2].Equal(t0) {
+			t.Fatalf("expected dropped run of 2 to be checkpointed at %s, got %s", t0, cp[2])
+		}
+
+		d.Succeeded(1, t0)
+		if runs := executed(); len(runs) != 0 {
+			t.Fatalf("expected the expired run of 2 to be dropped, got %v", runs)
+		}
+		if sfs, _ := held.HeldRuns(ctx, 2); len(sfs) != 1 || !sfs[0].Equal(later) {
+			t.Fatalf("expected run of 2 for %s to be held, got %v", later, sfs)
+		}
+	})
+
+	t.Run("succeeded runs are rebuilt on open", func(t *testing.T) {
+		d, held, cp, executed := newTestDependencyExecutor()
+		d.now = func() time.Time { return t0.Add(time.Hour) }
+		d.SetDependencies(3, []ID{1, 2})
+
+		// the runs of 1 and 2 for t0 succeeded before a restart, and the run
+		// of 3 for t0 was held back before 2 succeeded.
+		if err := held.HoldRun(ctx, 3, t0, t0); err != nil {
+			t.Fatal(err)
+		}
+		old := t0.Add(-DefaultDependencyRetention - time.Hour)
+		held.succeeded[1] = []time.Time{old, t0, t0.Add(time.Minute)}
+		held.succeeded[2] = []time.Time{old, t0}
+
+		if err := d.Open(ctx); err != nil {
+			t.Fatal(err)
+		}
+		runs := executed()
+		if len(runs) != 1 || runs[0].id != 3 || !runs[0].scheduledFor.Equal(t0) {
+			t.Fatalf("expected held run of 3 for %s to execute, got %v", t0, runs)
+		}
+		if !cp[3].Equal(t0) {
+			t.Fatalf("expected released run of 3 to be checkpointed at %s, got %s", t0, cp[3])
+		}
+
+		// a run scheduled after the restart finds the runs of its upstream
+		// schedulables that succeeded before it.
+		next := t0.Add(time.Minute)
+		if err := d.Execute(ctx, 3, next, next); err != ErrSkipCheckpoint {
+			t.Fatalf("expected run of 3 for %s to be held for 2, got %v", next, err)
+		}
+		d.Succeeded(2, next)
+		if runs := executed(); len(runs) != 1 || !runs[0].scheduledFor.Equal(next) {
+			t.Fatalf("expected run of 3 for %s to execute, got %v", next, runs)
+		}
+
+		// runs older than the retention are not rebuilt.
+		if err := d.Execute(ctx, 3, old, old); err != ErrSkipCheckpoint {
+			t.Fatalf("expected run of 3 for %s to be held, got %v", old, err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -24,6 +25,33 @@ type Executor interface {
 	Execute(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error
 }
 
+// ErrSkipCheckpoint is returned by an Executor for a run that it did not
+// execute, and that must be scheduled again if the scheduler restarts before
+// the run is executed. The scheduler does not checkpoint such runs.
+var ErrSkipCheckpoint = errors.New("run was not executed, skipping checkpoint")
+
+// HeldRunService persists the runs that an Executor holds back until they can
+// be executed, so that they survive a restart and are visible to users.
+type HeldRunService interface {
+	// HoldRun records the run of id for scheduledFor as waiting.
+	// Holding a run that is already waiting does nothing.
+	HoldRun(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error
+
+	// HeldRuns returns the scheduled times of the waiting runs of id.
+	HeldRuns(ctx context.Context, id ID) ([]time.Time, error)
+
+	// ReleaseRun executes the waiting run of id for scheduledFor, and reports
+	// whether there was such a run.
+	ReleaseRun(ctx context.Context, id ID, scheduledFor time.Time) (bool, error)
+
+	// DropRun cancels the waiting run of id for scheduledFor, logging reason.
+	DropRun(ctx context.Context, id ID, scheduledFor time.Time, reason string) error
+
+	// SucceededRuns returns the scheduled times of the succeeded runs of id
+	// scheduled at or after since.
+	SucceededRuns(ctx context.Context, id ID, since time.Time) ([]time.Time, error)
+}
+
 // Schedulable is the interface that encapsulates work that
 // is to be executed on a specified schedule.
 type Schedulable interface {
@@ -52,7 +80,17 @@ type SchedulableService interface {
 	UpdateLastScheduled(ctx context.Context, id ID, t time.Time) error
 }
 
+// NewSchedule parses unparsed as a schedule evaluated in UTC, and returns it
+// along with lastScheduledAt aligned to the schedule.
 func NewSchedule(unparsed string, lastScheduledAt time.Time) (Schedule, time.Time, error) {
+	return NewScheduleInLocation(unparsed, "", lastScheduledAt)
+}
+
+// NewScheduleInLocation parses unparsed as a schedule whose cron expression is
+// evaluated against the wall clock of the IANA time zone location.
+// An empty location evaluates the schedule in UTC.
+// Schedules using "@every" are fixed periods, so they are not affected by location.
+func NewScheduleInLocation(unparsed, location string, lastScheduledAt time.Time) (Schedule, time.Time, error) {
 	lastScheduledAt = lastScheduledAt.UTC().Truncate(time.Second)
 	c, err := cron.ParseUTC(unparsed)
 	if err != nil {
@@ -68,31 +106,81 @@ func NewSchedule(unparsed string, lastScheduledAt time.Time) (Schedule, time.Tim
 		err := every.Parse(everyString)
 		if err != nil {
 			// We cannot align a invalid time
-			return Schedule{c}, lastScheduledAt, nil
+			return Schedule{cron: c}, lastScheduledAt, nil
 		}
 
 		// drop nanoseconds
 		lastScheduledAt = time.Unix(lastScheduledAt.UTC().Unix(), 0).UTC()
 		everyDur, err := every.DurationFrom(lastScheduledAt)
 		if err != nil {
-			return Schedule{c}, lastScheduledAt, nil
+			return Schedule{cron: c}, lastScheduledAt, nil
 		}
 
 		// and align
 		lastScheduledAt = lastScheduledAt.Truncate(everyDur).Truncate(time.Second)
+		return Schedule{cron: c}, lastScheduledAt, nil
+	}
+
+	if location == "" {
+		return Schedule{cron: c}, lastScheduledAt, nil
 	}
 
-	return Schedule{c}, lastScheduledAt, err
+	loc, err := time.LoadLocation(location)
+	if err != nil {
+		return Schedule{}, lastScheduledAt, err
+	}
+	return Schedule{cron: c, loc: loc}, lastScheduledAt, nil
 }
 
 // Schedule is an object a valid schedule of runs
 type Schedule struct {
 	cron cron.Parsed
+	// loc is the location whose wall clock the cron expression is evaluated
+	// against, nil is UTC.
+	loc *time.Location
 }
 
 // Next returns the next time after from that a schedule should trigger on.
+//
+// When the schedule has a location, wall clock times skipped by a daylight
+// saving transition trigger at the same wall clock time in the new offset
+// (i.e. 02:30 on a spring forward day triggers at 03:30), and wall clock times
+// repeated by a transition only trigger on their first occurrence.
 func (s Schedule) Next(from time.Time) (time.Time, error) {
-	return cron.Parsed(s.cron).Next(from)
+	if s.loc == nil {
+		return cron.Parsed(s.cron).Next(from)
+	}
+
+	wall := wallClock(from.In(s.loc))
+	for {
+		next, err := cron.Parsed(s.cron).Next(wall)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t := inLocation(next, s.loc); t.After(from) {
+			return t.UTC(), nil
+		}
+		wall = next
+	}
+}
+
+// wallClock returns the wall clock of t as a UTC time, so that cron
+// expressions can be evaluated against it without a time zone.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// inLocation returns the time of the UTC wall clock w in loc. Wall clock times
+// that don't exist in loc, because a transition skipped over them, are
+// shifted forward by the length of the transition.
+func inLocation(w time.Time, loc *time.Location) time.Time {
+	t := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), loc)
+	if wallClock(t).Equal(w) {
+		return t
+	}
+	// apply the offset in effect before the transition.
+	_, offset := t.Add(-24 * time.Hour).Zone()
+	return w.Add(-time.Duration(offset) * time.Second).In(loc)
 }
 
 // ValidSchedule returns an error if the cron string is invalid.
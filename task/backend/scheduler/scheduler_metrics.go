@@ -112,7 +112,7 @@ func (em *SchedulerMetrics) reportScheduleDelay(d time.Duration) {
 func (em *SchedulerMetrics) reportExecution(err error, d time.Duration) {
 	em.totalExecuteCalls.Inc()
 	em.executeDelta.Observe(d.Seconds())
-	if err != nil {
+	if err != nil && err != ErrSkipCheckpoint {
 		em.totalExecuteFailure.Inc()
 	}
 }
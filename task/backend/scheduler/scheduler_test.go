@@ -476,6 +476,68 @@ func TestSchedule_panic(t *testing.T) {
 	}
 }
 
+type executorFunc func(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error
+
+func (f executorFunc) Execute(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error {
+	return f(ctx, id, scheduledFor, runAt)
+}
+
+type schedulableServiceFunc func(ctx context.Context, id ID, t time.Time) error
+
+func (f schedulableServiceFunc) UpdateLastScheduled(ctx context.Context, id ID, t time.Time) error {
+	return f(ctx, id, t)
+}
+
+func TestTreeScheduler_SkipCheckpoint(t *testing.T) {
+	// runs that the executor did not execute are not checkpointed, nor errors.
+	now := time.Now().UTC()
+	executed := make(chan time.Time, 10)
+	checkpointed := make(chan time.Time, 10)
+	errs := make(chan error, 10)
+
+	exe := executorFunc(func(ctx context.Context, id ID, scheduledFor time.Time, runAt time.Time) error {
+		executed <- scheduledFor
+		return ErrSkipCheckpoint
+	})
+	sch, _, err := NewScheduler(
+		exe,
+		schedulableServiceFunc(func(ctx context.Context, id ID, t time.Time) error {
+			checkpointed <- t
+			return nil
+		}),
+		WithMaxConcurrentWorkers(1),
+		WithOnErrorFn(func(_ context.Context, _ ID, _ time.Time, err error) {
+			errs <- err
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sch.Stop()
+
+	schedule, _, err := NewSchedule("* * * * * * *", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockSchedulable{id: 1, schedule: schedule, lastScheduled: now.Add(-3 * time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-executed:
+		case <-time.After(10 * time.Second):
+			t.Fatal("test timed out")
+		}
+	}
+	select {
+	case ts := <-checkpointed:
+		t.Fatalf("expected no checkpoint, got %s", ts)
+	case err := <-errs:
+		t.Fatalf("expected no error, got %v", err)
+	default:
+	}
+}
+
 func TestTreeScheduler_LongPanicTest(t *testing.T) {
 	// This test is to catch one specifgic type of race condition that can occur and isn't caught by race test, but causes a panic
 	// in the google btree library
@@ -666,3 +728,91 @@ func TestNewSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestSchedule_NextInLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		unparsed string
+		location string
+		from     time.Time
+		want     []time.Time
+	}{
+		{
+			name:     "UTC",
+			unparsed: "30 2 * * *",
+			from:     time.Date(2020, 3, 7, 12, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2020, 3, 8, 2, 30, 0, 0, time.UTC),
+				time.Date(2020, 3, 9, 2, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "spring forward shifts skipped times",
+			unparsed: "30 2 * * *",
+			location: "America/New_York",
+			from:     time.Date(2020, 3, 7, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2020, 3, 8, 3, 30, 0, 0, ny),
+				time.Date(2020, 3, 9, 2, 30, 0, 0, ny),
+			},
+		},
+		{
+			name:     "spring forward hourly",
+			unparsed: "0 * * * *",
+			location: "America/New_York",
+			from:     time.Date(2020, 3, 8, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2020, 3, 8, 1, 0, 0, 0, ny),
+				time.Date(2020, 3, 8, 3, 0, 0, 0, ny),
+				time.Date(2020, 3, 8, 4, 0, 0, 0, ny),
+			},
+		},
+		{
+			name:     "fall back runs repeated times once",
+			unparsed: "30 1 * * *",
+			location: "America/New_York",
+			from:     time.Date(2020, 10, 31, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2020, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+				time.Date(2020, 11, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST
+			},
+		},
+		{
+			name:     "every ignores location",
+			unparsed: "@every 1h",
+			location: "America/New_York",
+			from:     time.Date(2020, 3, 8, 6, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2020, 3, 8, 7, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 8, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch, from, err := NewScheduleInLocation(tt.unparsed, tt.location, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				next, err := sch.Next(from)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !next.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", from, next, want.UTC())
+				}
+				from = next
+			}
+		})
+	}
+
+	if _, _, err := NewScheduleInLocation("0 * * * *", "Not/AZone", time.Now()); err == nil {
+		t.Error("expected error for unknown location")
+	}
+}
@@ -320,6 +320,10 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 			s.sm.reportExecution(err, time.Since(preExec))
 			return err
 		}()
+		if err == ErrSkipCheckpoint {
+			// the run was not executed, it is scheduled again from the last checkpoint.
+			continue
+		}
 		if err != nil {
 			s.onErr(ctx, it.id, it.Next(), err)
 		}
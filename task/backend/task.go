@@ -29,6 +29,9 @@ type TaskControlService interface {
 
 	// AddRunLog adds a log line to the run.
 	AddRunLog(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
+
+	// UpdateRunStatistics sets what the query of the run read and wrote.
+	UpdateRunStatistics(ctx context.Context, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error
 }
 
 type TaskStatus string
@@ -48,6 +51,9 @@ const (
 	RunFail
 	RunCanceled
 	RunScheduled
+	// RunWaiting is the status of a run that waits for the runs of the tasks
+	// its task depends on to succeed.
+	RunWaiting
 )
 
 func (r RunStatus) String() string {
@@ -62,6 +68,8 @@ func (r RunStatus) String() string {
 		return "canceled"
 	case RunScheduled:
 		return "scheduled"
+	case RunWaiting:
+		return "waiting"
 	}
 	panic(fmt.Sprintf("unknown RunStatus: %d", r))
 }
@@ -143,7 +143,7 @@ func (d *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID i
 		run.StartedAt = when
 	case backend.RunSuccess, backend.RunFail, backend.RunCanceled:
 		run.FinishedAt = when
-	case backend.RunScheduled:
+	case backend.RunScheduled, backend.RunWaiting:
 		// nothing
 	default:
 		panic("invalid status")
@@ -165,6 +165,19 @@ func (d *TaskControlService) AddRunLog(ctx context.Context, taskID, runID influx
 	return nil
 }
 
+// UpdateRunStatistics sets what the query of the run read and wrote.
+func (d *TaskControlService) UpdateRunStatistics(ctx context.Context, taskID, runID influxdb.ID, stats *influxdb.RunStatistics) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	run := d.runs[taskID][runID]
+	if run == nil {
+		panic("cannot set the statistics of a non existent run")
+	}
+	run.Statistics = stats
+	return nil
+}
+
 func (d *TaskControlService) CreatedFor(taskID influxdb.ID) []*influxdb.Run {
 	d.mu.Lock()
 	defer d.mu.Unlock()
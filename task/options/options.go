@@ -38,6 +38,14 @@ type Options struct {
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
 	Retry *int64 `json:"retry,omitempty"`
+
+	// Location is the IANA time zone in which the Cron schedule is evaluated.
+	// If empty, cron schedules are evaluated in UTC.
+	Location string `json:"location,omitempty"`
+
+	// DependsOn are the IDs of the upstream tasks whose runs must succeed
+	// before a run of the task for the same scheduled time is executed.
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -127,6 +135,8 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.Location = ""
+	o.DependsOn = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
@@ -136,7 +146,9 @@ func (o *Options) IsZero() bool {
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.Location == "" &&
+		len(o.DependsOn) == 0
 }
 
 // All the task option names we accept.
@@ -147,6 +159,8 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optLocation    = "location"
+	optDependsOn   = "dependsOn"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -313,6 +327,28 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = pointer.Int64(retryVal.Int())
 	}
 
+	if locationVal, ok := optObject.Get(optLocation); ok {
+		if err := checkNature(locationVal.PolyType().Nature(), semantic.String); err != nil {
+			return opt, err
+		}
+		opt.Location = locationVal.Str()
+	}
+
+	if dependsOnVal, ok := optObject.Get(optDependsOn); ok {
+		if err := checkNature(dependsOnVal.PolyType().Nature(), semantic.Array); err != nil {
+			return opt, err
+		}
+		arr := dependsOnVal.Array()
+		if arr.Len() > 0 {
+			if err := checkNature(arr.Get(0).PolyType().Nature(), semantic.String); err != nil {
+				return opt, err
+			}
+		}
+		arr.Range(func(_ int, v values.Value) {
+			opt.DependsOn = append(opt.DependsOn, v.Str())
+		})
+	}
+
 	if err := opt.Validate(); err != nil {
 		return opt, err
 	}
@@ -374,6 +410,22 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	if o.Location != "" {
+		if _, err := time.LoadLocation(o.Location); err != nil {
+			errs = append(errs, "location invalid: "+err.Error())
+		}
+	}
+
+	seen := make(map[string]bool, len(o.DependsOn))
+	for _, id := range o.DependsOn {
+		if id == "" {
+			errs = append(errs, "dependsOn must not contain empty task IDs")
+		} else if seen[id] {
+			errs = append(errs, fmt.Sprintf("dependsOn contains duplicate task ID %q", id))
+		}
+		seen[id] = true
+	}
+
 	if len(errs) == 0 {
 		return nil
 	}
@@ -414,7 +466,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optLocation, optDependsOn:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -423,7 +475,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optLocation, optDependsOn}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
@@ -33,6 +33,16 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != nil && *opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, *opt.Retry)
 	}
+	if opt.Location != "" {
+		taskData = fmt.Sprintf("%s  location: %q,\n", taskData, opt.Location)
+	}
+	if len(opt.DependsOn) > 0 {
+		deps := make([]string, 0, len(opt.DependsOn))
+		for _, id := range opt.DependsOn {
+			deps = append(deps, fmt.Sprintf("%q", id))
+		}
+		taskData = fmt.Sprintf("%s  dependsOn: [%s],\n", taskData, strings.Join(deps, ", "))
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -74,6 +84,13 @@ func TestFromScript(t *testing.T) {
 		{script: scriptGenerator(options.Options{Name: "name4", Concurrency: pointer.Int64(1000), Every: *(options.MustParseDuration("1h"))}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name5\",\n  concurrency: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: "option task = {\n  name: \"name6\",\n  concurrency: 1,\n  every: 1,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name12", Cron: "0 2 * * *", Location: "America/New_York"}, ""),
+			exp: options.Options{Name: "name12", Cron: "0 2 * * *", Location: "America/New_York", Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name13", Cron: "0 2 * * *", Location: "Not/AZone"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name14", Every: *(options.MustParseDuration("1h")), DependsOn: []string{"0000000000000001", "0000000000000002"}}, ""),
+			exp: options.Options{Name: "name14", Every: *(options.MustParseDuration("1h")), DependsOn: []string{"0000000000000001", "0000000000000002"}, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name15", Every: *(options.MustParseDuration("1h")), DependsOn: []string{"0000000000000001", "0000000000000001"}}, ""), shouldErr: true},
+		{script: `option task = {name: "name16", every: 1h, dependsOn: [1, 2]} from(bucket: "x") |> range(start: -1h)`, shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name7", Retry: pointer.Int64(20), Every: *(options.MustParseDuration("1h"))}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name8\",\n  retry: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name9"}, ""), shouldErr: true},
@@ -222,6 +239,12 @@ func TestValidate(t *testing.T) {
 		t.Error("expected error for retry too large")
 	}
 
+	*bad = good
+	bad.Location = "Mars/Olympus_Mons"
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for unknown location")
+	}
+
 	notbad := new(options.Options)
 	*notbad = good
 	notbad.Cron = ""
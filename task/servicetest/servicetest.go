@@ -279,6 +279,7 @@ func testTaskCRUD(t *testing.T, sys *System) {
 		Status:          string(backend.DefaultTaskStatus),
 		Flux:            fmt.Sprintf(scriptFmt, 0),
 		Type:            influxdb.TaskSystemType,
+		Version:         1,
 	}
 	for fn, f := range found {
 		if diff := cmp.Diff(f, want); diff != "" {
@@ -363,6 +364,9 @@ func testTaskCRUD(t *testing.T, sys *System) {
 	if f.Flux != newFlux {
 		t.Fatalf("wrong flux from update; want %q, got %q", newFlux, f.Flux)
 	}
+	if f.Version != 2 {
+		t.Fatalf("expected script update to create version 2, got %d", f.Version)
+	}
 	if f.Status != string(backend.TaskActive) {
 		t.Fatalf("expected task to be created active, got %q", f.Status)
 	}
@@ -0,0 +1,118 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackfillStatusRunning is the status of a backfill that is enqueueing or
+	// waiting for its runs.
+	BackfillStatusRunning = "running"
+	// BackfillStatusCompleted is the status of a backfill all of whose runs have finished.
+	BackfillStatusCompleted = "completed"
+	// BackfillStatusCanceled is the status of a backfill that was canceled before it completed.
+	BackfillStatusCanceled = "canceled"
+)
+
+const (
+	// DefaultBackfillConcurrency is the number of runs of a backfill that
+	// execute at once, when not specified.
+	DefaultBackfillConcurrency = 1
+	// MaxBackfillConcurrency is the maximum number of runs of a backfill that
+	// execute at once.
+	MaxBackfillConcurrency = 10
+	// MaxBackfillRuns is the maximum number of runs of a single backfill.
+	MaxBackfillRuns = 100000
+)
+
+// Backfill runs a task for every time in a range that the task is scheduled
+// for. Its runs are executed in scheduledFor order, Concurrency at a time,
+// alongside and without delaying the runs of the task's live schedule.
+type Backfill struct {
+	ID          ID        `json:"id"`
+	TaskID      ID        `json:"taskID"`
+	OrgID       ID        `json:"orgID"`
+	Start       time.Time `json:"start"`
+	Stop        time.Time `json:"stop"`
+	Concurrency int       `json:"concurrency"`
+	Status      string    `json:"status"`
+
+	// Total is the number of runs in the backfill, of which Completed have
+	// succeeded and Failed have failed so far.
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+
+	// Cursor is the scheduled time before which all runs have finished. A
+	// backfill interrupted by a restart resumes from its cursor.
+	Cursor time.Time `json:"cursor"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BackfillCreate is the set of values to create a backfill. The task is run
+// for every time it is scheduled for from Start through Stop, inclusive.
+type BackfillCreate struct {
+	TaskID      ID        `json:"taskID"`
+	OrgID       ID        `json:"orgID"`
+	Start       time.Time `json:"start"`
+	Stop        time.Time `json:"stop"`
+	Concurrency int       `json:"concurrency,omitempty"`
+}
+
+// Valid returns an error if the backfill create is malformed.
+func (bc BackfillCreate) Valid() error {
+	invalidErr := func(format string, args ...interface{}) error {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	switch {
+	case !bc.TaskID.Valid():
+		return invalidErr("backfill requires a task")
+	case bc.Start.IsZero() || bc.Stop.IsZero():
+		return invalidErr("backfill requires a start and stop time")
+	case bc.Stop.Before(bc.Start):
+		return invalidErr("backfill stop must not be before start")
+	case bc.Concurrency < 0 || bc.Concurrency > MaxBackfillConcurrency:
+		return invalidErr("backfill concurrency must be between 1 and %d", MaxBackfillConcurrency)
+	}
+	return nil
+}
+
+// BackfillUpdate is the progress of a backfill.
+type BackfillUpdate struct {
+	Status    *string
+	Total     *int
+	Completed *int
+	Failed    *int
+	Cursor    *time.Time
+}
+
+// BackfillFilter represents a set of filters that restrict the returned backfills.
+type BackfillFilter struct {
+	TaskID *ID
+	Status *string
+}
+
+// TaskBackfillService represents a service for backfilling tasks over a range
+// of their schedule.
+type TaskBackfillService interface {
+	// FindBackfillByID returns a single backfill.
+	FindBackfillByID(ctx context.Context, id ID) (*Backfill, error)
+
+	// FindBackfills returns the backfills that match a filter.
+	FindBackfills(ctx context.Context, filter BackfillFilter) ([]*Backfill, error)
+
+	// CreateBackfill creates a backfill of a task and begins enqueueing its runs.
+	CreateBackfill(ctx context.Context, bc BackfillCreate) (*Backfill, error)
+
+	// CancelBackfill stops enqueueing the runs of a backfill and cancels the
+	// runs that are executing.
+	CancelBackfill(ctx context.Context, id ID) (*Backfill, error)
+}
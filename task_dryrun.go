@@ -0,0 +1,63 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// TaskDryRun is a task script to execute once, as a run of a task scheduled
+// for ScheduledFor would, without creating the task or persisting anything.
+type TaskDryRun struct {
+	OrganizationID ID        `json:"orgID"`
+	Flux           string    `json:"flux"`
+	ScheduledFor   time.Time `json:"scheduledFor"`
+}
+
+// Valid returns an error if the dry run is malformed.
+func (d TaskDryRun) Valid() error {
+	switch {
+	case !d.OrganizationID.Valid():
+		return &Error{
+			Code: EInvalid,
+			Msg:  "dry run requires an organization",
+		}
+	case d.Flux == "":
+		return &Error{
+			Code: EInvalid,
+			Msg:  "dry run requires a flux script",
+		}
+	}
+	return nil
+}
+
+// TaskDryRunResult is the outcome of a dry run of a task script.
+type TaskDryRunResult struct {
+	ScheduledFor time.Time `json:"scheduledFor"`
+	// Tables are the series the script would have written.
+	Tables []*TaskDryRunTable `json:"tables"`
+	Log    []Log              `json:"log"`
+	// Error is the error the script failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// TaskDryRunTable is a series of a bucket that a dry run would have written.
+type TaskDryRunTable struct {
+	OrganizationID ID                `json:"orgID"`
+	BucketID       ID                `json:"bucketID"`
+	Measurement    string            `json:"measurement"`
+	Tags           map[string]string `json:"tags"`
+	Rows           []TaskDryRunRow   `json:"rows"`
+}
+
+// TaskDryRunRow is the fields of a series at a single time.
+type TaskDryRunRow struct {
+	Time   time.Time              `json:"time"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// TaskDryRunService represents a service for executing task scripts without
+// persisting their results.
+type TaskDryRunService interface {
+	// DryRunTask executes a task script once and returns what it would have written.
+	DryRunTask(ctx context.Context, d TaskDryRun) (*TaskDryRunResult, error)
+}
@@ -47,6 +47,30 @@ var (
 		Msg:  "run not found",
 	}
 
+	// ErrBackfillNotFound is returned when searching for a single backfill that doesn't exist.
+	ErrBackfillNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "backfill not found",
+	}
+
+	// ErrTaskVersionNotFound is returned when searching for a single task version that doesn't exist.
+	ErrTaskVersionNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "task version not found",
+	}
+
+	// ErrTaskPartitionLeased is returned when claiming a partition of the tasks that another node holds the lease of.
+	ErrTaskPartitionLeased = &Error{
+		Code: EConflict,
+		Msg:  "task partition is leased by another node",
+	}
+
+	// ErrTaskRunClaimed is returned when claiming a run of a task that was already scheduled for the same time or later.
+	ErrTaskRunClaimed = &Error{
+		Code: EConflict,
+		Msg:  "task run was already claimed",
+	}
+
 	ErrRunKeyNotFound = &Error{
 		Code: ENotFound,
 		Msg:  "run key not found",
@@ -153,6 +177,26 @@ func ErrTaskOptionParse(err error) *Error {
 	}
 }
 
+// ErrTaskDependencyInvalid is returned when a task depends on a task that
+// can't be one of its upstream tasks.
+func ErrTaskDependencyInvalid(id string, reason string) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid task dependency %q: %s", id, reason),
+		Op:   "taskDependencies",
+	}
+}
+
+// ErrTaskDependencyCycle is returned when the dependencies of a task would
+// make it depend on itself.
+func ErrTaskDependencyCycle(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("task dependencies form a cycle through task %s", id),
+		Op:   "taskDependencies",
+	}
+}
+
 func ErrJsonMarshalError(err error) *Error {
 	return &Error{
 		Code: EInvalid,
@@ -0,0 +1,62 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// TaskNode is a process that schedules tasks, and shares their scheduling
+// with the other nodes that use the same metadata store. A node is alive
+// until it misses its heartbeat.
+type TaskNode struct {
+	ID          string    `json:"id"`
+	HeartbeatAt time.Time `json:"heartbeatAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// TaskPartitionLease is the claim of a node on a partition of the tasks. Only
+// the node that holds the lease of a partition schedules its tasks, until the
+// lease expires or is released.
+type TaskPartitionLease struct {
+	Partition int       `json:"partition"`
+	NodeID    string    `json:"nodeID"`
+	RenewedAt time.Time `json:"renewedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the lease has expired at t.
+func (l *TaskPartitionLease) Expired(t time.Time) bool {
+	return !l.ExpiresAt.After(t)
+}
+
+// TaskLeaseService records the nodes that schedule tasks, and the leases
+// they hold on the partitions of the tasks.
+type TaskLeaseService interface {
+	// HeartbeatTaskNode records that a node is alive for ttl, forgets the
+	// nodes that missed their heartbeat, and returns the nodes that are alive.
+	HeartbeatTaskNode(ctx context.Context, nodeID string, ttl time.Duration) ([]*TaskNode, error)
+
+	// DeleteTaskNode forgets a node that stopped scheduling tasks, so that the
+	// other nodes share its partitions without waiting for it to expire.
+	DeleteTaskNode(ctx context.Context, nodeID string) error
+
+	// FindTaskPartitionLeases returns the leases of all partitions, including
+	// expired leases that have not been claimed since.
+	FindTaskPartitionLeases(ctx context.Context) ([]*TaskPartitionLease, error)
+
+	// ClaimTaskPartition leases a partition to a node for ttl. A node renews
+	// its lease by claiming the partition again. Claiming a partition that is
+	// leased by another node fails with ErrTaskPartitionLeased, until that
+	// lease expires.
+	ClaimTaskPartition(ctx context.Context, nodeID string, partition int, ttl time.Duration) (*TaskPartitionLease, error)
+
+	// ReleaseTaskPartition gives up the lease of a node on a partition, so
+	// that other nodes can claim it right away.
+	ReleaseTaskPartition(ctx context.Context, nodeID string, partition int) error
+
+	// ClaimTaskRun advances the latest scheduled time of a task to
+	// scheduledFor, so that its run for scheduledFor is executed once.
+	// Claiming a run of a task that was already scheduled or completed for
+	// scheduledFor or later fails with ErrTaskRunClaimed.
+	ClaimTaskRun(ctx context.Context, taskID ID, scheduledFor time.Time) error
+}
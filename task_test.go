@@ -96,6 +96,20 @@ from(bucket: "x")
 			t.Fatalf("expected every to be 30s but was %s", op.Every)
 		}
 	})
+	t.Run("location", func(t *testing.T) {
+		tu := &platform.TaskUpdate{}
+		tu.Options.Location = "America/New_York"
+		if err := tu.UpdateFlux(`option task = {cron: "0 2 * * *", name: "foo", location: "UTC"} from(bucket:"x") |> range(start:-1h)`); err != nil {
+			t.Fatal(err)
+		}
+		op, err := options.FromScript(*tu.Flux)
+		if err != nil {
+			t.Error(err)
+		}
+		if op.Location != "America/New_York" {
+			t.Fatalf("expected location to be America/New_York but was %q", op.Location)
+		}
+	})
 	t.Run("switching from every to cron", func(t *testing.T) {
 		tu := &platform.TaskUpdate{}
 		tu.Options.Cron = "* * * * *"
@@ -156,3 +170,62 @@ from(bucket: "x")
 	})
 
 }
+
+func TestTaskNotification_Notifies(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        platform.TaskNotification
+		status   string
+		failed   int
+		notifies bool
+	}{
+		{name: "every failure", n: platform.TaskNotification{Policy: platform.TaskNotifyEveryFailure}, status: "failed", failed: 3, notifies: true},
+		{name: "every success", n: platform.TaskNotification{Policy: platform.TaskNotifyEveryFailure}, status: "success", failed: 3},
+		{name: "consecutive below", n: platform.TaskNotification{Policy: platform.TaskNotifyConsecutiveFailures, Failures: 3}, status: "failed", failed: 1},
+		{name: "consecutive reached", n: platform.TaskNotification{Policy: platform.TaskNotifyConsecutiveFailures, Failures: 3}, status: "failed", failed: 2, notifies: true},
+		{name: "consecutive past", n: platform.TaskNotification{Policy: platform.TaskNotifyConsecutiveFailures, Failures: 3}, status: "failed", failed: 3},
+		{name: "recovery first failure", n: platform.TaskNotification{Policy: platform.TaskNotifyRecovery}, status: "failed", notifies: true},
+		{name: "recovery repeated failure", n: platform.TaskNotification{Policy: platform.TaskNotifyRecovery}, status: "failed", failed: 1},
+		{name: "recovery recovered", n: platform.TaskNotification{Policy: platform.TaskNotifyRecovery}, status: "success", failed: 2, notifies: true},
+		{name: "recovery success", n: platform.TaskNotification{Policy: platform.TaskNotifyRecovery}, status: "success"},
+		{name: "canceled", n: platform.TaskNotification{Policy: platform.TaskNotifyEveryFailure}, status: "canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Notifies(tt.status, tt.failed); got != tt.notifies {
+				t.Fatalf("expected notifies %t, got %t", tt.notifies, got)
+			}
+		})
+	}
+}
+
+func TestTaskNotification_Valid(t *testing.T) {
+	for _, n := range []platform.TaskNotification{
+		{Policy: platform.TaskNotifyEveryFailure},
+		{EndpointID: 1, Policy: "sometimes"},
+		{EndpointID: 1, Policy: platform.TaskNotifyConsecutiveFailures},
+	} {
+		if err := n.Valid(); err == nil {
+			t.Errorf("expected %+v to be invalid", n)
+		}
+	}
+	if err := (&platform.TaskNotification{EndpointID: 1, Policy: platform.TaskNotifyConsecutiveFailures, Failures: 2}).Valid(); err != nil {
+		t.Errorf("expected notification to be valid: %v", err)
+	}
+}
+
+func TestTaskUpdate_ValidateLocation(t *testing.T) {
+	invalid := "invalid"
+
+	tu := &platform.TaskUpdate{Status: &invalid}
+	tu.Options.Location = "America/New_York"
+	if err := tu.Validate(); err == nil {
+		t.Fatal("expected invalid status to be rejected along with a location")
+	}
+
+	tu = &platform.TaskUpdate{}
+	tu.Options.Location = "Not/AZone"
+	if err := tu.Validate(); err == nil {
+		t.Fatal("expected invalid location to be rejected")
+	}
+}
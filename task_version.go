@@ -0,0 +1,46 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// TaskVersion is an immutable record of the script of a task. A version is
+// stored when a task is created and each time its script changes, and runs
+// reference the version they executed.
+type TaskVersion struct {
+	TaskID         ID  `json:"taskID"`
+	OrganizationID ID  `json:"orgID"`
+	Version        int `json:"version"`
+
+	Flux     string        `json:"flux"`
+	Name     string        `json:"name"`
+	Every    string        `json:"every,omitempty"`
+	Cron     string        `json:"cron,omitempty"`
+	Offset   time.Duration `json:"offset,omitempty"`
+	Location string        `json:"location,omitempty"`
+
+	// AuthorID is the user that created the version, if known.
+	AuthorID  ID        `json:"authorID,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TaskVersionFilter represents a set of filters that restrict the returned
+// versions of a task.
+type TaskVersionFilter struct {
+	TaskID ID
+	// After restricts the versions to those after the version After.
+	After int
+	Limit int
+}
+
+// TaskVersionService represents a service for reading the version history of
+// tasks. Versions are created by the TaskService; a task is rolled back by
+// updating it with the script of an earlier version.
+type TaskVersionService interface {
+	// FindTaskVersion returns a single version of a task.
+	FindTaskVersion(ctx context.Context, taskID ID, version int) (*TaskVersion, error)
+
+	// FindTaskVersions returns the versions of a task, oldest first.
+	FindTaskVersions(ctx context.Context, filter TaskVersionFilter) ([]*TaskVersion, error)
+}
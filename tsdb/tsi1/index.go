@@ -47,7 +47,6 @@ func init() {
 //
 // NOTE: Currently, this must not be change once a database is created. Further,
 // it must also be a power of 2.
-//
 var DefaultPartitionN uint64 = 8
 
 // An IndexOption is a functional option for changing the configuration of
@@ -92,6 +91,14 @@ var WithLogFileBufferSize = func(sz int) IndexOption {
 	}
 }
 
+// WithSeriesCreatedFn sets a function that is called with the measurement name
+// and the tags of every series the index creates. It may be called concurrently.
+var WithSeriesCreatedFn = func(fn func(name []byte, tags models.Tags)) IndexOption {
+	return func(i *Index) {
+		i.seriesCreatedFn = fn
+	}
+}
+
 // DisableMetrics ensures that activity is not collected via the prometheus metrics.
 // DisableMetrics must be called before Open.
 var DisableMetrics = func() IndexOption {
@@ -113,13 +120,14 @@ type Index struct {
 	metricsEnabled   bool
 
 	// The following may be set when initializing an Index.
-	path               string      // Root directory of the index partitions.
-	disableCompactions bool        // Initially disables compactions on the index.
-	maxLogFileSize     int64       // Maximum size of a LogFile before it's compacted.
-	logfileBufferSize  int         // The size of the buffer used by the LogFile.
-	disableFsync       bool        // Disables flushing buffers and fsyning files. Used when working with indexes offline.
-	logger             *zap.Logger // Index's logger.
-	config             Config      // The index configuration
+	path               string                    // Root directory of the index partitions.
+	disableCompactions bool                      // Initially disables compactions on the index.
+	maxLogFileSize     int64                     // Maximum size of a LogFile before it's compacted.
+	logfileBufferSize  int                       // The size of the buffer used by the LogFile.
+	disableFsync       bool                      // Disables flushing buffers and fsyning files. Used when working with indexes offline.
+	logger             *zap.Logger               // Index's logger.
+	config             Config                    // The index configuration
+	seriesCreatedFn    func([]byte, models.Tags) // Called with the name and tags of each created series.
 
 	// The following must be set when initializing an Index.
 	sfile *tsdb.SeriesFile // series lookup file
@@ -678,6 +686,14 @@ func (i *Index) CreateSeriesListIfNotExists(collection *tsdb.SeriesCollection) e
 					continue
 				}
 
+				if i.seriesCreatedFn != nil {
+					for j, id := range ids {
+						if !id.IsZero() {
+							i.seriesCreatedFn(pCollections[idx].Names[j], pCollections[idx].Tags[j])
+						}
+					}
+				}
+
 				// Some cached bitset results may need to be updated.
 				i.tagValueCache.RLock()
 				for j, id := range ids {
@@ -795,6 +811,39 @@ func (i *Index) SeriesN() int64 {
 	return total
 }
 
+// MeasurementSeriesN returns the number of non-tombstoned series for the
+// provided measurement.
+func (i *Index) MeasurementSeriesN(name []byte) (int64, error) {
+	itr, err := i.measurementSeriesIDIterator(name)
+	if err != nil {
+		return 0, err
+	} else if itr == nil {
+		return 0, nil
+	}
+	defer itr.Close()
+
+	// Prefer the series id set if the partitions can provide one, since it
+	// avoids walking every series in the measurement.
+	var ss *tsdb.SeriesIDSet
+	if sitr, ok := itr.(tsdb.SeriesIDSetIterator); ok {
+		ss = sitr.SeriesIDSet()
+	} else {
+		ss = tsdb.NewSeriesIDSet()
+		for {
+			e, err := itr.Next()
+			if err != nil {
+				return 0, err
+			} else if e.SeriesID.IsZero() {
+				break
+			}
+			ss.AddNoLock(e.SeriesID)
+		}
+	}
+
+	// Only count series that are still present in the index.
+	return int64(ss.And(i.SeriesIDSet()).Cardinality()), nil
+}
+
 // HasTagKey returns true if tag key exists. It returns the first error
 // encountered if any.
 func (i *Index) HasTagKey(name, key []byte) (bool, error) {
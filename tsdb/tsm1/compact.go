@@ -130,6 +130,7 @@ type fileStore interface {
 	LastModified() time.Time
 	BlockCount(path string, idx int) int
 	ParseFileName(path string) (int, int, error)
+	ColdDir() string
 }
 
 func NewDefaultPlanner(fs fileStore, writeColdDuration time.Duration) *DefaultPlanner {
@@ -184,6 +185,16 @@ func (t *tsmGeneration) count() int {
 	return len(t.files)
 }
 
+// inDir returns true if all the files of the generation are in dir.
+func (t *tsmGeneration) inDir(dir string) bool {
+	for _, f := range t.files {
+		if !inDir(f.Path, dir) {
+			return false
+		}
+	}
+	return true
+}
+
 // hasTombstones returns true if there are keys removed for any of the files.
 func (t *tsmGeneration) hasTombstones() bool {
 	for _, f := range t.files {
@@ -202,10 +213,55 @@ func (c *DefaultPlanner) ParseFileName(path string) (int, int, error) {
 	return c.FileStore.ParseFileName(path)
 }
 
-// FullyCompacted returns true if the shard is fully compacted.
+// FullyCompacted returns true if the shard is fully compacted. Generations in
+// the cold tier are not compacted with the hot tier, so they only count for
+// their tombstones.
 func (c *DefaultPlanner) FullyCompacted() bool {
-	gens := c.findGenerations(false)
-	return len(gens) <= 1 && !gens.hasTombstones()
+	gens, cold := c.splitCold(c.findGenerations(false))
+	return len(gens) <= 1 && !gens.hasTombstones() && !cold.hasTombstones()
+}
+
+// splitCold returns the generations with files in the hot tier and the
+// generations that are entirely in the cold tier. Cold generations are fully
+// compacted, so they are only rewritten to remove tombstoned data, on their
+// own, so that their data is not compacted back into the hot tier.
+func (c *DefaultPlanner) splitCold(generations tsmGenerations) (hot, cold tsmGenerations) {
+	dir := c.FileStore.ColdDir()
+	if dir == "" {
+		return generations, nil
+	}
+
+	hot = make(tsmGenerations, 0, len(generations))
+	for _, g := range generations {
+		if g.inDir(dir) {
+			cold = append(cold, g)
+		} else {
+			hot = append(hot, g)
+		}
+	}
+	return hot, cold
+}
+
+// planCold returns a compaction group for each cold generation that has
+// tombstones.
+func (c *DefaultPlanner) planCold(cold tsmGenerations) []CompactionGroup {
+	var cGroups []CompactionGroup
+	for _, g := range cold {
+		if !g.hasTombstones() {
+			continue
+		}
+
+		var cGroup CompactionGroup
+		for _, f := range g.files {
+			cGroup = append(cGroup, f.Path)
+		}
+		cGroups = append(cGroups, cGroup)
+	}
+
+	if !c.acquire(cGroups) {
+		return nil
+	}
+	return cGroups
 }
 
 // ForceFull causes the planner to return a full compaction plan the next time
@@ -327,7 +383,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	// Determine the generations from all files on disk.  We need to treat
 	// a generation conceptually as a single file even though it may be
 	// split across several files in sequence.
-	generations := c.findGenerations(true)
+	generations, _ := c.splitCold(c.findGenerations(true))
 
 	// If there is only one generation and no tombstones, then there's nothing to
 	// do.
@@ -404,9 +460,15 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 }
 
 // Plan returns a set of TSM files to rewrite for level 4 or higher.  The planning returns
-// multiple groups if possible to allow compactions to run concurrently.
+// multiple groups if possible to allow compactions to run concurrently.  Generations in
+// the cold tier are planned on their own.
 func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
-	generations := c.findGenerations(true)
+	generations, cold := c.splitCold(c.findGenerations(true))
+	return append(c.planCold(cold), c.planHot(lastWrite, generations)...)
+}
+
+// planHot returns a set of the TSM files of the hot tier to rewrite for level 4 or higher.
+func (c *DefaultPlanner) planHot(lastWrite time.Time, generations tsmGenerations) []CompactionGroup {
 
 	c.mu.RLock()
 	forceFull := c.forceFull
@@ -693,6 +755,7 @@ type Compactor struct {
 		SetCurrentGenerationFunc(func() int)
 		NextGeneration() int
 		TSMReader(path string) *TSMReader
+		ColdDir() string
 	}
 
 	// RateLimit is the limit for disk writes for all concurrent compactions.
@@ -949,6 +1012,21 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 	return c.writeNewFiles(maxGeneration, maxSequence, tsmFiles, tsm, true)
 }
 
+// outputDir returns the directory to write the compaction of tsmFiles to. Files
+// compacted only from files in the cold tier stay in the cold tier.
+func (c *Compactor) outputDir(tsmFiles []string) string {
+	dir := c.FileStore.ColdDir()
+	if dir == "" || len(tsmFiles) == 0 {
+		return c.Dir
+	}
+	for _, f := range tsmFiles {
+		if !inDir(f, dir) {
+			return c.Dir
+		}
+	}
+	return dir
+}
+
 // CompactFull writes multiple smaller TSM files into 1 or more larger files.
 func (c *Compactor) CompactFull(tsmFiles []string) ([]string, error) {
 	c.mu.RLock()
@@ -1030,12 +1108,13 @@ func (c *Compactor) removeTmpFiles(files []string) error {
 func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter KeyIterator, throttle bool) ([]string, error) {
 	// These are the new TSM files written
 	var files []string
+	dir := c.outputDir(src)
 
 	for {
 		sequence++
 
 		// New TSM files are written to a temp file and renamed when fully completed.
-		fileName := filepath.Join(c.Dir, c.formatFileName(generation, sequence)+"."+TSMFileExtension+"."+TmpTSMFileExtension)
+		fileName := filepath.Join(dir, c.formatFileName(generation, sequence)+"."+TSMFileExtension+"."+TmpTSMFileExtension)
 		statsFileName := StatsFilename(fileName)
 
 		// Write as much as possible to this file
@@ -2984,6 +2984,7 @@ type fakeFileStore struct {
 	lastModified time.Time
 	blockCount   int
 	readers      []*tsm1.TSMReader
+	coldDir      string
 }
 
 func (w *fakeFileStore) Stats() []tsm1.FileStat {
@@ -3021,3 +3022,7 @@ func (w *fakeFileStore) Close() {
 func (w *fakeFileStore) ParseFileName(path string) (int, int, error) {
 	return tsm1.DefaultParseFileName(path)
 }
+
+func (w *fakeFileStore) ColdDir() string {
+	return w.coldDir
+}
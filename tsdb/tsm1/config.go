@@ -35,6 +35,7 @@ type Config struct {
 
 	Compaction CompactionConfig `toml:"compaction"`
 	Cache      CacheConfig      `toml:"cache"`
+	Tiering    TieringConfig    `toml:"tiering"`
 }
 
 // NewConfig constructs a Config with the default values.
@@ -51,6 +52,7 @@ func NewConfig() Config {
 			ThroughputBurst:       toml.Size(DefaultCompactThroughputBurst),
 			MaxConcurrent:         DefaultCompactMaxConcurrent,
 		},
+		Tiering: NewTieringConfig(),
 	}
 }
 
@@ -125,6 +127,35 @@ func NewCacheConfig() CacheConfig {
 	}
 }
 
+// Default tiering configuration values.
+const (
+	DefaultTieringColdAge       = toml.Duration(30 * 24 * time.Hour) // 30 days
+	DefaultTieringCheckInterval = toml.Duration(time.Hour)
+)
+
+// TieringConfig holds all of the configuration for moving TSM files to a
+// secondary, cold, volume.
+type TieringConfig struct {
+	// ColdPath is the directory fully compacted TSM files are moved to once all
+	// of their data is older than ColdAge. Tiering is disabled when empty.
+	ColdPath string `toml:"cold-path"`
+
+	// ColdAge is the age the newest point of a TSM file must reach before the
+	// file is moved to ColdPath.
+	ColdAge toml.Duration `toml:"cold-age"`
+
+	// CheckInterval is how often the engine looks for files to move to ColdPath.
+	CheckInterval toml.Duration `toml:"check-interval"`
+}
+
+// NewTieringConfig initialises a new TieringConfig with default values.
+func NewTieringConfig() TieringConfig {
+	return TieringConfig{
+		ColdAge:       DefaultTieringColdAge,
+		CheckInterval: DefaultTieringCheckInterval,
+	}
+}
+
 // Default WAL configuration values.
 const (
 	DefaultWALEnabled    = true
@@ -175,6 +175,7 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	fs := NewFileStore(path)
 	fs.openLimiter = limiter.NewFixed(config.MaxConcurrentOpens)
 	fs.tsmMMAPWillNeed = config.MADVWillNeed
+	fs.WithColdDir(config.Tiering.ColdPath)
 
 	cache := NewCache(uint64(config.Cache.MaxMemorySize))
 
@@ -202,6 +202,7 @@ type FileStore struct {
 	currentGeneration     int        // internally maintained generation
 	currentGenerationFunc func() int // external generation
 	dir                   string
+	coldDir               string // directory of the cold tier; empty if tiering is disabled
 
 	files           []TSMFile
 	tsmMMAPWillNeed bool          // If true then the kernel will be advised MMAP_WILLNEED for TSM files.
@@ -272,6 +273,18 @@ func (f *FileStore) WithObserver(obs FileStoreObserver) {
 	f.obs = obs
 }
 
+// WithColdDir sets the directory TSM files are moved to by MoveToColdTier. It
+// must be called before the file store is opened.
+func (f *FileStore) WithColdDir(dir string) {
+	f.coldDir = dir
+}
+
+// ColdDir returns the directory of the cold tier, or the empty string if
+// tiering is disabled.
+func (f *FileStore) ColdDir() string {
+	return f.coldDir
+}
+
 func (f *FileStore) WithParseFileNameFunc(parseFileNameFunc ParseFileNameFunc) {
 	f.parseFileName = parseFileNameFunc
 }
@@ -630,6 +643,12 @@ func (f *FileStore) Open(ctx context.Context) error {
 		return err
 	}
 
+	if f.coldDir != "" {
+		if files, err = f.openColdDir(files); err != nil {
+			return err
+		}
+	}
+
 	// struct to hold the result of opening each reader in a goroutine
 	type res struct {
 		r   *TSMReader
@@ -935,66 +954,11 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 			if remove == file.Path() {
 				keep = false
 
-				// give the observer a chance to process the file first.
-				if err := f.obs.FileUnlinking(file.Path()); err != nil {
+				inUse, err := f.unlinkFile(file)
+				if err != nil {
 					return err
-				}
-
-				// Remove associated stats file.
-				statsFile := StatsFilename(file.Path())
-				if _, err := os.Stat(statsFile); err == nil {
-					if err := f.obs.FileUnlinking(statsFile); err != nil {
-						return err
-					}
-				}
-
-				for _, t := range file.TombstoneFiles() {
-					if err := f.obs.FileUnlinking(t.Path); err != nil {
-						return err
-					}
-				}
-
-				// If queries are running against this file, then we need to move it out of the
-				// way and let them complete.  We'll then delete the original file to avoid
-				// blocking callers upstream.  If the process crashes, the temp file is
-				// cleaned up at startup automatically.
-				//
-				// In order to ensure that there are no races with this (file held externally calls Ref
-				// after we check InUse), we need to maintain the invariant that every handle to a file
-				// is handed out in use (Ref'd), and handlers only ever relinquish the file once (call Unref
-				// exactly once, and never use it again). InUse is only valid during a write lock, since
-				// we allow calls to Ref and Unref under the read lock and no lock at all respectively.
-				if file.InUse() {
-					// Copy all the tombstones related to this TSM file
-					var deletes []string
-					for _, t := range file.TombstoneFiles() {
-						deletes = append(deletes, t.Path)
-					}
-
-					// Rename the TSM file used by this reader
-					tempPath := fmt.Sprintf("%s.%s", file.Path(), TmpTSMFileExtension)
-					if err := file.Rename(tempPath); err != nil {
-						return err
-					}
-
-					// Remove the old file and tombstones.  We can't use the normal TSMReader.Remove()
-					// because it now refers to our temp file which we can't remove.
-					for _, f := range deletes {
-						if err := os.Remove(f); err != nil {
-							return err
-						}
-					}
-
+				} else if inUse {
 					inuse = append(inuse, file)
-					continue
-				}
-
-				if err := file.Close(); err != nil {
-					return err
-				}
-
-				if err := file.Remove(); err != nil {
-					return err
 				}
 				break
 			}
@@ -1008,6 +972,11 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	if err := fs.SyncDir(f.dir); err != nil {
 		return err
 	}
+	if f.coldDir != "" {
+		if err := fs.SyncDir(f.coldDir); err != nil {
+			return err
+		}
+	}
 
 	// Tell the purger about our in-use files we need to remove
 	f.purger.add(inuse)
@@ -1024,6 +993,12 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.lastFileStats = nil
 	f.files = active
 	sort.Sort(tsmReaders(f.files))
+	return f.recalculateStats()
+}
+
+// recalculateStats resets the disk size and file count stats from the current
+// set of files. It must be called with the lock held for writing.
+func (f *FileStore) recalculateStats() error {
 	f.tracker.ClearFileCounts()
 	f.tracker.ClearDiskSizes()
 
@@ -1048,6 +1023,69 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	return nil
 }
 
+// unlinkFile removes file and its tombstones from disk. If queries are using the
+// file it is moved out of the way instead and inUse is true; the caller must hand
+// it to the purger. It must be called with the lock held for writing.
+func (f *FileStore) unlinkFile(file TSMFile) (inUse bool, err error) {
+	// give the observer a chance to process the file first.
+	if err := f.obs.FileUnlinking(file.Path()); err != nil {
+		return false, err
+	}
+
+	// Remove associated stats file.
+	statsFile := StatsFilename(file.Path())
+	if _, err := os.Stat(statsFile); err == nil {
+		if err := f.obs.FileUnlinking(statsFile); err != nil {
+			return false, err
+		}
+	}
+
+	for _, t := range file.TombstoneFiles() {
+		if err := f.obs.FileUnlinking(t.Path); err != nil {
+			return false, err
+		}
+	}
+
+	// If queries are running against this file, then we need to move it out of the
+	// way and let them complete.  We'll then delete the original file to avoid
+	// blocking callers upstream.  If the process crashes, the temp file is
+	// cleaned up at startup automatically.
+	//
+	// In order to ensure that there are no races with this (file held externally calls Ref
+	// after we check InUse), we need to maintain the invariant that every handle to a file
+	// is handed out in use (Ref'd), and handlers only ever relinquish the file once (call Unref
+	// exactly once, and never use it again). InUse is only valid during a write lock, since
+	// we allow calls to Ref and Unref under the read lock and no lock at all respectively.
+	if file.InUse() {
+		// Copy all the tombstones related to this TSM file
+		var deletes []string
+		for _, t := range file.TombstoneFiles() {
+			deletes = append(deletes, t.Path)
+		}
+
+		// Rename the TSM file used by this reader
+		tempPath := fmt.Sprintf("%s.%s", file.Path(), TmpTSMFileExtension)
+		if err := file.Rename(tempPath); err != nil {
+			return false, err
+		}
+
+		// Remove the old file and tombstones.  We can't use the normal TSMReader.Remove()
+		// because it now refers to our temp file which we can't remove.
+		for _, f := range deletes {
+			if err := os.Remove(f); err != nil {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+
+	if err := file.Close(); err != nil {
+		return false, err
+	}
+	return false, file.Remove()
+}
+
 // LastModified returns the last time the file store was updated with new
 // TSM files or a delete.
 func (f *FileStore) LastModified() time.Time {
@@ -1243,12 +1281,12 @@ func (f *FileStore) CreateSnapshot(ctx context.Context) (backupID int, backupDir
 	}
 	for _, tsmf := range files {
 		newpath := filepath.Join(backupDirFullPath, filepath.Base(tsmf.Path()))
-		if err := os.Link(tsmf.Path(), newpath); err != nil {
+		if err := f.linkFile(tsmf.Path(), newpath); err != nil {
 			return 0, "", fmt.Errorf("error creating tsm hard link: %q", err)
 		}
 		for _, tf := range tsmf.TombstoneFiles() {
 			newpath := filepath.Join(backupDirFullPath, filepath.Base(tf.Path))
-			if err := os.Link(tf.Path, newpath); err != nil {
+			if err := f.linkFile(tf.Path, newpath); err != nil {
 				return 0, "", fmt.Errorf("error creating tombstone hard link: %q", err)
 			}
 		}
@@ -1608,6 +1646,11 @@ func (p *purger) purge() {
 
 type tsmReaders []TSMFile
 
-func (a tsmReaders) Len() int           { return len(a) }
-func (a tsmReaders) Less(i, j int) bool { return a[i].Path() < a[j].Path() }
-func (a tsmReaders) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a tsmReaders) Len() int      { return len(a) }
+func (a tsmReaders) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+// Less orders files by name rather than by path, as files can live in either
+// the hot or the cold tier directory.
+func (a tsmReaders) Less(i, j int) bool {
+	return filepath.Base(a[i].Path()) < filepath.Base(a[j].Path())
+}
@@ -0,0 +1,181 @@
+package tsm1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// TierReport reports the TSM files of the hot and cold storage tiers, and the
+// files of the hot tier that are due to move to the cold tier.
+type TierReport struct {
+	Stderr io.Writer
+	Stdout io.Writer
+
+	Dir     string        // The hot tier directory.
+	ColdDir string        // The cold tier directory.
+	ColdAge time.Duration // Files whose data is all older than ColdAge are due to move.
+	Now     time.Time     // The time ColdAge is relative to. Defaults to the current time.
+}
+
+// TierSummary summarises the TSM files of a storage tier.
+type TierSummary struct {
+	Files    int
+	Size     uint64
+	Min, Max int64
+
+	// Due is the number of files that are due to move to the cold tier.
+	Due int
+}
+
+// Run executes the TierReport, returning the summaries of the hot and cold
+// tiers.
+//
+// Calling Run with print set to true emits data about each file to the report's
+// Stdout fd.
+func (r *TierReport) Run(print bool) (hot, cold *TierSummary, err error) {
+	if r.Stderr == nil {
+		r.Stderr = os.Stderr
+	}
+	if r.Stdout == nil {
+		r.Stdout = os.Stdout
+	}
+	if r.Now.IsZero() {
+		r.Now = time.Now()
+	}
+
+	if !print {
+		r.Stderr, r.Stdout = ioutil.Discard, ioutil.Discard
+	}
+
+	for _, dir := range []string{r.Dir, r.ColdDir} {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return nil, nil, err
+		} else if !fi.IsDir() {
+			return nil, nil, errors.New("data directory not valid")
+		}
+	}
+
+	tw := tabwriter.NewWriter(r.Stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"File", "Tier", "Level", "Size", "Min Time", "Max Time", "Due"}, "\t"))
+
+	before := r.Now.Add(-r.ColdAge).UnixNano()
+	hot, cold = newTierSummary(), newTierSummary()
+	for _, tier := range []struct {
+		name    string
+		dir     string
+		summary *TierSummary
+	}{
+		{name: "hot", dir: r.Dir, summary: hot},
+		{name: "cold", dir: r.ColdDir, summary: cold},
+	} {
+		files, err := filepath.Glob(filepath.Join(tier.dir, fmt.Sprintf("*.%s", TSMFileExtension)))
+		if err != nil {
+			panic(err) // Only error would be a bad pattern; not runtime related.
+		}
+
+		for _, path := range files {
+			stat, err := r.fileStat(path)
+			if err != nil {
+				fmt.Fprintf(r.Stderr, "error: %s: %v. Skipping file.\n", path, err)
+				continue
+			}
+
+			_, seq, err := DefaultParseFileName(path)
+			if err != nil {
+				fmt.Fprintf(r.Stderr, "error: %s: %v. Skipping file.\n", path, err)
+				continue
+			}
+
+			due := tier.summary == hot && seq >= 4 && stat.MaxTime < before
+			tier.summary.add(stat, due)
+
+			fmt.Fprintln(tw, strings.Join([]string{
+				filepath.Base(path),
+				tier.name,
+				formatLevel(uint64(seq)),
+				fmt.Sprint(stat.Size),
+				time.Unix(0, stat.MinTime).UTC().Format(time.RFC3339Nano),
+				time.Unix(0, stat.MaxTime).UTC().Format(time.RFC3339Nano),
+				fmt.Sprint(due),
+			}, "\t"))
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintln(r.Stdout, "\nSummary:")
+	tw = tabwriter.NewWriter(r.Stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Tier", "Files", "Size", "Min Time", "Max Time", "Due"}, "\t"))
+	for _, tier := range []struct {
+		name    string
+		summary *TierSummary
+	}{
+		{name: "hot", summary: hot},
+		{name: "cold", summary: cold},
+	} {
+		s := tier.summary
+		minTime, maxTime := "-", "-"
+		if s.Files > 0 {
+			minTime = time.Unix(0, s.Min).UTC().Format(time.RFC3339Nano)
+			maxTime = time.Unix(0, s.Max).UTC().Format(time.RFC3339Nano)
+		}
+		fmt.Fprintln(tw, strings.Join([]string{
+			tier.name,
+			fmt.Sprint(s.Files),
+			fmt.Sprint(s.Size),
+			minTime,
+			maxTime,
+			fmt.Sprint(s.Due),
+		}, "\t"))
+	}
+	tw.Flush()
+
+	return hot, cold, nil
+}
+
+// fileStat returns the stats of the TSM file at path, including its tombstones.
+func (r *TierReport) fileStat(path string) (FileStat, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY, 0600)
+	if err != nil {
+		return FileStat{}, err
+	}
+
+	reader, err := NewTSMReader(file)
+	if err != nil {
+		file.Close()
+		return FileStat{}, err
+	}
+	defer reader.Close()
+
+	stat := reader.Stats()
+	for _, ts := range reader.TombstoneFiles() {
+		stat.Size += ts.Size
+	}
+	return stat, nil
+}
+
+func newTierSummary() *TierSummary {
+	return &TierSummary{Min: math.MaxInt64, Max: math.MinInt64}
+}
+
+func (s *TierSummary) add(stat FileStat, due bool) {
+	s.Files++
+	s.Size += uint64(stat.Size)
+	if stat.MinTime < s.Min {
+		s.Min = stat.MinTime
+	}
+	if stat.MaxTime > s.Max {
+		s.Max = stat.MaxTime
+	}
+	if due {
+		s.Due++
+	}
+}
@@ -0,0 +1,311 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/fs"
+	"go.uber.org/zap"
+)
+
+// TierColdFiles moves the fully compacted TSM files whose data is all older than
+// before to the cold tier, returning the number of files moved. Level compactions
+// are stopped while files are moved so that a compaction does not replace a file
+// that has moved from underneath it.
+func (e *Engine) TierColdFiles(ctx context.Context, before int64) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if e.FileStore.ColdDir() == "" {
+		return 0, nil
+	}
+
+	eligible := func(stat FileStat) bool {
+		_, seq, err := e.FileStore.ParseFileName(stat.Path)
+		return err == nil && seq >= 4 && stat.MaxTime < before
+	}
+
+	// Avoid aborting running compactions when there is nothing to move.
+	var found bool
+	for _, stat := range e.FileStore.Stats() {
+		if !e.FileStore.isCold(stat.Path) && eligible(stat) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, nil
+	}
+
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	return e.FileStore.MoveToColdTier(ctx, eligible)
+}
+
+// MoveToColdTier moves the TSM files for which fn returns true to the cold tier
+// directory and returns the number of files moved. Files are copied before they
+// replace the originals, so readers are never interrupted. A file that is deleted
+// from or compacted while it is being copied is skipped.
+func (f *FileStore) MoveToColdTier(ctx context.Context, fn func(stat FileStat) bool) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if f.coldDir == "" {
+		return 0, nil
+	}
+
+	// Ensure files are not closed by a compaction while they are copied.
+	f.mu.RLock()
+	files := make(unrefs, 0)
+	defer files.Unref()
+	for _, file := range f.files {
+		if !f.isCold(file.Path()) && fn(file.Stats()) {
+			file.Ref()
+			files = append(files, file)
+		}
+	}
+	f.mu.RUnlock()
+
+	var moved int
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return moved, err
+		}
+
+		ok, err := f.moveToColdTier(file)
+		if err != nil {
+			return moved, err
+		} else if !ok {
+			f.logger.Info("Skipped moving changed file to cold tier", zap.String("path", file.Path()))
+			continue
+		}
+		f.logger.Info("Moved file to cold tier", zap.String("path", file.Path()))
+		moved++
+	}
+	span.LogKV("moved", moved)
+	return moved, nil
+}
+
+// moveToColdTier copies file and its tombstones to the cold tier and replaces
+// file with the copy. It returns false if file changed while being copied.
+func (f *FileStore) moveToColdTier(file TSMFile) (bool, error) {
+	path := file.Path()
+	tombstones := file.TombstoneFiles()
+
+	// The TSM file is copied last, under a temporary name, as renaming it is what
+	// commits the move.
+	var copied []string
+	cleanup := func() {
+		for _, p := range copied {
+			os.Remove(p)
+		}
+	}
+
+	srcs := make([]string, 0, len(tombstones)+2)
+	for _, t := range tombstones {
+		srcs = append(srcs, t.Path)
+	}
+	if statsFile := StatsFilename(path); fileExists(statsFile) {
+		srcs = append(srcs, statsFile)
+	}
+	srcs = append(srcs, path)
+
+	for _, src := range srcs {
+		dst := filepath.Join(f.coldDir, filepath.Base(src))
+		if src == path {
+			dst += "." + TmpTSMFileExtension
+		}
+		copied = append(copied, dst)
+		if err := copyFile(src, dst); err != nil {
+			cleanup()
+			return false, err
+		}
+	}
+	tmpPath := copied[len(copied)-1]
+
+	if err := fs.SyncDir(f.coldDir); err != nil {
+		cleanup()
+		return false, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Deletes write tombstones while holding the read lock, so the tombstones
+	// cannot change again until the lock is released.
+	idx := -1
+	for i, other := range f.files {
+		if other == file {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 || !sameFileStats(tombstones, file.TombstoneFiles()) {
+		cleanup()
+		return false, nil
+	}
+
+	// give the observer a chance to process the file first.
+	if err := f.obs.FileFinishing(tmpPath); err != nil {
+		cleanup()
+		return false, err
+	}
+
+	coldPath := strings.TrimSuffix(tmpPath, "."+TmpTSMFileExtension)
+	if err := fs.RenameFile(tmpPath, coldPath); err != nil {
+		cleanup()
+		return false, err
+	}
+
+	fd, err := os.Open(coldPath)
+	if err != nil {
+		return false, err
+	}
+
+	tsm, err := NewTSMReader(fd,
+		WithMadviseWillNeed(f.tsmMMAPWillNeed),
+		WithTSMReaderLogger(f.logger))
+	if err != nil {
+		// The original is still live, so give up on the copy.
+		os.Remove(coldPath)
+		return false, err
+	}
+	tsm.WithObserver(f.obs)
+
+	inUse, err := f.unlinkFile(file)
+	if err != nil {
+		return false, err
+	} else if inUse {
+		if err := os.Remove(StatsFilename(path)); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		f.purger.add([]TSMFile{file})
+	}
+
+	if err := fs.SyncDir(f.dir); err != nil {
+		return false, err
+	}
+
+	f.files[idx] = tsm
+	f.lastFileStats = nil
+	return true, f.recalculateStats()
+}
+
+// openColdDir prepares the cold tier directory for opening and returns the TSM
+// files of both tiers. hot are the TSM files of the hot tier.
+func (f *FileStore) openColdDir(hot []string) ([]string, error) {
+	if err := os.MkdirAll(f.coldDir, 0777); err != nil {
+		return nil, err
+	}
+
+	// Remove copies of files whose move did not complete, and files that were
+	// still in use when they were replaced.
+	tmpFiles, err := filepath.Glob(filepath.Join(f.coldDir, fmt.Sprintf("*.%s", TmpTSMFileExtension)))
+	if err != nil {
+		return nil, err
+	}
+	for _, tmp := range tmpFiles {
+		if err := os.Remove(tmp); err != nil {
+			return nil, fmt.Errorf("error removing temp cold tier file: %v", err)
+		}
+	}
+
+	cold, err := filepath.Glob(filepath.Join(f.coldDir, fmt.Sprintf("*.%s", TSMFileExtension)))
+	if err != nil {
+		return nil, err
+	}
+
+	coldNames := make(map[string]bool, len(cold))
+	for _, fn := range cold {
+		coldNames[filepath.Base(fn)] = true
+	}
+
+	// A file in both tiers was moved but the process stopped before the original
+	// was removed. The cold copy is complete, so remove the original.
+	files := make([]string, 0, len(hot)+len(cold))
+	for _, fn := range hot {
+		if !coldNames[filepath.Base(fn)] {
+			files = append(files, fn)
+			continue
+		}
+
+		f.logger.Info("Removing file already moved to cold tier", zap.String("path", fn))
+		if err := NewTombstoner(fn, nil).Delete(); err != nil {
+			return nil, err
+		}
+		for _, p := range []string{StatsFilename(fn), fn} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+	return append(files, cold...), nil
+}
+
+// isCold returns true if path is in the cold tier directory.
+func (f *FileStore) isCold(path string) bool {
+	return f.coldDir != "" && inDir(path, f.coldDir)
+}
+
+// inDir returns true if path is a file in dir.
+func inDir(path, dir string) bool {
+	return filepath.Dir(path) == filepath.Clean(dir)
+}
+
+// linkFile hard links src to dst. Files in the cold tier usually live on a
+// different volume, so they are copied when they cannot be linked.
+func (f *FileStore) linkFile(src, dst string) error {
+	err := os.Link(src, dst)
+	if err != nil && f.isCold(src) {
+		return copyFile(src, dst)
+	}
+	return err
+}
+
+// copyFile copies the contents of src to a new file at dst and syncs it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// sameFileStats returns true if a and b describe the same files, unchanged.
+func sameFileStats(a, b []FileStat) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Path != b[i].Path || a[i].Size != b[i].Size || a[i].LastModified != b[i].LastModified {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package tsm1_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestFileStore_MoveToColdTier(t *testing.T) {
+	dir, coldDir := MustTempDir(), MustTempDir()
+	defer os.RemoveAll(dir)
+	defer os.RemoveAll(coldDir)
+
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(10, 2.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(20, 3.0)}},
+	}
+	files, err := newFiles(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	ctx := context.Background()
+	openFileStore := func() *tsm1.FileStore {
+		fs := tsm1.NewFileStore(dir)
+		fs.WithColdDir(coldDir)
+		if err := fs.Open(ctx); err != nil {
+			t.Fatalf("unexpected error opening file store: %v", err)
+		}
+		return fs
+	}
+
+	assertValues := func(fs *tsm1.FileStore) {
+		t.Helper()
+		// The first file's values were deleted before it was moved.
+		if values, err := fs.Read([]byte("cpu"), 0); err != nil {
+			t.Fatalf("unexpected error reading values: %v", err)
+		} else if len(values) != 0 {
+			t.Fatalf("expected deleted values to stay deleted, got %v", values)
+		}
+		if values, err := fs.Read([]byte("cpu"), 10); err != nil {
+			t.Fatalf("unexpected error reading values: %v", err)
+		} else if len(values) != 1 || values[0].Value() != 2.0 {
+			t.Fatalf("unexpected values: %v", values)
+		}
+	}
+
+	fs := openFileStore()
+	if err := fs.DeleteRange([][]byte{[]byte("cpu")}, 0, 0); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	moved, err := fs.MoveToColdTier(ctx, func(stat tsm1.FileStat) bool { return stat.MaxTime < 20 })
+	if err != nil {
+		t.Fatalf("unexpected error moving files: %v", err)
+	} else if moved != 2 {
+		t.Fatalf("unexpected number of files moved: got %d, exp 2", moved)
+	}
+
+	stats := fs.Stats()
+	if got, exp := len(stats), 3; got != exp {
+		t.Fatalf("unexpected number of files: got %d, exp %d", got, exp)
+	}
+	for i, stat := range stats {
+		expDir := dir
+		if i < 2 {
+			expDir = coldDir
+		}
+		if got, exp := stat.Path, filepath.Join(expDir, filepath.Base(files[i])); got != exp {
+			t.Fatalf("unexpected path for file %d: got %s, exp %s", i, got, exp)
+		}
+	}
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Fatalf("expected moved file to be removed from the hot tier, got %v", err)
+	}
+	assertValues(fs)
+
+	// Files in the cold tier are not moved again.
+	if moved, err := fs.MoveToColdTier(ctx, func(tsm1.FileStat) bool { return true }); err != nil {
+		t.Fatalf("unexpected error moving files: %v", err)
+	} else if moved != 1 {
+		t.Fatalf("unexpected number of files moved: got %d, exp 1", moved)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatalf("unexpected error closing file store: %v", err)
+	}
+
+	// Simulate a move that stopped before removing the original file.
+	buf, err := ioutil.ReadFile(filepath.Join(coldDir, filepath.Base(files[1])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(files[1], buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fs = openFileStore()
+	defer fs.Close()
+
+	if got, exp := fs.Count(), 3; got != exp {
+		t.Fatalf("unexpected number of files after reopening: got %d, exp %d", got, exp)
+	}
+	if _, err := os.Stat(files[1]); !os.IsNotExist(err) {
+		t.Fatalf("expected original of moved file to be removed on open, got %v", err)
+	}
+	assertValues(fs)
+
+	report := &tsm1.TierReport{Dir: dir, ColdDir: coldDir}
+	hot, cold, err := report.Run(false)
+	if err != nil {
+		t.Fatalf("unexpected error running tier report: %v", err)
+	}
+	if hot.Files != 0 || cold.Files != 3 {
+		t.Fatalf("unexpected tier report: hot %+v, cold %+v", hot, cold)
+	}
+	if got, exp := cold.Max, int64(20); got != exp {
+		t.Fatalf("unexpected cold tier max time: got %d, exp %d", got, exp)
+	}
+}
+
+func TestCompactor_ColdTier(t *testing.T) {
+	dir, coldDir := MustTempDir(), MustTempDir()
+	defer os.RemoveAll(dir)
+	defer os.RemoveAll(coldDir)
+
+	files, err := newFiles(dir,
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(5, 2.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(10, 3.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(20, 4.0)}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	ctx := context.Background()
+	fs := tsm1.NewFileStore(dir)
+	fs.WithColdDir(coldDir)
+	if err := fs.Open(ctx); err != nil {
+		t.Fatalf("unexpected error opening file store: %v", err)
+	}
+	defer fs.Close()
+
+	if _, err := fs.MoveToColdTier(ctx, func(stat tsm1.FileStat) bool { return stat.MaxTime < 10 }); err != nil {
+		t.Fatalf("unexpected error moving files: %v", err)
+	}
+	if err := fs.DeleteRange([][]byte{[]byte("cpu")}, 0, 0); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	// Only the cold generation with tombstones is planned, on its own, even
+	// when a full compaction is due.
+	planner := tsm1.NewDefaultPlanner(fs, time.Nanosecond)
+	groups := planner.Plan(time.Now().Add(-time.Hour))
+	defer planner.Release(groups)
+	if len(groups) != 2 {
+		t.Fatalf("expected a cold and a hot group, got %v", groups)
+	}
+	exp := tsm1.CompactionGroup{filepath.Join(coldDir, filepath.Base(files[0]))}
+	if !reflect.DeepEqual(groups[0], exp) {
+		t.Fatalf("unexpected cold group: got %v, exp %v", groups[0], exp)
+	}
+	for _, f := range groups[1] {
+		if filepath.Dir(f) != dir {
+			t.Fatalf("expected the hot group to only hold hot files, got %v", groups[1])
+		}
+	}
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Open()
+	defer compactor.Close()
+
+	compacted, err := compactor.CompactFull(groups[0])
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	} else if len(compacted) != 1 {
+		t.Fatalf("unexpected compacted files: %v", compacted)
+	}
+	if got := filepath.Dir(compacted[0]); got != coldDir {
+		t.Fatalf("expected the compaction to be written to the cold tier, got %s", got)
+	}
+	if err := fs.Replace(groups[0], compacted); err != nil {
+		t.Fatalf("unexpected error replacing files: %v", err)
+	}
+
+	var cold int
+	for _, stat := range fs.Stats() {
+		if filepath.Dir(stat.Path) == coldDir {
+			cold++
+			if stat.HasTombstone {
+				t.Fatalf("expected the tombstones of the cold file to be compacted, got %+v", stat)
+			}
+		}
+	}
+	if cold != 1 {
+		t.Fatalf("expected a single cold file, got %d", cold)
+	}
+	if values, err := fs.Read([]byte("cpu"), 5); err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	} else if len(values) != 1 || values[0].Value() != 2.0 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}